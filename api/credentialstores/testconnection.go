@@ -0,0 +1,59 @@
+package credentialstores
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/boundary/api"
+)
+
+type CredentialStoreTestConnectionResult struct {
+	response *api.Response
+}
+
+// GetItem will always be nil for CredentialStoreTestConnectionResult
+func (n CredentialStoreTestConnectionResult) GetItem() interface{} {
+	return nil
+}
+
+func (n CredentialStoreTestConnectionResult) GetResponse() *api.Response {
+	return n.response
+}
+
+// TestConnection asks the controller to connect to the backing service of a
+// credential store with the given options, without creating the store.  An
+// error is returned if the controller couldn't connect with them.
+func (c *Client) TestConnection(ctx context.Context, resourceType string, scopeId string, opt ...Option) (*CredentialStoreTestConnectionResult, error) {
+	if scopeId == "" {
+		return nil, fmt.Errorf("empty scopeId value passed into TestConnection request")
+	}
+	if resourceType == "" {
+		return nil, fmt.Errorf("empty resourceType value passed into TestConnection request")
+	}
+	if c.client == nil {
+		return nil, fmt.Errorf("nil client")
+	}
+
+	opts, apiOpts := getOpts(opt...)
+	opts.postMap["type"] = resourceType
+	opts.postMap["scope_id"] = scopeId
+
+	req, err := c.client.NewRequest(ctx, "POST", "credential-stores:test-connection", opts.postMap, apiOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("error creating TestConnection request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error performing client request during TestConnection call: %w", err)
+	}
+
+	apiErr, err := resp.Decode(nil)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding TestConnection response: %w", err)
+	}
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	return &CredentialStoreTestConnectionResult{response: resp}, nil
+}
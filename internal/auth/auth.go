@@ -12,6 +12,7 @@ import (
 	"github.com/hashicorp/boundary/internal/gen/controller/api/resources/scopes"
 	"github.com/hashicorp/boundary/internal/gen/controller/tokens"
 	"github.com/hashicorp/boundary/internal/kms"
+	"github.com/hashicorp/boundary/internal/observability/event"
 	"github.com/hashicorp/boundary/internal/perms"
 	"github.com/hashicorp/boundary/internal/requests"
 	"github.com/hashicorp/boundary/internal/servers/controller/common"
@@ -248,6 +249,13 @@ func Verify(ctx context.Context, opt ...Option) (ret VerifyResults) {
 		return
 	}
 
+	// the request's scope is only known once its resource has been resolved,
+	// so it's added to the request's event info here, which allows sinks to
+	// filter the request's events by scope
+	if ri, ok := event.RequestInfoFromContext(ctx); ok && ret.Scope != nil {
+		ri.ScopeId = ret.Scope.Id
+	}
+
 	ret.AuthTokenId = v.requestInfo.PublicId
 	ret.AuthenticationFinished = authResults.AuthenticationFinished
 	if !authResults.Authorized {
@@ -408,7 +416,7 @@ func (v verifier) performAuthCheck() (aclResults perms.ACLResults, userId string
 	_ = retErr
 	scopeInfo = new(scopes.ScopeInfo)
 	userId = AnonymousUserId
-	var accountId string
+	var accountId, authMethodId string
 
 	// Validate the token and fetch the corresponding user ID
 	switch v.requestInfo.TokenFormat {
@@ -439,15 +447,23 @@ func (v verifier) performAuthCheck() (aclResults perms.ACLResults, userId string
 		}
 		if at != nil {
 			accountId = at.GetAuthAccountId()
+			authMethodId = at.GetAuthMethodId()
 			userId = at.GetIamUserId()
 			if userId == "" {
 				v.logger.Warn("perform auth check: valid token did not map to a user, likely because no account is associated with the user any longer; continuing as u_anon", "token_id", at.GetPublicId())
 				userId = AnonymousUserId
 				accountId = ""
+				authMethodId = ""
 			}
 		}
 	}
 
+	// the request's identity is recorded by its audit events; anonymous
+	// requests don't have one
+	if info, ok := event.AuthInfoFromContext(v.ctx); ok && userId != AnonymousUserId {
+		info.UserId, info.AuthMethodId, info.AuthAccountId = userId, authMethodId, accountId
+	}
+
 	iamRepo, err := v.iamRepoFn()
 	if err != nil {
 		retErr = errors.Wrap(err, op, errors.WithMsg("failed to get iam repo"))
@@ -1,18 +1,53 @@
 package oidc
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/hashicorp/boundary/internal/db"
 	"github.com/hashicorp/boundary/internal/errors"
 	"github.com/hashicorp/boundary/internal/intglobals"
 )
 
 const (
+	// Subtype defines the auth method subtype for OIDC auth methods.
+	Subtype = "oidc"
 	// AuthMethodPrefix defines the prefix for AuthMethod public ids.
 	AuthMethodPrefix = "amoidc"
 	// AccountPrefix defines the prefix for Account public ids.
 	AccountPrefix = "acctoidc"
 )
 
+// accountIdVersion identifies the algorithm used to derive an account's
+// public id from its auth method, issuer and subject.
+type accountIdVersion int
+
+const (
+	// accountIdV1 seeds the PRNG with the auth method id, issuer and
+	// subject.  It predates versioning, so its seed has no version marker;
+	// every later version must include one in its seed, so the same inputs
+	// never derive the same id under two versions.
+	accountIdV1 accountIdVersion = 1
+
+	// accountIdV2 seeds the PRNG with its marker, followed by the auth
+	// method id, issuer and subject.
+	accountIdV2 accountIdVersion = 2
+
+	// currentAccountIdVersion is the version used to derive the ids of new
+	// accounts.
+	currentAccountIdVersion = accountIdV2
+)
+
+// accountIdVersions are the known versions, newest first.  An account keeps
+// the id it was created with, so an existing account's id may have been
+// derived with any of them.
+var accountIdVersions = []accountIdVersion{accountIdV2, accountIdV1}
+
+// marker returns the value which identifies the version in the PRNG seed.
+func (v accountIdVersion) marker() string {
+	return fmt.Sprintf("%s-v%d", AccountPrefix, v)
+}
+
 func newAuthMethodId() (string, error) {
 	const op = "oidc.newAuthMethodId"
 	id, err := db.NewPublicId(AuthMethodPrefix)
@@ -22,7 +57,12 @@ func newAuthMethodId() (string, error) {
 	return id, nil
 }
 
-func newAccountId(authMethodId, issuer, sub string) (string, error) {
+// newAccountId derives the public id of the account for the subject issued by
+// the auth method's issuer.  The id is derived using the
+// currentAccountIdVersion, unless withAccountIdVersion is used to derive it
+// with an earlier version (for example, to find accounts created before the
+// current version was introduced).
+func newAccountId(authMethodId, issuer, sub string, opt ...Option) (string, error) {
 	const op = "oidc.newAccountId"
 	if authMethodId == "" {
 		return "", errors.New(errors.InvalidParameter, op, "missing auth method id")
@@ -33,16 +73,114 @@ func newAccountId(authMethodId, issuer, sub string) (string, error) {
 	if sub == "" {
 		return "", errors.New(errors.InvalidParameter, op, "missing subject")
 	}
-	id, err := db.NewPublicId(AccountPrefix, db.WithPrngValues([]string{authMethodId, issuer, sub}))
+	opts := getOpts(opt...)
+	version := currentAccountIdVersion
+	if opts.withAccountIdVersion != 0 {
+		version = opts.withAccountIdVersion
+	}
+	prngValues, err := accountIdPrngValues(version, authMethodId, issuer, sub)
+	if err != nil {
+		return "", errors.Wrap(err, op)
+	}
+	id, err := db.NewPublicId(AccountPrefix, db.WithPrngValues(prngValues))
+	if err != nil {
+		return "", errors.Wrap(err, op)
+	}
+	return id, nil
+}
+
+// AccountIdFromClaims returns the public id a new account for the subject
+// issued by the auth method's issuer is created with.  Since account ids are
+// deterministic, it allows the id to be known before the account is created.
+// An existing account may have been created with an earlier version, so it
+// should be looked up with LookupAccountByClaims.
+func AccountIdFromClaims(authMethodId, issuer, sub string) (string, error) {
+	const op = "oidc.AccountIdFromClaims"
+	id, err := newAccountId(authMethodId, issuer, sub)
 	if err != nil {
 		return "", errors.Wrap(err, op)
 	}
 	return id, nil
 }
 
+// accountIdsFromClaims returns the public ids the account for the subject
+// issued by the auth method's issuer may have, one for each of the
+// accountIdVersions, newest first.
+func accountIdsFromClaims(authMethodId, issuer, sub string) ([]string, error) {
+	const op = "oidc.accountIdsFromClaims"
+	ids := make([]string, 0, len(accountIdVersions))
+	for _, v := range accountIdVersions {
+		id, err := newAccountId(authMethodId, issuer, sub, withAccountIdVersion(v))
+		if err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// accountIdPrngValues returns the values used to seed the PRNG when
+// deterministically computing an account's public id with the version.  The
+// values of an existing version must not change, since existing accounts
+// would no longer be found by their id; a new version must be added instead.
+func accountIdPrngValues(version accountIdVersion, authMethodId, issuer, sub string) ([]string, error) {
+	const op = "oidc.accountIdPrngValues"
+	switch version {
+	case accountIdV1:
+		return []string{authMethodId, issuer, sub}, nil
+	case accountIdV2:
+		return []string{version.marker(), authMethodId, issuer, sub}, nil
+	default:
+		return nil, errors.New(errors.InvalidParameter, op, fmt.Sprintf("unknown account id version %d", version))
+	}
+}
+
+// RelinkAccount computes the public ids of an account before and after its
+// auth method's issuer changes.  It's intended for migration tooling which
+// needs to remap references from the old account id to the new one when an
+// organization legitimately migrates to a new IdP.  The old account may have
+// been created with any of the known versions of the id derivation, so
+// oldIds has the id of each of them, newest first; newId is the id of the
+// current version.
+func RelinkAccount(authMethodId, oldIssuer, newIssuer, sub string) (oldIds []string, newId string, err error) {
+	const op = "oidc.RelinkAccount"
+	if oldIssuer == newIssuer {
+		return nil, "", errors.New(errors.InvalidParameter, op, "old and new issuer are the same")
+	}
+	oldIds, err = accountIdsFromClaims(authMethodId, oldIssuer, sub)
+	if err != nil {
+		return nil, "", errors.Wrap(err, op, errors.WithMsg("unable to compute old account ids"))
+	}
+	newId, err = newAccountId(authMethodId, newIssuer, sub)
+	if err != nil {
+		return nil, "", errors.Wrap(err, op, errors.WithMsg("unable to compute new account id"))
+	}
+	return oldIds, newId, nil
+}
+
 func newManagedGroupId() (string, error) {
 	const op = "oidc.newManagedGroupId"
-	id, err := db.NewPublicId(intglobals.OidcManagedGroupPrefix)
+	id, err := NewManagedGroupId(intglobals.OidcManagedGroupPrefix)
+	if err != nil {
+		return "", errors.Wrap(err, op)
+	}
+	return id, nil
+}
+
+// NewManagedGroupId returns a new managed group public id with the prefix.
+// It allows auth subtypes which reuse the oidc managed group plumbing to mint
+// ids under their own prefix.  The prefix must not be empty, and must not
+// contain an underscore since it's the separator between the prefix and the
+// rest of the id.
+func NewManagedGroupId(prefix string) (string, error) {
+	const op = "oidc.NewManagedGroupId"
+	switch {
+	case prefix == "":
+		return "", errors.New(errors.InvalidParameter, op, "missing prefix")
+	case strings.Contains(prefix, "_"):
+		return "", errors.New(errors.InvalidParameter, op, fmt.Sprintf("prefix %q must not contain an underscore", prefix))
+	}
+	id, err := db.NewPublicId(prefix)
 	if err != nil {
 		return "", errors.Wrap(err, op)
 	}
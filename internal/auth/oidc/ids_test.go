@@ -4,6 +4,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/boundary/internal/db"
+	"github.com/hashicorp/boundary/internal/errors"
+	"github.com/hashicorp/boundary/internal/intglobals"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -20,4 +23,223 @@ func Test_Ids(t *testing.T) {
 		require.NoError(t, err)
 		assert.True(t, strings.HasPrefix(id, AccountPrefix+"_"))
 	})
+	t.Run(intglobals.OidcManagedGroupPrefix, func(t *testing.T) {
+		id, err := newManagedGroupId()
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(id, intglobals.OidcManagedGroupPrefix+"_"))
+	})
+}
+
+func Test_NewManagedGroupId(t *testing.T) {
+	t.Parallel()
+	t.Run("custom-prefix", func(t *testing.T) {
+		id, err := NewManagedGroupId("mgcustom")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(id, "mgcustom_"))
+	})
+	t.Run("missing-prefix", func(t *testing.T) {
+		_, err := NewManagedGroupId("")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+	})
+	t.Run("prefix-with-separator", func(t *testing.T) {
+		_, err := NewManagedGroupId("mg_custom")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+	})
+}
+
+func Test_AccountIdFromClaims(t *testing.T) {
+	t.Parallel()
+	const (
+		authMethodId = "amoidc_1234567890"
+		issuer       = "https://idp.example.com"
+		sub          = "alice"
+	)
+	tests := []struct {
+		name         string
+		authMethodId string
+		issuer       string
+		sub          string
+		wantErrMsg   string
+	}{
+		{
+			name:       "missing-auth-method-id",
+			issuer:     issuer,
+			sub:        sub,
+			wantErrMsg: "missing auth method id",
+		},
+		{
+			name:         "missing-issuer",
+			authMethodId: authMethodId,
+			sub:          sub,
+			wantErrMsg:   "missing issuer",
+		},
+		{
+			name:         "missing-subject",
+			authMethodId: authMethodId,
+			issuer:       issuer,
+			wantErrMsg:   "missing subject",
+		},
+		{
+			name:         "valid",
+			authMethodId: authMethodId,
+			issuer:       issuer,
+			sub:          sub,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			got, err := AccountIdFromClaims(tt.authMethodId, tt.issuer, tt.sub)
+			if tt.wantErrMsg != "" {
+				require.Error(err)
+				assert.True(errors.Match(errors.T(errors.InvalidParameter), err))
+				assert.Contains(err.Error(), tt.wantErrMsg)
+				return
+			}
+			require.NoError(err)
+			want, err := newAccountId(tt.authMethodId, tt.issuer, tt.sub)
+			require.NoError(err)
+			assert.Equal(want, got)
+		})
+	}
+}
+
+func Test_RelinkAccount(t *testing.T) {
+	t.Parallel()
+	const (
+		authMethodId = "amoidc_1234567890"
+		oldIssuer    = "https://old-idp.example.com"
+		newIssuer    = "https://new-idp.example.com"
+		sub          = "alice"
+	)
+	t.Run("same-issuer", func(t *testing.T) {
+		_, _, err := RelinkAccount(authMethodId, oldIssuer, oldIssuer, sub)
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+	})
+	t.Run("missing-subject", func(t *testing.T) {
+		_, _, err := RelinkAccount(authMethodId, oldIssuer, newIssuer, "")
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+	})
+	t.Run("matches-newAccountId", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		oldIds, newId, err := RelinkAccount(authMethodId, oldIssuer, newIssuer, sub)
+		require.NoError(err)
+		assert.NotContains(oldIds, newId)
+
+		wantOldIds, err := accountIdsFromClaims(authMethodId, oldIssuer, sub)
+		require.NoError(err)
+		wantNewId, err := newAccountId(authMethodId, newIssuer, sub)
+		require.NoError(err)
+		assert.Equal(wantOldIds, oldIds)
+		assert.Equal(wantNewId, newId)
+
+		// the seed of v1 must match the original computation, otherwise
+		// existing accounts would be stranded
+		seededId, err := db.NewPublicId(AccountPrefix, db.WithPrngValues([]string{authMethodId, oldIssuer, sub}))
+		require.NoError(err)
+		assert.Contains(oldIds, seededId)
+	})
+}
+
+func Test_AccountIdVersions(t *testing.T) {
+	t.Parallel()
+	t.Run("v1-pinned", func(t *testing.T) {
+		// v1 ids are stored in existing accounts, so they must never change
+		tests := []struct {
+			authMethodId string
+			issuer       string
+			sub          string
+			want         string
+		}{
+			{
+				authMethodId: "amoidc_1234567890",
+				issuer:       "https://idp.example.com",
+				sub:          "alice",
+				want:         "acctoidc_9LANNB3eQg",
+			},
+			{
+				authMethodId: "amoidc_0987654321",
+				issuer:       "https://accounts.example.org",
+				sub:          "bob@example.org",
+				want:         "acctoidc_3sco0vGYs6",
+			},
+		}
+		for _, tt := range tests {
+			assert, require := assert.New(t), require.New(t)
+			got, err := newAccountId(tt.authMethodId, tt.issuer, tt.sub, withAccountIdVersion(accountIdV1))
+			require.NoError(err)
+			assert.Equal(tt.want, got)
+		}
+	})
+	t.Run("v2-pinned", func(t *testing.T) {
+		// v2 ids are stored in existing accounts, so they must never change
+		tests := []struct {
+			authMethodId string
+			issuer       string
+			sub          string
+			want         string
+		}{
+			{
+				authMethodId: "amoidc_1234567890",
+				issuer:       "https://idp.example.com",
+				sub:          "alice",
+				want:         "acctoidc_jr5unX6n4t",
+			},
+			{
+				authMethodId: "amoidc_0987654321",
+				issuer:       "https://accounts.example.org",
+				sub:          "bob@example.org",
+				want:         "acctoidc_bPTIgotF6f",
+			},
+		}
+		for _, tt := range tests {
+			assert, require := assert.New(t), require.New(t)
+			got, err := newAccountId(tt.authMethodId, tt.issuer, tt.sub, withAccountIdVersion(accountIdV2))
+			require.NoError(err)
+			assert.Equal(tt.want, got)
+		}
+	})
+	t.Run("versions-differ", func(t *testing.T) {
+		// the marker keeps the versions from deriving the same id
+		assert, require := assert.New(t), require.New(t)
+		v1, err := newAccountId("amoidc_1234567890", "https://idp.example.com", "alice", withAccountIdVersion(accountIdV1))
+		require.NoError(err)
+		v2, err := newAccountId("amoidc_1234567890", "https://idp.example.com", "alice", withAccountIdVersion(accountIdV2))
+		require.NoError(err)
+		assert.NotEqual(v1, v2)
+		assert.Equal("acctoidc-v2", accountIdV2.marker())
+	})
+	t.Run("ids-from-claims", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		ids, err := accountIdsFromClaims("amoidc_1234567890", "https://idp.example.com", "alice")
+		require.NoError(err)
+		// newest first, ending with the pinned v1 id
+		require.Len(ids, len(accountIdVersions))
+		current, err := newAccountId("amoidc_1234567890", "https://idp.example.com", "alice")
+		require.NoError(err)
+		assert.Equal(current, ids[0])
+		assert.Equal("acctoidc_9LANNB3eQg", ids[len(ids)-1])
+
+		_, err = accountIdsFromClaims("amoidc_1234567890", "", "alice")
+		assert.True(errors.Match(errors.T(errors.InvalidParameter), err))
+	})
+	t.Run("default-is-current", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		got, err := newAccountId("amoidc_1234567890", "https://idp.example.com", "alice")
+		require.NoError(err)
+		want, err := newAccountId("amoidc_1234567890", "https://idp.example.com", "alice", withAccountIdVersion(currentAccountIdVersion))
+		require.NoError(err)
+		assert.Equal(want, got)
+	})
+	t.Run("unknown-version", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		_, err := newAccountId("amoidc_1234567890", "https://idp.example.com", "alice", withAccountIdVersion(currentAccountIdVersion+1))
+		require.Error(err)
+		assert.True(errors.Match(errors.T(errors.InvalidParameter), err))
+		assert.Contains(err.Error(), "unknown account id version")
+	})
 }
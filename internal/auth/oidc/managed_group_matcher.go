@@ -0,0 +1,146 @@
+package oidc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/boundary/internal/errors"
+	"github.com/hashicorp/go-bexpr"
+	"github.com/mitchellh/pointerstructure"
+)
+
+// ManagedGroupMatcherFunc defines a func that's used to determine which
+// managed groups an account belongs to during account linking.  It's passed
+// all the managed groups for the account's auth method along with the parsed
+// ID Token and UserInfo claims, and it must return the public ids of the
+// managed groups the account is a member of.
+type ManagedGroupMatcherFunc func(ctx context.Context, mgs []*ManagedGroup, idTokenClaims, userInfoClaims map[string]interface{}) ([]string, error)
+
+var (
+	// managedGroupMatchers are the registered matchers keyed by auth method
+	// subtype
+	managedGroupMatchers = map[string]ManagedGroupMatcherFunc{}
+	// authMethodSubtypes maps auth method id prefixes to the subtype whose
+	// matcher is used for the auth method's accounts
+	authMethodSubtypes       = map[string]string{AuthMethodPrefix: Subtype}
+	managedGroupMatchersLock sync.RWMutex
+)
+
+// RegisterManagedGroupMatcher will register a ManagedGroupMatcherFunc for the
+// auth method subtype.  It's intended to be called from an init() func and it
+// will return an error if a matcher has already been registered for the
+// subtype.  The matcher is used for auth methods whose public id has one of the
+// authMethodPrefixes (auth methods with the oidc AuthMethodPrefix always
+// resolve to the oidc Subtype).  When no matcher is registered for a subtype,
+// each managed group's filter expression is evaluated against the claims.
+func RegisterManagedGroupMatcher(subtype string, fn ManagedGroupMatcherFunc, authMethodPrefixes ...string) error {
+	const op = "oidc.RegisterManagedGroupMatcher"
+	if subtype == "" {
+		return errors.New(errors.InvalidParameter, op, "missing subtype")
+	}
+	if fn == nil {
+		return errors.New(errors.InvalidParameter, op, "missing matcher func")
+	}
+	managedGroupMatchersLock.Lock()
+	defer managedGroupMatchersLock.Unlock()
+	if _, ok := managedGroupMatchers[subtype]; ok {
+		return errors.New(errors.InvalidParameter, op, "matcher already registered for subtype: "+subtype)
+	}
+	for _, prefix := range authMethodPrefixes {
+		prefix = strings.TrimSpace(prefix)
+		if existing, ok := authMethodSubtypes[prefix]; ok && existing != subtype {
+			return errors.New(errors.InvalidParameter, op, fmt.Sprintf("auth method prefix %q already registered for subtype: %s", prefix, existing))
+		}
+	}
+	for _, prefix := range authMethodPrefixes {
+		authMethodSubtypes[strings.TrimSpace(prefix)] = subtype
+	}
+	managedGroupMatchers[subtype] = fn
+	return nil
+}
+
+// authMethodSubtype returns the subtype registered for the prefix of the auth
+// method id, or an empty string if none is registered.
+func authMethodSubtype(authMethodId string) string {
+	i := strings.Index(authMethodId, "_")
+	if i == -1 {
+		return ""
+	}
+	managedGroupMatchersLock.RLock()
+	defer managedGroupMatchersLock.RUnlock()
+	return authMethodSubtypes[authMethodId[:i]]
+}
+
+// registeredManagedGroupMatcher returns the ManagedGroupMatcherFunc
+// registered for the subtype.
+func registeredManagedGroupMatcher(subtype string) (ManagedGroupMatcherFunc, bool) {
+	managedGroupMatchersLock.RLock()
+	defer managedGroupMatchersLock.RUnlock()
+	fn, ok := managedGroupMatchers[subtype]
+	return fn, ok
+}
+
+// matchManagedGroups returns the managed groups the account with the provided
+// claims belongs to, using the matcher registered for the subtype (if one
+// exists) or each managed group's filter expression.  Matched ids are only
+// checked against the auth method's managed groups, not a prefix, since
+// subtypes may mint managed group ids under their own prefix via
+// NewManagedGroupId.
+func matchManagedGroups(ctx context.Context, subtype string, mgs []*ManagedGroup, idTokenClaims, userInfoClaims map[string]interface{}) ([]*ManagedGroup, error) {
+	const op = "oidc.matchManagedGroups"
+	fn, ok := registeredManagedGroupMatcher(subtype)
+	if !ok {
+		fn = filterManagedGroupMatcher
+	}
+	ids, err := fn(ctx, mgs, idTokenClaims, userInfoClaims)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	byId := make(map[string]*ManagedGroup, len(mgs))
+	for _, mg := range mgs {
+		byId[mg.GetPublicId()] = mg
+	}
+	matched := make([]*ManagedGroup, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		mg, ok := byId[id]
+		if !ok {
+			return nil, errors.New(errors.InvalidParameter, op, "matched managed group is not part of the auth method: "+id)
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		matched = append(matched, mg)
+	}
+	return matched, nil
+}
+
+// filterManagedGroupMatcher is the default ManagedGroupMatcherFunc which
+// evaluates each managed group's filter expression against the claims.
+func filterManagedGroupMatcher(_ context.Context, mgs []*ManagedGroup, idTokenClaims, userInfoClaims map[string]interface{}) ([]string, error) {
+	const op = "oidc.filterManagedGroupMatcher"
+	evalData := map[string]interface{}{
+		"token":    idTokenClaims,
+		"userinfo": userInfoClaims,
+	}
+	matched := make([]string, 0, len(mgs))
+	for _, mg := range mgs {
+		eval, err := bexpr.CreateEvaluator(mg.Filter)
+		if err != nil {
+			// We check all filters on ingress so this should never happen,
+			// but we validate anyways
+			return nil, errors.Wrap(err, op)
+		}
+		match, err := eval.Evaluate(evalData)
+		if err != nil && !errors.Is(err, pointerstructure.ErrNotFound) {
+			return nil, errors.Wrap(err, op)
+		}
+		if match {
+			matched = append(matched, mg.GetPublicId())
+		}
+	}
+	return matched, nil
+}
@@ -0,0 +1,163 @@
+package oidc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/boundary/internal/auth/oidc/store"
+	"github.com/hashicorp/boundary/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RegisterManagedGroupMatcher(t *testing.T) {
+	t.Parallel()
+	const testSubtype = "test-register-matcher"
+	t.Cleanup(func() {
+		managedGroupMatchersLock.Lock()
+		defer managedGroupMatchersLock.Unlock()
+		delete(managedGroupMatchers, testSubtype)
+		delete(authMethodSubtypes, "amtestregister")
+	})
+	testMatcher := func(context.Context, []*ManagedGroup, map[string]interface{}, map[string]interface{}) ([]string, error) {
+		return nil, nil
+	}
+	t.Run("missing-subtype", func(t *testing.T) {
+		err := RegisterManagedGroupMatcher("", testMatcher)
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+	})
+	t.Run("missing-func", func(t *testing.T) {
+		err := RegisterManagedGroupMatcher(testSubtype, nil)
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+	})
+	t.Run("success-and-duplicate", func(t *testing.T) {
+		require.NoError(t, RegisterManagedGroupMatcher(testSubtype, testMatcher))
+		_, ok := registeredManagedGroupMatcher(testSubtype)
+		assert.True(t, ok)
+
+		err := RegisterManagedGroupMatcher(testSubtype, testMatcher)
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+	})
+	t.Run("prefix-of-another-subtype", func(t *testing.T) {
+		err := RegisterManagedGroupMatcher("test-register-other", testMatcher, AuthMethodPrefix)
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+		_, ok := registeredManagedGroupMatcher("test-register-other")
+		assert.False(t, ok)
+		assert.Equal(t, Subtype, authMethodSubtype(AuthMethodPrefix+"_1234567890"))
+	})
+}
+
+func Test_authMethodSubtype(t *testing.T) {
+	t.Parallel()
+	const testSubtype = "test-auth-method-subtype"
+	testMatcher := func(context.Context, []*ManagedGroup, map[string]interface{}, map[string]interface{}) ([]string, error) {
+		return nil, nil
+	}
+	require.NoError(t, RegisterManagedGroupMatcher(testSubtype, testMatcher, "amtestsubtype"))
+	t.Cleanup(func() {
+		managedGroupMatchersLock.Lock()
+		defer managedGroupMatchersLock.Unlock()
+		delete(managedGroupMatchers, testSubtype)
+		delete(authMethodSubtypes, "amtestsubtype")
+	})
+
+	tests := []struct {
+		name         string
+		authMethodId string
+		want         string
+	}{
+		{name: "oidc", authMethodId: AuthMethodPrefix + "_1234567890", want: Subtype},
+		{name: "registered-prefix", authMethodId: "amtestsubtype_1234567890", want: testSubtype},
+		{name: "unregistered-prefix", authMethodId: "amunknown_1234567890"},
+		{name: "no-prefix", authMethodId: "1234567890"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, authMethodSubtype(tt.authMethodId))
+		})
+	}
+}
+
+func Test_matchManagedGroups(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testMg := func(id, filter string) *ManagedGroup {
+		return &ManagedGroup{
+			ManagedGroup: &store.ManagedGroup{
+				PublicId:     id,
+				AuthMethodId: "amoidc_1234567890",
+				Filter:       filter,
+			},
+		}
+	}
+	mgs := []*ManagedGroup{
+		testMg("mgoidc_1111111111", `"/token/groups" contains "admins"`),
+		testMg("mgoidc_2222222222", `"/userinfo/dept" == "eng"`),
+		testMg("mgcustom_3333333333", `"/userinfo/dept" == "ops"`),
+	}
+	idTkClaims := map[string]interface{}{"groups": []string{"admins"}}
+	userInfoClaims := map[string]interface{}{"custom_groups": "mgoidc_2222222222"}
+
+	t.Run("default-filter", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		got, err := matchManagedGroups(ctx, "no-registered-matcher", mgs, idTkClaims, userInfoClaims)
+		require.NoError(err)
+		require.Len(got, 1)
+		assert.Equal("mgoidc_1111111111", got[0].PublicId)
+	})
+
+	const customSubtype = "test-match-custom"
+	require.NoError(t, RegisterManagedGroupMatcher(customSubtype, func(_ context.Context, _ []*ManagedGroup, _ map[string]interface{}, u map[string]interface{}) ([]string, error) {
+		return []string{u["custom_groups"].(string), u["custom_groups"].(string)}, nil
+	}))
+	const customPrefixSubtype = "test-match-custom-prefix"
+	require.NoError(t, RegisterManagedGroupMatcher(customPrefixSubtype, func(context.Context, []*ManagedGroup, map[string]interface{}, map[string]interface{}) ([]string, error) {
+		return []string{"mgcustom_3333333333"}, nil
+	}))
+	const notManagedGroupSubtype = "test-match-not-managed-group"
+	require.NoError(t, RegisterManagedGroupMatcher(notManagedGroupSubtype, func(context.Context, []*ManagedGroup, map[string]interface{}, map[string]interface{}) ([]string, error) {
+		return []string{"acctoidc_1234567890"}, nil
+	}))
+	const unknownSubtype = "test-match-unknown"
+	require.NoError(t, RegisterManagedGroupMatcher(unknownSubtype, func(context.Context, []*ManagedGroup, map[string]interface{}, map[string]interface{}) ([]string, error) {
+		return []string{"mgoidc_3333333333"}, nil
+	}))
+	t.Cleanup(func() {
+		managedGroupMatchersLock.Lock()
+		defer managedGroupMatchersLock.Unlock()
+		delete(managedGroupMatchers, customSubtype)
+		delete(managedGroupMatchers, customPrefixSubtype)
+		delete(managedGroupMatchers, notManagedGroupSubtype)
+		delete(managedGroupMatchers, unknownSubtype)
+	})
+
+	t.Run("custom-matcher", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		got, err := matchManagedGroups(ctx, customSubtype, mgs, idTkClaims, userInfoClaims)
+		require.NoError(err)
+		require.Len(got, 1)
+		assert.Equal("mgoidc_2222222222", got[0].PublicId)
+	})
+	t.Run("custom-prefix", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		got, err := matchManagedGroups(ctx, customPrefixSubtype, mgs, idTkClaims, userInfoClaims)
+		require.NoError(err)
+		require.Len(got, 1)
+		assert.Equal("mgcustom_3333333333", got[0].PublicId)
+	})
+	t.Run("not-managed-group", func(t *testing.T) {
+		_, err := matchManagedGroups(ctx, notManagedGroupSubtype, mgs, idTkClaims, userInfoClaims)
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+	})
+	t.Run("unknown-managed-group", func(t *testing.T) {
+		_, err := matchManagedGroups(ctx, unknownSubtype, mgs, idTkClaims, userInfoClaims)
+		require.Error(t, err)
+		assert.True(t, errors.Match(errors.T(errors.InvalidParameter), err))
+	})
+}
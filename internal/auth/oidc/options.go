@@ -45,6 +45,7 @@ type options struct {
 	withOperationalState    AuthMethodState
 	withAccountClaimMap     map[string]AccountToClaim
 	withReader              db.Reader
+	withAccountIdVersion    accountIdVersion
 }
 
 func getDefaultOptions() options {
@@ -229,3 +230,11 @@ func WithReader(reader db.Reader) Option {
 		o.withReader = reader
 	}
 }
+
+// withAccountIdVersion provides an optional version of the algorithm used to
+// derive an account's public id.
+func withAccountIdVersion(v accountIdVersion) Option {
+	return func(o *options) {
+		o.withAccountIdVersion = v
+	}
+}
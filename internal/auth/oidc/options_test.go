@@ -203,4 +203,11 @@ func Test_GetOpts(t *testing.T) {
 		opts := getOpts(WithReader(r))
 		assert.Equal(r, opts.withReader)
 	})
+	t.Run("withAccountIdVersion", func(t *testing.T) {
+		assert := assert.New(t)
+		opts := getOpts(withAccountIdVersion(accountIdV1))
+		testOpts := getDefaultOptions()
+		testOpts.withAccountIdVersion = accountIdV1
+		assert.Equal(opts, testOpts)
+	})
 }
@@ -129,6 +129,52 @@ func (r *Repository) LookupAccount(ctx context.Context, withPublicId string, opt
 	return a, nil
 }
 
+// LookupAccountByClaims returns the account for the subject issued by the
+// auth method's issuer.  The account is looked up by the id of each known
+// version of the id derivation, newest first, so accounts created before the
+// current version are found.  Returns nil, nil if no account is found.  All
+// options are ignored.
+func (r *Repository) LookupAccountByClaims(ctx context.Context, authMethodId, issuer, sub string, _ ...Option) (*Account, error) {
+	const op = "oidc.(Repository).LookupAccountByClaims"
+	ids, err := accountIdsFromClaims(authMethodId, issuer, sub)
+	if err != nil {
+		return nil, errors.Wrap(err, op)
+	}
+	for _, id := range ids {
+		a, err := r.LookupAccount(ctx, id)
+		if err != nil {
+			return nil, errors.Wrap(err, op)
+		}
+		if a != nil {
+			return a, nil
+		}
+	}
+	return nil, nil
+}
+
+// LookupAccountAuthMethodId returns the public id of the auth method which owns
+// the account.  Account ids are only namespaced by the AccountPrefix, so the
+// owning auth method can't be derived from the id itself.  withPublicId must
+// be an oidc account id.  If the account is not found, it will return a
+// RecordNotFound error.  All options are ignored.
+func (r *Repository) LookupAccountAuthMethodId(ctx context.Context, withPublicId string, _ ...Option) (string, error) {
+	const op = "oidc.(Repository).LookupAccountAuthMethodId"
+	if withPublicId == "" {
+		return "", errors.New(errors.InvalidPublicId, op, "missing public id")
+	}
+	if !strings.HasPrefix(withPublicId, AccountPrefix+"_") {
+		return "", errors.New(errors.InvalidPublicId, op, fmt.Sprintf("%s is not an oidc account id", withPublicId))
+	}
+	a, err := r.LookupAccount(ctx, withPublicId)
+	if err != nil {
+		return "", errors.Wrap(err, op)
+	}
+	if a == nil {
+		return "", errors.New(errors.RecordNotFound, op, fmt.Sprintf("account %s not found", withPublicId))
+	}
+	return a.AuthMethodId, nil
+}
+
 // ListAccounts in an auth method and supports WithLimit option.
 func (r *Repository) ListAccounts(ctx context.Context, withAuthMethodId string, opt ...Option) ([]*Account, error) {
 	const op = "oidc.(Repository).ListAccounts"
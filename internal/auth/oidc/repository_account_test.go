@@ -540,6 +540,141 @@ func TestRepository_LookupAccount(t *testing.T) {
 	}
 }
 
+func TestRepository_LookupAccountByClaims(t *testing.T) {
+	conn, _ := db.TestSetup(t, "postgres")
+	rw := db.New(conn)
+	wrapper := db.TestWrapper(t)
+
+	kmsCache := kms.TestKms(t, conn, wrapper)
+	iamRepo := iam.TestRepo(t, conn, wrapper)
+	org, _ := iam.TestScopes(t, iamRepo)
+
+	ctx := context.Background()
+	databaseWrapper, err := kmsCache.GetWrapper(ctx, org.PublicId, kms.KeyPurposeDatabase)
+	require.NoError(t, err)
+
+	authMethod := TestAuthMethod(
+		t, conn, databaseWrapper, org.PublicId, ActivePrivateState,
+		"alice-rp", "fido",
+		WithSigningAlgs(RS256),
+		WithIssuer(TestConvertToUrls(t, "https://www.alice.com")[0]),
+		WithApiUrl(TestConvertToUrls(t, "https://www.alice.com/callback")[0]),
+	)
+	current := TestAccount(t, conn, authMethod, "current-subject")
+	v1 := TestAccount(t, conn, authMethod, "v1-subject", withAccountIdVersion(accountIdV1))
+
+	tests := []struct {
+		name      string
+		sub       string
+		want      *Account
+		wantIsErr errors.Code
+	}{
+		{
+			name: "current-version",
+			sub:  "current-subject",
+			want: current,
+		},
+		{
+			name: "v1",
+			sub:  "v1-subject",
+			want: v1,
+		},
+		{
+			name: "not-found",
+			sub:  "unknown-subject",
+		},
+		{
+			name:      "missing-sub",
+			wantIsErr: errors.InvalidParameter,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			kms := kms.TestKms(t, conn, wrapper)
+			repo, err := NewRepository(rw, rw, kms)
+			require.NoError(err)
+			got, err := repo.LookupAccountByClaims(ctx, authMethod.PublicId, authMethod.Issuer, tt.sub)
+			if tt.wantIsErr != 0 {
+				assert.Truef(errors.Match(errors.T(tt.wantIsErr), err), "want err: %q got: %q", tt.wantIsErr, err)
+				assert.Nil(got)
+				return
+			}
+			require.NoError(err)
+			assert.EqualValues(tt.want, got)
+		})
+	}
+}
+
+func TestRepository_LookupAccountAuthMethodId(t *testing.T) {
+	conn, _ := db.TestSetup(t, "postgres")
+	rw := db.New(conn)
+	wrapper := db.TestWrapper(t)
+
+	kmsCache := kms.TestKms(t, conn, wrapper)
+	iamRepo := iam.TestRepo(t, conn, wrapper)
+	org, _ := iam.TestScopes(t, iamRepo)
+
+	ctx := context.Background()
+	databaseWrapper, err := kmsCache.GetWrapper(ctx, org.PublicId, kms.KeyPurposeDatabase)
+	require.NoError(t, err)
+
+	authMethod := TestAuthMethod(
+		t, conn, databaseWrapper, org.PublicId, ActivePrivateState,
+		"alice-rp", "fido",
+		WithSigningAlgs(RS256),
+		WithIssuer(TestConvertToUrls(t, "https://www.alice.com")[0]),
+		WithApiUrl(TestConvertToUrls(t, "https://www.alice.com/callback")[0]),
+	)
+	account := TestAccount(t, conn, authMethod, "test-subject")
+
+	newAcctId, err := newAccountId(authMethod.GetPublicId(), authMethod.Issuer, "random-id")
+	require.NoError(t, err)
+	tests := []struct {
+		name      string
+		in        string
+		want      string
+		wantIsErr errors.Code
+	}{
+		{
+			name:      "With no public id",
+			wantIsErr: errors.InvalidPublicId,
+		},
+		{
+			name:      "With non oidc account id",
+			in:        authMethod.GetPublicId(),
+			wantIsErr: errors.InvalidPublicId,
+		},
+		{
+			name:      "With non existing account id",
+			in:        newAcctId,
+			wantIsErr: errors.RecordNotFound,
+		},
+		{
+			name: "With existing account id",
+			in:   account.GetPublicId(),
+			want: authMethod.GetPublicId(),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			repo, err := NewRepository(rw, rw, kmsCache)
+			assert.NoError(err)
+			require.NotNil(repo)
+			got, err := repo.LookupAccountAuthMethodId(context.Background(), tt.in)
+			if tt.wantIsErr != 0 {
+				assert.Truef(errors.Match(errors.T(tt.wantIsErr), err), "Unexpected error %s", err)
+				return
+			}
+			require.NoError(err)
+			assert.Equal(tt.want, got)
+		})
+	}
+}
+
 func TestRepository_DeleteAccount(t *testing.T) {
 	conn, _ := db.TestSetup(t, "postgres")
 	rw := db.New(conn)
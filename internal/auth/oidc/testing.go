@@ -170,7 +170,7 @@ func TestAccount(t *testing.T, conn *gorm.DB, am *AuthMethod, subject string, op
 	a, err := NewAccount(am.PublicId, subject, opt...)
 	require.NoError(err)
 
-	id, err := newAccountId(am.GetPublicId(), am.Issuer, subject)
+	id, err := newAccountId(am.GetPublicId(), am.Issuer, subject, opt...)
 	require.NoError(err)
 	a.PublicId = id
 
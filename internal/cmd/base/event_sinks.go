@@ -0,0 +1,97 @@
+package base
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// reopenEventSinksResultsSuffix is appended to the path of a server's pid file
+// to get the path of the results of reopening its event sinks.
+const reopenEventSinksResultsSuffix = ".reopen-event-sinks.json"
+
+// ReopenEventSinksResults are the results of reopening a server's event
+// sinks, which the server writes next to its pid file so they can be read back
+// by the command which signaled it.
+type ReopenEventSinksResults struct {
+	Pid   int                      `json:"pid"`
+	Sinks []ReopenEventSinksResult `json:"sinks"`
+	// Error is set when the sinks couldn't be reopened at all, such as when
+	// the server's eventer is closed.
+	Error string `json:"error,omitempty"`
+}
+
+// ReopenEventSinksResult is the result of reopening one of the sinks.
+type ReopenEventSinksResult struct {
+	Name     string `json:"name"`
+	Reopened bool   `json:"reopened"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ReopenEventSinksResultsPath returns the path of the results of reopening the
+// event sinks of the server with the pid file.
+func ReopenEventSinksResultsPath(pidPath string) string {
+	return pidPath + reopenEventSinksResultsSuffix
+}
+
+// ReopenEventSinks reopens the server eventer's sinks (for example, after
+// their files have been rotated) and reports the result to the UI.  When the
+// server has a pid file, the result of each sink is also written to
+// ReopenEventSinksResultsPath.
+func (b *Server) ReopenEventSinks(pidPath string) {
+	if b.Eventer == nil {
+		return
+	}
+	b.UI.Output("==> Boundary event sinks reopen triggered")
+	results := &ReopenEventSinksResults{
+		Pid:   os.Getpid(),
+		Sinks: []ReopenEventSinksResult{},
+	}
+	sinks, err := b.Eventer.ReopenSinks()
+	for _, s := range sinks {
+		r := ReopenEventSinksResult{Name: s.Name, Reopened: s.Error == nil}
+		if s.Error != nil {
+			r.Error = s.Error.Error()
+		}
+		results.Sinks = append(results.Sinks, r)
+	}
+	switch {
+	case err != nil:
+		if len(sinks) == 0 {
+			results.Error = err.Error()
+		}
+		b.UI.Error(fmt.Errorf("Error(s) were encountered reopening event sinks: %w", err).Error())
+	default:
+		b.UI.Output("==> Boundary event sinks reopened")
+	}
+	if pidPath == "" {
+		return
+	}
+	if err := writeReopenEventSinksResults(ReopenEventSinksResultsPath(pidPath), results); err != nil {
+		b.UI.Error(fmt.Errorf("Error writing the results of reopening event sinks: %w", err).Error())
+	}
+}
+
+// writeReopenEventSinksResults writes the results to a temporary file which is
+// renamed to path, so they're never read partially written.
+func writeReopenEventSinksResults(path string, results *ReopenEventSinksResults) error {
+	b, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,55 @@
+package base
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/boundary/internal/observability/event"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_ReopenEventSinks(t *testing.T) {
+	readResults := func(t *testing.T, pidPath string) *ReopenEventSinksResults {
+		t.Helper()
+		b, err := ioutil.ReadFile(ReopenEventSinksResultsPath(pidPath))
+		require.NoError(t, err)
+		var results ReopenEventSinksResults
+		require.NoError(t, json.Unmarshal(b, &results))
+		return &results
+	}
+
+	t.Run("results-written", func(t *testing.T) {
+		assert := assert.New(t)
+		e, _ := event.NewTestEventer(t)
+		ui := cli.NewMockUi()
+		s := &Server{Command: &Command{UI: ui}, Eventer: e}
+		pidPath := filepath.Join(t.TempDir(), "boundary.pid")
+
+		s.ReopenEventSinks(pidPath)
+		assert.Contains(ui.OutputWriter.String(), "event sinks reopened")
+		assert.Equal(&ReopenEventSinksResults{
+			Pid:   os.Getpid(),
+			Sinks: []ReopenEventSinksResult{{Name: "test-sink", Reopened: true}},
+		}, readResults(t, pidPath))
+	})
+	t.Run("closed", func(t *testing.T) {
+		assert := assert.New(t)
+		e, _ := event.NewTestEventer(t)
+		require.NoError(t, e.Close(context.Background()))
+		ui := cli.NewMockUi()
+		s := &Server{Command: &Command{UI: ui}, Eventer: e}
+		pidPath := filepath.Join(t.TempDir(), "boundary.pid")
+
+		s.ReopenEventSinks(pidPath)
+		assert.Contains(ui.ErrorWriter.String(), "Error(s) were encountered reopening event sinks")
+		results := readResults(t, pidPath)
+		assert.Empty(results.Sinks)
+		assert.Contains(results.Error, event.ErrEventerClosed.Error())
+	})
+}
@@ -135,7 +135,7 @@ func NewServer(cmd *Command) *Server {
 		SecureRandomReader: rand.Reader,
 		ReloadFuncsLock:    new(sync.RWMutex),
 		ReloadFuncs:        make(map[string][]reloadutil.ReloadFunc),
-		StderrLock:         new(sync.Mutex),
+		StderrLock:         event.StderrLock(),
 	}
 }
 
@@ -188,6 +188,12 @@ func (b *Server) SetupEventing(logger hclog.Logger, serializationLock *sync.Mute
 		return berrors.Wrap(err, op, berrors.WithMsg("unable to initialize system eventer"))
 	}
 
+	// the startup event records the event config in the event stream, but a
+	// failure to write it isn't a reason to not start
+	if err := e.EmitStartupEvent(context.Background()); err != nil {
+		logger.Error("unable to emit eventer startup event", "error", err.Error())
+	}
+
 	return nil
 }
 
@@ -293,6 +299,9 @@ func (b *Server) RemovePidFile(pidPath string) error {
 	if pidPath == "" {
 		return nil
 	}
+	if err := os.Remove(ReopenEventSinksResultsPath(pidPath)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
 	return os.Remove(pidPath)
 }
 
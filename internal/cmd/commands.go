@@ -38,6 +38,7 @@ func initCommands(ui, serverCmdUi cli.Ui, runOpts *RunOptions) {
 			return &server.Command{
 				Server:    base.NewServer(base.NewCommand(serverCmdUi)),
 				SighupCh:  base.MakeSighupCh(),
+				SigUSR1Ch: MakeSigUSR1Ch(),
 				SigUSR2Ch: MakeSigUSR2Ch(),
 			}, nil
 		},
@@ -45,9 +46,15 @@ func initCommands(ui, serverCmdUi cli.Ui, runOpts *RunOptions) {
 			return &dev.Command{
 				Server:    base.NewServer(base.NewCommand(serverCmdUi)),
 				SighupCh:  base.MakeSighupCh(),
+				SigUSR1Ch: MakeSigUSR1Ch(),
 				SigUSR2Ch: MakeSigUSR2Ch(),
 			}, nil
 		},
+		"server reopen-event-sinks": func() (cli.Command, error) {
+			return &server.ReopenEventSinksCommand{
+				Command: base.NewCommand(ui),
+			}, nil
+		},
 		"version": func() (cli.Command, error) {
 			return &version.Command{
 				Command: base.NewCommand(ui),
@@ -392,6 +399,18 @@ func initCommands(ui, serverCmdUi cli.Ui, runOpts *RunOptions) {
 				Func:    "delete",
 			}, nil
 		},
+		"credential-stores delete vault": func() (cli.Command, error) {
+			return &credentialstorescmd.VaultCommand{
+				Command: base.NewCommand(ui),
+				Func:    "delete",
+			}, nil
+		},
+		"credential-stores import": func() (cli.Command, error) {
+			return &credentialstorescmd.VaultCommand{
+				Command: base.NewCommand(ui),
+				Func:    "import",
+			}, nil
+		},
 		"credential-stores list": func() (cli.Command, error) {
 			return &credentialstorescmd.Command{
 				Command: base.NewCommand(ui),
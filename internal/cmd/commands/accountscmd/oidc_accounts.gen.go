@@ -4,6 +4,7 @@ package accountscmd
 import (
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/hashicorp/boundary/api"
 	"github.com/hashicorp/boundary/api/accounts"
@@ -15,7 +16,7 @@ import (
 )
 
 func initOidcFlags() {
-	flagsOnce.Do(func() {
+	flagsOidcOnce.Do(func() {
 		extraFlags := extraOidcActionsFlagsMapFunc()
 		for k, v := range extraFlags {
 			flagsOidcMap[k] = append(flagsOidcMap[k], v...)
@@ -230,6 +231,8 @@ func (c *OidcCommand) Run(args []string) int {
 }
 
 var (
+	flagsOidcOnce = new(sync.Once)
+
 	extraOidcActionsFlagsMapFunc = func() map[string][]string { return nil }
 	extraOidcSynopsisFunc        = func(*OidcCommand) string { return "" }
 	extraOidcFlagsFunc           = func(*OidcCommand, *base.FlagSets, *base.FlagSet) {}
@@ -4,6 +4,7 @@ package authmethodscmd
 import (
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/hashicorp/boundary/api"
 	"github.com/hashicorp/boundary/api/authmethods"
@@ -15,7 +16,7 @@ import (
 )
 
 func initOidcFlags() {
-	flagsOnce.Do(func() {
+	flagsOidcOnce.Do(func() {
 		extraFlags := extraOidcActionsFlagsMapFunc()
 		for k, v := range extraFlags {
 			flagsOidcMap[k] = append(flagsOidcMap[k], v...)
@@ -245,6 +246,8 @@ func (c *OidcCommand) Run(args []string) int {
 }
 
 var (
+	flagsOidcOnce = new(sync.Once)
+
 	extraOidcActionsFlagsMapFunc = func() map[string][]string { return nil }
 	extraOidcSynopsisFunc        = func(*OidcCommand) string { return "" }
 	extraOidcFlagsFunc           = func(*OidcCommand, *base.FlagSets, *base.FlagSet) {}
@@ -4,6 +4,7 @@ package authmethodscmd
 import (
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/hashicorp/boundary/api"
 	"github.com/hashicorp/boundary/api/authmethods"
@@ -15,7 +16,7 @@ import (
 )
 
 func initPasswordFlags() {
-	flagsOnce.Do(func() {
+	flagsPasswordOnce.Do(func() {
 		extraFlags := extraPasswordActionsFlagsMapFunc()
 		for k, v := range extraFlags {
 			flagsPasswordMap[k] = append(flagsPasswordMap[k], v...)
@@ -235,6 +236,8 @@ func (c *PasswordCommand) Run(args []string) int {
 }
 
 var (
+	flagsPasswordOnce = new(sync.Once)
+
 	extraPasswordActionsFlagsMapFunc = func() map[string][]string { return nil }
 	extraPasswordSynopsisFunc        = func(*PasswordCommand) string { return "" }
 	extraPasswordFlagsFunc           = func(*PasswordCommand, *base.FlagSets, *base.FlagSet) {}
@@ -4,6 +4,7 @@ package credentiallibrariescmd
 import (
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/hashicorp/boundary/api"
 	"github.com/hashicorp/boundary/api/credentiallibraries"
@@ -15,7 +16,7 @@ import (
 )
 
 func initVaultFlags() {
-	flagsOnce.Do(func() {
+	flagsVaultOnce.Do(func() {
 		extraFlags := extraVaultActionsFlagsMapFunc()
 		for k, v := range extraFlags {
 			flagsVaultMap[k] = append(flagsVaultMap[k], v...)
@@ -232,6 +233,8 @@ func (c *VaultCommand) Run(args []string) int {
 }
 
 var (
+	flagsVaultOnce = new(sync.Once)
+
 	extraVaultActionsFlagsMapFunc = func() map[string][]string { return nil }
 	extraVaultSynopsisFunc        = func(*VaultCommand) string { return "" }
 	extraVaultFlagsFunc           = func(*VaultCommand, *base.FlagSets, *base.FlagSet) {}
@@ -8,8 +8,184 @@ import (
 	"github.com/hashicorp/boundary/api"
 	"github.com/hashicorp/boundary/api/credentialstores"
 	"github.com/hashicorp/boundary/internal/cmd/base"
+	"github.com/mattn/go-colorable"
+	"github.com/mitchellh/cli"
 )
 
+func init() {
+	extraActionsFlagsMapFunc = extraActionsFlagsMapFuncImpl
+	extraFlagsFunc = extraFlagsFuncImpl
+	extraFlagsHandlingFunc = extraFlagsHandlingFuncImpl
+	executeExtraActions = executeExtraActionsImpl
+	printCustomActionOutput = printCustomActionOutputImpl
+}
+
+const updatedAfterFlagName = "updated-after"
+
+// listUpdatedAfterFunc is the command's func while it lists the stores
+// updated after -updated-after.  The generated Run doesn't send a list
+// request for it, so the stores are listed and filtered by
+// executeExtraActionsImpl instead.
+const listUpdatedAfterFunc = "list-updated-after"
+
+type extraCmdVars struct {
+	flagUpdatedAfter string
+	flagQuiet        bool
+	flagNoColor      bool
+
+	// updatedAfter is parsed from -updated-after, and updatedAfterItems are
+	// the listed stores which were updated after it.
+	updatedAfter      time.Time
+	updatedAfterItems []*credentialstores.CredentialStore
+	updatedAfterCode  int
+}
+
+func extraActionsFlagsMapFuncImpl() map[string][]string {
+	return map[string][]string{
+		"read":   {quietFlagName, noColorFlagName},
+		"delete": {quietFlagName, noColorFlagName},
+		"list":   {updatedAfterFlagName, quietFlagName, noColorFlagName},
+	}
+}
+
+func extraFlagsFuncImpl(c *Command, _ *base.FlagSets, f *base.FlagSet) {
+	for _, name := range flagsMap[c.Func] {
+		switch name {
+		case updatedAfterFlagName:
+			f.StringVar(&base.StringVar{
+				Name:   updatedAfterFlagName,
+				Target: &c.flagUpdatedAfter,
+				Usage:  `Only list the stores which were updated after this time, which is an RFC 3339 timestamp such as "2021-06-01T12:00:00Z" or a date such as "2021-06-01" (midnight UTC). The stores are filtered after they're listed, so this can be used with -filter.`,
+			})
+		case quietFlagName:
+			f.BoolVar(quietFlag(&c.flagQuiet))
+		case noColorFlagName:
+			f.BoolVar(noColorFlag(&c.flagNoColor))
+		}
+	}
+}
+
+// quietFlag is the -quiet flag of the credential store commands.
+func quietFlag(target *bool) *base.BoolVar {
+	return &base.BoolVar{
+		Name:   quietFlagName,
+		Target: target,
+		Usage:  "Suppress the table or JSON output of a successful operation. The exit code still reflects the result, and errors are still printed.",
+	}
+}
+
+// noColorFlag is the -no-color flag of the credential store commands, which
+// disables color for a single command as BOUNDARY_CLI_NO_COLOR does for all of
+// them.
+func noColorFlag(target *bool) *base.BoolVar {
+	return &base.BoolVar{
+		Name:   noColorFlagName,
+		Target: target,
+		EnvVar: base.EnvBoundaryCLINoColor,
+		Usage:  "Disable ANSI color codes in the command output.",
+	}
+}
+
+// disableColor strips ANSI escape sequences from the ui's output, as is done
+// for every command when BOUNDARY_CLI_NO_COLOR is set.
+func disableColor(ui cli.Ui) {
+	bui, ok := ui.(*base.BoundaryUI)
+	if !ok {
+		return
+	}
+	u := bui.Ui
+	if cui, ok := u.(*cli.ColoredUi); ok {
+		u = cui.Ui
+	}
+	basic, ok := u.(*cli.BasicUi)
+	if !ok {
+		return
+	}
+	if basic.Writer != nil {
+		basic.Writer = colorable.NewNonColorable(basic.Writer)
+	}
+	if basic.ErrorWriter != nil {
+		basic.ErrorWriter = colorable.NewNonColorable(basic.ErrorWriter)
+	}
+}
+
+func extraFlagsHandlingFuncImpl(c *Command, _ *base.FlagSets, _ *[]credentialstores.Option) bool {
+	if c.flagNoColor {
+		disableColor(c.UI)
+	}
+	if c.Func == "list" && c.flagUpdatedAfter != "" {
+		t, err := parseUpdatedAfter(c.flagUpdatedAfter)
+		if err != nil {
+			c.PrintCliError(err)
+			return false
+		}
+		c.updatedAfter = t
+		c.Func = listUpdatedAfterFunc
+	}
+	return true
+}
+
+// parseUpdatedAfter parses the value of -updated-after, which is either an
+// RFC 3339 timestamp or a date.
+func parseUpdatedAfter(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("Error parsing -%s: %q isn't an RFC 3339 timestamp (such as 2021-06-01T12:00:00Z) or a date (such as 2021-06-01)", updatedAfterFlagName, v)
+}
+
+func executeExtraActionsImpl(c *Command, origResult api.GenericResult, origError error, csClient *credentialstores.Client, _ uint32, opts []credentialstores.Option) (api.GenericResult, error) {
+	if c.Func != listUpdatedAfterFunc {
+		return origResult, origError
+	}
+	c.Func = "list"
+	result, err := csClient.List(c.Context, c.FlagScopeId, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.updatedAfterItems = []*credentialstores.CredentialStore{}
+	for _, item := range result.Items {
+		if item.UpdatedTime.After(c.updatedAfter) {
+			c.updatedAfterItems = append(c.updatedAfterItems, item)
+		}
+	}
+	c.updatedAfterCode = result.GetResponse().HttpResponse().StatusCode
+	return nil, nil
+}
+
+func printCustomActionOutputImpl(c *Command) (bool, error) {
+	// when quiet, the exit code is the only output of a successful operation
+	if c.flagQuiet {
+		return true, nil
+	}
+	if c.updatedAfterItems == nil {
+		return false, nil
+	}
+	switch base.Format(c.UI) {
+	case "json":
+		// the items are filtered, so the response's body can't be printed
+		// as it is by PrintJsonItems
+		b, err := base.JsonFormatter{}.Format(struct {
+			StatusCode int                                 `json:"status_code"`
+			Items      []*credentialstores.CredentialStore `json:"items"`
+		}{
+			StatusCode: c.updatedAfterCode,
+			Items:      c.updatedAfterItems,
+		})
+		if err != nil {
+			return false, fmt.Errorf("Error formatting as JSON: %w", err)
+		}
+		c.UI.Output(string(b))
+	default:
+		c.UI.Output(fmt.Sprintf("Credential stores updated after %s:", c.updatedAfter.Format(time.RFC3339)))
+		c.UI.Output(c.printListTable(c.updatedAfterItems))
+	}
+	return true, nil
+}
+
 func (c *Command) extraHelpFunc(helpMap map[string]func() string) string {
 	var helpStr string
 	switch c.Func {
@@ -114,32 +290,62 @@ func (c *Command) printListTable(items []*credentialstores.CredentialStore) stri
 	return base.WrapForHelpText(output)
 }
 
+// itemTableColumns are the columns of the item table which can be selected
+// with -table-columns, in the order they're printed.
+var itemTableColumns = []string{
+	"id",
+	"version",
+	"created",
+	"updated",
+	"name",
+	"description",
+	"type",
+	"scope",
+	"authorized-actions",
+	"authorized-collection-actions",
+	"attributes",
+}
+
 func printItemTable(result api.GenericResult) string {
+	return printItemTableColumns(result, itemTableColumns)
+}
+
+// printItemTableColumns prints the item table with only the columns, which
+// must be in itemTableColumns.
+func printItemTableColumns(result api.GenericResult, columns []string) string {
 	item := result.GetItem().(*credentialstores.CredentialStore)
+	show := make(map[string]bool, len(columns))
+	for _, c := range columns {
+		show[c] = true
+	}
 	nonAttributeMap := map[string]interface{}{}
-	if item.Id != "" {
+	if show["id"] && item.Id != "" {
 		nonAttributeMap["ID"] = item.Id
 	}
-	if item.Version != 0 {
+	if show["version"] && item.Version != 0 {
 		nonAttributeMap["Version"] = item.Version
 	}
-	if !item.CreatedTime.IsZero() {
+	if show["created"] && !item.CreatedTime.IsZero() {
 		nonAttributeMap["Created Time"] = item.CreatedTime.Local().Format(time.RFC1123)
 	}
-	if !item.UpdatedTime.IsZero() {
+	if show["updated"] && !item.UpdatedTime.IsZero() {
 		nonAttributeMap["Updated Time"] = item.UpdatedTime.Local().Format(time.RFC1123)
 	}
-	if item.Name != "" {
+	if show["name"] && item.Name != "" {
 		nonAttributeMap["Name"] = item.Name
 	}
-	if item.Description != "" {
+	if show["description"] && item.Description != "" {
 		nonAttributeMap["Description"] = item.Description
 	}
-	if item.Type != "" {
+	if show["type"] && item.Type != "" {
 		nonAttributeMap["Type"] = item.Type
 	}
 
-	maxLength := base.MaxAttributesLength(nonAttributeMap, item.Attributes, keySubstMap)
+	var attributes map[string]interface{}
+	if show["attributes"] {
+		attributes = item.Attributes
+	}
+	maxLength := base.MaxAttributesLength(nonAttributeMap, attributes, keySubstMap)
 
 	ret := []string{
 		"",
@@ -147,7 +353,7 @@ func printItemTable(result api.GenericResult) string {
 		base.WrapMap(2, maxLength+2, nonAttributeMap),
 	}
 
-	if item.Scope != nil {
+	if show["scope"] && item.Scope != nil {
 		ret = append(ret,
 			"",
 			"  Scope:",
@@ -155,7 +361,7 @@ func printItemTable(result api.GenericResult) string {
 		)
 	}
 
-	if len(item.AuthorizedActions) > 0 {
+	if show["authorized-actions"] && len(item.AuthorizedActions) > 0 {
 		ret = append(ret,
 			"",
 			"  Authorized Actions:",
@@ -163,7 +369,7 @@ func printItemTable(result api.GenericResult) string {
 		)
 	}
 
-	if len(item.AuthorizedCollectionActions) > 0 {
+	if show["authorized-collection-actions"] && len(item.AuthorizedCollectionActions) > 0 {
 		keys := make([]string, 0, len(item.AuthorizedCollectionActions))
 		for k := range item.AuthorizedCollectionActions {
 			keys = append(keys, k)
@@ -180,11 +386,11 @@ func printItemTable(result api.GenericResult) string {
 		}
 	}
 
-	if len(item.Attributes) > 0 {
+	if len(attributes) > 0 {
 		ret = append(ret,
 			"",
 			"  Attributes:",
-			base.WrapMap(4, maxLength, item.Attributes),
+			base.WrapMap(4, maxLength, attributes),
 		)
 	}
 
@@ -0,0 +1,184 @@
+package credentialstorescmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/hashicorp/boundary/internal/cmd/base"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testListBody = `{"items": [
+	{"id": "csvlt_old", "type": "vault", "updated_time": "2021-05-01T12:00:00Z"},
+	{"id": "csvlt_new", "type": "vault", "updated_time": "2021-07-01T12:00:00Z"}
+]}`
+
+func TestCommand_updatedAfter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(testListBody))
+	}))
+	t.Cleanup(srv.Close)
+	args := []string{
+		"-addr", srv.URL,
+		"-keyring-type", "none",
+		"-scope-id", "p_1234567890",
+	}
+
+	t.Run("json", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		ui := cli.NewMockUi()
+		c := &Command{Command: base.NewCommand(&base.BoundaryUI{Ui: ui, Format: "json"}), Func: "list"}
+		require.Equal(base.CommandSuccess, c.Run(append(args, "-updated-after", "2021-06-01T00:00:00Z")), ui.ErrorWriter.String())
+
+		var got struct {
+			StatusCode int `json:"status_code"`
+			Items      []struct {
+				Id string `json:"id"`
+			} `json:"items"`
+		}
+		require.NoError(json.Unmarshal(ui.OutputWriter.Bytes(), &got))
+		assert.Equal(http.StatusOK, got.StatusCode)
+		require.Len(got.Items, 1)
+		assert.Equal("csvlt_new", got.Items[0].Id)
+	})
+	t.Run("table", func(t *testing.T) {
+		assert := assert.New(t)
+		ui := cli.NewMockUi()
+		c := &Command{Command: base.NewCommand(ui), Func: "list"}
+		assert.Equal(base.CommandSuccess, c.Run(append(args, "-updated-after", "2021-06-01")))
+
+		out := ui.OutputWriter.String()
+		assert.Contains(out, "Credential stores updated after 2021-06-01T00:00:00Z:")
+		assert.Contains(out, "csvlt_new")
+		assert.NotContains(out, "csvlt_old")
+	})
+	t.Run("none", func(t *testing.T) {
+		assert := assert.New(t)
+		ui := cli.NewMockUi()
+		c := &Command{Command: base.NewCommand(ui), Func: "list"}
+		assert.Equal(base.CommandSuccess, c.Run(append(args, "-updated-after", "2021-08-01")))
+		assert.Contains(ui.OutputWriter.String(), "No credential store found")
+	})
+	t.Run("unfiltered", func(t *testing.T) {
+		assert := assert.New(t)
+		ui := cli.NewMockUi()
+		c := &Command{Command: base.NewCommand(ui), Func: "list"}
+		assert.Equal(base.CommandSuccess, c.Run(args))
+
+		out := ui.OutputWriter.String()
+		assert.NotContains(out, "updated after")
+		assert.Contains(out, "csvlt_old")
+		assert.Contains(out, "csvlt_new")
+	})
+	t.Run("invalid", func(t *testing.T) {
+		assert := assert.New(t)
+		ui := cli.NewMockUi()
+		c := &Command{Command: base.NewCommand(ui), Func: "list"}
+		assert.Equal(base.CommandUserError, c.Run(append(args, "-updated-after", "yesterday")))
+		assert.Contains(ui.ErrorWriter.String(), `"yesterday" isn't an RFC 3339 timestamp`)
+	})
+}
+
+func TestCommand_quiet(t *testing.T) {
+	// the controller succeeds for csvlt_found and fails for anything else
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/v1/credential-stores" && r.URL.Query().Get("scope_id") == "p_1234567890":
+			_, _ = w.Write([]byte(testListBody))
+		case strings.HasSuffix(r.URL.Path, "/csvlt_found"):
+			_, _ = w.Write([]byte(`{"id":"csvlt_found","type":"vault","version":1}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"kind":"NotFound","message":"credential store not found"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	clientArgs := []string{
+		"-addr", srv.URL,
+		"-keyring-type", "none",
+		"-quiet",
+	}
+
+	tests := []struct {
+		name     string
+		fn       string
+		args     []string
+		wantCode int
+	}{
+		{name: "read", fn: "read", args: []string{"-id", "csvlt_found"}, wantCode: base.CommandSuccess},
+		{name: "read-fails", fn: "read", args: []string{"-id", "csvlt_missing"}, wantCode: base.CommandApiError},
+		{name: "delete", fn: "delete", args: []string{"-id", "csvlt_found"}, wantCode: base.CommandSuccess},
+		{name: "delete-fails", fn: "delete", args: []string{"-id", "csvlt_missing"}, wantCode: base.CommandApiError},
+		{name: "list", fn: "list", args: []string{"-scope-id", "p_1234567890"}, wantCode: base.CommandSuccess},
+		{name: "list-fails", fn: "list", args: []string{"-scope-id", "p_missing"}, wantCode: base.CommandApiError},
+	}
+	for _, tt := range tests {
+		for _, format := range []string{"table", "json"} {
+			t.Run(fmt.Sprintf("%s-%s", tt.name, format), func(t *testing.T) {
+				assert := assert.New(t)
+				ui := cli.NewMockUi()
+				c := &Command{Command: base.NewCommand(&base.BoundaryUI{Ui: ui, Format: format}), Func: tt.fn}
+				assert.Equal(tt.wantCode, c.Run(append(clientArgs, tt.args...)), ui.ErrorWriter.String())
+				assert.Empty(ui.OutputWriter.String())
+				if tt.wantCode != base.CommandSuccess {
+					// errors are printed even when quiet
+					assert.Contains(ui.ErrorWriter.String(), "credential store not found")
+				}
+			})
+		}
+	}
+}
+
+func TestCommand_noColor(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"kind":"NotFound","message":"credential store not found"}`))
+	}))
+	t.Cleanup(srv.Close)
+	args := []string{
+		"-addr", srv.URL,
+		"-keyring-type", "none",
+		"-id", "csvlt_missing",
+	}
+	// the ui is colored as it is by the CLI when BOUNDARY_CLI_NO_COLOR isn't
+	// set, and on a terminal
+	noColor := color.NoColor
+	color.NoColor = false
+	t.Cleanup(func() { color.NoColor = noColor })
+	newColoredUi := func(errOut *bytes.Buffer) *base.BoundaryUI {
+		return &base.BoundaryUI{
+			Ui: &cli.ColoredUi{
+				ErrorColor: cli.UiColorRed,
+				Ui:         &cli.BasicUi{Writer: new(bytes.Buffer), ErrorWriter: errOut},
+			},
+			Format: "table",
+		}
+	}
+
+	t.Run("colored", func(t *testing.T) {
+		assert := assert.New(t)
+		errOut := new(bytes.Buffer)
+		c := &Command{Command: base.NewCommand(newColoredUi(errOut)), Func: "read"}
+		assert.Equal(base.CommandApiError, c.Run(args))
+		assert.Contains(errOut.String(), "\x1b[")
+	})
+	t.Run("no-color", func(t *testing.T) {
+		assert := assert.New(t)
+		errOut := new(bytes.Buffer)
+		c := &Command{Command: base.NewCommand(newColoredUi(errOut)), Func: "read"}
+		assert.Equal(base.CommandApiError, c.Run(append(args, "-no-color")))
+		assert.Contains(errOut.String(), "credential store not found")
+		assert.NotContains(errOut.String(), "\x1b[")
+	})
+}
@@ -4,6 +4,7 @@ package credentialstorescmd
 import (
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/hashicorp/boundary/api"
 	"github.com/hashicorp/boundary/api/credentialstores"
@@ -15,7 +16,7 @@ import (
 )
 
 func initVaultFlags() {
-	flagsOnce.Do(func() {
+	flagsVaultOnce.Do(func() {
 		extraFlags := extraVaultActionsFlagsMapFunc()
 		for k, v := range extraFlags {
 			flagsVaultMap[k] = append(flagsVaultMap[k], v...)
@@ -237,6 +238,8 @@ func (c *VaultCommand) Run(args []string) int {
 }
 
 var (
+	flagsVaultOnce = new(sync.Once)
+
 	extraVaultActionsFlagsMapFunc = func() map[string][]string { return nil }
 	extraVaultSynopsisFunc        = func(*VaultCommand) string { return "" }
 	extraVaultFlagsFunc           = func(*VaultCommand, *base.FlagSets, *base.FlagSet) {}
@@ -1,15 +1,28 @@
 package credentialstorescmd
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/boundary/api"
 	"github.com/hashicorp/boundary/api/credentialstores"
+	"github.com/hashicorp/boundary/api/scopes"
 	"github.com/hashicorp/boundary/internal/cmd/base"
+	"github.com/hashicorp/boundary/internal/types/scope"
 	"github.com/hashicorp/go-secure-stdlib/parseutil"
+	"github.com/hashicorp/go-secure-stdlib/strutil"
 )
 
 func init() {
 	extraVaultFlagsFunc = extraVaultFlagsFuncImpl
 	extraVaultActionsFlagsMapFunc = extraVaultActionsFlagsMapFuncImpl
 	extraVaultFlagsHandlingFunc = extraVaultFlagHandlingFuncImpl
+	extraVaultSynopsisFunc = extraVaultSynopsisFuncImpl
+	executeExtraVaultActions = executeExtraVaultActionsImpl
+	printCustomVaultActionOutput = printCustomVaultActionOutputImpl
 }
 
 const (
@@ -21,8 +34,56 @@ const (
 	vaultTokenFlagName           = "vault-token"
 	clientCertificateFlagName    = "vault-client-certificate"
 	clientCertificateKeyFlagName = "vault-client-certificate-key"
+	quietFlagName                = "quiet"
+	noColorFlagName              = "no-color"
+	allFlagName                  = "all"
+	yesFlagName                  = "yes"
+	attrFlagName                 = "attr"
+	scopeNameFlagName            = "scope-name"
+	recursiveFlagName            = "recursive"
+	localPreflightFlagName       = "local-preflight"
+	testConnectionFlagName       = "test-connection"
+	tableColumnsFlagName         = "table-columns"
+	showOptionsFlagName          = "show-options"
+	fileFlagName                 = "file"
+	dryRunFlagName               = "dry-run"
 )
 
+// showOptionsFunc is the command's func while it shows the options of a
+// create (see -show-options).  The generated Run only sends a create request
+// for the create func, so switching to it builds the request without sending
+// it.
+const showOptionsFunc = "show-options"
+
+// testConnectionFunc is the command's func while the controller tests the
+// connection of a create (see -test-connection).  Like showOptionsFunc, it
+// keeps the generated Run from sending the create, which is only sent once the
+// test has passed.
+const testConnectionFunc = "test-connection"
+
+// maskedAttrs are the attributes whose values are masked by -show-options.
+var maskedAttrs = []string{"token", "ca_cert", "client_certificate", "client_certificate_key"}
+
+// maskedValue replaces the value of a masked attribute in the output of
+// -show-options.
+const maskedValue = "<masked>"
+
+// attrKeyRegexp is the format of the keys accepted by -attr.
+var attrKeyRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// dedicatedAttrFlags maps the attributes which have a dedicated flag to the
+// name of that flag.  These can't be set with -attr.
+var dedicatedAttrFlags = map[string]string{
+	"address":                addressFlagName,
+	"namespace":              namespaceFlagName,
+	"ca_cert":                vaultCaCertFlagName,
+	"tls_server_name":        tlsServerNameFlagName,
+	"tls_skip_verify":        tlsSkipVerifyFlagName,
+	"token":                  vaultTokenFlagName,
+	"client_certificate":     clientCertificateFlagName,
+	"client_certificate_key": clientCertificateKeyFlagName,
+}
+
 type extraVaultCmdVars struct {
 	flagAddress       string
 	flagNamespace     string
@@ -32,26 +93,103 @@ type extraVaultCmdVars struct {
 	flagClientCertKey string
 	flagTlsServerName string
 	flagTlsSkipVerify bool
+	flagQuiet         bool
+	flagNoColor       bool
+	flagAll           bool
+	flagYes           bool
+	flagAttrs         []string
+	flagTableColumns  string
+
+	// flagLocalPreflight is -local-preflight for a create.
+	flagLocalPreflight bool
+
+	// flagTestConnection is -test-connection for a create.
+	flagTestConnection bool
+
+	// flagScopeNameRecursive is -recursive for a create, which only affects
+	// how -scope-name is resolved.
+	flagScopeNameRecursive bool
+
+	// flagShowOptions is -show-options for a create, and showOptions is the
+	// create request's body it prints, with the secrets masked.
+	flagShowOptions bool
+	showOptions     map[string]interface{}
+
+	// tableColumns are the item table columns parsed from -table-columns,
+	// and tableResult is the result printed with them.
+	tableColumns []string
+	tableResult  api.GenericResult
+
+	// scopeIdsByName caches the scope ids resolved from -scope-name for the
+	// invocation of the command.
+	scopeIdsByName map[string]string
+
+	deleteAllResult *deleteAllResult
+
+	// flagFile and flagDryRun are -file and -dry-run for an import, and
+	// importDefinitions are the store definitions read from the file.
+	flagFile          string
+	flagDryRun        bool
+	importDefinitions []storeDefinition
+	importResult      *importResult
+}
+
+// deleteAllResult is the outcome of deleting all vault credential stores in a
+// scope.
+type deleteAllResult struct {
+	Deleted []string           `json:"deleted"`
+	Failed  []deleteAllFailure `json:"failed"`
+}
+
+type deleteAllFailure struct {
+	Id    string `json:"id"`
+	Error string `json:"error"`
 }
 
 func extraVaultActionsFlagsMapFuncImpl() map[string][]string {
+	vaultFlags := []string{
+		addressFlagName,
+		namespaceFlagName,
+		vaultCaCertFlagName,
+		tlsServerNameFlagName,
+		tlsSkipVerifyFlagName,
+		vaultTokenFlagName,
+		clientCertificateFlagName,
+		clientCertificateKeyFlagName,
+		attrFlagName,
+		quietFlagName,
+		noColorFlagName,
+		tableColumnsFlagName,
+	}
 	flags := map[string][]string{
-		"create": {
-			addressFlagName,
-			namespaceFlagName,
-			vaultCaCertFlagName,
-			tlsServerNameFlagName,
-			tlsSkipVerifyFlagName,
-			vaultTokenFlagName,
-			clientCertificateFlagName,
-			clientCertificateKeyFlagName,
-		},
-	}
-	flags["update"] = flags["create"]
+		"create": append([]string{scopeNameFlagName, localPreflightFlagName, testConnectionFlagName, showOptionsFlagName}, vaultFlags...),
+		"update": vaultFlags,
+	}
+	flags["delete"] = []string{
+		"scope-id",
+		"recursive",
+		allFlagName,
+		yesFlagName,
+		quietFlagName,
+		noColorFlagName,
+	}
+	flags[importFunc] = []string{
+		"scope-id",
+		fileFlagName,
+		dryRunFlagName,
+		quietFlagName,
+		noColorFlagName,
+	}
 	return flags
 }
 
-func extraVaultFlagsFuncImpl(c *VaultCommand, set *base.FlagSets, _ *base.FlagSet) {
+func extraVaultFlagsFuncImpl(c *VaultCommand, set *base.FlagSets, cmdFlags *base.FlagSet) {
+	// the common -id flag is registered without a completion, so the ids of
+	// the scope's stores are predicted here
+	if strutil.StrListContains(flagsVaultMap[c.Func], "id") {
+		set.Completions()["-id"] = &storeIdPredictor{c: c}
+	}
+
 	f := set.NewFlagSet("Vault Credential Store Options")
 
 	for _, name := range flagsVaultMap[c.Func] {
@@ -66,19 +204,19 @@ func extraVaultFlagsFuncImpl(c *VaultCommand, set *base.FlagSets, _ *base.FlagSe
 			f.StringVar(&base.StringVar{
 				Name:   namespaceFlagName,
 				Target: &c.flagNamespace,
-				Usage:  "The vault namespace the store should use.",
+				Usage:  `The Vault Enterprise namespace the store should use, such as "ns1/ns2". It's required when the store's token was issued in a namespace. Use "null" with update to remove the store's namespace.`,
 			})
 		case vaultCaCertFlagName:
 			f.StringVar(&base.StringVar{
 				Name:   vaultCaCertFlagName,
 				Target: &c.flagCaCert,
-				Usage:  "The CA Cert to use when connecting to vault. This can be the value itself, refer to a file on disk (file://) from which the value will be read, or an env var (env://) from which the value will be read.",
+				Usage:  `The CA Cert to use when connecting to vault. This can be the value itself, refer to a file on disk (file://) from which the value will be read, or an env var (env://) from which the value will be read. Use "null" with update to remove the store's CA cert.`,
 			})
 		case tlsServerNameFlagName:
 			f.StringVar(&base.StringVar{
 				Name:   tlsServerNameFlagName,
 				Target: &c.flagTlsServerName,
-				Usage:  `Name to use as the SNI host when connecting via TLS.`,
+				Usage:  `Name to use as the SNI host when connecting via TLS. Use "null" with update to remove the store's TLS server name.`,
 			})
 		case tlsSkipVerifyFlagName:
 			f.BoolVar(&base.BoolVar{
@@ -96,19 +234,122 @@ func extraVaultFlagsFuncImpl(c *VaultCommand, set *base.FlagSets, _ *base.FlagSe
 			f.StringVar(&base.StringVar{
 				Name:   clientCertificateFlagName,
 				Target: &c.flagClientCert,
-				Usage:  "The client certificate to use when boundary connects to vault for this store. This can be the value itself, refer to a file on disk (file://) from which the value will be read, or an env var (env://) from which the value will be read.",
+				Usage:  `The client certificate to use when boundary connects to vault for this store. This can be the value itself, refer to a file on disk (file://) from which the value will be read, or an env var (env://) from which the value will be read. Use "null" with update to remove the store's client certificate.`,
 			})
 		case clientCertificateKeyFlagName:
 			f.StringVar(&base.StringVar{
 				Name:   clientCertificateKeyFlagName,
 				Target: &c.flagClientCertKey,
-				Usage:  `The client certificate's private key to use when boundary connects to vault for this store. This can be the value itself, refer to a file on disk (file://) from which the value will be read, or an env var (env://) from which the value will be read.`,
+				Usage:  `The client certificate's private key to use when boundary connects to vault for this store. This can be the value itself, refer to a file on disk (file://) from which the value will be read, or an env var (env://) from which the value will be read. Use "null" with update to remove the store's client certificate key.`,
+			})
+		case attrFlagName:
+			f.StringSliceVar(&base.StringSliceVar{
+				Name:   attrFlagName,
+				Target: &c.flagAttrs,
+				Usage:  `An attribute of the store, in the form key=value, for attributes which don't have a dedicated flag. Values which are valid JSON (numbers, booleans, objects, arrays) are sent as such, anything else is sent as a string. May be specified multiple times.`,
+			})
+		case quietFlagName:
+			cmdFlags.BoolVar(quietFlag(&c.flagQuiet))
+		case allFlagName:
+			cmdFlags.BoolVar(&base.BoolVar{
+				Name:   allFlagName,
+				Target: &c.flagAll,
+				Usage:  "Delete all vault-type credential stores in the scope. Required, as a single store is deleted with \"credential-stores delete -id\".",
+			})
+		case yesFlagName:
+			cmdFlags.BoolVar(&base.BoolVar{
+				Name:   yesFlagName,
+				Target: &c.flagYes,
+				Usage:  "Skip the confirmation prompt when deleting all stores.",
+			})
+		case localPreflightFlagName:
+			f.BoolVar(&base.BoolVar{
+				Name:   localPreflightFlagName,
+				Target: &c.flagLocalPreflight,
+				Usage:  "Look up -vault-token with the Vault server of the other Vault flags before creating the store, printing the result and Vault's diagnostic. The store isn't created if the lookup fails. This is only a local preflight: the lookup is made from this host, not the controller, so it doesn't prove the controller can reach Vault. Only the flags are used; VAULT_* environment variables are ignored.",
+			})
+		case testConnectionFlagName:
+			f.BoolVar(&base.BoolVar{
+				Name:   testConnectionFlagName,
+				Target: &c.flagTestConnection,
+				Usage:  "Have the controller connect to Vault with the store's Vault flags before creating the store, printing the result and the controller's diagnostic. The controller checks the token as it would for the create, without renewing it. The store isn't created if the test fails.",
 			})
+		case tableColumnsFlagName:
+			cmdFlags.StringVar(&base.StringVar{
+				Name:   tableColumnsFlagName,
+				Target: &c.flagTableColumns,
+				Usage:  fmt.Sprintf("A comma separated list of the columns to print in table output, such as \"id,name,updated\". The columns are: %s. JSON output always includes every field.", strings.Join(itemTableColumns, ", ")),
+			})
+		case showOptionsFlagName:
+			cmdFlags.BoolVar(&base.BoolVar{
+				Name:   showOptionsFlagName,
+				Target: &c.flagShowOptions,
+				Usage:  "Print the options the store would be created with, as they'd be sent to the controller once -attr and the other flags are applied, without creating it. The token, certificates and key are masked.",
+			})
+		case fileFlagName:
+			cmdFlags.StringVar(&base.StringVar{
+				Name:   fileFlagName,
+				Target: &c.flagFile,
+				Usage:  `The path of a JSON file holding an array of the stores to import. Each store is an object with "type" (defaults to "vault"), "scope_id" (defaults to -scope-id), "name", "description" and "attributes" fields.`,
+			})
+		case dryRunFlagName:
+			cmdFlags.BoolVar(&base.BoolVar{
+				Name:   dryRunFlagName,
+				Target: &c.flagDryRun,
+				Usage:  "Validate the stores of -file without creating them.",
+			})
+		case noColorFlagName:
+			cmdFlags.BoolVar(noColorFlag(&c.flagNoColor))
 		}
 	}
+
+	// This isn't the common -recursive flag, since that would be sent with
+	// the create request.
+	if strutil.StrListContains(flagsVaultMap[c.Func], scopeNameFlagName) {
+		f.BoolVar(&base.BoolVar{
+			Name:   recursiveFlagName,
+			Target: &c.flagScopeNameRecursive,
+			Usage:  "Search all of the scopes beneath -scope-id, rather than only its direct children, for the scope named by -scope-name.",
+		})
+	}
 }
 
 func extraVaultFlagHandlingFuncImpl(c *VaultCommand, f *base.FlagSets, opts *[]credentialstores.Option) bool {
+	if c.flagNoColor {
+		disableColor(c.UI)
+	}
+	switch c.Func {
+	case "delete":
+		return c.confirmDeleteAll()
+	case importFunc:
+		return c.readImportFile()
+	}
+	if c.flagTableColumns != "" {
+		columns, err := parseTableColumns(c.flagTableColumns)
+		if err != nil {
+			c.PrintCliError(err)
+			return false
+		}
+		c.tableColumns = columns
+	}
+	if c.FlagScopeName != "" && strutil.StrListContains(flagsVaultMap[c.Func], scopeNameFlagName) {
+		scopeId, err := c.resolveScopeName(c.FlagScopeName)
+		if err != nil {
+			c.PrintCliError(err)
+			return false
+		}
+		c.FlagScopeId = scopeId
+	}
+	// The attributes option replaces the attributes map, so it must come
+	// before the options of the dedicated flags, which add to it.
+	if len(c.flagAttrs) > 0 {
+		attrs, err := parseAttrFlags(c.flagAttrs)
+		if err != nil {
+			c.PrintCliError(err)
+			return false
+		}
+		*opts = append(*opts, credentialstores.WithAttributes(attrs))
+	}
 	switch c.flagAddress {
 	case "":
 	default:
@@ -117,8 +358,15 @@ func extraVaultFlagHandlingFuncImpl(c *VaultCommand, f *base.FlagSets, opts *[]c
 	switch c.flagNamespace {
 	case "":
 	case "null":
+		if !c.checkNullFlag(namespaceFlagName, "namespace") {
+			return false
+		}
 		*opts = append(*opts, credentialstores.DefaultVaultCredentialStoreNamespace())
 	default:
+		if err := validateVaultNamespace(c.flagNamespace); err != nil {
+			c.PrintCliError(err)
+			return false
+		}
 		*opts = append(*opts, credentialstores.WithVaultCredentialStoreNamespace(c.flagNamespace))
 	}
 	switch c.flagVaultToken {
@@ -129,14 +377,30 @@ func extraVaultFlagHandlingFuncImpl(c *VaultCommand, f *base.FlagSets, opts *[]c
 	switch c.flagCaCert {
 	case "":
 	case "null":
+		if !c.checkNullFlag(vaultCaCertFlagName, "CA certificate") {
+			return false
+		}
 		*opts = append(*opts, credentialstores.DefaultVaultCredentialStoreCaCert())
 	default:
 		cer, _ := parseutil.ParsePath(c.flagCaCert)
 		*opts = append(*opts, credentialstores.WithVaultCredentialStoreCaCert(cer))
 	}
+	switch c.flagTlsServerName {
+	case "":
+	case "null":
+		if !c.checkNullFlag(tlsServerNameFlagName, "TLS server name") {
+			return false
+		}
+		*opts = append(*opts, credentialstores.DefaultVaultCredentialStoreTlsServerName())
+	default:
+		*opts = append(*opts, credentialstores.WithVaultCredentialStoreTlsServerName(c.flagTlsServerName))
+	}
 	switch c.flagClientCert {
 	case "":
 	case "null":
+		if !c.checkNullFlag(clientCertificateFlagName, "client certificate") {
+			return false
+		}
 		*opts = append(*opts, credentialstores.DefaultVaultCredentialStoreClientCertificate())
 	default:
 		cer, _ := parseutil.ParsePath(c.flagClientCert)
@@ -145,18 +409,395 @@ func extraVaultFlagHandlingFuncImpl(c *VaultCommand, f *base.FlagSets, opts *[]c
 	switch c.flagClientCertKey {
 	case "":
 	case "null":
+		if !c.checkNullFlag(clientCertificateKeyFlagName, "client certificate key") {
+			return false
+		}
 		*opts = append(*opts, credentialstores.DefaultVaultCredentialStoreClientCertificateKey())
 	default:
-		cer, _ := parseutil.ParsePath(c.flagClientCert)
-		*opts = append(*opts, credentialstores.WithVaultCredentialStoreClientCertificateKey(cer))
+		key, _ := parseutil.ParsePath(c.flagClientCertKey)
+		*opts = append(*opts, credentialstores.WithVaultCredentialStoreClientCertificateKey(key))
 	}
 	if c.flagTlsSkipVerify {
 		*opts = append(*opts, credentialstores.WithVaultCredentialStoreTlsSkipVerify(c.flagTlsSkipVerify))
 	}
+	if c.flagShowOptions {
+		for _, flag := range []struct {
+			name string
+			set  bool
+		}{
+			{localPreflightFlagName, c.flagLocalPreflight},
+			{testConnectionFlagName, c.flagTestConnection},
+		} {
+			if flag.set {
+				c.PrintCliError(fmt.Errorf("-%s can't be used with -%s, since the store isn't created", showOptionsFlagName, flag.name))
+				return false
+			}
+		}
+		c.Func = showOptionsFunc
+		return true
+	}
+	if c.flagLocalPreflight && !c.localPreflight() {
+		return false
+	}
+	if c.flagTestConnection {
+		c.Func = testConnectionFunc
+	}
+
+	return true
+}
+
+// checkNullFlag returns true when the "null" value of the flag, which
+// removes the field from an existing store, can be used.  It's only valid with
+// update, since a new store doesn't have a value to remove.
+func (c *VaultCommand) checkNullFlag(flagName, field string) bool {
+	if c.Func == "update" {
+		return true
+	}
+	c.PrintCliError(fmt.Errorf("-%s null removes an existing store's %s, so it can only be used with update", flagName, field))
+	return false
+}
+
+// resolveScopeName returns the id of the scope with the name.  The scope is
+// searched for in the children of -scope-id, or in all of the scopes beneath
+// it when -recursive is set.  An error is returned if no scope, or more than
+// one scope, has the name.
+func (c *VaultCommand) resolveScopeName(name string) (string, error) {
+	if id, ok := c.scopeIdsByName[name]; ok {
+		return id, nil
+	}
+	parentId := c.FlagScopeId
+	if parentId == "" {
+		parentId = scope.Global.String()
+	}
+	client, err := c.Client()
+	if err != nil {
+		return "", fmt.Errorf("Error creating API client: %w", err)
+	}
+	// WithRecursive lists recursively whatever its value, so it's only
+	// passed for -recursive
+	var opts []scopes.Option
+	if c.flagScopeNameRecursive {
+		opts = append(opts, scopes.WithRecursive(true))
+	}
+	result, err := scopes.NewClient(client).List(c.Context, parentId, opts...)
+	if err != nil {
+		return "", fmt.Errorf("Error listing the scopes of %s to resolve -%s: %w", parentId, scopeNameFlagName, err)
+	}
+	var matches []*scopes.Scope
+	for _, s := range result.GetItems().([]*scopes.Scope) {
+		if s.Name == name {
+			matches = append(matches, s)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		where := fmt.Sprintf("the child scopes of %s", parentId)
+		if c.flagScopeNameRecursive {
+			where = fmt.Sprintf("the scopes beneath %s", parentId)
+		}
+		return "", fmt.Errorf("No scope named %q was found in %s", name, where)
+	case 1:
+	default:
+		found := make([]string, 0, len(matches))
+		for _, s := range matches {
+			found = append(found, fmt.Sprintf("%s (parent %s)", s.Id, s.ScopeId))
+		}
+		return "", fmt.Errorf("Scope name %q is ambiguous, it matches %s; pass its parent's id with -scope-id to disambiguate", name, strings.Join(found, ", "))
+	}
+	if c.scopeIdsByName == nil {
+		c.scopeIdsByName = make(map[string]string)
+	}
+	c.scopeIdsByName[name] = matches[0].Id
+	return matches[0].Id, nil
+}
+
+// parseTableColumns parses the comma separated columns passed with
+// -table-columns, returning an error for a column which isn't one of
+// itemTableColumns.
+func parseTableColumns(flagColumns string) ([]string, error) {
+	var columns []string
+	for _, col := range strings.Split(flagColumns, ",") {
+		col = strings.ToLower(strings.TrimSpace(col))
+		switch {
+		case col == "":
+			return nil, fmt.Errorf("Invalid -%s %q: column names must not be empty", tableColumnsFlagName, flagColumns)
+		case !strutil.StrListContains(itemTableColumns, col):
+			return nil, fmt.Errorf("Invalid -%s column %q: must be one of %s", tableColumnsFlagName, col, strings.Join(itemTableColumns, ", "))
+		case strutil.StrListContains(columns, col):
+			return nil, fmt.Errorf("Column %q was specified more than once in -%s", col, tableColumnsFlagName)
+		}
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+// validateVaultNamespace returns an error if the namespace isn't a valid
+// Vault namespace path: one or more names separated by slashes, optionally
+// followed by a trailing slash.
+func validateVaultNamespace(ns string) error {
+	for _, name := range strings.Split(strings.TrimSuffix(ns, "/"), "/") {
+		switch {
+		case name == "":
+			return fmt.Errorf("Invalid -%s %q: namespaces must not be empty", namespaceFlagName, ns)
+		case strings.ContainsAny(name, " \t\n"):
+			return fmt.Errorf("Invalid -%s %q: namespaces must not contain whitespace", namespaceFlagName, ns)
+		}
+	}
+	return nil
+}
+
+// parseAttrFlags parses the key=value pairs passed with -attr into an
+// attributes map.
+func parseAttrFlags(flagAttrs []string) (map[string]interface{}, error) {
+	attrs := make(map[string]interface{}, len(flagAttrs))
+	for _, kv := range flagAttrs {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("Invalid -%s %q: must be in the form key=value", attrFlagName, kv)
+		}
+		k, v := strings.TrimSpace(parts[0]), parts[1]
+		if !attrKeyRegexp.MatchString(k) {
+			return nil, fmt.Errorf("Invalid -%s key %q: must start with a lowercase letter and contain only lowercase letters, digits and underscores", attrFlagName, k)
+		}
+		if name, ok := dedicatedAttrFlags[k]; ok {
+			return nil, fmt.Errorf("Attribute %q can't be set with -%s, use -%s instead", k, attrFlagName, name)
+		}
+		if _, ok := attrs[k]; ok {
+			return nil, fmt.Errorf("Attribute %q was specified more than once", k)
+		}
+		var val interface{}
+		if err := json.Unmarshal([]byte(v), &val); err != nil {
+			val = v
+		}
+		attrs[k] = val
+	}
+	return attrs, nil
+}
+
+func extraVaultSynopsisFuncImpl(c *VaultCommand) string {
+	switch c.Func {
+	case "delete":
+		return "Delete all vault-type credential stores in a scope"
+	case importFunc:
+		return "Import credential stores from a file"
+	}
+	return ""
+}
 
+// confirmDeleteAll validates the flags of a delete and asks the user to
+// confirm it, unless -yes was passed.
+func (c *VaultCommand) confirmDeleteAll() bool {
+	if !c.flagAll {
+		c.PrintCliError(errors.New("-all is required; use \"boundary credential-stores delete -id\" to delete a single store"))
+		return false
+	}
+	if c.FlagScopeId == "" {
+		c.PrintCliError(errors.New("Scope ID must be passed in via -scope-id or BOUNDARY_SCOPE_ID"))
+		return false
+	}
+	if c.flagYes {
+		return true
+	}
+	scopes := c.FlagScopeId
+	if c.FlagRecursive {
+		scopes += " and its child scopes"
+	}
+	answer, err := c.UI.Ask(fmt.Sprintf("All vault-type credential stores in %s will be deleted. Type \"yes\" to continue:", scopes))
+	if err != nil {
+		c.PrintCliError(fmt.Errorf("Error reading confirmation: %w", err))
+		return false
+	}
+	if strings.TrimSpace(answer) != "yes" {
+		c.PrintCliError(errors.New("Delete canceled"))
+		return false
+	}
 	return true
 }
 
+func executeExtraVaultActionsImpl(c *VaultCommand, origResult api.GenericResult, origError error, csClient *credentialstores.Client, version uint32, opts []credentialstores.Option) (api.GenericResult, error) {
+	switch c.Func {
+	case "delete":
+		return c.deleteAll(csClient, opts)
+	case importFunc:
+		return c.importStores(csClient)
+	case showOptionsFunc:
+		return nil, c.resolveShowOptions(csClient, opts)
+	case testConnectionFunc:
+		if err := c.testConnection(csClient, opts); err != nil {
+			return nil, err
+		}
+		c.Func = "create"
+		origResult, origError = csClient.Create(c.Context, "vault", c.FlagScopeId, opts...)
+	}
+	if origError == nil {
+		// kept so printCustomVaultActionOutputImpl can print the table with
+		// only the columns of -table-columns
+		c.tableResult = origResult
+	}
+	return origResult, origError
+}
+
+// deleteAll lists the vault-type credential stores in the scope and deletes
+// each of them.  A summary is printed and an error is returned if any of the
+// deletes failed.
+func (c *VaultCommand) deleteAll(csClient *credentialstores.Client, opts []credentialstores.Option) (api.GenericResult, error) {
+	listResult, err := csClient.List(c.Context, c.FlagScopeId, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.deleteAllResult = &deleteAllResult{
+		Deleted: []string{},
+		Failed:  []deleteAllFailure{},
+	}
+	for _, cs := range listResult.GetItems().([]*credentialstores.CredentialStore) {
+		if cs.Type != "vault" {
+			continue
+		}
+		if _, err := csClient.Delete(c.Context, cs.Id); err != nil {
+			c.deleteAllResult.Failed = append(c.deleteAllResult.Failed, deleteAllFailure{Id: cs.Id, Error: err.Error()})
+			continue
+		}
+		c.deleteAllResult.Deleted = append(c.deleteAllResult.Deleted, cs.Id)
+	}
+	if !c.flagQuiet || len(c.deleteAllResult.Failed) > 0 {
+		c.printDeleteAllResult()
+	}
+	if n := len(c.deleteAllResult.Failed); n > 0 {
+		return nil, fmt.Errorf("%d of %d deletes failed", n, n+len(c.deleteAllResult.Deleted))
+	}
+	return nil, nil
+}
+
+// resolveShowOptions sets showOptions from the body of the create request
+// built from the options.  The request is built by a client which returns its
+// requests as curl strings, so it isn't sent.
+func (c *VaultCommand) resolveShowOptions(csClient *credentialstores.Client, opts []credentialstores.Option) error {
+	client := csClient.ApiClient().Clone()
+	client.SetOutputCurlString(true)
+	lastOutputStringError := api.LastOutputStringError
+	defer func() { api.LastOutputStringError = lastOutputStringError }()
+	_, createErr := credentialstores.NewClient(client).Create(c.Context, "vault", c.FlagScopeId, opts...)
+	var outErr *api.OutputStringError
+	if !errors.As(createErr, &outErr) {
+		if createErr == nil {
+			createErr = errors.New("the create request was sent")
+		}
+		return fmt.Errorf("unable to resolve the options: %w", createErr)
+	}
+	b, err := outErr.BodyBytes()
+	if err != nil {
+		return fmt.Errorf("unable to read the create request: %w", err)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(b, &body); err != nil {
+		return fmt.Errorf("unable to decode the create request: %w", err)
+	}
+	if attrs, ok := body["attributes"].(map[string]interface{}); ok {
+		for _, k := range maskedAttrs {
+			if v, ok := attrs[k]; ok && v != nil {
+				attrs[k] = maskedValue
+			}
+		}
+	}
+	c.showOptions = body
+	return nil
+}
+
+// printShowOptions prints the options resolved for -show-options.
+func (c *VaultCommand) printShowOptions() {
+	switch base.Format(c.UI) {
+	case "json":
+		b, err := json.Marshal(c.showOptions)
+		if err != nil {
+			c.PrintCliError(fmt.Errorf("Error formatting as JSON: %w", err))
+			return
+		}
+		c.UI.Output(string(b))
+	default:
+		nonAttributeMap := map[string]interface{}{}
+		for k, label := range map[string]string{
+			"scope_id":    "Scope ID",
+			"type":        "Type",
+			"name":        "Name",
+			"description": "Description",
+		} {
+			if v, ok := c.showOptions[k]; ok && v != nil {
+				nonAttributeMap[label] = v
+			}
+		}
+		attributes, _ := c.showOptions["attributes"].(map[string]interface{})
+		maxLength := base.MaxAttributesLength(nonAttributeMap, attributes, keySubstMap)
+		ret := []string{
+			"",
+			"Credential Store create options (the store was not created):",
+			base.WrapMap(2, maxLength+2, nonAttributeMap),
+		}
+		if len(attributes) > 0 {
+			ret = append(ret,
+				"",
+				"  Attributes:",
+				base.WrapMap(4, maxLength, attributes),
+			)
+		}
+		c.UI.Output(base.WrapForHelpText(ret))
+	}
+}
+
+func (c *VaultCommand) printDeleteAllResult() {
+	r := c.deleteAllResult
+	switch base.Format(c.UI) {
+	case "json":
+		b, err := json.Marshal(r)
+		if err != nil {
+			c.PrintCliError(fmt.Errorf("Error formatting as JSON: %w", err))
+			return
+		}
+		c.UI.Output(string(b))
+	default:
+		output := []string{
+			"",
+			"Delete summary:",
+			fmt.Sprintf("  Deleted:                 %d", len(r.Deleted)),
+			fmt.Sprintf("  Failed:                  %d", len(r.Failed)),
+		}
+		if len(r.Deleted) > 0 {
+			output = append(output, "", "  Deleted Credential Stores:")
+			for _, id := range r.Deleted {
+				output = append(output, fmt.Sprintf("    %s", id))
+			}
+		}
+		if len(r.Failed) > 0 {
+			output = append(output, "", "  Failed Credential Stores:")
+			for _, f := range r.Failed {
+				output = append(output, fmt.Sprintf("    %s: %s", f.Id, f.Error))
+			}
+		}
+		c.UI.Output(base.WrapForHelpText(output))
+	}
+}
+
+func printCustomVaultActionOutputImpl(c *VaultCommand) (bool, error) {
+	if c.Func == "delete" || c.Func == importFunc {
+		// the summary has already been printed while deleting or importing
+		return true, nil
+	}
+	if c.showOptions != nil {
+		// the options are the only output of -show-options, so they're
+		// printed even when quiet
+		c.printShowOptions()
+		return true, nil
+	}
+	// when quiet, the exit code is the only output of a successful operation
+	if c.flagQuiet {
+		return true, nil
+	}
+	if len(c.tableColumns) > 0 && c.tableResult != nil && base.Format(c.UI) == "table" {
+		c.UI.Output(printItemTableColumns(c.tableResult, c.tableColumns))
+		return true, nil
+	}
+	return false, nil
+}
+
 func (c *VaultCommand) extraVaultHelpFunc(helpMap map[string]func() string) string {
 	var helpStr string
 	switch c.Func {
@@ -168,6 +809,8 @@ func (c *VaultCommand) extraVaultHelpFunc(helpMap map[string]func() string) stri
 			"",
 			`    $ boundary credential-stores create vault -vault-address "http://localhost:8200" -vault-token "s.s0m3t0k3n"`,
 			"",
+			"  Pass -test-connection to have the controller check the store's Vault address and token before it's created, -local-preflight to check them from this host, or -show-options to print the options it would be created with, once -attr and the other flags are applied, without creating it.",
+			"",
 			"",
 		})
 
@@ -181,6 +824,32 @@ func (c *VaultCommand) extraVaultHelpFunc(helpMap map[string]func() string) stri
 			"",
 			"",
 		})
+
+	case "delete":
+		helpStr = base.WrapForHelpText([]string{
+			"Usage: boundary credential-stores delete vault -all [options] [args]",
+			"",
+			"  Delete all vault-type credential stores in a scope. A summary of the deleted stores and any failures is printed, and the command fails if any store could not be deleted. Example:",
+			"",
+			`    $ boundary credential-stores delete vault -all -scope-id p_1234567890 -yes`,
+			"",
+			"",
+		})
+
+	case importFunc:
+		helpStr = base.WrapForHelpText([]string{
+			"Usage: boundary credential-stores import -file <path> [options] [args]",
+			"",
+			"  Create each of the credential stores defined in a JSON file. A store which fails to be created doesn't stop the others from being created; a summary of the created stores and any failures is printed, and the command fails if any store could not be created. Pass -dry-run to validate the file without creating any stores. Example:",
+			"",
+			`    $ boundary credential-stores import -file stores.json -scope-id p_1234567890`,
+			"",
+			"  Where stores.json holds:",
+			"",
+			`    [{"name": "devops", "attributes": {"address": "https://vault.example.com:8200", "token": "s.s0m3t0k3n"}}]`,
+			"",
+			"",
+		})
 	}
 	return helpStr + c.Flags().Help()
 }
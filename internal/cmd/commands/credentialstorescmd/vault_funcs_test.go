@@ -0,0 +1,600 @@
+package credentialstorescmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/boundary/api"
+	"github.com/hashicorp/boundary/api/credentialstores"
+	"github.com/hashicorp/boundary/internal/cmd/base"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testUpdateAttributes sends an update with the options and returns the
+// attributes of the request's body.
+func testUpdateAttributes(t *testing.T, opts []credentialstores.Option) map[string]interface{} {
+	t.Helper()
+	require := require.New(t)
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"csvlt_1234567890","type":"vault","version":2}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := api.NewClient(nil)
+	require.NoError(err)
+	require.NoError(client.SetAddr(srv.URL))
+	_, err = credentialstores.NewClient(client).Update(context.Background(), "csvlt_1234567890", 1, opts...)
+	require.NoError(err)
+	attrs, _ := body["attributes"].(map[string]interface{})
+	return attrs
+}
+
+func TestVaultCommand_clearFields(t *testing.T) {
+	tests := []struct {
+		name          string
+		fn            string
+		cmd           extraVaultCmdVars
+		wantAttrs     map[string]interface{}
+		wantErrOutput string
+	}{
+		{
+			name: "set-on-create",
+			fn:   "create",
+			cmd: extraVaultCmdVars{
+				flagCaCert:        "ca-cert",
+				flagTlsServerName: "vault.example.com",
+				flagClientCert:    "client-cert",
+				flagClientCertKey: "client-cert-key",
+			},
+			wantAttrs: map[string]interface{}{
+				"ca_cert":                "ca-cert",
+				"tls_server_name":        "vault.example.com",
+				"client_certificate":     "client-cert",
+				"client_certificate_key": "client-cert-key",
+			},
+		},
+		{
+			name: "change-on-update",
+			fn:   "update",
+			cmd: extraVaultCmdVars{
+				flagNamespace:     "ns1",
+				flagClientCert:    "rotated-cert",
+				flagClientCertKey: "rotated-key",
+			},
+			wantAttrs: map[string]interface{}{
+				"namespace":              "ns1",
+				"client_certificate":     "rotated-cert",
+				"client_certificate_key": "rotated-key",
+			},
+		},
+		{
+			name: "clear-on-update",
+			fn:   "update",
+			cmd: extraVaultCmdVars{
+				flagNamespace:     "null",
+				flagCaCert:        "null",
+				flagTlsServerName: "null",
+				flagClientCert:    "null",
+				flagClientCertKey: "null",
+			},
+			wantAttrs: map[string]interface{}{
+				"namespace":              nil,
+				"ca_cert":                nil,
+				"tls_server_name":        nil,
+				"client_certificate":     nil,
+				"client_certificate_key": nil,
+			},
+		},
+		{
+			name:          "clear-ca-cert-on-create",
+			fn:            "create",
+			cmd:           extraVaultCmdVars{flagCaCert: "null"},
+			wantErrOutput: "-vault-ca-cert null removes an existing store's CA certificate, so it can only be used with update",
+		},
+		{
+			name:          "clear-client-cert-on-create",
+			fn:            "create",
+			cmd:           extraVaultCmdVars{flagClientCert: "null"},
+			wantErrOutput: "-vault-client-certificate null removes an existing store's client certificate, so it can only be used with update",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			ui := cli.NewMockUi()
+			c := &VaultCommand{Command: base.NewCommand(ui), Func: tt.fn, extraVaultCmdVars: tt.cmd}
+			var opts []credentialstores.Option
+			ok := extraVaultFlagHandlingFuncImpl(c, nil, &opts)
+			if tt.wantErrOutput != "" {
+				assert.False(ok)
+				assert.Contains(ui.ErrorWriter.String(), tt.wantErrOutput)
+				return
+			}
+			require.True(ok, ui.ErrorWriter.String())
+			assert.Equal(tt.wantAttrs, testUpdateAttributes(t, opts))
+		})
+	}
+}
+
+func TestVaultCommand_showOptions(t *testing.T) {
+	// the store must not be created, so the controller fails the test if
+	// it's called
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to the controller: %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+	args := []string{
+		"-addr", srv.URL,
+		"-keyring-type", "none",
+		"-scope-id", "p_1234567890",
+		"-name", "devops",
+		"-vault-address", "https://vault.example.com:8200",
+		"-vault-token", "s.s3cr3t",
+		"-vault-client-certificate", "client-cert",
+		"-vault-client-certificate-key", "client-cert-key",
+		"-attr", "worker_filter=\"dev\" in \"/tags/env\"",
+		"-show-options",
+	}
+
+	t.Run("json", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		ui := cli.NewMockUi()
+		c := &VaultCommand{Command: base.NewCommand(&base.BoundaryUI{Ui: ui, Format: "json"}), Func: "create"}
+		require.Equal(base.CommandSuccess, c.Run(args), ui.ErrorWriter.String())
+
+		out := ui.OutputWriter.String()
+		for _, secret := range []string{"s.s3cr3t", "client-cert"} {
+			assert.NotContains(out, secret)
+		}
+		var got map[string]interface{}
+		require.NoError(json.Unmarshal([]byte(out), &got))
+		assert.Equal(map[string]interface{}{
+			"scope_id": "p_1234567890",
+			"type":     "vault",
+			"name":     "devops",
+			"attributes": map[string]interface{}{
+				"address":                "https://vault.example.com:8200",
+				"token":                  maskedValue,
+				"client_certificate":     maskedValue,
+				"client_certificate_key": maskedValue,
+				"worker_filter":          `"dev" in "/tags/env"`,
+			},
+		}, got)
+	})
+	t.Run("table", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		ui := cli.NewMockUi()
+		c := &VaultCommand{Command: base.NewCommand(ui), Func: "create"}
+		require.Equal(base.CommandSuccess, c.Run(args), ui.ErrorWriter.String())
+
+		out := ui.OutputWriter.String()
+		assert.Contains(out, "the store was not created")
+		assert.Contains(out, "devops")
+		assert.Contains(out, "https://vault.example.com:8200")
+		assert.Contains(out, maskedValue)
+		assert.NotContains(out, "s.s3cr3t")
+		assert.NotContains(out, "client-cert")
+	})
+	t.Run("with-local-preflight", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		c := &VaultCommand{Command: base.NewCommand(ui), Func: "create"}
+		assert.Equal(t, base.CommandUserError, c.Run(append(args, "-local-preflight")))
+		assert.Contains(t, ui.ErrorWriter.String(), "-show-options can't be used with -local-preflight")
+	})
+}
+
+func TestVaultCommand_quiet(t *testing.T) {
+	// the controller rejects every store with the "rejected" name
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "application/json")
+		if body["name"] == "rejected" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"kind":"InvalidArgument","message":"invalid token"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"csvlt_1234567890","type":"vault","version":2}`))
+	}))
+	t.Cleanup(srv.Close)
+	clientArgs := []string{
+		"-addr", srv.URL,
+		"-keyring-type", "none",
+		"-quiet",
+	}
+
+	tests := []struct {
+		name     string
+		fn       string
+		args     []string
+		wantCode int
+	}{
+		{name: "create", fn: "create", args: []string{"-scope-id", "p_1234567890", "-name", "devops"}, wantCode: base.CommandSuccess},
+		{name: "create-fails", fn: "create", args: []string{"-scope-id", "p_1234567890", "-name", "rejected"}, wantCode: base.CommandApiError},
+		{name: "update", fn: "update", args: []string{"-id", "csvlt_1234567890", "-version", "1", "-name", "devops"}, wantCode: base.CommandSuccess},
+		{name: "update-fails", fn: "update", args: []string{"-id", "csvlt_1234567890", "-version", "1", "-name", "rejected"}, wantCode: base.CommandApiError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			ui := cli.NewMockUi()
+			c := &VaultCommand{Command: base.NewCommand(ui), Func: tt.fn}
+			assert.Equal(tt.wantCode, c.Run(append(clientArgs, tt.args...)), ui.ErrorWriter.String())
+			assert.Empty(ui.OutputWriter.String())
+			if tt.wantCode != base.CommandSuccess {
+				// errors are printed even when quiet
+				assert.Contains(ui.ErrorWriter.String(), "invalid token")
+			}
+		})
+	}
+}
+
+const testDeleteAllListBody = `{"items": [
+	{"id": "csvlt_first", "type": "vault"},
+	{"id": "csvlt_rejected", "type": "vault"},
+	{"id": "csother_1234567890", "type": "other"},
+	{"id": "csvlt_second", "type": "vault"}
+]}`
+
+func TestVaultCommand_deleteAll(t *testing.T) {
+	// the controller deletes every store except csvlt_rejected, and records
+	// the stores it deletes
+	var l sync.Mutex
+	var deleted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(testDeleteAllListBody))
+		case http.MethodDelete:
+			id := path.Base(r.URL.Path)
+			if id == "csvlt_rejected" {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"kind":"PermissionDenied","message":"forbidden"}`))
+				return
+			}
+			l.Lock()
+			defer l.Unlock()
+			deleted = append(deleted, id)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request to the controller: %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	args := []string{
+		"-addr", srv.URL,
+		"-keyring-type", "none",
+		"-scope-id", "p_1234567890",
+		"-all",
+	}
+	newCommand := func(ui cli.Ui) *VaultCommand {
+		deleted = nil
+		return &VaultCommand{Command: base.NewCommand(ui), Func: "delete"}
+	}
+
+	t.Run("confirmed", func(t *testing.T) {
+		assert := assert.New(t)
+		ui := cli.NewMockUi()
+		ui.InputReader = strings.NewReader("yes\n")
+		c := newCommand(ui)
+		assert.Equal(base.CommandCliError, c.Run(args))
+
+		assert.Contains(ui.OutputWriter.String(), "All vault-type credential stores in p_1234567890 will be deleted")
+		assert.ElementsMatch([]string{"csvlt_first", "csvlt_second"}, deleted)
+	})
+	t.Run("not-confirmed", func(t *testing.T) {
+		assert := assert.New(t)
+		ui := cli.NewMockUi()
+		ui.InputReader = strings.NewReader("no\n")
+		c := newCommand(ui)
+		assert.Equal(base.CommandUserError, c.Run(args))
+
+		assert.Contains(ui.ErrorWriter.String(), "Delete canceled")
+		assert.Empty(deleted)
+	})
+	t.Run("yes", func(t *testing.T) {
+		assert := assert.New(t)
+		ui := cli.NewMockUi()
+		c := newCommand(ui)
+		assert.Equal(base.CommandCliError, c.Run(append(args, "-yes")))
+
+		assert.NotContains(ui.OutputWriter.String(), "will be deleted")
+		assert.ElementsMatch([]string{"csvlt_first", "csvlt_second"}, deleted)
+	})
+	t.Run("summary-table", func(t *testing.T) {
+		assert := assert.New(t)
+		ui := cli.NewMockUi()
+		c := newCommand(ui)
+		assert.Equal(base.CommandCliError, c.Run(append(args, "-yes")))
+
+		out := ui.OutputWriter.String()
+		assert.Contains(out, "Delete summary:")
+		assert.Regexp(`Deleted:\s+2`, out)
+		assert.Regexp(`Failed:\s+1`, out)
+		assert.Contains(out, "csvlt_first")
+		assert.Contains(out, "csvlt_second")
+		assert.Contains(out, "csvlt_rejected: ")
+		assert.NotContains(out, "csother_1234567890")
+		// the partial failure fails the command
+		assert.Contains(ui.ErrorWriter.String(), "1 of 3 deletes failed")
+	})
+	t.Run("summary-json", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		ui := cli.NewMockUi()
+		c := newCommand(&base.BoundaryUI{Ui: ui, Format: "json"})
+		assert.Equal(base.CommandCliError, c.Run(append(args, "-yes")))
+
+		var got deleteAllResult
+		require.NoError(json.Unmarshal(ui.OutputWriter.Bytes(), &got))
+		assert.ElementsMatch([]string{"csvlt_first", "csvlt_second"}, got.Deleted)
+		require.Len(got.Failed, 1)
+		assert.Equal("csvlt_rejected", got.Failed[0].Id)
+		assert.Contains(got.Failed[0].Error, "forbidden")
+	})
+	t.Run("quiet-with-failure", func(t *testing.T) {
+		assert := assert.New(t)
+		ui := cli.NewMockUi()
+		c := newCommand(ui)
+		assert.Equal(base.CommandCliError, c.Run(append(args, "-yes", "-quiet")))
+
+		// the summary is printed when a delete failed, even when quiet
+		assert.Contains(ui.OutputWriter.String(), "csvlt_rejected")
+	})
+	t.Run("missing-all", func(t *testing.T) {
+		assert := assert.New(t)
+		ui := cli.NewMockUi()
+		c := newCommand(ui)
+		assert.Equal(base.CommandUserError, c.Run(args[:len(args)-1]))
+
+		assert.Contains(ui.ErrorWriter.String(), "-all is required")
+		assert.Empty(deleted)
+	})
+}
+
+func TestParseAttrFlags(t *testing.T) {
+	tests := []struct {
+		name            string
+		flagAttrs       []string
+		want            map[string]interface{}
+		wantErrContains string
+	}{
+		{
+			name:      "string",
+			flagAttrs: []string{"worker_filter=\"dev\" in \"/tags/env\""},
+			want:      map[string]interface{}{"worker_filter": `"dev" in "/tags/env"`},
+		},
+		{
+			name:      "unquoted-string",
+			flagAttrs: []string{"role=admin"},
+			want:      map[string]interface{}{"role": "admin"},
+		},
+		{
+			name:      "empty-value",
+			flagAttrs: []string{"role="},
+			want:      map[string]interface{}{"role": ""},
+		},
+		{
+			name:      "value-with-equals",
+			flagAttrs: []string{"query=a=b"},
+			want:      map[string]interface{}{"query": "a=b"},
+		},
+		{
+			name: "json-values",
+			flagAttrs: []string{
+				"max_ttl=3600",
+				"renewable=true",
+				"options={\"retries\":3}",
+				"policies=[\"a\",\"b\"]",
+				"nothing=null",
+			},
+			want: map[string]interface{}{
+				"max_ttl":   float64(3600),
+				"renewable": true,
+				"options":   map[string]interface{}{"retries": float64(3)},
+				"policies":  []interface{}{"a", "b"},
+				"nothing":   nil,
+			},
+		},
+		{
+			name:      "invalid-json-is-string",
+			flagAttrs: []string{"options={retries:3}"},
+			want:      map[string]interface{}{"options": "{retries:3}"},
+		},
+		{
+			name:      "key-trimmed",
+			flagAttrs: []string{" role =admin"},
+			want:      map[string]interface{}{"role": "admin"},
+		},
+		{
+			name:            "missing-value",
+			flagAttrs:       []string{"role"},
+			wantErrContains: `Invalid -attr "role": must be in the form key=value`,
+		},
+		{
+			name:            "empty-key",
+			flagAttrs:       []string{"=admin"},
+			wantErrContains: `Invalid -attr key ""`,
+		},
+		{
+			name:            "uppercase-key",
+			flagAttrs:       []string{"Role=admin"},
+			wantErrContains: `Invalid -attr key "Role"`,
+		},
+		{
+			name:            "key-starts-with-digit",
+			flagAttrs:       []string{"1role=admin"},
+			wantErrContains: `Invalid -attr key "1role"`,
+		},
+		{
+			name:            "key-with-dash",
+			flagAttrs:       []string{"worker-filter=x"},
+			wantErrContains: `Invalid -attr key "worker-filter"`,
+		},
+		{
+			name:            "duplicate-key",
+			flagAttrs:       []string{"role=admin", "role=dev"},
+			wantErrContains: `Attribute "role" was specified more than once`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			got, err := parseAttrFlags(tt.flagAttrs)
+			if tt.wantErrContains != "" {
+				require.Error(err)
+				assert.Contains(err.Error(), tt.wantErrContains)
+				assert.Nil(got)
+				return
+			}
+			require.NoError(err)
+			assert.Equal(tt.want, got)
+		})
+	}
+	t.Run("dedicated-flags", func(t *testing.T) {
+		// attributes which have a dedicated flag must be set with it
+		for k, name := range dedicatedAttrFlags {
+			_, err := parseAttrFlags([]string{k + "=value"})
+			require.Error(t, err, k)
+			assert.Contains(t, err.Error(), fmt.Sprintf("Attribute %q can't be set with -attr, use -%s instead", k, name))
+		}
+	})
+}
+
+func TestVaultCommand_resolveScopeName(t *testing.T) {
+	// the scopes beneath global, by parent; "web" is the name of a project of
+	// each org, and "db" is only the name of a project
+	children := map[string][]string{
+		"global": {
+			`{"id": "o_eng", "scope_id": "global", "name": "eng"}`,
+			`{"id": "o_ops", "scope_id": "global", "name": "ops"}`,
+		},
+		"o_eng": {`{"id": "p_engweb", "scope_id": "o_eng", "name": "web"}`},
+		"o_ops": {
+			`{"id": "p_opsweb", "scope_id": "o_ops", "name": "web"}`,
+			`{"id": "p_db", "scope_id": "o_ops", "name": "db"}`,
+		},
+	}
+	var l sync.Mutex
+	var lists int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l.Lock()
+		lists++
+		l.Unlock()
+		items := children[r.URL.Query().Get("scope_id")]
+		if r.URL.Query().Get("recursive") == "true" {
+			for _, s := range items {
+				var child struct {
+					Id string `json:"id"`
+				}
+				require.NoError(t, json.Unmarshal([]byte(s), &child))
+				items = append(items, children[child.Id]...)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"items": [%s]}`, strings.Join(items, ","))
+	}))
+	t.Cleanup(srv.Close)
+	newCommand := func(t *testing.T, args ...string) *VaultCommand {
+		t.Helper()
+		lists = 0
+		c := &VaultCommand{Command: base.NewCommand(cli.NewMockUi()), Func: "create"}
+		initVaultFlags()
+		require.NoError(t, c.Flags().Parse(append([]string{"-addr", srv.URL, "-keyring-type", "none"}, args...)))
+		return c
+	}
+
+	tests := []struct {
+		name            string
+		args            []string
+		scopeName       string
+		want            string
+		wantErrContains string
+	}{
+		{
+			name:      "child",
+			scopeName: "eng",
+			want:      "o_eng",
+		},
+		{
+			name:            "not-a-child",
+			scopeName:       "db",
+			wantErrContains: `No scope named "db" was found in the child scopes of global`,
+		},
+		{
+			name:      "recursive",
+			args:      []string{"-recursive"},
+			scopeName: "db",
+			want:      "p_db",
+		},
+		{
+			name:            "recursive-not-found",
+			args:            []string{"-recursive"},
+			scopeName:       "qa",
+			wantErrContains: `No scope named "qa" was found in the scopes beneath global`,
+		},
+		{
+			name:            "ambiguous",
+			args:            []string{"-recursive"},
+			scopeName:       "web",
+			wantErrContains: `Scope name "web" is ambiguous, it matches p_engweb (parent o_eng), p_opsweb (parent o_ops); pass its parent's id with -scope-id to disambiguate`,
+		},
+		{
+			name:      "disambiguated-by-scope-id",
+			args:      []string{"-scope-id", "o_ops"},
+			scopeName: "web",
+			want:      "p_opsweb",
+		},
+		{
+			name:      "disambiguated-by-recursive-scope-id",
+			args:      []string{"-scope-id", "o_eng", "-recursive"},
+			scopeName: "web",
+			want:      "p_engweb",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			c := newCommand(t, tt.args...)
+			got, err := c.resolveScopeName(tt.scopeName)
+			if tt.wantErrContains != "" {
+				require.Error(err)
+				assert.Contains(err.Error(), tt.wantErrContains)
+				assert.Empty(c.scopeIdsByName)
+				return
+			}
+			require.NoError(err)
+			assert.Equal(tt.want, got)
+		})
+	}
+	t.Run("cached", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		c := newCommand(t)
+		for i := 0; i < 2; i++ {
+			got, err := c.resolveScopeName("eng")
+			require.NoError(err)
+			assert.Equal("o_eng", got)
+		}
+		assert.Equal(1, lists)
+
+		// the cache is only kept for the invocation of the command
+		c = newCommand(t)
+		_, err := c.resolveScopeName("eng")
+		require.NoError(err)
+		assert.Equal(1, lists)
+	})
+}
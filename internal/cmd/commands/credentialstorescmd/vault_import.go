@@ -0,0 +1,210 @@
+package credentialstorescmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/hashicorp/boundary/api"
+	"github.com/hashicorp/boundary/api/credentialstores"
+	"github.com/hashicorp/boundary/internal/cmd/base"
+)
+
+// importFunc is the command's func for importing stores from a file (see
+// "credential-stores import").
+const importFunc = "import"
+
+// storeDefinition is a credential store in the file passed to import with
+// -file, which holds a JSON array of them.  Type defaults to vault, the only
+// type of credential store, and ScopeId defaults to -scope-id.
+type storeDefinition struct {
+	Type        string                 `json:"type"`
+	ScopeId     string                 `json:"scope_id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Attributes  map[string]interface{} `json:"attributes"`
+}
+
+// importResult is the outcome of importing the stores of a file.  Created
+// lists the stores which were created and, for -dry-run, Validated lists the
+// definitions which would have been.
+type importResult struct {
+	DryRun    bool           `json:"dry_run"`
+	Created   []importedItem `json:"created"`
+	Validated []importedItem `json:"validated,omitempty"`
+	Failed    []importedItem `json:"failed"`
+}
+
+// importedItem is the outcome of a definition, identified by its index in
+// the file.
+type importedItem struct {
+	Index int    `json:"index"`
+	Name  string `json:"name,omitempty"`
+	Id    string `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// readImportFile reads the store definitions from the file passed with -file.
+// Unknown fields are rejected, so a misspelt field isn't silently ignored.
+func (c *VaultCommand) readImportFile() bool {
+	if c.flagFile == "" {
+		c.PrintCliError(fmt.Errorf("A file of store definitions must be passed in via -%s", fileFlagName))
+		return false
+	}
+	b, err := ioutil.ReadFile(c.flagFile)
+	if err != nil {
+		c.PrintCliError(fmt.Errorf("Error reading -%s: %w", fileFlagName, err))
+		return false
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	var defs []storeDefinition
+	if err := dec.Decode(&defs); err != nil {
+		c.PrintCliError(fmt.Errorf("Error decoding -%s, it must be a JSON array of store definitions: %w", fileFlagName, err))
+		return false
+	}
+	if len(defs) == 0 {
+		c.PrintCliError(fmt.Errorf("-%s doesn't define any stores", fileFlagName))
+		return false
+	}
+	c.importDefinitions = defs
+	return true
+}
+
+// validate returns an error if the definition can't be created, and sets its
+// defaults.
+func (d *storeDefinition) validate(defaultScopeId string) error {
+	if d.Type == "" {
+		d.Type = "vault"
+	}
+	if d.ScopeId == "" {
+		d.ScopeId = defaultScopeId
+	}
+	switch {
+	case d.Type != "vault":
+		return fmt.Errorf("unknown credential store type %q", d.Type)
+	case d.ScopeId == "":
+		return errors.New("missing scope_id, and no -scope-id was passed")
+	}
+	for _, attr := range []string{"address", "token"} {
+		if v, ok := d.Attributes[attr].(string); !ok || v == "" {
+			return fmt.Errorf("missing the %s attribute", attr)
+		}
+	}
+	return nil
+}
+
+// options returns the options of the create request for the definition.
+func (d *storeDefinition) options() []credentialstores.Option {
+	opts := []credentialstores.Option{credentialstores.WithAttributes(d.Attributes)}
+	if d.Name != "" {
+		opts = append(opts, credentialstores.WithName(d.Name))
+	}
+	if d.Description != "" {
+		opts = append(opts, credentialstores.WithDescription(d.Description))
+	}
+	return opts
+}
+
+// importStores creates a store for each definition read from -file,
+// continuing past the definitions which fail.  With -dry-run, the definitions
+// are only validated.  A summary is printed and an error is returned if any
+// of the definitions failed.
+func (c *VaultCommand) importStores(csClient *credentialstores.Client) (api.GenericResult, error) {
+	c.importResult = &importResult{
+		DryRun:  c.flagDryRun,
+		Created: []importedItem{},
+		Failed:  []importedItem{},
+	}
+	if c.flagDryRun {
+		c.importResult.Validated = []importedItem{}
+	}
+	for i := range c.importDefinitions {
+		d := &c.importDefinitions[i]
+		item := importedItem{Index: i, Name: d.Name}
+		if err := d.validate(c.FlagScopeId); err != nil {
+			item.Error = err.Error()
+			c.importResult.Failed = append(c.importResult.Failed, item)
+			continue
+		}
+		if c.flagDryRun {
+			c.importResult.Validated = append(c.importResult.Validated, item)
+			continue
+		}
+		result, err := csClient.Create(c.Context, d.Type, d.ScopeId, d.options()...)
+		if err != nil {
+			item.Error = err.Error()
+			c.importResult.Failed = append(c.importResult.Failed, item)
+			continue
+		}
+		item.Id = result.GetItem().(*credentialstores.CredentialStore).Id
+		c.importResult.Created = append(c.importResult.Created, item)
+	}
+	if !c.flagQuiet || len(c.importResult.Failed) > 0 {
+		c.printImportResult()
+	}
+	if n := len(c.importResult.Failed); n > 0 {
+		if c.flagDryRun {
+			return nil, fmt.Errorf("%d of %d store definitions are invalid", n, len(c.importDefinitions))
+		}
+		return nil, fmt.Errorf("%d of %d creates failed", n, len(c.importDefinitions))
+	}
+	return nil, nil
+}
+
+func (c *VaultCommand) printImportResult() {
+	r := c.importResult
+	switch base.Format(c.UI) {
+	case "json":
+		b, err := json.Marshal(r)
+		if err != nil {
+			c.PrintCliError(fmt.Errorf("Error formatting as JSON: %w", err))
+			return
+		}
+		c.UI.Output(string(b))
+	default:
+		title := "Import summary:"
+		if r.DryRun {
+			title = "Import summary (dry run, no stores were created):"
+		}
+		output := []string{
+			"",
+			title,
+			fmt.Sprintf("  Created:                 %d", len(r.Created)),
+		}
+		if r.DryRun {
+			output = append(output, fmt.Sprintf("  Validated:               %d", len(r.Validated)))
+		}
+		output = append(output, fmt.Sprintf("  Failed:                  %d", len(r.Failed)))
+		if len(r.Created) > 0 {
+			output = append(output, "", "  Created Credential Stores:")
+			for _, item := range r.Created {
+				output = append(output, fmt.Sprintf("    %s: %s", item.label(), item.Id))
+			}
+		}
+		if len(r.Validated) > 0 {
+			output = append(output, "", "  Validated Credential Stores:")
+			for _, item := range r.Validated {
+				output = append(output, fmt.Sprintf("    %s", item.label()))
+			}
+		}
+		if len(r.Failed) > 0 {
+			output = append(output, "", "  Failed Credential Stores:")
+			for _, item := range r.Failed {
+				output = append(output, fmt.Sprintf("    %s: %s", item.label(), item.Error))
+			}
+		}
+		c.UI.Output(base.WrapForHelpText(output))
+	}
+}
+
+// label identifies the item in the summary table by its index and, when it
+// has one, its name.
+func (i importedItem) label() string {
+	if i.Name == "" {
+		return fmt.Sprintf("[%d]", i.Index)
+	}
+	return fmt.Sprintf("[%d] %s", i.Index, i.Name)
+}
@@ -0,0 +1,119 @@
+package credentialstorescmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/boundary/internal/cmd/base"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testImportFile = `[
+	{"name": "first", "attributes": {"address": "https://vault.example.com:8200", "token": "s.first"}},
+	{"name": "rejected", "attributes": {"address": "https://vault.example.com:8200", "token": "s.rejected"}},
+	{"name": "no-token", "attributes": {"address": "https://vault.example.com:8200"}},
+	{"name": "other-scope", "scope_id": "p_0987654321", "attributes": {"address": "https://vault.example.com:8200", "token": "s.other"}}
+]`
+
+func TestVaultCommand_import(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stores.json")
+	require.NoError(t, os.WriteFile(path, []byte(testImportFile), 0o600))
+
+	// the controller creates every store except "rejected", and records the
+	// scope of each store it creates
+	var l sync.Mutex
+	var created []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "application/json")
+		if body["name"] == "rejected" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"kind":"InvalidArgument","message":"invalid token"}`))
+			return
+		}
+		l.Lock()
+		defer l.Unlock()
+		created = append(created, fmt.Sprintf("%s/%s", body["scope_id"], body["name"]))
+		_, _ = fmt.Fprintf(w, `{"id":"csvlt_%d","type":"vault","version":1}`, len(created))
+	}))
+	t.Cleanup(srv.Close)
+	clientArgs := []string{
+		"-addr", srv.URL,
+		"-keyring-type", "none",
+		"-scope-id", "p_1234567890",
+	}
+	args := append(clientArgs, "-file", path)
+
+	t.Run("json", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		created = nil
+		ui := cli.NewMockUi()
+		c := &VaultCommand{Command: base.NewCommand(&base.BoundaryUI{Ui: ui, Format: "json"}), Func: importFunc}
+		assert.Equal(base.CommandCliError, c.Run(args))
+		assert.Contains(ui.ErrorWriter.String(), "2 of 4 creates failed")
+
+		var got importResult
+		require.NoError(json.Unmarshal(ui.OutputWriter.Bytes(), &got))
+		assert.Equal([]importedItem{
+			{Index: 0, Name: "first", Id: "csvlt_1"},
+			{Index: 3, Name: "other-scope", Id: "csvlt_2"},
+		}, got.Created)
+		require.Len(got.Failed, 2)
+		assert.Equal(1, got.Failed[0].Index)
+		assert.Contains(got.Failed[0].Error, "invalid token")
+		assert.Equal(importedItem{Index: 2, Name: "no-token", Error: "missing the token attribute"}, got.Failed[1])
+		assert.Equal([]string{"p_1234567890/first", "p_0987654321/other-scope"}, created)
+	})
+	t.Run("table", func(t *testing.T) {
+		assert := assert.New(t)
+		created = nil
+		ui := cli.NewMockUi()
+		c := &VaultCommand{Command: base.NewCommand(ui), Func: importFunc}
+		assert.Equal(base.CommandCliError, c.Run(args))
+
+		out := ui.OutputWriter.String()
+		assert.Contains(out, "Import summary:")
+		assert.Contains(out, "[0] first: csvlt_1")
+		assert.Contains(out, "[2] no-token: missing the token attribute")
+	})
+	t.Run("dry-run", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		created = nil
+		ui := cli.NewMockUi()
+		c := &VaultCommand{Command: base.NewCommand(&base.BoundaryUI{Ui: ui, Format: "json"}), Func: importFunc}
+		assert.Equal(base.CommandCliError, c.Run(append(args, "-dry-run")))
+		assert.Contains(ui.ErrorWriter.String(), "1 of 4 store definitions are invalid")
+		assert.Empty(created)
+
+		var got importResult
+		require.NoError(json.Unmarshal(ui.OutputWriter.Bytes(), &got))
+		assert.True(got.DryRun)
+		assert.Empty(got.Created)
+		assert.Len(got.Validated, 3)
+		require.Len(got.Failed, 1)
+		assert.Equal(2, got.Failed[0].Index)
+	})
+	t.Run("missing-file", func(t *testing.T) {
+		ui := cli.NewMockUi()
+		c := &VaultCommand{Command: base.NewCommand(ui), Func: importFunc}
+		assert.Equal(t, base.CommandUserError, c.Run(clientArgs))
+		assert.Contains(t, ui.ErrorWriter.String(), "must be passed in via -file")
+	})
+	t.Run("unknown-field", func(t *testing.T) {
+		bad := filepath.Join(t.TempDir(), "bad.json")
+		require.NoError(t, os.WriteFile(bad, []byte(`[{"nmae": "typo"}]`), 0o600))
+		ui := cli.NewMockUi()
+		c := &VaultCommand{Command: base.NewCommand(ui), Func: importFunc}
+		assert.Equal(t, base.CommandUserError, c.Run(append(clientArgs, "-file", bad)))
+		assert.Contains(t, ui.ErrorWriter.String(), `unknown field "nmae"`)
+	})
+}
@@ -0,0 +1,155 @@
+package credentialstorescmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/boundary/api/credentialstores"
+	"github.com/posener/complete"
+)
+
+const (
+	// storeIdCacheTTL is how long the store ids listed for a completion are
+	// reused.  Each completion is a separate invocation of the CLI, so they're
+	// cached on disk.
+	storeIdCacheTTL = 30 * time.Second
+
+	// storeIdLookupTimeout bounds the time spent listing stores, so a slow or
+	// unreachable controller never blocks the shell.
+	storeIdLookupTimeout = 2 * time.Second
+)
+
+// storeIdPredictor predicts the ids of the vault-type credential stores in the
+// current scope.  The scope is taken from the -scope-id being completed, or
+// BOUNDARY_SCOPE_ID, and defaults to global.  When the stores can't be listed
+// (e.g. the controller is unreachable or the user isn't authenticated) it
+// falls back to complete.PredictAnything.
+type storeIdPredictor struct {
+	c *VaultCommand
+}
+
+var _ complete.Predictor = (*storeIdPredictor)(nil)
+
+func (p *storeIdPredictor) Predict(args complete.Args) []string {
+	type result struct {
+		ids []string
+		err error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		ids, err := p.storeIds(scopeIdFromArgs(args.All))
+		resultCh <- result{ids: ids, err: err}
+	}()
+	select {
+	case r := <-resultCh:
+		if r.err == nil {
+			return r.ids
+		}
+	case <-time.After(storeIdLookupTimeout):
+	}
+	return complete.PredictAnything.Predict(args)
+}
+
+// storeIds returns the ids of the vault-type credential stores in the scope,
+// using the cached ids when they're recent enough.
+func (p *storeIdPredictor) storeIds(scopeId string) ([]string, error) {
+	client, err := p.c.Client()
+	if err != nil {
+		return nil, err
+	}
+	cacheFile := storeIdCacheFile(client.Addr(), scopeId)
+	if ids, ok := readStoreIdCache(cacheFile); ok {
+		return ids, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeIdLookupTimeout)
+	defer cancel()
+	result, err := credentialstores.NewClient(client).List(ctx, scopeId)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(result.Items))
+	for _, item := range result.Items {
+		if item.Type == "vault" {
+			ids = append(ids, item.Id)
+		}
+	}
+	writeStoreIdCache(cacheFile, ids)
+	return ids, nil
+}
+
+// scopeIdFromArgs returns the value of the -scope-id in the args being
+// completed, then BOUNDARY_SCOPE_ID, then global.
+func scopeIdFromArgs(args []string) string {
+	for i, arg := range args {
+		name := strings.TrimLeft(arg, "-")
+		if name == arg {
+			continue
+		}
+		switch {
+		case name == "scope-id" && i+1 < len(args):
+			return args[i+1]
+		case strings.HasPrefix(name, "scope-id="):
+			return strings.TrimPrefix(name, "scope-id=")
+		}
+	}
+	if scopeId := os.Getenv("BOUNDARY_SCOPE_ID"); scopeId != "" {
+		return scopeId
+	}
+	return "global"
+}
+
+// storeIdCacheFile returns the path of the cache file for the controller's
+// scope, or "" when there's no user cache dir.
+func storeIdCacheFile(addr, scopeId string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(addr + "\n" + scopeId))
+	return filepath.Join(dir, "boundary", "completion", "vault-credential-stores-"+hex.EncodeToString(sum[:8])+".json")
+}
+
+// readStoreIdCache returns the cached ids if the cache file was written within
+// the storeIdCacheTTL.
+func readStoreIdCache(cacheFile string) ([]string, bool) {
+	if cacheFile == "" {
+		return nil, false
+	}
+	fi, err := os.Stat(cacheFile)
+	if err != nil || time.Since(fi.ModTime()) > storeIdCacheTTL {
+		return nil, false
+	}
+	b, err := ioutil.ReadFile(cacheFile)
+	if err != nil {
+		return nil, false
+	}
+	var ids []string
+	if err := json.Unmarshal(b, &ids); err != nil {
+		return nil, false
+	}
+	return ids, true
+}
+
+// writeStoreIdCache caches the ids.  Failures are ignored, since the cache is
+// only an optimization.
+func writeStoreIdCache(cacheFile string, ids []string) {
+	if cacheFile == "" {
+		return
+	}
+	b, err := json.Marshal(ids)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0o700); err != nil {
+		return
+	}
+	_ = ioutil.WriteFile(cacheFile, b, 0o600)
+}
@@ -0,0 +1,127 @@
+package credentialstorescmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/boundary/internal/cmd/base"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStoreIdController is a controller which lists the stores of its body, or
+// fails every list when failing is set, and counts the lists.
+type testStoreIdController struct {
+	l       sync.Mutex
+	body    string
+	failing bool
+	lists   map[string]int
+}
+
+func (c *testStoreIdController) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.l.Lock()
+	defer c.l.Unlock()
+	if c.lists == nil {
+		c.lists = make(map[string]int)
+	}
+	c.lists[r.URL.Query().Get("scope_id")]++
+	w.Header().Set("Content-Type", "application/json")
+	if c.failing {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"kind":"Internal","message":"controller unavailable"}`))
+		return
+	}
+	_, _ = w.Write([]byte(c.body))
+}
+
+func (c *testStoreIdController) set(body string, failing bool) {
+	c.l.Lock()
+	defer c.l.Unlock()
+	c.body, c.failing = body, failing
+}
+
+func (c *testStoreIdController) listCount(scopeId string) int {
+	c.l.Lock()
+	defer c.l.Unlock()
+	return c.lists[scopeId]
+}
+
+func TestStoreIdPredictor(t *testing.T) {
+	// the cache is written to a dir of the test, rather than the user's
+	testSetenv(t, "XDG_CACHE_HOME", t.TempDir())
+
+	controller := &testStoreIdController{}
+	srv := httptest.NewServer(controller)
+	t.Cleanup(srv.Close)
+	newPredictor := func(t *testing.T, addr string) *storeIdPredictor {
+		t.Helper()
+		c := &VaultCommand{Command: base.NewCommand(cli.NewMockUi()), Func: "update"}
+		initVaultFlags()
+		require.NoError(t, c.Flags().Parse([]string{"-addr", addr, "-keyring-type", "none"}))
+		return &storeIdPredictor{c: c}
+	}
+	storesBody := func(ids ...string) string {
+		items := `{"id": "csother_1234567890", "type": "other"}`
+		for _, id := range ids {
+			items += fmt.Sprintf(`, {"id": %q, "type": "vault"}`, id)
+		}
+		return fmt.Sprintf(`{"items": [%s]}`, items)
+	}
+	// each test uses its own scope, so it has its own cache file
+	args := func(scopeId string) complete.Args {
+		return complete.Args{All: []string{"-scope-id", scopeId, "-id"}, Last: "-id"}
+	}
+
+	t.Run("cached", func(t *testing.T) {
+		assert := assert.New(t)
+		controller.set(storesBody("csvlt_first", "csvlt_second"), false)
+		p := newPredictor(t, srv.URL)
+		assert.Equal([]string{"csvlt_first", "csvlt_second"}, p.Predict(args("p_cached")))
+
+		// a store created since is not predicted until the cache expires
+		controller.set(storesBody("csvlt_first", "csvlt_second", "csvlt_third"), false)
+		assert.Equal([]string{"csvlt_first", "csvlt_second"}, newPredictor(t, srv.URL).Predict(args("p_cached")))
+		assert.Equal(1, controller.listCount("p_cached"))
+	})
+	t.Run("expired", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		controller.set(storesBody("csvlt_first"), false)
+		p := newPredictor(t, srv.URL)
+		assert.Equal([]string{"csvlt_first"}, p.Predict(args("p_expired")))
+
+		cacheFile := storeIdCacheFile(srv.URL, "p_expired")
+		expired := time.Now().Add(-storeIdCacheTTL - time.Second)
+		require.NoError(os.Chtimes(cacheFile, expired, expired))
+		controller.set(storesBody("csvlt_first", "csvlt_second"), false)
+		assert.Equal([]string{"csvlt_first", "csvlt_second"}, newPredictor(t, srv.URL).Predict(args("p_expired")))
+		assert.Equal(2, controller.listCount("p_expired"))
+	})
+	t.Run("api-error", func(t *testing.T) {
+		assert := assert.New(t)
+		controller.set("", true)
+		p := newPredictor(t, srv.URL)
+		assert.Equal(complete.PredictAnything.Predict(args("p_api_error")), p.Predict(args("p_api_error")))
+		assert.NoFileExists(storeIdCacheFile(srv.URL, "p_api_error"))
+
+		// the failure isn't cached, so the stores are predicted once they
+		// can be listed
+		controller.set(storesBody("csvlt_first"), false)
+		assert.Equal([]string{"csvlt_first"}, newPredictor(t, srv.URL).Predict(args("p_api_error")))
+		assert.Equal(2, controller.listCount("p_api_error"))
+	})
+	t.Run("unreachable", func(t *testing.T) {
+		assert := assert.New(t)
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closed.Close()
+		p := newPredictor(t, closed.URL)
+		assert.Equal(complete.PredictAnything.Predict(args("p_unreachable")), p.Predict(args("p_unreachable")))
+		assert.NoFileExists(storeIdCacheFile(closed.URL, "p_unreachable"))
+	})
+}
@@ -0,0 +1,220 @@
+package credentialstorescmd
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/boundary/api/credentialstores"
+	"github.com/hashicorp/boundary/internal/cmd/base"
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/go-rootcerts"
+	"github.com/hashicorp/go-secure-stdlib/parseutil"
+)
+
+// localPreflightTimeout bounds the lookup of the store's token by
+// -local-preflight.
+const localPreflightTimeout = 10 * time.Second
+
+// preflightResult is the outcome of the local preflight of a store's Vault
+// server with -local-preflight.
+type preflightResult struct {
+	Passed     bool   `json:"passed"`
+	Address    string `json:"address"`
+	Namespace  string `json:"namespace,omitempty"`
+	Status     int    `json:"status,omitempty"`
+	Diagnostic string `json:"diagnostic"`
+}
+
+// vaultResponseError is a response of the Vault server other than a 200, with
+// the errors of its body.
+type vaultResponseError struct {
+	StatusCode int
+	Errors     []string
+}
+
+func (e *vaultResponseError) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("Vault responded with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("Vault responded with status %d: %s", e.StatusCode, strings.Join(e.Errors, "; "))
+}
+
+// localPreflight looks up the store's token with the Vault server of the
+// store's flags, printing whether it passed along with Vault's diagnostic.
+// It's only a local preflight: the lookup is made from the host running the
+// command, so it doesn't prove the controller can reach the server.  false is
+// returned if the preflight failed.
+func (c *VaultCommand) localPreflight() bool {
+	r := &preflightResult{
+		Address:   c.flagAddress,
+		Namespace: c.flagNamespace,
+	}
+	if err := c.lookupVaultToken(); err != nil {
+		r.Diagnostic = err.Error()
+		var respErr *vaultResponseError
+		if errors.As(err, &respErr) {
+			r.Status = respErr.StatusCode
+			if len(respErr.Errors) > 0 {
+				r.Diagnostic = strings.Join(respErr.Errors, "; ")
+			}
+		}
+	} else {
+		r.Passed = true
+		r.Diagnostic = "the token was looked up successfully"
+	}
+	// a passing preflight isn't printed as JSON, so the output of the create
+	// remains a single JSON object
+	if !r.Passed || (!c.flagQuiet && base.Format(c.UI) != "json") {
+		c.printPreflightResult("Local preflight (from this host, not the controller):", r)
+	}
+	return r.Passed
+}
+
+// testConnection has the controller test the connection to the Vault server
+// of the store of the options, without creating the store.  A passing test is
+// printed along with the store's Vault address, and the error of a failing
+// test is returned so it's printed as the controller's error.
+func (c *VaultCommand) testConnection(csClient *credentialstores.Client, opts []credentialstores.Option) error {
+	if _, err := csClient.TestConnection(c.Context, "vault", c.FlagScopeId, opts...); err != nil {
+		return err
+	}
+	if !c.flagQuiet && base.Format(c.UI) != "json" {
+		c.printPreflightResult("Connection test (from the controller):", &preflightResult{
+			Passed:     true,
+			Address:    c.flagAddress,
+			Namespace:  c.flagNamespace,
+			Diagnostic: "the controller connected to Vault and checked the token",
+		})
+	}
+	return nil
+}
+
+// lookupVaultToken looks up the store's token with the Vault server of the
+// store's flags.  Only the flags are used, rather than a client of the Vault
+// API which would also read the VAULT_* environment variables, so the lookup
+// is made with the configuration the store would be created with.
+func (c *VaultCommand) lookupVaultToken() error {
+	if c.flagAddress == "" {
+		return fmt.Errorf("-%s is required for the preflight", addressFlagName)
+	}
+	if c.flagVaultToken == "" {
+		return fmt.Errorf("-%s is required for the preflight", vaultTokenFlagName)
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: c.flagTlsSkipVerify,
+		ServerName:         c.flagTlsServerName,
+	}
+	if c.flagCaCert != "" {
+		caCert, err := parseFlagValue(vaultCaCertFlagName, c.flagCaCert)
+		if err != nil {
+			return err
+		}
+		if err := rootcerts.ConfigureTLS(tlsConfig, &rootcerts.Config{CACertificate: []byte(caCert)}); err != nil {
+			return fmt.Errorf("Error configuring -%s: %w", vaultCaCertFlagName, err)
+		}
+	}
+	if c.flagClientCert != "" || c.flagClientCertKey != "" {
+		cert, err := parseFlagValue(clientCertificateFlagName, c.flagClientCert)
+		if err != nil {
+			return err
+		}
+		key, err := parseFlagValue(clientCertificateKeyFlagName, c.flagClientCertKey)
+		if err != nil {
+			return err
+		}
+		clientCert, err := tls.X509KeyPair([]byte(cert), []byte(key))
+		if err != nil {
+			return fmt.Errorf("Error parsing the client certificate: %w", err)
+		}
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return &clientCert, nil
+		}
+	}
+	transport := cleanhttp.DefaultTransport()
+	transport.TLSClientConfig = tlsConfig
+	client := &http.Client{Transport: transport}
+
+	ctx, cancel := context.WithTimeout(c.Context, localPreflightTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(c.flagAddress, "/")+"/v1/auth/token/lookup-self", nil)
+	if err != nil {
+		return fmt.Errorf("Error creating the lookup request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", c.flagVaultToken)
+	req.Header.Set("X-Vault-Request", "true")
+	if c.flagNamespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.flagNamespace)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	respErr := &vaultResponseError{StatusCode: resp.StatusCode}
+	var body struct {
+		Errors []string `json:"errors"`
+	}
+	b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err := json.Unmarshal(b, &body); err == nil {
+		respErr.Errors = body.Errors
+	}
+	return respErr
+}
+
+// parseFlagValue returns the value of a flag which can be the value itself, a
+// file:// or an env:// path.  A value which isn't a URL, such as an inline
+// PEM, is the value itself.
+func parseFlagValue(flagName, v string) (string, error) {
+	parsed, err := parseutil.ParsePath(v)
+	if err != nil && !errors.Is(err, parseutil.ErrNotAUrl) {
+		return "", fmt.Errorf("Error reading -%s: %w", flagName, err)
+	}
+	return parsed, nil
+}
+
+func (c *VaultCommand) printPreflightResult(title string, r *preflightResult) {
+	switch base.Format(c.UI) {
+	case "json":
+		b, err := json.Marshal(r)
+		if err != nil {
+			c.PrintCliError(fmt.Errorf("Error formatting as JSON: %w", err))
+			return
+		}
+		c.UI.Output(string(b))
+	default:
+		result := "passed"
+		if !r.Passed {
+			result = "failed"
+		}
+		output := []string{
+			"",
+			title,
+			fmt.Sprintf("  Result:                  %s", result),
+			fmt.Sprintf("  Vault Address:           %s", r.Address),
+		}
+		if r.Namespace != "" {
+			output = append(output, fmt.Sprintf("  Vault Namespace:         %s", r.Namespace))
+		}
+		if r.Status != 0 {
+			output = append(output, fmt.Sprintf("  Status:                  %d", r.Status))
+		}
+		output = append(output, fmt.Sprintf("  Diagnostic:              %s", r.Diagnostic))
+		if r.Passed {
+			c.UI.Output(base.WrapForHelpText(output))
+			return
+		}
+		c.UI.Error(base.WrapForHelpText(output))
+	}
+}
@@ -0,0 +1,306 @@
+package credentialstorescmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/boundary/internal/cmd/base"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSetenv sets the environment variable for the test.
+func testSetenv(t *testing.T, key, value string) {
+	t.Helper()
+	prev, ok := os.LookupEnv(key)
+	require.NoError(t, os.Setenv(key, value))
+	t.Cleanup(func() {
+		if ok {
+			_ = os.Setenv(key, prev)
+			return
+		}
+		_ = os.Unsetenv(key)
+	})
+}
+
+func TestVaultCommand_localPreflight(t *testing.T) {
+	// the Vault server only accepts s.good, which was issued in the ns1
+	// namespace
+	vaultSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path != "/v1/auth/token/lookup-self":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		case r.Header.Get("X-Vault-Token") != "s.good" || r.Header.Get("X-Vault-Namespace") != "ns1":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+		default:
+			_, _ = w.Write([]byte(`{"data":{"id":"s.good"}}`))
+		}
+	}))
+	t.Cleanup(vaultSrv.Close)
+
+	// the controller records the stores it creates
+	var l sync.Mutex
+	var created int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l.Lock()
+		defer l.Unlock()
+		created++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"csvlt_1234567890","type":"vault","version":1}`))
+	}))
+	t.Cleanup(srv.Close)
+	clientArgs := []string{
+		"-addr", srv.URL,
+		"-keyring-type", "none",
+		"-scope-id", "p_1234567890",
+		"-local-preflight",
+	}
+	run := func(t *testing.T, format string, args ...string) (code int, ui *cli.MockUi) {
+		t.Helper()
+		created = 0
+		ui = cli.NewMockUi()
+		c := &VaultCommand{Command: base.NewCommand(&base.BoundaryUI{Ui: ui, Format: format}), Func: "create"}
+		return c.Run(append(clientArgs, args...)), ui
+	}
+
+	t.Run("passed", func(t *testing.T) {
+		assert := assert.New(t)
+		code, ui := run(t, "table", "-vault-address", vaultSrv.URL, "-vault-token", "s.good", "-vault-namespace", "ns1")
+		assert.Equal(base.CommandSuccess, code, ui.ErrorWriter.String())
+
+		out := ui.OutputWriter.String()
+		assert.Contains(out, "Local preflight (from this host, not the controller):")
+		assert.Regexp(`Result:\s+passed`, out)
+		assert.Equal(1, created)
+	})
+	t.Run("passed-json", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		code, ui := run(t, "json", "-vault-address", vaultSrv.URL, "-vault-token", "s.good", "-vault-namespace", "ns1")
+		require.Equal(base.CommandSuccess, code, ui.ErrorWriter.String())
+
+		// the create's item is the only output
+		var got map[string]interface{}
+		require.NoError(json.Unmarshal(ui.OutputWriter.Bytes(), &got))
+		assert.Equal(1, created)
+	})
+	t.Run("failed", func(t *testing.T) {
+		assert := assert.New(t)
+		code, ui := run(t, "table", "-vault-address", vaultSrv.URL, "-vault-token", "s.bad", "-vault-namespace", "ns1")
+		assert.Equal(base.CommandUserError, code)
+
+		errOut := ui.ErrorWriter.String()
+		assert.Regexp(`Result:\s+failed`, errOut)
+		assert.Regexp(`Status:\s+403`, errOut)
+		assert.Regexp(`Diagnostic:\s+permission denied`, errOut)
+		assert.Zero(created)
+	})
+	t.Run("failed-json", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		code, ui := run(t, "json", "-vault-address", vaultSrv.URL, "-vault-token", "s.bad", "-vault-namespace", "ns1")
+		assert.Equal(base.CommandUserError, code)
+
+		var got preflightResult
+		require.NoError(json.Unmarshal(ui.OutputWriter.Bytes(), &got))
+		assert.Equal(preflightResult{
+			Address:    vaultSrv.URL,
+			Namespace:  "ns1",
+			Status:     http.StatusForbidden,
+			Diagnostic: "permission denied",
+		}, got)
+		assert.Zero(created)
+	})
+	t.Run("environment-ignored", func(t *testing.T) {
+		// the environment would pass the preflight, but only the flags are
+		// used
+		testSetenv(t, "VAULT_ADDR", vaultSrv.URL)
+		testSetenv(t, "VAULT_TOKEN", "s.good")
+		testSetenv(t, "VAULT_NAMESPACE", "ns1")
+		testSetenv(t, "VAULT_SKIP_VERIFY", "not-a-bool")
+
+		assert := assert.New(t)
+		code, ui := run(t, "table", "-vault-address", vaultSrv.URL, "-vault-token", "s.bad")
+		assert.Equal(base.CommandUserError, code)
+		assert.Regexp(`Diagnostic:\s+permission denied`, ui.ErrorWriter.String())
+		assert.Zero(created)
+	})
+	t.Run("missing-token", func(t *testing.T) {
+		assert := assert.New(t)
+		code, ui := run(t, "table", "-vault-address", vaultSrv.URL)
+		assert.Equal(base.CommandUserError, code)
+		assert.Contains(ui.ErrorWriter.String(), "-vault-token is required for the preflight")
+		assert.Zero(created)
+	})
+	t.Run("unreachable", func(t *testing.T) {
+		assert := assert.New(t)
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closed.Close()
+		code, ui := run(t, "table", "-vault-address", closed.URL, "-vault-token", "s.good")
+		assert.Equal(base.CommandUserError, code)
+		assert.Regexp(`Result:\s+failed`, ui.ErrorWriter.String())
+		assert.Contains(ui.ErrorWriter.String(), "connection refused")
+		assert.Zero(created)
+	})
+}
+
+// testClientCertificate returns the PEM of a self-signed client certificate
+// and its key.
+func testClientCertificate(t *testing.T) (cert, key string) {
+	t.Helper()
+	require := require.New(t)
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "boundary"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	require.NoError(err)
+	keyDer, err := x509.MarshalECPrivateKey(priv)
+	require.NoError(err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}))
+}
+
+func TestVaultCommand_localPreflightInlinePEM(t *testing.T) {
+	// the Vault server requires a client certificate
+	vaultSrv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if len(r.TLS.PeerCertificates) == 0 || r.Header.Get("X-Vault-Token") != "s.good" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"id":"s.good"}}`))
+	}))
+	vaultSrv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	vaultSrv.StartTLS()
+	t.Cleanup(vaultSrv.Close)
+	caCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: vaultSrv.Certificate().Raw}))
+	clientCert, clientKey := testClientCertificate(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"csvlt_1234567890","type":"vault","version":1}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	assert := assert.New(t)
+	ui := cli.NewMockUi()
+	c := &VaultCommand{Command: base.NewCommand(ui), Func: "create"}
+	code := c.Run([]string{
+		"-addr", srv.URL,
+		"-keyring-type", "none",
+		"-scope-id", "p_1234567890",
+		"-local-preflight",
+		"-vault-address", vaultSrv.URL,
+		"-vault-token", "s.good",
+		"-vault-ca-cert", caCert,
+		"-vault-client-certificate", clientCert,
+		"-vault-client-certificate-key", clientKey,
+	})
+	assert.Equal(base.CommandSuccess, code, ui.ErrorWriter.String())
+	assert.Regexp(`Result:\s+passed`, ui.OutputWriter.String())
+}
+
+func TestVaultCommand_testConnection(t *testing.T) {
+	// the controller only connects with s.good, and records the requests it
+	// receives
+	var l sync.Mutex
+	var tested, created int
+	var testedBody map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l.Lock()
+		defer l.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/v1/credential-stores:test-connection" {
+			created++
+			_, _ = w.Write([]byte(`{"id":"csvlt_1234567890","type":"vault","version":1}`))
+			return
+		}
+		tested++
+		testedBody = nil
+		_ = json.NewDecoder(r.Body).Decode(&testedBody)
+		attrs, _ := testedBody["attributes"].(map[string]interface{})
+		if attrs["token"] != "s.good" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"kind":"InvalidArgument","message":"unable to lookup vault token: permission denied"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+	run := func(t *testing.T, format string, args ...string) (code int, ui *cli.MockUi) {
+		t.Helper()
+		tested, created = 0, 0
+		ui = cli.NewMockUi()
+		c := &VaultCommand{Command: base.NewCommand(&base.BoundaryUI{Ui: ui, Format: format}), Func: "create"}
+		return c.Run(append([]string{
+			"-addr", srv.URL,
+			"-keyring-type", "none",
+			"-scope-id", "p_1234567890",
+			"-test-connection",
+			"-vault-address", "https://vault:8200",
+		}, args...)), ui
+	}
+
+	t.Run("passed", func(t *testing.T) {
+		assert := assert.New(t)
+		code, ui := run(t, "table", "-vault-token", "s.good")
+		assert.Equal(base.CommandSuccess, code, ui.ErrorWriter.String())
+
+		out := ui.OutputWriter.String()
+		assert.Contains(out, "Connection test (from the controller):")
+		assert.Regexp(`Result:\s+passed`, out)
+		assert.Equal(1, tested)
+		assert.Equal(1, created)
+		assert.Equal("vault", testedBody["type"])
+		assert.Equal("p_1234567890", testedBody["scope_id"])
+	})
+	t.Run("passed-json", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		code, ui := run(t, "json", "-vault-token", "s.good")
+		require.Equal(base.CommandSuccess, code, ui.ErrorWriter.String())
+
+		// the create's item is the only output
+		var got map[string]interface{}
+		require.NoError(json.Unmarshal(ui.OutputWriter.Bytes(), &got))
+		assert.Equal(1, created)
+	})
+	t.Run("failed", func(t *testing.T) {
+		assert := assert.New(t)
+		code, ui := run(t, "table", "-vault-token", "s.bad")
+		assert.Equal(base.CommandApiError, code)
+		assert.Contains(ui.ErrorWriter.String(), "test-connection")
+		assert.Contains(ui.ErrorWriter.String(), "unable to lookup vault token: permission denied")
+		assert.Equal(1, tested)
+		assert.Zero(created)
+	})
+	t.Run("show-options", func(t *testing.T) {
+		assert := assert.New(t)
+		code, ui := run(t, "table", "-vault-token", "s.good", "-show-options")
+		assert.Equal(base.CommandUserError, code)
+		assert.Contains(ui.ErrorWriter.String(), "-show-options can't be used with -test-connection")
+		assert.Zero(tested)
+		assert.Zero(created)
+	})
+}
@@ -36,6 +36,7 @@ type Command struct {
 	SighupCh      chan struct{}
 	childSighupCh []chan struct{}
 	ReloadedCh    chan struct{}
+	SigUSR1Ch     chan struct{}
 	SigUSR2Ch     chan struct{}
 
 	Config     *config.Config
@@ -574,6 +575,9 @@ func (c *Command) Run(args []string) int {
 
 			shutdownTriggered = true
 
+		case <-c.SigUSR1Ch:
+			c.ReopenEventSinks(c.Config.PidFile)
+
 		case <-c.SigUSR2Ch:
 			buf := make([]byte, 32*1024*1024)
 			n := runtime.Stack(buf[:], true)
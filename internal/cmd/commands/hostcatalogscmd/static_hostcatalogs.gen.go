@@ -4,6 +4,7 @@ package hostcatalogscmd
 import (
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/hashicorp/boundary/api"
 	"github.com/hashicorp/boundary/api/hostcatalogs"
@@ -15,7 +16,7 @@ import (
 )
 
 func initStaticFlags() {
-	flagsOnce.Do(func() {
+	flagsStaticOnce.Do(func() {
 		extraFlags := extraStaticActionsFlagsMapFunc()
 		for k, v := range extraFlags {
 			flagsStaticMap[k] = append(flagsStaticMap[k], v...)
@@ -233,6 +234,8 @@ func (c *StaticCommand) Run(args []string) int {
 }
 
 var (
+	flagsStaticOnce = new(sync.Once)
+
 	extraStaticActionsFlagsMapFunc = func() map[string][]string { return nil }
 	extraStaticSynopsisFunc        = func(*StaticCommand) string { return "" }
 	extraStaticFlagsFunc           = func(*StaticCommand, *base.FlagSets, *base.FlagSet) {}
@@ -0,0 +1,179 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/boundary/internal/cmd/base"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+var (
+	_ cli.Command             = (*ReopenEventSinksCommand)(nil)
+	_ cli.CommandAutocomplete = (*ReopenEventSinksCommand)(nil)
+)
+
+// reopenResultsTimeout bounds how long the command waits for the server to
+// write the results of reopening its event sinks.
+const reopenResultsTimeout = 10 * time.Second
+
+// ReopenEventSinksCommand tells a running Boundary server to reopen its event
+// sinks, without reloading the rest of the server as SIGHUP does, and reports
+// the result of each sink.
+type ReopenEventSinksCommand struct {
+	*base.Command
+
+	flagPidFile string
+
+	// sendSignal and resultsTimeout are replaced by tests, which don't signal
+	// a real server.
+	sendSignal     func(pid int) error
+	resultsTimeout time.Duration
+}
+
+func (c *ReopenEventSinksCommand) Synopsis() string {
+	return "Reopen the event sinks of a running Boundary server"
+}
+
+func (c *ReopenEventSinksCommand) Help() string {
+	helpText := `
+Usage: boundary server reopen-event-sinks [options]
+
+  Tell a running Boundary server (or dev environment) to reopen its event
+  sinks, for example after its event files have been rotated by logrotate:
+
+      $ boundary server reopen-event-sinks -pid-file=/var/run/boundary.pid
+
+  This sends SIGUSR1 to the process of the server's pid file, which must be set
+  with pid_file in the server's configuration. The server writes whether each
+  sink was reopened next to its pid file, and the command prints it, exiting
+  with an error if any sink failed to reopen. Unlike SIGHUP, nothing else is
+  reloaded.
+
+` + c.Flags().Help()
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ReopenEventSinksCommand) Flags() *base.FlagSets {
+	set := c.FlagSet(base.FlagSetNone)
+
+	f := set.NewFlagSet("Command Options")
+
+	f.StringVar(&base.StringVar{
+		Name:       "pid-file",
+		Target:     &c.flagPidFile,
+		Completion: complete.PredictFiles("*"),
+		Usage:      "The path of the pid file of the running Boundary server, as set by pid_file in its configuration.",
+	})
+
+	return set
+}
+
+func (c *ReopenEventSinksCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *ReopenEventSinksCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+func (c *ReopenEventSinksCommand) Run(args []string) int {
+	f := c.Flags()
+	if err := f.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return base.CommandUserError
+	}
+	if c.flagPidFile == "" {
+		c.UI.Error("The server's pid file must be provided via -pid-file")
+		return base.CommandUserError
+	}
+	b, err := ioutil.ReadFile(c.flagPidFile)
+	if err != nil {
+		c.UI.Error(fmt.Errorf("Error reading pid file: %w", err).Error())
+		return base.CommandUserError
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil || pid <= 0 {
+		c.UI.Error(fmt.Sprintf("Pid file %s doesn't hold a valid process id", c.flagPidFile))
+		return base.CommandUserError
+	}
+
+	// the results of a previous reopen are removed, so only the results of
+	// this one are read back
+	resultsPath := base.ReopenEventSinksResultsPath(c.flagPidFile)
+	if err := os.Remove(resultsPath); err != nil && !os.IsNotExist(err) {
+		c.UI.Error(fmt.Errorf("Error removing previous results: %w", err).Error())
+		return base.CommandCliError
+	}
+	sendSignal := c.sendSignal
+	if sendSignal == nil {
+		sendSignal = sendReopenSignal
+	}
+	if err := sendSignal(pid); err != nil {
+		c.UI.Error(fmt.Errorf("Error signaling server process %d: %w", pid, err).Error())
+		return base.CommandCliError
+	}
+	results, err := c.waitForResults(resultsPath, pid)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return base.CommandCliError
+	}
+	if results.Error != "" {
+		c.UI.Error(fmt.Sprintf("Server process %d was unable to reopen its event sinks: %s", pid, results.Error))
+		return base.CommandCliError
+	}
+
+	var failed int
+	output := []string{fmt.Sprintf("Event sinks of server process %d:", pid)}
+	for _, s := range results.Sinks {
+		if !s.Reopened {
+			failed++
+			output = append(output, fmt.Sprintf("  %s: failed: %s", s.Name, s.Error))
+			continue
+		}
+		output = append(output, fmt.Sprintf("  %s: reopened", s.Name))
+	}
+	if failed > 0 {
+		c.UI.Error(strings.Join(output, "\n"))
+		c.UI.Error(fmt.Sprintf("%d of %d event sinks failed to reopen", failed, len(results.Sinks)))
+		return base.CommandCliError
+	}
+	c.UI.Output(strings.Join(output, "\n"))
+	return base.CommandSuccess
+}
+
+// waitForResults waits for the server to write the results of reopening its
+// event sinks to path.
+func (c *ReopenEventSinksCommand) waitForResults(path string, pid int) (*base.ReopenEventSinksResults, error) {
+	timeout := c.resultsTimeout
+	if timeout == 0 {
+		timeout = reopenResultsTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		b, err := ioutil.ReadFile(path)
+		switch {
+		case err == nil:
+			var results base.ReopenEventSinksResults
+			if err := json.Unmarshal(b, &results); err != nil {
+				return nil, fmt.Errorf("Error parsing the results of server process %d: %w", pid, err)
+			}
+			if results.Pid != pid {
+				return nil, fmt.Errorf("The results were written by process %d rather than server process %d", results.Pid, pid)
+			}
+			return &results, nil
+		case !os.IsNotExist(err):
+			return nil, fmt.Errorf("Error reading the results of server process %d: %w", pid, err)
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("Server process %d didn't report whether its event sinks were reopened within %s; check that pid_file is set in its configuration and its output for errors", pid, timeout)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -0,0 +1,11 @@
+// +build !windows
+
+package server
+
+import "syscall"
+
+// sendReopenSignal sends SIGUSR1 to the server process, which reopens its
+// event sinks.
+func sendReopenSignal(pid int) error {
+	return syscall.Kill(pid, syscall.SIGUSR1)
+}
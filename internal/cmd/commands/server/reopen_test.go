@@ -0,0 +1,135 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/boundary/internal/cmd/base"
+	"github.com/mitchellh/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReopenEventSinksCommand(t *testing.T) {
+	const pid = 4242
+	// newCommand returns a command whose signal is received by a fake server,
+	// which writes the results to the results path of the pid file
+	newCommand := func(t *testing.T, pidPath string, results *base.ReopenEventSinksResults) (*ReopenEventSinksCommand, *cli.MockUi, *int) {
+		t.Helper()
+		ui := cli.NewMockUi()
+		var signaled int
+		c := &ReopenEventSinksCommand{
+			Command:        base.NewCommand(ui),
+			resultsTimeout: 200 * time.Millisecond,
+			sendSignal: func(got int) error {
+				signaled = got
+				if results == nil {
+					return nil
+				}
+				b, err := json.Marshal(results)
+				require.NoError(t, err)
+				return ioutil.WriteFile(base.ReopenEventSinksResultsPath(pidPath), b, 0o600)
+			},
+		}
+		return c, ui, &signaled
+	}
+	pidFile := func(t *testing.T) string {
+		t.Helper()
+		pidPath := filepath.Join(t.TempDir(), "boundary.pid")
+		require.NoError(t, ioutil.WriteFile(pidPath, []byte(fmt.Sprintf("%d\n", pid)), 0o600))
+		return pidPath
+	}
+
+	t.Run("reopened", func(t *testing.T) {
+		assert := assert.New(t)
+		pidPath := pidFile(t)
+		c, ui, signaled := newCommand(t, pidPath, &base.ReopenEventSinksResults{
+			Pid: pid,
+			Sinks: []base.ReopenEventSinksResult{
+				{Name: "file-sink", Reopened: true},
+				{Name: "stderr", Reopened: true},
+			},
+		})
+		assert.Equal(base.CommandSuccess, c.Run([]string{"-pid-file", pidPath}), ui.ErrorWriter.String())
+		assert.Equal(pid, *signaled)
+		out := ui.OutputWriter.String()
+		assert.Contains(out, "file-sink: reopened")
+		assert.Contains(out, "stderr: reopened")
+	})
+	t.Run("failed-sink", func(t *testing.T) {
+		assert := assert.New(t)
+		pidPath := pidFile(t)
+		c, ui, _ := newCommand(t, pidPath, &base.ReopenEventSinksResults{
+			Pid: pid,
+			Sinks: []base.ReopenEventSinksResult{
+				{Name: "kafka-sink", Error: "brokers unavailable"},
+				{Name: "stderr", Reopened: true},
+			},
+		})
+		assert.Equal(base.CommandCliError, c.Run([]string{"-pid-file", pidPath}))
+		errOut := ui.ErrorWriter.String()
+		assert.Contains(errOut, "kafka-sink: failed: brokers unavailable")
+		assert.Contains(errOut, "stderr: reopened")
+		assert.Contains(errOut, "1 of 2 event sinks failed to reopen")
+	})
+	t.Run("unable-to-reopen", func(t *testing.T) {
+		assert := assert.New(t)
+		pidPath := pidFile(t)
+		c, ui, _ := newCommand(t, pidPath, &base.ReopenEventSinksResults{Pid: pid, Error: "eventer is closed"})
+		assert.Equal(base.CommandCliError, c.Run([]string{"-pid-file", pidPath}))
+		assert.Contains(ui.ErrorWriter.String(), "unable to reopen its event sinks: eventer is closed")
+	})
+	t.Run("stale-results", func(t *testing.T) {
+		assert := assert.New(t)
+		pidPath := pidFile(t)
+		// the results of an earlier reopen aren't reported for this one,
+		// which the server never answers
+		b, err := json.Marshal(&base.ReopenEventSinksResults{Pid: pid, Sinks: []base.ReopenEventSinksResult{{Name: "stale", Reopened: true}}})
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(base.ReopenEventSinksResultsPath(pidPath), b, 0o600))
+		c, ui, _ := newCommand(t, pidPath, nil)
+		assert.Equal(base.CommandCliError, c.Run([]string{"-pid-file", pidPath}))
+		assert.Contains(ui.ErrorWriter.String(), "didn't report whether its event sinks were reopened")
+		assert.NotContains(ui.OutputWriter.String(), "stale")
+	})
+	t.Run("other-process", func(t *testing.T) {
+		assert := assert.New(t)
+		pidPath := pidFile(t)
+		c, ui, _ := newCommand(t, pidPath, &base.ReopenEventSinksResults{Pid: pid + 1})
+		assert.Equal(base.CommandCliError, c.Run([]string{"-pid-file", pidPath}))
+		assert.Contains(ui.ErrorWriter.String(), fmt.Sprintf("written by process %d", pid+1))
+	})
+	t.Run("signal-error", func(t *testing.T) {
+		assert := assert.New(t)
+		pidPath := pidFile(t)
+		c, ui, _ := newCommand(t, pidPath, nil)
+		c.sendSignal = func(int) error { return errors.New("no such process") }
+		assert.Equal(base.CommandCliError, c.Run([]string{"-pid-file", pidPath}))
+		assert.Contains(ui.ErrorWriter.String(), fmt.Sprintf("Error signaling server process %d: no such process", pid))
+	})
+	t.Run("missing-pid-file", func(t *testing.T) {
+		assert := assert.New(t)
+		c, ui, _ := newCommand(t, "", nil)
+		assert.Equal(base.CommandUserError, c.Run(nil))
+		assert.Contains(ui.ErrorWriter.String(), "-pid-file")
+
+		c, ui, signaled := newCommand(t, "", nil)
+		assert.Equal(base.CommandUserError, c.Run([]string{"-pid-file", filepath.Join(t.TempDir(), "missing.pid")}))
+		assert.Contains(ui.ErrorWriter.String(), "Error reading pid file")
+		assert.Zero(*signaled)
+	})
+	t.Run("invalid-pid-file", func(t *testing.T) {
+		assert := assert.New(t)
+		pidPath := filepath.Join(t.TempDir(), "boundary.pid")
+		require.NoError(t, ioutil.WriteFile(pidPath, []byte("not-a-pid"), 0o600))
+		c, ui, signaled := newCommand(t, pidPath, nil)
+		assert.Equal(base.CommandUserError, c.Run([]string{"-pid-file", pidPath}))
+		assert.Contains(ui.ErrorWriter.String(), "doesn't hold a valid process id")
+		assert.Zero(*signaled)
+	})
+}
@@ -0,0 +1,10 @@
+// +build windows
+
+package server
+
+import "errors"
+
+// sendReopenSignal isn't supported on Windows.
+func sendReopenSignal(int) error {
+	return errors.New("reopening event sinks is not supported on Windows")
+}
@@ -38,6 +38,7 @@ type Command struct {
 	*base.Server
 
 	SighupCh  chan struct{}
+	SigUSR1Ch chan struct{}
 	SigUSR2Ch chan struct{}
 
 	Config     *config.Config
@@ -642,6 +643,9 @@ func (c *Command) WaitForInterrupt() int {
 				c.UI.Error(fmt.Errorf("Error(s) were encountered during reload: %w", err).Error())
 			}
 
+		case <-c.SigUSR1Ch:
+			c.ReopenEventSinks(c.Config.PidFile)
+
 		case <-c.SigUSR2Ch:
 			buf := make([]byte, 32*1024*1024)
 			n := runtime.Stack(buf[:], true)
@@ -4,6 +4,7 @@ package targetscmd
 import (
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/hashicorp/boundary/api"
 	"github.com/hashicorp/boundary/api/targets"
@@ -15,7 +16,7 @@ import (
 )
 
 func initTcpFlags() {
-	flagsOnce.Do(func() {
+	flagsTcpOnce.Do(func() {
 		extraFlags := extraTcpActionsFlagsMapFunc()
 		for k, v := range extraFlags {
 			flagsTcpMap[k] = append(flagsTcpMap[k], v...)
@@ -235,6 +236,8 @@ func (c *TcpCommand) Run(args []string) int {
 }
 
 var (
+	flagsTcpOnce = new(sync.Once)
+
 	extraTcpActionsFlagsMapFunc = func() map[string][]string { return nil }
 	extraTcpSynopsisFunc        = func(*TcpCommand) string { return "" }
 	extraTcpFlagsFunc           = func(*TcpCommand, *base.FlagSets, *base.FlagSet) {}
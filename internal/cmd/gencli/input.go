@@ -165,13 +165,14 @@ var inputStructs = map[string][]*cmdInfo{
 	},
 	"credentialstores": {
 		{
-			ResourceType:     resource.CredentialStore.String(),
-			Pkg:              "credentialstores",
-			StdActions:       []string{"read", "delete", "list"},
-			IsAbstractType:   true,
-			HasExtraHelpFunc: true,
-			Container:        "Scope",
-			HasId:            true,
+			ResourceType:        resource.CredentialStore.String(),
+			Pkg:                 "credentialstores",
+			StdActions:          []string{"read", "delete", "list"},
+			IsAbstractType:      true,
+			HasExtraCommandVars: true,
+			HasExtraHelpFunc:    true,
+			Container:           "Scope",
+			HasId:               true,
 		},
 		{
 			ResourceType:         resource.CredentialStore.String(),
@@ -96,7 +96,7 @@ import (
 )
 
 func init{{ camelCase .SubActionPrefix }}Flags() {
-	flagsOnce.Do(func() {
+	flags{{ camelCase .SubActionPrefix }}Once.Do(func() {
 		extraFlags := extra{{ camelCase .SubActionPrefix }}ActionsFlagsMapFunc()
 		for k, v := range extraFlags {
 			flags{{ camelCase .SubActionPrefix }}Map[k] = append(flags{{ camelCase .SubActionPrefix }}Map[k], v...)
@@ -429,9 +429,8 @@ func (c *{{ camelCase .SubActionPrefix }}Command) Run(args []string) int {
 }
 
 var (
-	{{ if not .SubActionPrefix }}
-	flagsOnce = new(sync.Once)
-	{{ end }}
+	flags{{ camelCase .SubActionPrefix }}Once = new(sync.Once)
+
 	extra{{ camelCase .SubActionPrefix }}ActionsFlagsMapFunc = func() map[string][]string { return nil }
 	extra{{ camelCase .SubActionPrefix }}SynopsisFunc = func(*{{ camelCase .SubActionPrefix }}Command) string { return "" }
 	extra{{ camelCase .SubActionPrefix }}FlagsFunc = func(*{{ camelCase .SubActionPrefix }}Command, *base.FlagSets, *base.FlagSet) {}
@@ -77,24 +77,10 @@ func (r *Repository) CreateCredentialStore(ctx context.Context, cs *CredentialSt
 	if err != nil {
 		return nil, errors.Wrap(err, op, errors.WithMsg("unable to create vault client"))
 	}
-	tokenLookup, err := client.lookupToken()
-	if err != nil {
-		return nil, errors.Wrap(err, op, errors.WithMsg("unable to lookup vault token"))
-	}
-	if err := validateTokenLookup(op, tokenLookup); err != nil {
+	if err := validateToken(op, client); err != nil {
 		return nil, err
 	}
 
-	available, err := client.capabilities(requiredCapabilities.paths())
-	if err != nil {
-		return nil, errors.Wrap(err, op, errors.WithMsg("unable to get vault capabilities"))
-	}
-	missing := available.missing(requiredCapabilities)
-	if len(missing) > 0 {
-		return nil,
-			errors.New(errors.VaultTokenMissingCapabilities, op, fmt.Sprintf("missing capabilites: %v", missing))
-	}
-
 	renewedToken, err := client.renewToken()
 	if err != nil {
 		return nil, errors.Wrap(err, op, errors.WithMsg("unable to renew vault token"))
@@ -207,6 +193,65 @@ func (r *Repository) CreateCredentialStore(ctx context.Context, cs *CredentialSt
 	return newCredentialStore, nil
 }
 
+// CheckCredentialStoreConnection checks that the Vault server of cs can be
+// reached and that the Vault token of cs could be used to create cs, without
+// storing cs or renewing its token. cs must contain a valid ScopeId,
+// VaultAddress, and Vault token. CheckCredentialStoreConnection calls the
+// /sys/health, /auth/token/lookup-self and /sys/capabilities-self Vault
+// endpoints.
+func (r *Repository) CheckCredentialStoreConnection(ctx context.Context, cs *CredentialStore, _ ...Option) error {
+	const op = "vault.(Repository).CheckCredentialStoreConnection"
+	if cs == nil {
+		return errors.New(errors.InvalidParameter, op, "nil CredentialStore")
+	}
+	if cs.CredentialStore == nil {
+		return errors.New(errors.InvalidParameter, op, "nil embedded CredentialStore")
+	}
+	if cs.ScopeId == "" {
+		return errors.New(errors.InvalidParameter, op, "no scope id")
+	}
+	if len(cs.inputToken) == 0 {
+		return errors.New(errors.InvalidParameter, op, "no vault token")
+	}
+	if cs.VaultAddress == "" {
+		return errors.New(errors.InvalidParameter, op, "no vault address")
+	}
+	if cs.clientCert != nil && len(cs.clientCert.CertificateKey) == 0 {
+		return errors.New(errors.InvalidParameter, op, "client certificate without private key")
+	}
+
+	client, err := cs.client()
+	if err != nil {
+		return errors.Wrap(err, op, errors.WithMsg("unable to create vault client"))
+	}
+	if err := client.ping(); err != nil {
+		return errors.Wrap(err, op, errors.WithMsg("unable to reach vault"))
+	}
+	return validateToken(op, client)
+}
+
+// validateToken looks up the token of c and returns an error if it can't be
+// used by a credential store.
+func validateToken(op errors.Op, c *client) error {
+	tokenLookup, err := c.lookupToken()
+	if err != nil {
+		return errors.Wrap(err, op, errors.WithMsg("unable to lookup vault token"))
+	}
+	if err := validateTokenLookup(op, tokenLookup); err != nil {
+		return err
+	}
+
+	available, err := c.capabilities(requiredCapabilities.paths())
+	if err != nil {
+		return errors.Wrap(err, op, errors.WithMsg("unable to get vault capabilities"))
+	}
+	missing := available.missing(requiredCapabilities)
+	if len(missing) > 0 {
+		return errors.New(errors.VaultTokenMissingCapabilities, op, fmt.Sprintf("missing capabilites: %v", missing))
+	}
+	return nil
+}
+
 func validateTokenLookup(op errors.Op, s *vault.Secret) error {
 	if s.Data == nil {
 		return errors.New(errors.InvalidParameter, op, "vault secret is not a token lookup")
@@ -222,6 +222,94 @@ func TestRepository_CreateCredentialStoreNonResource(t *testing.T) {
 	}
 }
 
+func TestRepository_CheckCredentialStoreConnection(t *testing.T) {
+	t.Parallel()
+	conn, _ := db.TestSetup(t, "postgres")
+	rw := db.New(conn)
+	wrapper := db.TestWrapper(t)
+
+	tests := []struct {
+		name      string
+		tls       TestVaultTLS
+		tokenOpts []TestOption
+		wantErr   errors.Code
+	}{
+		{
+			name: "no-tls-valid-token",
+		},
+		{
+			name: "client-tls-valid-token",
+			tls:  TestClientTLS,
+		},
+		{
+			name:      "token-missing-capabilities",
+			tokenOpts: []TestOption{WithPolicies([]string{"default"})},
+			wantErr:   errors.VaultTokenMissingCapabilities,
+		},
+		{
+			name:      "no-tls-token-not-renewable",
+			tokenOpts: []TestOption{TestRenewableToken(false)},
+			wantErr:   errors.VaultTokenNotRenewable,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			ctx := context.Background()
+			kms := kms.TestKms(t, conn, wrapper)
+			sche := scheduler.TestScheduler(t, conn, wrapper)
+			repo, err := NewRepository(rw, rw, kms, sche)
+			require.NoError(err)
+			require.NotNil(repo)
+			_, prj := iam.TestScopes(t, iam.TestRepo(t, conn, wrapper))
+
+			v := NewTestVaultServer(t, WithTestVaultTLS(tt.tls))
+			_, token := v.CreateToken(t, tt.tokenOpts...)
+
+			var opts []Option
+			if tt.tls == TestClientTLS {
+				opts = append(opts, WithCACert(v.CaCert))
+				clientCert, err := NewClientCertificate(v.ClientCert, v.ClientKey)
+				require.NoError(err)
+				opts = append(opts, WithClientCert(clientCert))
+			}
+
+			in, err := NewCredentialStore(prj.GetPublicId(), v.Addr, []byte(token), opts...)
+			require.NoError(err)
+			err = repo.CheckCredentialStoreConnection(ctx, in)
+			if tt.wantErr != 0 {
+				assert.Truef(errors.Match(errors.T(tt.wantErr), err), "want err: %q got: %q", tt.wantErr, err)
+			} else {
+				assert.NoError(err)
+			}
+
+			// nothing is stored
+			stores, err := repo.ListCredentialStores(ctx, []string{prj.GetPublicId()})
+			require.NoError(err)
+			assert.Empty(stores)
+		})
+	}
+
+	t.Run("unreachable", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		kms := kms.TestKms(t, conn, wrapper)
+		sche := scheduler.TestScheduler(t, conn, wrapper)
+		repo, err := NewRepository(rw, rw, kms, sche)
+		require.NoError(err)
+		_, prj := iam.TestScopes(t, iam.TestRepo(t, conn, wrapper))
+
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(err)
+		addr := fmt.Sprintf("http://%s", l.Addr().String())
+		require.NoError(l.Close())
+
+		in, err := NewCredentialStore(prj.GetPublicId(), addr, []byte("token"))
+		require.NoError(err)
+		assert.Error(repo.CheckCredentialStoreConnection(context.Background(), in))
+	})
+}
+
 func TestRepository_LookupCredentialStore(t *testing.T) {
 	t.Parallel()
 	conn, _ := db.TestSetup(t, "postgres")
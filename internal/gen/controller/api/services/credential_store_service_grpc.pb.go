@@ -44,6 +44,13 @@ type CredentialStoreServiceClient interface {
 	// DeleteCredentialStore removes a Credential Store from Boundary. If the Credential Store id
 	// is malformed or not provided an error is returned.
 	DeleteCredentialStore(ctx context.Context, in *DeleteCredentialStoreRequest, opts ...grpc.CallOption) (*DeleteCredentialStoreResponse, error)
+	// TestCredentialStoreConnection checks that the controller can connect to
+	// the Credential Store's backing service with the provided Credential Store's
+	// configuration, without storing it.  The provided request must include the
+	// scope in which the Credential Store would be created.  If the connection
+	// or the Credential Store's credentials are not usable an error describing
+	// the failure is returned.
+	TestCredentialStoreConnection(ctx context.Context, in *TestCredentialStoreConnectionRequest, opts ...grpc.CallOption) (*TestCredentialStoreConnectionResponse, error)
 }
 
 type credentialStoreServiceClient struct {
@@ -99,6 +106,15 @@ func (c *credentialStoreServiceClient) DeleteCredentialStore(ctx context.Context
 	return out, nil
 }
 
+func (c *credentialStoreServiceClient) TestCredentialStoreConnection(ctx context.Context, in *TestCredentialStoreConnectionRequest, opts ...grpc.CallOption) (*TestCredentialStoreConnectionResponse, error) {
+	out := new(TestCredentialStoreConnectionResponse)
+	err := c.cc.Invoke(ctx, "/controller.api.services.v1.CredentialStoreService/TestCredentialStoreConnection", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CredentialStoreServiceServer is the server API for CredentialStoreService service.
 // All implementations must embed UnimplementedCredentialStoreServiceServer
 // for forward compatibility
@@ -129,6 +145,13 @@ type CredentialStoreServiceServer interface {
 	// DeleteCredentialStore removes a Credential Store from Boundary. If the Credential Store id
 	// is malformed or not provided an error is returned.
 	DeleteCredentialStore(context.Context, *DeleteCredentialStoreRequest) (*DeleteCredentialStoreResponse, error)
+	// TestCredentialStoreConnection checks that the controller can connect to
+	// the Credential Store's backing service with the provided Credential Store's
+	// configuration, without storing it.  The provided request must include the
+	// scope in which the Credential Store would be created.  If the connection
+	// or the Credential Store's credentials are not usable an error describing
+	// the failure is returned.
+	TestCredentialStoreConnection(context.Context, *TestCredentialStoreConnectionRequest) (*TestCredentialStoreConnectionResponse, error)
 	mustEmbedUnimplementedCredentialStoreServiceServer()
 }
 
@@ -151,6 +174,9 @@ func (UnimplementedCredentialStoreServiceServer) UpdateCredentialStore(context.C
 func (UnimplementedCredentialStoreServiceServer) DeleteCredentialStore(context.Context, *DeleteCredentialStoreRequest) (*DeleteCredentialStoreResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteCredentialStore not implemented")
 }
+func (UnimplementedCredentialStoreServiceServer) TestCredentialStoreConnection(context.Context, *TestCredentialStoreConnectionRequest) (*TestCredentialStoreConnectionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TestCredentialStoreConnection not implemented")
+}
 func (UnimplementedCredentialStoreServiceServer) mustEmbedUnimplementedCredentialStoreServiceServer() {
 }
 
@@ -255,6 +281,24 @@ func _CredentialStoreService_DeleteCredentialStore_Handler(srv interface{}, ctx
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CredentialStoreService_TestCredentialStoreConnection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TestCredentialStoreConnectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CredentialStoreServiceServer).TestCredentialStoreConnection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/controller.api.services.v1.CredentialStoreService/TestCredentialStoreConnection",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CredentialStoreServiceServer).TestCredentialStoreConnection(ctx, req.(*TestCredentialStoreConnectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CredentialStoreService_ServiceDesc is the grpc.ServiceDesc for CredentialStoreService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -282,6 +326,10 @@ var CredentialStoreService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteCredentialStore",
 			Handler:    _CredentialStoreService_DeleteCredentialStore_Handler,
 		},
+		{
+			MethodName: "TestCredentialStoreConnection",
+			Handler:    _CredentialStoreService_TestCredentialStoreConnection_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "controller/api/services/v1/credential_store_service.proto",
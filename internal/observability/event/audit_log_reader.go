@@ -0,0 +1,190 @@
+package event
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// gzipMagic are the first bytes of gzipped data.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// AuditLogReader reads the audit events from a JSON audit log written by a
+// FileSink.  Events of other types in the log are skipped.  Use it like a
+// bufio.Scanner:
+//
+//	r, err := event.NewAuditLogReader(f)
+//	...
+//	for r.Next() {
+//		a := r.Audit()
+//		...
+//	}
+//	if err := r.Err(); err != nil {
+//		...
+//	}
+//
+// A header line (see SinkConfig.HeaderLine) at the start of the log is
+// skipped.  Gzipped logs are decompressed transparently.  Encrypted logs must
+// be decrypted with DecryptAuditFile before they're read.
+type AuditLogReader struct {
+	scanner *bufio.Scanner
+	lineNum int
+	current *audit
+	err     error
+}
+
+// auditLogLine is a line of the log, as written by the eventlogger's JSON
+// formatter.  Its created at timestamp depends on the sink's TimestampFormat,
+// and it isn't used since the audit has its own timestamp.
+type auditLogLine struct {
+	CreatedAt json.RawMessage `json:"created_at"`
+	EventType string          `json:"event_type"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// auditLogPayload is an audit event's payload.  Its request and response
+// details can't be unmarshaled into the audit's proto.Message fields, so
+// they're unmarshaled separately.
+type auditLogPayload struct {
+	audit
+	Request  *auditLogRequest  `json:"request,omitempty"`
+	Response *auditLogResponse `json:"response,omitempty"`
+}
+
+type auditLogRequest struct {
+	Operation string                 `json:"operation"`
+	Endpoint  string                 `json:"endpoint"`
+	Details   map[string]interface{} `json:"details"`
+}
+
+type auditLogResponse struct {
+	StatusCode int                    `json:"status_code,omitempty"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+}
+
+// NewAuditLogReader creates a new AuditLogReader which reads the log from r.
+// Gzipped data is detected and decompressed.
+func NewAuditLogReader(r io.Reader) (*AuditLogReader, error) {
+	const op = "event.NewAuditLogReader"
+	if r == nil {
+		return nil, fmt.Errorf("%s: missing reader: %w", op, ErrInvalidParameter)
+	}
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(gzipMagic))
+	switch {
+	case err == io.EOF:
+		// an empty (or tiny) log can't be gzipped
+	case err != nil:
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	var src io.Reader = br
+	if bytes.Equal(magic, gzipMagic) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("%s: unable to read gzipped log: %w", op, err)
+		}
+		src = gz
+	}
+	scanner := bufio.NewScanner(src)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), 64*1024*1024)
+	return &AuditLogReader{
+		scanner: scanner,
+	}, nil
+}
+
+// Next advances the reader to the next audit event, which is then available
+// via Audit.  It returns false when there are no more events or when an error
+// occurs, which is then available via Err.  Reading stops at the first
+// malformed line.
+func (r *AuditLogReader) Next() bool {
+	const op = "event.(AuditLogReader).Next"
+	r.current = nil
+	if r.err != nil {
+		return false
+	}
+	for r.scanner.Scan() {
+		r.lineNum++
+		b := bytes.TrimSpace(r.scanner.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+		if r.lineNum == 1 && !bytes.HasPrefix(b, []byte("{")) {
+			// the file's header line
+			continue
+		}
+		var line auditLogLine
+		if err := json.Unmarshal(b, &line); err != nil {
+			r.err = fmt.Errorf("%s: line %d is not a json event: %w", op, r.lineNum, err)
+			return false
+		}
+		if line.EventType != string(AuditType) {
+			continue
+		}
+		a, err := line.audit()
+		if err != nil {
+			r.err = fmt.Errorf("%s: line %d is not an audit event: %w", op, r.lineNum, err)
+			return false
+		}
+		r.current = a
+		return true
+	}
+	if err := r.scanner.Err(); err != nil {
+		r.err = fmt.Errorf("%s: %w", op, err)
+	}
+	return false
+}
+
+// Audit returns the audit event read by the last call to Next.
+func (r *AuditLogReader) Audit() *audit {
+	return r.current
+}
+
+// Err returns the first error encountered by the reader.
+func (r *AuditLogReader) Err() error {
+	return r.err
+}
+
+// audit returns the line's payload as an audit event.  Request and response
+// details are returned as a *structpb.Struct.
+func (l *auditLogLine) audit() (*audit, error) {
+	const op = "event.(auditLogLine).audit"
+	if len(l.Payload) == 0 {
+		return nil, fmt.Errorf("%s: missing payload: %w", op, ErrInvalidParameter)
+	}
+	var p auditLogPayload
+	if err := json.Unmarshal(l.Payload, &p); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	a := p.audit
+	if p.Request != nil {
+		a.Request = &Request{
+			Operation: p.Request.Operation,
+			Endpoint:  p.Request.Endpoint,
+		}
+		if p.Request.Details != nil {
+			details, err := structpb.NewStruct(p.Request.Details)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid request details: %w", op, err)
+			}
+			a.Request.Details = details
+		}
+	}
+	if p.Response != nil {
+		a.Response = &Response{
+			StatusCode: p.Response.StatusCode,
+		}
+		if p.Response.Details != nil {
+			details, err := structpb.NewStruct(p.Response.Details)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid response details: %w", op, err)
+			}
+			a.Response.Details = details
+		}
+	}
+	return &a, nil
+}
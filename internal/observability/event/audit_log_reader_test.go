@@ -0,0 +1,138 @@
+package event
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestAuditLogReader(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tmpFile, err := ioutil.TempFile("./", "tmp-audit-log-TestAuditLogReader")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.Remove(tmpFile.Name()) })
+
+	c := EventerConfig{
+		AuditEnabled:        true,
+		ObservationsEnabled: true,
+		Sinks: []SinkConfig{
+			{
+				Name:       "every-type-file-sink",
+				SinkType:   FileSink,
+				EventTypes: []Type{EveryType},
+				Format:     JSONSinkFormat,
+				Path:       "./",
+				FileName:   tmpFile.Name(),
+			},
+		},
+	}
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	e, err := NewEventer(testLogger, testLock, c)
+	require.NoError(t, err)
+
+	details, err := structpb.NewStruct(map[string]interface{}{"name": "test"})
+	require.NoError(t, err)
+	var wantIds []string
+	for i := 0; i < 3; i++ {
+		testAudit, err := newAudit("TestAuditLogReader",
+			WithAuth(&Auth{AccessorId: "at_1234567890", UserInfo: &UserInfo{UserId: "u_1234567890"}}),
+			WithRequest(&Request{Operation: "POST", Endpoint: "/v1/scopes", Details: details}),
+			WithResponse(&Response{StatusCode: 200, Details: details}),
+			WithFlush(),
+		)
+		require.NoError(t, err)
+		require.NoError(t, e.writeAudit(ctx, testAudit))
+		wantIds = append(wantIds, testAudit.Id)
+
+		// other event types in the log are skipped
+		testError, err := newError("TestAuditLogReader", fmt.Errorf("%s: no msg: test", ErrIo))
+		require.NoError(t, err)
+		require.NoError(t, e.writeError(ctx, testError))
+	}
+	log, err := ioutil.ReadFile(tmpFile.Name())
+	require.NoError(t, err)
+
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	_, err = gz.Write(log)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	readAll := func(t *testing.T, r *AuditLogReader) ([]*audit, error) {
+		t.Helper()
+		var got []*audit
+		for r.Next() {
+			got = append(got, r.Audit())
+		}
+		return got, r.Err()
+	}
+
+	t.Run("missing-reader", func(t *testing.T) {
+		_, err := NewAuditLogReader(nil)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	})
+	t.Run("empty", func(t *testing.T) {
+		r, err := NewAuditLogReader(strings.NewReader(""))
+		require.NoError(t, err)
+		got, err := readAll(t, r)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+	for name, data := range map[string][]byte{"plain": log, "gzipped": gzipped.Bytes()} {
+		data := data
+		t.Run(name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			r, err := NewAuditLogReader(bytes.NewReader(data))
+			require.NoError(err)
+			got, err := readAll(t, r)
+			require.NoError(err)
+			require.Len(got, len(wantIds))
+			for i, a := range got {
+				assert.Equal(wantIds[i], a.Id)
+				assert.Equal(string(ApiRequest), a.Type)
+				assert.Equal("u_1234567890", a.Auth.UserInfo.UserId)
+				assert.Equal("POST", a.Request.Operation)
+				assert.Equal("/v1/scopes", a.Request.Endpoint)
+				assert.Equal(200, a.Response.StatusCode)
+				assert.False(a.Timestamp.IsZero())
+			}
+		})
+	}
+	t.Run("header-line", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		r, err := NewAuditLogReader(strings.NewReader("\ufeffcreated_at,event_type,payload\n" + string(log)))
+		require.NoError(err)
+		got, err := readAll(t, r)
+		require.NoError(err)
+		assert.Len(got, len(wantIds))
+	})
+	t.Run("malformed-line", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		lines := strings.SplitN(string(log), "\n", 2)
+		malformed := lines[0] + "\n{not json\n" + lines[1]
+		r, err := NewAuditLogReader(strings.NewReader(malformed))
+		require.NoError(err)
+		got, err := readAll(t, r)
+		require.Error(err)
+		assert.Contains(err.Error(), "line 2")
+		assert.Len(got, 1)
+		assert.False(r.Next())
+	})
+}
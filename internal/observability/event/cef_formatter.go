@@ -0,0 +1,266 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/boundary/version"
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/eventlogger/filters/gated"
+)
+
+const (
+	cefVendor  = "HashiCorp"
+	cefProduct = "Boundary"
+
+	cefSeverityLow    = 3
+	cefSeverityMedium = 5
+	cefSeverityHigh   = 7
+)
+
+var (
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r\n", " ", "\n", " ", "\r", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r\n", `\n`, "\n", `\n`, "\r", `\r`)
+)
+
+// cefFormatter is a formatter node which formats an event using ArcSight's
+// Common Event Format (CEF):
+//
+//	CEF:0|HashiCorp|Boundary|<version>|<signature id>|<name>|<severity>|<extension>
+//
+// Audit events use their request's operation as the signature id and the
+// request's endpoint as the name.  Other event types use their event type as
+// the signature id and their op as the name.
+type cefFormatter struct {
+	version string
+}
+
+var _ eventlogger.Node = &cefFormatter{}
+
+func newCEFFormatter() *cefFormatter {
+	return &cefFormatter{
+		version: version.Get().VersionNumber(),
+	}
+}
+
+// Process formats the event as CEF and stores the formatted data in the
+// event's Formatted field with a key of "cef"
+func (f *cefFormatter) Process(_ context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	const op = "event.(cefFormatter).Process"
+	if e == nil {
+		return nil, fmt.Errorf("%s: missing event: %w", op, ErrInvalidParameter)
+	}
+	ce, err := newCEFEvent(e)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	e.FormattedAs(string(CEFSinkFormat), []byte(ce.String(f.version)+"\n"))
+	return e, nil
+}
+
+// Reopen is a no op
+func (f *cefFormatter) Reopen() error {
+	return nil
+}
+
+// Type describes the type of the node as a Formatter.
+func (f *cefFormatter) Type() eventlogger.NodeType {
+	return eventlogger.NodeTypeFormatter
+}
+
+// cefEvent is the intermediate representation of an event before it's
+// rendered as CEF.
+type cefEvent struct {
+	signatureId string
+	name        string
+	severity    int
+	extension   map[string]string
+}
+
+func newCEFEvent(e *eventlogger.Event) (*cefEvent, error) {
+	const op = "event.newCEFEvent"
+	ce := &cefEvent{
+		signatureId: string(e.Type),
+		name:        string(e.Type),
+		severity:    cefSeverityLow,
+		extension: map[string]string{
+			"rt": strconv.FormatInt(e.CreatedAt.UnixNano()/1e6, 10),
+		},
+	}
+	switch p := e.Payload.(type) {
+	case audit:
+		if err := ce.fromAudit(&p); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	case *audit:
+		if err := ce.fromAudit(p); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	case *err:
+		ce.severity = cefSeverityHigh
+		ce.setName(string(p.Op))
+		ce.set("externalId", string(p.Id))
+		ce.setCorrelationId(p.CorrelationId)
+		if p.Error != nil {
+			ce.set("msg", p.Error.Error())
+		}
+		ce.fromRequestInfo(p.RequestInfo)
+		if err := ce.setJSON(2, "stack", p.Stack); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	case *sysEvent:
+		ce.setName(string(p.Op))
+		ce.set("externalId", string(p.Id))
+		ce.setCorrelationId(p.CorrelationId)
+		if err := ce.setJSON(2, "data", p.Data); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	case *customEvent:
+		ce.setName(string(p.Op))
+		ce.set("externalId", string(p.Id))
+		ce.setCorrelationId(p.CorrelationId)
+		if err := ce.setJSON(2, "data", p.Data); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	case *gated.EventPayload:
+		ce.fromGatedPayload(p)
+	case gated.EventPayload:
+		ce.fromGatedPayload(&p)
+	default:
+		if err := ce.setJSON(2, "payload", e.Payload); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	return ce, nil
+}
+
+func (ce *cefEvent) fromAudit(a *audit) error {
+	const op = "event.(cefEvent).fromAudit"
+	ce.set("externalId", a.Id)
+	ce.setCorrelationId(a.CorrelationId)
+	ce.fromRequestInfo(a.RequestInfo)
+	if a.Auth != nil {
+		ce.set("suser", a.Auth.UserName)
+		ce.set("cs5Label", "accessor_id")
+		ce.set("cs5", a.Auth.AccessorId)
+		if a.Auth.UserInfo != nil {
+			ce.set("suid", a.Auth.UserInfo.UserId)
+		}
+	}
+	if a.Request != nil {
+		if a.Request.Operation != "" {
+			ce.signatureId = a.Request.Operation
+		}
+		ce.setName(a.Request.Endpoint)
+		ce.set("request", a.Request.Endpoint)
+		if err := ce.setJSON(2, "request_details", a.Request.Details); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if a.Response != nil {
+		switch {
+		case a.Response.StatusCode >= 500:
+			ce.severity = cefSeverityHigh
+		case a.Response.StatusCode >= 400:
+			ce.severity = cefSeverityMedium
+		}
+		if a.Response.StatusCode != 0 {
+			ce.set("outcome", strconv.Itoa(a.Response.StatusCode))
+		}
+		if err := ce.setJSON(3, "response_details", a.Response.Details); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	return nil
+}
+
+// fromGatedPayload sets the fields of an observation, which has been composed
+// by a gated filter.
+func (ce *cefEvent) fromGatedPayload(p *gated.EventPayload) {
+	ce.set("externalId", p.ID)
+	if id, ok := p.Header[CorrelationIdField].(string); ok {
+		ce.setCorrelationId(id)
+	}
+	for _, d := range p.Details {
+		if op, ok := d.Payload[OpField].(string); ok {
+			ce.setName(op)
+		}
+	}
+	if ri, ok := p.Header[RequestInfoField].(*RequestInfo); ok {
+		ce.fromRequestInfo(ri)
+	}
+}
+
+func (ce *cefEvent) fromRequestInfo(ri *RequestInfo) {
+	if ri == nil {
+		return
+	}
+	ce.set("requestMethod", ri.Method)
+	ce.set("requestContext", ri.Path)
+}
+
+func (ce *cefEvent) setName(name string) {
+	if name != "" {
+		ce.name = name
+	}
+}
+
+func (ce *cefEvent) setCorrelationId(id string) {
+	if id == "" {
+		return
+	}
+	ce.set("cs1Label", CorrelationIdField)
+	ce.set("cs1", id)
+}
+
+// setJSON sets the custom string extension field cs<n> to the JSON encoding of
+// v, labelled with label.  Empty values are skipped.
+func (ce *cefEvent) setJSON(n int, label string, v interface{}) error {
+	const op = "event.(cefEvent).setJSON"
+	if v == nil {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("%s: unable to marshal %s: %w", op, label, err)
+	}
+	if s := string(b); s != "null" && s != "{}" {
+		ce.set(fmt.Sprintf("cs%dLabel", n), label)
+		ce.set(fmt.Sprintf("cs%d", n), s)
+	}
+	return nil
+}
+
+func (ce *cefEvent) set(k, v string) {
+	if v != "" {
+		ce.extension[k] = v
+	}
+}
+
+// String renders the event as a CEF line (without a trailing newline).
+// Extension fields are sorted by key so the output is deterministic.
+func (ce *cefEvent) String(ver string) string {
+	keys := make([]string, 0, len(ce.extension))
+	for k := range ce.extension {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	ext := make([]string, 0, len(keys))
+	for _, k := range keys {
+		ext = append(ext, k+"="+cefExtensionEscaper.Replace(ce.extension[k]))
+	}
+	return strings.Join([]string{
+		"CEF:0",
+		cefHeaderEscaper.Replace(cefVendor),
+		cefHeaderEscaper.Replace(cefProduct),
+		cefHeaderEscaper.Replace(ver),
+		cefHeaderEscaper.Replace(ce.signatureId),
+		cefHeaderEscaper.Replace(ce.name),
+		strconv.Itoa(ce.severity),
+		strings.Join(ext, " "),
+	}, "|")
+}
@@ -0,0 +1,158 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/boundary/version"
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/eventlogger/filters/gated"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_cefFormatter_Process(t *testing.T) {
+	t.Parallel()
+	now := time.Unix(1625000000, 0)
+	rt := fmt.Sprintf("rt=%d", now.UnixNano()/1e6)
+	testEvent := func(t Type, payload interface{}) *eventlogger.Event {
+		return &eventlogger.Event{
+			Type:      eventlogger.EventType(t),
+			CreatedAt: now,
+			Payload:   payload,
+		}
+	}
+	tests := []struct {
+		name      string
+		event     *eventlogger.Event
+		want      string
+		wantErrIs error
+	}{
+		{
+			name:      "missing-event",
+			wantErrIs: ErrInvalidParameter,
+		},
+		{
+			name: "audit",
+			event: testEvent(AuditType, &audit{
+				Id:            "audit-id",
+				CorrelationId: "correlation-id",
+				Auth:          &Auth{AccessorId: "at_1234567890", UserName: "alice", UserInfo: &UserInfo{UserId: "u_1234567890"}},
+				Request:       &Request{Operation: "POST", Endpoint: "/v1/scopes"},
+				Response:      &Response{StatusCode: 403},
+			}),
+			want: "CEF:0|HashiCorp|Boundary|" + version.Get().VersionNumber() + "|POST|/v1/scopes|5|" +
+				"cs1=correlation-id cs1Label=correlation_id cs5=at_1234567890 cs5Label=accessor_id externalId=audit-id outcome=403 request=/v1/scopes " +
+				rt + " suid=u_1234567890 suser=alice\n",
+		},
+		{
+			name: "audit-header-escaping",
+			event: testEvent(AuditType, &audit{
+				Id:      "audit-id",
+				Request: &Request{Operation: `op|with\pipe`, Endpoint: "/v1/a|b=c"},
+			}),
+			want: "CEF:0|HashiCorp|Boundary|" + version.Get().VersionNumber() + `|op\|with\\pipe|/v1/a\|b=c|3|` +
+				`externalId=audit-id request=/v1/a|b\=c ` + rt + "\n",
+		},
+		{
+			name: "extension-escaping",
+			event: testEvent(ErrorType, &err{
+				Op:    "test.op",
+				Error: fmt.Errorf("a=b\\c\nd"),
+			}),
+			want: "CEF:0|HashiCorp|Boundary|" + version.Get().VersionNumber() + "|error|test.op|7|" +
+				`msg=a\=b\\c\nd ` + rt + "\n",
+		},
+		{
+			name: "error-stack",
+			event: testEvent(ErrorType, &err{
+				Op:    "test.op",
+				Error: fmt.Errorf("test"),
+				Stack: []string{"pkg.fn /src/pkg/fn.go:10"},
+			}),
+			want: "CEF:0|HashiCorp|Boundary|" + version.Get().VersionNumber() + "|error|test.op|7|" +
+				`cs2=["pkg.fn /src/pkg/fn.go:10"] cs2Label=stack msg=test ` + rt + "\n",
+		},
+		{
+			name:  "system",
+			event: testEvent(SystemType, &sysEvent{Id: "sys-id", Op: "test.op", Data: map[string]interface{}{"k": "v"}}),
+			want: "CEF:0|HashiCorp|Boundary|" + version.Get().VersionNumber() + "|system|test.op|3|" +
+				`cs2={"k":"v"} cs2Label=data externalId=sys-id ` + rt + "\n",
+		},
+		{
+			name: "observation",
+			event: testEvent(ObservationType, gated.EventPayload{
+				ID:      "obs-id",
+				Header:  map[string]interface{}{CorrelationIdField: "correlation-id"},
+				Details: []gated.EventPayloadDetails{{Payload: map[string]interface{}{OpField: "test.op"}}},
+			}),
+			want: "CEF:0|HashiCorp|Boundary|" + version.Get().VersionNumber() + "|observation|test.op|3|" +
+				"cs1=correlation-id cs1Label=correlation_id externalId=obs-id " + rt + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			got, err := newCEFFormatter().Process(context.Background(), tt.event)
+			if tt.wantErrIs != nil {
+				require.Error(err)
+				assert.ErrorIs(err, tt.wantErrIs)
+				return
+			}
+			require.NoError(err)
+			b, ok := got.Format(string(CEFSinkFormat))
+			require.True(ok)
+			assert.Equal(tt.want, string(b))
+		})
+	}
+}
+
+func TestEventer_cef(t *testing.T) {
+	t.Parallel()
+	cefFile, err := ioutil.TempFile("./", "tmp-cef-TestEventer_cef")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		os.Remove(cefFile.Name())
+	})
+	c := EventerConfig{
+		Sinks: []SinkConfig{
+			{
+				Name:       "cef",
+				SinkType:   FileSink,
+				EventTypes: []Type{ErrorType},
+				Format:     CEFSinkFormat,
+				Path:       "./",
+				FileName:   cefFile.Name(),
+			},
+			{
+				Name:       "stderr",
+				SinkType:   StderrSink,
+				EventTypes: []Type{ErrorType},
+				Format:     JSONSinkFormat,
+			},
+		},
+	}
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	e, err := NewEventer(testLogger, testLock, c)
+	require.NoError(t, err)
+
+	testError, err := newError("TestEventer_cef", fmt.Errorf("%s: no msg: test", ErrIo))
+	require.NoError(t, err)
+	require.NoError(t, e.writeError(context.Background(), testError))
+
+	got, err := ioutil.ReadFile(cefFile.Name())
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(got), "CEF:0|HashiCorp|Boundary|"))
+	assert.Contains(t, string(got), "|error|TestEventer_cef|7|")
+}
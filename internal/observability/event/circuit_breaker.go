@@ -0,0 +1,397 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+)
+
+const (
+	// defaultCircuitBreakerThreshold is the default number of consecutive
+	// failures which opens a network sink's circuit breaker.
+	defaultCircuitBreakerThreshold = 5
+
+	// defaultCircuitBreakerCooldown is the default amount of time a network
+	// sink's circuit breaker stays open before it probes the sink.
+	defaultCircuitBreakerCooldown = 30 * time.Second
+)
+
+// CircuitState is the state of a sink's circuit breaker.
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"    // CircuitClosed means events are sent to the sink
+	CircuitOpen     CircuitState = "open"      // CircuitOpen means events are not sent to the sink
+	CircuitHalfOpen CircuitState = "half-open" // CircuitHalfOpen means a single event is probing the sink
+)
+
+// circuitBreaker is the circuit breaker configuration for network sinks.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+}
+
+func (c circuitBreaker) validate() error {
+	const op = "event.(circuitBreaker).validate"
+	if c.threshold <= 0 {
+		return fmt.Errorf("%s: threshold must be greater than zero: %w", op, ErrInvalidParameter)
+	}
+	if c.cooldown <= 0 {
+		return fmt.Errorf("%s: cooldown must be greater than zero: %w", op, ErrInvalidParameter)
+	}
+	return nil
+}
+
+// circuitBreakerSink wraps a network sink node (kafka, cloudwatch and grpc).
+// After threshold consecutive failures it opens, and events aren't sent to
+// the sink until the cooldown has elapsed.  While it's open, events with an
+// enforced delivery fail immediately with ErrCircuitOpen and all other events
+// are dropped and counted.  Once the cooldown has elapsed, a single event is
+// sent to probe the sink: its success closes the breaker and its failure
+// opens it for another cooldown.
+//
+// When the sink has a spool, best effort events which it fails to send, or
+// which arrive while it's open, are written to the spool instead of being
+// dropped.  Once the sink is allowed to send again (probed by the next event
+// or a health check) the spool is replayed in order in the background, and
+// new events are spooled behind it until it's empty.
+type circuitBreakerSink struct {
+	name    string
+	node    eventlogger.Node
+	config  circuitBreaker
+	eventer *Eventer
+
+	// enforced is true when the sink's delivery guarantee is enforced, in
+	// which case every event is enforced regardless of its type.
+	enforced bool
+
+	// now is used in place of time.Now, so tests can control the cooldown.
+	now func() time.Time
+
+	l        sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	dropped  uint64
+
+	// spool is the sink's spool, which is nil when it doesn't have one.
+	// spoolFormat is the format of the events' bytes it stores.
+	spool       *spool
+	spoolFormat string
+
+	replayLock   sync.Mutex
+	replaying    bool
+	replayCtx    context.Context
+	replayCancel context.CancelFunc
+	replayWg     sync.WaitGroup
+}
+
+var (
+	_ eventlogger.Node = &circuitBreakerSink{}
+	_ io.Closer        = &circuitBreakerSink{}
+	_ healthChecker    = &circuitBreakerSink{}
+)
+
+func newCircuitBreakerSink(e *Eventer, name string, node eventlogger.Node, config circuitBreaker, enforced bool) (*circuitBreakerSink, error) {
+	const op = "event.newCircuitBreakerSink"
+	if e == nil {
+		return nil, fmt.Errorf("%s: missing eventer: %w", op, ErrInvalidParameter)
+	}
+	if node == nil {
+		return nil, fmt.Errorf("%s: missing node: %w", op, ErrInvalidParameter)
+	}
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	replayCtx, replayCancel := context.WithCancel(context.Background())
+	return &circuitBreakerSink{
+		name:         name,
+		node:         node,
+		config:       config,
+		eventer:      e,
+		enforced:     enforced,
+		now:          time.Now,
+		state:        CircuitClosed,
+		replayCtx:    replayCtx,
+		replayCancel: replayCancel,
+	}, nil
+}
+
+// withSpool sets the spool of the breaker's sink, whose events are formatted
+// as format.
+func (s *circuitBreakerSink) withSpool(sp *spool, format string) {
+	s.spool = sp
+	s.spoolFormat = format
+}
+
+// Process sends the event to the wrapped sink unless the breaker is open.
+func (s *circuitBreakerSink) Process(ctx context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	const op = "event.(circuitBreakerSink).Process"
+	if e == nil {
+		return nil, fmt.Errorf("%s: missing event: %w", op, ErrInvalidParameter)
+	}
+	allowed, probe := s.allow()
+	spooled := s.spool != nil && !s.enforced && !enforcedDelivery(Type(e.Type))
+	if spooled && (!allowed || s.spool.len() > 0) {
+		// the event is queued behind the spooled events, so they're
+		// replayed in order
+		if allowed {
+			s.replay(probe)
+		}
+		if err := s.spoolEvent(e); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		return nil, nil
+	}
+	if !allowed {
+		if s.enforced || enforcedDelivery(Type(e.Type)) {
+			return nil, fmt.Errorf("%s: sink %q: %w", op, s.name, ErrCircuitOpen)
+		}
+		atomic.AddUint64(&s.dropped, 1)
+		return nil, nil
+	}
+	processed, err := s.node.Process(ctx, e)
+	s.record(err, probe)
+	if err != nil {
+		if spooled {
+			if spoolErr := s.spoolEvent(e); spoolErr == nil {
+				return nil, nil
+			}
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return processed, nil
+}
+
+// spoolEvent writes the event's formatted bytes to the sink's spool.
+func (s *circuitBreakerSink) spoolEvent(e *eventlogger.Event) error {
+	const op = "event.(circuitBreakerSink).spoolEvent"
+	value, ok := e.Format(s.spoolFormat)
+	if !ok {
+		return fmt.Errorf("%s: event is missing its %s format: %w", op, s.spoolFormat, ErrInvalidParameter)
+	}
+	rec := spoolRecord{
+		CreatedAt:     e.CreatedAt,
+		EventType:     string(e.Type),
+		CorrelationId: correlationIdFromPayload(e.Payload),
+		Data:          value,
+	}
+	if err := s.spool.append(rec); err != nil {
+		return fmt.Errorf("%s: sink %q: %w", op, s.name, err)
+	}
+	return nil
+}
+
+// replay starts replaying the spool in the background, unless it's already
+// being replayed.  The caller must have been allowed to send an event, and
+// probe is whether it's probing the sink.
+func (s *circuitBreakerSink) replay(probe bool) {
+	s.replayLock.Lock()
+	defer s.replayLock.Unlock()
+	if s.replaying || s.replayCtx.Err() != nil {
+		if probe {
+			s.releaseProbe()
+		}
+		return
+	}
+	s.replaying = true
+	s.replayWg.Add(1)
+	go s.runReplay(probe)
+}
+
+// runReplay sends the spooled events to the sink, oldest first, until the
+// spool is empty or a send fails.
+func (s *circuitBreakerSink) runReplay(probe bool) {
+	defer s.replayWg.Done()
+	for allowed := true; ; allowed, probe = s.allow() {
+		if !allowed {
+			s.stopReplay()
+			return
+		}
+		s.replayLock.Lock()
+		rec, ok, err := s.spool.peek()
+		if err != nil || !ok || s.replayCtx.Err() != nil {
+			s.replaying = false
+			s.replayLock.Unlock()
+			if probe {
+				s.releaseProbe()
+			}
+			if err != nil {
+				s.eventer.logger.Error("unable to read sink spool", "sink", s.name, "error", err.Error())
+			}
+			return
+		}
+		s.replayLock.Unlock()
+		e := &eventlogger.Event{
+			Type:      eventlogger.EventType(rec.EventType),
+			CreatedAt: rec.CreatedAt,
+			Formatted: map[string][]byte{s.spoolFormat: rec.Data},
+			Payload:   &spooledPayload{CorrelationId: rec.CorrelationId},
+		}
+		_, err = s.node.Process(s.replayCtx, e)
+		s.record(err, probe)
+		if err != nil {
+			s.eventer.logger.Debug("unable to replay spooled event", "sink", s.name, "error", err.Error())
+			s.stopReplay()
+			return
+		}
+		if err := s.spool.pop(); err != nil {
+			s.eventer.logger.Error("unable to remove replayed event from sink spool", "sink", s.name, "error", err.Error())
+			s.stopReplay()
+			return
+		}
+	}
+}
+
+func (s *circuitBreakerSink) stopReplay() {
+	s.replayLock.Lock()
+	defer s.replayLock.Unlock()
+	s.replaying = false
+}
+
+// releaseProbe returns a probe which wasn't sent, so the next event probes
+// the sink instead.
+func (s *circuitBreakerSink) releaseProbe() {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if s.state == CircuitHalfOpen {
+		s.state = CircuitOpen
+	}
+}
+
+// allow returns true when an event may be sent to the wrapped sink, and
+// whether the event is probing the sink after the breaker's cooldown.
+func (s *circuitBreakerSink) allow() (allowed bool, probe bool) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	switch s.state {
+	case CircuitOpen:
+		if s.now().Sub(s.openedAt) < s.config.cooldown {
+			return false, false
+		}
+		s.state = CircuitHalfOpen
+		return true, true
+	case CircuitHalfOpen:
+		// only one probe is sent at a time
+		return false, false
+	default:
+		return true, false
+	}
+}
+
+// record the result of sending an event to the wrapped sink.
+func (s *circuitBreakerSink) record(sendErr error, probe bool) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if sendErr == nil {
+		if s.state != CircuitClosed {
+			s.eventer.logger.Info("sink circuit breaker closed", "sink", s.name)
+		}
+		s.state = CircuitClosed
+		s.failures = 0
+		return
+	}
+	s.failures++
+	switch {
+	case probe:
+	case s.state == CircuitClosed && s.failures >= s.config.threshold:
+	default:
+		return
+	}
+	s.state = CircuitOpen
+	s.openedAt = s.now()
+	s.eventer.logger.Warn("sink circuit breaker opened", "sink", s.name, "failures", s.failures, "cooldown", s.config.cooldown.String(), "error", sendErr.Error())
+}
+
+// State returns the state of the breaker.
+func (s *circuitBreakerSink) State() CircuitState {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return s.state
+}
+
+// Dropped returns the number of events dropped while the breaker was open.
+func (s *circuitBreakerSink) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Spooled returns the number of events in the sink's spool.
+func (s *circuitBreakerSink) Spooled() int {
+	if s.spool == nil {
+		return 0
+	}
+	return s.spool.len()
+}
+
+// SpoolDropped returns the number of events dropped because the sink's spool
+// was full.
+func (s *circuitBreakerSink) SpoolDropped() uint64 {
+	if s.spool == nil {
+		return 0
+	}
+	return s.spool.Dropped()
+}
+
+// checkHealth returns an error while the breaker is open, otherwise it checks
+// the wrapped sink when it's able to.  When the sink's spool isn't empty, it
+// starts replaying it if the sink may be sent to, which probes the sink once
+// the breaker's cooldown has elapsed.
+func (s *circuitBreakerSink) checkHealth(ctx context.Context) error {
+	const op = "event.(circuitBreakerSink).checkHealth"
+	if s.Spooled() > 0 && !s.isReplaying() {
+		if allowed, probe := s.allow(); allowed {
+			s.replay(probe)
+		}
+	}
+	if state := s.State(); state != CircuitClosed {
+		return fmt.Errorf("%s: circuit breaker is %s: %w", op, state, ErrCircuitOpen)
+	}
+	if hc, ok := s.node.(healthChecker); ok {
+		return hc.checkHealth(ctx)
+	}
+	return nil
+}
+
+// Reopen reopens the wrapped sink.  The breaker's state is unchanged, since
+// the next probe will determine if the sink has recovered.
+func (s *circuitBreakerSink) Reopen() error {
+	return s.node.Reopen()
+}
+
+func (s *circuitBreakerSink) isReplaying() bool {
+	s.replayLock.Lock()
+	defer s.replayLock.Unlock()
+	return s.replaying
+}
+
+// Close stops replaying the spool and closes it, so its remaining events are
+// replayed by the next eventer, then closes the wrapped sink when it's an
+// io.Closer.
+func (s *circuitBreakerSink) Close() error {
+	const op = "event.(circuitBreakerSink).Close"
+	s.replayCancel()
+	s.replayWg.Wait()
+	var spoolErr error
+	if s.spool != nil {
+		spoolErr = s.spool.close()
+	}
+	if c, ok := s.node.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			return err
+		}
+	}
+	if spoolErr != nil {
+		return fmt.Errorf("%s: %w", op, spoolErr)
+	}
+	return nil
+}
+
+// Type describes the type of the node as a Sink.
+func (s *circuitBreakerSink) Type() eventlogger.NodeType {
+	return eventlogger.NodeTypeSink
+}
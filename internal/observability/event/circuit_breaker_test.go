@@ -0,0 +1,192 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testFlakySink is a sink node which counts its calls and fails while its err
+// is set.
+type testFlakySink struct {
+	l     sync.Mutex
+	err   error
+	calls int
+}
+
+func (s *testFlakySink) setErr(err error) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.err = err
+}
+
+func (s *testFlakySink) Calls() int {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return s.calls
+}
+
+func (s *testFlakySink) Process(_ context.Context, _ *eventlogger.Event) (*eventlogger.Event, error) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.calls++
+	return nil, s.err
+}
+
+func (s *testFlakySink) Reopen() error { return nil }
+
+func (s *testFlakySink) Type() eventlogger.NodeType { return eventlogger.NodeTypeSink }
+
+func Test_newCircuitBreakerSink(t *testing.T) {
+	t.Parallel()
+	testEventer := &Eventer{logger: hclog.NewNullLogger()}
+	valid := circuitBreaker{threshold: 1, cooldown: time.Second}
+	tests := []struct {
+		name      string
+		e         *Eventer
+		node      eventlogger.Node
+		config    circuitBreaker
+		wantErrIs error
+	}{
+		{name: "missing-eventer", node: &testFlakySink{}, config: valid, wantErrIs: ErrInvalidParameter},
+		{name: "missing-node", e: testEventer, config: valid, wantErrIs: ErrInvalidParameter},
+		{name: "zero-threshold", e: testEventer, node: &testFlakySink{}, config: circuitBreaker{cooldown: time.Second}, wantErrIs: ErrInvalidParameter},
+		{name: "zero-cooldown", e: testEventer, node: &testFlakySink{}, config: circuitBreaker{threshold: 1}, wantErrIs: ErrInvalidParameter},
+		{name: "valid", e: testEventer, node: &testFlakySink{}, config: valid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			s, err := newCircuitBreakerSink(tt.e, "test", tt.node, tt.config, false)
+			if tt.wantErrIs != nil {
+				require.Error(err)
+				assert.ErrorIs(err, tt.wantErrIs)
+				return
+			}
+			require.NoError(err)
+			assert.Equal(CircuitClosed, s.State())
+		})
+	}
+}
+
+func Test_circuitBreakerSink_Process(t *testing.T) {
+	t.Parallel()
+	testEventer := &Eventer{logger: hclog.NewNullLogger()}
+	testErr := errors.New("sink is down")
+	ctx := context.Background()
+	observation := &eventlogger.Event{Type: eventlogger.EventType(ObservationType)}
+	audit := &eventlogger.Event{Type: eventlogger.EventType(AuditType)}
+
+	t.Run("opens-until-cooldown", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		node := &testFlakySink{err: testErr}
+		s, err := newCircuitBreakerSink(testEventer, "test", node, circuitBreaker{threshold: 3, cooldown: time.Minute}, false)
+		require.NoError(err)
+		now := time.Now()
+		s.now = func() time.Time { return now }
+
+		for i := 0; i < 3; i++ {
+			_, err := s.Process(ctx, observation)
+			require.Error(err)
+			assert.ErrorIs(err, testErr)
+		}
+		assert.Equal(CircuitOpen, s.State())
+		assert.Equal(3, node.Calls())
+		assert.ErrorIs(s.checkHealth(ctx), ErrCircuitOpen)
+
+		// best effort events are dropped and enforced events fail, without
+		// calling the sink
+		_, err = s.Process(ctx, observation)
+		require.NoError(err)
+		_, err = s.Process(ctx, audit)
+		require.Error(err)
+		assert.ErrorIs(err, ErrCircuitOpen)
+		assert.Equal(3, node.Calls())
+		assert.Equal(uint64(1), s.Dropped())
+
+		now = now.Add(time.Minute - time.Nanosecond)
+		_, err = s.Process(ctx, observation)
+		require.NoError(err)
+		assert.Equal(3, node.Calls())
+
+		// the cooldown has elapsed, so the next event probes the sink and
+		// its success closes the breaker
+		node.setErr(nil)
+		now = now.Add(time.Nanosecond)
+		_, err = s.Process(ctx, observation)
+		require.NoError(err)
+		assert.Equal(4, node.Calls())
+		assert.Equal(CircuitClosed, s.State())
+		assert.NoError(s.checkHealth(ctx))
+
+		_, err = s.Process(ctx, observation)
+		require.NoError(err)
+		assert.Equal(5, node.Calls())
+	})
+	t.Run("failed-probe", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		node := &testFlakySink{err: testErr}
+		s, err := newCircuitBreakerSink(testEventer, "test", node, circuitBreaker{threshold: 1, cooldown: time.Minute}, false)
+		require.NoError(err)
+		now := time.Now()
+		s.now = func() time.Time { return now }
+
+		_, err = s.Process(ctx, observation)
+		require.Error(err)
+		assert.Equal(CircuitOpen, s.State())
+
+		now = now.Add(time.Minute)
+		_, err = s.Process(ctx, observation)
+		require.Error(err)
+		assert.Equal(2, node.Calls())
+		assert.Equal(CircuitOpen, s.State())
+
+		// the failed probe started another cooldown
+		now = now.Add(time.Second)
+		_, err = s.Process(ctx, observation)
+		require.NoError(err)
+		assert.Equal(2, node.Calls())
+	})
+	t.Run("successes-reset-failures", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		node := &testFlakySink{err: testErr}
+		s, err := newCircuitBreakerSink(testEventer, "test", node, circuitBreaker{threshold: 2, cooldown: time.Minute}, false)
+		require.NoError(err)
+		for i := 0; i < 3; i++ {
+			node.setErr(testErr)
+			_, err = s.Process(ctx, observation)
+			require.Error(err)
+			node.setErr(nil)
+			_, err = s.Process(ctx, observation)
+			require.NoError(err)
+		}
+		assert.Equal(CircuitClosed, s.State())
+	})
+	t.Run("enforced-sink", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		node := &testFlakySink{err: testErr}
+		s, err := newCircuitBreakerSink(testEventer, "test", node, circuitBreaker{threshold: 1, cooldown: time.Minute}, true)
+		require.NoError(err)
+		_, err = s.Process(ctx, observation)
+		require.Error(err)
+		_, err = s.Process(ctx, observation)
+		require.Error(err)
+		assert.ErrorIs(err, ErrCircuitOpen)
+		assert.Equal(uint64(0), s.Dropped())
+	})
+	t.Run("missing-event", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		s, err := newCircuitBreakerSink(testEventer, "test", &testFlakySink{}, circuitBreaker{threshold: 1, cooldown: time.Minute}, false)
+		require.NoError(err)
+		_, err = s.Process(ctx, nil)
+		require.Error(err)
+		assert.ErrorIs(err, ErrInvalidParameter)
+	})
+}
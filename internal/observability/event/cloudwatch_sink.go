@@ -0,0 +1,324 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/hashicorp/eventlogger"
+)
+
+const (
+	// cloudWatchMaxBatchEvents is the max number of log events in a
+	// PutLogEvents request.
+	cloudWatchMaxBatchEvents = 10000
+
+	// cloudWatchMaxBatchBytes is the max size of a PutLogEvents request, which
+	// is the sum of its messages' sizes plus cloudWatchEventOverhead for each
+	// log event.
+	cloudWatchMaxBatchBytes = 1048576
+
+	// cloudWatchMaxEventBytes is the max size of a single log event, including
+	// cloudWatchEventOverhead.
+	cloudWatchMaxEventBytes = 262144
+
+	// cloudWatchEventOverhead is the size CloudWatch adds to every log event
+	// when enforcing its size limits.
+	cloudWatchEventOverhead = 26
+
+	// cloudWatchMaxBatchSpan is the max time between the oldest and newest
+	// log events of a PutLogEvents request.
+	cloudWatchMaxBatchSpan = 24 * time.Hour
+)
+
+// CloudWatchSinkConfig defines the configuration for a CloudWatchSink
+type CloudWatchSinkConfig struct {
+	LogGroup  string `hcl:"log_group"`  // LogGroup defines the existing log group events are put to
+	LogStream string `hcl:"log_stream"` // LogStream defines the log stream events are put to, which is created if it doesn't exist
+	Region    string `hcl:"region"`     // Region defines the AWS region of the log group
+}
+
+func (cc *CloudWatchSinkConfig) validate() error {
+	const op = "event.(CloudWatchSinkConfig).validate"
+	if cc.LogGroup == "" {
+		return fmt.Errorf("%s: missing log group: %w", op, ErrInvalidParameter)
+	}
+	if cc.LogStream == "" {
+		return fmt.Errorf("%s: missing log stream: %w", op, ErrInvalidParameter)
+	}
+	if cc.Region == "" {
+		return fmt.Errorf("%s: missing region: %w", op, ErrInvalidParameter)
+	}
+	return nil
+}
+
+// CloudWatchLogsClient defines the CloudWatch Logs API used by a
+// CloudWatchSink, which is implemented by the AWS SDK's
+// *cloudwatchlogs.CloudWatchLogs.
+type CloudWatchLogsClient interface {
+	DescribeLogStreamsWithContext(ctx aws.Context, in *cloudwatchlogs.DescribeLogStreamsInput, opt ...request.Option) (*cloudwatchlogs.DescribeLogStreamsOutput, error)
+	CreateLogStreamWithContext(ctx aws.Context, in *cloudwatchlogs.CreateLogStreamInput, opt ...request.Option) (*cloudwatchlogs.CreateLogStreamOutput, error)
+	PutLogEventsWithContext(ctx aws.Context, in *cloudwatchlogs.PutLogEventsInput, opt ...request.Option) (*cloudwatchlogs.PutLogEventsOutput, error)
+}
+
+// CloudWatchClientFactory creates a CloudWatchLogsClient for the config.  It's
+// called by the sink's first put, and by its first put after it's reopened.
+type CloudWatchClientFactory func(*CloudWatchSinkConfig) (CloudWatchLogsClient, error)
+
+// defaultCloudWatchClient creates a client with the AWS SDK's default
+// credential chain, which uses the instance role when no other credentials
+// are configured in the environment.
+func defaultCloudWatchClient(c *CloudWatchSinkConfig) (CloudWatchLogsClient, error) {
+	const op = "event.defaultCloudWatchClient"
+	sess, err := session.NewSession(aws.NewConfig().WithRegion(c.Region))
+	if err != nil {
+		return nil, fmt.Errorf("%s: unable to create aws session: %w", op, err)
+	}
+	return cloudwatchlogs.New(sess), nil
+}
+
+// cloudWatchBatch is a batch of log events which are put together.  Its done
+// channel is closed once it's been put, and err is its result.
+type cloudWatchBatch struct {
+	events []*cloudwatchlogs.InputLogEvent
+	bytes  int
+	done   chan struct{}
+	err    error
+}
+
+// fits returns true if the log event can be added to the batch without
+// exceeding CloudWatch's limits.
+func (b *cloudWatchBatch) fits(le *cloudwatchlogs.InputLogEvent) bool {
+	if len(b.events) == 0 {
+		return true
+	}
+	if len(b.events) >= cloudWatchMaxBatchEvents {
+		return false
+	}
+	if b.bytes+len(*le.Message)+cloudWatchEventOverhead > cloudWatchMaxBatchBytes {
+		return false
+	}
+	first := aws.Int64Value(b.events[0].Timestamp)
+	return time.Duration(aws.Int64Value(le.Timestamp)-first)*time.Millisecond < cloudWatchMaxBatchSpan
+}
+
+func (b *cloudWatchBatch) add(le *cloudwatchlogs.InputLogEvent) {
+	b.events = append(b.events, le)
+	b.bytes += len(*le.Message) + cloudWatchEventOverhead
+}
+
+// cloudWatchSink is a sink node which puts formatted events to a CloudWatch
+// Logs log stream.  Events which are processed while a batch is being put
+// are added to the next batch, which is put once the current one completes,
+// so events are batched under load without delaying their delivery: Process
+// only returns once its event's batch has been put.
+type cloudWatchSink struct {
+	config  *CloudWatchSinkConfig
+	format  string
+	factory CloudWatchClientFactory
+	eventer *Eventer
+
+	// l serializes putting batches, since each put needs the sequence token
+	// returned by the previous one, and guards the client and the token.
+	l             sync.Mutex
+	client        CloudWatchLogsClient
+	sequenceToken *string
+
+	batchLock sync.Mutex
+	pending   *cloudWatchBatch
+}
+
+var _ eventlogger.Node = &cloudWatchSink{}
+
+func newCloudWatchSink(e *Eventer, c *CloudWatchSinkConfig, format string, factory CloudWatchClientFactory) (*cloudWatchSink, error) {
+	const op = "event.newCloudWatchSink"
+	if e == nil {
+		return nil, fmt.Errorf("%s: missing eventer: %w", op, ErrInvalidParameter)
+	}
+	if c == nil {
+		return nil, fmt.Errorf("%s: missing cloudwatch config: %w", op, ErrInvalidParameter)
+	}
+	if format == "" {
+		return nil, fmt.Errorf("%s: missing format: %w", op, ErrInvalidParameter)
+	}
+	if factory == nil {
+		factory = defaultCloudWatchClient
+	}
+	// the log stream is resolved by the first put, so an unreachable
+	// endpoint doesn't keep the Eventer from being created
+	return &cloudWatchSink{
+		config:  c,
+		format:  format,
+		factory: factory,
+		eventer: e,
+	}, nil
+}
+
+// Process adds the event's formatted data to the pending batch and waits for
+// the batch to be put.  Throttled puts are retried with a backoff.  The batch
+// is put with a ctx owned by the sink (see batchSendTimeout), so when ctx is
+// done Process stops waiting, but the batch's other events are still put.
+func (s *cloudWatchSink) Process(ctx context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	const op = "event.(cloudWatchSink).Process"
+	if e == nil {
+		return nil, fmt.Errorf("%s: missing event: %w", op, ErrInvalidParameter)
+	}
+	value, ok := e.Format(s.format)
+	if !ok {
+		return nil, fmt.Errorf("%s: unable to find %s format: %w", op, s.format, ErrInvalidParameter)
+	}
+	if len(value)+cloudWatchEventOverhead > cloudWatchMaxEventBytes {
+		return nil, fmt.Errorf("%s: event of %d bytes exceeds the cloudwatch limit of %d bytes: %w", op, len(value), cloudWatchMaxEventBytes-cloudWatchEventOverhead, ErrInvalidParameter)
+	}
+	createdAt := e.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	le := &cloudwatchlogs.InputLogEvent{
+		Message:   aws.String(string(value)),
+		Timestamp: aws.Int64(createdAt.UnixNano() / int64(time.Millisecond)),
+	}
+	b, leader := s.enqueue(le)
+	if leader {
+		go s.put(b)
+	}
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%s: %w", op, ctx.Err())
+	}
+	if b.err != nil {
+		return nil, fmt.Errorf("%s: %w", op, b.err)
+	}
+	// return a nil event to indicate the pipeline is complete
+	return nil, nil
+}
+
+// enqueue adds the log event to the pending batch, starting a new batch when
+// there's none or the event doesn't fit in it.  leader is true when a new
+// batch was started, and its caller is responsible for putting it.
+func (s *cloudWatchSink) enqueue(le *cloudwatchlogs.InputLogEvent) (b *cloudWatchBatch, leader bool) {
+	s.batchLock.Lock()
+	defer s.batchLock.Unlock()
+	if s.pending == nil || !s.pending.fits(le) {
+		s.pending = &cloudWatchBatch{done: make(chan struct{})}
+		leader = true
+	}
+	s.pending.add(le)
+	return s.pending, leader
+}
+
+// put waits for the previous batch to be put, stops the batch from accepting
+// more events and then puts it, resolving the log stream first when it hasn't
+// been.
+func (s *cloudWatchSink) put(b *cloudWatchBatch) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.batchLock.Lock()
+	if s.pending == b {
+		s.pending = nil
+	}
+	s.batchLock.Unlock()
+	defer close(b.done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), batchSendTimeout)
+	defer cancel()
+
+	// CloudWatch requires a batch's events to be in chronological order
+	sort.SliceStable(b.events, func(i, j int) bool {
+		return aws.Int64Value(b.events[i].Timestamp) < aws.Int64Value(b.events[j].Timestamp)
+	})
+	b.err = s.eventer.retrySend(ctx, stdRetryCount, s.eventer.retryBackoff(), func() (eventlogger.Status, error) {
+		if s.client == nil {
+			if err := s.resolve(ctx); err != nil {
+				return eventlogger.Status{}, err
+			}
+		}
+		out, err := s.client.PutLogEventsWithContext(ctx, &cloudwatchlogs.PutLogEventsInput{
+			LogGroupName:  aws.String(s.config.LogGroup),
+			LogStreamName: aws.String(s.config.LogStream),
+			LogEvents:     b.events,
+			SequenceToken: s.sequenceToken,
+		})
+		var invalidToken *cloudwatchlogs.InvalidSequenceTokenException
+		var alreadyAccepted *cloudwatchlogs.DataAlreadyAcceptedException
+		switch {
+		case err == nil:
+			s.sequenceToken = out.NextSequenceToken
+			return eventlogger.Status{}, nil
+		case errors.As(err, &alreadyAccepted):
+			// a previous attempt was accepted even though its response was
+			// lost
+			s.sequenceToken = alreadyAccepted.ExpectedSequenceToken
+			return eventlogger.Status{}, nil
+		case errors.As(err, &invalidToken):
+			// another writer put to the stream, so the next attempt uses the
+			// token it expects
+			s.sequenceToken = invalidToken.ExpectedSequenceToken
+		}
+		return eventlogger.Status{}, err
+	})
+}
+
+// resolve creates a client and finds the log stream's sequence token,
+// creating the stream when it doesn't exist.  It's called with l held.
+func (s *cloudWatchSink) resolve(ctx context.Context) error {
+	const op = "event.(cloudWatchSink).resolve"
+	client, err := s.factory(s.config)
+	if err != nil {
+		return fmt.Errorf("%s: unable to create cloudwatch client: %w", op, err)
+	}
+	in := &cloudwatchlogs.DescribeLogStreamsInput{
+		LogGroupName:        aws.String(s.config.LogGroup),
+		LogStreamNamePrefix: aws.String(s.config.LogStream),
+	}
+	for {
+		out, err := client.DescribeLogStreamsWithContext(ctx, in)
+		if err != nil {
+			return fmt.Errorf("%s: unable to describe log stream %q: %w", op, s.config.LogStream, err)
+		}
+		for _, ls := range out.LogStreams {
+			if aws.StringValue(ls.LogStreamName) == s.config.LogStream {
+				s.client, s.sequenceToken = client, ls.UploadSequenceToken
+				return nil
+			}
+		}
+		if aws.StringValue(out.NextToken) == "" {
+			break
+		}
+		in.NextToken = out.NextToken
+	}
+	_, err = client.CreateLogStreamWithContext(ctx, &cloudwatchlogs.CreateLogStreamInput{
+		LogGroupName:  aws.String(s.config.LogGroup),
+		LogStreamName: aws.String(s.config.LogStream),
+	})
+	var exists *cloudwatchlogs.ResourceAlreadyExistsException
+	if err != nil && !errors.As(err, &exists) {
+		return fmt.Errorf("%s: unable to create log stream %q: %w", op, s.config.LogStream, err)
+	}
+	// a new stream doesn't have a sequence token, and when another writer
+	// created it first, the first put will learn the token it expects
+	s.client, s.sequenceToken = client, nil
+	return nil
+}
+
+// Reopen discards the client, so the next put creates a new one and
+// re-resolves the log stream, and a stream which was deleted is recreated.
+func (s *cloudWatchSink) Reopen() error {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.client, s.sequenceToken = nil, nil
+	return nil
+}
+
+// Type describes the type of the node as a Sink.
+func (s *cloudWatchSink) Type() eventlogger.NodeType {
+	return eventlogger.NodeTypeSink
+}
@@ -0,0 +1,416 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCloudWatchClient is a fake CloudWatch Logs client which enforces the
+// sequence tokens of its streams.  A stream's token is nil until the stream
+// is first put to.
+type testCloudWatchClient struct {
+	l         sync.Mutex
+	streams   map[string]*string
+	nextToken int
+	throttles int
+	accepted  bool
+	created   []string
+	puts      [][]string
+
+	// putStarted and releasePut, when set, block each put until releasePut
+	// receives, after signaling putStarted.
+	putStarted chan struct{}
+	releasePut chan struct{}
+}
+
+func (c *testCloudWatchClient) DescribeLogStreamsWithContext(_ aws.Context, in *cloudwatchlogs.DescribeLogStreamsInput, _ ...request.Option) (*cloudwatchlogs.DescribeLogStreamsOutput, error) {
+	c.l.Lock()
+	defer c.l.Unlock()
+	out := &cloudwatchlogs.DescribeLogStreamsOutput{}
+	for name, token := range c.streams {
+		if strings.HasPrefix(name, aws.StringValue(in.LogStreamNamePrefix)) {
+			out.LogStreams = append(out.LogStreams, &cloudwatchlogs.LogStream{LogStreamName: aws.String(name), UploadSequenceToken: token})
+		}
+	}
+	return out, nil
+}
+
+func (c *testCloudWatchClient) CreateLogStreamWithContext(_ aws.Context, in *cloudwatchlogs.CreateLogStreamInput, _ ...request.Option) (*cloudwatchlogs.CreateLogStreamOutput, error) {
+	c.l.Lock()
+	defer c.l.Unlock()
+	if c.streams == nil {
+		c.streams = map[string]*string{}
+	}
+	name := aws.StringValue(in.LogStreamName)
+	if _, ok := c.streams[name]; ok {
+		return nil, &cloudwatchlogs.ResourceAlreadyExistsException{}
+	}
+	c.streams[name] = nil
+	c.created = append(c.created, name)
+	return &cloudwatchlogs.CreateLogStreamOutput{}, nil
+}
+
+func (c *testCloudWatchClient) PutLogEventsWithContext(_ aws.Context, in *cloudwatchlogs.PutLogEventsInput, _ ...request.Option) (*cloudwatchlogs.PutLogEventsOutput, error) {
+	if c.putStarted != nil {
+		c.putStarted <- struct{}{}
+		<-c.releasePut
+	}
+	c.l.Lock()
+	defer c.l.Unlock()
+	expected, ok := c.streams[aws.StringValue(in.LogStreamName)]
+	switch {
+	case !ok:
+		return nil, &cloudwatchlogs.ResourceNotFoundException{}
+	case c.throttles > 0:
+		c.throttles--
+		return nil, awserr.New("ThrottlingException", "rate exceeded", nil)
+	case aws.StringValue(expected) != aws.StringValue(in.SequenceToken):
+		return nil, &cloudwatchlogs.InvalidSequenceTokenException{ExpectedSequenceToken: expected}
+	}
+	c.nextToken++
+	token := aws.String(fmt.Sprintf("token-%d", c.nextToken))
+	c.streams[aws.StringValue(in.LogStreamName)] = token
+	messages := make([]string, 0, len(in.LogEvents))
+	for _, le := range in.LogEvents {
+		messages = append(messages, aws.StringValue(le.Message))
+	}
+	c.puts = append(c.puts, messages)
+	if c.accepted {
+		c.accepted = false
+		return nil, &cloudwatchlogs.DataAlreadyAcceptedException{ExpectedSequenceToken: token}
+	}
+	return &cloudwatchlogs.PutLogEventsOutput{NextSequenceToken: token}, nil
+}
+
+func TestCloudWatchSinkConfig_validate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name            string
+		cc              CloudWatchSinkConfig
+		wantErrContains string
+	}{
+		{
+			name:            "missing-log-group",
+			cc:              CloudWatchSinkConfig{LogStream: "controller", Region: "us-east-1"},
+			wantErrContains: "missing log group",
+		},
+		{
+			name:            "missing-log-stream",
+			cc:              CloudWatchSinkConfig{LogGroup: "boundary", Region: "us-east-1"},
+			wantErrContains: "missing log stream",
+		},
+		{
+			name:            "missing-region",
+			cc:              CloudWatchSinkConfig{LogGroup: "boundary", LogStream: "controller"},
+			wantErrContains: "missing region",
+		},
+		{
+			name: "valid",
+			cc:   CloudWatchSinkConfig{LogGroup: "boundary", LogStream: "controller", Region: "us-east-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			err := tt.cc.validate()
+			if tt.wantErrContains != "" {
+				assert.ErrorIs(err, ErrInvalidParameter)
+				assert.Contains(err.Error(), tt.wantErrContains)
+				return
+			}
+			assert.NoError(err)
+		})
+	}
+}
+
+func Test_cloudWatchSink(t *testing.T) {
+	t.Parallel()
+	testEventer := &Eventer{logger: hclog.NewNullLogger(), noBackoffJitter: true}
+	testConfig := &CloudWatchSinkConfig{LogGroup: "boundary", LogStream: "controller", Region: "us-east-1"}
+	testEvent := func(data string) *eventlogger.Event {
+		e := &eventlogger.Event{Type: eventlogger.EventType(AuditType), CreatedAt: time.Now()}
+		e.FormattedAs(string(JSONSinkFormat), []byte(data))
+		return e
+	}
+	factory := func(c *testCloudWatchClient) CloudWatchClientFactory {
+		return func(*CloudWatchSinkConfig) (CloudWatchLogsClient, error) { return c, nil }
+	}
+
+	t.Run("missing-config", func(t *testing.T) {
+		_, err := newCloudWatchSink(testEventer, nil, string(JSONSinkFormat), factory(&testCloudWatchClient{}))
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	})
+	t.Run("creates-stream", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		c := &testCloudWatchClient{}
+		s, err := newCloudWatchSink(testEventer, testConfig, string(JSONSinkFormat), factory(c))
+		require.NoError(err)
+		// the stream is resolved by the first put
+		assert.Empty(c.created)
+
+		for _, data := range []string{"one", "two"} {
+			got, err := s.Process(context.Background(), testEvent(data))
+			require.NoError(err)
+			assert.Nil(got)
+		}
+		assert.Equal([]string{"controller"}, c.created)
+		assert.Equal([][]string{{"one"}, {"two"}}, c.puts)
+	})
+	t.Run("existing-stream", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		c := &testCloudWatchClient{streams: map[string]*string{"controller": aws.String("existing-token"), "controller-2": nil}}
+		s, err := newCloudWatchSink(testEventer, testConfig, string(JSONSinkFormat), factory(c))
+		require.NoError(err)
+		_, err = s.Process(context.Background(), testEvent("one"))
+		require.NoError(err)
+		assert.Empty(c.created)
+		assert.Len(c.puts, 1)
+	})
+	t.Run("invalid-sequence-token", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		c := &testCloudWatchClient{}
+		s, err := newCloudWatchSink(testEventer, testConfig, string(JSONSinkFormat), factory(c))
+		require.NoError(err)
+		_, err = s.Process(context.Background(), testEvent("one"))
+		require.NoError(err)
+		// another writer puts to the stream
+		c.l.Lock()
+		c.streams["controller"] = aws.String("other-writer")
+		c.l.Unlock()
+		_, err = s.Process(context.Background(), testEvent("two"))
+		require.NoError(err)
+		assert.Equal([][]string{{"one"}, {"two"}}, c.puts)
+	})
+	t.Run("data-already-accepted", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		c := &testCloudWatchClient{accepted: true}
+		s, err := newCloudWatchSink(testEventer, testConfig, string(JSONSinkFormat), factory(c))
+		require.NoError(err)
+		for _, data := range []string{"one", "two"} {
+			_, err := s.Process(context.Background(), testEvent(data))
+			require.NoError(err)
+		}
+		assert.Equal([][]string{{"one"}, {"two"}}, c.puts)
+	})
+	t.Run("throttled", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		c := &testCloudWatchClient{throttles: stdRetryCount}
+		s, err := newCloudWatchSink(testEventer, testConfig, string(JSONSinkFormat), factory(c))
+		require.NoError(err)
+		_, err = s.Process(context.Background(), testEvent("one"))
+		require.NoError(err)
+		assert.Len(c.puts, 1)
+	})
+	t.Run("max-retries", func(t *testing.T) {
+		c := &testCloudWatchClient{throttles: stdRetryCount + 1}
+		s, err := newCloudWatchSink(testEventer, testConfig, string(JSONSinkFormat), factory(c))
+		require.NoError(t, err)
+		_, err = s.Process(context.Background(), testEvent("one"))
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrMaxRetries)
+	})
+	t.Run("event-too-large", func(t *testing.T) {
+		c := &testCloudWatchClient{}
+		s, err := newCloudWatchSink(testEventer, testConfig, string(JSONSinkFormat), factory(c))
+		require.NoError(t, err)
+		_, err = s.Process(context.Background(), testEvent(strings.Repeat("a", cloudWatchMaxEventBytes)))
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+		assert.Empty(t, c.puts)
+	})
+	t.Run("batches-while-putting", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		c := &testCloudWatchClient{}
+		s, err := newCloudWatchSink(testEventer, testConfig, string(JSONSinkFormat), factory(c))
+		require.NoError(err)
+		c.putStarted, c.releasePut = make(chan struct{}), make(chan struct{})
+
+		var wg sync.WaitGroup
+		process := func(data string) {
+			defer wg.Done()
+			_, err := s.Process(context.Background(), testEvent(data))
+			assert.NoError(err)
+		}
+		wg.Add(1)
+		go process("first")
+		<-c.putStarted
+
+		// events processed while the first put is blocked are batched
+		const batched = 5
+		wg.Add(batched)
+		for i := 0; i < batched; i++ {
+			go process(fmt.Sprintf("batched-%d", i))
+		}
+		assert.Eventually(func() bool {
+			s.batchLock.Lock()
+			defer s.batchLock.Unlock()
+			return s.pending != nil && len(s.pending.events) == batched
+		}, 5*time.Second, 10*time.Millisecond)
+		c.releasePut <- struct{}{}
+		<-c.putStarted
+		c.releasePut <- struct{}{}
+		wg.Wait()
+
+		require.Len(c.puts, 2)
+		assert.Equal([]string{"first"}, c.puts[0])
+		assert.Len(c.puts[1], batched)
+	})
+	t.Run("cancelled-leader", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		c := &testCloudWatchClient{}
+		s, err := newCloudWatchSink(testEventer, testConfig, string(JSONSinkFormat), factory(c))
+		require.NoError(err)
+		c.putStarted, c.releasePut = make(chan struct{}), make(chan struct{})
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.Process(context.Background(), testEvent("first"))
+			assert.NoError(err)
+		}()
+		<-c.putStarted
+
+		// the caller which starts the next batch gives up before it's put,
+		// which doesn't fail the event added to it by another caller
+		ctx, cancel := context.WithCancel(context.Background())
+		leaderErr := make(chan error, 1)
+		go func() {
+			_, err := s.Process(ctx, testEvent("cancelled"))
+			leaderErr <- err
+		}()
+		assert.Eventually(func() bool {
+			s.batchLock.Lock()
+			defer s.batchLock.Unlock()
+			return s.pending != nil && len(s.pending.events) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.Process(context.Background(), testEvent("batched"))
+			assert.NoError(err)
+		}()
+		assert.Eventually(func() bool {
+			s.batchLock.Lock()
+			defer s.batchLock.Unlock()
+			return len(s.pending.events) == 2
+		}, 5*time.Second, 10*time.Millisecond)
+		cancel()
+		assert.ErrorIs(<-leaderErr, context.Canceled)
+
+		c.releasePut <- struct{}{}
+		<-c.putStarted
+		c.releasePut <- struct{}{}
+		wg.Wait()
+		require.Len(c.puts, 2)
+		assert.Equal([]string{"cancelled", "batched"}, c.puts[1])
+	})
+	t.Run("batch-limits", func(t *testing.T) {
+		assert := assert.New(t)
+		now := time.Now()
+		logEvent := func(size int, at time.Time) *cloudwatchlogs.InputLogEvent {
+			return &cloudwatchlogs.InputLogEvent{
+				Message:   aws.String(strings.Repeat("a", size)),
+				Timestamp: aws.Int64(at.UnixNano() / int64(time.Millisecond)),
+			}
+		}
+		b := &cloudWatchBatch{}
+		assert.True(b.fits(logEvent(cloudWatchMaxEventBytes, now)))
+		for i := 0; i < 4; i++ {
+			b.add(logEvent(cloudWatchMaxEventBytes-cloudWatchEventOverhead, now))
+		}
+		assert.False(b.fits(logEvent(1, now)))
+
+		b = &cloudWatchBatch{}
+		b.add(logEvent(1, now))
+		assert.True(b.fits(logEvent(1, now.Add(time.Hour))))
+		assert.False(b.fits(logEvent(1, now.Add(cloudWatchMaxBatchSpan))))
+
+		b = &cloudWatchBatch{}
+		for i := 0; i < cloudWatchMaxBatchEvents; i++ {
+			b.add(logEvent(1, now))
+		}
+		assert.False(b.fits(logEvent(1, now)))
+	})
+	t.Run("reopen", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		c := &testCloudWatchClient{}
+		s, err := newCloudWatchSink(testEventer, testConfig, string(JSONSinkFormat), factory(c))
+		require.NoError(err)
+		_, err = s.Process(context.Background(), testEvent("one"))
+		require.NoError(err)
+
+		// the stream is deleted, and the next put after reopening
+		// recreates it
+		delete(c.streams, "controller")
+		require.NoError(s.Reopen())
+		_, err = s.Process(context.Background(), testEvent("two"))
+		require.NoError(err)
+		assert.Equal([]string{"controller", "controller"}, c.created)
+		assert.Len(c.puts, 2)
+	})
+}
+
+func TestEventer_cloudWatchSink(t *testing.T) {
+	t.Parallel()
+	assert, require := assert.New(t), require.New(t)
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	c := EventerConfig{
+		AuditEnabled: true,
+		Sinks: []SinkConfig{
+			{
+				Name:             "cloudwatch",
+				SinkType:         CloudWatchSink,
+				EventTypes:       []Type{AuditType},
+				Format:           JSONSinkFormat,
+				CloudWatchConfig: &CloudWatchSinkConfig{LogGroup: "boundary", LogStream: "controller", Region: "us-east-1"},
+			},
+			{
+				Name:       "discard",
+				SinkType:   DiscardSink,
+				EventTypes: []Type{ErrorType, SystemType},
+				Format:     JSONSinkFormat,
+			},
+		},
+	}
+	client := &testCloudWatchClient{}
+	var configs []*CloudWatchSinkConfig
+	e, err := NewEventer(testLogger, testLock, c, WithCloudWatchClient(func(cc *CloudWatchSinkConfig) (CloudWatchLogsClient, error) {
+		configs = append(configs, cc)
+		return client, nil
+	}))
+	require.NoError(err)
+	// the client isn't created until the first put
+	assert.Empty(configs)
+
+	testAudit, err := newAudit("TestEventer_cloudWatchSink", WithRequestInfo(&RequestInfo{Id: "test-request-id"}), WithFlush())
+	require.NoError(err)
+	require.NoError(e.writeAudit(context.Background(), testAudit))
+
+	require.Len(client.puts, 1)
+	require.Len(client.puts[0], 1)
+	assert.Contains(client.puts[0][0], "test-request-id")
+	require.Len(configs, 1)
+	assert.Equal("us-east-1", configs[0].Region)
+
+	require.NoError(e.Reopen())
+	require.NoError(e.writeAudit(context.Background(), testAudit))
+	assert.Len(configs, 2)
+}
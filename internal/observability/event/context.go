@@ -2,6 +2,7 @@ package event
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"runtime"
@@ -16,6 +17,9 @@ type key int
 const (
 	eventerKey key = iota
 	requestInfoKey
+	correlationIdKey
+	authInfoKey
+	spanKey
 )
 
 // NewEventerContext will return a context containing a value of the provided Eventer
@@ -65,6 +69,57 @@ func RequestInfoFromContext(ctx context.Context) (*RequestInfo, bool) {
 	return reqInfo, ok
 }
 
+// WithCorrelationId will return a context containing the provided correlation
+// id.  The correlation id is added to every event written with the returned
+// context, which allows all of a request's events to be stitched together.
+func WithCorrelationId(ctx context.Context, id string) (context.Context, error) {
+	const op = "event.WithCorrelationId"
+	if ctx == nil {
+		return nil, fmt.Errorf("%s: missing context: %w", op, ErrInvalidParameter)
+	}
+	if id == "" {
+		return nil, fmt.Errorf("%s: missing correlation id: %w", op, ErrInvalidParameter)
+	}
+	return context.WithValue(ctx, correlationIdKey, id), nil
+}
+
+// CorrelationIdFromContext attempts to get the correlation id value from the
+// context provided
+func CorrelationIdFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	id, ok := ctx.Value(correlationIdKey).(string)
+	return id, ok && id != ""
+}
+
+// WithAuthInfo will return a context containing the provided AuthInfo.  The
+// info is recorded in every audit event written with the returned context.
+// Since a request's identity is only known once it has been authenticated,
+// the info may be added to the context when the request starts and updated
+// once the request has been authenticated; audit events record the info as
+// it is when they're written.
+func WithAuthInfo(ctx context.Context, info *AuthInfo) (context.Context, error) {
+	const op = "event.WithAuthInfo"
+	if ctx == nil {
+		return nil, fmt.Errorf("%s: missing context: %w", op, ErrInvalidParameter)
+	}
+	if info == nil {
+		return nil, fmt.Errorf("%s: missing auth info: %w", op, ErrInvalidParameter)
+	}
+	return context.WithValue(ctx, authInfoKey, info), nil
+}
+
+// AuthInfoFromContext attempts to get the AuthInfo value from the context
+// provided
+func AuthInfoFromContext(ctx context.Context) (*AuthInfo, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	info, ok := ctx.Value(authInfoKey).(*AuthInfo)
+	return info, ok && info != nil
+}
+
 // WriteObservation will write an observation event.  It will first check the
 // ctx for an eventer, then try event.SysEventer() and if no eventer can be
 // found an error is returned.
@@ -73,43 +128,57 @@ func RequestInfoFromContext(ctx context.Context) (*RequestInfo, bool) {
 // WithHeader, WithDetails, WithId, WithFlush and WithRequestInfo. All other
 // options are ignored.
 func WriteObservation(ctx context.Context, caller Op, opt ...Option) error {
+	_, err := WriteObservationWithResult(ctx, caller, opt...)
+	return err
+}
+
+// WriteObservationWithResult will write an observation event, like
+// WriteObservation, and return which sinks it was written to.  An observation
+// is held by the eventer until it's flushed (see WithFlush), so the result of
+// a write which isn't flushed has no sinks, and the result of a flush includes
+// the sinks of the observation composed from each of its writes.  When the
+// eventer retries the write, only the last attempt's outcomes are returned.
+// The result of a write held while observations are paused (see
+// PauseObservations) has no sinks.
+func WriteObservationWithResult(ctx context.Context, caller Op, opt ...Option) (SendResult, error) {
 	// TODO (jimlambrt) 6/2021: remove this feature flag envvar when events are
 	// generally available.
 	if !strings.EqualFold(os.Getenv(globals.BOUNDARY_DEVELOPER_ENABLE_EVENTS), "true") {
-		return nil
+		return SendResult{}, nil
 	}
-	const op = "event.WriteObservation"
+	const op = "event.WriteObservationWithResult"
 	if ctx == nil {
-		return fmt.Errorf("%s: missing context: %w", op, ErrInvalidParameter)
+		return SendResult{}, fmt.Errorf("%s: missing context: %w", op, ErrInvalidParameter)
 	}
 	if caller == "" {
-		return fmt.Errorf("%s: missing operation: %w", op, ErrInvalidParameter)
+		return SendResult{}, fmt.Errorf("%s: missing operation: %w", op, ErrInvalidParameter)
 	}
 	eventer, ok := EventerFromContext(ctx)
 	if !ok {
 		eventer = SysEventer()
 		if eventer == nil {
-			return fmt.Errorf("%s: missing both context and system eventer: %w", op, ErrInvalidParameter)
+			return SendResult{}, fmt.Errorf("%s: missing both context and system eventer: %w", op, ErrInvalidParameter)
 		}
 	}
 	opts := getOpts(opt...)
 	if opts.withDetails == nil && opts.withHeader == nil && !opts.withFlush {
-		return fmt.Errorf("%s: specify either header or details options for an event payload: %w", op, ErrInvalidParameter)
+		return SendResult{}, fmt.Errorf("%s: specify either header or details options for an event payload: %w", op, ErrInvalidParameter)
 	}
 	if opts.withRequestInfo == nil {
 		var err error
 		if opt, err = addCtxOptions(ctx, opt...); err != nil {
-			return fmt.Errorf("%s: %w", op, err)
+			return SendResult{}, fmt.Errorf("%s: %w", op, err)
 		}
 	}
 	e, err := newObservation(caller, opt...)
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return SendResult{}, fmt.Errorf("%s: %w", op, err)
 	}
+	ctx, recorder := newSendResultContext(ctx)
 	if err := eventer.writeObservation(ctx, e); err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return recorder.result(), fmt.Errorf("%s: %w", op, err)
 	}
-	return nil
+	return recorder.result(), nil
 }
 
 // WriteError will write an error event.  It will first check the
@@ -166,42 +235,94 @@ func WriteError(ctx context.Context, caller Op, e error, opt ...Option) {
 // WithRequest, WithResponse, WithAuth, WithId, WithFlush and WithRequestInfo.
 // All other options are ignored.
 func WriteAudit(ctx context.Context, caller Op, opt ...Option) error {
+	_, err := WriteAuditWithResult(ctx, caller, opt...)
+	return err
+}
+
+// WriteAuditWithResult will write an audit event, like WriteAudit, and return
+// which sinks it was written to.  An audit event is held by the eventer until
+// it's flushed (see WithFlush and EventerConfig.FlushEachAudit), so the result
+// of a write which isn't flushed has no sinks.  An error is returned when the
+// event couldn't be delivered.
+func WriteAuditWithResult(ctx context.Context, caller Op, opt ...Option) (SendResult, error) {
 	// TODO (jimlambrt) 6/2021: remove this feature flag envvar when events are
 	// generally available.
 	if !strings.EqualFold(os.Getenv(globals.BOUNDARY_DEVELOPER_ENABLE_EVENTS), "true") {
-		return nil
+		return SendResult{}, nil
 	}
-	const op = "event.WriteAudit"
+	const op = "event.WriteAuditWithResult"
 	if ctx == nil {
-		return fmt.Errorf("%s: missing context: %w", op, ErrInvalidParameter)
+		return SendResult{}, fmt.Errorf("%s: missing context: %w", op, ErrInvalidParameter)
 	}
 	if caller == "" {
-		return fmt.Errorf("%s: missing operation: %w", op, ErrInvalidParameter)
+		return SendResult{}, fmt.Errorf("%s: missing operation: %w", op, ErrInvalidParameter)
 	}
 	eventer, ok := EventerFromContext(ctx)
 	if !ok {
 		eventer = SysEventer()
 		if eventer == nil {
-			return fmt.Errorf("%s: missing both context and system eventer: %w", op, ErrInvalidParameter)
+			return SendResult{}, fmt.Errorf("%s: missing both context and system eventer: %w", op, ErrInvalidParameter)
 		}
 	}
 	opts := getOpts(opt...)
 	if opts.withRequestInfo == nil {
 		var err error
 		if opt, err = addCtxOptions(ctx, opt...); err != nil {
-			return fmt.Errorf("%s: %w", op, err)
+			return SendResult{}, fmt.Errorf("%s: %w", op, err)
 		}
 	}
 	e, err := newAudit(caller, opt...)
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return SendResult{}, fmt.Errorf("%s: %w", op, err)
 	}
+	ctx, recorder := newSendResultContext(ctx)
 	if err := eventer.writeAudit(ctx, e); err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return recorder.result(), fmt.Errorf("%s: %w", op, err)
+	}
+	return recorder.result(), nil
+}
+
+// CheckAuditDegraded returns ErrAuditDegraded when the eventer (from the ctx
+// or the system eventer) is degraded because audit events can't be delivered
+// (see EventerConfig.AuditFailClosedThreshold).  Operations which must be
+// audited should be rejected when it returns an error.  It returns nil when
+// there's no eventer.
+func CheckAuditDegraded(ctx context.Context) error {
+	const op = "event.CheckAuditDegraded"
+	eventer, ok := EventerFromContext(ctx)
+	if !ok {
+		eventer = SysEventer()
+	}
+	if eventer != nil && eventer.AuditDegraded() {
+		return fmt.Errorf("%s: %w", op, ErrAuditDegraded)
 	}
 	return nil
 }
 
+// WriteAuditFailClosed will write an audit event, like WriteAudit, for an
+// operation which mustn't proceed un-audited.  When the event can't be
+// delivered, the returned error wraps ErrAuditNotDelivered, and the caller
+// should abort the operation:
+//
+//	if err := event.WriteAuditFailClosed(ctx, op, event.WithFlush()); err != nil {
+//		return err
+//	}
+//
+// Errors writing the event which aren't delivery failures (like a missing
+// operation) are returned as they are.
+func WriteAuditFailClosed(ctx context.Context, caller Op, opt ...Option) error {
+	const op = "event.WriteAuditFailClosed"
+	_, err := WriteAuditWithResult(ctx, caller, opt...)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrInvalidParameter):
+		return fmt.Errorf("%s: %w", op, err)
+	default:
+		return fmt.Errorf("%s: %w", op, &auditNotDeliveredError{err: err})
+	}
+}
+
 func addCtxOptions(ctx context.Context, opt ...Option) ([]Option, error) {
 	const op = "event.addCtxOptions"
 	opts := getOpts(opt...)
@@ -292,3 +413,52 @@ func WriteSysEvent(ctx context.Context, caller Op, data map[string]interface{})
 		return
 	}
 }
+
+// WriteEvent will write an event of a custom type registered with
+// RegisterEventType.  It will first check the ctx for an eventer, then try
+// event.SysEventer() and if no eventer can be found an error is returned.  The
+// event is written to the sinks which subscribe to its type, and its
+// correlation id is taken from the ctx (see WithCorrelationId).
+//
+// Supported options: WithId and WithHeader.  All other options are ignored.
+func WriteEvent(ctx context.Context, t Type, caller Op, data map[string]interface{}, opt ...Option) error {
+	const op = "event.WriteEvent"
+	switch {
+	case ctx == nil:
+		return fmt.Errorf("%s: missing context: %w", op, ErrInvalidParameter)
+	case caller == "":
+		return fmt.Errorf("%s: missing operation: %w", op, ErrInvalidParameter)
+	case data == nil:
+		return fmt.Errorf("%s: missing data: %w", op, ErrInvalidParameter)
+	}
+	if _, ok := registeredType(t); !ok {
+		return fmt.Errorf("%s: '%s' is not a registered event type: %w", op, t, ErrInvalidParameter)
+	}
+	eventer, ok := EventerFromContext(ctx)
+	if !ok {
+		eventer = SysEventer()
+		if eventer == nil {
+			return fmt.Errorf("%s: missing both context and system eventer: %w", op, ErrInvalidParameter)
+		}
+	}
+	opts := getOpts(opt...)
+	id := opts.withId
+	if id == "" {
+		var err error
+		if id, err = newId(string(t)); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	e := &customEvent{
+		Id:        Id(id),
+		Version:   eventer.schemaVersion,
+		Op:        caller,
+		Data:      data,
+		Header:    opts.withHeader,
+		eventType: t,
+	}
+	if err := eventer.writeCustomEvent(ctx, e); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package event_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -37,6 +38,7 @@ type testAudit struct {
 	Timestamp      time.Time          `json:"timestamp"`              // std audit field
 	RequestInfo    *event.RequestInfo `json:"request_info,omitempty"` // boundary field
 	Auth           *event.Auth        `json:"auth,omitempty"`         // std audit field
+	AuthInfo       *event.AuthInfo    `json:"auth_info,omitempty"`    // boundary field
 	Request        *event.Request     `json:"request,omitempty"`      // std audit field
 	Response       *event.Response    `json:"response,omitempty"`     // std audit field
 	SerializedHMAC string             `json:"serialized_hmac"`        // boundary field
@@ -555,6 +557,12 @@ func Test_WriteAudit(t *testing.T) {
 	require.NoError(t, err)
 	ctx, err = event.NewRequestInfoContext(ctx, info)
 	require.NoError(t, err)
+	authCtx, err := event.WithAuthInfo(ctx, &event.AuthInfo{
+		UserId:        "u_1234567890",
+		AuthMethodId:  "ampw_1234567890",
+		AuthAccountId: "acctpw_1234567890",
+	})
+	require.NoError(t, err)
 
 	testAuth := &event.Auth{}
 	testReq := &event.Request{
@@ -649,8 +657,9 @@ func Test_WriteAudit(t *testing.T) {
 				},
 			},
 			wantAudit: &testAudit{
-				Auth:    testAuth,
-				Request: testReq,
+				Auth:     testAuth,
+				AuthInfo: &event.AuthInfo{Authenticated: false},
+				Request:  testReq,
 			},
 			setup: func() error {
 				return event.InitSysEventer(testLogger, testLock, event.WithEventerConfig(&c.EventerConfig))
@@ -673,6 +682,33 @@ func Test_WriteAudit(t *testing.T) {
 			wantAudit: &testAudit{
 				Id:       "867-5309",
 				Auth:     testAuth,
+				AuthInfo: &event.AuthInfo{Authenticated: false},
+				Request:  testReq,
+				Response: testResp,
+			},
+			auditSinkFileName: c.AllEvents.Name(),
+		},
+		{
+			name: "authenticated",
+			ctx:  authCtx,
+			auditOpts: [][]event.Option{
+				{
+					event.WithAuth(testAuth),
+					event.WithRequest(testReq),
+				},
+				{
+					event.WithResponse(testResp),
+				},
+			},
+			wantAudit: &testAudit{
+				Id:   "867-5309",
+				Auth: testAuth,
+				AuthInfo: &event.AuthInfo{
+					UserId:        "u_1234567890",
+					AuthMethodId:  "ampw_1234567890",
+					AuthAccountId: "acctpw_1234567890",
+					Authenticated: true,
+				},
 				Request:  testReq,
 				Response: testResp,
 			},
@@ -725,6 +761,7 @@ func Test_WriteAudit(t *testing.T) {
 					EventType: string(gotAudit.EventType),
 					Payload: map[string]interface{}{
 						"auth":            tt.wantAudit.Auth,
+						"auth_info":       tt.wantAudit.AuthInfo,
 						"id":              gotAudit.Payload["id"],
 						"timestamp":       now,
 						"request":         tt.wantAudit.Request,
@@ -999,3 +1036,159 @@ func Test_WriteSysEvent(t *testing.T) {
 		})
 	}
 }
+
+func Test_WithCorrelationId(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name            string
+		ctx             context.Context
+		id              string
+		wantErrIs       error
+		wantErrContains string
+	}{
+		{
+			name:            "missing-ctx",
+			id:              "test-correlation-id",
+			wantErrIs:       event.ErrInvalidParameter,
+			wantErrContains: "missing context",
+		},
+		{
+			name:            "missing-id",
+			ctx:             context.Background(),
+			wantErrIs:       event.ErrInvalidParameter,
+			wantErrContains: "missing correlation id",
+		},
+		{
+			name: "valid",
+			ctx:  context.Background(),
+			id:   "test-correlation-id",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			ctx, err := event.WithCorrelationId(tt.ctx, tt.id)
+			if tt.wantErrIs != nil {
+				require.Error(err)
+				assert.Nil(ctx)
+				assert.ErrorIs(err, tt.wantErrIs)
+				if tt.wantErrContains != "" {
+					assert.Contains(err.Error(), tt.wantErrContains)
+				}
+				return
+			}
+			require.NoError(err)
+			got, ok := event.CorrelationIdFromContext(ctx)
+			require.True(ok)
+			assert.Equal(tt.id, got)
+		})
+	}
+	t.Run("not-set", func(t *testing.T) {
+		got, ok := event.CorrelationIdFromContext(context.Background())
+		assert.False(t, ok)
+		assert.Empty(t, got)
+	})
+}
+
+func Test_WithAuthInfo(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name            string
+		ctx             context.Context
+		info            *event.AuthInfo
+		wantErrIs       error
+		wantErrContains string
+	}{
+		{
+			name:            "missing-ctx",
+			info:            &event.AuthInfo{UserId: "u_1234567890"},
+			wantErrIs:       event.ErrInvalidParameter,
+			wantErrContains: "missing context",
+		},
+		{
+			name:            "missing-info",
+			ctx:             context.Background(),
+			wantErrIs:       event.ErrInvalidParameter,
+			wantErrContains: "missing auth info",
+		},
+		{
+			name: "valid",
+			ctx:  context.Background(),
+			info: &event.AuthInfo{UserId: "u_1234567890", AuthMethodId: "ampw_1234567890", AuthAccountId: "acctpw_1234567890"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			ctx, err := event.WithAuthInfo(tt.ctx, tt.info)
+			if tt.wantErrIs != nil {
+				require.Error(err)
+				assert.Nil(ctx)
+				assert.ErrorIs(err, tt.wantErrIs)
+				if tt.wantErrContains != "" {
+					assert.Contains(err.Error(), tt.wantErrContains)
+				}
+				return
+			}
+			require.NoError(err)
+			got, ok := event.AuthInfoFromContext(ctx)
+			require.True(ok)
+			assert.Equal(tt.info, got)
+		})
+	}
+	t.Run("not-set", func(t *testing.T) {
+		got, ok := event.AuthInfoFromContext(context.Background())
+		assert.False(t, ok)
+		assert.Nil(t, got)
+	})
+}
+
+func Test_CorrelationIdAllEventTypes(t *testing.T) {
+	// this test cannot be run in parallel because of it's dependency on the
+	// sysEventer
+	event.TestEnableEventing(t, true)
+	assert, require := assert.New(t), require.New(t)
+
+	c := event.TestEventerConfig(t, "Test_CorrelationIdAllEventTypes")
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	require.NoError(event.InitSysEventer(testLogger, testLock, event.WithEventerConfig(&c.EventerConfig)))
+	t.Cleanup(func() { event.TestResetSystEventer(t) })
+
+	const correlationId = "test-correlation-id"
+	ctx, err := event.NewEventerContext(context.Background(), event.SysEventer())
+	require.NoError(err)
+	ctx, err = event.NewRequestInfoContext(ctx, event.TestRequestInfo(t))
+	require.NoError(err)
+	ctx, err = event.WithCorrelationId(ctx, correlationId)
+	require.NoError(err)
+
+	require.NoError(event.WriteObservation(ctx, "test-observation", event.WithHeader(map[string]interface{}{"test": "header"}), event.WithFlush()))
+	require.NoError(event.WriteAudit(ctx, "test-audit", event.WithAuth(&event.Auth{UserEmail: "alice@example.com"}), event.WithFlush()))
+	event.WriteError(ctx, "test-error", fmt.Errorf("test error"))
+	event.WriteSysEvent(ctx, "test-sysevent", map[string]interface{}{"test": "data"})
+
+	b, err := ioutil.ReadFile(c.AllEvents.Name())
+	require.NoError(err)
+	dec := json.NewDecoder(bytes.NewReader(b))
+	gotTypes := map[string]bool{}
+	for dec.More() {
+		got := &eventJson{}
+		require.NoError(dec.Decode(got))
+		gotTypes[got.EventType] = true
+		payload := got.Payload
+		if got.EventType == string(event.ObservationType) {
+			payload = got.Payload[event.HeaderField].(map[string]interface{})
+		}
+		assert.Equalf(correlationId, payload[event.CorrelationIdField], "%s event", got.EventType)
+	}
+	assert.Equal(map[string]bool{
+		string(event.ObservationType): true,
+		string(event.AuditType):       true,
+		string(event.ErrorType):       true,
+		string(event.SystemType):      true,
+	}, gotTypes)
+}
@@ -0,0 +1,213 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/eventlogger/filters/gated"
+	"github.com/hashicorp/eventlogger/sinks/writer"
+)
+
+// debugSink is a sink enabled at runtime by EnableDebugSink, which receives
+// the eventer's observations alongside its configured sinks.
+type debugSink struct {
+	config     SinkConfig
+	pipelineId eventlogger.PipelineID
+	gate       *gated.Filter
+
+	// nodes are the nodes of the sink's pipeline after its gate, ending
+	// with sinkNode.
+	nodes    []eventlogger.Node
+	sinkNode eventlogger.Node
+}
+
+// Send processes an observation flushed by the sink's gate with the nodes
+// after the gate, so it's only written to the debug sink rather than sent to
+// every sink again.
+func (d *debugSink) Send(ctx context.Context, t eventlogger.EventType, payload interface{}) (eventlogger.Status, error) {
+	e := &eventlogger.Event{
+		Type:      t,
+		CreatedAt: time.Now(),
+		Formatted: make(map[string][]byte),
+		Payload:   payload,
+	}
+	for _, n := range d.nodes {
+		var err error
+		if e, err = n.Process(ctx, e); err != nil {
+			return eventlogger.Status{}, err
+		}
+		if e == nil {
+			// the event was filtered
+			break
+		}
+	}
+	return eventlogger.Status{}, nil
+}
+
+// EnableDebugSink adds a sink which receives every observation sent to the
+// eventer's sinks, for troubleshooting without restarting or editing the
+// eventer's config.  The eventer's configured sinks are unchanged, and the
+// debug sink isn't included in Sinks or Health.  Observations must be enabled
+// in the eventer's config for the sink to receive any.
+//
+// The sink must be a stderr or file sink.  Its event types default to
+// observations, which are the only type it may have, and its format and
+// filters are used like a configured sink's.  Mirrors, spools, writer groups,
+// batching, rotate schedules, field name maps and file name templates aren't
+// supported, and a file sink can't write to a configured sink's file.  Only
+// one debug sink is enabled at a time: DisableDebugSink removes it.
+func (e *Eventer) EnableDebugSink(sc SinkConfig) error {
+	const op = "event.(Eventer).EnableDebugSink"
+	if len(sc.EventTypes) == 0 {
+		sc.EventTypes = []Type{ObservationType}
+	}
+	if err := sc.validate(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	switch {
+	case len(sc.EventTypes) != 1 || sc.EventTypes[0] != ObservationType:
+		return fmt.Errorf("%s: debug sinks only receive %s events: %w", op, ObservationType, ErrInvalidParameter)
+	case sc.SinkType != StderrSink && sc.SinkType != FileSink:
+		return fmt.Errorf("%s: debug sinks must be %s or %s sinks: %w", op, StderrSink, FileSink, ErrInvalidParameter)
+	case len(sc.Mirrors) > 0 || sc.SpoolPath != "" || sc.WriterGroup != "" || sc.BatchSize > 1 ||
+		sc.RotateSchedule != "" || len(sc.FieldNameMap) > 0 || sc.FileNameTemplate != "":
+		return fmt.Errorf("%s: debug sink %q uses a setting which isn't supported by debug sinks: %w", op, sc.Name, ErrInvalidParameter)
+	}
+
+	e.closeLock.Lock()
+	defer e.closeLock.Unlock()
+	if e.closed {
+		return fmt.Errorf("%s: %w", op, ErrEventerClosed)
+	}
+	if e.debugSink != nil {
+		return fmt.Errorf("%s: debug sink %q is already enabled: %w", op, e.debugSink.config.Name, ErrInvalidParameter)
+	}
+	if sc.SinkType == FileSink {
+		target := sc.outputTarget()
+		for _, pipelines := range [][]pipeline{e.auditPipelines, e.observationPipelines, e.errPipelines, e.sysPipelines, e.customPipelines} {
+			for _, p := range pipelines {
+				if p.sinkConfig.outputTarget() == target {
+					return fmt.Errorf("%s: debug sink %q and sink %q have the same output target (%s): %w", op, sc.Name, p.sinkConfig.Name, target, ErrDuplicateSink)
+				}
+			}
+		}
+	}
+
+	format := sc.formatKey()
+	fmtNode, err := newFormatterNode(format)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	filterNode, err := newSinkFilter(sc.AllowFilters, sc.DenyFilters, sc.OpPrefixes, sc.ScopeIds, sc.DropMissingScope)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	var sinkNode eventlogger.Node
+	switch sc.SinkType {
+	case StderrSink:
+		sinkNode = &writer.Sink{
+			Format: format,
+			Writer: e.stderr,
+		}
+	default:
+		if sinkNode, err = newConfiguredFileSink(sc, format); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	d := &debugSink{
+		config:   sc,
+		sinkNode: sinkNode,
+	}
+	d.gate = &gated.Filter{Broker: d}
+	nodes := []eventlogger.Node{d.gate}
+	if filterNode != nil {
+		nodes = append(nodes, filterNode)
+	}
+	nodes = append(nodes, fmtNode, sinkNode)
+	d.nodes = nodes[1:]
+
+	// the nodes can't be unregistered, so a failure leaves them registered
+	// but unreachable, like the nodes of a disabled debug sink
+	closeSink := func() {
+		if c, ok := sinkNode.(io.Closer); ok {
+			_ = c.Close()
+		}
+	}
+	nodeIds := make([]eventlogger.NodeID, 0, len(nodes))
+	for _, n := range nodes {
+		id, err := newId("debug")
+		if err != nil {
+			closeSink()
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if err := e.broker.RegisterNode(eventlogger.NodeID(id), n); err != nil {
+			closeSink()
+			return fmt.Errorf("%s: failed to register debug sink node: %w", op, err)
+		}
+		nodeIds = append(nodeIds, eventlogger.NodeID(id))
+	}
+	pipeId, err := newId(observationPipeline)
+	if err != nil {
+		closeSink()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	d.pipelineId = eventlogger.PipelineID(pipeId)
+	err = e.broker.RegisterPipeline(eventlogger.Pipeline{
+		EventType:  eventlogger.EventType(ObservationType),
+		PipelineID: d.pipelineId,
+		NodeIDs:    nodeIds,
+	})
+	if err != nil {
+		closeSink()
+		return fmt.Errorf("%s: failed to register debug sink pipeline: %w", op, err)
+	}
+	e.debugSink = d
+	e.logger.Info("debug sink enabled", "sink", sc.Name, "sink_type", sc.SinkType)
+	return nil
+}
+
+// DisableDebugSink removes the sink added by EnableDebugSink.  The
+// observations held by the sink's gate are flushed to it, its pipeline is
+// removed so it receives no more events, and then it's closed.  Like
+// ReopenSinks, writes are quiesced while it's removed.  Disabling a debug sink
+// which isn't enabled is a no op.
+func (e *Eventer) DisableDebugSink() error {
+	const op = "event.(Eventer).DisableDebugSink"
+	e.closeLock.Lock()
+	defer e.closeLock.Unlock()
+	if e.closed {
+		return fmt.Errorf("%s: %w", op, ErrEventerClosed)
+	}
+	if err := e.removeDebugSink(context.Background()); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// removeDebugSink flushes, removes and closes the debug sink when it's
+// enabled.  The caller must hold the closeLock for writing.
+func (e *Eventer) removeDebugSink(ctx context.Context) error {
+	const op = "event.(Eventer).removeDebugSink"
+	d := e.debugSink
+	if d == nil {
+		return nil
+	}
+	e.debugSink = nil
+	var flushErr error
+	if err := d.gate.FlushAll(ctx); err != nil {
+		flushErr = fmt.Errorf("%s: unable to flush debug sink %q: %w", op, d.config.Name, err)
+	}
+	if err := e.broker.RemovePipeline(eventlogger.EventType(ObservationType), d.pipelineId); err != nil {
+		return fmt.Errorf("%s: unable to remove debug sink %q: %w", op, d.config.Name, err)
+	}
+	if c, ok := d.sinkNode.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			return fmt.Errorf("%s: unable to close debug sink %q: %w", op, d.config.Name, err)
+		}
+	}
+	e.logger.Info("debug sink disabled", "sink", d.config.Name)
+	return flushErr
+}
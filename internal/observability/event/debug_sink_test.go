@@ -0,0 +1,186 @@
+package event
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventer_EnableDebugSink(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	writeObservation := func(t *testing.T, e *Eventer, id string, opt ...Option) {
+		t.Helper()
+		obs, err := newObservation("TestEventer_EnableDebugSink", append(opt, WithId(id))...)
+		require.NoError(t, err)
+		require.NoError(t, e.writeObservation(ctx, obs))
+	}
+	readFile := func(t *testing.T, path string) string {
+		t.Helper()
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	t.Run("enable-emit-disable", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		e, buf := NewTestEventer(t)
+		dir := t.TempDir()
+		require.NoError(e.EnableDebugSink(SinkConfig{
+			Name:     "debug",
+			SinkType: FileSink,
+			Format:   JSONSinkFormat,
+			Path:     dir,
+			FileName: "debug.log",
+		}))
+		path := filepath.Join(dir, "debug.log")
+
+		writeObservation(t, e, "flushed", WithFlush())
+		assert.Contains(readFile(t, path), `"flushed"`)
+		assert.Len(buf.Events(t, ObservationType), 1)
+
+		// other event types aren't sent to the debug sink
+		require.NoError(e.writeSysEvent(ctx, &sysEvent{Id: "sys-id", Op: "TestEventer_EnableDebugSink", Data: map[string]interface{}{"name": "data"}}))
+		assert.NotContains(readFile(t, path), "sys-id")
+
+		// disabling the sink flushes the observations held by its gate
+		writeObservation(t, e, "gated")
+		assert.NotContains(readFile(t, path), `"gated"`)
+		require.NoError(e.DisableDebugSink())
+		assert.Contains(readFile(t, path), `"gated"`)
+
+		// once it's disabled, only the configured sinks receive observations
+		buf.Reset()
+		writeObservation(t, e, "disabled", WithFlush())
+		assert.NotContains(readFile(t, path), `"disabled"`)
+		assert.Contains(buf.String(), `"disabled"`)
+
+		// disabling it again is a no op, and it can be enabled again
+		require.NoError(e.DisableDebugSink())
+		require.NoError(e.EnableDebugSink(SinkConfig{
+			Name:     "debug",
+			SinkType: FileSink,
+			Format:   JSONSinkFormat,
+			Path:     dir,
+			FileName: "debug.log",
+		}))
+		writeObservation(t, e, "reenabled", WithFlush())
+		assert.Contains(readFile(t, path), `"reenabled"`)
+	})
+	t.Run("stderr", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		e, buf := NewTestEventer(t)
+		require.NoError(e.EnableDebugSink(SinkConfig{
+			Name:     "debug",
+			SinkType: StderrSink,
+			Format:   JSONSinkFormat,
+		}))
+		writeObservation(t, e, "stderr-id", WithFlush())
+
+		// the debug sink writes to the same stderr as the configured sink
+		got := buf.Events(t, ObservationType)
+		require.Len(got, 2)
+		for _, ev := range got {
+			assert.Equal("stderr-id", ev.Payload["id"])
+		}
+	})
+	t.Run("closed-with-eventer", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		e, _ := NewTestEventer(t)
+		dir := t.TempDir()
+		require.NoError(e.EnableDebugSink(SinkConfig{
+			Name:     "debug",
+			SinkType: FileSink,
+			Format:   JSONSinkFormat,
+			Path:     dir,
+			FileName: "debug.log",
+		}))
+		writeObservation(t, e, "gated")
+		require.NoError(e.Close(ctx))
+		assert.Contains(readFile(t, filepath.Join(dir, "debug.log")), `"gated"`)
+
+		err := e.EnableDebugSink(SinkConfig{Name: "debug", SinkType: StderrSink, Format: JSONSinkFormat})
+		assert.ErrorIs(err, ErrEventerClosed)
+		assert.ErrorIs(e.DisableDebugSink(), ErrEventerClosed)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		e, _ := NewTestEventer(t)
+		tests := []struct {
+			name            string
+			sc              SinkConfig
+			wantErrIs       error
+			wantErrContains string
+		}{
+			{
+				name:            "missing-name",
+				sc:              SinkConfig{SinkType: StderrSink, Format: JSONSinkFormat},
+				wantErrIs:       ErrInvalidParameter,
+				wantErrContains: "missing sink name",
+			},
+			{
+				name:            "audit-events",
+				sc:              SinkConfig{Name: "debug", SinkType: StderrSink, Format: JSONSinkFormat, EventTypes: []Type{AuditType}},
+				wantErrIs:       ErrInvalidParameter,
+				wantErrContains: "only receive observation events",
+			},
+			{
+				name:            "grpc-sink",
+				sc:              SinkConfig{Name: "debug", SinkType: GRPCSink, Format: JSONSinkFormat, GRPCConfig: &GRPCSinkConfig{Address: "collector:9400"}},
+				wantErrIs:       ErrInvalidParameter,
+				wantErrContains: "must be stderr or file sinks",
+			},
+			{
+				name:            "mirrors",
+				sc:              SinkConfig{Name: "debug", SinkType: StderrSink, Format: JSONSinkFormat, Mirrors: []SinkConfig{{Name: "mirror", SinkType: DiscardSink}}},
+				wantErrIs:       ErrInvalidParameter,
+				wantErrContains: "isn't supported by debug sinks",
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert, require := assert.New(t), require.New(t)
+				err := e.EnableDebugSink(tt.sc)
+				require.Error(err)
+				assert.ErrorIs(err, tt.wantErrIs)
+				assert.Contains(err.Error(), tt.wantErrContains)
+			})
+		}
+	})
+	t.Run("already-enabled", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		e, _ := NewTestEventer(t)
+		require.NoError(e.EnableDebugSink(SinkConfig{Name: "debug", SinkType: StderrSink, Format: JSONSinkFormat}))
+		err := e.EnableDebugSink(SinkConfig{Name: "other", SinkType: StderrSink, Format: JSONSinkFormat})
+		require.Error(err)
+		assert.ErrorIs(err, ErrInvalidParameter)
+		assert.Contains(err.Error(), `debug sink "debug" is already enabled`)
+	})
+	t.Run("configured-sink-file", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		dir := t.TempDir()
+		e, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, EventerConfig{
+			ObservationsEnabled: true,
+			Sinks: []SinkConfig{
+				{
+					Name:       "file",
+					SinkType:   FileSink,
+					EventTypes: []Type{EveryType},
+					Format:     JSONSinkFormat,
+					Path:       dir,
+					FileName:   "events.log",
+				},
+			},
+		})
+		require.NoError(err)
+		t.Cleanup(func() { _ = e.Close(ctx) })
+		err = e.EnableDebugSink(SinkConfig{Name: "debug", SinkType: FileSink, Format: JSONSinkFormat, Path: dir, FileName: "events.log"})
+		require.Error(err)
+		assert.ErrorIs(err, ErrDuplicateSink)
+	})
+}
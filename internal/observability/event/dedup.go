@@ -0,0 +1,92 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/eventlogger/filters/gated"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// dedupCacheSize is the max number of event ids remembered by a dedupNode.
+// When it's exceeded, the least recently seen ids are forgotten before their
+// window expires.
+const dedupCacheSize = 4096
+
+// dedupNode is a filter node which drops events with the same id (see IdField)
+// as an event it's already seen within its window.  Audit events are never
+// dropped, since every occurrence of an audit event matters.
+//
+// A dedupNode must not be shared between pipelines, otherwise an event would
+// only be delivered to the first pipeline's sink.
+type dedupNode struct {
+	window time.Duration
+	now    func() time.Time
+
+	l    sync.Mutex
+	seen *lru.Cache
+}
+
+var _ eventlogger.Node = &dedupNode{}
+
+func newDedupNode(window time.Duration) (*dedupNode, error) {
+	const op = "event.newDedupNode"
+	if window <= 0 {
+		return nil, fmt.Errorf("%s: dedup window must be greater than zero: %w", op, ErrInvalidParameter)
+	}
+	seen, err := lru.New(dedupCacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &dedupNode{
+		window: window,
+		now:    time.Now,
+		seen:   seen,
+	}, nil
+}
+
+// Process returns nil (filtering out the event) when an event with the same
+// id has been processed within the window.
+func (n *dedupNode) Process(_ context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	const op = "event.(dedupNode).Process"
+	if e == nil {
+		return nil, fmt.Errorf("%s: missing event: %w", op, ErrInvalidParameter)
+	}
+	var id string
+	switch p := e.Payload.(type) {
+	case *gated.EventPayload:
+		id = p.ID
+	case gated.EventPayload:
+		id = p.ID
+	case *sysEvent:
+		id = string(p.Id)
+	case *customEvent:
+		id = string(p.Id)
+	}
+	if id == "" {
+		// audit events, and any event without an id, are never deduped
+		return e, nil
+	}
+
+	n.l.Lock()
+	defer n.l.Unlock()
+	now := n.now()
+	if seenAt, ok := n.seen.Get(id); ok && now.Sub(seenAt.(time.Time)) < n.window {
+		return nil, nil
+	}
+	n.seen.Add(id, now)
+	return e, nil
+}
+
+// Reopen is a no op
+func (n *dedupNode) Reopen() error {
+	return nil
+}
+
+// Type describes the type of the node as a Filter.
+func (n *dedupNode) Type() eventlogger.NodeType {
+	return eventlogger.NodeTypeFilter
+}
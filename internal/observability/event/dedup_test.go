@@ -0,0 +1,155 @@
+package event
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/eventlogger/filters/gated"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newDedupNode(t *testing.T) {
+	t.Parallel()
+	_, err := newDedupNode(0)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+
+	n, err := newDedupNode(time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, n.window)
+}
+
+func Test_dedupNode_Process(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	now := time.Now()
+	newTestNode := func(t *testing.T) *dedupNode {
+		t.Helper()
+		n, err := newDedupNode(time.Minute)
+		require.NoError(t, err)
+		n.now = func() time.Time { return now }
+		return n
+	}
+	testEvent := func(payload interface{}) *eventlogger.Event {
+		return &eventlogger.Event{Type: "test", Payload: payload}
+	}
+
+	t.Run("missing-event", func(t *testing.T) {
+		_, err := newTestNode(t).Process(ctx, nil)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	})
+	t.Run("window-expiry", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		n := newTestNode(t)
+		got, err := n.Process(ctx, testEvent(gated.EventPayload{ID: "obs-id"}))
+		require.NoError(err)
+		assert.NotNil(got)
+		got, err = n.Process(ctx, testEvent(&gated.EventPayload{ID: "obs-id"}))
+		require.NoError(err)
+		assert.Nil(got)
+
+		got, err = n.Process(ctx, testEvent(&sysEvent{Id: "sys-id"}))
+		require.NoError(err)
+		assert.NotNil(got)
+		n.now = func() time.Time { return now.Add(59 * time.Second) }
+		got, err = n.Process(ctx, testEvent(&sysEvent{Id: "sys-id"}))
+		require.NoError(err)
+		assert.Nil(got)
+
+		// once the window has expired, the id is delivered again
+		n.now = func() time.Time { return now.Add(2 * time.Minute) }
+		got, err = n.Process(ctx, testEvent(&sysEvent{Id: "sys-id"}))
+		require.NoError(err)
+		assert.NotNil(got)
+	})
+	t.Run("audit-exemption", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		n := newTestNode(t)
+		for _, payload := range []interface{}{
+			&audit{Id: "audit-id"},
+			&audit{Id: "audit-id"},
+			audit{Id: "audit-id"},
+			audit{Id: "audit-id"},
+		} {
+			got, err := n.Process(ctx, testEvent(payload))
+			require.NoError(err)
+			assert.NotNil(got)
+		}
+	})
+}
+
+func TestEventer_WithDedupWindow(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tmpFile, err := ioutil.TempFile("./", "tmp-dedup-TestEventer_WithDedupWindow")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.Remove(tmpFile.Name()) })
+
+	c := EventerConfig{
+		AuditEnabled:        true,
+		ObservationsEnabled: true,
+		SysEventsEnabled:    true,
+		Sinks: []SinkConfig{
+			{
+				Name:       "every-type-file-sink",
+				SinkType:   FileSink,
+				EventTypes: []Type{EveryType},
+				Format:     JSONSinkFormat,
+				Path:       "./",
+				FileName:   tmpFile.Name(),
+			},
+			{
+				Name:       "stderr",
+				SinkType:   StderrSink,
+				EventTypes: []Type{EveryType},
+				Format:     JSONSinkFormat,
+			},
+		},
+	}
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	_, err = NewEventer(testLogger, testLock, c, WithDedupWindow(-time.Second))
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+
+	e, err := NewEventer(testLogger, testLock, c, WithDedupWindow(time.Minute))
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		testObservation, err := newObservation("TestEventer_WithDedupWindow", WithId("obs-id"), WithFlush())
+		require.NoError(t, err)
+		require.NoError(t, e.writeObservation(ctx, testObservation))
+
+		require.NoError(t, e.writeSysEvent(ctx, &sysEvent{Id: "sys-id", Op: "TestEventer_WithDedupWindow", Data: map[string]interface{}{"name": "data"}}))
+
+		testAudit, err := newAudit("TestEventer_WithDedupWindow", WithId("audit-id"), WithFlush())
+		require.NoError(t, err)
+		require.NoError(t, e.writeAudit(ctx, testAudit))
+	}
+
+	b, err := ioutil.ReadFile(tmpFile.Name())
+	require.NoError(t, err)
+	got := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		for _, id := range []string{"obs-id", "sys-id", "audit-id"} {
+			if strings.Contains(line, `"id":"`+id+`"`) {
+				got[id]++
+			}
+		}
+	}
+	// the file sink isn't affected by the stderr sink having seen the ids,
+	// and audit events are never deduped
+	assert.Equal(t, map[string]int{"obs-id": 1, "sys-id": 1, "audit-id": 2}, got)
+}
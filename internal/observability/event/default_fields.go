@@ -0,0 +1,115 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/eventlogger/filters/gated"
+)
+
+// HostnameField is the default field populated with the host's name when
+// WithDefaultFields is used without providing it.
+const HostnameField = "hostname"
+
+// defaultFieldsNode is a filter node which adds static fields (hostname, node
+// id, environment, etc) to the header of every event.  Fields already in an
+// event's header are never overwritten.
+type defaultFieldsNode struct {
+	fields map[string]string
+}
+
+var _ eventlogger.Node = &defaultFieldsNode{}
+
+// newDefaultFieldsNode returns a node for the fields, adding the host's name
+// when a HostnameField isn't provided.
+func newDefaultFieldsNode(fields map[string]string) (*defaultFieldsNode, error) {
+	const op = "event.newDefaultFieldsNode"
+	n := &defaultFieldsNode{
+		fields: make(map[string]string, len(fields)+1),
+	}
+	for k, v := range fields {
+		if k == "" {
+			return nil, fmt.Errorf("%s: missing default field name: %w", op, ErrInvalidParameter)
+		}
+		n.fields[k] = v
+	}
+	if _, ok := n.fields[HostnameField]; !ok {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("%s: unable to get hostname: %w", op, err)
+		}
+		n.fields[HostnameField] = hostname
+	}
+	return n, nil
+}
+
+// Process adds the default fields to the event's header.  Since events are
+// shared between pipelines, a new event is returned with a copy of the
+// payload rather than modifying the event's payload.
+func (n *defaultFieldsNode) Process(_ context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	const op = "event.(defaultFieldsNode).Process"
+	if e == nil {
+		return nil, fmt.Errorf("%s: missing event: %w", op, ErrInvalidParameter)
+	}
+	var payload interface{}
+	switch p := e.Payload.(type) {
+	case *audit:
+		cp := *p
+		cp.Header = n.merge(p.Header)
+		payload = &cp
+	case audit:
+		p.Header = n.merge(p.Header)
+		payload = p
+	case *err:
+		cp := *p
+		cp.Header = n.merge(p.Header)
+		payload = &cp
+	case *sysEvent:
+		cp := *p
+		cp.Header = n.merge(p.Header)
+		payload = &cp
+	case *customEvent:
+		cp := *p
+		cp.Header = n.merge(p.Header)
+		payload = &cp
+	case *gated.EventPayload:
+		cp := *p
+		cp.Header = n.merge(p.Header)
+		payload = &cp
+	case gated.EventPayload:
+		p.Header = n.merge(p.Header)
+		payload = p
+	default:
+		return nil, fmt.Errorf("%s: unable to add default fields to %T payload: %w", op, e.Payload, ErrInvalidParameter)
+	}
+	return &eventlogger.Event{
+		Type:      e.Type,
+		CreatedAt: e.CreatedAt,
+		Payload:   payload,
+	}, nil
+}
+
+// merge returns a new header with the default fields added to the header's
+// existing fields.
+func (n *defaultFieldsNode) merge(header map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(header)+len(n.fields))
+	for k, v := range n.fields {
+		merged[k] = v
+	}
+	for k, v := range header {
+		merged[k] = v
+	}
+	return merged
+}
+
+// Reopen is a no op
+func (n *defaultFieldsNode) Reopen() error {
+	return nil
+}
+
+// Type describes the type of the node as a Filter.
+func (n *defaultFieldsNode) Type() eventlogger.NodeType {
+	return eventlogger.NodeTypeFilter
+}
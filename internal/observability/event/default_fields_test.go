@@ -0,0 +1,180 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/eventlogger/filters/gated"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newDefaultFieldsNode(t *testing.T) {
+	t.Parallel()
+	t.Run("missing-field-name", func(t *testing.T) {
+		_, err := newDefaultFieldsNode(map[string]string{"": "value"})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	})
+	t.Run("hostname", func(t *testing.T) {
+		hostname, err := os.Hostname()
+		require.NoError(t, err)
+		n, err := newDefaultFieldsNode(nil)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{HostnameField: hostname}, n.fields)
+	})
+	t.Run("explicit-hostname", func(t *testing.T) {
+		n, err := newDefaultFieldsNode(map[string]string{HostnameField: "controller-1"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{HostnameField: "controller-1"}, n.fields)
+	})
+}
+
+func Test_defaultFieldsNode_Process(t *testing.T) {
+	t.Parallel()
+	n, nodeErr := newDefaultFieldsNode(map[string]string{HostnameField: "controller-1", "environment": "test"})
+	require.NoError(t, nodeErr)
+	tests := []struct {
+		name       string
+		payload    interface{}
+		wantHeader func(interface{}) map[string]interface{}
+		wantErrIs  error
+	}{
+		{
+			name:       "audit",
+			payload:    &audit{Id: "audit-id"},
+			wantHeader: func(p interface{}) map[string]interface{} { return p.(*audit).Header },
+		},
+		{
+			name:       "composed-audit",
+			payload:    audit{Id: "audit-id"},
+			wantHeader: func(p interface{}) map[string]interface{} { return p.(audit).Header },
+		},
+		{
+			name:       "error",
+			payload:    &err{Op: "test"},
+			wantHeader: func(p interface{}) map[string]interface{} { return p.(*err).Header },
+		},
+		{
+			name:       "system",
+			payload:    &sysEvent{Op: "test", Header: map[string]interface{}{"environment": "per-event"}},
+			wantHeader: func(p interface{}) map[string]interface{} { return p.(*sysEvent).Header },
+		},
+		{
+			name:       "observation",
+			payload:    gated.EventPayload{ID: "obs-id", Header: map[string]interface{}{RequestInfoField: &RequestInfo{Id: "req-id"}}},
+			wantHeader: func(p interface{}) map[string]interface{} { return p.(gated.EventPayload).Header },
+		},
+		{
+			name:      "unknown-payload",
+			payload:   map[string]interface{}{"name": "value"},
+			wantErrIs: ErrInvalidParameter,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			e := &eventlogger.Event{Type: "test", Payload: tt.payload}
+			got, gotErr := n.Process(context.Background(), e)
+			if tt.wantErrIs != nil {
+				require.Error(gotErr)
+				assert.ErrorIs(gotErr, tt.wantErrIs)
+				return
+			}
+			require.NoError(gotErr)
+			// the original event is shared by other pipelines and mustn't
+			// be modified
+			assert.Equal(tt.payload, e.Payload)
+			assert.NotSame(e, got)
+
+			header := tt.wantHeader(got.Payload)
+			assert.Equal("controller-1", header[HostnameField])
+			switch tt.name {
+			case "system":
+				assert.Equal("per-event", header["environment"])
+			case "observation":
+				assert.Equal("test", header["environment"])
+				assert.Equal(&RequestInfo{Id: "req-id"}, header[RequestInfoField])
+			default:
+				assert.Equal("test", header["environment"])
+			}
+		})
+	}
+}
+
+func TestEventer_WithDefaultFields(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tmpFile, err := ioutil.TempFile("./", "tmp-default-fields-TestEventer_WithDefaultFields")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.Remove(tmpFile.Name()) })
+
+	c := EventerConfig{
+		AuditEnabled:        true,
+		ObservationsEnabled: true,
+		SysEventsEnabled:    true,
+		Sinks: []SinkConfig{
+			{
+				Name:       "every-type-file-sink",
+				SinkType:   FileSink,
+				EventTypes: []Type{EveryType},
+				Format:     JSONSinkFormat,
+				Path:       "./",
+				FileName:   tmpFile.Name(),
+			},
+		},
+	}
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	e, err := NewEventer(testLogger, testLock, c, WithDefaultFields(map[string]string{"environment": "test", "node_id": "controller-1"}))
+	require.NoError(t, err)
+
+	testObservation, err := newObservation("TestEventer_WithDefaultFields", WithHeader(map[string]interface{}{"name": "header"}), WithRequestInfo(&RequestInfo{Id: "req-id"}), WithFlush())
+	require.NoError(t, err)
+	require.NoError(t, e.writeObservation(ctx, testObservation))
+
+	testAudit, err := newAudit("TestEventer_WithDefaultFields", WithFlush())
+	require.NoError(t, err)
+	require.NoError(t, e.writeAudit(ctx, testAudit))
+
+	testError, err := newError("TestEventer_WithDefaultFields", fmt.Errorf("%s: no msg: test", ErrIo))
+	require.NoError(t, err)
+	require.NoError(t, e.writeError(ctx, testError))
+
+	id, err := newId(string(SystemType))
+	require.NoError(t, err)
+	require.NoError(t, e.writeSysEvent(ctx, &sysEvent{Id: Id(id), Op: "TestEventer_WithDefaultFields", Data: map[string]interface{}{"name": "data"}}))
+
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+	b, err := ioutil.ReadFile(tmpFile.Name())
+	require.NoError(t, err)
+	gotTypes := map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		var got map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &got))
+		eventType := got["event_type"].(string)
+		payload := got["payload"].(map[string]interface{})
+		header := payload[HeaderField].(map[string]interface{})
+		assert.Equalf(t, "test", header["environment"], "%s event: %s", eventType, line)
+		assert.Equalf(t, "controller-1", header["node_id"], "%s event: %s", eventType, line)
+		assert.Equalf(t, hostname, header[HostnameField], "%s event: %s", eventType, line)
+		if eventType == string(ObservationType) {
+			assert.Equal(t, map[string]interface{}{"id": "req-id"}, header[RequestInfoField])
+			assert.Equal(t, "header", header["name"])
+		}
+		gotTypes[eventType] = true
+	}
+	assert.Len(t, gotTypes, 4)
+}
@@ -0,0 +1,61 @@
+package event
+
+import (
+	"os"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	// EnvEventFormat defines an envvar which overrides the default sink's
+	// format (json).
+	EnvEventFormat = "BOUNDARY_EVENT_FORMAT"
+
+	// EnvEventSink defines an envvar which overrides the default sink's type
+	// (stderr, file or discard).
+	EnvEventSink = "BOUNDARY_EVENT_SINK"
+
+	// EnvEventFilePath and EnvEventFileName define envvars for the path and
+	// file name of the default sink when its type is file.  A file name is
+	// required for a file sink.
+	EnvEventFilePath = "BOUNDARY_EVENT_FILE_PATH"
+	EnvEventFileName = "BOUNDARY_EVENT_FILE_NAME"
+)
+
+// DefaultSinkFromEnv returns the DefaultSink with any overrides from the
+// environment applied.  Unknown or incomplete values are ignored with a
+// logged warning, falling back to the DefaultSink's values.
+func DefaultSinkFromEnv() SinkConfig {
+	return defaultSinkFromEnv(hclog.Default(), os.Getenv)
+}
+
+func defaultSinkFromEnv(logger hclog.Logger, getenv func(string) string) SinkConfig {
+	sc := DefaultSink()
+
+	if f := strings.ToLower(strings.TrimSpace(getenv(EnvEventFormat))); f != "" {
+		if err := SinkFormat(f).Validate(); err != nil {
+			logger.Warn("ignoring invalid default event sink format", "envvar", EnvEventFormat, "value", f, "fallback", sc.Format)
+		} else {
+			sc.Format = SinkFormat(f)
+		}
+	}
+
+	switch t := SinkType(strings.ToLower(strings.TrimSpace(getenv(EnvEventSink)))); t {
+	case "", StderrSink:
+	case FileSink:
+		fileName := strings.TrimSpace(getenv(EnvEventFileName))
+		if fileName == "" {
+			logger.Warn("ignoring default event sink type without a file name", "envvar", EnvEventSink, "value", t, "missing", EnvEventFileName, "fallback", sc.SinkType)
+			break
+		}
+		sc.SinkType = FileSink
+		sc.Path = strings.TrimSpace(getenv(EnvEventFilePath))
+		sc.FileName = fileName
+	case DiscardSink:
+		sc.SinkType = DiscardSink
+	default:
+		logger.Warn("ignoring invalid default event sink type", "envvar", EnvEventSink, "value", t, "fallback", sc.SinkType)
+	}
+	return sc
+}
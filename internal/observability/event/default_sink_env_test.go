@@ -0,0 +1,103 @@
+package event
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_defaultSinkFromEnv(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		env         map[string]string
+		want        SinkConfig
+		wantWarning string
+	}{
+		{
+			name: "no-env",
+			want: DefaultSink(),
+		},
+		{
+			name: "stderr-json",
+			env:  map[string]string{EnvEventSink: "stderr", EnvEventFormat: "json"},
+			want: DefaultSink(),
+		},
+		{
+			name: "mixed-case",
+			env:  map[string]string{EnvEventSink: " STDERR ", EnvEventFormat: "JSON"},
+			want: DefaultSink(),
+		},
+		{
+			name: "file",
+			env:  map[string]string{EnvEventSink: "file", EnvEventFilePath: "/var/log/boundary", EnvEventFileName: "events.log"},
+			want: func() SinkConfig {
+				sc := DefaultSink()
+				sc.SinkType = FileSink
+				sc.Path = "/var/log/boundary"
+				sc.FileName = "events.log"
+				return sc
+			}(),
+		},
+		{
+			name:        "file-missing-file-name",
+			env:         map[string]string{EnvEventSink: "file", EnvEventFilePath: "/var/log/boundary"},
+			want:        DefaultSink(),
+			wantWarning: "without a file name",
+		},
+		{
+			name: "discard",
+			env:  map[string]string{EnvEventSink: "discard"},
+			want: func() SinkConfig {
+				sc := DefaultSink()
+				sc.SinkType = DiscardSink
+				return sc
+			}(),
+		},
+		{
+			name:        "invalid-sink",
+			env:         map[string]string{EnvEventSink: "syslog"},
+			want:        DefaultSink(),
+			wantWarning: "invalid default event sink type",
+		},
+		{
+			name:        "kafka-sink-unsupported",
+			env:         map[string]string{EnvEventSink: "kafka"},
+			want:        DefaultSink(),
+			wantWarning: "invalid default event sink type",
+		},
+		{
+			name:        "invalid-format",
+			env:         map[string]string{EnvEventFormat: "xml"},
+			want:        DefaultSink(),
+			wantWarning: "invalid default event sink format",
+		},
+		{
+			name: "invalid-format-valid-sink",
+			env:  map[string]string{EnvEventFormat: "xml", EnvEventSink: "file", EnvEventFileName: "events.log"},
+			want: func() SinkConfig {
+				sc := DefaultSink()
+				sc.SinkType = FileSink
+				sc.FileName = "events.log"
+				return sc
+			}(),
+			wantWarning: "invalid default event sink format",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			var buf bytes.Buffer
+			logger := hclog.New(&hclog.LoggerOptions{Output: &buf})
+			got := defaultSinkFromEnv(logger, func(k string) string { return tt.env[k] })
+			assert.Equal(tt.want, got)
+			if tt.wantWarning != "" {
+				assert.Contains(buf.String(), tt.wantWarning)
+				return
+			}
+			assert.Empty(buf.String())
+		})
+	}
+}
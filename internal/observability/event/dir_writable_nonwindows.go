@@ -0,0 +1,11 @@
+// +build !windows
+
+package event
+
+import "golang.org/x/sys/unix"
+
+// dirWritable reports an error if the current user can't create files in the
+// dir.  It doesn't modify the filesystem.
+func dirWritable(dir string) error {
+	return unix.Access(dir, unix.W_OK|unix.X_OK)
+}
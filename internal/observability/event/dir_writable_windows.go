@@ -0,0 +1,21 @@
+// +build windows
+
+package event
+
+import (
+	"errors"
+	"os"
+)
+
+// dirWritable reports an error if the dir is read-only.  It doesn't modify the
+// filesystem.
+func dirWritable(dir string) error {
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if fi.Mode().Perm()&0o200 == 0 {
+		return errors.New("read-only directory")
+	}
+	return nil
+}
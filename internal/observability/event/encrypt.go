@@ -0,0 +1,141 @@
+package event
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hashicorp/eventlogger"
+	wrapping "github.com/hashicorp/go-kms-wrapping"
+)
+
+// encryptedLineSeparator separates the key id, nonce and ciphertext of each
+// encrypted event line.
+const encryptedLineSeparator = ":"
+
+// encryptNode is a formatter node which encrypts an event's formatted data
+// using a wrapper.  Each encrypted event is written as a single line of
+// "<key id>:<base64 nonce>:<base64 ciphertext>", so rotated keys can be
+// identified when the line is decrypted.
+type encryptNode struct {
+	wrapper wrapping.Wrapper
+	format  string
+}
+
+var _ eventlogger.Node = &encryptNode{}
+
+func newEncryptNode(w wrapping.Wrapper, format string) (*encryptNode, error) {
+	const op = "event.newEncryptNode"
+	if w == nil {
+		return nil, fmt.Errorf("%s: missing wrapper: %w", op, ErrInvalidParameter)
+	}
+	if format == "" {
+		return nil, fmt.Errorf("%s: missing format: %w", op, ErrInvalidParameter)
+	}
+	return &encryptNode{
+		wrapper: w,
+		format:  format,
+	}, nil
+}
+
+// Process encrypts the event's formatted data.  Since events are shared
+// between pipelines, a new event is returned with the encrypted data rather
+// than modifying the event's existing formatted data.
+func (n *encryptNode) Process(ctx context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	const op = "event.(encryptNode).Process"
+	if e == nil {
+		return nil, fmt.Errorf("%s: missing event: %w", op, ErrInvalidParameter)
+	}
+	plaintext, ok := e.Format(n.format)
+	if !ok {
+		return nil, fmt.Errorf("%s: unable to find %s format: %w", op, n.format, ErrInvalidParameter)
+	}
+	blob, err := n.wrapper.Encrypt(ctx, bytes.TrimSuffix(plaintext, []byte("\n")), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: unable to encrypt event: %w", op, err)
+	}
+	keyId := n.wrapper.KeyID()
+	if blob.KeyInfo != nil && blob.KeyInfo.KeyID != "" {
+		keyId = blob.KeyInfo.KeyID
+	}
+	line := strings.Join([]string{
+		keyId,
+		base64.StdEncoding.EncodeToString(blob.IV),
+		base64.StdEncoding.EncodeToString(blob.Ciphertext),
+	}, encryptedLineSeparator) + "\n"
+
+	encrypted := &eventlogger.Event{
+		Type:      e.Type,
+		CreatedAt: e.CreatedAt,
+		Payload:   e.Payload,
+	}
+	encrypted.FormattedAs(n.format, []byte(line))
+	return encrypted, nil
+}
+
+// Reopen is a no op for encryptNodes.
+func (n *encryptNode) Reopen() error { return nil }
+
+// Type describes the type of the node as a Formatter.
+func (n *encryptNode) Type() eventlogger.NodeType {
+	return eventlogger.NodeTypeFormatter
+}
+
+// DecryptAuditFile reads the encrypted events from r, decrypts them with the
+// wrapper and writes them to w as plaintext, one event per line.  Use a
+// multiwrapper to decrypt a file containing events encrypted with rotated
+// keys.
+func DecryptAuditFile(ctx context.Context, wrapper wrapping.Wrapper, r io.Reader, w io.Writer) error {
+	const op = "event.DecryptAuditFile"
+	if wrapper == nil {
+		return fmt.Errorf("%s: missing wrapper: %w", op, ErrInvalidParameter)
+	}
+	if r == nil {
+		return fmt.Errorf("%s: missing reader: %w", op, ErrInvalidParameter)
+	}
+	if w == nil {
+		return fmt.Errorf("%s: missing writer: %w", op, ErrInvalidParameter)
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), 64*1024*1024)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		parts := strings.Split(line, encryptedLineSeparator)
+		if len(parts) < 3 {
+			return fmt.Errorf("%s: line %d is not an encrypted event: %w", op, lineNum, ErrInvalidParameter)
+		}
+		// the key id may contain the separator, so the nonce and ciphertext
+		// are always the last two parts.
+		keyId := strings.Join(parts[:len(parts)-2], encryptedLineSeparator)
+		iv, err := base64.StdEncoding.DecodeString(parts[len(parts)-2])
+		if err != nil {
+			return fmt.Errorf("%s: line %d has an invalid nonce: %w", op, lineNum, err)
+		}
+		ciphertext, err := base64.StdEncoding.DecodeString(parts[len(parts)-1])
+		if err != nil {
+			return fmt.Errorf("%s: line %d has invalid ciphertext: %w", op, lineNum, err)
+		}
+		plaintext, err := wrapper.Decrypt(ctx, &wrapping.EncryptedBlobInfo{
+			Ciphertext: ciphertext,
+			IV:         iv,
+			KeyInfo:    &wrapping.KeyInfo{KeyID: keyId},
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("%s: unable to decrypt line %d: %w", op, lineNum, err)
+		}
+		if _, err := w.Write(append(plaintext, '\n')); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-kms-wrapping/wrappers/aead"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testWrapper(t *testing.T, keyId string) *aead.Wrapper {
+	t.Helper()
+	require := require.New(t)
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(err)
+	w := aead.NewWrapper(nil)
+	_, err = w.SetConfig(map[string]string{"key_id": keyId})
+	require.NoError(err)
+	require.NoError(w.SetAESGCMKeyBytes(key))
+	return w
+}
+
+func Test_encryptNode_Process(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	w := testWrapper(t, "test-key")
+
+	t.Run("missing-wrapper", func(t *testing.T) {
+		_, err := newEncryptNode(nil, string(JSONSinkFormat))
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	})
+	t.Run("missing-format", func(t *testing.T) {
+		n, err := newEncryptNode(w, string(JSONSinkFormat))
+		require.NoError(t, err)
+		_, err = n.Process(ctx, &eventlogger.Event{})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	})
+	t.Run("success", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		n, err := newEncryptNode(w, string(JSONSinkFormat))
+		require.NoError(err)
+		e := &eventlogger.Event{Type: eventlogger.EventType(AuditType)}
+		e.FormattedAs(string(JSONSinkFormat), []byte(`{"test":"event"}`+"\n"))
+
+		got, err := n.Process(ctx, e)
+		require.NoError(err)
+		require.NotSame(e, got)
+
+		// the original event must not be modified since it's shared by other
+		// pipelines
+		orig, _ := e.Format(string(JSONSinkFormat))
+		assert.Equal(`{"test":"event"}`+"\n", string(orig))
+
+		line, ok := got.Format(string(JSONSinkFormat))
+		require.True(ok)
+		assert.True(strings.HasPrefix(string(line), "test-key:"))
+		assert.Equal(1, strings.Count(string(line), "\n"))
+
+		var out bytes.Buffer
+		require.NoError(DecryptAuditFile(ctx, w, bytes.NewReader(line), &out))
+		assert.Equal(`{"test":"event"}`+"\n", out.String())
+	})
+}
+
+func TestDecryptAuditFile(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	w := testWrapper(t, "test-key")
+
+	tests := []struct {
+		name            string
+		input           string
+		wantErrIs       error
+		wantErrContains string
+	}{
+		{
+			name:            "not-encrypted",
+			input:           `{"test":"event"}`,
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "line 1 is not an encrypted event",
+		},
+		{
+			name:            "invalid-ciphertext",
+			input:           "test-key::not-base64!",
+			wantErrContains: "line 1 has invalid ciphertext",
+		},
+		{
+			name:            "wrong-key",
+			input:           "test-key::" + "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA",
+			wantErrContains: "unable to decrypt line 1",
+		},
+		{
+			name:  "empty",
+			input: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			var out bytes.Buffer
+			err := DecryptAuditFile(ctx, w, strings.NewReader(tt.input), &out)
+			if tt.wantErrContains != "" {
+				require.Error(err)
+				if tt.wantErrIs != nil {
+					assert.ErrorIs(err, tt.wantErrIs)
+				}
+				assert.Contains(err.Error(), tt.wantErrContains)
+				return
+			}
+			require.NoError(err)
+			assert.Empty(out.String())
+		})
+	}
+}
+
+func TestEventer_encryptedAudit(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	require, assert := require.New(t), assert.New(t)
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	w := testWrapper(t, "test-key")
+
+	tmpFile, err := ioutil.TempFile("./", "tmp-encrypted-audit")
+	require.NoError(err)
+	t.Cleanup(func() { os.Remove(tmpFile.Name()) })
+
+	c := EventerConfig{
+		AuditEnabled: true,
+		Sinks: []SinkConfig{
+			{
+				Name:       "audit-file-sink",
+				SinkType:   FileSink,
+				EventTypes: []Type{AuditType, ErrorType},
+				Format:     JSONSinkFormat,
+				Path:       "./",
+				FileName:   tmpFile.Name(),
+			},
+		},
+	}
+
+	_, err = NewEventer(testLogger, testLock, c, WithEncryptedObservations())
+	require.Error(err)
+	assert.ErrorIs(err, ErrInvalidParameter)
+
+	e, err := NewEventer(testLogger, testLock, c, WithWrapper(w))
+	require.NoError(err)
+
+	a, err := newAudit("TestEventer_encryptedAudit", WithId("test-audit-id"), WithFlush())
+	require.NoError(err)
+	require.NoError(e.writeAudit(ctx, a))
+
+	encrypted, err := ioutil.ReadFile(tmpFile.Name())
+	require.NoError(err)
+	assert.NotContains(string(encrypted), "test-audit-id")
+	assert.True(strings.HasPrefix(string(encrypted), "test-key:"))
+
+	var out bytes.Buffer
+	require.NoError(DecryptAuditFile(ctx, w, bytes.NewReader(encrypted), &out))
+	got := map[string]interface{}{}
+	require.NoError(json.Unmarshal(out.Bytes(), &got))
+	assert.Equal(string(AuditType), got["event_type"])
+	assert.Equal("test-audit-id", got["payload"].(map[string]interface{})["id"])
+
+	// errors written to the same sink aren't encrypted
+	require.NoError(os.Truncate(tmpFile.Name(), 0))
+	errEvent, err := newError("TestEventer_encryptedAudit", ErrInvalidParameter, WithId("test-error-id"))
+	require.NoError(err)
+	require.NoError(e.writeError(ctx, errEvent))
+	plaintext, err := ioutil.ReadFile(tmpFile.Name())
+	require.NoError(err)
+	assert.Contains(string(plaintext), "test-error-id")
+}
@@ -0,0 +1,120 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// errorCoalescer tracks the latest error event sent, while identical
+// consecutive errors are coalesced as its repeats (see WithErrorCoalescing).
+type errorCoalescer struct {
+	l sync.Mutex
+
+	// sent is the first error of the current run, which was sent when it was
+	// written.
+	sent *err
+
+	// repeat is the latest of the run's held repeats, and count, first and
+	// last are their number and the times the first and last of them were
+	// written.
+	repeat *err
+	count  int
+	first  time.Time
+	last   time.Time
+
+	timer *time.Timer
+}
+
+// sameError returns true when the errors have the same op and message.
+func sameError(a, b *err) bool {
+	return a.Op == b.Op && a.Error.Error() == b.Error.Error()
+}
+
+// coalesceError sends the error event, unless it's identical to the first
+// error of the current run, in which case it's held as one of the run's
+// repeats and nil is returned.  A different error ends the run, sending its
+// repeats, and starts a new one, which ends when the coalescing interval has
+// elapsed.  Only the error of sending the event itself is returned, since
+// error delivery is enforced; a failure to send the repeats is written to the
+// eventer's logger.  The caller must hold the eventer's closeLock.
+func (e *Eventer) coalesceError(ctx context.Context, event *err) error {
+	const op = "event.(Eventer).coalesceError"
+	c := &e.errorCoalescer
+	c.l.Lock()
+	defer c.l.Unlock()
+	if c.sent != nil && sameError(c.sent, event) {
+		now := time.Now()
+		if c.count == 0 {
+			c.first = now
+		}
+		c.repeat, c.count, c.last = event, c.count+1, now
+		return nil
+	}
+	_ = e.sendErrorRepeats(ctx)
+	c.sent = event
+	c.timer = time.AfterFunc(e.errorCoalesceInterval, func() {
+		e.flushCoalescedErrorAfterInterval(event)
+	})
+	if err := e.sendError(ctx, event); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// flushCoalescedErrorAfterInterval ends the run started by the event, sending
+// its repeats, when it's still the current run once the coalescing interval
+// has elapsed.
+func (e *Eventer) flushCoalescedErrorAfterInterval(event *err) {
+	e.closeLock.RLock()
+	defer e.closeLock.RUnlock()
+	if e.closed {
+		// the run's repeats were sent when the eventer was closed
+		return
+	}
+	c := &e.errorCoalescer
+	c.l.Lock()
+	defer c.l.Unlock()
+	if c.sent != event {
+		return
+	}
+	_ = e.sendErrorRepeats(context.Background())
+}
+
+// flushCoalescedError ends the current run, sending its repeats, if there are
+// any.  The caller must hold the eventer's closeLock.
+func (e *Eventer) flushCoalescedError(ctx context.Context) error {
+	const op = "event.(Eventer).flushCoalescedError"
+	c := &e.errorCoalescer
+	c.l.Lock()
+	defer c.l.Unlock()
+	if err := e.sendErrorRepeats(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// sendErrorRepeats ends the current run and sends its held repeats, if there
+// are any, as the latest of them with their repeat count and the times the
+// first and last of them were written.  When they can't be sent, they're
+// written to the eventer's logger, since the writers of the repeats have
+// already returned.  The caller must hold the coalescer's lock.
+func (e *Eventer) sendErrorRepeats(ctx context.Context) error {
+	const op = "event.(Eventer).sendErrorRepeats"
+	c := &e.errorCoalescer
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	event, count, first, last := c.repeat, c.count, c.first, c.last
+	c.sent, c.repeat, c.count, c.timer = nil, nil, 0, nil
+	if count == 0 {
+		return nil
+	}
+	event.RepeatCount, event.FirstSeen, event.LastSeen = count, &first, &last
+	if err := e.sendError(ctx, event); err != nil {
+		e.logger.Error("unable to send coalesced error event", "op", event.Op, "id", event.Id, "correlation_id", event.CorrelationId, "repeat_count", event.RepeatCount, "error", event.Error, "send_error", err.Error())
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
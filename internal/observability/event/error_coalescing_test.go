@@ -0,0 +1,172 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventer_WithErrorCoalescing(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testSetup := TestEventerConfig(t, "TestEventer_WithErrorCoalescing")
+	newEventer := func(t *testing.T, b *testMockBroker, interval time.Duration) *Eventer {
+		t.Helper()
+		e, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, testSetup.EventerConfig, TestWithBroker(t, b), WithErrorCoalescing(interval))
+		require.NoError(t, err)
+		return e
+	}
+	writeError := func(t *testing.T, e *Eventer, op Op, msg string) {
+		t.Helper()
+		testError, err := newError(op, fmt.Errorf("%s: %w", msg, ErrIo))
+		require.NoError(t, err)
+		require.NoError(t, e.writeError(ctx, testError))
+	}
+	// sentErrors returns the error events sent, holding the eventer's
+	// closeLock so it doesn't race with a coalescing interval elapsing.
+	sentErrors := func(e *Eventer, b *testMockBroker) []interface{} {
+		e.closeLock.Lock()
+		defer e.closeLock.Unlock()
+		return append([]interface{}(nil), b.sent[eventlogger.EventType(ErrorType)]...)
+	}
+
+	t.Run("different-error-flushes", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		b := &testMockBroker{}
+		e := newEventer(t, b, time.Hour)
+
+		// the first error is sent right away, and its repeats are held
+		for i := 0; i < 100; i++ {
+			writeError(t, e, "TestEventer_WithErrorCoalescing", "connection refused")
+		}
+		sent := sentErrors(e, b)
+		require.Len(sent, 1)
+		got, ok := sent[0].(*err)
+		require.True(ok)
+		assert.Zero(got.RepeatCount)
+		assert.Nil(got.FirstSeen)
+
+		// a different error sends the repeats, and then itself
+		writeError(t, e, "TestEventer_WithErrorCoalescing", "a different error")
+		sent = sentErrors(e, b)
+		require.Len(sent, 3)
+		got, ok = sent[1].(*err)
+		require.True(ok)
+		assert.Equal(99, got.RepeatCount)
+		require.NotNil(got.FirstSeen)
+		require.NotNil(got.LastSeen)
+		assert.False(got.LastSeen.Before(*got.FirstSeen))
+		assert.Contains(got.Error.Error(), "connection refused")
+		got, ok = sent[2].(*err)
+		require.True(ok)
+		assert.Contains(got.Error.Error(), "a different error")
+		assert.Zero(got.RepeatCount)
+
+		// the different error wasn't repeated, so nothing is sent when the
+		// eventer is closed
+		require.NoError(e.Close(ctx))
+		assert.Len(b.sent[eventlogger.EventType(ErrorType)], 3)
+	})
+	t.Run("different-op-flushes", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		b := &testMockBroker{}
+		e := newEventer(t, b, time.Hour)
+
+		writeError(t, e, "op-1", "connection refused")
+		writeError(t, e, "op-2", "connection refused")
+		writeError(t, e, "op-1", "connection refused")
+		writeError(t, e, "op-1", "connection refused")
+		assert.Len(sentErrors(e, b), 3)
+		require.NoError(e.Close(ctx))
+		sent := b.sent[eventlogger.EventType(ErrorType)]
+		require.Len(sent, 4)
+		got, ok := sent[3].(*err)
+		require.True(ok)
+		assert.Equal(1, got.RepeatCount)
+	})
+	t.Run("interval-flushes", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		b := &testMockBroker{}
+		e := newEventer(t, b, 10*time.Millisecond)
+
+		for i := 0; i < 3; i++ {
+			writeError(t, e, "TestEventer_WithErrorCoalescing", "connection refused")
+		}
+		require.Eventually(func() bool { return len(sentErrors(e, b)) == 2 }, 5*time.Second, 10*time.Millisecond)
+		got, ok := sentErrors(e, b)[1].(*err)
+		require.True(ok)
+		assert.Equal(2, got.RepeatCount)
+
+		// once the interval has elapsed, the error is sent right away again
+		writeError(t, e, "TestEventer_WithErrorCoalescing", "connection refused")
+		assert.Len(sentErrors(e, b), 3)
+		require.NoError(e.Close(ctx))
+		assert.Len(b.sent[eventlogger.EventType(ErrorType)], 3)
+	})
+	t.Run("send-failure-returned", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		b := &testMockBroker{errorOnSend: fmt.Errorf("%s: no msg: %w", "test", ErrIo)}
+		e, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, testSetup.EventerConfig, TestWithBroker(t, b), WithErrorCoalescing(time.Hour), WithNoBackoffJitter())
+		require.NoError(err)
+
+		// the first error's failure is returned to its own writer
+		testError, err := newError("TestEventer_WithErrorCoalescing", fmt.Errorf("connection refused: %w", ErrIo))
+		require.NoError(err)
+		err = e.writeError(ctx, testError)
+		require.Error(err)
+		assert.ErrorIs(err, ErrIo)
+	})
+	t.Run("repeats-failure-logged", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		var logs bytes.Buffer
+		logger := hclog.New(&hclog.LoggerOptions{Output: &logs})
+		b := &testMockBroker{}
+		e, newErr := NewEventer(logger, &sync.Mutex{}, testSetup.EventerConfig, TestWithBroker(t, b), WithErrorCoalescing(10*time.Millisecond), WithNoBackoffJitter())
+		require.NoError(newErr)
+
+		writeError(t, e, "TestEventer_WithErrorCoalescing", "connection refused")
+		writeError(t, e, "TestEventer_WithErrorCoalescing", "connection refused")
+		e.closeLock.Lock()
+		b.errorOnSend = fmt.Errorf("%s: no msg: %w", "test", ErrIo)
+		e.closeLock.Unlock()
+
+		// the repeats can't be sent once the interval has elapsed, so
+		// they're logged rather than returned to the next writer
+		require.Eventually(func() bool {
+			e.closeLock.Lock()
+			defer e.closeLock.Unlock()
+			return strings.Contains(logs.String(), "unable to send coalesced error event")
+		}, 5*time.Second, 10*time.Millisecond)
+		e.closeLock.Lock()
+		b.errorOnSend = nil
+		e.closeLock.Unlock()
+		writeError(t, e, "TestEventer_WithErrorCoalescing", "a different error")
+		sent := sentErrors(e, b)
+		got, ok := sent[len(sent)-1].(*err)
+		require.True(ok)
+		assert.Contains(got.Error.Error(), "a different error")
+		require.NoError(e.Close(ctx))
+	})
+	t.Run("disabled", func(t *testing.T) {
+		b := &testMockBroker{}
+		e := newEventer(t, b, 0)
+		for i := 0; i < 3; i++ {
+			writeError(t, e, "TestEventer_WithErrorCoalescing", "connection refused")
+		}
+		assert.Len(t, b.sent[eventlogger.EventType(ErrorType)], 3)
+	})
+	t.Run("negative-interval", func(t *testing.T) {
+		_, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, testSetup.EventerConfig, TestWithBroker(t, &testMockBroker{}), WithErrorCoalescing(-time.Second))
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	})
+}
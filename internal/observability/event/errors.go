@@ -2,11 +2,54 @@ package event
 
 import (
 	"errors"
+	"fmt"
 )
 
 var (
-	ErrInvalidParameter = errors.New("invalid parameter")
-	ErrMaxRetries       = errors.New("too many retries")
-	ErrIo               = errors.New("error during io operation")
-	ErrRecordNotFound   = errors.New("record not found")
+	ErrInvalidParameter  = errors.New("invalid parameter")
+	ErrMaxRetries        = errors.New("too many retries")
+	ErrIo                = errors.New("error during io operation")
+	ErrRecordNotFound    = errors.New("record not found")
+	ErrEventTooLarge     = errors.New("event too large")
+	ErrEventerClosed     = errors.New("eventer closed")
+	ErrCircuitOpen       = errors.New("sink circuit breaker open")
+	ErrSendTimeout       = errors.New("event send timed out")
+	ErrNotAcknowledged   = errors.New("event not acknowledged as persisted")
+	ErrAuditNotDelivered = errors.New("audit event not delivered")
+	ErrAuditDegraded     = errors.New("audit delivery degraded")
+
+	// The following identify why an eventer or its config is invalid.  They
+	// all wrap ErrInvalidParameter, so errors.Is(err, ErrInvalidParameter)
+	// remains true for them.
+	ErrMissingLogger    = &invalidParameterError{reason: "missing logger"}
+	ErrDuplicateSink    = &invalidParameterError{reason: "duplicate sink"}
+	ErrNoSinkForType    = &invalidParameterError{reason: "no sink for event type"}
+	ErrUnwritablePath   = &invalidParameterError{reason: "unwritable path"}
+	ErrNoLocalAuditSink = &invalidParameterError{reason: "no local audit sink"}
+	ErrNoSinks          = &invalidParameterError{reason: "no sinks"}
 )
+
+// invalidParameterError is an ErrInvalidParameter with a more specific
+// identity.  Its message is ErrInvalidParameter's, since the reason is already
+// part of the message of the error which wraps it.
+type invalidParameterError struct {
+	reason string
+}
+
+func (e *invalidParameterError) Error() string { return ErrInvalidParameter.Error() }
+
+func (e *invalidParameterError) Unwrap() error { return ErrInvalidParameter }
+
+// auditNotDeliveredError is an ErrAuditNotDelivered which wraps the error
+// writing the audit event, so both can be identified with errors.Is.
+type auditNotDeliveredError struct {
+	err error
+}
+
+func (e *auditNotDeliveredError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrAuditNotDelivered, e.err)
+}
+
+func (e *auditNotDeliveredError) Is(target error) bool { return target == ErrAuditNotDelivered }
+
+func (e *auditNotDeliveredError) Unwrap() error { return e.err }
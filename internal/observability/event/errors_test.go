@@ -0,0 +1,131 @@
+package event
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrors_Is(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	notADir := filepath.Join(tmpDir, "not-a-dir")
+	require.NoError(t, ioutil.WriteFile(notADir, nil, 0o600))
+
+	stderrSink := func(name string, types ...Type) SinkConfig {
+		return SinkConfig{Name: name, SinkType: StderrSink, EventTypes: types, Format: JSONSinkFormat}
+	}
+	tests := []struct {
+		name            string
+		newEventer      func() error
+		wantErrIs       error
+		wantErrContains string
+	}{
+		{
+			name: "missing-logger",
+			newEventer: func() error {
+				_, err := NewEventer(nil, &sync.Mutex{}, EventerConfig{})
+				return err
+			},
+			wantErrIs:       ErrMissingLogger,
+			wantErrContains: "missing logger",
+		},
+		{
+			name: "duplicate-sink",
+			newEventer: func() error {
+				_, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, EventerConfig{
+					Sinks: []SinkConfig{stderrSink("first", EveryType), stderrSink("second", EveryType)},
+				})
+				return err
+			},
+			wantErrIs:       ErrDuplicateSink,
+			wantErrContains: "have the same output target",
+		},
+		{
+			name: "no-sink-for-enabled-type",
+			newEventer: func() error {
+				_, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, EventerConfig{
+					AuditEnabled: true,
+					Sinks:        []SinkConfig{stderrSink("errors", ErrorType)},
+				})
+				return err
+			},
+			wantErrIs:       ErrNoSinkForType,
+			wantErrContains: "audit events enabled but no sink defined for it",
+		},
+		{
+			name: "no-sink-for-errors",
+			newEventer: func() error {
+				_, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, EventerConfig{
+					Sinks: []SinkConfig{stderrSink("system", SystemType)},
+				})
+				return err
+			},
+			wantErrIs:       ErrNoSinkForType,
+			wantErrContains: "no sink defined for error events",
+		},
+		{
+			name: "no-local-audit-sink",
+			newEventer: func() error {
+				_, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, EventerConfig{
+					AuditEnabled:          true,
+					RequireLocalAuditSink: true,
+					Sinks: []SinkConfig{
+						{Name: "kafka", SinkType: KafkaSink, EventTypes: []Type{EveryType}, Format: JSONSinkFormat, KafkaConfig: &KafkaSinkConfig{Brokers: []string{"localhost:9092"}, Topic: "events"}},
+					},
+				})
+				return err
+			},
+			wantErrIs:       ErrNoLocalAuditSink,
+			wantErrContains: "a local audit sink is required",
+		},
+		{
+			name: "unwritable-path",
+			newEventer: func() error {
+				_, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, EventerConfig{
+					Sinks: []SinkConfig{
+						{Name: "file", SinkType: FileSink, EventTypes: []Type{EveryType}, Format: JSONSinkFormat, Path: notADir, FileName: "events.log"},
+					},
+				})
+				return err
+			},
+			wantErrIs:       ErrUnwritablePath,
+			wantErrContains: "is not a directory",
+		},
+		{
+			name: "unwritable-path-validate-config",
+			newEventer: func() error {
+				return ValidateConfig(EventerConfig{
+					Sinks: []SinkConfig{
+						{Name: "file", SinkType: FileSink, EventTypes: []Type{EveryType}, Format: JSONSinkFormat, Path: notADir, FileName: "events.log"},
+					},
+				})
+			},
+			wantErrIs:       ErrUnwritablePath,
+			wantErrContains: "is not a directory",
+		},
+	}
+	allErrs := []error{ErrMissingLogger, ErrDuplicateSink, ErrNoSinkForType, ErrUnwritablePath, ErrNoLocalAuditSink}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			err := tt.newEventer()
+			require.Error(err)
+			assert.ErrorIs(err, tt.wantErrIs)
+			assert.ErrorIs(err, ErrInvalidParameter)
+			assert.Contains(err.Error(), tt.wantErrContains)
+			assert.Contains(err.Error(), ErrInvalidParameter.Error())
+			for _, other := range allErrs {
+				if other != tt.wantErrIs {
+					assert.False(errors.Is(err, other), "unexpectedly is %s", other.(*invalidParameterError).reason)
+				}
+			}
+		})
+	}
+}
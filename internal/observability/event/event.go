@@ -2,17 +2,30 @@ package event
 
 import "google.golang.org/protobuf/proto"
 
+// SchemaVersion defines the current schema version for all event types.  It's
+// stamped onto every event as its VersionField by the Eventer's write methods.
+const SchemaVersion = "v0.1"
+
 type (
 	Id string
+
+	// Op is the operation which an event originates from.  Ops are dotted
+	// namespaces which start with the op's package, followed by its type
+	// and function, like "oidc.Callback" or
+	// "session.(Repository).CreateSession", so the events of a subsystem can
+	// be routed to a sink by their op's prefix (see SinkConfig.OpPrefixes).
 	Op string
 )
 
-// RequestInfo defines the fields captured about a Boundary request.
+// RequestInfo defines the fields captured about a Boundary request.  ScopeId
+// is the scope of the request's resource, which is only known once the
+// request has been authorized, so it's set then (see SinkConfig.ScopeIds).
 type RequestInfo struct {
 	Id       string `json:"id,omitempty"`
 	Method   string `json:"method,omitempty"`
 	Path     string `json:"path,omitempty"`
 	PublicId string `json:"public_id,omitempty"`
+	ScopeId  string `json:"scope_id,omitempty"`
 }
 
 // UserInfo defines the fields captured about a user for a Boundary request.
@@ -21,6 +34,18 @@ type UserInfo struct {
 	AuthAccountId string `json:"auth_account_id,omitempty"`
 }
 
+// AuthInfo defines the identity of the actor who performed a Boundary request,
+// which is recorded in its audit events (see WithAuthInfo).  Authenticated is
+// set when the audit event is written: it's true when the actor has a user
+// id, and false for anonymous requests and requests whose identity is
+// unknown.
+type AuthInfo struct {
+	UserId        string `json:"user_id,omitempty"`
+	AuthMethodId  string `json:"auth_method_id,omitempty"`
+	AuthAccountId string `json:"auth_account_id,omitempty"`
+	Authenticated bool   `json:"authenticated"`
+}
+
 type GrantsInfo struct {
 	Grants []GrantsPair `json:"grants_pair,omitempty"`
 }
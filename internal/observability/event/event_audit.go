@@ -8,7 +8,7 @@ import (
 )
 
 // auditVersion defines the version of audit events
-const auditVersion = "v0.1"
+const auditVersion = SchemaVersion
 
 // auditEventType defines the type of audit event
 type auditEventType string
@@ -19,16 +19,20 @@ const (
 
 // audit defines the data of audit events
 type audit struct {
-	Id             string       `json:"id"`                     // std audit/boundary field
-	Version        string       `json:"version"`                // std audit/boundary field
-	Type           string       `json:"type"`                   // std audit field
-	Timestamp      time.Time    `json:"timestamp"`              // std audit field
-	RequestInfo    *RequestInfo `json:"request_info,omitempty"` // boundary field
-	Auth           *Auth        `json:"auth,omitempty"`         // std audit field
-	Request        *Request     `json:"request,omitempty"`      // std audit field
-	Response       *Response    `json:"response,omitempty"`     // std audit field
-	SerializedHMAC string       `json:"serialized_hmac"`        // boundary field
-	Flush          bool         `json:"-"`
+	Id             string                 `json:"id"`                       // std audit/boundary field
+	Version        string                 `json:"version"`                  // std audit/boundary field
+	Type           string                 `json:"type"`                     // std audit field
+	Timestamp      time.Time              `json:"timestamp"`                // std audit field
+	RequestInfo    *RequestInfo           `json:"request_info,omitempty"`   // boundary field
+	CorrelationId  string                 `json:"correlation_id,omitempty"` // boundary field
+	Auth           *Auth                  `json:"auth,omitempty"`           // std audit field
+	AuthInfo       *AuthInfo              `json:"auth_info,omitempty"`      // boundary field
+	Request        *Request               `json:"request,omitempty"`        // std audit field
+	Response       *Response              `json:"response,omitempty"`       // std audit field
+	SerializedHMAC string                 `json:"serialized_hmac"`          // boundary field
+	Header         map[string]interface{} `json:"header,omitempty"`         // boundary field
+	Seq            uint64                 `json:"seq,omitempty"`            // boundary field
+	Flush          bool                   `json:"-"`
 }
 
 func newAudit(fromOperation Op, opt ...Option) (*audit, error) {
@@ -77,6 +81,9 @@ func (a *audit) validate() error {
 	if a.Id == "" {
 		return fmt.Errorf("%s: missing id: %w", op, ErrInvalidParameter)
 	}
+	if a.Version == "" {
+		return fmt.Errorf("%s: missing version: %w", op, ErrInvalidParameter)
+	}
 	return nil
 }
 
@@ -99,7 +106,7 @@ func (a *audit) ComposeFrom(events []*eventlogger.Event) (eventlogger.EventType,
 	if len(events) == 0 {
 		return "", nil, fmt.Errorf("%s: missing events: %w", op, ErrInvalidParameter)
 	}
-	var validId string
+	var validId, validVersion string
 	payload := audit{}
 	for i, v := range events {
 		gated, ok := v.Payload.(*audit)
@@ -117,8 +124,14 @@ func (a *audit) ComposeFrom(events []*eventlogger.Event) (eventlogger.EventType,
 		if gated.Id != validId {
 			return "", nil, fmt.Errorf("%s: event %d has an invalid id: %s != %s: %w", op, i, gated.Id, validId, ErrInvalidParameter)
 		}
-		if gated.Version != auditVersion {
-			return "", nil, fmt.Errorf("%s: event %d has an invalid version: %s != %s: %w", op, i, gated.Version, auditVersion, ErrInvalidParameter)
+		if gated.Version == "" {
+			return "", nil, fmt.Errorf("%s: event %d: version is required: %w", op, i, ErrInvalidParameter)
+		}
+		if validVersion == "" {
+			validVersion = gated.Version
+		}
+		if gated.Version != validVersion {
+			return "", nil, fmt.Errorf("%s: event %d has an invalid version: %s != %s: %w", op, i, gated.Version, validVersion, ErrInvalidParameter)
 		}
 		if gated.Type != string(ApiRequest) {
 			return "", nil, fmt.Errorf("%s: event %d has an invalid type: %s != %s: %w", op, i, gated.Type, string(AuditType), ErrInvalidParameter)
@@ -126,9 +139,18 @@ func (a *audit) ComposeFrom(events []*eventlogger.Event) (eventlogger.EventType,
 		if gated.RequestInfo != nil {
 			payload.RequestInfo = gated.RequestInfo
 		}
+		if gated.CorrelationId != "" {
+			payload.CorrelationId = gated.CorrelationId
+		}
 		if gated.Auth != nil {
 			payload.Auth = gated.Auth
 		}
+		// the parts written before the request was authenticated don't
+		// have its identity, so they don't replace the identity of a part
+		// which has it
+		if gated.AuthInfo != nil && (payload.AuthInfo == nil || gated.AuthInfo.Authenticated || !payload.AuthInfo.Authenticated) {
+			payload.AuthInfo = gated.AuthInfo
+		}
 		if gated.Request != nil {
 			payload.Request = gated.Request
 		}
@@ -138,10 +160,13 @@ func (a *audit) ComposeFrom(events []*eventlogger.Event) (eventlogger.EventType,
 		if !gated.Timestamp.IsZero() {
 			payload.Timestamp = gated.Timestamp
 		}
+		if gated.Seq != 0 {
+			payload.Seq = gated.Seq
+		}
 
 	}
 	payload.Id = validId
-	payload.Version = auditVersion
+	payload.Version = validVersion
 	payload.Type = string(ApiRequest)
 	return eventlogger.EventType(a.EventType()), payload, nil
 }
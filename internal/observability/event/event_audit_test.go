@@ -86,23 +86,32 @@ func TestAudit_validate(t *testing.T) {
 	tests := []struct {
 		name            string
 		id              string
+		version         string
 		wantErrIs       error
 		wantErrContains string
 	}{
 		{
 			name:            "missing-id",
+			version:         auditVersion,
 			wantErrIs:       ErrInvalidParameter,
 			wantErrContains: "missing id",
 		},
 		{
-			name: "valid",
-			id:   "valid",
+			name:            "missing-version",
+			id:              "missing-version",
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "missing version",
+		},
+		{
+			name:    "valid",
+			id:      "valid",
+			version: auditVersion,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			assert, require := assert.New(t), require.New(t)
-			a := audit{Id: tt.id}
+			a := audit{Id: tt.id, Version: tt.version}
 			err := a.validate()
 			if tt.wantErrIs != nil {
 				require.Error(err)
@@ -174,9 +183,29 @@ func TestAudit_ComposeFrom(t *testing.T) {
 			wantErrIs:       ErrInvalidParameter,
 			wantErrContains: "invalid type",
 		},
+		{
+			name: "missing-version",
+			events: []*eventlogger.Event{
+				{
+					Payload: &audit{
+						Id:   "test-id",
+						Type: string(ApiRequest),
+					},
+				},
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "version is required",
+		},
 		{
 			name: "invalid-version",
 			events: []*eventlogger.Event{
+				{
+					Payload: &audit{
+						Id:      "test-id",
+						Version: auditVersion,
+						Type:    string(ApiRequest),
+					},
+				},
 				{
 					Payload: &audit{
 						Id:      "test-id",
@@ -252,6 +281,41 @@ func TestAudit_ComposeFrom(t *testing.T) {
 				RequestInfo: TestRequestInfo(t),
 			},
 		},
+		{
+			name: "authenticated-auth-info-is-kept",
+			events: []*eventlogger.Event{
+				{
+					Payload: &audit{
+						Id:       "valid",
+						Version:  auditVersion,
+						Type:     string(ApiRequest),
+						AuthInfo: &AuthInfo{},
+					},
+				},
+				{
+					Payload: &audit{
+						Id:       "valid",
+						Version:  auditVersion,
+						Type:     string(ApiRequest),
+						AuthInfo: &AuthInfo{UserId: "u_1234567890", Authenticated: true},
+					},
+				},
+				{
+					Payload: &audit{
+						Id:       "valid",
+						Version:  auditVersion,
+						Type:     string(ApiRequest),
+						AuthInfo: &AuthInfo{},
+					},
+				},
+			},
+			want: audit{
+				Id:       "valid",
+				Version:  auditVersion,
+				Type:     string(ApiRequest),
+				AuthInfo: &AuthInfo{UserId: "u_1234567890", Authenticated: true},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,37 @@
+package event
+
+import "fmt"
+
+// customEvent is an event of a type registered with RegisterEventType.  Its
+// fields are the same as a sysEvent's.
+type customEvent struct {
+	Id            Id                     `json:"id,omitempty"`
+	Version       string                 `json:"version"`
+	Op            Op                     `json:"op,omitempty"`
+	CorrelationId string                 `json:"correlation_id,omitempty"`
+	Data          map[string]interface{} `json:"data"`
+	Header        map[string]interface{} `json:"header,omitempty"`
+	Seq           uint64                 `json:"seq,omitempty"`
+
+	eventType Type
+}
+
+// EventType is required for all event types by the eventlogger broker
+func (e *customEvent) EventType() string { return string(e.eventType) }
+
+func (e *customEvent) validate() error {
+	const op = "event.(customEvent).validate"
+	if e.Id == "" {
+		return fmt.Errorf("%s: missing id: %w", op, ErrInvalidParameter)
+	}
+	if e.Op == "" {
+		return fmt.Errorf("%s: missing operation: %w", op, ErrInvalidParameter)
+	}
+	if e.Version == "" {
+		return fmt.Errorf("%s: missing version: %w", op, ErrInvalidParameter)
+	}
+	if _, ok := registeredType(e.eventType); !ok {
+		return fmt.Errorf("%s: '%s' is not a registered event type: %w", op, e.eventType, ErrInvalidParameter)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package event
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRegisterEventType registers a custom type for the duration of a test.
+// Tests run in parallel, so each must register a type with its own name.
+func testRegisterEventType(t *testing.T, name string, opt ...Option) Type {
+	t.Helper()
+	typ, err := RegisterEventType(name, opt...)
+	require.NoError(t, err)
+	t.Cleanup(func() { unregisterEventType(typ) })
+	return typ
+}
+
+func TestRegisterEventType(t *testing.T) {
+	t.Parallel()
+	registered := testRegisterEventType(t, "test-register-event-type")
+	tests := []struct {
+		name            string
+		typeName        string
+		wantErrContains string
+	}{
+		{name: "missing-name", wantErrContains: "missing event type name"},
+		{name: "upper-case", typeName: "Billing", wantErrContains: `"Billing" is not a valid event type name`},
+		{name: "leading-digit", typeName: "1billing", wantErrContains: "is not a valid event type name"},
+		{name: "every-type", typeName: string(EveryType), wantErrContains: "is not a valid event type name"},
+		{name: "built-in", typeName: string(AuditType), wantErrContains: `"audit" is a built in event type`},
+		{name: "already-registered", typeName: string(registered), wantErrContains: "is already registered"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			got, err := RegisterEventType(tt.typeName)
+			assert.ErrorIs(err, ErrInvalidParameter)
+			assert.Contains(err.Error(), tt.wantErrContains)
+			assert.Empty(got)
+		})
+	}
+	t.Run("validate", func(t *testing.T) {
+		assert := assert.New(t)
+		assert.NoError(registered.validate())
+		assert.False(enforcedDelivery(registered))
+		assert.ErrorIs(Type("test-unregistered").validate(), ErrInvalidParameter)
+	})
+	t.Run("enforced-delivery", func(t *testing.T) {
+		typ := testRegisterEventType(t, "test-register-event-type-enforced", WithEnforcedDelivery())
+		assert.True(t, enforcedDelivery(typ))
+	})
+}
+
+func TestWriteEvent(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	billing := testRegisterEventType(t, "test-write-event-billing")
+	unrouted := testRegisterEventType(t, "test-write-event-unrouted")
+
+	// the billing events are only written to the stderr sink which subscribes
+	// to them, and not to the file sink for EveryType
+	everyFile := filepath.Join(t.TempDir(), "every.log")
+	c := EventerConfig{
+		AuditEnabled:        true,
+		ObservationsEnabled: true,
+		SysEventsEnabled:    true,
+		Sinks: []SinkConfig{
+			{
+				Name:       "every",
+				SinkType:   FileSink,
+				EventTypes: []Type{EveryType},
+				Format:     JSONSinkFormat,
+				FileName:   filepath.Base(everyFile),
+				Path:       filepath.Dir(everyFile),
+			},
+			{
+				Name:       "billing",
+				SinkType:   StderrSink,
+				EventTypes: []Type{billing},
+				Format:     JSONSinkFormat,
+			},
+		},
+	}
+	buf := &SinkBuffer{}
+	e, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, c, WithStderrWriter(buf))
+	require.NoError(t, err)
+	t.Cleanup(func() { e.Close(context.Background()) })
+	ctx, err = NewEventerContext(ctx, e)
+	require.NoError(t, err)
+
+	t.Run("write", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		ctx, err := WithCorrelationId(ctx, "test-correlation-id")
+		require.NoError(err)
+		require.NoError(WriteEvent(ctx, billing, "TestWriteEvent", map[string]interface{}{"amount": 42}, WithId("test-billing-id")))
+
+		got := buf.Events(t, billing)
+		require.Len(got, 1)
+		assert.Equal(billing, got[0].EventType)
+		assert.Equal("test-billing-id", got[0].Payload[IdField])
+		assert.Equal("TestWriteEvent", got[0].Payload[OpField])
+		assert.Equal("test-correlation-id", got[0].Payload[CorrelationIdField])
+		assert.Equal(map[string]interface{}{"amount": float64(42)}, got[0].Payload["data"])
+
+		// an error is written, so the every sink's file is created
+		ev, err := newError("TestWriteEvent", ErrInvalidParameter)
+		require.NoError(err)
+		require.NoError(e.writeError(ctx, ev))
+		b, err := ioutil.ReadFile(everyFile)
+		require.NoError(err)
+		assert.Contains(string(b), string(ErrorType))
+		assert.NotContains(string(b), string(billing))
+	})
+	t.Run("sinks-and-health", func(t *testing.T) {
+		assert := assert.New(t)
+		var billingTypes []Type
+		for _, s := range e.Sinks() {
+			if s.Name == "billing" {
+				billingTypes = s.EventTypes
+			}
+		}
+		assert.Equal([]Type{billing}, billingTypes)
+
+		h, ok := e.Health(ctx).EventTypes[billing]
+		assert.True(ok)
+		assert.True(h.Enabled)
+		assert.True(h.AcceptingWrites)
+		assert.NotNil(h.LastSuccess)
+		assert.Equal("billing", h.Sinks[0].Name)
+		assert.NotContains(e.Health(ctx).EventTypes, unrouted)
+	})
+	t.Run("schema", func(t *testing.T) {
+		assert := assert.New(t)
+		assert.Equal(EventSchema(SystemType).Fields, EventSchema(billing).Fields)
+		_, err := GenerateJSONSchema(billing)
+		assert.NoError(err)
+	})
+	t.Run("no-subscribed-sinks", func(t *testing.T) {
+		assert.NoError(t, WriteEvent(ctx, unrouted, "TestWriteEvent", map[string]interface{}{}))
+		assert.Empty(t, buf.Events(t, unrouted))
+	})
+	t.Run("unregistered", func(t *testing.T) {
+		err := WriteEvent(ctx, "test-write-event-unregistered", "TestWriteEvent", map[string]interface{}{})
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+		assert.Contains(t, err.Error(), "is not a registered event type")
+	})
+	t.Run("built-in", func(t *testing.T) {
+		err := WriteEvent(ctx, SystemType, "TestWriteEvent", map[string]interface{}{})
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	})
+	t.Run("missing-data", func(t *testing.T) {
+		err := WriteEvent(ctx, billing, "TestWriteEvent", nil)
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	})
+}
+
+func TestNewEventer_customTypes(t *testing.T) {
+	t.Parallel()
+	enforced := testRegisterEventType(t, "test-new-eventer-enforced", WithEnforcedDelivery())
+	unenforced := testRegisterEventType(t, "test-new-eventer-unenforced")
+	c := EventerConfig{
+		Sinks: []SinkConfig{
+			{
+				Name:       "every",
+				SinkType:   StderrSink,
+				EventTypes: []Type{EveryType},
+				Format:     JSONSinkFormat,
+			},
+			{
+				Name:       "first",
+				SinkType:   FileSink,
+				EventTypes: []Type{enforced, unenforced},
+				Format:     JSONSinkFormat,
+				FileName:   "first.log",
+				Path:       t.TempDir(),
+			},
+			{
+				Name:       "second",
+				SinkType:   FileSink,
+				EventTypes: []Type{enforced},
+				Format:     JSONSinkFormat,
+				FileName:   "second.log",
+				Path:       t.TempDir(),
+			},
+		},
+	}
+	b := &testMockBroker{}
+	_, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, c, TestWithBroker(t, b))
+	require.NoError(t, err)
+
+	assert := assert.New(t)
+	assert.Equal(2, b.successThresholds[eventlogger.EventType(enforced)])
+	assert.NotContains(b.successThresholds, eventlogger.EventType(unenforced))
+	var custom []eventlogger.EventType
+	for _, p := range b.pipelines {
+		if p.EventType == eventlogger.EventType(enforced) || p.EventType == eventlogger.EventType(unenforced) {
+			custom = append(custom, p.EventType)
+		}
+	}
+	assert.ElementsMatch([]eventlogger.EventType{
+		eventlogger.EventType(enforced),
+		eventlogger.EventType(unenforced),
+		eventlogger.EventType(enforced),
+	}, custom)
+
+	// sinks can't subscribe to types which aren't registered
+	c.Sinks[1].EventTypes = []Type{"test-new-eventer-unregistered"}
+	_, err = NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, c, TestWithBroker(t, &testMockBroker{}))
+	assert.ErrorIs(err, ErrInvalidParameter)
+}
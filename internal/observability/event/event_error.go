@@ -2,17 +2,46 @@ package event
 
 import (
 	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
 )
 
 // errorVersion defines the version of error events
-const errorVersion = "v0.1"
+const errorVersion = SchemaVersion
+
+// defaultErrorStackDepth is the max number of frames in an error event's stack
+// when WithErrorStackTraces doesn't provide one.
+const defaultErrorStackDepth = 32
+
+// writeErrorFunc is the name of WriteError's function, whose frame is trimmed
+// from the top of an error event's stack.
+var writeErrorFunc = reflect.TypeOf((*Eventer)(nil)).Elem().PkgPath() + ".WriteError"
 
 type err struct {
-	Error       error        `json:"error"`
-	Id          Id           `json:"id,omitempty"`
-	Version     string       `json:"version"`
-	Op          Op           `json:"op,omitempty"`
-	RequestInfo *RequestInfo `json:"request_info,omitempty"`
+	Error         error                  `json:"error"`
+	Id            Id                     `json:"id,omitempty"`
+	Version       string                 `json:"version"`
+	Op            Op                     `json:"op,omitempty"`
+	CorrelationId string                 `json:"correlation_id,omitempty"`
+	RequestInfo   *RequestInfo           `json:"request_info,omitempty"`
+	Header        map[string]interface{} `json:"header,omitempty"`
+
+	// Stack is the stack of the goroutine which wrote the error, one
+	// "function file:line" entry per frame, when the eventer was created
+	// WithErrorStackTraces.
+	Stack []string `json:"stack,omitempty"`
+
+	// RepeatCount, FirstSeen and LastSeen are set when this event is the
+	// latest of an error's repeats which were coalesced into it (see
+	// WithErrorCoalescing).
+	RepeatCount int        `json:"repeat_count,omitempty"`
+	FirstSeen   *time.Time `json:"first_seen,omitempty"`
+	LastSeen    *time.Time `json:"last_seen,omitempty"`
+
+	// Seq is the event's sequence number (see WithSequenceNumbers).
+	Seq uint64 `json:"seq,omitempty"`
 }
 
 func newError(fromOperation Op, e error, opt ...Option) (*err, error) {
@@ -55,8 +84,40 @@ func (e *err) validate() error {
 	if e.Op == "" {
 		return fmt.Errorf("%s: missing operation: %w", op, ErrInvalidParameter)
 	}
+	if e.Version == "" {
+		return fmt.Errorf("%s: missing version: %w", op, ErrInvalidParameter)
+	}
 	if e.Error == nil {
 		return fmt.Errorf("%s: missing error: %w", op, ErrInvalidParameter)
 	}
 	return nil
 }
+
+// errorStack returns the stack of the goroutine writing an error event, as
+// "function file:line" entries, starting at the caller of WriteError (or of
+// writeError, when it's called directly).  Runtime frames are stripped and at
+// most maxDepth frames are returned.
+func errorStack(maxDepth int) []string {
+	if maxDepth <= 0 {
+		return nil
+	}
+	// a few extra frames are captured, since some are stripped
+	pcs := make([]uintptr, maxDepth+8)
+	// skip runtime.Callers, errorStack and writeError
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	stack := make([]string, 0, maxDepth)
+	for top := true; len(stack) < maxDepth; top = false {
+		f, more := frames.Next()
+		switch {
+		case top && f.Function == writeErrorFunc:
+		case strings.HasPrefix(f.Function, "runtime."):
+		default:
+			stack = append(stack, fmt.Sprintf("%s %s:%d", f.Function, f.File, f.Line))
+		}
+		if !more {
+			break
+		}
+	}
+	return stack
+}
@@ -92,6 +92,7 @@ func Test_errvalidate(t *testing.T) {
 		name            string
 		id              string
 		op              Op
+		version         string
 		want            error
 		wantErrIs       error
 		wantErrContains string
@@ -111,19 +112,29 @@ func Test_errvalidate(t *testing.T) {
 			wantErrContains: "missing operation",
 		},
 		{
-			name: "valid",
-			op:   Op("valid"),
-			id:   "valid",
-			want: fmt.Errorf("%s: valid error: %w", "valid-error", ErrInvalidParameter),
+			name:            "missing-version",
+			op:              Op("missing-version"),
+			id:              "missing-version",
+			want:            fmt.Errorf("%s: missing version: %w", "missing-version", ErrInvalidParameter),
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "missing version",
+		},
+		{
+			name:    "valid",
+			op:      Op("valid"),
+			id:      "valid",
+			version: errorVersion,
+			want:    fmt.Errorf("%s: valid error: %w", "valid-error", ErrInvalidParameter),
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			assert, require := assert.New(t), require.New(t)
 			e := err{
-				Op:    tt.op,
-				Id:    Id(tt.id),
-				Error: tt.want,
+				Op:      tt.op,
+				Id:      Id(tt.id),
+				Version: tt.version,
+				Error:   tt.want,
 			}
 			err := e.validate()
 			if tt.wantErrIs != nil {
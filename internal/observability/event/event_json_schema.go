@@ -0,0 +1,177 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/eventlogger/filters/gated"
+	"github.com/hashicorp/go-secure-stdlib/strutil"
+)
+
+// jsonSchemaDraft is the JSON Schema dialect of the documents returned by
+// GenerateJSONSchema.
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// GenerateJSONSchema returns a JSON Schema document which describes the events
+// of type t, as they're written by json sinks.  The schema of the event's
+// payload is generated from its struct, so it stays in sync with the struct's
+// fields: a field is required unless it's tagged omitempty.  Objects allow
+// additional properties, since headers, details and default fields (see
+// WithDefaultFields) aren't known until the event is written.
+//
+// Observations are written as the id, header and details composed from the
+// parts sent for the observation's id, so the observation's own fields
+// (other than its op, which is written to each detail) are described as
+// fields of its header.
+func GenerateJSONSchema(t Type) ([]byte, error) {
+	const op = "event.GenerateJSONSchema"
+	var payload map[string]interface{}
+	switch t {
+	case AuditType:
+		payload = jsonSchemaOf(reflect.TypeOf(audit{}))
+	case ErrorType:
+		payload = jsonSchemaOf(reflect.TypeOf(err{}))
+	case SystemType:
+		payload = jsonSchemaOf(reflect.TypeOf(sysEvent{}))
+	case ObservationType:
+		payload = observationJSONSchema()
+	default:
+		if _, ok := registeredType(t); !ok {
+			return nil, fmt.Errorf("%s: %s is not a valid event type: %w", op, t, ErrInvalidParameter)
+		}
+		payload = jsonSchemaOf(reflect.TypeOf(customEvent{}))
+	}
+	doc := map[string]interface{}{
+		"$schema": jsonSchemaDraft,
+		"title":   fmt.Sprintf("boundary %s event", t),
+		"type":    "object",
+		"properties": map[string]interface{}{
+			CreatedAtField: map[string]interface{}{
+				// created_at is an integer when the sink's
+				// TimestampFormat is EpochMillisTimestampFormat
+				"oneOf": []interface{}{
+					map[string]interface{}{"type": "string", "format": "date-time"},
+					map[string]interface{}{"type": "integer"},
+				},
+			},
+			"event_type": map[string]interface{}{"const": string(t)},
+			"payload":    payload,
+		},
+		"required": []string{CreatedAtField, "event_type", "payload"},
+	}
+	b, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return b, nil
+}
+
+// observationJSONSchema returns the schema of a composed observation's
+// payload.
+func observationJSONSchema() map[string]interface{} {
+	s := jsonSchemaOf(reflect.TypeOf(gated.EventPayload{}))
+	header := jsonSchemaOf(reflect.TypeOf(observation{}), "Payload", "Op")
+	s["properties"].(map[string]interface{})[HeaderField] = header
+	return s
+}
+
+// jsonSchemaOf returns the schema of the JSON encoding of values of type t.
+// The struct fields named by skip are excluded from its schema.
+func jsonSchemaOf(t reflect.Type, skip ...string) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte is encoded as a base64 string
+			return map[string]interface{}{"type": "string"}
+		}
+		s := map[string]interface{}{"type": "array"}
+		if items := jsonSchemaOf(t.Elem()); len(items) > 0 {
+			s["items"] = items
+		}
+		return s
+	case reflect.Map:
+		s := map[string]interface{}{"type": "object"}
+		if values := jsonSchemaOf(t.Elem()); len(values) > 0 {
+			s["additionalProperties"] = values
+		}
+		return s
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		var required []string
+		addStructFields(t, properties, &required, skip)
+		s := map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+		}
+		if len(required) > 0 {
+			sort.Strings(required)
+			s["required"] = required
+		}
+		return s
+	default:
+		// interfaces (like details and errors) may be any JSON value
+		return map[string]interface{}{}
+	}
+}
+
+// addStructFields adds the schema of each of the struct's encoded fields to
+// properties, following the encoding/json rules for field names and embedded
+// structs.
+func addStructFields(t reflect.Type, properties map[string]interface{}, required *[]string, skip []string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		if strutil.StrListContains(skip, f.Name) {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, tagOpts := tag, ""
+		if idx := strings.Index(tag, ","); idx != -1 {
+			name, tagOpts = tag[:idx], tag[idx+1:]
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			addStructFields(ft, properties, required, nil)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = jsonSchemaOf(f.Type)
+		if !strings.Contains(","+tagOpts+",", ",omitempty,") {
+			*required = append(*required, name)
+		}
+	}
+}
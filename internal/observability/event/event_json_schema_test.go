@@ -0,0 +1,50 @@
+package event
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files")
+
+func TestGenerateJSONSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		t      Type
+		golden string
+	}{
+		{name: "audit", t: AuditType, golden: "audit.json"},
+		{name: "observation", t: ObservationType, golden: "observation.json"},
+		{name: "error", t: ErrorType, golden: "error.json"},
+		{name: "system", t: SystemType, golden: "system.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			got, err := GenerateJSONSchema(tt.t)
+			require.NoError(err)
+			assert.True(json.Valid(got))
+
+			path := filepath.Join("testdata", "json_schema", tt.golden)
+			if *updateGolden {
+				require.NoError(os.MkdirAll(filepath.Dir(path), 0o755))
+				require.NoError(os.WriteFile(path, got, 0o644))
+			}
+			want, err := os.ReadFile(path)
+			require.NoError(err, "run the test with -update to create the golden file")
+			assert.Equal(string(want), string(got))
+		})
+	}
+	t.Run("invalid-type", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		_, err := GenerateJSONSchema(EveryType)
+		require.Error(err)
+		assert.ErrorIs(err, ErrInvalidParameter)
+	})
+}
@@ -8,13 +8,17 @@ import (
 )
 
 // observationVersion defines the version of observation events
-const observationVersion = "v0.1"
+const observationVersion = SchemaVersion
 
 type observation struct {
 	*gated.Payload
 	Version     string       `json:"version"`
 	Op          Op           `json:"op,omitempty"`
 	RequestInfo *RequestInfo `json:"request_info,omitempty"`
+
+	// Level is the observation's verbosity level.  Observations below the
+	// eventer's minimum level aren't emitted.
+	Level ObservationLevel `json:"-"`
 }
 
 func newObservation(fromOperation Op, opt ...Option) (*observation, error) {
@@ -31,7 +35,7 @@ func newObservation(fromOperation Op, opt ...Option) (*observation, error) {
 		}
 	}
 	for k := range opts.withHeader {
-		if strutil.StrListContains([]string{OpField, VersionField, RequestInfoField}, k) {
+		if strutil.StrListContains([]string{OpField, VersionField, RequestInfoField, CorrelationIdField, LevelField, SeqField}, k) {
 			return nil, fmt.Errorf("%s: %s is a reserved field name: %w", op, k, ErrInvalidParameter)
 		}
 	}
@@ -45,6 +49,7 @@ func newObservation(fromOperation Op, opt ...Option) (*observation, error) {
 		Op:          fromOperation,
 		RequestInfo: opts.withRequestInfo,
 		Version:     observationVersion,
+		Level:       opts.withObservationLevel,
 	}
 	if err := i.validate(); err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
@@ -63,5 +68,11 @@ func (i *observation) validate() error {
 	if i.Op == "" {
 		return fmt.Errorf("%s: missing operation: %w", op, ErrInvalidParameter)
 	}
+	if i.Version == "" {
+		return fmt.Errorf("%s: missing version: %w", op, ErrInvalidParameter)
+	}
+	if err := i.Level.validate(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
 	return nil
 }
@@ -98,6 +98,7 @@ func Test_observationvalidate(t *testing.T) {
 		name            string
 		id              string
 		op              Op
+		version         string
 		wantErrIs       error
 		wantErrContains string
 	}{
@@ -114,16 +115,25 @@ func Test_observationvalidate(t *testing.T) {
 			wantErrContains: "missing operation",
 		},
 		{
-			name: "valid",
-			op:   Op("valid"),
-			id:   "valid",
+			name:            "missing-version",
+			op:              Op("missing-version"),
+			id:              "missing-version",
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "missing version",
+		},
+		{
+			name:    "valid",
+			op:      Op("valid"),
+			id:      "valid",
+			version: observationVersion,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			assert, require := assert.New(t), require.New(t)
 			e := observation{
-				Op: tt.op,
+				Op:      tt.op,
+				Version: tt.version,
 				Payload: &gated.Payload{
 					ID: tt.id,
 				},
@@ -0,0 +1,138 @@
+package event
+
+// FieldKind is the kind of an event field's JSON value.
+type FieldKind string
+
+const (
+	StringKind    FieldKind = "string"    // StringKind is a JSON string
+	TimestampKind FieldKind = "timestamp" // TimestampKind is a JSON string with an RFC 3339 timestamp
+	ObjectKind    FieldKind = "object"    // ObjectKind is a JSON object
+	ArrayKind     FieldKind = "array"     // ArrayKind is a JSON array
+	NumberKind    FieldKind = "number"    // NumberKind is a JSON number
+	BoolKind      FieldKind = "boolean"   // BoolKind is a JSON boolean
+)
+
+// SchemaField describes a field of an event.
+type SchemaField struct {
+	// Name of the field's JSON key.
+	Name string
+
+	// Kind of the field's value.
+	Kind FieldKind
+
+	// Required is true when the field is always present.  Other fields are
+	// omitted when they're empty.
+	Required bool
+
+	// Fields are the fields of the field's value when its kind is an object,
+	// or of each of its elements when its kind is an array.  They're only set
+	// when the value's fields are known, and a header may have other fields
+	// (see WithHeader).
+	Fields []SchemaField
+}
+
+// Schema describes the fields of an event type's payload.  Every event is
+// written as its created_at (TimestampKind), its event_type (StringKind) and
+// its payload (ObjectKind), which has the schema's fields:
+//
+//	{"created_at": ..., "event_type": "audit", "payload": {"id": ..., ...}}
+type Schema struct {
+	Type   Type
+	Fields []SchemaField
+}
+
+// EventSchema returns the schema of the event type's payload.  The schema of
+// an unknown type, or EveryType, has no fields.  Types registered with
+// RegisterEventType share a schema, which has the same fields as system
+// events.
+func EventSchema(t Type) Schema {
+	s := Schema{Type: t}
+	switch t {
+	case AuditType:
+		s.Fields = []SchemaField{
+			{Name: IdField, Kind: StringKind, Required: true},
+			{Name: VersionField, Kind: StringKind, Required: true},
+			{Name: TypeField, Kind: StringKind, Required: true},
+			{Name: "timestamp", Kind: TimestampKind, Required: true},
+			{Name: RequestInfoField, Kind: ObjectKind, Fields: requestInfoSchema},
+			{Name: CorrelationIdField, Kind: StringKind},
+			{Name: "auth", Kind: ObjectKind},
+			{Name: "auth_info", Kind: ObjectKind, Fields: authInfoSchema},
+			{Name: "request", Kind: ObjectKind},
+			{Name: "response", Kind: ObjectKind},
+			{Name: "serialized_hmac", Kind: StringKind, Required: true},
+			{Name: HeaderField, Kind: ObjectKind},
+			{Name: SeqField, Kind: NumberKind},
+		}
+	case ErrorType:
+		s.Fields = []SchemaField{
+			{Name: "error", Kind: ObjectKind, Required: true},
+			{Name: IdField, Kind: StringKind, Required: true},
+			{Name: VersionField, Kind: StringKind, Required: true},
+			{Name: OpField, Kind: StringKind, Required: true},
+			{Name: CorrelationIdField, Kind: StringKind},
+			{Name: RequestInfoField, Kind: ObjectKind, Fields: requestInfoSchema},
+			{Name: HeaderField, Kind: ObjectKind},
+			{Name: "stack", Kind: ArrayKind},
+			{Name: "repeat_count", Kind: NumberKind},
+			{Name: "first_seen", Kind: TimestampKind},
+			{Name: "last_seen", Kind: TimestampKind},
+			{Name: SeqField, Kind: NumberKind},
+		}
+	case ObservationType:
+		// an observation is composed from the parts sent for its id: its
+		// header holds the version, request info and correlation id, and
+		// each part's details hold its op.
+		s.Fields = []SchemaField{
+			{Name: IdField, Kind: StringKind, Required: true},
+			{Name: HeaderField, Kind: ObjectKind, Required: true, Fields: []SchemaField{
+				{Name: VersionField, Kind: StringKind, Required: true},
+				{Name: RequestInfoField, Kind: ObjectKind, Fields: requestInfoSchema},
+				{Name: CorrelationIdField, Kind: StringKind},
+				{Name: LevelField, Kind: StringKind},
+				{Name: SeqField, Kind: NumberKind},
+			}},
+			{Name: DetailsField, Kind: ArrayKind, Fields: []SchemaField{
+				{Name: TypeField, Kind: StringKind, Required: true},
+				{Name: CreatedAtField, Kind: TimestampKind, Required: true},
+				{Name: "payload", Kind: ObjectKind},
+			}},
+		}
+	case SystemType:
+		s.Fields = sysSchema
+	default:
+		if _, ok := registeredType(t); ok {
+			s.Fields = sysSchema
+		}
+	}
+	return s
+}
+
+// sysSchema is the schema of system events, which is also the schema of the
+// events of custom types.
+var sysSchema = []SchemaField{
+	{Name: IdField, Kind: StringKind, Required: true},
+	{Name: VersionField, Kind: StringKind, Required: true},
+	{Name: OpField, Kind: StringKind, Required: true},
+	{Name: CorrelationIdField, Kind: StringKind},
+	{Name: "data", Kind: ObjectKind, Required: true},
+	{Name: HeaderField, Kind: ObjectKind},
+	{Name: SeqField, Kind: NumberKind},
+}
+
+// authInfoSchema is the schema of an AuthInfo.
+var authInfoSchema = []SchemaField{
+	{Name: "user_id", Kind: StringKind},
+	{Name: "auth_method_id", Kind: StringKind},
+	{Name: "auth_account_id", Kind: StringKind},
+	{Name: "authenticated", Kind: BoolKind, Required: true},
+}
+
+// requestInfoSchema is the schema of a RequestInfo.
+var requestInfoSchema = []SchemaField{
+	{Name: IdField, Kind: StringKind},
+	{Name: "method", Kind: StringKind},
+	{Name: "path", Kind: StringKind},
+	{Name: "public_id", Kind: StringKind},
+	{Name: "scope_id", Kind: StringKind},
+}
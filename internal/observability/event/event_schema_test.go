@@ -0,0 +1,184 @@
+package event
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/eventlogger/filters/gated"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_EventSchema_structs ensures the schemas stay in sync with the structs
+// of the events' payloads.
+func Test_EventSchema_structs(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		fields []SchemaField
+		v      interface{}
+	}{
+		{name: "audit", fields: EventSchema(AuditType).Fields, v: audit{}},
+		{name: "error", fields: EventSchema(ErrorType).Fields, v: err{}},
+		{name: "system", fields: EventSchema(SystemType).Fields, v: sysEvent{}},
+		{name: "observation", fields: EventSchema(ObservationType).Fields, v: gated.EventPayload{}},
+		{name: "observation-details", fields: schemaField(t, EventSchema(ObservationType), DetailsField).Fields, v: gated.EventPayloadDetails{}},
+		{name: "request-info", fields: requestInfoSchema, v: RequestInfo{}},
+		{name: "auth-info", fields: authInfoSchema, v: AuthInfo{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, jsonNames(reflect.TypeOf(tt.v)), schemaNames(tt.fields))
+		})
+	}
+	assert.Empty(t, EventSchema(EveryType).Fields)
+	assert.Empty(t, EventSchema("unknown").Fields)
+}
+
+// TestEventer_EventSchema ensures the write path populates exactly the fields
+// in the schemas.
+func TestEventer_EventSchema(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tmpFile, err := ioutil.TempFile("./", "tmp-schema-TestEventer_EventSchema")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.Remove(tmpFile.Name()) })
+	c := EventerConfig{
+		AuditEnabled:        true,
+		ObservationsEnabled: true,
+		SysEventsEnabled:    true,
+		Sinks: []SinkConfig{
+			{
+				Name:       "every-type-file-sink",
+				SinkType:   FileSink,
+				EventTypes: []Type{EveryType},
+				Format:     JSONSinkFormat,
+				Path:       "./",
+				FileName:   tmpFile.Name(),
+			},
+		},
+	}
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	e, err := NewEventer(testLogger, testLock, c)
+	require.NoError(t, err)
+
+	info := &RequestInfo{Id: "request-id", Method: "POST", Path: "/v1/users"}
+	testAudit, err := newAudit("TestEventer_EventSchema", WithRequestInfo(info), WithFlush())
+	require.NoError(t, err)
+	require.NoError(t, e.writeAudit(ctx, testAudit))
+	testError, err := newError("TestEventer_EventSchema", fmt.Errorf("%s: test", ErrIo), WithRequestInfo(info))
+	require.NoError(t, err)
+	require.NoError(t, e.writeError(ctx, testError))
+	testObservation, err := newObservation("TestEventer_EventSchema", WithRequestInfo(info), WithDetails(map[string]interface{}{"name": "test"}), WithFlush())
+	require.NoError(t, err)
+	require.NoError(t, e.writeObservation(ctx, testObservation))
+	require.NoError(t, e.writeSysEvent(ctx, &sysEvent{Id: "sys-id", Version: sysVersion, Op: "TestEventer_EventSchema", Data: map[string]interface{}{"name": "test"}}))
+	require.NoError(t, e.Close(ctx))
+
+	f, err := os.Open(tmpFile.Name())
+	require.NoError(t, err)
+	defer f.Close()
+	seen := map[Type]bool{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var line struct {
+			CreatedAt string                 `json:"created_at"`
+			EventType Type                   `json:"event_type"`
+			Payload   map[string]interface{} `json:"payload"`
+		}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		assert.NotEmpty(t, line.CreatedAt)
+		seen[line.EventType] = true
+		assertSchema(t, string(line.EventType), EventSchema(line.EventType).Fields, line.Payload, true)
+	}
+	require.NoError(t, scanner.Err())
+	assert.Equal(t, map[Type]bool{AuditType: true, ErrorType: true, ObservationType: true, SystemType: true}, seen)
+}
+
+// assertSchema asserts the value has the schema's required fields and, when
+// exact, that it only has the schema's fields.
+func assertSchema(t *testing.T, path string, fields []SchemaField, v map[string]interface{}, exact bool) {
+	t.Helper()
+	if exact {
+		for k := range v {
+			assert.Contains(t, schemaNames(fields), k, "%s has a field which isn't in its schema", path)
+		}
+	}
+	for _, f := range fields {
+		fv, ok := v[f.Name]
+		if !ok {
+			assert.False(t, f.Required, "%s is missing required field %s", path, f.Name)
+			continue
+		}
+		switch f.Kind {
+		case StringKind, TimestampKind:
+			assert.IsType(t, "", fv, "%s.%s", path, f.Name)
+		case NumberKind:
+			assert.IsType(t, float64(0), fv, "%s.%s", path, f.Name)
+		case BoolKind:
+			assert.IsType(t, false, fv, "%s.%s", path, f.Name)
+		case ArrayKind:
+			assert.IsType(t, []interface{}{}, fv, "%s.%s", path, f.Name)
+			for _, elem := range fv.([]interface{}) {
+				assertSchema(t, path+"."+f.Name, f.Fields, elem.(map[string]interface{}), true)
+			}
+		case ObjectKind:
+			if fv == nil && !f.Required {
+				continue
+			}
+			assert.IsType(t, map[string]interface{}{}, fv, "%s.%s", path, f.Name)
+			if len(f.Fields) > 0 {
+				// headers may have other fields
+				assertSchema(t, path+"."+f.Name, f.Fields, fv.(map[string]interface{}), f.Name != HeaderField)
+			}
+		}
+	}
+}
+
+func schemaField(t *testing.T, s Schema, name string) SchemaField {
+	t.Helper()
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	require.FailNow(t, "missing schema field", name)
+	return SchemaField{}
+}
+
+func schemaNames(fields []SchemaField) []string {
+	names := make([]string, 0, len(fields))
+	for _, f := range fields {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// jsonNames returns the sorted json names of the struct's fields.
+func jsonNames(typ reflect.Type) []string {
+	var names []string
+	for i := 0; i < typ.NumField(); i++ {
+		name := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
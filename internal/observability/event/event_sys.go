@@ -1,14 +1,33 @@
 package event
 
+import "fmt"
+
 // sysVersion defines the version of sys events
-const sysVersion = "v0.1"
+const sysVersion = SchemaVersion
 
 type sysEvent struct {
-	Id      Id                     `json:"id,omitempty"`
-	Version string                 `json:"version"`
-	Op      Op                     `json:"op,omitempty"`
-	Data    map[string]interface{} `json:"data"`
+	Id            Id                     `json:"id,omitempty"`
+	Version       string                 `json:"version"`
+	Op            Op                     `json:"op,omitempty"`
+	CorrelationId string                 `json:"correlation_id,omitempty"`
+	Data          map[string]interface{} `json:"data"`
+	Header        map[string]interface{} `json:"header,omitempty"`
+	Seq           uint64                 `json:"seq,omitempty"`
 }
 
 // EventType is required for all event types by the eventlogger broker
 func (e *sysEvent) EventType() string { return string(ErrorType) }
+
+func (e *sysEvent) validate() error {
+	const op = "event.(sysEvent).validate"
+	if e.Id == "" {
+		return fmt.Errorf("%s: missing id: %w", op, ErrInvalidParameter)
+	}
+	if e.Op == "" {
+		return fmt.Errorf("%s: missing operation: %w", op, ErrInvalidParameter)
+	}
+	if e.Version == "" {
+		return fmt.Errorf("%s: missing version: %w", op, ErrInvalidParameter)
+	}
+	return nil
+}
@@ -2,6 +2,9 @@ package event
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
+	"sync"
 )
 
 // Type represents the event's type
@@ -15,12 +18,98 @@ const (
 	SystemType      Type = "system"      // SysType represents system events
 )
 
-func (et Type) validate() error {
-	const op = "event.(Type).validate"
+// customTypeName matches the names of the types which may be registered with
+// RegisterEventType.
+var customTypeName = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// customType is an event type registered with RegisterEventType.
+type customType struct {
+	enforcedDelivery bool
+}
+
+// customTypes are the event types registered with RegisterEventType.
+var customTypes = struct {
+	sync.RWMutex
+	m map[Type]customType
+}{m: map[Type]customType{}}
+
+// RegisterEventType registers a custom event type, which may then be used in
+// the EventTypes of a SinkConfig and written with WriteEvent.  Types must be
+// registered before the eventers which route them are created, since an
+// eventer's pipelines are created by NewEventer.  Custom types aren't
+// included in EveryType, so they're only written to the sinks which subscribe
+// to them by name.
+//
+// The name must be lower case letters, digits, underscores and hyphens, and
+// start with a letter.  It must not be the name of a built in type or of a
+// type which has already been registered.  Supported options:
+// WithEnforcedDelivery.
+func RegisterEventType(name string, opt ...Option) (Type, error) {
+	const op = "event.RegisterEventType"
+	t := Type(name)
+	switch {
+	case name == "":
+		return "", fmt.Errorf("%s: missing event type name: %w", op, ErrInvalidParameter)
+	case !customTypeName.MatchString(name):
+		return "", fmt.Errorf("%s: %q is not a valid event type name: %w", op, name, ErrInvalidParameter)
+	case t.builtIn():
+		return "", fmt.Errorf("%s: %q is a built in event type: %w", op, name, ErrInvalidParameter)
+	}
+	opts := getOpts(opt...)
+	customTypes.Lock()
+	defer customTypes.Unlock()
+	if _, ok := customTypes.m[t]; ok {
+		return "", fmt.Errorf("%s: event type %q is already registered: %w", op, name, ErrInvalidParameter)
+	}
+	customTypes.m[t] = customType{enforcedDelivery: opts.withEnforcedDelivery}
+	return t, nil
+}
+
+// unregisterEventType removes a type registered with RegisterEventType.  It's
+// only used by tests, which must not share their registered types.
+func unregisterEventType(t Type) {
+	customTypes.Lock()
+	defer customTypes.Unlock()
+	delete(customTypes.m, t)
+}
+
+// registeredType returns the registration of a custom type and whether it's
+// registered.
+func registeredType(t Type) (customType, bool) {
+	customTypes.RLock()
+	defer customTypes.RUnlock()
+	ct, ok := customTypes.m[t]
+	return ct, ok
+}
+
+// builtIn returns whether the type is one of the package's own types,
+// including EveryType.
+func (et Type) builtIn() bool {
 	switch et {
 	case EveryType, ObservationType, AuditType, ErrorType, SystemType:
-		return nil
+		return true
 	default:
-		return fmt.Errorf("%s: '%s' is not a valid event type: %w", op, et, ErrInvalidParameter)
+		return false
+	}
+}
+
+func (et Type) validate() error {
+	const op = "event.(Type).validate"
+	if et.builtIn() {
+		return nil
+	}
+	if _, ok := registeredType(et); ok {
+		return nil
+	}
+	return fmt.Errorf("%s: '%s' is not a valid event type: %w", op, et, ErrInvalidParameter)
+}
+
+// sortedTypes returns the types sorted by name.
+func sortedTypes(types map[Type]bool) []Type {
+	sorted := make([]Type, 0, len(types))
+	for t := range types {
+		sorted = append(sorted, t)
 	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
 }
@@ -2,8 +2,11 @@ package event
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -11,22 +14,27 @@ import (
 	"github.com/hashicorp/eventlogger/filters/gated"
 	"github.com/hashicorp/eventlogger/sinks/writer"
 	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-multierror"
 )
 
 const (
-	OpField          = "op"           // OpField in an event.
-	RequestInfoField = "request_info" // RequestInfoField in an event.
-	VersionField     = "version"      // VersionField in an event
-	DetailsField     = "details"      // Details field in an event.
-	HeaderField      = "header"       // HeaderField in an event.
-	IdField          = "id"           // IdField in an event.
-	CreatedAtField   = "created_at"   // CreatedAtField in an event.
-	TypeField        = "type"         // TypeField in an event.
+	OpField            = "op"             // OpField in an event.
+	RequestInfoField   = "request_info"   // RequestInfoField in an event.
+	VersionField       = "version"        // VersionField in an event
+	CorrelationIdField = "correlation_id" // CorrelationIdField in an event
+	DetailsField       = "details"        // Details field in an event.
+	HeaderField        = "header"         // HeaderField in an event.
+	IdField            = "id"             // IdField in an event.
+	CreatedAtField     = "created_at"     // CreatedAtField in an event.
+	TypeField          = "type"           // TypeField in an event.
+	LevelField         = "level"          // LevelField in an observation event.
+	SeqField           = "seq"            // SeqField in an event (see WithSequenceNumbers).
 
 	auditPipeline       = "audit-pipeline"       // auditPipeline is a pipeline for audit events
 	observationPipeline = "observation-pipeline" // observationPipeline is a pipeline for observation events
 	errPipeline         = "err-pipeline"         // errPipeline is a pipeline for error events
 	sysPipeline         = "sys-pipeline"         // sysPipeline is a pipeline for system events
+	customPipeline      = "custom-pipeline"      // customPipeline is a pipeline for the events of a custom type (see RegisterEventType)
 )
 
 // flushable defines an interface that all eventlogger Nodes must implement if
@@ -45,25 +53,152 @@ type broker interface {
 	RegisterNode(id eventlogger.NodeID, node eventlogger.Node) error
 	SetSuccessThreshold(t eventlogger.EventType, successThreshold int) error
 	RegisterPipeline(def eventlogger.Pipeline) error
+	RemovePipeline(t eventlogger.EventType, id eventlogger.PipelineID) error
 }
 
 // Eventer provides a method to send events to pipelines of sinks
 type Eventer struct {
 	broker               broker
 	flushableNodes       []flushable
+	flushableSinks       []flushable
 	conf                 EventerConfig
 	logger               hclog.Logger
 	auditPipelines       []pipeline
 	observationPipelines []pipeline
 	errPipelines         []pipeline
+	sysPipelines         []pipeline
+	customPipelines      []pipeline
+	schemaVersion        string
+	health               *eventerHealth
+	warningsHook         WarningsHook
+	warningsAsEvents     bool
+	maxEventBytes        int
+	strictSerialization  bool
+	noBackoffJitter      bool
+
+	// observationPause buffers observations while they're paused, up to
+	// maxPausedObservations (or defaultMaxPausedObservations when it's zero).
+	observationPause      observationPause
+	maxPausedObservations int
+
+	// errorStackDepth is the max depth of the stack attached to error
+	// events.  Stacks aren't attached when it's zero.
+	errorStackDepth int
+
+	// errorCoalescer holds the repeats of the latest error event sent while
+	// identical errors are coalesced, for up to errorCoalesceInterval.
+	// Errors aren't coalesced when the interval is zero.
+	errorCoalescer        errorCoalescer
+	errorCoalesceInterval time.Duration
+
+	// sendTimeout is the max duration of each attempt to send an event to
+	// the broker.  Sends aren't given a timeout when it's zero.
+	sendTimeout time.Duration
+
+	// sequenceNumbers is true when events are numbered with seq, the
+	// number of the last event numbered (see nextSeq).
+	sequenceNumbers bool
+	seq             uint64
+
+	// auditGates are the gated filter nodes of the audit pipelines, keyed by
+	// pipeline id.  They're only set when the config's FlushEachAudit is
+	// enabled.
+	auditGates map[eventlogger.PipelineID]flushable
+
+	// sinks describes the eventer's sinks, in the order they're registered.
+	sinks []SinkInfo
+
+	// rotationSchedulers rotate the file sinks with a RotateSchedule.
+	// They're started once the eventer is created and stopped when it's
+	// closed.
+	rotationSchedulers []*rotationScheduler
+
+	// rateLimitNodes are the rate limit nodes of each event type's
+	// pipelines.  They're only set for types with a rate limit.
+	rateLimitNodes map[Type][]*rateLimitNode
+
+	// sensitiveHeaders are the lower cased names of the header fields which
+	// are scrubbed from observations (see WithSensitiveHeaders).
+	sensitiveHeaders map[string]bool
+
+	// pathConfirmations queues the paths of the file sinks after their first
+	// write, when the eventer was created WithPathConfirmation.
+	pathConfirmations pathConfirmations
+
+	// stderr is the writer of the StderrSinks which aren't in a writer
+	// group, which serializes their writes unless WithUnserializedStderr
+	// was used.
+	stderr io.Writer
+
+	// stderrLock is the serialization lock recorded in openStderrLocks
+	// when the eventer writes to os.Stderr, which is removed once it's
+	// closed.
+	stderrLock *sync.Mutex
+
+	// debugSink is the sink enabled by EnableDebugSink, which is nil when
+	// it isn't enabled.
+	debugSink *debugSink
+
+	// middleware is the chain each event's payload is passed through before
+	// it's sent (see Use).
+	middleware []Middleware
+
+	// now returns the current time for timing spans (see StartSpan).
+	// time.Now is used when it's nil.
+	now func() time.Time
+
+	// closeLock is held for reading while an event is written and for
+	// writing while the eventer is closed or reopened, so events aren't
+	// written to closed sinks or to sinks being reopened.
+	closeLock sync.RWMutex
+	closed    bool
 }
 
+// WarningsHook is called with the warnings returned when an event of type t
+// isn't delivered to every sink.
+type WarningsHook func(t Type, warnings []error)
+
 type pipeline struct {
-	eventType  Type
-	fmtId      eventlogger.NodeID
-	sinkId     eventlogger.NodeID
-	gateId     eventlogger.NodeID
-	sinkConfig SinkConfig
+	eventType   Type
+	fmtId       eventlogger.NodeID
+	sinkId      eventlogger.NodeID
+	gateId      eventlogger.NodeID
+	dedupId     eventlogger.NodeID
+	defaultsId  eventlogger.NodeID
+	limitId     eventlogger.NodeID
+	filterId    eventlogger.NodeID
+	rateLimitId eventlogger.NodeID
+	encryptId   eventlogger.NodeID
+	sinkNode    eventlogger.Node
+	sinkConfig  SinkConfig
+}
+
+// nodeIds returns the ids of the pipeline's nodes in order.
+func (p pipeline) nodeIds() []eventlogger.NodeID {
+	ids := make([]eventlogger.NodeID, 0, 8)
+	if p.gateId != "" {
+		ids = append(ids, p.gateId)
+	}
+	if p.dedupId != "" {
+		ids = append(ids, p.dedupId)
+	}
+	if p.defaultsId != "" {
+		ids = append(ids, p.defaultsId)
+	}
+	if p.limitId != "" {
+		ids = append(ids, p.limitId)
+	}
+	if p.filterId != "" {
+		ids = append(ids, p.filterId)
+	}
+	if p.rateLimitId != "" {
+		ids = append(ids, p.rateLimitId)
+	}
+	ids = append(ids, p.fmtId)
+	if p.encryptId != "" {
+		ids = append(ids, p.encryptId)
+	}
+	return append(ids, p.sinkId)
 }
 
 var (
@@ -124,29 +259,56 @@ func SysEventer() *Eventer {
 }
 
 // NewEventer creates a new Eventer using the config.  Supports options:
-// WithNow, WithSerializationLock, WithBroker
+// WithNow, WithSerializationLock, WithBroker, WithSchemaVersion,
+// WithKafkaProducer, WithCloudWatchClient, WithWrapper,
+// WithEncryptedObservations, WithWarningsHook, WithWarningsAsEvents,
+// WithDefaultFields, WithMaxEventBytes, WithDedupWindow, WithStderrWriter,
+// WithWriterGroup, WithStrictSerialization, WithRateLimit, WithNoBackoffJitter,
+// WithErrorStackTraces, WithCircuitBreaker, WithMaxPausedObservations,
+// WithErrorCoalescing, WithSendTimeout, WithSequenceNumbers,
+// WithNoDefaultSink and WithDedupeFileSinks
+//
+// When the config has no sinks, the DefaultSink is used and a warning is
+// logged and written as a system event, so the fallback is observable, unless
+// WithNoDefaultSink is used, in which case an error is returned.
+//
+// The serializationLock serializes the writes of the eventer's StderrSinks.
+// Every eventer in the process which writes to os.Stderr must be given the
+// same lock, usually StderrLock, otherwise their output may be interleaved;
+// a warning is logged when an open eventer has a different lock.
 func NewEventer(log hclog.Logger, serializationLock *sync.Mutex, c EventerConfig, opt ...Option) (*Eventer, error) {
 	const op = "event.NewEventer"
 	if log == nil {
-		return nil, fmt.Errorf("%s: missing logger: %w", op, ErrInvalidParameter)
+		return nil, fmt.Errorf("%s: missing logger: %w", op, ErrMissingLogger)
 	}
 	if serializationLock == nil {
 		return nil, fmt.Errorf("%s: missing serialization lock: %w", op, ErrInvalidParameter)
 	}
 
+	opts := getOpts(opt...)
+
 	// if there are no sinks in config, then we'll default to just one stderr
 	// sink.
+	var usingDefaultSink bool
 	if len(c.Sinks) == 0 {
-		c.Sinks = append(c.Sinks, DefaultSink())
+		if opts.withNoDefaultSink {
+			return nil, fmt.Errorf("%s: config has no sinks and the default sink is disabled: %w", op, ErrNoSinks)
+		}
+		defaultSink := DefaultSink()
+		log.Warn("no event sinks configured, falling back to the default sink", "sink", defaultSink.Name, "sink_type", defaultSink.SinkType)
+		c.Sinks = append(c.Sinks, defaultSink)
+		usingDefaultSink = true
+	}
+	if opts.withDedupeFileSinks {
+		c.Sinks = dedupeFileSinks(log, c.Sinks)
 	}
 
-	if err := c.Validate(); err != nil {
+	if err := c.validateForEventer(); err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	var auditPipelines, observationPipelines, errPipelines, sysPipelines []pipeline
+	var auditPipelines, observationPipelines, errPipelines, sysPipelines, customPipelines []pipeline
 
-	opts := getOpts(opt...)
 	var b broker
 	switch {
 	case opts.withBroker != nil:
@@ -155,16 +317,112 @@ func NewEventer(log hclog.Logger, serializationLock *sync.Mutex, c EventerConfig
 		b = eventlogger.NewBroker()
 	}
 
+	if opts.withEncryptedObservations && opts.withWrapper == nil {
+		return nil, fmt.Errorf("%s: encrypted observations require a wrapper: %w", op, ErrInvalidParameter)
+	}
+	if opts.withMaxEventBytes < 0 {
+		return nil, fmt.Errorf("%s: max event bytes must not be negative: %w", op, ErrInvalidParameter)
+	}
+	if opts.withDedupWindow < 0 {
+		return nil, fmt.Errorf("%s: dedup window must not be negative: %w", op, ErrInvalidParameter)
+	}
+	for name, g := range opts.withWriterGroups {
+		switch {
+		case name == "":
+			return nil, fmt.Errorf("%s: missing writer group name: %w", op, ErrInvalidParameter)
+		case g.w == nil:
+			return nil, fmt.Errorf("%s: writer group %q is missing a writer: %w", op, name, ErrInvalidParameter)
+		case g.l == nil:
+			return nil, fmt.Errorf("%s: writer group %q is missing a lock: %w", op, name, ErrInvalidParameter)
+		}
+	}
+	if opts.withMaxPausedObservations < 0 {
+		return nil, fmt.Errorf("%s: max paused observations must not be negative: %w", op, ErrInvalidParameter)
+	}
+	if opts.withErrorStackDepth < 0 {
+		return nil, fmt.Errorf("%s: error stack depth must not be negative: %w", op, ErrInvalidParameter)
+	}
+	if opts.withErrorCoalesceInterval < 0 {
+		return nil, fmt.Errorf("%s: error coalescing interval must not be negative: %w", op, ErrInvalidParameter)
+	}
+	if opts.withSendTimeout < 0 {
+		return nil, fmt.Errorf("%s: send timeout must not be negative: %w", op, ErrInvalidParameter)
+	}
+	for t, r := range opts.withRateLimits {
+		switch t {
+		case AuditType, ObservationType, ErrorType, SystemType:
+		default:
+			if _, ok := registeredType(t); !ok {
+				return nil, fmt.Errorf("%s: %s is not a valid rate limited event type: %w", op, t, ErrInvalidParameter)
+			}
+		}
+		if err := r.validate(); err != nil {
+			return nil, fmt.Errorf("%s: invalid %s rate limit: %w", op, t, err)
+		}
+	}
+	breaker := circuitBreaker{
+		threshold: defaultCircuitBreakerThreshold,
+		cooldown:  defaultCircuitBreakerCooldown,
+	}
+	if opts.withCircuitBreaker != nil {
+		if err := opts.withCircuitBreaker.validate(); err != nil {
+			return nil, fmt.Errorf("%s: invalid circuit breaker: %w", op, err)
+		}
+		breaker = *opts.withCircuitBreaker
+	}
+
 	e := &Eventer{
-		logger: log,
-		conf:   c,
-		broker: b,
+		logger:                log,
+		conf:                  c,
+		broker:                b,
+		schemaVersion:         SchemaVersion,
+		health:                newEventerHealth(),
+		warningsHook:          opts.withWarningsHook,
+		warningsAsEvents:      opts.withWarningsAsEvents,
+		maxEventBytes:         opts.withMaxEventBytes,
+		strictSerialization:   opts.withStrictSerialization,
+		noBackoffJitter:       opts.withNoBackoffJitter,
+		maxPausedObservations: opts.withMaxPausedObservations,
+		errorCoalesceInterval: opts.withErrorCoalesceInterval,
+		sendTimeout:           opts.withSendTimeout,
+		sequenceNumbers:       opts.withSequenceNumbers,
+		sensitiveHeaders:      newSensitiveHeaders(opts.withSensitiveHeaders, opts.withReplaceSensitive),
+	}
+	if opts.withSchemaVersion != "" {
+		e.schemaVersion = opts.withSchemaVersion
+	}
+	if opts.withErrorStackTraces {
+		e.errorStackDepth = opts.withErrorStackDepth
+		if e.errorStackDepth == 0 {
+			e.errorStackDepth = defaultErrorStackDepth
+		}
 	}
 
 	if !opts.withNow.IsZero() {
 		e.broker.StopTimeAt(opts.withNow)
 	}
 
+	// when the eventer can't be built, the pipelines already registered with
+	// the broker are removed and the sink nodes already created are closed,
+	// so a broker which was passed in isn't left half built and no sink
+	// resources leak.  The broker can't unregister nodes, but they aren't
+	// reachable once the pipelines are removed.
+	var registeredPipelines []eventlogger.Pipeline
+	var sinkNodes []eventlogger.Node
+	built := false
+	defer func() {
+		if !built {
+			e.rollback(registeredPipelines, sinkNodes)
+		}
+	}()
+	registerPipeline := func(def eventlogger.Pipeline) error {
+		if err := e.broker.RegisterPipeline(def); err != nil {
+			return err
+		}
+		registeredPipelines = append(registeredPipelines, def)
+		return nil
+	}
+
 	// Create JSONFormatter node
 	id, err := newId("json")
 	if err != nil {
@@ -177,54 +435,298 @@ func NewEventer(log hclog.Logger, serializationLock *sync.Mutex, c EventerConfig
 		return nil, fmt.Errorf("%s: failed to register json node: %w", op, err)
 	}
 
+	// the other formatter nodes are only registered when a sink requires
+	// them, and they're shared by every sink using the same format
+	fmtIds := map[string]eventlogger.NodeID{
+		eventlogger.JSONFormat: jsonfmtId,
+	}
+	formatterId := func(format string) (eventlogger.NodeID, error) {
+		if id, ok := fmtIds[format]; ok {
+			return id, nil
+		}
+		node, err := newFormatterNode(format)
+		if err != nil {
+			return "", err
+		}
+		id, err := newId(format)
+		if err != nil {
+			return "", err
+		}
+		if err := e.broker.RegisterNode(eventlogger.NodeID(id), node); err != nil {
+			return "", fmt.Errorf("failed to register %s node: %w", format, err)
+		}
+		fmtIds[format] = eventlogger.NodeID(id)
+		return eventlogger.NodeID(id), nil
+	}
+
+	// the default fields node is only registered when default fields are
+	// provided, and it's shared by every pipeline
+	var defaultsId eventlogger.NodeID
+	if opts.withDefaultFields != nil {
+		defaultsNode, err := newDefaultFieldsNode(opts.withDefaultFields)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		id, err = newId("default-fields")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		defaultsId = eventlogger.NodeID(id)
+		if err := e.broker.RegisterNode(defaultsId, defaultsNode); err != nil {
+			return nil, fmt.Errorf("%s: failed to register default fields node: %w", op, err)
+		}
+	}
+
+	// the size limit node is only registered when a max event bytes is
+	// provided, and it's shared by the audit and observation pipelines
+	var limitId eventlogger.NodeID
+	if opts.withMaxEventBytes > 0 {
+		limitNode, err := newSizeLimitNode(opts.withMaxEventBytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		id, err = newId("size-limit")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		limitId = eventlogger.NodeID(id)
+		if err := e.broker.RegisterNode(limitId, limitNode); err != nil {
+			return nil, fmt.Errorf("%s: failed to register size limit node: %w", op, err)
+		}
+	}
+
+	// encryption nodes are only registered when a wrapper is provided, and
+	// there's one for each format written by an encrypted file sink
+	encryptIds := map[string]eventlogger.NodeID{}
+
 	// serializedStderr will be shared among all StderrSinks so their output is not
-	// interwoven
+	// interwoven, unless WithUnserializedStderr bypasses its lock
 	serializedStderr := serializedWriter{
 		w: os.Stderr,
 		l: serializationLock,
 	}
+	if opts.withStderrWriter != nil {
+		serializedStderr.w = opts.withStderrWriter
+	}
+	e.stderr = &serializedStderr
+	if opts.withUnserializedStderr {
+		e.stderr = serializedStderr.w
+	}
+	// writerGroups are shared among the StderrSinks in each group, which
+	// don't contend with stderr's lock or the other groups' locks
+	writerGroups := make(map[string]*serializedWriter, len(opts.withWriterGroups))
+	for name, g := range opts.withWriterGroups {
+		g := g
+		writerGroups[name] = &g
+	}
 
-	// we need to keep track of all the Sink filenames to ensure they aren't
-	// reused.
-	allSinkFilenames := map[string]bool{}
+	// newBreakerNode wraps a network sink's node in its circuit breaker,
+	// along with the sink's spool when it has one.
+	newBreakerNode := func(s SinkConfig, sinkFormat string, node eventlogger.Node, enforced bool) (*circuitBreakerSink, error) {
+		breakerNode, err := newCircuitBreakerSink(e, s.Name, node, breaker, enforced)
+		if err != nil {
+			return nil, err
+		}
+		sp, err := newSinkSpool(s)
+		if err != nil {
+			if c, ok := node.(io.Closer); ok {
+				_ = c.Close()
+			}
+			return nil, err
+		}
+		if sp != nil {
+			breakerNode.withSpool(sp, sinkFormat)
+		}
+		return breakerNode, nil
+	}
 
-	for _, s := range c.Sinks {
-		var sinkId eventlogger.NodeID
-		var sinkNode eventlogger.Node
+	// newSinkNode returns a new sink node for the sink config, along with
+	// the prefix of its node id.
+	newSinkNode := func(s SinkConfig, sinkFormat string) (eventlogger.Node, string, error) {
 		switch s.SinkType {
 		case StderrSink:
-			sinkNode = &writer.Sink{
-				Format: string(s.Format),
-				Writer: &serializedStderr,
+			if s.WriterGroup != "" {
+				g, ok := writerGroups[s.WriterGroup]
+				if !ok {
+					return nil, "", fmt.Errorf("stderr sink %s requires writer group %q: %w", s.Name, s.WriterGroup, ErrInvalidParameter)
+				}
+				return &writer.Sink{
+					Format: sinkFormat,
+					Writer: g,
+				}, fmt.Sprintf("stderr_%s_", s.WriterGroup), nil
 			}
-			id, err = newId("stderr")
+			return &writer.Sink{
+				Format: sinkFormat,
+				Writer: e.stderr,
+			}, "stderr", nil
+		case DiscardSink:
+			return &writer.Sink{
+				Format: sinkFormat,
+				Writer: io.Discard,
+			}, "discard", nil
+		case KafkaSink:
+			sinkNode, err := newKafkaSink(e, s.KafkaConfig, sinkFormat, opts.withKafkaProducer)
 			if err != nil {
-				return nil, fmt.Errorf("%s: %w", op, err)
+				return nil, "", err
+			}
+			breakerNode, err := newBreakerNode(s, sinkFormat, sinkNode, s.KafkaConfig.DeliveryGuarantee == Enforced)
+			if err != nil {
+				return nil, "", err
+			}
+			return breakerNode, fmt.Sprintf("kafka_%s_", s.KafkaConfig.Topic), nil
+		case CloudWatchSink:
+			sinkNode, err := newCloudWatchSink(e, s.CloudWatchConfig, sinkFormat, opts.withCloudWatchClient)
+			if err != nil {
+				return nil, "", err
+			}
+			breakerNode, err := newBreakerNode(s, sinkFormat, sinkNode, false)
+			if err != nil {
+				return nil, "", err
+			}
+			return breakerNode, fmt.Sprintf("cloudwatch_%s_%s_", s.CloudWatchConfig.LogGroup, s.CloudWatchConfig.LogStream), nil
+		case GRPCSink:
+			sinkNode, err := newGRPCSink(e, s.GRPCConfig, sinkFormat, string(s.Format))
+			if err != nil {
+				return nil, "", err
+			}
+			breakerNode, err := newBreakerNode(s, sinkFormat, sinkNode, s.GRPCConfig.DeliveryGuarantee == Enforced)
+			if err != nil {
+				return nil, "", err
 			}
-			sinkId = eventlogger.NodeID(id)
+			return breakerNode, fmt.Sprintf("grpc_%s_", s.GRPCConfig.Address), nil
 		default:
-			if _, found := allSinkFilenames[s.Path+s.FileName]; found {
-				return nil, fmt.Errorf("%s: duplicate file sink: %s %s", op, s.Path, s.FileName)
+			fs, err := newConfiguredFileSink(s, sinkFormat)
+			if err != nil {
+				return nil, "", err
+			}
+			if opts.withPathConfirmation {
+				fs.sinkName = s.Name
+				fs.pathConfirmations = &e.pathConfirmations
+			}
+			if fs.batchSize > 1 {
+				e.flushableSinks = append(e.flushableSinks, fs)
+			}
+			if s.RotateSchedule != "" {
+				schedule, err := parseRotateSchedule(s.RotateSchedule)
+				if err != nil {
+					return nil, "", err
+				}
+				rs, err := newRotationScheduler(s.Name, schedule, fs, e.logger)
+				if err != nil {
+					return nil, "", err
+				}
+				fs.scheduled = true
+				e.rotationSchedulers = append(e.rotationSchedulers, rs)
+			}
+			return fs, fmt.Sprintf("file_%s_%s_", s.Path, s.FileName), nil
+		}
+	}
+
+	// each sink with a file name template is materialized as a file sink
+	// for each of its event types
+	sinks, err := expandSinks(c.Sinks)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	for _, s := range sortedSinks(sinks) {
+		sinkFormat := s.formatKey()
+		var fmtId eventlogger.NodeID
+		switch {
+		case len(s.FieldNameMap) > 0:
+			// the sink's output is renamed, so it has its own formatter
+			// whose output is only written by the sink
+			id, err = newId("json-field-names")
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", op, err)
 			}
-			sinkNode = &eventlogger.FileSink{
-				Format:      string(s.Format),
-				Path:        s.Path,
-				FileName:    s.FileName,
-				MaxBytes:    s.RotateBytes,
-				MaxDuration: s.RotateDuration,
-				MaxFiles:    s.RotateMaxFiles,
+			fmtId = eventlogger.NodeID(id)
+			sinkFormat += fieldNamesFormatSuffix + id
+			fmtNode := &jsonFormatter{
+				pretty:        s.JSONPretty,
+				epochMillis:   s.TimestampFormat == EpochMillisTimestampFormat,
+				sortedKeys:    s.SortedKeys,
+				fieldNames:    s.FieldNameMap,
+				fieldNamesKey: sinkFormat,
 			}
-			id, err = newId(fmt.Sprintf("file_%s_%s_", s.Path, s.FileName))
+			if err := e.broker.RegisterNode(fmtId, fmtNode); err != nil {
+				return nil, fmt.Errorf("%s: failed to register json field names node: %w", op, err)
+			}
+		default:
+			fmtId, err = formatterId(sinkFormat)
 			if err != nil {
 				return nil, fmt.Errorf("%s: %w", op, err)
 			}
-			sinkId = eventlogger.NodeID(id)
 		}
-		err = e.broker.RegisterNode(sinkId, sinkNode)
+		sinkNode, idPrefix, err := newSinkNode(s, sinkFormat)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		sinkNodes = append(sinkNodes, sinkNode)
+		if len(s.Mirrors) > 0 {
+			mirrors := make([]*mirror, 0, len(s.Mirrors))
+			for _, m := range s.mirrorConfigs() {
+				mirrorNode, _, err := newSinkNode(m, sinkFormat)
+				if err != nil {
+					return nil, fmt.Errorf("%s: mirror %q: %w", op, m.Name, err)
+				}
+				sinkNodes = append(sinkNodes, mirrorNode)
+				mr, err := newMirror(e, m.Name, mirrorNode)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", op, err)
+				}
+				mirrors = append(mirrors, mr)
+			}
+			sinkNode = &mirrorSink{
+				primary: sinkNode,
+				mirrors: mirrors,
+			}
+		}
+		id, err = newId(idPrefix)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		sinkId := eventlogger.NodeID(id)
+		// the broker's node records the sink's outcomes for SendResults
+		err = e.broker.RegisterNode(sinkId, &resultSink{name: s.Name, node: sinkNode})
 		if err != nil {
 			return nil, fmt.Errorf("%s: failed to register sink node %s: %w", op, sinkId, err)
 		}
+		var filterId eventlogger.NodeID
+		filterNode, err := newSinkFilter(s.AllowFilters, s.DenyFilters, s.OpPrefixes, s.ScopeIds, s.DropMissingScope)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if filterNode != nil {
+			id, err = newId("filter")
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+			filterId = eventlogger.NodeID(id)
+			if err := e.broker.RegisterNode(filterId, filterNode); err != nil {
+				return nil, fmt.Errorf("%s: failed to register filter node: %w", op, err)
+			}
+		}
+		var encryptId eventlogger.NodeID
+		if opts.withWrapper != nil && s.SinkType == FileSink {
+			var found bool
+			if encryptId, found = encryptIds[sinkFormat]; !found {
+				encryptNode, err := newEncryptNode(opts.withWrapper, sinkFormat)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", op, err)
+				}
+				id, err = newId("encrypt")
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", op, err)
+				}
+				encryptId = eventlogger.NodeID(id)
+				if err := e.broker.RegisterNode(encryptId, encryptNode); err != nil {
+					return nil, fmt.Errorf("%s: failed to register encrypt node: %w", op, err)
+				}
+				encryptIds[sinkFormat] = encryptId
+			}
+		}
 		var addToAudit, addToObservation, addToErr, addToSys bool
+		addToCustom := map[Type]bool{}
 		for _, t := range s.EventTypes {
 			switch t {
 			case EveryType:
@@ -240,48 +742,123 @@ func NewEventer(log hclog.Logger, serializationLock *sync.Mutex, c EventerConfig
 				addToObservation = true
 			case SystemType:
 				addToSys = true
+			default:
+				// the sink's types were validated, so any other type is a
+				// registered custom type
+				addToCustom[t] = true
 			}
 		}
+		sinkCustomTypes := sortedTypes(addToCustom)
+		info := SinkInfo{
+			Name:   s.Name,
+			Type:   s.SinkType,
+			Format: s.Format,
+		}
+		if addToAudit {
+			info.EventTypes = append(info.EventTypes, AuditType)
+		}
+		if addToObservation {
+			info.EventTypes = append(info.EventTypes, ObservationType)
+		}
+		if addToErr {
+			info.EventTypes = append(info.EventTypes, ErrorType)
+		}
+		if addToSys {
+			info.EventTypes = append(info.EventTypes, SystemType)
+		}
+		info.EventTypes = append(info.EventTypes, sinkCustomTypes...)
+		e.sinks = append(e.sinks, info)
 		if addToAudit {
 			auditPipelines = append(auditPipelines, pipeline{
 				eventType:  AuditType,
-				fmtId:      jsonfmtId,
+				fmtId:      fmtId,
+				defaultsId: defaultsId,
+				limitId:    limitId,
+				filterId:   filterId,
+				encryptId:  encryptId,
 				sinkId:     sinkId,
+				sinkNode:   sinkNode,
 				sinkConfig: s,
 			})
 		}
 		if addToObservation {
-			observationPipelines = append(observationPipelines, pipeline{
+			p := pipeline{
 				eventType:  ObservationType,
-				fmtId:      jsonfmtId,
+				fmtId:      fmtId,
+				defaultsId: defaultsId,
+				limitId:    limitId,
+				filterId:   filterId,
 				sinkId:     sinkId,
+				sinkNode:   sinkNode,
 				sinkConfig: s,
-			})
+			}
+			if opts.withEncryptedObservations {
+				p.encryptId = encryptId
+			}
+			observationPipelines = append(observationPipelines, p)
 		}
 		if addToErr {
 			errPipelines = append(errPipelines, pipeline{
 				eventType:  ErrorType,
-				fmtId:      jsonfmtId,
+				fmtId:      fmtId,
+				defaultsId: defaultsId,
+				filterId:   filterId,
 				sinkId:     sinkId,
+				sinkNode:   sinkNode,
 				sinkConfig: s,
 			})
 		}
 		if addToSys {
 			sysPipelines = append(sysPipelines, pipeline{
-				eventType: SystemType,
-				fmtId:     jsonfmtId,
-				sinkId:    sinkId,
+				eventType:  SystemType,
+				fmtId:      fmtId,
+				defaultsId: defaultsId,
+				filterId:   filterId,
+				sinkId:     sinkId,
+				sinkNode:   sinkNode,
+				sinkConfig: s,
+			})
+		}
+		for _, t := range sinkCustomTypes {
+			customPipelines = append(customPipelines, pipeline{
+				eventType:  t,
+				fmtId:      fmtId,
+				defaultsId: defaultsId,
+				filterId:   filterId,
+				sinkId:     sinkId,
+				sinkNode:   sinkNode,
+				sinkConfig: s,
 			})
 		}
 	}
-	if c.AuditEnabled && len(auditPipelines) == 0 {
-		return nil, fmt.Errorf("%s: audit events enabled but no sink defined for it: %w", op, ErrInvalidParameter)
-	}
-	if c.ObservationsEnabled && len(observationPipelines) == 0 {
-		return nil, fmt.Errorf("%s: observation events enabled but no sink defined for it: %w", op, ErrInvalidParameter)
+	if c.FlushEachAudit {
+		e.auditGates = make(map[eventlogger.PipelineID]flushable, len(auditPipelines))
 	}
-	if c.SysEventsEnabled && len(sysPipelines) == 0 {
-		return nil, fmt.Errorf("%s: system events enabled but no sink defined for it: %w", op, ErrInvalidParameter)
+
+	// registerRateLimitNode registers a rate limit node for a pipeline when
+	// its event type has a rate limit.  Like the dedup nodes, each pipeline
+	// requires its own rate limit node.
+	registerRateLimitNode := func(t Type) (eventlogger.NodeID, error) {
+		r, ok := opts.withRateLimits[t]
+		if !ok {
+			return "", nil
+		}
+		limiter, err := newRateLimitNode(t, r)
+		if err != nil {
+			return "", err
+		}
+		id, err := newId("rate-limit")
+		if err != nil {
+			return "", err
+		}
+		if err := e.broker.RegisterNode(eventlogger.NodeID(id), limiter); err != nil {
+			return "", fmt.Errorf("failed to register rate limit node: %w", err)
+		}
+		if e.rateLimitNodes == nil {
+			e.rateLimitNodes = map[Type][]*rateLimitNode{}
+		}
+		e.rateLimitNodes[t] = append(e.rateLimitNodes[t], limiter)
+		return eventlogger.NodeID(id), nil
 	}
 
 	for _, p := range auditPipelines {
@@ -297,19 +874,46 @@ func NewEventer(log hclog.Logger, serializationLock *sync.Mutex, c EventerConfig
 		if err := e.broker.RegisterNode(p.gateId, &gatedFilterNode); err != nil {
 			return nil, fmt.Errorf("%s: unable to register audit gated filter: %w", op, err)
 		}
+		if p.rateLimitId, err = registerRateLimitNode(p.eventType); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
 
 		pipeId, err := newId(auditPipeline)
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
 		}
-		err = e.broker.RegisterPipeline(eventlogger.Pipeline{
+		err = registerPipeline(eventlogger.Pipeline{
 			EventType:  eventlogger.EventType(p.eventType),
 			PipelineID: eventlogger.PipelineID(pipeId),
-			NodeIDs:    []eventlogger.NodeID{p.gateId, p.fmtId, p.sinkId},
+			NodeIDs:    p.nodeIds(),
 		})
 		if err != nil {
 			return nil, fmt.Errorf("%s: failed to register audit pipeline: %w", op, err)
 		}
+		if e.auditGates != nil {
+			e.auditGates[eventlogger.PipelineID(pipeId)] = &gatedFilterNode
+		}
+	}
+
+	// registerDedupNode registers a dedup node for a pipeline when a dedup
+	// window is provided.  Unlike the other filter nodes, each pipeline
+	// requires its own dedup node.
+	registerDedupNode := func() (eventlogger.NodeID, error) {
+		if opts.withDedupWindow == 0 {
+			return "", nil
+		}
+		dedupNode, err := newDedupNode(opts.withDedupWindow)
+		if err != nil {
+			return "", err
+		}
+		id, err := newId("dedup")
+		if err != nil {
+			return "", err
+		}
+		if err := e.broker.RegisterNode(eventlogger.NodeID(id), dedupNode); err != nil {
+			return "", fmt.Errorf("failed to register dedup node: %w", err)
+		}
+		return eventlogger.NodeID(id), nil
 	}
 
 	for _, p := range observationPipelines {
@@ -325,15 +929,21 @@ func NewEventer(log hclog.Logger, serializationLock *sync.Mutex, c EventerConfig
 		if err := e.broker.RegisterNode(p.gateId, &gatedFilterNode); err != nil {
 			return nil, fmt.Errorf("%s: unable to register audit gated filter: %w", op, err)
 		}
+		if p.dedupId, err = registerDedupNode(); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if p.rateLimitId, err = registerRateLimitNode(p.eventType); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
 
 		pipeId, err := newId(observationPipeline)
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
 		}
-		err = e.broker.RegisterPipeline(eventlogger.Pipeline{
+		err = registerPipeline(eventlogger.Pipeline{
 			EventType:  eventlogger.EventType(p.eventType),
 			PipelineID: eventlogger.PipelineID(pipeId),
-			NodeIDs:    []eventlogger.NodeID{p.gateId, p.fmtId, p.sinkId},
+			NodeIDs:    p.nodeIds(),
 		})
 		if err != nil {
 			return nil, fmt.Errorf("%s: failed to register observation pipeline: %w", op, err)
@@ -341,14 +951,18 @@ func NewEventer(log hclog.Logger, serializationLock *sync.Mutex, c EventerConfig
 	}
 	errNodeIds := make([]eventlogger.NodeID, 0, len(errPipelines))
 	for _, p := range errPipelines {
+		p.rateLimitId, err = registerRateLimitNode(p.eventType)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
 		pipeId, err := newId(errPipeline)
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
 		}
-		err = e.broker.RegisterPipeline(eventlogger.Pipeline{
+		err = registerPipeline(eventlogger.Pipeline{
 			EventType:  eventlogger.EventType(p.eventType),
 			PipelineID: eventlogger.PipelineID(pipeId),
-			NodeIDs:    []eventlogger.NodeID{p.fmtId, p.sinkId},
+			NodeIDs:    p.nodeIds(),
 		})
 		if err != nil {
 			return nil, fmt.Errorf("%s: failed to register err pipeline: %w", op, err)
@@ -357,40 +971,182 @@ func NewEventer(log hclog.Logger, serializationLock *sync.Mutex, c EventerConfig
 	}
 	sysNodeIds := make([]eventlogger.NodeID, 0, len(sysPipelines))
 	for _, p := range sysPipelines {
+		p.dedupId, err = registerDedupNode()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		p.rateLimitId, err = registerRateLimitNode(p.eventType)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
 		pipeId, err := newId(sysPipeline)
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
 		}
-		err = e.broker.RegisterPipeline(eventlogger.Pipeline{
+		err = registerPipeline(eventlogger.Pipeline{
 			EventType:  eventlogger.EventType(p.eventType),
 			PipelineID: eventlogger.PipelineID(pipeId),
-			NodeIDs:    []eventlogger.NodeID{p.fmtId, p.sinkId},
+			NodeIDs:    p.nodeIds(),
 		})
 		if err != nil {
 			return nil, fmt.Errorf("%s: failed to register sys pipeline: %w", op, err)
 		}
 		sysNodeIds = append(sysNodeIds, p.sinkId)
 	}
+	customNodeIds := map[Type][]eventlogger.NodeID{}
+	for _, p := range customPipelines {
+		p.dedupId, err = registerDedupNode()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		p.rateLimitId, err = registerRateLimitNode(p.eventType)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		pipeId, err := newId(customPipeline)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		err = registerPipeline(eventlogger.Pipeline{
+			EventType:  eventlogger.EventType(p.eventType),
+			PipelineID: eventlogger.PipelineID(pipeId),
+			NodeIDs:    p.nodeIds(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to register %s pipeline: %w", op, p.eventType, err)
+		}
+		customNodeIds[p.eventType] = append(customNodeIds[p.eventType], p.sinkId)
+	}
 
 	// always enforce delivery of errors
 	err = e.broker.SetSuccessThreshold(eventlogger.EventType(ErrorType), len(errNodeIds))
 	if err != nil {
 		return nil, fmt.Errorf("%s: failed to set success threshold for error events: %w", op, err)
 	}
+	for t, nodeIds := range customNodeIds {
+		if !enforcedDelivery(t) {
+			continue
+		}
+		if err := e.broker.SetSuccessThreshold(eventlogger.EventType(t), len(nodeIds)); err != nil {
+			return nil, fmt.Errorf("%s: failed to set success threshold for %s events: %w", op, t, err)
+		}
+	}
 
 	e.auditPipelines = append(e.auditPipelines, auditPipelines...)
 	e.errPipelines = append(e.errPipelines, errPipelines...)
 	e.observationPipelines = append(e.observationPipelines, observationPipelines...)
+	e.sysPipelines = append(e.sysPipelines, sysPipelines...)
+	e.customPipelines = append(e.customPipelines, customPipelines...)
+
+	for _, rs := range e.rotationSchedulers {
+		rs.start()
+	}
+
+	// eventers which write to os.Stderr must share a lock, otherwise their
+	// output is interleaved (see StderrLock)
+	if opts.withStderrWriter == nil && !opts.withUnserializedStderr && writesStderr(sinks) {
+		e.stderrLock = serializationLock
+		if !openStderrLocks.add(serializationLock) {
+			e.logger.Warn("eventers which write to stderr have different serialization locks, so their output may be interleaved")
+		}
+	}
 
+	built = true
+
+	if usingDefaultSink && e.conf.SysEventsEnabled {
+		e.sendDefaultSinkEvent(context.Background())
+	}
 	return e, nil
 }
 
+// sendDefaultSinkEvent sends a system event which records that the eventer's
+// config had no sinks, so it fell back to the DefaultSink.  Failures are only
+// logged, since the fallback was already logged as a warning.
+func (e *Eventer) sendDefaultSinkEvent(ctx context.Context) {
+	const op = "event.(Eventer).sendDefaultSinkEvent"
+	id, err := newId(string(SystemType))
+	if err != nil {
+		e.logger.Error("unable to generate id for default sink system event", "error", err.Error())
+		return
+	}
+	sink := DefaultSink()
+	event := &sysEvent{
+		Id:      Id(id),
+		Version: sysVersion,
+		Op:      op,
+		Data: map[string]interface{}{
+			"msg":       "no event sinks configured, falling back to the default sink",
+			"sink":      sink.Name,
+			"sink_type": string(sink.SinkType),
+		},
+	}
+	if err := e.writeSysEvent(ctx, event); err != nil {
+		e.logger.Error("unable to send default sink system event", "error", err.Error())
+	}
+}
+
+// rollback undoes a NewEventer which failed: the pipelines it registered are
+// removed from the broker, in reverse order, and the sink nodes it created are
+// closed.  Failures are only logged, since the error which caused the rollback
+// is the one NewEventer returns.
+func (e *Eventer) rollback(pipelines []eventlogger.Pipeline, sinkNodes []eventlogger.Node) {
+	for i := len(pipelines) - 1; i >= 0; i-- {
+		p := pipelines[i]
+		if err := e.broker.RemovePipeline(p.EventType, p.PipelineID); err != nil {
+			e.logger.Error("unable to remove pipeline of a failed eventer", "pipeline", p.PipelineID, "error", err.Error())
+		}
+	}
+	for _, n := range sinkNodes {
+		if c, ok := n.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				e.logger.Error("unable to close sink of a failed eventer", "error", err.Error())
+			}
+		}
+	}
+}
+
+// newFormatterNode returns a new formatter node for the format of a sink (see
+// SinkConfig.formatKey).
+func newFormatterNode(format string) (eventlogger.Node, error) {
+	switch format {
+	case eventlogger.JSONFormat:
+		return &eventlogger.JSONFormatter{}, nil
+	case jsonPrettyFormat:
+		return &jsonFormatter{pretty: true}, nil
+	case eventlogger.JSONFormat + epochMillisFormatSuffix:
+		return &jsonFormatter{epochMillis: true}, nil
+	case jsonPrettyFormat + epochMillisFormatSuffix:
+		return &jsonFormatter{pretty: true, epochMillis: true}, nil
+	case eventlogger.JSONFormat + sortedKeysFormatSuffix,
+		jsonPrettyFormat + sortedKeysFormatSuffix,
+		eventlogger.JSONFormat + epochMillisFormatSuffix + sortedKeysFormatSuffix,
+		jsonPrettyFormat + epochMillisFormatSuffix + sortedKeysFormatSuffix:
+		return &jsonFormatter{
+			pretty:      strings.HasPrefix(format, jsonPrettyFormat),
+			epochMillis: strings.Contains(format, epochMillisFormatSuffix),
+			sortedKeys:  true,
+		}, nil
+	case string(TextSinkFormat) + epochMillisFormatSuffix:
+		return &textFormatter{epochMillis: true}, nil
+	case string(CEFSinkFormat):
+		return newCEFFormatter(), nil
+	case string(MsgpackSinkFormat):
+		return &msgpackFormatter{}, nil
+	case string(TextSinkFormat):
+		return &textFormatter{}, nil
+	case string(ProtoSinkFormat):
+		return &protoFormatter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q: %w", format, ErrInvalidParameter)
+	}
+}
+
 func DefaultEventerConfig() *EventerConfig {
 	return &EventerConfig{
 		AuditEnabled:        false,
 		ObservationsEnabled: true,
 		SysEventsEnabled:    true,
-		Sinks:               []SinkConfig{DefaultSink()},
+		Sinks:               []SinkConfig{DefaultSinkFromEnv()},
 	}
 }
 
@@ -409,19 +1165,109 @@ func (e *Eventer) writeObservation(ctx context.Context, event *observation) erro
 	if event == nil {
 		return fmt.Errorf("%s: missing event: %w", op, ErrInvalidParameter)
 	}
+	e.closeLock.RLock()
+	defer e.closeLock.RUnlock()
+	if e.closed {
+		return fmt.Errorf("%s: %w", op, ErrEventerClosed)
+	}
 	if !e.conf.ObservationsEnabled {
 		return nil
 	}
-	err := e.retrySend(ctx, stdRetryCount, expBackoff{}, func() (eventlogger.Status, error) {
-		if event.Header != nil {
-			event.Header[RequestInfoField] = event.RequestInfo
-			event.Header[VersionField] = event.Version
+	if !event.Level.atLeast(e.conf.ObservationLevel) {
+		return nil
+	}
+	event.Version = e.schemaVersion
+	event.Payload.Header = scrubHeader(event.Payload.Header, e.sensitiveHeaders)
+	if ok, err := e.checkSerialization(ObservationType, event.Payload); !ok {
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	}
+	if e.bufferObservation(ctx, event) {
+		return nil
+	}
+	if err := e.sendObservation(ctx, event); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// send passes the payload through the eventer's middleware (see Use) and
+// sends the payload it returns to the broker.  When the eventer has a send
+// timeout (see WithSendTimeout), the send is given a ctx derived from ctx with
+// the timeout, and ErrSendTimeout is returned when the timeout elapses before
+// the event is sent.  Best effort events aren't retried once they've timed
+// out, so they don't tie up their writer, while audit and error events are
+// still retried until ctx is done.
+//
+// The paths of the file sinks which were first written while the payload was
+// sent are then sent too (see WithPathConfirmation).
+func (e *Eventer) send(ctx context.Context, t Type, payload interface{}) (eventlogger.Status, error) {
+	const op = "event.(Eventer).send"
+	defer e.sendPathConfirmations()
+	payload, ok, err := e.applyMiddleware(ctx, t, payload)
+	switch {
+	case err != nil:
+		// a middleware's rejection won't change when the send is retried
+		return eventlogger.Status{}, &permanentError{err: fmt.Errorf("%s: %w", op, err)}
+	case !ok:
+		return eventlogger.Status{}, nil
+	}
+	if e.sendTimeout == 0 {
+		return e.broker.Send(ctx, eventlogger.EventType(t), payload)
+	}
+	sendCtx, cancel := context.WithTimeout(ctx, e.sendTimeout)
+	defer cancel()
+	status, err := e.broker.Send(sendCtx, eventlogger.EventType(t), payload)
+	if err == nil || ctx.Err() != nil || sendCtx.Err() != context.DeadlineExceeded {
+		return status, err
+	}
+	err = fmt.Errorf("%s: %s event not sent within %s: %w", op, t, e.sendTimeout, ErrSendTimeout)
+	if !enforcedDelivery(t) {
+		return status, &permanentError{err: err}
+	}
+	return status, err
+}
+
+// sendObservation sends an Observation event to the broker, retrying failed
+// sends.
+func (e *Eventer) sendObservation(ctx context.Context, event *observation) error {
+	const op = "event.(Eventer).sendObservation"
+	var status eventlogger.Status
+	recorder, _ := sendRecorderFromContext(ctx)
+	var seq uint64
+	if event.Flush {
+		// only flushed observations are emitted, so only they're numbered
+		seq = e.nextSeq()
+	}
+	err := e.retrySend(ctx, stdRetryCount, e.retryBackoff(), func() (eventlogger.Status, error) {
+		if recorder != nil {
+			recorder.reset()
+		}
+		if event.Header == nil {
+			event.Header = map[string]interface{}{}
+		}
+		if seq != 0 {
+			event.Header[SeqField] = seq
+		}
+		event.Header[RequestInfoField] = event.RequestInfo
+		event.Header[VersionField] = event.Version
+		if event.Level != DefaultObservationLevel {
+			event.Header[LevelField] = event.Level
+		}
+		if id, ok := CorrelationIdFromContext(ctx); ok {
+			event.Header[CorrelationIdField] = id
 		}
 		if event.Detail != nil {
 			event.Detail[OpField] = string(event.Op)
 		}
-		return e.broker.Send(ctx, eventlogger.EventType(ObservationType), event.Payload)
+		var sendErr error
+		status, sendErr = e.send(ctx, ObservationType, event.Payload)
+		return status, sendErr
 	})
+	e.handleWarnings(ctx, ObservationType, status)
+	e.health.record(ObservationType, err)
 	if err != nil {
 		e.logger.Error("encountered an error sending an observation event", "error:", err.Error())
 		return fmt.Errorf("%s: %w", op, err)
@@ -435,11 +1281,58 @@ func (e *Eventer) writeError(ctx context.Context, event *err) error {
 	if event == nil {
 		return fmt.Errorf("%s: missing event: %w", op, ErrInvalidParameter)
 	}
-	err := e.retrySend(ctx, stdRetryCount, expBackoff{}, func() (eventlogger.Status, error) {
-		return e.broker.Send(ctx, eventlogger.EventType(ErrorType), event)
+	// the stack is captured first, so it has the fewest frames to skip
+	if e.errorStackDepth > 0 && event.Stack == nil {
+		event.Stack = errorStack(e.errorStackDepth)
+	}
+	e.closeLock.RLock()
+	defer e.closeLock.RUnlock()
+	if e.closed {
+		return fmt.Errorf("%s: %w", op, ErrEventerClosed)
+	}
+	event.Version = e.schemaVersion
+	if id, ok := CorrelationIdFromContext(ctx); ok && event.CorrelationId == "" {
+		event.CorrelationId = id
+	}
+	if ok, err := e.checkSerialization(ErrorType, event); !ok {
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	}
+	if e.errorCoalesceInterval > 0 {
+		if err := e.coalesceError(ctx, event); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	}
+	if err := e.sendError(ctx, event); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// sendError sends the error event.  The caller must hold the eventer's
+// closeLock.
+func (e *Eventer) sendError(ctx context.Context, event *err) error {
+	const op = "event.(Eventer).sendError"
+	event.Seq = e.nextSeq()
+	var status eventlogger.Status
+	err := e.retrySend(ctx, stdRetryCount, e.retryBackoff(), func() (eventlogger.Status, error) {
+		var sendErr error
+		status, sendErr = e.send(ctx, ErrorType, event)
+		return status, sendErr
 	})
+	e.handleWarnings(ctx, ErrorType, status)
+	e.health.record(ErrorType, err)
 	if err != nil {
-		e.logger.Error("encountered an error sending an error event", "error:", err.Error())
+		if ctx.Err() != nil {
+			// the ctx is done (most likely because we're shutting down), so
+			// the error event is written to the logger rather than lost.
+			e.logger.Error("unable to send error event before the context was done", "op", event.Op, "id", event.Id, "correlation_id", event.CorrelationId, "error", event.Error)
+		} else {
+			e.logger.Error("encountered an error sending an error event", "error:", err.Error())
+		}
 		return fmt.Errorf("%s: %w", op, err)
 	}
 	return nil
@@ -451,9 +1344,43 @@ func (e *Eventer) writeSysEvent(ctx context.Context, event *sysEvent) error {
 	if event == nil {
 		return fmt.Errorf("%s: missing event: %w", op, ErrInvalidParameter)
 	}
-	err := e.retrySend(ctx, stdRetryCount, expBackoff{}, func() (eventlogger.Status, error) {
-		return e.broker.Send(ctx, eventlogger.EventType(SystemType), event)
+	e.closeLock.RLock()
+	defer e.closeLock.RUnlock()
+	if e.closed {
+		return fmt.Errorf("%s: %w", op, ErrEventerClosed)
+	}
+	if err := e.sendSysEvent(ctx, event); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// sendSysEvent sends a sysEvent to the broker, retrying failed sends.  The
+// caller must hold the eventer's closeLock.
+func (e *Eventer) sendSysEvent(ctx context.Context, event *sysEvent) error {
+	const op = "event.(Eventer).sendSysEvent"
+	event.Version = e.schemaVersion
+	if id, ok := CorrelationIdFromContext(ctx); ok && event.CorrelationId == "" {
+		event.CorrelationId = id
+	}
+	if err := event.validate(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if ok, err := e.checkSerialization(SystemType, event); !ok {
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	}
+	event.Seq = e.nextSeq()
+	var status eventlogger.Status
+	err := e.retrySend(ctx, stdRetryCount, e.retryBackoff(), func() (eventlogger.Status, error) {
+		var sendErr error
+		status, sendErr = e.send(ctx, SystemType, event)
+		return status, sendErr
 	})
+	e.handleWarnings(ctx, SystemType, status)
+	e.health.record(SystemType, err)
 	if err != nil {
 		e.logger.Error("encountered an error sending an sys event", "error:", err.Error())
 		return fmt.Errorf("%s: %w", op, err)
@@ -461,36 +1388,398 @@ func (e *Eventer) writeSysEvent(ctx context.Context, event *sysEvent) error {
 	return nil
 }
 
+// writeCustomEvent writes/sends an event of a custom type (see
+// RegisterEventType).  An event isn't sent when none of the eventer's sinks
+// subscribe to its type.
+func (e *Eventer) writeCustomEvent(ctx context.Context, event *customEvent) error {
+	const op = "event.(Eventer).writeCustomEvent"
+	if event == nil {
+		return fmt.Errorf("%s: missing event: %w", op, ErrInvalidParameter)
+	}
+	e.closeLock.RLock()
+	defer e.closeLock.RUnlock()
+	if e.closed {
+		return fmt.Errorf("%s: %w", op, ErrEventerClosed)
+	}
+	if id, ok := CorrelationIdFromContext(ctx); ok && event.CorrelationId == "" {
+		event.CorrelationId = id
+	}
+	if err := event.validate(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !e.routes(event.eventType) {
+		return nil
+	}
+	if ok, err := e.checkSerialization(event.eventType, event); !ok {
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	}
+	event.Seq = e.nextSeq()
+	var status eventlogger.Status
+	err := e.retrySend(ctx, stdRetryCount, e.retryBackoff(), func() (eventlogger.Status, error) {
+		var sendErr error
+		status, sendErr = e.send(ctx, event.eventType, event)
+		return status, sendErr
+	})
+	e.handleWarnings(ctx, event.eventType, status)
+	e.health.record(event.eventType, err)
+	if err != nil {
+		e.logger.Error("encountered an error sending a custom event", "event_type", event.eventType, "error", err.Error())
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// routes returns whether any of the eventer's sinks subscribe to the custom
+// type t.
+func (e *Eventer) routes(t Type) bool {
+	for _, p := range e.customPipelines {
+		if p.eventType == t {
+			return true
+		}
+	}
+	return false
+}
+
 // writeAudit writes/send an audit event
 func (e *Eventer) writeAudit(ctx context.Context, event *audit) error {
 	const op = "event.(Eventer).writeAudit"
 	if event == nil {
 		return fmt.Errorf("%s: missing event: %w", op, ErrInvalidParameter)
 	}
+	e.closeLock.RLock()
+	defer e.closeLock.RUnlock()
+	if e.closed {
+		return fmt.Errorf("%s: %w", op, ErrEventerClosed)
+	}
 	if !e.conf.AuditEnabled {
 		return nil
 	}
-	err := e.retrySend(ctx, stdRetryCount, expBackoff{}, func() (eventlogger.Status, error) {
-		return e.broker.Send(ctx, eventlogger.EventType(AuditType), event)
+	event.Version = e.schemaVersion
+	if id, ok := CorrelationIdFromContext(ctx); ok && event.CorrelationId == "" {
+		event.CorrelationId = id
+	}
+	if event.AuthInfo == nil {
+		// every audit event records its actor, and an event without one is
+		// still written, flagged as unauthenticated
+		event.AuthInfo = &AuthInfo{}
+		if info, ok := AuthInfoFromContext(ctx); ok {
+			// the info is copied, since the context's info is updated once
+			// the request has been authenticated
+			*event.AuthInfo = *info
+		}
+		event.AuthInfo.Authenticated = event.AuthInfo.UserId != ""
+	}
+	if e.maxEventBytes > 0 {
+		// audit events are rejected rather than truncated, so the error is
+		// returned to the caller here rather than becoming a warning when
+		// the composed event reaches the pipeline's size limit node.
+		size, err := payloadSize(event)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if size > e.maxEventBytes {
+			return fmt.Errorf("%s: audit event of %d bytes exceeds the max of %d bytes: %w", op, size, e.maxEventBytes, ErrEventTooLarge)
+		}
+	}
+	if ok, err := e.checkSerialization(AuditType, event); !ok {
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	}
+	if event.Flush || e.conf.FlushEachAudit {
+		// only flushed audit events are emitted, so only they're numbered
+		event.Seq = e.nextSeq()
+	}
+	var status eventlogger.Status
+	recorder, _ := sendRecorderFromContext(ctx)
+	err := e.retrySend(ctx, stdRetryCount, e.retryBackoff(), func() (eventlogger.Status, error) {
+		if recorder != nil {
+			recorder.reset()
+		}
+		var sendErr error
+		status, sendErr = e.send(ctx, AuditType, event)
+		return status, sendErr
 	})
+	e.handleWarnings(ctx, AuditType, status)
+	e.health.record(AuditType, err)
 	if err != nil {
 		e.logger.Error("encountered an error sending an audit event", "error:", err.Error())
+		if threshold := e.conf.AuditFailClosedThreshold; threshold > 0 {
+			if _, failures := e.health.get(AuditType); failures == threshold {
+				e.logger.Error("audit delivery is degraded, operations which must be audited will be rejected until an audit event is delivered", "failures", failures)
+			}
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := e.flushAuditGates(ctx); err != nil {
+		e.logger.Error("encountered an error flushing an audit event", "error:", err.Error())
 		return fmt.Errorf("%s: %w", op, err)
 	}
 	return nil
 }
 
+// flushAuditGates flushes the gated filter node of each audit pipeline, so
+// audit events are written to their sinks rather than being held until
+// they're completed.  It's a no op unless the config's FlushEachAudit is
+// enabled.
+func (e *Eventer) flushAuditGates(ctx context.Context) error {
+	const op = "event.(Eventer).flushAuditGates"
+	for pipeId, n := range e.auditGates {
+		if err := n.FlushAll(ctx); err != nil {
+			return fmt.Errorf("%s: unable to flush audit pipeline %s: %w", op, pipeId, err)
+		}
+	}
+	return nil
+}
+
+// checkSerialization marshals the payload of an event of type t when the
+// eventer's strict serialization is enabled, and returns false if the event
+// shouldn't be sent because it can't be serialized.  An error is returned for
+// the types with an enforced delivery guarantee (see enforcedDelivery), while
+// other events are logged and dropped.
+func (e *Eventer) checkSerialization(t Type, payload interface{}) (bool, error) {
+	const op = "event.(Eventer).checkSerialization"
+	if !e.strictSerialization {
+		return true, nil
+	}
+	if _, err := json.Marshal(payload); err != nil {
+		if enforcedDelivery(t) {
+			return false, fmt.Errorf("%s: unable to serialize %s event (%s): %w", op, t, err, ErrInvalidParameter)
+		}
+		e.logger.Error("dropping event which can't be serialized", "event_type", t, "error", err.Error())
+		return false, nil
+	}
+	return true, nil
+}
+
+// handleWarnings logs the warnings from the status of the last attempt to send
+// an event of type t and passes them to the eventer's WarningsHook.  Warnings
+// are returned by the broker when an event wasn't delivered to every sink, but
+// it was delivered to enough sinks to meet the success threshold.  When the
+// eventer was created WithWarningsAsEvents, the warnings are also sent as a
+// system event, unless they're the warnings of a system event, which could
+// otherwise loop forever.
+func (e *Eventer) handleWarnings(ctx context.Context, t Type, status eventlogger.Status) {
+	if len(status.Warnings) == 0 {
+		return
+	}
+	var warnings error
+	for _, w := range status.Warnings {
+		warnings = multierror.Append(warnings, w)
+	}
+	e.logger.Warn("event was not delivered to every sink", "event_type", t, "warnings", warnings.Error())
+	if e.warningsHook != nil {
+		e.warningsHook(t, status.Warnings)
+	}
+	if e.warningsAsEvents && t != SystemType && e.conf.SysEventsEnabled {
+		e.sendWarningsEvent(ctx, t, status.Warnings)
+	}
+}
+
+// sendWarningsEvent sends the warnings from sending an event of type t as a
+// system event.  Failures are only logged, since the event they pertain to was
+// delivered.
+func (e *Eventer) sendWarningsEvent(ctx context.Context, t Type, warnings []error) {
+	const op = "event.(Eventer).sendWarningsEvent"
+	id, err := newId(string(SystemType))
+	if err != nil {
+		e.logger.Error("unable to generate id for warnings system event", "error", err.Error())
+		return
+	}
+	msgs := make([]string, 0, len(warnings))
+	for _, w := range warnings {
+		msgs = append(msgs, w.Error())
+	}
+	event := &sysEvent{
+		Id:      Id(id),
+		Version: sysVersion,
+		Op:      op,
+		Data: map[string]interface{}{
+			"msg":        "event was not delivered to every sink",
+			"event_type": string(t),
+			"warnings":   msgs,
+		},
+	}
+	if err := e.sendSysEvent(ctx, event); err != nil {
+		e.logger.Error("unable to send warnings system event", "error", err.Error())
+	}
+}
+
 // Reopen can used during a SIGHUP to reopen nodes, most importantly the underlying
-// file sinks.
+// file sinks.  Writes are quiesced while the nodes are reopened: Reopen waits
+// for the events being written to be sent, and events written while it
+// reopens the nodes are sent once it's done.  So no event is sent to a sink
+// while it's being reopened, where it could be lost or written twice.
+// Reopening a closed eventer returns ErrEventerClosed.
 func (e *Eventer) Reopen() error {
+	const op = "event.(Eventer).Reopen"
+	e.closeLock.Lock()
+	defer e.closeLock.Unlock()
+	if e.closed {
+		return fmt.Errorf("%s: %w", op, ErrEventerClosed)
+	}
 	if e.broker != nil {
 		return e.broker.Reopen(context.Background())
 	}
 	return nil
 }
 
-// FlushNodes will flush any of the eventer's flushable nodes.  This
-// needs to be called whenever Boundary is stopping (aka shutting down).
+// SinkReopenResult is the outcome of reopening one of the eventer's sinks with
+// ReopenSinks.  Error is nil when the sink was reopened.
+type SinkReopenResult struct {
+	Name  string
+	Error error
+}
+
+// ReopenSinks reopens each of the eventer's sinks, most importantly file sinks
+// after their files have been rotated by an external tool.  Unlike Reopen, a
+// failure to reopen one sink doesn't prevent the others from being reopened.
+// The result of each sink is returned in the order of the eventer's sinks,
+// along with an error which identifies each sink which failed.  Like Reopen,
+// writes are quiesced while the sinks are reopened.
+func (e *Eventer) ReopenSinks() ([]SinkReopenResult, error) {
+	const op = "event.(Eventer).ReopenSinks"
+	e.closeLock.Lock()
+	defer e.closeLock.Unlock()
+	if e.closed {
+		return nil, fmt.Errorf("%s: %w", op, ErrEventerClosed)
+	}
+	var results []SinkReopenResult
+	var reopenErrors error
+	reopened := map[eventlogger.Node]bool{}
+	for _, pipelines := range [][]pipeline{e.auditPipelines, e.observationPipelines, e.errPipelines, e.sysPipelines, e.customPipelines} {
+		for _, p := range pipelines {
+			if p.sinkNode == nil || reopened[p.sinkNode] {
+				continue
+			}
+			reopened[p.sinkNode] = true
+			r := SinkReopenResult{Name: p.sinkConfig.Name}
+			if err := p.sinkNode.Reopen(); err != nil {
+				r.Error = err
+				reopenErrors = multierror.Append(reopenErrors, fmt.Errorf("%s: unable to reopen sink %q: %w", op, p.sinkConfig.Name, err))
+			}
+			results = append(results, r)
+		}
+	}
+	return results, reopenErrors
+}
+
+// ShouldEmit returns whether events of type t are emitted by the eventer: its
+// type is enabled and at least one sink subscribes to it.  Callers may use it
+// to skip the work of building an event which would be dropped, like
+// hclog's IsInfo.  It returns false for EveryType, unknown types and once the
+// eventer is closed.
+func (e *Eventer) ShouldEmit(t Type) bool {
+	if e == nil {
+		return false
+	}
+	e.closeLock.RLock()
+	defer e.closeLock.RUnlock()
+	if e.closed {
+		return false
+	}
+	switch t {
+	case AuditType:
+		return e.conf.AuditEnabled && len(e.auditPipelines) > 0
+	case ObservationType:
+		return e.conf.ObservationsEnabled && (len(e.observationPipelines) > 0 || e.debugSink != nil)
+	case ErrorType:
+		return len(e.errPipelines) > 0
+	case SystemType:
+		return e.conf.SysEventsEnabled && len(e.sysPipelines) > 0
+	default:
+		return e.routes(t)
+	}
+}
+
+// SinkInfo describes a sink of an Eventer and the event types routed to it.
+type SinkInfo struct {
+	Name       string
+	Type       SinkType
+	Format     SinkFormat
+	EventTypes []Type // EventTypes are the routed types, which never include EveryType
+}
+
+// Sinks returns a snapshot of the eventer's sinks, sorted like the eventer
+// registers them: by name and then by where they write.  EveryType is
+// expanded into each of the types routed to a sink, and mirrors aren't
+// included.  Only the sinks the eventer was configured with are returned, and
+// not the debug sink (see EnableDebugSink).  They don't change after the
+// eventer is created, so it's safe to call Sinks concurrently with writing
+// events, with Reopen and with enabling or disabling the debug sink.
+func (e *Eventer) Sinks() []SinkInfo {
+	infos := make([]SinkInfo, 0, len(e.sinks))
+	for _, si := range e.sinks {
+		si.EventTypes = append([]Type(nil), si.EventTypes...)
+		infos = append(infos, si)
+	}
+	return infos
+}
+
+// Close sends any observations held by PauseObservations, stops the scheduled
+// rotation of file sinks, flushes the eventer's flushable nodes (see
+// FlushNodes), removes its debug sink (see DisableDebugSink) and then closes
+// each of its sinks which can be closed, releasing their file handles and
+// connections.  The returned error includes every failure.  After Close,
+// writing an event returns ErrEventerClosed.  Closing a closed eventer is a
+// no op.
+func (e *Eventer) Close(ctx context.Context) error {
+	const op = "event.(Eventer).Close"
+	e.closeLock.Lock()
+	defer e.closeLock.Unlock()
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+
+	// observations held while paused are sent before the sinks are closed
+	var closeErrors error
+	if err := e.sendPausedObservations(ctx); err != nil {
+		closeErrors = multierror.Append(closeErrors, fmt.Errorf("%s: %w", op, err))
+	}
+	if err := e.flushCoalescedError(ctx); err != nil {
+		closeErrors = multierror.Append(closeErrors, fmt.Errorf("%s: %w", op, err))
+	}
+
+	if e.stderrLock != nil {
+		openStderrLocks.remove(e.stderrLock)
+	}
+
+	// the schedulers are stopped first, so they don't reopen closed sinks
+	for _, rs := range e.rotationSchedulers {
+		rs.close()
+	}
+
+	if err := e.FlushNodes(ctx); err != nil {
+		closeErrors = multierror.Append(closeErrors, fmt.Errorf("%s: %w", op, err))
+	}
+	if err := e.removeDebugSink(ctx); err != nil {
+		closeErrors = multierror.Append(closeErrors, fmt.Errorf("%s: %w", op, err))
+	}
+	closed := map[eventlogger.Node]bool{}
+	for _, pipelines := range [][]pipeline{e.auditPipelines, e.observationPipelines, e.errPipelines, e.sysPipelines, e.customPipelines} {
+		for _, p := range pipelines {
+			if p.sinkNode == nil || closed[p.sinkNode] {
+				continue
+			}
+			closed[p.sinkNode] = true
+			if c, ok := p.sinkNode.(io.Closer); ok {
+				if err := c.Close(); err != nil {
+					closeErrors = multierror.Append(closeErrors, fmt.Errorf("%s: unable to close sink %q: %w", op, p.sinkConfig.Name, err))
+				}
+			}
+		}
+	}
+	return closeErrors
+}
+
+// FlushNodes will flush any of the eventer's flushable nodes, and then write
+// the events buffered by its batching sinks.  This needs to be called
+// whenever Boundary is stopping (aka shutting down).
 func (e *Eventer) FlushNodes(ctx context.Context) error {
 	const op = "event.(Eventer).FlushNodes"
 	for _, n := range e.flushableNodes {
@@ -498,5 +1787,11 @@ func (e *Eventer) FlushNodes(ctx context.Context) error {
 			return fmt.Errorf("%s: %w", op, err)
 		}
 	}
+	// sinks are flushed last, since flushing the nodes sends events to them
+	for _, n := range e.flushableSinks {
+		if err := n.FlushAll(ctx); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
 	return nil
 }
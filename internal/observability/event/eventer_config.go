@@ -2,24 +2,217 @@ package event
 
 import (
 	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
 )
 
 // EventerConfig supplies all the configuration needed to create/config an Eventer.
 type EventerConfig struct {
-	AuditEnabled        bool         `hcl:"audit_enabled"`        // AuditEnabled specifies if audit events should be emitted.
-	ObservationsEnabled bool         `hcl:"observations_enabled"` // ObservationsEnabled specifies if observation events should be emitted.
-	SysEventsEnabled    bool         `hcl:"sysevents_enabled"`    // SysEventsEnabled specifies if sysevents should be emitted.
-	Sinks               []SinkConfig `hcl:"sinks"`                // Sinks are all the configured sinks
+	AuditEnabled        bool             `hcl:"audit_enabled"`        // AuditEnabled specifies if audit events should be emitted.
+	ObservationsEnabled bool             `hcl:"observations_enabled"` // ObservationsEnabled specifies if observation events should be emitted.
+	SysEventsEnabled    bool             `hcl:"sysevents_enabled"`    // SysEventsEnabled specifies if sysevents should be emitted.
+	Sinks               []SinkConfig     `hcl:"sinks"`                // Sinks are all the configured sinks
+	FlushEachAudit      bool             `hcl:"flush_each_audit"`     // FlushEachAudit specifies if each audit event should be flushed to its sinks as soon as it's written.
+	ObservationLevel    ObservationLevel `hcl:"observation_level"`    // ObservationLevel specifies the minimum level of observations which are emitted.  Defaults to InfoLevel.
+
+	// RequireLocalAuditSink specifies that when audit events are enabled, at
+	// least one of their sinks must be local (a FileSink or StderrSink), so
+	// audit events have a durable destination when network sinks are
+	// unreachable.  Mirrors don't count, since they're best effort.
+	RequireLocalAuditSink bool `hcl:"require_local_audit_sink"`
+
+	// AuditFailClosedThreshold is the number of consecutive audit events
+	// which must fail to be delivered before the eventer is degraded (see
+	// (Eventer).AuditDegraded), so operations which must be audited, like
+	// authorizing a session, can be rejected rather than proceed
+	// un-audited.  The eventer recovers once an audit event is delivered.
+	// The eventer is never degraded when it's zero.
+	AuditFailClosedThreshold int `hcl:"audit_fail_closed_threshold"`
 }
 
 // Validate will Validate the config. A config isn't required to have any
-// sinks to be valid.
+// sinks to be valid.  Sinks which would receive the same event type more than
+// once, or which share an output target with another sink, are invalid.
+// Sinks are compared in sortedSinks order, so the sinks reported as sharing an
+// output target don't depend on the order they're configured in.  A sink's
+// FileNameTemplate is expanded into a file for each of its event types before
+// output targets are compared, and a network sink's spool is an output target
+// too.
 func (c *EventerConfig) Validate() error {
 	const op = "event.(EventerConfig).Validate"
+	if err := c.ObservationLevel.validate(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if c.AuditFailClosedThreshold < 0 {
+		return fmt.Errorf("%s: audit fail closed threshold must not be negative: %w", op, ErrInvalidParameter)
+	}
 	for i, s := range c.Sinks {
 		if err := s.validate(); err != nil {
 			return fmt.Errorf("%s: sink %d is invalid: %w", op, i, err)
 		}
+		if err := s.validateEventTypes(); err != nil {
+			return fmt.Errorf("%s: sink %d is invalid: %w", op, i, err)
+		}
+	}
+	// a sink's file name template expands into a file for each type, and
+	// each of them must have a distinct output target
+	sinks, err := expandSinks(c.Sinks)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	targets := make(map[string]string, len(sinks))
+	for _, s := range sortedSinks(sinks) {
+		for _, s := range append([]SinkConfig{s}, s.mirrorConfigs()...) {
+			if s.SinkType == DiscardSink {
+				// discarded events aren't written anywhere, so any number
+				// of sinks can discard them
+				continue
+			}
+			for _, target := range []string{s.outputTarget(), s.spoolTarget()} {
+				if target == "" {
+					continue
+				}
+				if other, found := targets[target]; found {
+					return fmt.Errorf("%s: sinks %q and %q have the same output target (%s): %w", op, other, s.Name, target, ErrDuplicateSink)
+				}
+				targets[target] = s.Name
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateConfig validates the config the way NewEventer would, without
+// creating an Eventer.  It has no side effects: no nodes are registered, and
+// file sinks' directories are only checked for being writable, not created.
+// Checks which depend on NewEventer's options, like a kafka sink's producer,
+// aren't made.
+func ValidateConfig(c EventerConfig) error {
+	const op = "event.ValidateConfig"
+	// NewEventer defaults to a stderr sink when there are no sinks
+	if len(c.Sinks) == 0 {
+		c.Sinks = []SinkConfig{DefaultSink()}
+	}
+	if err := c.validateForEventer(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	sinks, err := expandSinks(c.Sinks)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	for _, s := range sortedSinks(sinks) {
+		for _, s := range append([]SinkConfig{s}, s.mirrorConfigs()...) {
+			if err := s.probe(); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+		}
+	}
+	return nil
+}
+
+// dedupeFileSinks collapses file sinks which write to the same file into the
+// first of them (in config order), which receives the union of their event
+// types, and logs a warning for each sink which was collapsed.  Only sinks
+// whose settings are otherwise the same (ignoring their names, descriptions
+// and event types) are collapsed, since it's unclear which of the settings
+// should win when they differ; those sinks are left as they are, so
+// Validate still reports them as duplicates.
+func dedupeFileSinks(log hclog.Logger, sinks []SinkConfig) []SinkConfig {
+	deduped := make([]SinkConfig, 0, len(sinks))
+	byTarget := map[string]int{}
+	for _, s := range sinks {
+		if s.SinkType != FileSink || s.FileNameTemplate != "" {
+			deduped = append(deduped, s)
+			continue
+		}
+		target := s.outputTarget()
+		i, found := byTarget[target]
+		if !found || !sameSinkSettings(deduped[i], s) {
+			if !found {
+				byTarget[target] = len(deduped)
+			}
+			deduped = append(deduped, s)
+			continue
+		}
+		log.Warn("collapsing duplicate file sink", "sink", s.Name, "into", deduped[i].Name, "target", target)
+		deduped[i].EventTypes = mergeEventTypes(deduped[i].EventTypes, s.EventTypes)
+	}
+	return deduped
+}
+
+// sameSinkSettings returns true when the sinks' settings are the same, other
+// than their names, descriptions and event types.
+func sameSinkSettings(a, b SinkConfig) bool {
+	a.Name, a.Description, a.EventTypes = "", "", nil
+	b.Name, b.Description, b.EventTypes = "", "", nil
+	return reflect.DeepEqual(a, b)
+}
+
+// mergeEventTypes returns the union of the event types, in the order they're
+// first listed.  EveryType includes every other type, so the union is just
+// EveryType when either of them has it.
+func mergeEventTypes(a, b []Type) []Type {
+	merged := make([]Type, 0, len(a)+len(b))
+	seen := make(map[Type]bool, len(a)+len(b))
+	for _, t := range append(append([]Type{}, a...), b...) {
+		if t == EveryType {
+			return []Type{EveryType}
+		}
+		if !seen[t] {
+			seen[t] = true
+			merged = append(merged, t)
+		}
+	}
+	return merged
+}
+
+// validateForEventer runs the checks shared by NewEventer and ValidateConfig.
+// Besides Validate, it ensures every enabled event type, and errors which are
+// always emitted, have a sink.
+func (c *EventerConfig) validateForEventer() error {
+	const op = "event.(EventerConfig).validateForEventer"
+	if err := c.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	var hasAudit, hasObservation, hasErr, hasSys, hasLocalAudit bool
+	var auditSinks []string
+	for _, s := range sortedSinks(c.Sinks) {
+		for _, t := range s.EventTypes {
+			if t == EveryType || t == AuditType {
+				auditSinks = append(auditSinks, fmt.Sprintf("%q (%s)", s.Name, s.SinkType))
+				hasLocalAudit = hasLocalAudit || s.isLocal()
+			}
+			switch t {
+			case EveryType:
+				hasAudit, hasObservation, hasErr, hasSys = true, true, true, true
+			case AuditType:
+				hasAudit = true
+			case ObservationType:
+				hasObservation = true
+			case ErrorType:
+				hasErr = true
+			case SystemType:
+				hasSys = true
+			}
+		}
+	}
+	if c.AuditEnabled && !hasAudit {
+		return fmt.Errorf("%s: audit events enabled but no sink defined for it: %w", op, ErrNoSinkForType)
+	}
+	if c.AuditEnabled && c.RequireLocalAuditSink && !hasLocalAudit {
+		return fmt.Errorf("%s: a local audit sink is required, but the audit sinks are %s: %w", op, strings.Join(auditSinks, ", "), ErrNoLocalAuditSink)
+	}
+	if c.ObservationsEnabled && !hasObservation {
+		return fmt.Errorf("%s: observation events enabled but no sink defined for it: %w", op, ErrNoSinkForType)
+	}
+	if c.SysEventsEnabled && !hasSys {
+		return fmt.Errorf("%s: system events enabled but no sink defined for it: %w", op, ErrNoSinkForType)
+	}
+	// errors are always emitted, so there must always be a sink for them
+	if !hasErr {
+		return fmt.Errorf("%s: no sink defined for error events: %w", op, ErrNoSinkForType)
 	}
 	return nil
 }
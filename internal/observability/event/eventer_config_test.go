@@ -1,6 +1,9 @@
 package event
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -26,6 +29,244 @@ func TestEventerConfig_validate(t *testing.T) {
 			wantErrIs:       ErrInvalidParameter,
 			wantErrContains: "is not a valid sink type",
 		},
+		{
+			name: "invalid-format",
+			c: EventerConfig{
+				Sinks: []SinkConfig{
+					{
+						Name:       "bogus-format",
+						SinkType:   StderrSink,
+						EventTypes: []Type{EveryType},
+						Format:     "bogus",
+					},
+				},
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: `sink "bogus-format": event.(SinkFormat).Validate: 'bogus' is not a valid sink format (supported formats: json, cef, msgpack, text, proto)`,
+		},
+		{
+			name:            "negative-audit-fail-closed-threshold",
+			c:               EventerConfig{AuditFailClosedThreshold: -1},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "audit fail closed threshold must not be negative",
+		},
+		{
+			name: "rotate-max-files-without-trigger",
+			c: EventerConfig{
+				Sinks: []SinkConfig{
+					{
+						Name:           "audit-file",
+						SinkType:       FileSink,
+						EventTypes:     []Type{AuditType},
+						Format:         JSONSinkFormat,
+						FileName:       "audit.log",
+						RotateMaxFiles: 5,
+					},
+				},
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: `sink "audit-file": rotate max files requires rotate bytes`,
+		},
+		{
+			name: "every-type-and-explicit-type",
+			c: EventerConfig{
+				Sinks: []SinkConfig{
+					{
+						Name:       "overlapping",
+						SinkType:   StderrSink,
+						EventTypes: []Type{EveryType, AuditType},
+						Format:     JSONSinkFormat,
+					},
+				},
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: `sink "overlapping" receives event types through both *`,
+		},
+		{
+			name: "duplicate-event-type",
+			c: EventerConfig{
+				Sinks: []SinkConfig{
+					{
+						Name:       "dup-type",
+						SinkType:   StderrSink,
+						EventTypes: []Type{AuditType, AuditType},
+						Format:     JSONSinkFormat,
+					},
+				},
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: `sink "dup-type" lists event type audit more than once`,
+		},
+		{
+			name: "duplicate-file-target",
+			c: EventerConfig{
+				Sinks: []SinkConfig{
+					{
+						Name:       "first",
+						SinkType:   FileSink,
+						EventTypes: []Type{AuditType},
+						Format:     JSONSinkFormat,
+						Path:       "/var/log/boundary",
+						FileName:   "events.log",
+					},
+					{
+						Name:       "second",
+						SinkType:   FileSink,
+						EventTypes: []Type{ErrorType},
+						Format:     JSONSinkFormat,
+						Path:       "/var/log/boundary/",
+						FileName:   "./events.log",
+					},
+				},
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: `sinks "first" and "second" have the same output target`,
+		},
+		{
+			name: "file-target-is-stderr",
+			c: EventerConfig{
+				Sinks: []SinkConfig{
+					{
+						Name:       "stderr",
+						SinkType:   StderrSink,
+						EventTypes: []Type{EveryType},
+						Format:     JSONSinkFormat,
+					},
+					{
+						Name:       "redirect",
+						SinkType:   FileSink,
+						EventTypes: []Type{AuditType},
+						Format:     JSONSinkFormat,
+						Path:       "/dev",
+						FileName:   "stderr",
+					},
+				},
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: `sinks "redirect" and "stderr" have the same output target`,
+		},
+		{
+			name: "valid-distinct-targets",
+			c: EventerConfig{
+				Sinks: []SinkConfig{
+					{
+						Name:       "stderr",
+						SinkType:   StderrSink,
+						EventTypes: []Type{EveryType},
+						Format:     JSONSinkFormat,
+					},
+					{
+						Name:       "audit",
+						SinkType:   FileSink,
+						EventTypes: []Type{AuditType},
+						Format:     JSONSinkFormat,
+						Path:       "/var/log/boundary",
+						FileName:   "audit.log",
+					},
+					{
+						Name:       "errors",
+						SinkType:   FileSink,
+						EventTypes: []Type{ErrorType, SystemType},
+						Format:     JSONSinkFormat,
+						Path:       "/var/log/boundary",
+						FileName:   "errors.log",
+					},
+				},
+			},
+		},
+		{
+			name: "file-name-template-duplicate-target",
+			c: EventerConfig{
+				Sinks: []SinkConfig{
+					{
+						Name:             "per-type",
+						SinkType:         FileSink,
+						EventTypes:       []Type{EveryType},
+						Format:           JSONSinkFormat,
+						Path:             "/var/log/boundary",
+						FileNameTemplate: "{{.EventType}}.log",
+					},
+					{
+						Name:       "audit",
+						SinkType:   FileSink,
+						EventTypes: []Type{AuditType},
+						Format:     JSONSinkFormat,
+						Path:       "/var/log/boundary",
+						FileName:   "audit.log",
+					},
+				},
+			},
+			wantErrIs:       ErrDuplicateSink,
+			wantErrContains: `sinks "audit" and "per-type" have the same output target`,
+		},
+		{
+			name: "spool-is-file-sink-target",
+			c: EventerConfig{
+				Sinks: []SinkConfig{
+					{
+						Name:       "grpc",
+						SinkType:   GRPCSink,
+						EventTypes: []Type{EveryType},
+						Format:     JSONSinkFormat,
+						GRPCConfig: &GRPCSinkConfig{Address: "collector:9400"},
+						SpoolPath:  "/var/log/boundary/events.log",
+					},
+					{
+						Name:       "file",
+						SinkType:   FileSink,
+						EventTypes: []Type{AuditType},
+						Format:     JSONSinkFormat,
+						Path:       "/var/log/boundary",
+						FileName:   "events.log",
+					},
+				},
+			},
+			wantErrIs:       ErrDuplicateSink,
+			wantErrContains: `sinks "file" and "grpc" have the same output target`,
+		},
+		{
+			name: "stderr-sinks-in-same-writer-group",
+			c: EventerConfig{
+				Sinks: []SinkConfig{
+					{
+						Name:        "audit",
+						SinkType:    StderrSink,
+						EventTypes:  []Type{AuditType},
+						Format:      JSONSinkFormat,
+						WriterGroup: "group",
+					},
+					{
+						Name:        "observation",
+						SinkType:    StderrSink,
+						EventTypes:  []Type{ObservationType},
+						Format:      JSONSinkFormat,
+						WriterGroup: "group",
+					},
+				},
+			},
+			wantErrIs:       ErrDuplicateSink,
+			wantErrContains: `sinks "audit" and "observation" have the same output target`,
+		},
+		{
+			name: "valid-stderr-sinks-in-writer-groups",
+			c: EventerConfig{
+				Sinks: []SinkConfig{
+					{
+						Name:        "audit",
+						SinkType:    StderrSink,
+						EventTypes:  []Type{AuditType},
+						Format:      JSONSinkFormat,
+						WriterGroup: "audit",
+					},
+					{
+						Name:       "default",
+						SinkType:   StderrSink,
+						EventTypes: []Type{ObservationType, ErrorType},
+						Format:     JSONSinkFormat,
+					},
+				},
+			},
+		},
 		{
 			name: "valid-with-all-defaults",
 			c:    EventerConfig{},
@@ -48,3 +289,243 @@ func TestEventerConfig_validate(t *testing.T) {
 		})
 	}
 }
+
+func TestEventerConfig_ValidateDeterministic(t *testing.T) {
+	t.Parallel()
+	sink := func(name, fileName string) SinkConfig {
+		return SinkConfig{
+			Name:       name,
+			SinkType:   FileSink,
+			EventTypes: []Type{EveryType},
+			Format:     JSONSinkFormat,
+			Path:       "/var/log/boundary",
+			FileName:   fileName,
+		}
+	}
+	sinks := []SinkConfig{
+		sink("c", "dup-1.log"),
+		sink("a", "dup-2.log"),
+		sink("b", "dup-1.log"),
+		sink("d", "dup-2.log"),
+		sink("e", "unique.log"),
+	}
+	orders := [][]int{
+		{0, 1, 2, 3, 4},
+		{4, 3, 2, 1, 0},
+		{2, 0, 4, 3, 1},
+		{3, 4, 1, 0, 2},
+	}
+	for _, order := range orders {
+		c := EventerConfig{}
+		for _, i := range order {
+			c.Sinks = append(c.Sinks, sinks[i])
+		}
+		err := c.Validate()
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+		assert.Containsf(t, err.Error(), `sinks "b" and "c" have the same output target`, "order: %v", order)
+	}
+	t.Run("sortedSinks-does-not-modify-config", func(t *testing.T) {
+		c := EventerConfig{Sinks: []SinkConfig{sink("b", "b.log"), sink("a", "a.log")}}
+		require.NoError(t, c.Validate())
+		assert.Equal(t, "b", c.Sinks[0].Name)
+		got := sortedSinks(c.Sinks)
+		assert.Equal(t, "a", got[0].Name)
+		assert.Equal(t, "b", got[1].Name)
+	})
+}
+
+func TestValidateConfig(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	tests := []struct {
+		name            string
+		c               EventerConfig
+		wantErrIs       error
+		wantErrContains string
+	}{
+		{
+			name: "default-sink",
+			c:    EventerConfig{AuditEnabled: true, ObservationsEnabled: true, SysEventsEnabled: true},
+		},
+		{
+			name: "duplicate-sinks",
+			c: EventerConfig{
+				Sinks: []SinkConfig{
+					{Name: "first", SinkType: StderrSink, EventTypes: []Type{EveryType}, Format: JSONSinkFormat},
+					{Name: "second", SinkType: StderrSink, EventTypes: []Type{EveryType}, Format: JSONSinkFormat},
+				},
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: `sinks "first" and "second" have the same output target`,
+		},
+		{
+			name: "audit-without-sink",
+			c: EventerConfig{
+				AuditEnabled: true,
+				Sinks: []SinkConfig{
+					{Name: "errors", SinkType: StderrSink, EventTypes: []Type{ErrorType}, Format: JSONSinkFormat},
+				},
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "audit events enabled but no sink defined for it",
+		},
+		{
+			name: "no-error-sink",
+			c: EventerConfig{
+				Sinks: []SinkConfig{
+					{Name: "system", SinkType: StderrSink, EventTypes: []Type{SystemType}, Format: JSONSinkFormat},
+				},
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "no sink defined for error events",
+		},
+		{
+			name: "missing-file-dir",
+			c: EventerConfig{
+				Sinks: []SinkConfig{
+					{Name: "file", SinkType: FileSink, EventTypes: []Type{EveryType}, Format: JSONSinkFormat, Path: filepath.Join(tmpDir, "missing"), FileName: "events.log"},
+				},
+			},
+		},
+		{
+			name: "file-path-not-a-dir",
+			c: EventerConfig{
+				Sinks: []SinkConfig{
+					{Name: "file", SinkType: FileSink, EventTypes: []Type{EveryType}, Format: JSONSinkFormat, Path: filepath.Join(tmpDir, "not-a-dir"), FileName: "events.log"},
+				},
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "is not a directory",
+		},
+	}
+	require.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "not-a-dir"), nil, 0o600))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			err := ValidateConfig(tt.c)
+			if tt.wantErrIs != nil {
+				require.Error(err)
+				assert.ErrorIs(err, tt.wantErrIs)
+				assert.Contains(err.Error(), tt.wantErrContains)
+				return
+			}
+			require.NoError(err)
+		})
+	}
+	t.Run("no-side-effects", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		path := filepath.Join(tmpDir, "not-created")
+		c := EventerConfig{
+			Sinks: []SinkConfig{
+				{Name: "file", SinkType: FileSink, EventTypes: []Type{EveryType}, Format: JSONSinkFormat, Path: path, FileName: "events.log"},
+			},
+		}
+		require.NoError(ValidateConfig(c))
+		_, err := os.Stat(path)
+		assert.True(os.IsNotExist(err))
+	})
+	t.Run("read-only-dir", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("root can write to read-only directories")
+		}
+		assert, require := assert.New(t), require.New(t)
+		readOnly := t.TempDir()
+		require.NoError(os.Chmod(readOnly, 0o500))
+		t.Cleanup(func() { os.Chmod(readOnly, 0o700) })
+		c := EventerConfig{
+			Sinks: []SinkConfig{
+				{Name: "read-only", SinkType: FileSink, EventTypes: []Type{EveryType}, Format: JSONSinkFormat, Path: filepath.Join(readOnly, "missing"), FileName: "events.log"},
+			},
+		}
+		err := ValidateConfig(c)
+		require.Error(err)
+		assert.ErrorIs(err, ErrInvalidParameter)
+		assert.Contains(err.Error(), `sink "read-only": directory `+readOnly+" is not writable")
+	})
+}
+
+func TestEventerConfig_RequireLocalAuditSink(t *testing.T) {
+	t.Parallel()
+	kafkaSink := SinkConfig{
+		Name:        "kafka",
+		SinkType:    KafkaSink,
+		EventTypes:  []Type{AuditType},
+		Format:      JSONSinkFormat,
+		KafkaConfig: &KafkaSinkConfig{Brokers: []string{"localhost:9092"}, Topic: "audit", DeliveryGuarantee: Enforced},
+	}
+	errSink := SinkConfig{Name: "errors", SinkType: StderrSink, EventTypes: []Type{ErrorType}, Format: JSONSinkFormat}
+	tests := []struct {
+		name            string
+		c               EventerConfig
+		wantErrIs       error
+		wantErrContains string
+	}{
+		{
+			name: "network-only",
+			c: EventerConfig{
+				AuditEnabled:          true,
+				RequireLocalAuditSink: true,
+				Sinks:                 []SinkConfig{kafkaSink, errSink},
+			},
+			wantErrIs:       ErrNoLocalAuditSink,
+			wantErrContains: `a local audit sink is required, but the audit sinks are "kafka" (kafka)`,
+		},
+		{
+			name: "network-only-not-required",
+			c: EventerConfig{
+				AuditEnabled: true,
+				Sinks:        []SinkConfig{kafkaSink, errSink},
+			},
+		},
+		{
+			name: "network-only-audit-disabled",
+			c: EventerConfig{
+				RequireLocalAuditSink: true,
+				Sinks:                 []SinkConfig{kafkaSink, errSink},
+			},
+		},
+		{
+			name: "network-with-local-mirror",
+			c: EventerConfig{
+				AuditEnabled:          true,
+				RequireLocalAuditSink: true,
+				Sinks: []SinkConfig{
+					func() SinkConfig {
+						s := kafkaSink
+						s.Mirrors = []SinkConfig{{Name: "mirror", SinkType: FileSink, Path: t.TempDir(), FileName: "audit.log"}}
+						return s
+					}(),
+					errSink,
+				},
+			},
+			wantErrIs:       ErrNoLocalAuditSink,
+			wantErrContains: `the audit sinks are "kafka" (kafka)`,
+		},
+		{
+			name: "mixed",
+			c: EventerConfig{
+				AuditEnabled:          true,
+				RequireLocalAuditSink: true,
+				Sinks: []SinkConfig{
+					kafkaSink,
+					{Name: "local", SinkType: StderrSink, EventTypes: []Type{EveryType}, Format: JSONSinkFormat},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			err := ValidateConfig(tt.c)
+			if tt.wantErrIs != nil {
+				require.Error(err)
+				assert.ErrorIs(err, tt.wantErrIs)
+				assert.ErrorIs(err, ErrInvalidParameter)
+				assert.Contains(err.Error(), tt.wantErrContains)
+				return
+			}
+			require.NoError(err)
+		})
+	}
+}
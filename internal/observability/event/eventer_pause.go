@@ -0,0 +1,129 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// defaultMaxPausedObservations is the default number of observations which
+// are buffered while observations are paused.
+const defaultMaxPausedObservations = 10000
+
+// pausedObservation is an observation written while observations are paused,
+// along with the correlation id of the context it was written with.
+type pausedObservation struct {
+	event         *observation
+	correlationId string
+}
+
+// observationPause buffers the observations written while observations are
+// paused (see PauseObservations).
+type observationPause struct {
+	l        sync.Mutex
+	paused   bool
+	buffered []pausedObservation
+	dropped  int
+
+	// resumeLock serializes resuming, so buffered observations are sent in
+	// the order they were written.
+	resumeLock sync.Mutex
+}
+
+// PauseObservations holds observation events until ResumeObservations is
+// called, for example while a sink is being migrated.  Audit, error and system
+// events aren't affected.  Held observations are buffered in the order they're
+// written, up to the limit set by WithMaxPausedObservations (10,000 by
+// default).  Observations are best effort, so once the buffer is full further
+// observations are dropped rather than blocking their writers, and the number
+// dropped is logged when observations are resumed.  Pausing paused
+// observations is a no op.
+func (e *Eventer) PauseObservations() {
+	e.observationPause.l.Lock()
+	defer e.observationPause.l.Unlock()
+	e.observationPause.paused = true
+}
+
+// ResumeObservations sends the observations buffered while observations were
+// paused, in the order they were written, and then stops holding
+// observations.  Observations written while the buffer is sent are buffered
+// after it, so they're sent in order too.  An observation which can't be sent
+// is logged and the rest are still sent; the returned error includes every
+// failure.  Resuming observations which aren't paused is a no op.
+func (e *Eventer) ResumeObservations(ctx context.Context) error {
+	const op = "event.(Eventer).ResumeObservations"
+	e.closeLock.RLock()
+	defer e.closeLock.RUnlock()
+	if e.closed {
+		return fmt.Errorf("%s: %w", op, ErrEventerClosed)
+	}
+	if err := e.sendPausedObservations(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// bufferObservation buffers the observation when observations are paused,
+// and returns false when they're not.
+func (e *Eventer) bufferObservation(ctx context.Context, event *observation) bool {
+	p := &e.observationPause
+	p.l.Lock()
+	defer p.l.Unlock()
+	if !p.paused {
+		return false
+	}
+	max := e.maxPausedObservations
+	if max == 0 {
+		max = defaultMaxPausedObservations
+	}
+	if len(p.buffered) >= max {
+		p.dropped++
+		return true
+	}
+	id, _ := CorrelationIdFromContext(ctx)
+	p.buffered = append(p.buffered, pausedObservation{event: event, correlationId: id})
+	return true
+}
+
+// sendPausedObservations sends the buffered observations until the buffer is
+// empty, and then unpauses observations.  The caller must hold the eventer's
+// closeLock.
+func (e *Eventer) sendPausedObservations(ctx context.Context) error {
+	const op = "event.(Eventer).sendPausedObservations"
+	p := &e.observationPause
+	p.resumeLock.Lock()
+	defer p.resumeLock.Unlock()
+
+	var sendErrors error
+	for {
+		p.l.Lock()
+		buffered, dropped := p.buffered, p.dropped
+		p.buffered, p.dropped = nil, 0
+		if len(buffered) == 0 {
+			p.paused = false
+		}
+		p.l.Unlock()
+		if dropped > 0 {
+			e.logger.Warn("observations were dropped while paused, since the buffer was full", "dropped", dropped)
+		}
+		if len(buffered) == 0 {
+			break
+		}
+		for _, po := range buffered {
+			sendCtx := ctx
+			if po.correlationId != "" {
+				var err error
+				if sendCtx, err = WithCorrelationId(ctx, po.correlationId); err != nil {
+					sendErrors = multierror.Append(sendErrors, fmt.Errorf("%s: %w", op, err))
+					continue
+				}
+			}
+			if err := e.sendObservation(sendCtx, po.event); err != nil {
+				sendErrors = multierror.Append(sendErrors, fmt.Errorf("%s: %w", op, err))
+			}
+		}
+	}
+	return sendErrors
+}
@@ -0,0 +1,109 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventer_PauseObservations(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	c := EventerConfig{
+		AuditEnabled:        true,
+		ObservationsEnabled: true,
+		Sinks: []SinkConfig{
+			{
+				Name:       "stderr",
+				SinkType:   StderrSink,
+				EventTypes: []Type{EveryType},
+				Format:     JSONSinkFormat,
+			},
+		},
+	}
+	idPattern := regexp.MustCompile(`"id":"((?:obs|audit)-[0-9a-z-]+)"`)
+	// writtenIds returns the ids of the events written, in order
+	writtenIds := func(b *bytes.Buffer) []string {
+		var ids []string
+		for _, m := range idPattern.FindAllStringSubmatch(b.String(), -1) {
+			ids = append(ids, m[1])
+		}
+		return ids
+	}
+	writeObservation := func(t *testing.T, e *Eventer, id string) {
+		t.Helper()
+		o, err := newObservation("TestEventer_PauseObservations", WithId(id), WithDetails(map[string]interface{}{"name": id}), WithFlush())
+		require.NoError(t, err)
+		require.NoError(t, e.writeObservation(ctx, o))
+	}
+	writeAudit := func(t *testing.T, e *Eventer, id string) {
+		t.Helper()
+		a, err := newAudit("TestEventer_PauseObservations", WithId(id), WithFlush())
+		require.NoError(t, err)
+		require.NoError(t, e.writeAudit(ctx, a))
+	}
+
+	t.Run("buffer-and-resume", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		var buf bytes.Buffer
+		e, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, c, WithStderrWriter(&buf))
+		require.NoError(err)
+
+		writeObservation(t, e, "obs-1")
+		e.PauseObservations()
+		writeObservation(t, e, "obs-2")
+		writeObservation(t, e, "obs-3")
+		writeAudit(t, e, "audit-1")
+		// audit events still flow while observations are paused
+		assert.Equal([]string{"obs-1", "audit-1"}, writtenIds(&buf))
+
+		require.NoError(e.ResumeObservations(ctx))
+		writeObservation(t, e, "obs-4")
+		assert.Equal([]string{"obs-1", "audit-1", "obs-2", "obs-3", "obs-4"}, writtenIds(&buf))
+
+		// resuming observations which aren't paused is a no op
+		require.NoError(e.ResumeObservations(ctx))
+		assert.Len(writtenIds(&buf), 5)
+	})
+	t.Run("full-buffer", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		var buf bytes.Buffer
+		e, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, c, WithStderrWriter(&buf), WithMaxPausedObservations(2))
+		require.NoError(err)
+
+		e.PauseObservations()
+		for _, id := range []string{"obs-1", "obs-2", "obs-3"} {
+			writeObservation(t, e, id)
+		}
+		assert.Empty(writtenIds(&buf))
+		require.NoError(e.ResumeObservations(ctx))
+		assert.Equal([]string{"obs-1", "obs-2"}, writtenIds(&buf))
+	})
+	t.Run("close-while-paused", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		var buf bytes.Buffer
+		e, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, c, WithStderrWriter(&buf))
+		require.NoError(err)
+
+		e.PauseObservations()
+		writeObservation(t, e, "obs-1")
+		require.NoError(e.Close(ctx))
+		assert.Equal([]string{"obs-1"}, writtenIds(&buf))
+
+		err = e.ResumeObservations(ctx)
+		require.Error(err)
+		assert.ErrorIs(err, ErrEventerClosed)
+	})
+	t.Run("invalid-max", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		_, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, c, WithMaxPausedObservations(-1))
+		require.Error(err)
+		assert.ErrorIs(err, ErrInvalidParameter)
+	})
+}
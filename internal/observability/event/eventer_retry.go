@@ -2,6 +2,7 @@ package event
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
@@ -14,18 +15,42 @@ import (
 const (
 	// stdRetryCount is the standard number of times for retry when sending events
 	stdRetryCount = 3
+
+	// batchSendTimeout is the max duration of sending a network sink's batch
+	// of events, including its retries.  A batch holds the events of several
+	// callers, so it's sent with a ctx owned by the sink rather than the ctx
+	// of any one of them.
+	batchSendTimeout = 30 * time.Second
 )
 
 type backoff interface {
 	duration(attemptNumber uint) time.Duration
 }
 
-type expBackoff struct{}
+// expBackoff is an exponential backoff with "equal jitter": an attempt's
+// duration is half of its exponential duration plus a random duration of up
+// to the other half.  The jitter keeps goroutines which are retrying a shared
+// sink from retrying in lockstep when it recovers.
+type expBackoff struct {
+	// noJitter disables the jitter, so the durations are the exponential
+	// durations.
+	noJitter bool
+}
 
 // duration returns an exponential backing off time duration
 func (b expBackoff) duration(attempt uint) time.Duration {
-	r := rand.Float64()
-	return time.Millisecond * time.Duration(math.Exp2(float64(attempt))*5*(r+0.5))
+	d := time.Millisecond * time.Duration(math.Exp2(float64(attempt))*5)
+	if b.noJitter {
+		return d
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// retryBackoff returns the backoff used when retrying to send the eventer's
+// events.
+func (e *Eventer) retryBackoff() backoff {
+	return expBackoff{noJitter: e.noBackoffJitter}
 }
 
 type retryInfo struct {
@@ -35,8 +60,20 @@ type retryInfo struct {
 
 type sendHandler func() (eventlogger.Status, error)
 
+// permanentError is returned by a sendHandler when its send mustn't be
+// retried.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
 // retrySend will attempt sendHandler (which is intended to be a closure that
 // sends an event) the specified number of retries using the specified backoff.
+// Retrying stops when the ctx is done, and the ctx's error is included in the
+// returned error.  It also stops when the handler returns a permanentError.
 func (e *Eventer) retrySend(ctx context.Context, retries uint, backOff backoff, handler sendHandler) error {
 	const op = "event.(Eventer).retrySend"
 	if backOff == nil {
@@ -47,28 +84,33 @@ func (e *Eventer) retrySend(ctx context.Context, retries uint, backOff backoff,
 	}
 	success := false
 	var retryErrors error
-	var attemptStatus eventlogger.Status
 	info := retryInfo{}
 	for attempts := uint(1); ; attempts++ {
 		if attempts > retries+1 {
 			retryErrors = multierror.Append(retryErrors, fmt.Errorf("%s: reached max of %d: %w", op, retries, ErrMaxRetries))
 			return retryErrors
 		}
-		var err error
-		attemptStatus, err = handler()
-		if len(attemptStatus.Warnings) > 0 {
-			var retryWarnings error
-			for _, w := range attemptStatus.Warnings {
-				retryWarnings = multierror.Append(retryWarnings, w)
-			}
-			e.logger.Error("unable to send event", "operation", op, "warning", retryWarnings)
+		if err := ctx.Err(); err != nil {
+			retryErrors = multierror.Append(retryErrors, fmt.Errorf("%s: %w", op, err))
+			return retryErrors
 		}
+		_, err := handler()
 		if err != nil {
 			retryErrors = multierror.Append(retryErrors, fmt.Errorf("%s: %w", op, err))
+			if errors.As(err, new(*permanentError)) {
+				return retryErrors
+			}
 			d := backOff.duration(attempts)
 			info.retries++
 			info.backoff = info.backoff + d
-			time.Sleep(d)
+			t := time.NewTimer(d)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				retryErrors = multierror.Append(retryErrors, fmt.Errorf("%s: %w", op, ctx.Err()))
+				return retryErrors
+			case <-t.C:
+			}
 			continue
 		}
 		success = true
@@ -6,6 +6,7 @@ import (
 	"os"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/eventlogger"
 	"github.com/hashicorp/go-hclog"
@@ -119,3 +120,180 @@ func TestEventer_retrySend(t *testing.T) {
 		})
 	}
 }
+
+type testFixedBackoff time.Duration
+
+func (b testFixedBackoff) duration(uint) time.Duration { return time.Duration(b) }
+
+func TestEventer_retrySendCanceled(t *testing.T) {
+	t.Parallel()
+	e := &Eventer{logger: hclog.NewNullLogger()}
+	ctx, cancel := context.WithCancel(context.Background())
+	var attempts int
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := e.retrySend(ctx, stdRetryCount, testFixedBackoff(time.Hour), func() (eventlogger.Status, error) {
+		attempts++
+		return eventlogger.Status{}, fmt.Errorf("%s: no msg: %w", "test", ErrIo)
+	})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, err, ErrIo)
+	assert.Equal(t, 1, attempts)
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+
+	t.Run("already-canceled", func(t *testing.T) {
+		attempts = 0
+		err := e.retrySend(ctx, stdRetryCount, expBackoff{}, func() (eventlogger.Status, error) {
+			attempts++
+			return eventlogger.Status{}, nil
+		})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 0, attempts)
+	})
+}
+
+func Test_expBackoff_duration(t *testing.T) {
+	t.Parallel()
+	t.Run("no-jitter", func(t *testing.T) {
+		b := expBackoff{noJitter: true}
+		assert.Equal(t, 10*time.Millisecond, b.duration(1))
+		assert.Equal(t, 20*time.Millisecond, b.duration(2))
+		assert.Equal(t, 40*time.Millisecond, b.duration(3))
+	})
+	t.Run("jitter", func(t *testing.T) {
+		assert := assert.New(t)
+		b := expBackoff{}
+		for attempt := uint(1); attempt <= 5; attempt++ {
+			max := expBackoff{noJitter: true}.duration(attempt)
+			seen := map[time.Duration]bool{}
+			for i := 0; i < 100; i++ {
+				d := b.duration(attempt)
+				assert.GreaterOrEqual(int64(d), int64(max/2), "attempt %d", attempt)
+				assert.LessOrEqual(int64(d), int64(max), "attempt %d", attempt)
+				seen[d] = true
+			}
+			// successive durations aren't a fixed schedule
+			assert.Greater(len(seen), 1, "attempt %d", attempt)
+		}
+	})
+}
+
+func TestEventer_retryBackoff(t *testing.T) {
+	t.Parallel()
+	testConfig := TestEventerConfig(t, "TestEventer_retryBackoff")
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+	})
+
+	e, err := NewEventer(testLogger, testLock, testConfig.EventerConfig)
+	require.NoError(t, err)
+	assert.Equal(t, expBackoff{}, e.retryBackoff())
+
+	e, err = NewEventer(testLogger, testLock, testConfig.EventerConfig, WithNoBackoffJitter())
+	require.NoError(t, err)
+	assert.Equal(t, expBackoff{noJitter: true}, e.retryBackoff())
+}
+
+// testSlowBroker is a broker whose sends take delay, unless their ctx is done
+// first (as the eventlogger broker's do).
+type testSlowBroker struct {
+	*testMockBroker
+	delay time.Duration
+
+	l     sync.Mutex
+	calls int
+}
+
+func (b *testSlowBroker) Send(ctx context.Context, t eventlogger.EventType, payload interface{}) (eventlogger.Status, error) {
+	b.l.Lock()
+	b.calls++
+	b.l.Unlock()
+	select {
+	case <-ctx.Done():
+		return eventlogger.Status{}, ctx.Err()
+	case <-time.After(b.delay):
+		return b.testMockBroker.Send(ctx, t, payload)
+	}
+}
+
+func (b *testSlowBroker) Calls() int {
+	b.l.Lock()
+	defer b.l.Unlock()
+	return b.calls
+}
+
+func TestEventer_WithSendTimeout(t *testing.T) {
+	t.Parallel()
+	testSetup := TestEventerConfig(t, "TestEventer_WithSendTimeout")
+	newEventer := func(t *testing.T, b broker, timeout time.Duration) *Eventer {
+		t.Helper()
+		e, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, testSetup.EventerConfig, TestWithBroker(t, b), WithSendTimeout(timeout), WithNoBackoffJitter())
+		require.NoError(t, err)
+		return e
+	}
+
+	t.Run("best-effort-not-retried", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		b := &testSlowBroker{testMockBroker: &testMockBroker{}, delay: time.Hour}
+		e := newEventer(t, b, 10*time.Millisecond)
+
+		start := time.Now()
+		id, err := newId(string(SystemType))
+		require.NoError(err)
+		err = e.writeSysEvent(context.Background(), &sysEvent{Id: Id(id), Op: "TestEventer_WithSendTimeout", Data: map[string]interface{}{"name": "data"}})
+		require.Error(err)
+		assert.ErrorIs(err, ErrSendTimeout)
+		assert.Equal(1, b.Calls())
+		assert.Less(int64(time.Since(start)), int64(5*time.Second))
+	})
+	t.Run("enforced-retried", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		b := &testSlowBroker{testMockBroker: &testMockBroker{}, delay: time.Hour}
+		e := newEventer(t, b, 10*time.Millisecond)
+
+		testAudit, err := newAudit("TestEventer_WithSendTimeout", WithFlush())
+		require.NoError(err)
+		err = e.writeAudit(context.Background(), testAudit)
+		require.Error(err)
+		assert.ErrorIs(err, ErrSendTimeout)
+		assert.ErrorIs(err, ErrMaxRetries)
+		assert.Equal(stdRetryCount+1, b.Calls())
+	})
+	t.Run("enforced-within-ctx", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		b := &testSlowBroker{testMockBroker: &testMockBroker{}, delay: time.Hour}
+		e := newEventer(t, b, 50*time.Millisecond)
+
+		// the overall ctx is done before the send's timeout, so the send
+		// isn't retried
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		testAudit, err := newAudit("TestEventer_WithSendTimeout", WithFlush())
+		require.NoError(err)
+		err = e.writeAudit(ctx, testAudit)
+		require.Error(err)
+		assert.ErrorIs(err, context.DeadlineExceeded)
+		assert.NotErrorIs(err, ErrSendTimeout)
+		assert.Equal(1, b.Calls())
+	})
+	t.Run("within-timeout", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		b := &testSlowBroker{testMockBroker: &testMockBroker{}, delay: time.Millisecond}
+		e := newEventer(t, b, time.Minute)
+
+		testAudit, err := newAudit("TestEventer_WithSendTimeout", WithFlush())
+		require.NoError(err)
+		require.NoError(e.writeAudit(context.Background(), testAudit))
+		assert.Equal(1, b.Calls())
+		assert.Len(b.sent[eventlogger.EventType(AuditType)], 1)
+	})
+	t.Run("negative-timeout", func(t *testing.T) {
+		_, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, testSetup.EventerConfig, TestWithBroker(t, &testMockBroker{}), WithSendTimeout(-time.Second))
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	})
+}
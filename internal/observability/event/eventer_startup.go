@@ -0,0 +1,100 @@
+package event
+
+import (
+	"context"
+	"fmt"
+)
+
+// EmitStartupEvent writes a system event which summarizes the eventer's
+// effective config: which event types are enabled and, for each sink, its
+// name, type, format, event types, where it writes and its delivery
+// guarantee.  It's intended to be called once the eventer is created, so the
+// event stream records the logging that was active.  Only an allow list of
+// each sink's settings is included, so credentials (like a kafka sink's SASL
+// password or CA cert) are never written.  It's a no op when system events
+// aren't enabled.
+func (e *Eventer) EmitStartupEvent(ctx context.Context) error {
+	const op = "event.(Eventer).EmitStartupEvent"
+	if !e.conf.SysEventsEnabled {
+		return nil
+	}
+	id, err := newId(string(SystemType))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	sinks, err := expandSinks(e.conf.Sinks)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	summaries := make([]interface{}, 0, len(sinks))
+	for _, s := range sortedSinks(sinks) {
+		summaries = append(summaries, startupSinkSummary(s))
+	}
+	event := &sysEvent{
+		Id:      Id(id),
+		Version: sysVersion,
+		Op:      op,
+		Data: map[string]interface{}{
+			"msg":                      "eventer started",
+			"audit_enabled":            e.conf.AuditEnabled,
+			"observations_enabled":     e.conf.ObservationsEnabled,
+			"sysevents_enabled":        e.conf.SysEventsEnabled,
+			"flush_each_audit":         e.conf.FlushEachAudit,
+			"observation_level":        string(e.conf.ObservationLevel),
+			"require_local_audit_sink": e.conf.RequireLocalAuditSink,
+			"sinks":                    summaries,
+		},
+	}
+	if err := e.writeSysEvent(ctx, event); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// startupSinkSummary returns the settings of the sink which are included in
+// the startup event.  Settings are only added to it explicitly, so new
+// settings (which may be secrets) aren't included by default.
+func startupSinkSummary(s SinkConfig) map[string]interface{} {
+	eventTypes := make([]string, 0, len(s.EventTypes))
+	for _, t := range s.EventTypes {
+		eventTypes = append(eventTypes, string(t))
+	}
+	summary := map[string]interface{}{
+		"name":        s.Name,
+		"sink_type":   string(s.SinkType),
+		"format":      string(s.Format),
+		"event_types": eventTypes,
+		"target":      s.outputTarget(),
+		"local":       s.isLocal(),
+	}
+	if s.KafkaConfig != nil {
+		guarantee := s.KafkaConfig.DeliveryGuarantee
+		if guarantee == DefaultDeliveryGuarantee {
+			guarantee = BestEffort
+		}
+		summary["delivery_guarantee"] = string(guarantee)
+		summary["tls_enabled"] = s.KafkaConfig.TLSEnabled
+		if s.KafkaConfig.SASLMechanism != "" {
+			summary["sasl_mechanism"] = s.KafkaConfig.SASLMechanism
+		}
+	}
+	if s.GRPCConfig != nil {
+		guarantee := s.GRPCConfig.DeliveryGuarantee
+		if guarantee == DefaultDeliveryGuarantee {
+			guarantee = BestEffort
+		}
+		summary["delivery_guarantee"] = string(guarantee)
+		summary["tls_enabled"] = s.GRPCConfig.TLSEnabled
+	}
+	if s.SpoolPath != "" {
+		summary["spool"] = s.spoolTarget()
+	}
+	if len(s.Mirrors) > 0 {
+		mirrors := make([]interface{}, 0, len(s.Mirrors))
+		for _, m := range s.mirrorConfigs() {
+			mirrors = append(mirrors, startupSinkSummary(m))
+		}
+		summary["mirrors"] = mirrors
+	}
+	return summary
+}
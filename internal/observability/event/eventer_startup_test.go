@@ -0,0 +1,104 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventer_EmitStartupEvent(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	const (
+		testPassword = "test-sasl-password"
+		testCaCert   = "-----BEGIN CERTIFICATE-----test-ca-cert"
+	)
+	c := EventerConfig{
+		AuditEnabled:     true,
+		SysEventsEnabled: true,
+		Sinks: []SinkConfig{
+			{
+				Name:       "stderr",
+				SinkType:   StderrSink,
+				EventTypes: []Type{EveryType},
+				Format:     JSONSinkFormat,
+			},
+			{
+				Name:       "kafka",
+				SinkType:   KafkaSink,
+				EventTypes: []Type{AuditType},
+				Format:     JSONSinkFormat,
+				KafkaConfig: &KafkaSinkConfig{
+					Brokers:           []string{"localhost:9092"},
+					Topic:             "events",
+					DeliveryGuarantee: Enforced,
+					SASLMechanism:     "PLAIN",
+					SASLUsername:      "test-user",
+					SASLPassword:      testPassword,
+					TLSEnabled:        true,
+					TLSCACert:         testCaCert,
+				},
+			},
+		},
+	}
+	p := &testKafkaProducer{}
+	var buf bytes.Buffer
+	e, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, c,
+		WithStderrWriter(&buf),
+		WithKafkaProducer(func(*KafkaSinkConfig) (KafkaProducer, error) { return p, nil }),
+	)
+	require.NoError(t, err)
+	require.NoError(t, e.EmitStartupEvent(ctx))
+
+	out := buf.String()
+	assert.NotContains(t, out, testPassword)
+	assert.NotContains(t, out, testCaCert)
+	assert.NotContains(t, out, "test-user")
+
+	var got struct {
+		EventType string `json:"event_type"`
+		Payload   struct {
+			Op   string `json:"op"`
+			Data struct {
+				AuditEnabled bool                     `json:"audit_enabled"`
+				Sinks        []map[string]interface{} `json:"sinks"`
+			} `json:"data"`
+		} `json:"payload"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(out)), &got))
+	assert.Equal(t, string(SystemType), got.EventType)
+	assert.Equal(t, "event.(Eventer).EmitStartupEvent", got.Payload.Op)
+	assert.True(t, got.Payload.Data.AuditEnabled)
+	require.Len(t, got.Payload.Data.Sinks, 2)
+
+	kafka, stderr := got.Payload.Data.Sinks[0], got.Payload.Data.Sinks[1]
+	assert.Equal(t, "kafka", kafka["name"])
+	assert.Equal(t, string(KafkaSink), kafka["sink_type"])
+	assert.Equal(t, string(Enforced), kafka["delivery_guarantee"])
+	assert.Equal(t, "PLAIN", kafka["sasl_mechanism"])
+	assert.Equal(t, []interface{}{string(AuditType)}, kafka["event_types"])
+	assert.Equal(t, "stderr", stderr["name"])
+	assert.Equal(t, string(StderrSink), stderr["sink_type"])
+	assert.Equal(t, string(JSONSinkFormat), stderr["format"])
+	assert.Equal(t, true, stderr["local"])
+
+	t.Run("sys-events-disabled", func(t *testing.T) {
+		c := c
+		c.SysEventsEnabled = false
+		var buf bytes.Buffer
+		e, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, c,
+			WithStderrWriter(&buf),
+			WithKafkaProducer(func(*KafkaSinkConfig) (KafkaProducer, error) { return p, nil }),
+		)
+		require.NoError(t, err)
+		require.NoError(t, e.EmitStartupEvent(ctx))
+		assert.Empty(t, buf.String())
+	})
+}
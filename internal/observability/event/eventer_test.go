@@ -1,11 +1,18 @@
 package event
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/eventlogger"
 	"github.com/hashicorp/go-hclog"
@@ -54,8 +61,9 @@ func Test_InitSysEventer(t *testing.T) {
 			log:  testLogger,
 			lock: testLock,
 			want: &Eventer{
-				logger: testLogger,
-				conf:   testConfig.EventerConfig,
+				logger:        testLogger,
+				schemaVersion: SchemaVersion,
+				conf:          testConfig.EventerConfig,
 			},
 		},
 		{
@@ -64,7 +72,8 @@ func Test_InitSysEventer(t *testing.T) {
 			log:  testLogger,
 			lock: testLock,
 			want: &Eventer{
-				logger: testLogger,
+				logger:        testLogger,
+				schemaVersion: SchemaVersion,
 				conf: EventerConfig{
 					Sinks: []SinkConfig{
 						{
@@ -104,7 +113,13 @@ func Test_InitSysEventer(t *testing.T) {
 			tt.want.flushableNodes = got.flushableNodes
 			tt.want.auditPipelines = got.auditPipelines
 			tt.want.errPipelines = got.errPipelines
+			tt.want.sysPipelines = got.sysPipelines
+			tt.want.health = got.health
 			tt.want.observationPipelines = got.observationPipelines
+			tt.want.sinks = got.sinks
+			tt.want.stderr = got.stderr
+			tt.want.stderrLock = got.stderrLock
+			tt.want.sensitiveHeaders = newSensitiveHeaders(nil, false)
 			assert.Equal(tt.want, got)
 		})
 	}
@@ -190,6 +205,52 @@ func TestEventer_writeObservation(t *testing.T) {
 	})
 }
 
+// testWedgedBroker simulates a wedged sink by blocking every send until the
+// ctx is done.
+type testWedgedBroker struct {
+	testMockBroker
+	sends int32
+}
+
+func (b *testWedgedBroker) Send(ctx context.Context, _ eventlogger.EventType, _ interface{}) (eventlogger.Status, error) {
+	atomic.AddInt32(&b.sends, 1)
+	<-ctx.Done()
+	return eventlogger.Status{}, ctx.Err()
+}
+
+func TestEventer_writeErrorCanceled(t *testing.T) {
+	t.Parallel()
+	testSetup := TestEventerConfig(t, "TestEventer_writeErrorCanceled")
+	var logBuf bytes.Buffer
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex:  testLock,
+		Output: &logBuf,
+	})
+	b := &testWedgedBroker{}
+	eventer, err := NewEventer(testLogger, testLock, testSetup.EventerConfig, TestWithBroker(t, b))
+	require.NoError(t, err)
+
+	testError, err := newError("TestEventer_writeErrorCanceled", fmt.Errorf("%s: no msg: test", ErrIo))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	err = eventer.writeError(ctx, testError)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, int64(time.Since(start)), int64(5*time.Second))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&b.sends), "no retries should be attempted after the ctx is done")
+
+	testLock.Lock()
+	defer testLock.Unlock()
+	assert.Contains(t, logBuf.String(), "unable to send error event before the context was done")
+	assert.Contains(t, logBuf.String(), "TestEventer_writeErrorCanceled")
+	assert.NotContains(t, logBuf.String(), "encountered an error sending an error event")
+}
+
 func TestEventer_writeAudit(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -341,13 +402,31 @@ func Test_NewEventer(t *testing.T) {
 			logger:    testLogger,
 			wantErrIs: ErrInvalidParameter,
 		},
+		{
+			name: "no-error-sink",
+			config: EventerConfig{
+				AuditEnabled: true,
+				Sinks: []SinkConfig{
+					{
+						Name:       "audit-only",
+						EventTypes: []Type{AuditType},
+						Format:     JSONSinkFormat,
+						SinkType:   StderrSink,
+					},
+				},
+			},
+			logger:    testLogger,
+			lock:      testLock,
+			wantErrIs: ErrInvalidParameter,
+		},
 		{
 			name:   "success-with-default-config",
 			config: EventerConfig{},
 			logger: testLogger,
 			lock:   testLock,
 			want: &Eventer{
-				logger: testLogger,
+				logger:        testLogger,
+				schemaVersion: SchemaVersion,
 				conf: EventerConfig{
 					Sinks: []SinkConfig{
 						{
@@ -381,8 +460,9 @@ func Test_NewEventer(t *testing.T) {
 			logger: testLogger,
 			lock:   testLock,
 			want: &Eventer{
-				logger: testLogger,
-				conf:   testSetup.EventerConfig,
+				logger:        testLogger,
+				schemaVersion: SchemaVersion,
+				conf:          testSetup.EventerConfig,
 			},
 			wantRegistered: []string{
 				"json",              // fmt for everything
@@ -415,8 +495,9 @@ func Test_NewEventer(t *testing.T) {
 			logger: testLogger,
 			lock:   testLock,
 			want: &Eventer{
-				logger: testLogger,
-				conf:   testSetupWithOpts.EventerConfig,
+				logger:        testLogger,
+				schemaVersion: SchemaVersion,
+				conf:          testSetupWithOpts.EventerConfig,
 			},
 			wantRegistered: []string{
 				"json",              // fmt for everything
@@ -470,7 +551,13 @@ func Test_NewEventer(t *testing.T) {
 			tt.want.flushableNodes = got.flushableNodes
 			tt.want.auditPipelines = got.auditPipelines
 			tt.want.errPipelines = got.errPipelines
+			tt.want.sysPipelines = got.sysPipelines
+			tt.want.health = got.health
 			tt.want.observationPipelines = got.observationPipelines
+			tt.want.sinks = got.sinks
+			tt.want.stderr = got.stderr
+			tt.want.stderrLock = got.stderrLock
+			tt.want.sensitiveHeaders = newSensitiveHeaders(nil, false)
 			assert.Equal(tt.want, got)
 
 			assert.Lenf(testBroker.registeredNodeIds, len(tt.wantRegistered), "got nodes: %q", testBroker.registeredNodeIds)
@@ -558,6 +645,194 @@ func TestEventer_Reopen(t *testing.T) {
 		require.NoError(e.Reopen())
 		assert.True(e.broker.(*testMockBroker).reopened)
 	})
+	t.Run("waits-for-writes", func(t *testing.T) {
+		e, _ := NewTestEventer(t)
+		e.broker = &testMockBroker{}
+
+		// the read lock is held while an event is written, so Reopen must
+		// wait for the write to finish
+		e.closeLock.RLock()
+		done := make(chan error)
+		go func() { done <- e.Reopen() }()
+		select {
+		case <-done:
+			t.Fatal("reopened while an event was being written")
+		case <-time.After(50 * time.Millisecond):
+		}
+		e.closeLock.RUnlock()
+		require.NoError(t, <-done)
+		assert.True(t, e.broker.(*testMockBroker).reopened)
+	})
+	t.Run("closed", func(t *testing.T) {
+		e, _ := NewTestEventer(t)
+		require.NoError(t, e.Close(context.Background()))
+		assert.ErrorIs(t, e.Reopen(), ErrEventerClosed)
+		_, err := e.ReopenSinks()
+		assert.ErrorIs(t, err, ErrEventerClosed)
+	})
+	t.Run("concurrent-writes", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		ctx := context.Background()
+		const (
+			writers         = 4
+			writesPerWriter = 200
+			reopens         = 50
+		)
+		dir := t.TempDir()
+		c := EventerConfig{
+			AuditEnabled: true,
+			Sinks: []SinkConfig{
+				{
+					Name:       "audit",
+					SinkType:   FileSink,
+					EventTypes: []Type{AuditType},
+					Format:     JSONSinkFormat,
+					Path:       dir,
+					FileName:   "audit.log",
+				},
+				{
+					Name:       "discard",
+					SinkType:   DiscardSink,
+					EventTypes: []Type{EveryType},
+					Format:     JSONSinkFormat,
+				},
+			},
+		}
+		testLock := &sync.Mutex{}
+		testLogger := hclog.New(&hclog.LoggerOptions{
+			Mutex: testLock,
+			Name:  "test",
+		})
+		e, err := NewEventer(testLogger, testLock, c)
+		require.NoError(err)
+
+		var wg sync.WaitGroup
+		for w := 0; w < writers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < writesPerWriter; i++ {
+					a, err := newAudit("TestEventer_Reopen", WithId(fmt.Sprintf("%d-%d", w, i)), WithFlush())
+					if !assert.NoError(err) {
+						return
+					}
+					assert.NoError(e.writeAudit(ctx, a))
+				}
+			}(w)
+		}
+		// the file is rotated before each reopen, so each reopen opens a
+		// new file while the writers are writing.  The file isn't created
+		// until the first event is written to it.
+		for i := 0; i < reopens; i++ {
+			err := os.Rename(filepath.Join(dir, "audit.log"), filepath.Join(dir, fmt.Sprintf("audit-%d.log", i)))
+			if !os.IsNotExist(err) {
+				require.NoError(err)
+			}
+			require.NoError(e.Reopen())
+		}
+		wg.Wait()
+		require.NoError(e.Close(ctx))
+
+		files, err := filepath.Glob(filepath.Join(dir, "audit*.log"))
+		require.NoError(err)
+		got := map[string]int{}
+		for _, f := range files {
+			b, err := ioutil.ReadFile(f)
+			require.NoError(err)
+			for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+				if line == "" {
+					continue
+				}
+				var ev struct {
+					Payload struct {
+						Id string `json:"id"`
+					} `json:"payload"`
+				}
+				require.NoError(json.Unmarshal([]byte(line), &ev), line)
+				got[ev.Payload.Id]++
+			}
+		}
+		// every audit event was written exactly once
+		assert.Len(got, writers*writesPerWriter)
+		for id, n := range got {
+			assert.Equal(1, n, id)
+		}
+	})
+}
+
+func TestEventer_ReopenSinks(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	t.Run("rotated-file", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		testSetup := TestEventerConfig(t, "TestEventer_ReopenSinks")
+		e, err := NewEventer(testLogger, testLock, testSetup.EventerConfig)
+		require.NoError(err)
+
+		testError, err := newError("TestEventer_ReopenSinks", fmt.Errorf("%s: no msg: test", ErrIo))
+		require.NoError(err)
+		require.NoError(e.writeError(ctx, testError))
+
+		rotated := testSetup.ErrorEvents.Name() + ".1"
+		require.NoError(os.Rename(testSetup.ErrorEvents.Name(), rotated))
+		t.Cleanup(func() { os.Remove(rotated) })
+
+		results, err := e.ReopenSinks()
+		require.NoError(err)
+		for _, r := range results {
+			assert.NoError(r.Error, r.Name)
+		}
+		require.NoError(e.writeError(ctx, testError))
+
+		b, err := ioutil.ReadFile(testSetup.ErrorEvents.Name())
+		require.NoError(err)
+		assert.Contains(string(b), "TestEventer_ReopenSinks")
+	})
+	t.Run("failed-sink", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		var producers int
+		c := EventerConfig{
+			Sinks: []SinkConfig{
+				{
+					Name:        "kafka-sink",
+					SinkType:    KafkaSink,
+					EventTypes:  []Type{EveryType},
+					Format:      JSONSinkFormat,
+					KafkaConfig: &KafkaSinkConfig{Brokers: []string{"localhost:9092"}, Topic: "events"},
+				},
+				{
+					Name:       "stderr",
+					SinkType:   StderrSink,
+					EventTypes: []Type{EveryType},
+					Format:     JSONSinkFormat,
+				},
+			},
+		}
+		e, err := NewEventer(testLogger, testLock, c, WithKafkaProducer(func(*KafkaSinkConfig) (KafkaProducer, error) {
+			producers++
+			if producers > 1 {
+				return nil, fmt.Errorf("brokers unavailable")
+			}
+			return &testKafkaProducer{}, nil
+		}))
+		require.NoError(err)
+		results, err := e.ReopenSinks()
+		require.Error(err)
+		assert.Contains(err.Error(), `unable to reopen sink "kafka-sink"`)
+		assert.NotContains(err.Error(), `"stderr"`)
+
+		// the stderr sink is reopened even though the kafka sink failed
+		require.Len(results, 2)
+		assert.Equal("kafka-sink", results[0].Name)
+		assert.Error(results[0].Error)
+		assert.Equal("stderr", results[1].Name)
+		assert.NoError(results[1].Error)
+	})
 }
 
 func TestEventer_FlushNodes(t *testing.T) {
@@ -583,6 +858,176 @@ func TestEventer_FlushNodes(t *testing.T) {
 	})
 }
 
+func TestEventer_Close(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	tmpFile, err := ioutil.TempFile("./", "tmp-eventer-TestEventer_Close")
+	require.NoError(t, err)
+	tmpFile.Close()
+	t.Cleanup(func() { os.Remove(tmpFile.Name()) })
+	fileName, err := filepath.Abs(tmpFile.Name())
+	require.NoError(t, err)
+
+	// openHandles returns the number of this process's open file handles for
+	// the file.
+	openHandles := func(t *testing.T) int {
+		t.Helper()
+		fds, err := ioutil.ReadDir("/proc/self/fd")
+		if err != nil {
+			t.Skip("unable to list open file handles:", err)
+		}
+		var n int
+		for _, fd := range fds {
+			if target, err := os.Readlink(filepath.Join("/proc/self/fd", fd.Name())); err == nil && target == fileName {
+				n++
+			}
+		}
+		return n
+	}
+
+	c := EventerConfig{
+		AuditEnabled:        true,
+		ObservationsEnabled: true,
+		Sinks: []SinkConfig{
+			{
+				Name:       "every-type-file-sink",
+				SinkType:   FileSink,
+				EventTypes: []Type{EveryType},
+				Format:     JSONSinkFormat,
+				Path:       "./",
+				FileName:   tmpFile.Name(),
+			},
+		},
+	}
+	e, err := NewEventer(testLogger, testLock, c)
+	require.NoError(t, err)
+
+	// an observation which isn't flushed is written to the file by Close
+	testObservation, err := newObservation("TestEventer_Close", WithId("obs-id"), WithDetails(map[string]interface{}{"name": "value"}))
+	require.NoError(t, err)
+	require.NoError(t, e.writeObservation(ctx, testObservation))
+	testAudit, err := newAudit("TestEventer_Close", WithId("audit-id"), WithFlush())
+	require.NoError(t, err)
+	require.NoError(t, e.writeAudit(ctx, testAudit))
+	assert.Equal(t, 1, openHandles(t))
+
+	require.NoError(t, e.Close(ctx))
+	assert.Equal(t, 0, openHandles(t))
+	b, err := ioutil.ReadFile(tmpFile.Name())
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"id":"obs-id"`)
+	assert.Contains(t, string(b), `"id":"audit-id"`)
+
+	err = e.writeAudit(ctx, testAudit)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrEventerClosed)
+	err = e.writeObservation(ctx, testObservation)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrEventerClosed)
+	err = e.writeSysEvent(ctx, &sysEvent{Id: "sys-id", Op: "TestEventer_Close"})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrEventerClosed)
+	testError, err := newError("TestEventer_Close", fmt.Errorf("%s: no msg: test", ErrIo))
+	require.NoError(t, err)
+	err = e.writeError(ctx, testError)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrEventerClosed)
+	assert.Equal(t, 0, openHandles(t))
+
+	// closing a closed eventer is a no op
+	require.NoError(t, e.Close(ctx))
+}
+
+func TestEventer_FlushEachAudit(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	newTestEventer := func(t *testing.T, flushEachAudit bool) (*Eventer, string) {
+		t.Helper()
+		tmpFile, err := ioutil.TempFile("./", "tmp-audit-TestEventer_FlushEachAudit")
+		require.NoError(t, err)
+		t.Cleanup(func() { os.Remove(tmpFile.Name()) })
+		c := EventerConfig{
+			AuditEnabled:   true,
+			FlushEachAudit: flushEachAudit,
+			Sinks: []SinkConfig{
+				{
+					Name:       "audit-file-sink",
+					SinkType:   FileSink,
+					EventTypes: []Type{AuditType, ErrorType},
+					Format:     JSONSinkFormat,
+					Path:       "./",
+					FileName:   tmpFile.Name(),
+				},
+			},
+		}
+		e, err := NewEventer(testLogger, testLock, c)
+		require.NoError(t, err)
+		return e, tmpFile.Name()
+	}
+	tests := []struct {
+		name           string
+		flushEachAudit bool
+		wantWritten    bool
+	}{
+		{
+			name:           "flush-each-audit",
+			flushEachAudit: true,
+			wantWritten:    true,
+		},
+		{
+			name: "gated",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			e, fileName := newTestEventer(t, tt.flushEachAudit)
+			if tt.flushEachAudit {
+				assert.Len(e.auditGates, 1)
+			} else {
+				assert.Nil(e.auditGates)
+			}
+
+			// the audit isn't flushed by its caller, so it's gated unless
+			// the eventer flushes each audit.
+			testAudit, err := newAudit("TestEventer_FlushEachAudit")
+			require.NoError(err)
+			require.NoError(e.writeAudit(ctx, testAudit))
+
+			b, err := ioutil.ReadFile(fileName)
+			require.NoError(err)
+			if tt.wantWritten {
+				assert.Contains(string(b), testAudit.Id)
+				return
+			}
+			assert.Empty(b)
+		})
+	}
+	t.Run("flush-error", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		e, _ := newTestEventer(t, true)
+		node := &testFlushNode{raiseError: true}
+		e.auditGates = map[eventlogger.PipelineID]flushable{"test-pipeline": node}
+
+		testAudit, err := newAudit("TestEventer_FlushEachAudit")
+		require.NoError(err)
+		err = e.writeAudit(ctx, testAudit)
+		require.Error(err)
+		assert.Contains(err.Error(), "flush-all")
+		assert.True(node.flushed)
+	})
+}
+
 type testFlushNode struct {
 	flushed    bool
 	raiseError bool
@@ -595,3 +1040,911 @@ func (t *testFlushNode) FlushAll(_ context.Context) error {
 	}
 	return nil
 }
+
+func TestEventer_schemaVersion(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	const pinnedVersion = "v-test"
+
+	testSetup := TestEventerConfig(t, "TestEventer_schemaVersion")
+	testSetup.EventerConfig.SysEventsEnabled = true
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	e, err := NewEventer(testLogger, testLock, testSetup.EventerConfig, WithSchemaVersion(pinnedVersion))
+	require.NoError(t, err)
+
+	testObservation, err := newObservation("TestEventer_schemaVersion", WithDetails(map[string]interface{}{"name": "details"}), WithFlush())
+	require.NoError(t, err)
+	require.NoError(t, e.writeObservation(ctx, testObservation))
+
+	testAudit, err := newAudit("TestEventer_schemaVersion", WithFlush())
+	require.NoError(t, err)
+	require.NoError(t, e.writeAudit(ctx, testAudit))
+
+	testError, err := newError("TestEventer_schemaVersion", fmt.Errorf("%s: no msg: test", ErrIo))
+	require.NoError(t, err)
+	require.NoError(t, e.writeError(ctx, testError))
+
+	id, err := newId(string(SystemType))
+	require.NoError(t, err)
+	require.NoError(t, e.writeSysEvent(ctx, &sysEvent{Id: Id(id), Op: "TestEventer_schemaVersion", Data: map[string]interface{}{"name": "data"}}))
+
+	b, err := ioutil.ReadFile(testSetup.AllEvents.Name())
+	require.NoError(t, err)
+	gotTypes := map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		var got map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &got))
+		eventType := got["event_type"].(string)
+		payload := got["payload"].(map[string]interface{})
+		switch eventType {
+		case string(ObservationType):
+			payload = payload[HeaderField].(map[string]interface{})
+		}
+		assert.Equalf(t, pinnedVersion, payload[VersionField], "%s event: %s", eventType, line)
+		gotTypes[eventType] = true
+	}
+	assert.Len(t, gotTypes, 4)
+}
+
+func TestEventer_sendWarnings(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testSetup := TestEventerConfig(t, "TestEventer_sendWarnings")
+	var logBuf bytes.Buffer
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex:  testLock,
+		Output: &logBuf,
+	})
+	testWarning := fmt.Errorf("%s: sink failed: %w", "test", ErrIo)
+	gotWarnings := map[Type][]error{}
+	e, err := NewEventer(testLogger, testLock, testSetup.EventerConfig,
+		TestWithBroker(t, &testMockBroker{warningsOnSend: []error{testWarning}}),
+		WithWarningsHook(func(t Type, warnings []error) {
+			gotWarnings[t] = append(gotWarnings[t], warnings...)
+		}),
+	)
+	require.NoError(t, err)
+	e.conf.SysEventsEnabled = true
+
+	testObservation, err := newObservation("TestEventer_sendWarnings", WithHeader(map[string]interface{}{"name": "header"}))
+	require.NoError(t, err)
+	require.NoError(t, e.writeObservation(ctx, testObservation))
+
+	testAudit, err := newAudit("TestEventer_sendWarnings")
+	require.NoError(t, err)
+	require.NoError(t, e.writeAudit(ctx, testAudit))
+
+	testError, err := newError("TestEventer_sendWarnings", fmt.Errorf("%s: no msg: test", ErrIo))
+	require.NoError(t, err)
+	require.NoError(t, e.writeError(ctx, testError))
+
+	id, err := newId(string(SystemType))
+	require.NoError(t, err)
+	require.NoError(t, e.writeSysEvent(ctx, &sysEvent{Id: Id(id), Op: "TestEventer_sendWarnings", Data: map[string]interface{}{"name": "data"}}))
+
+	for _, et := range []Type{ObservationType, AuditType, ErrorType, SystemType} {
+		assert.Equalf(t, []error{testWarning}, gotWarnings[et], "%s event", et)
+		assert.Containsf(t, logBuf.String(), fmt.Sprintf("event_type=%s", et), "%s event", et)
+	}
+	assert.Contains(t, logBuf.String(), "[WARN]")
+	assert.Contains(t, logBuf.String(), testWarning.Error())
+}
+
+func TestEventer_WithWarningsAsEvents(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testSetup := TestEventerConfig(t, "TestEventer_WithWarningsAsEvents")
+	testWarning := fmt.Errorf("%s: sink failed: %w", "test", ErrIo)
+	newEventer := func(t *testing.T, b *testMockBroker, sysEventsEnabled bool) *Eventer {
+		t.Helper()
+		c := testSetup.EventerConfig
+		c.SysEventsEnabled = sysEventsEnabled
+		e, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, c, TestWithBroker(t, b), WithWarningsAsEvents(true))
+		require.NoError(t, err)
+		return e
+	}
+
+	t.Run("warnings-sent-as-sys-events", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		b := &testMockBroker{warningsOnSend: []error{testWarning}}
+		e := newEventer(t, b, true)
+
+		testAudit, err := newAudit("TestEventer_WithWarningsAsEvents")
+		require.NoError(err)
+		require.NoError(e.writeAudit(ctx, testAudit))
+
+		sent := b.sent[eventlogger.EventType(SystemType)]
+		require.Len(sent, 1)
+		got, ok := sent[0].(*sysEvent)
+		require.True(ok)
+		assert.Equal(Op("event.(Eventer).sendWarningsEvent"), got.Op)
+		assert.Equal(string(AuditType), got.Data["event_type"])
+		assert.Equal([]string{testWarning.Error()}, got.Data["warnings"])
+
+		// the warnings of system events, including the warnings event
+		// itself, aren't sent as system events
+		id, err := newId(string(SystemType))
+		require.NoError(err)
+		require.NoError(e.writeSysEvent(ctx, &sysEvent{Id: Id(id), Op: "TestEventer_WithWarningsAsEvents", Data: map[string]interface{}{"name": "data"}}))
+		assert.Len(b.sent[eventlogger.EventType(SystemType)], 2)
+	})
+	t.Run("sys-events-disabled", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		b := &testMockBroker{warningsOnSend: []error{testWarning}}
+		e := newEventer(t, b, false)
+
+		testError, err := newError("TestEventer_WithWarningsAsEvents", fmt.Errorf("%s: no msg: test", ErrIo))
+		require.NoError(err)
+		require.NoError(e.writeError(ctx, testError))
+		assert.Len(b.sent[eventlogger.EventType(ErrorType)], 1)
+		assert.Empty(b.sent[eventlogger.EventType(SystemType)])
+	})
+	t.Run("no-warnings", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		b := &testMockBroker{}
+		e := newEventer(t, b, true)
+
+		testError, err := newError("TestEventer_WithWarningsAsEvents", fmt.Errorf("%s: no msg: test", ErrIo))
+		require.NoError(err)
+		require.NoError(e.writeError(ctx, testError))
+		assert.Empty(b.sent[eventlogger.EventType(SystemType)])
+	})
+}
+
+func TestEventer_WithStderrWriter(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	var buf bytes.Buffer
+	e, err := NewEventer(testLogger, testLock, EventerConfig{}, WithStderrWriter(&buf))
+	require.NoError(t, err)
+
+	testError, err := newError("TestEventer_WithStderrWriter", fmt.Errorf("%s: no msg: test", ErrIo))
+	require.NoError(t, err)
+	require.NoError(t, e.writeError(ctx, testError))
+
+	testLock.Lock()
+	defer testLock.Unlock()
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, string(ErrorType), got["event_type"])
+	assert.Equal(t, string(testError.Id), got["payload"].(map[string]interface{})[IdField])
+}
+
+func TestEventer_defaultSinkFallback(t *testing.T) {
+	t.Parallel()
+	t.Run("warns", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		testLock := &sync.Mutex{}
+		var logBuf bytes.Buffer
+		testLogger := hclog.New(&hclog.LoggerOptions{
+			Mutex:  testLock,
+			Name:   "test",
+			Output: &logBuf,
+		})
+		var buf bytes.Buffer
+		_, err := NewEventer(testLogger, testLock, EventerConfig{SysEventsEnabled: true}, WithStderrWriter(&buf))
+		require.NoError(err)
+
+		testLock.Lock()
+		defer testLock.Unlock()
+		assert.Contains(logBuf.String(), "[WARN]")
+		assert.Contains(logBuf.String(), "no event sinks configured, falling back to the default sink")
+
+		var got map[string]interface{}
+		require.NoError(json.Unmarshal(buf.Bytes(), &got))
+		assert.Equal(string(SystemType), got["event_type"])
+		payload := got["payload"].(map[string]interface{})
+		assert.Equal("event.(Eventer).sendDefaultSinkEvent", payload[OpField])
+		assert.Equal(DefaultSink().Name, payload["data"].(map[string]interface{})["sink"])
+	})
+	t.Run("configured-sinks", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		testLock := &sync.Mutex{}
+		var logBuf bytes.Buffer
+		testLogger := hclog.New(&hclog.LoggerOptions{
+			Mutex:  testLock,
+			Name:   "test",
+			Output: &logBuf,
+		})
+		var buf bytes.Buffer
+		c := EventerConfig{
+			SysEventsEnabled: true,
+			Sinks:            []SinkConfig{DefaultSink()},
+		}
+		_, err := NewEventer(testLogger, testLock, c, WithStderrWriter(&buf))
+		require.NoError(err)
+
+		testLock.Lock()
+		defer testLock.Unlock()
+		assert.NotContains(logBuf.String(), "falling back to the default sink")
+		assert.Empty(buf.String())
+	})
+	t.Run("WithNoDefaultSink", func(t *testing.T) {
+		assert := assert.New(t)
+		testLock := &sync.Mutex{}
+		testLogger := hclog.New(&hclog.LoggerOptions{
+			Mutex: testLock,
+			Name:  "test",
+		})
+		e, err := NewEventer(testLogger, testLock, EventerConfig{SysEventsEnabled: true}, WithNoDefaultSink())
+		assert.Nil(e)
+		assert.ErrorIs(err, ErrNoSinks)
+		assert.ErrorIs(err, ErrInvalidParameter)
+	})
+}
+
+func TestEventer_WithDedupeFileSinks(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	sinks := func() []SinkConfig {
+		return []SinkConfig{
+			{
+				Name:       "audit",
+				SinkType:   FileSink,
+				EventTypes: []Type{AuditType},
+				Format:     JSONSinkFormat,
+				Path:       tmpDir,
+				FileName:   "events.log",
+			},
+			{
+				Name:       "everything-else",
+				SinkType:   FileSink,
+				EventTypes: []Type{ObservationType, ErrorType, AuditType},
+				Format:     JSONSinkFormat,
+				Path:       tmpDir,
+				FileName:   "events.log",
+			},
+		}
+	}
+	newLogger := func(buf *bytes.Buffer, l *sync.Mutex) hclog.Logger {
+		return hclog.New(&hclog.LoggerOptions{
+			Mutex:  l,
+			Name:   "test",
+			Output: buf,
+		})
+	}
+
+	t.Run("default-is-error", func(t *testing.T) {
+		testLock := &sync.Mutex{}
+		var logBuf bytes.Buffer
+		_, err := NewEventer(newLogger(&logBuf, testLock), testLock, EventerConfig{AuditEnabled: true, Sinks: sinks()})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrDuplicateSink)
+	})
+	t.Run("merged", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		testLock := &sync.Mutex{}
+		var logBuf bytes.Buffer
+		c := EventerConfig{AuditEnabled: true, ObservationsEnabled: true, Sinks: sinks()}
+		e, err := NewEventer(newLogger(&logBuf, testLock), testLock, c, WithDedupeFileSinks())
+		require.NoError(err)
+		t.Cleanup(func() { _ = e.Close(context.Background()) })
+
+		require.Len(e.conf.Sinks, 1)
+		assert.Equal("audit", e.conf.Sinks[0].Name)
+		assert.Equal([]Type{AuditType, ObservationType, ErrorType}, e.conf.Sinks[0].EventTypes)
+		// the caller's config isn't changed
+		assert.Len(c.Sinks, 2)
+		assert.Equal([]Type{AuditType}, c.Sinks[0].EventTypes)
+
+		testLock.Lock()
+		defer testLock.Unlock()
+		assert.Contains(logBuf.String(), "[WARN]")
+		assert.Contains(logBuf.String(), "collapsing duplicate file sink")
+		assert.Contains(logBuf.String(), "sink=everything-else")
+	})
+	t.Run("every-type", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		testLock := &sync.Mutex{}
+		var logBuf bytes.Buffer
+		c := EventerConfig{AuditEnabled: true, Sinks: sinks()}
+		c.Sinks[1].EventTypes = []Type{EveryType}
+		e, err := NewEventer(newLogger(&logBuf, testLock), testLock, c, WithDedupeFileSinks())
+		require.NoError(err)
+		t.Cleanup(func() { _ = e.Close(context.Background()) })
+		require.Len(e.conf.Sinks, 1)
+		assert.Equal([]Type{EveryType}, e.conf.Sinks[0].EventTypes)
+	})
+	t.Run("different-settings", func(t *testing.T) {
+		testLock := &sync.Mutex{}
+		var logBuf bytes.Buffer
+		c := EventerConfig{AuditEnabled: true, Sinks: sinks()}
+		c.Sinks[1].Format = CEFSinkFormat
+		_, err := NewEventer(newLogger(&logBuf, testLock), testLock, c, WithDedupeFileSinks())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrDuplicateSink)
+	})
+}
+
+func TestEventer_WithStrictSerialization(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testLock := &sync.Mutex{}
+	var logBuf bytes.Buffer
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex:  testLock,
+		Name:   "test",
+		Output: &logBuf,
+	})
+	tmpFile, err := ioutil.TempFile("./", "tmp-strict-TestEventer_WithStrictSerialization")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.Remove(tmpFile.Name()) })
+	c := EventerConfig{
+		AuditEnabled:        true,
+		ObservationsEnabled: true,
+		SysEventsEnabled:    true,
+		Sinks: []SinkConfig{
+			{
+				Name:       "every-type-file-sink",
+				SinkType:   FileSink,
+				EventTypes: []Type{EveryType},
+				Format:     JSONSinkFormat,
+				Path:       "./",
+				FileName:   tmpFile.Name(),
+			},
+		},
+	}
+	e, err := NewEventer(testLogger, testLock, c, WithStrictSerialization())
+	require.NoError(t, err)
+
+	unserializable := map[string]interface{}{"ch": make(chan int), "fn": func() {}}
+	tests := []struct {
+		name      string
+		write     func() error
+		wantErrIs error
+	}{
+		{
+			name: "observation-dropped",
+			write: func() error {
+				testObservation, err := newObservation("TestEventer_WithStrictSerialization", WithDetails(unserializable), WithFlush())
+				require.NoError(t, err)
+				return e.writeObservation(ctx, testObservation)
+			},
+		},
+		{
+			name: "system-dropped",
+			write: func() error {
+				return e.writeSysEvent(ctx, &sysEvent{Id: "sys-id", Op: "TestEventer_WithStrictSerialization", Data: unserializable})
+			},
+		},
+		{
+			name: "audit-rejected",
+			write: func() error {
+				testAudit, err := newAudit("TestEventer_WithStrictSerialization", WithFlush())
+				require.NoError(t, err)
+				testAudit.Header = unserializable
+				return e.writeAudit(ctx, testAudit)
+			},
+			wantErrIs: ErrInvalidParameter,
+		},
+		{
+			name: "error-rejected",
+			write: func() error {
+				testError, err := newError("TestEventer_WithStrictSerialization", fmt.Errorf("%s: no msg: test", ErrIo))
+				require.NoError(t, err)
+				testError.Header = unserializable
+				return e.writeError(ctx, testError)
+			},
+			wantErrIs: ErrInvalidParameter,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			err := tt.write()
+			if tt.wantErrIs != nil {
+				require.Error(err)
+				assert.ErrorIs(err, tt.wantErrIs)
+				assert.Contains(err.Error(), "unsupported type")
+				return
+			}
+			require.NoError(err)
+		})
+	}
+
+	// none of the events reached the sink, and the dropped events were logged
+	b, err := ioutil.ReadFile(tmpFile.Name())
+	require.NoError(t, err)
+	assert.Empty(t, b)
+	testLock.Lock()
+	defer testLock.Unlock()
+	assert.Equal(t, 2, strings.Count(logBuf.String(), "dropping event which can't be serialized"))
+}
+
+func TestEventer_DiscardSink(t *testing.T) {
+	t.Parallel()
+	assert, require := assert.New(t), require.New(t)
+	ctx := context.Background()
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	tmpFile, err := ioutil.TempFile("./", "tmp-discard-TestEventer_DiscardSink")
+	require.NoError(err)
+	t.Cleanup(func() { os.Remove(tmpFile.Name()) })
+	c := EventerConfig{
+		AuditEnabled:        true,
+		ObservationsEnabled: true,
+		Sinks: []SinkConfig{
+			{
+				Name:       "muted-audits",
+				SinkType:   DiscardSink,
+				EventTypes: []Type{AuditType},
+				Format:     JSONSinkFormat,
+			},
+			{
+				Name:       "muted-errors",
+				SinkType:   DiscardSink,
+				EventTypes: []Type{ErrorType},
+				Format:     JSONSinkFormat,
+			},
+			{
+				Name:       "observations",
+				SinkType:   FileSink,
+				EventTypes: []Type{ObservationType},
+				Format:     JSONSinkFormat,
+				Path:       "./",
+				FileName:   tmpFile.Name(),
+			},
+		},
+	}
+	// any number of discard sinks are allowed, since they don't have an
+	// output target
+	require.NoError(ValidateConfig(c))
+	e, err := NewEventer(testLogger, testLock, c)
+	require.NoError(err)
+
+	// audit and error events have an enforced delivery, which succeeds
+	// against a discard sink
+	for i := 0; i < 3; i++ {
+		testAudit, err := newAudit("TestEventer_DiscardSink", WithFlush())
+		require.NoError(err)
+		require.NoError(e.writeAudit(ctx, testAudit))
+		testError, err := newError("TestEventer_DiscardSink", fmt.Errorf("%s: test", ErrIo))
+		require.NoError(err)
+		require.NoError(e.writeError(ctx, testError))
+	}
+	testObservation, err := newObservation("TestEventer_DiscardSink", WithDetails(map[string]interface{}{"name": "test"}), WithFlush())
+	require.NoError(err)
+	require.NoError(e.writeObservation(ctx, testObservation))
+	require.NoError(e.Close(ctx))
+
+	b, err := ioutil.ReadFile(tmpFile.Name())
+	require.NoError(err)
+	assert.Contains(string(b), testObservation.ID)
+	assert.NotContains(string(b), string(AuditType))
+}
+
+func TestEventer_FileMode(t *testing.T) {
+	t.Parallel()
+	assert, require := assert.New(t), require.New(t)
+	ctx := context.Background()
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	dir := filepath.Join(t.TempDir(), "audit", "logs")
+	c := EventerConfig{
+		AuditEnabled: true,
+		Sinks: []SinkConfig{
+			{
+				Name:       "audit",
+				SinkType:   FileSink,
+				EventTypes: []Type{AuditType, ErrorType},
+				Format:     JSONSinkFormat,
+				Path:       dir,
+				FileName:   "audit.log",
+				FileMode:   0o640,
+				DirMode:    0o750,
+			},
+		},
+	}
+	e, err := NewEventer(testLogger, testLock, c)
+	require.NoError(err)
+	testAudit, err := newAudit("TestEventer_FileMode", WithFlush())
+	require.NoError(err)
+	require.NoError(e.writeAudit(ctx, testAudit))
+
+	fi, err := os.Stat(filepath.Join(dir, "audit.log"))
+	require.NoError(err)
+	assert.Equal(os.FileMode(0o640), fi.Mode().Perm())
+	for _, d := range []string{dir, filepath.Dir(dir)} {
+		fi, err = os.Stat(d)
+		require.NoError(err)
+		assert.Equal(os.FileMode(0o750), fi.Mode().Perm(), d)
+	}
+
+	// the mode is applied again when the file is reopened
+	require.NoError(os.Chmod(filepath.Join(dir, "audit.log"), 0o644))
+	require.NoError(e.Reopen())
+	fi, err = os.Stat(filepath.Join(dir, "audit.log"))
+	require.NoError(err)
+	assert.Equal(os.FileMode(0o640), fi.Mode().Perm())
+	require.NoError(e.Close(ctx))
+}
+
+func TestEventer_RotateSchedule(t *testing.T) {
+	t.Parallel()
+	assert, require := assert.New(t), require.New(t)
+	ctx := context.Background()
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	dir := t.TempDir()
+	c := EventerConfig{
+		AuditEnabled: true,
+		Sinks: []SinkConfig{
+			{
+				Name:           "audit",
+				SinkType:       FileSink,
+				EventTypes:     []Type{AuditType, ErrorType},
+				Format:         JSONSinkFormat,
+				Path:           dir,
+				FileName:       "audit.log",
+				RotateSchedule: "daily@00:00",
+			},
+		},
+	}
+	e, err := NewEventer(testLogger, testLock, c)
+	require.NoError(err)
+	require.Len(e.rotationSchedulers, 1)
+	rs := e.rotationSchedulers[0]
+
+	testAudit, err := newAudit("TestEventer_RotateSchedule", WithFlush())
+	require.NoError(err)
+	require.NoError(e.writeAudit(ctx, testAudit))
+	// the file is named as a rotated file, since it will be rotated
+	files, err := filepath.Glob(filepath.Join(dir, "audit-*.log"))
+	require.NoError(err)
+	assert.Len(files, 1)
+
+	// closing the eventer stops its schedulers
+	require.NoError(e.Close(ctx))
+	select {
+	case <-rs.done:
+	default:
+		assert.Fail("rotation scheduler is still running")
+	}
+}
+
+func TestEventer_ErrorStackTraces(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	tests := []struct {
+		name      string
+		opt       []Option
+		wantDepth int
+	}{
+		{name: "disabled"},
+		{name: "default-depth", opt: []Option{WithErrorStackTraces(0)}, wantDepth: defaultErrorStackDepth},
+		{name: "max-depth", opt: []Option{WithErrorStackTraces(1)}, wantDepth: 1},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			dir := t.TempDir()
+			c := EventerConfig{
+				Sinks: []SinkConfig{
+					{
+						Name:       "errors",
+						SinkType:   FileSink,
+						EventTypes: []Type{ErrorType},
+						Format:     JSONSinkFormat,
+						Path:       dir,
+						FileName:   "errors.log",
+					},
+				},
+			}
+			e, err := NewEventer(testLogger, testLock, c, tt.opt...)
+			require.NoError(err)
+			t.Cleanup(func() { e.Close(ctx) })
+			assert.Equal(tt.wantDepth, e.errorStackDepth)
+
+			testError, err := newError("TestEventer_ErrorStackTraces", fmt.Errorf("%s: no msg: test", ErrIo))
+			require.NoError(err)
+			require.NoError(e.writeError(ctx, testError))
+
+			b, err := ioutil.ReadFile(filepath.Join(dir, "errors.log"))
+			require.NoError(err)
+			var got struct {
+				Payload map[string]interface{} `json:"payload"`
+			}
+			require.NoError(json.Unmarshal(b, &got))
+			stack, ok := got.Payload["stack"]
+			if tt.wantDepth == 0 {
+				assert.False(ok, "stack should only be present when enabled")
+				return
+			}
+			require.True(ok, "stack should be present when enabled")
+			frames := stack.([]interface{})
+			require.NotEmpty(frames)
+			assert.LessOrEqual(len(frames), tt.wantDepth)
+			// the stack starts at the caller of writeError
+			assert.Contains(frames[0], "TestEventer_ErrorStackTraces")
+			for _, f := range frames {
+				assert.NotContains(f, "runtime.", "runtime frames should be stripped")
+			}
+		})
+	}
+	t.Run("negative-depth", func(t *testing.T) {
+		c := EventerConfig{Sinks: []SinkConfig{DefaultSink()}}
+		_, err := NewEventer(testLogger, testLock, c, WithErrorStackTraces(-1))
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	})
+}
+
+func TestEventer_Sinks(t *testing.T) {
+	t.Parallel()
+	assert, require := assert.New(t), require.New(t)
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	dir := t.TempDir()
+	c := EventerConfig{
+		AuditEnabled: true,
+		Sinks: []SinkConfig{
+			{
+				Name:       "stderr",
+				SinkType:   StderrSink,
+				EventTypes: []Type{EveryType},
+				Format:     TextSinkFormat,
+			},
+			{
+				Name:       "audit",
+				SinkType:   FileSink,
+				EventTypes: []Type{ErrorType, AuditType},
+				Format:     JSONSinkFormat,
+				Path:       dir,
+				FileName:   "audit.log",
+				Mirrors: []SinkConfig{
+					{Name: "audit-mirror", SinkType: DiscardSink},
+				},
+			},
+		},
+	}
+	e, err := NewEventer(testLogger, testLock, c)
+	require.NoError(err)
+	t.Cleanup(func() { e.Close(context.Background()) })
+
+	want := []SinkInfo{
+		{Name: "audit", Type: FileSink, Format: JSONSinkFormat, EventTypes: []Type{AuditType, ErrorType}},
+		{Name: "stderr", Type: StderrSink, Format: TextSinkFormat, EventTypes: []Type{AuditType, ObservationType, ErrorType, SystemType}},
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.Equal(want, e.Sinks())
+		}()
+		go func() {
+			defer wg.Done()
+			assert.NoError(e.Reopen())
+		}()
+	}
+	wg.Wait()
+
+	// the snapshot is a copy
+	got := e.Sinks()
+	got[0].EventTypes[0] = ObservationType
+	assert.Equal(want, e.Sinks())
+}
+
+func TestNewEventer_rollback(t *testing.T) {
+	t.Parallel()
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	// openFiles returns the files of the process which are in the dir, when
+	// the platform allows them to be listed.
+	openFiles := func(t *testing.T, dir string) []string {
+		t.Helper()
+		fds, err := ioutil.ReadDir("/proc/self/fd")
+		if err != nil {
+			t.Skipf("open files can't be listed: %s", err)
+		}
+		var files []string
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join("/proc/self/fd", fd.Name()))
+			if err == nil && strings.HasPrefix(target, dir) {
+				files = append(files, target)
+			}
+		}
+		return files
+	}
+	// the file sink has a pipeline for each of the four event types, and the
+	// kafka sink one for error events
+	const totalPipelines = 5
+	for n := 1; n <= totalPipelines; n++ {
+		n := n
+		t.Run(fmt.Sprintf("pipeline-%d", n), func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			dir := t.TempDir()
+			c := EventerConfig{
+				Sinks: []SinkConfig{
+					{
+						Name:       "file",
+						SinkType:   FileSink,
+						EventTypes: []Type{EveryType},
+						Format:     JSONSinkFormat,
+						Path:       dir,
+						FileName:   "events.log",
+					},
+					{
+						Name:        "kafka",
+						SinkType:    KafkaSink,
+						EventTypes:  []Type{ErrorType},
+						Format:      JSONSinkFormat,
+						KafkaConfig: &KafkaSinkConfig{Brokers: []string{"localhost:9092"}, Topic: "events"},
+					},
+				},
+			}
+			b := &testMockBroker{errorOnRegisterPipeline: n}
+			p := &testKafkaProducer{}
+			e, err := NewEventer(testLogger, testLock, c, TestWithBroker(t, b), WithKafkaProducer(func(*KafkaSinkConfig) (KafkaProducer, error) { return p, nil }))
+			require.Error(err)
+			assert.Nil(e)
+
+			assert.Empty(b.pipelines)
+			assert.Len(b.removedPipelines, n-1)
+			assert.True(p.closed)
+			assert.Empty(openFiles(t, dir))
+		})
+	}
+	t.Run("success", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		b := &testMockBroker{}
+		c := EventerConfig{
+			Sinks: []SinkConfig{
+				{
+					Name:        "kafka",
+					SinkType:    KafkaSink,
+					EventTypes:  []Type{EveryType},
+					Format:      JSONSinkFormat,
+					KafkaConfig: &KafkaSinkConfig{Brokers: []string{"localhost:9092"}, Topic: "events"},
+				},
+			},
+		}
+		p := &testKafkaProducer{}
+		_, err := NewEventer(testLogger, testLock, c, TestWithBroker(t, b), WithKafkaProducer(func(*KafkaSinkConfig) (KafkaProducer, error) { return p, nil }))
+		require.NoError(err)
+		assert.Len(b.pipelines, 4)
+		assert.Empty(b.removedPipelines)
+		assert.False(p.closed)
+	})
+}
+
+func TestEventer_TimestampFormat(t *testing.T) {
+	t.Parallel()
+	assert, require := assert.New(t), require.New(t)
+	dir := t.TempDir()
+	sink := func(name string, format SinkFormat, tf TimestampFormat) SinkConfig {
+		return SinkConfig{
+			Name:            name,
+			SinkType:        FileSink,
+			EventTypes:      []Type{ErrorType},
+			Format:          format,
+			Path:            dir,
+			FileName:        name + ".log",
+			TimestampFormat: tf,
+		}
+	}
+	c := EventerConfig{
+		Sinks: []SinkConfig{
+			sink("default", JSONSinkFormat, DefaultTimestampFormat),
+			sink("rfc3339nano", JSONSinkFormat, RFC3339NanoTimestampFormat),
+			sink("epoch-millis", JSONSinkFormat, EpochMillisTimestampFormat),
+			sink("text-epoch-millis", TextSinkFormat, EpochMillisTimestampFormat),
+		},
+	}
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	now := time.Date(2021, 6, 29, 20, 53, 20, int(123456789*time.Nanosecond), time.UTC)
+	e, err := NewEventer(testLogger, testLock, c, WithNow(now))
+	require.NoError(err)
+
+	testError, err := newError("TestEventer_TimestampFormat", fmt.Errorf("%s: no msg: test", ErrIo))
+	require.NoError(err)
+	require.NoError(e.writeError(context.Background(), testError))
+
+	createdAt := func(name string) interface{} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name+".log"))
+		require.NoError(err)
+		var m map[string]interface{}
+		require.NoError(json.Unmarshal(b, &m))
+		return m["created_at"]
+	}
+	assert.Equal("2021-06-29T20:53:20.123456789Z", createdAt("default"))
+	assert.Equal("2021-06-29T20:53:20.123456789Z", createdAt("rfc3339nano"))
+	assert.Equal(float64(1625000000123), createdAt("epoch-millis"))
+
+	text, err := ioutil.ReadFile(filepath.Join(dir, "text-epoch-millis.log"))
+	require.NoError(err)
+	assert.True(strings.HasPrefix(string(text), "1625000000123 [error] "), string(text))
+}
+
+func TestEventer_ShouldEmit(t *testing.T) {
+	t.Parallel()
+	subscribed := testRegisterEventType(t, "test-should-emit-subscribed")
+	unsubscribed := testRegisterEventType(t, "test-should-emit-unsubscribed")
+	newEventer := func(t *testing.T, c EventerConfig) *Eventer {
+		t.Helper()
+		e, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, c, TestWithBroker(t, &testMockBroker{}))
+		require.NoError(t, err)
+		return e
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		assert := assert.New(t)
+		e, _ := NewTestEventer(t)
+		for _, typ := range []Type{AuditType, ObservationType, ErrorType, SystemType} {
+			assert.True(e.ShouldEmit(typ), typ)
+		}
+		assert.False(e.ShouldEmit(EveryType))
+		assert.False(e.ShouldEmit("unknown"))
+	})
+	t.Run("disabled", func(t *testing.T) {
+		assert := assert.New(t)
+		e := newEventer(t, EventerConfig{
+			Sinks: []SinkConfig{
+				{Name: "every", SinkType: StderrSink, EventTypes: []Type{EveryType}, Format: JSONSinkFormat},
+			},
+		})
+		assert.False(e.ShouldEmit(AuditType))
+		assert.False(e.ShouldEmit(ObservationType))
+		assert.False(e.ShouldEmit(SystemType))
+		assert.True(e.ShouldEmit(ErrorType))
+	})
+	t.Run("no-sink", func(t *testing.T) {
+		assert := assert.New(t)
+		e := newEventer(t, EventerConfig{
+			Sinks: []SinkConfig{
+				{Name: "errors", SinkType: StderrSink, EventTypes: []Type{ErrorType, subscribed}, Format: JSONSinkFormat},
+			},
+		})
+		// the types are enabled after the eventer is created, so it has
+		// no sinks for them
+		e.conf.AuditEnabled, e.conf.ObservationsEnabled, e.conf.SysEventsEnabled = true, true, true
+		assert.False(e.ShouldEmit(AuditType))
+		assert.False(e.ShouldEmit(ObservationType))
+		assert.False(e.ShouldEmit(SystemType))
+		assert.True(e.ShouldEmit(subscribed))
+		assert.False(e.ShouldEmit(unsubscribed))
+	})
+	t.Run("closed", func(t *testing.T) {
+		e, _ := NewTestEventer(t)
+		require.NoError(t, e.Close(context.Background()))
+		assert.False(t, e.ShouldEmit(ErrorType))
+	})
+	t.Run("nil", func(t *testing.T) {
+		var e *Eventer
+		assert.False(t, e.ShouldEmit(ErrorType))
+	})
+}
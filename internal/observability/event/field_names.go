@@ -0,0 +1,67 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// fieldNamesFormatSuffix is appended to the format key of the output of a
+// sink with a FieldNameMap.  Each of those sinks has its own formatter, so
+// its key is suffixed with the sink's id too.
+const fieldNamesFormatSuffix = "-field-names-"
+
+// validateFieldNameMap ensures the map renames fields to distinct, non-empty
+// names.  A field may be renamed to the name of another field only when that
+// field is renamed too, otherwise the two would collide in the output.
+func validateFieldNameMap(names map[string]string) error {
+	const op = "event.validateFieldNameMap"
+	renamedTo := make(map[string]string, len(names))
+	// the fields are sorted, so the error for a collision is deterministic
+	fields := make([]string, 0, len(names))
+	for from := range names {
+		fields = append(fields, from)
+	}
+	sort.Strings(fields)
+	for _, from := range fields {
+		to := names[from]
+		switch {
+		case from == "":
+			return fmt.Errorf("%s: missing field name: %w", op, ErrInvalidParameter)
+		case to == "":
+			return fmt.Errorf("%s: missing new name of field %q: %w", op, from, ErrInvalidParameter)
+		}
+		if other, ok := renamedTo[to]; ok {
+			return fmt.Errorf("%s: fields %q and %q are both renamed to %q: %w", op, other, from, to, ErrInvalidParameter)
+		}
+		renamedTo[to] = from
+	}
+	return nil
+}
+
+// renameFields returns the payload as a map with the same fields as its JSON,
+// with its fields renamed by names.  Only the payload's own fields are
+// renamed, not the fields nested within them.  An error is returned when a
+// field is renamed to the name of a field of the payload which isn't renamed.
+func renameFields(payload interface{}, names map[string]string) (map[string]interface{}, error) {
+	const op = "event.renameFields"
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	renamed := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		if to, ok := names[k]; ok {
+			k = to
+		}
+		renamed[k] = v
+	}
+	if len(renamed) != len(fields) {
+		return nil, fmt.Errorf("%s: a renamed field collides with a field of the event: %w", op, ErrInvalidParameter)
+	}
+	return renamed, nil
+}
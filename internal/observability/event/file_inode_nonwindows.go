@@ -0,0 +1,16 @@
+// +build !windows
+
+package event
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileInode returns the inode of the file, or zero when it's unknown.
+func fileInode(fi os.FileInfo) uint64 {
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok {
+		return uint64(st.Ino)
+	}
+	return 0
+}
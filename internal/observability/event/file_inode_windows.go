@@ -0,0 +1,10 @@
+// +build windows
+
+package event
+
+import "os"
+
+// fileInode returns zero, since files don't have an inode on windows.
+func fileInode(_ os.FileInfo) uint64 {
+	return 0
+}
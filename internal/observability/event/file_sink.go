@@ -0,0 +1,461 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+)
+
+const (
+	defaultFileSinkMode = 0o600
+	fileSinkDirMode     = 0o700
+
+	// defaultBatchFlushInterval is the max time a batched event waits to be
+	// written when the sink's BatchFlushInterval isn't set.
+	defaultBatchFlushInterval = time.Second
+)
+
+// fileSink writes the []byte representation of an event to a file.  It
+// behaves like the eventlogger.FileSink (including its rotation), but it
+// can be closed, so an Eventer is able to release its file handles.
+//
+// When batchSize is greater than one, events are buffered and written to the
+// file together once batchSize events are buffered or batchFlushInterval has
+// passed since the first of them was buffered, whichever comes first.  Events
+// with an enforced delivery (audit and error events) are never buffered: the
+// buffered events are written before them, so the file's order is kept.
+type fileSink struct {
+	path        string        // path is the log file's directory, excluding fileName
+	fileName    string        // fileName is the name of the log file
+	mode        os.FileMode   // mode is the file's mode and permission bits
+	dirMode     os.FileMode   // dirMode is the permission bits of the file's directory, when it's created
+	maxBytes    int           // maxBytes is the max bytes written to a file before it's rotated
+	maxDuration time.Duration // maxDuration is the max duration between file rotations
+	maxFiles    int           // maxFiles is the max number of rotated files kept
+	format      string        // format is the event format written, which defaults to JSON
+	scheduled   bool          // scheduled is true when the file is also rotated by a rotationScheduler
+	headerLine  string        // headerLine is written as the first line of each new file
+
+	batchSize          int           // batchSize is the number of events buffered before they're written
+	batchFlushInterval time.Duration // batchFlushInterval is the max time an event is buffered
+
+	// pathConfirmations queues the path of the sink's file after its first
+	// write, when the eventer was created WithPathConfirmation.
+	sinkName          string
+	pathConfirmations *pathConfirmations
+	pathConfirmed     bool
+
+	l            sync.Mutex
+	f            *os.File
+	lastCreated  time.Time
+	bytesWritten int64
+
+	batch      []byte
+	batched    int
+	batchTimer *time.Timer
+	// batchErr is the error of the last write of the buffered events by the
+	// batch timer, which is returned by the next call to the sink.
+	batchErr error
+}
+
+var (
+	_ eventlogger.Node = &fileSink{}
+	_ io.Closer        = &fileSink{}
+	_ flushable        = &fileSink{}
+)
+
+// newConfiguredFileSink returns a new file sink for the sink config, whose
+// events are formatted as format.  Its directory is created when it doesn't
+// exist.
+func newConfiguredFileSink(sc SinkConfig, format string) (*fileSink, error) {
+	if err := sc.preflight(); err != nil {
+		return nil, err
+	}
+	return &fileSink{
+		format:             format,
+		path:               sc.Path,
+		fileName:           sc.FileName,
+		maxBytes:           sc.RotateBytes,
+		maxDuration:        sc.RotateDuration,
+		maxFiles:           sc.RotateMaxFiles,
+		mode:               sc.FileMode,
+		dirMode:            sc.DirMode,
+		batchSize:          sc.BatchSize,
+		batchFlushInterval: sc.BatchFlushInterval,
+		headerLine:         sc.HeaderLine,
+	}, nil
+}
+
+// Type describes the type of the node as a Sink.
+func (fs *fileSink) Type() eventlogger.NodeType {
+	return eventlogger.NodeTypeSink
+}
+
+// Process writes the []byte representation of an event to the file, opening
+// it if required.  When the sink batches events, the event may be buffered
+// rather than written.
+func (fs *fileSink) Process(_ context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	const op = "event.(fileSink).Process"
+	if e == nil {
+		return nil, fmt.Errorf("%s: missing event: %w", op, ErrInvalidParameter)
+	}
+	format := fs.format
+	if format == "" {
+		format = eventlogger.JSONFormat
+	}
+	val, ok := e.Format(format)
+	if !ok {
+		return nil, errors.New("event was not marshaled")
+	}
+
+	fs.l.Lock()
+	defer fs.l.Unlock()
+
+	if fs.batchSize > 1 && !enforcedDelivery(Type(e.Type)) {
+		fs.batch = append(fs.batch, val...)
+		fs.batched++
+		if fs.batched < fs.batchSize {
+			if fs.batchTimer == nil {
+				fs.startBatchTimer()
+			}
+			return nil, fs.takeBatchErr()
+		}
+		return nil, fs.flush()
+	}
+	if err := fs.flush(); err != nil {
+		return nil, err
+	}
+	// sinks are leafs, so do not return the event, since nothing more can
+	// happen to it downstream.
+	return nil, fs.write(val)
+}
+
+// write writes the bytes to the file, opening and rotating it if required.
+// The caller must hold the sink's lock.
+func (fs *fileSink) write(val []byte) error {
+	reader := bytes.NewReader(val)
+
+	if fs.f == nil {
+		if err := fs.open(); err != nil {
+			return err
+		}
+	}
+
+	// rotate if necessary
+	if err := fs.rotate(); err != nil {
+		return err
+	}
+
+	if n, err := reader.WriteTo(fs.f); err == nil {
+		fs.bytesWritten += n
+		fs.confirmPath()
+		return nil
+	}
+
+	// opportunistically try to reopen the file, once per call.
+	_ = fs.f.Close()
+	fs.f = nil
+	if err := fs.open(); err != nil {
+		return err
+	}
+	_, _ = reader.Seek(0, io.SeekStart)
+	n, err := reader.WriteTo(fs.f)
+	fs.bytesWritten += n
+	if err != nil {
+		return err
+	}
+	fs.confirmPath()
+	return nil
+}
+
+// startBatchTimer starts the timer which writes the buffered events once the
+// batch flush interval has passed.  The caller must hold the sink's lock.
+func (fs *fileSink) startBatchTimer() {
+	interval := fs.batchFlushInterval
+	if interval <= 0 {
+		interval = defaultBatchFlushInterval
+	}
+	var t *time.Timer
+	t = time.AfterFunc(interval, func() {
+		fs.l.Lock()
+		defer fs.l.Unlock()
+		// the batch was already written if the sink's timer was replaced
+		if fs.batchTimer != t {
+			return
+		}
+		if err := fs.flush(); err != nil {
+			fs.batchErr = err
+		}
+	})
+	fs.batchTimer = t
+}
+
+// flush writes the buffered events to the file.  The buffered events are
+// discarded even when the write fails, so a failing file can't grow the
+// buffer without bound.  The caller must hold the sink's lock.
+func (fs *fileSink) flush() error {
+	if fs.batchTimer != nil {
+		fs.batchTimer.Stop()
+		fs.batchTimer = nil
+	}
+	err := fs.takeBatchErr()
+	if fs.batched > 0 {
+		if writeErr := fs.write(fs.batch); writeErr != nil {
+			err = writeErr
+		}
+		fs.batch, fs.batched = fs.batch[:0], 0
+	}
+	return err
+}
+
+// takeBatchErr returns and clears the error of the batch timer's last write.
+// The caller must hold the sink's lock.
+func (fs *fileSink) takeBatchErr() error {
+	err := fs.batchErr
+	fs.batchErr = nil
+	return err
+}
+
+// FlushAll writes the sink's buffered events to its file.
+func (fs *fileSink) FlushAll(_ context.Context) error {
+	fs.l.Lock()
+	defer fs.l.Unlock()
+	return fs.flush()
+}
+
+// Reopen will close, rotate and reopen the sink's file.
+func (fs *fileSink) Reopen() error {
+	switch fs.path {
+	case "discard":
+		return nil
+	}
+
+	fs.l.Lock()
+	defer fs.l.Unlock()
+
+	if err := fs.flush(); err != nil {
+		return err
+	}
+
+	if fs.f != nil {
+		// ensure file still exists
+		if _, err := os.Stat(fs.f.Name()); os.IsNotExist(err) {
+			_ = fs.f.Close()
+			fs.f = nil
+		}
+	}
+	if fs.f == nil {
+		return fs.open()
+	}
+
+	err := fs.f.Close()
+	// set to nil here so that even if we error out, on the next access open()
+	// will be tried
+	fs.f = nil
+	if err != nil {
+		return err
+	}
+	return fs.open()
+}
+
+// Close writes the sink's buffered events and closes its file.  The file is
+// reopened if another event is processed.
+func (fs *fileSink) Close() error {
+	fs.l.Lock()
+	defer fs.l.Unlock()
+	flushErr := fs.flush()
+	if fs.f == nil {
+		return flushErr
+	}
+	err := fs.f.Close()
+	fs.f = nil
+	if flushErr != nil {
+		return flushErr
+	}
+	return err
+}
+
+// roll writes the sink's buffered events, closes its file and opens a new
+// one, pruning the rotated files.  It's called by the sink's rotation
+// scheduler.
+func (fs *fileSink) roll() error {
+	fs.l.Lock()
+	defer fs.l.Unlock()
+	if err := fs.flush(); err != nil {
+		return err
+	}
+	if fs.f != nil {
+		_ = fs.f.Close()
+		fs.f = nil
+	}
+	if err := fs.pruneFiles(); err != nil {
+		return err
+	}
+	return fs.open()
+}
+
+func (fs *fileSink) open() error {
+	mode := fs.mode
+	if mode == 0 {
+		mode = defaultFileSinkMode
+	}
+	if err := mkdirAll(fs.path, fs.dirMode); err != nil {
+		return err
+	}
+
+	createTime := time.Now()
+	// new file names have the format:
+	//	file rotation enabled: filename-timestamp.extension
+	//	file rotation disabled: filename.extension
+	newFilePath := filepath.Join(fs.path, fs.newFileName(createTime))
+
+	var err error
+	fs.f, err = os.OpenFile(newFilePath, os.O_APPEND|os.O_WRONLY|os.O_CREATE, mode)
+	if err != nil {
+		return err
+	}
+
+	// change the file mode in case the log file already existed. We special
+	// case a few paths since we can't chmod them, and bypass if the mode is
+	// zero
+	switch newFilePath {
+	case "/dev/null", "/dev/stderr", "/dev/stdout":
+	default:
+		if fs.mode != 0 {
+			if err := os.Chmod(newFilePath, fs.mode); err != nil {
+				return err
+			}
+		}
+	}
+
+	fs.lastCreated = createTime
+	fs.bytesWritten = 0
+
+	switch newFilePath {
+	case "/dev/null", "/dev/stderr", "/dev/stdout":
+	default:
+		if err := fs.writeHeaderLine(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeHeaderLine writes the sink's header line when its file is empty, so
+// it's only written to new files and never in the middle of a file which is
+// appended to.  It isn't counted toward the file's max bytes, so a file is
+// never rotated with only its header line.
+func (fs *fileSink) writeHeaderLine() error {
+	if fs.headerLine == "" {
+		return nil
+	}
+	fi, err := fs.f.Stat()
+	if err != nil {
+		return err
+	}
+	if fi.Size() > 0 {
+		return nil
+	}
+	_, err = fs.f.WriteString(fs.headerLine + "\n")
+	return err
+}
+
+// mkdirAll creates the directory, along with any missing parents, when it
+// doesn't exist.  The created directories have the mode, which defaults to
+// fileSinkDirMode, regardless of the umask.  The mode of an existing
+// directory isn't changed.
+func mkdirAll(dir string, mode os.FileMode) error {
+	if _, err := os.Stat(dir); err == nil {
+		return nil
+	}
+	if mode == 0 {
+		mode = fileSinkDirMode
+	}
+	// find the directories which will be created, so their mode can be set
+	var created []string
+	for d := filepath.Clean(dir); ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		}
+		created = append(created, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	if err := os.MkdirAll(dir, mode); err != nil {
+		return err
+	}
+	for _, d := range created {
+		if err := os.Chmod(d, mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (fs *fileSink) rotate() error {
+	elapsed := time.Since(fs.lastCreated)
+	if (fs.bytesWritten >= int64(fs.maxBytes) && (fs.maxBytes > 0)) ||
+		((elapsed > fs.maxDuration) && (fs.maxDuration > 0)) {
+		_ = fs.f.Close()
+		if err := fs.pruneFiles(); err != nil {
+			return err
+		}
+		return fs.open()
+	}
+	return nil
+}
+
+func (fs *fileSink) pruneFiles() error {
+	if fs.maxFiles == 0 {
+		return nil
+	}
+
+	// get all the files that match the log file pattern
+	pattern := fs.fileNamePattern()
+	matches, err := filepath.Glob(filepath.Join(fs.path, fmt.Sprintf(pattern, "*")))
+	if err != nil {
+		return err
+	}
+
+	// filepath.Glob does not publicly guarantee that files are sorted
+	sort.Strings(matches)
+
+	stale := len(matches) - fs.maxFiles
+	for i := 0; i < stale; i++ {
+		if err := os.Remove(matches[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (fs *fileSink) fileNamePattern() string {
+	ext := filepath.Ext(fs.fileName)
+	if ext == "" {
+		ext = ".log"
+	}
+	return strings.TrimSuffix(fs.fileName, ext) + "-%s" + ext
+}
+
+func (fs *fileSink) newFileName(createTime time.Time) string {
+	if fs.rotateEnabled() {
+		return fmt.Sprintf(fs.fileNamePattern(), strconv.FormatInt(createTime.UnixNano(), 10))
+	}
+	return fs.fileName
+}
+
+func (fs *fileSink) rotateEnabled() bool {
+	return fs.maxBytes > 0 || fs.maxDuration != 0 || fs.scheduled
+}
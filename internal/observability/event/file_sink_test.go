@@ -0,0 +1,234 @@
+package event
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_fileSink(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	newEvent := func(data string) *eventlogger.Event {
+		e := &eventlogger.Event{Type: "test"}
+		e.FormattedAs(eventlogger.JSONFormat, []byte(data))
+		return e
+	}
+
+	t.Run("missing-event", func(t *testing.T) {
+		fs := &fileSink{path: t.TempDir(), fileName: "events.log"}
+		_, err := fs.Process(ctx, nil)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	})
+	t.Run("process-close-reopen", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		dir := t.TempDir()
+		fs := &fileSink{path: dir, fileName: "events.log"}
+
+		got, err := fs.Process(ctx, newEvent("first\n"))
+		require.NoError(err)
+		assert.Nil(got)
+		require.NotNil(fs.f)
+
+		require.NoError(fs.Close())
+		assert.Nil(fs.f)
+		require.NoError(fs.Close())
+
+		// the file is reopened by the next event
+		_, err = fs.Process(ctx, newEvent("second\n"))
+		require.NoError(err)
+		require.NoError(fs.Reopen())
+		require.NoError(fs.Close())
+
+		b, err := ioutil.ReadFile(filepath.Join(dir, "events.log"))
+		require.NoError(err)
+		assert.Equal("first\nsecond\n", string(b))
+
+		fi, err := os.Stat(filepath.Join(dir, "events.log"))
+		require.NoError(err)
+		assert.Equal(os.FileMode(defaultFileSinkMode), fi.Mode().Perm())
+	})
+	t.Run("rotate", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		dir := t.TempDir()
+		fs := &fileSink{path: dir, fileName: "events.log", maxBytes: 1, maxFiles: 2}
+		t.Cleanup(func() { fs.Close() })
+		for i := 0; i < 5; i++ {
+			_, err := fs.Process(ctx, newEvent("event\n"))
+			require.NoError(err)
+		}
+		files, err := filepath.Glob(filepath.Join(dir, "events-*.log"))
+		require.NoError(err)
+		// the current file plus the max number of rotated files
+		assert.Len(files, 3)
+	})
+	t.Run("batch-size", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		dir := t.TempDir()
+		fs := &fileSink{path: dir, fileName: "events.log", batchSize: 3, batchFlushInterval: time.Hour}
+		t.Cleanup(func() { fs.Close() })
+		read := func() string {
+			b, err := ioutil.ReadFile(filepath.Join(dir, "events.log"))
+			if os.IsNotExist(err) {
+				return ""
+			}
+			require.NoError(err)
+			return string(b)
+		}
+
+		for _, data := range []string{"1\n", "2\n"} {
+			_, err := fs.Process(ctx, newEvent(data))
+			require.NoError(err)
+		}
+		assert.Empty(read())
+
+		_, err := fs.Process(ctx, newEvent("3\n"))
+		require.NoError(err)
+		assert.Equal("1\n2\n3\n", read())
+
+		_, err = fs.Process(ctx, newEvent("4\n"))
+		require.NoError(err)
+		assert.Equal("1\n2\n3\n", read())
+		require.NoError(fs.FlushAll(ctx))
+		assert.Equal("1\n2\n3\n4\n", read())
+
+		_, err = fs.Process(ctx, newEvent("5\n"))
+		require.NoError(err)
+		require.NoError(fs.Close())
+		assert.Equal("1\n2\n3\n4\n5\n", read())
+	})
+	t.Run("batch-flush-interval", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		dir := t.TempDir()
+		fs := &fileSink{path: dir, fileName: "events.log", batchSize: 100, batchFlushInterval: 10 * time.Millisecond}
+		t.Cleanup(func() { fs.Close() })
+
+		_, err := fs.Process(ctx, newEvent("1\n"))
+		require.NoError(err)
+		assert.Eventually(func() bool {
+			b, err := ioutil.ReadFile(filepath.Join(dir, "events.log"))
+			return err == nil && string(b) == "1\n"
+		}, time.Second, 5*time.Millisecond)
+	})
+	t.Run("header-line", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		dir := t.TempDir()
+		fs := &fileSink{path: dir, fileName: "events.log", headerLine: "#schema: v1"}
+		t.Cleanup(func() { fs.Close() })
+		read := func() string {
+			b, err := ioutil.ReadFile(filepath.Join(dir, "events.log"))
+			require.NoError(err)
+			return string(b)
+		}
+
+		// written when the file is created
+		_, err := fs.Process(ctx, newEvent("1\n"))
+		require.NoError(err)
+		assert.Equal("#schema: v1\n1\n", read())
+
+		// not written when an existing file is reopened and appended to
+		require.NoError(fs.Reopen())
+		require.NoError(fs.Close())
+		_, err = fs.Process(ctx, newEvent("2\n"))
+		require.NoError(err)
+		assert.Equal("#schema: v1\n1\n2\n", read())
+
+		// written again when the file was moved before it's reopened
+		require.NoError(os.Rename(filepath.Join(dir, "events.log"), filepath.Join(dir, "events.log.1")))
+		require.NoError(fs.Reopen())
+		_, err = fs.Process(ctx, newEvent("3\n"))
+		require.NoError(err)
+		assert.Equal("#schema: v1\n3\n", read())
+	})
+	t.Run("header-line-rotate", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		dir := t.TempDir()
+		fs := &fileSink{path: dir, fileName: "events.log", maxBytes: 1, headerLine: "#schema: v1"}
+		t.Cleanup(func() { fs.Close() })
+		for i := 0; i < 3; i++ {
+			_, err := fs.Process(ctx, newEvent("event\n"))
+			require.NoError(err)
+		}
+		files, err := filepath.Glob(filepath.Join(dir, "events-*.log"))
+		require.NoError(err)
+		require.Len(files, 3)
+		// each rotated file starts with the header line
+		for _, f := range files {
+			b, err := ioutil.ReadFile(f)
+			require.NoError(err)
+			assert.Equal("#schema: v1\nevent\n", string(b), f)
+		}
+	})
+	t.Run("enforced-delivery-is-not-batched", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		dir := t.TempDir()
+		fs := &fileSink{path: dir, fileName: "events.log", batchSize: 100, batchFlushInterval: time.Hour}
+		t.Cleanup(func() { fs.Close() })
+
+		_, err := fs.Process(ctx, newEvent("observation\n"))
+		require.NoError(err)
+		audit := newEvent("audit\n")
+		audit.Type = eventlogger.EventType(AuditType)
+		_, err = fs.Process(ctx, audit)
+		require.NoError(err)
+
+		// the buffered event is written first, so the order is kept
+		b, err := ioutil.ReadFile(filepath.Join(dir, "events.log"))
+		require.NoError(err)
+		assert.Equal("observation\naudit\n", string(b))
+	})
+}
+
+// writeSyscalls returns the number of write syscalls made by the process,
+// which is only available on linux.
+func writeSyscalls() (uint64, bool) {
+	b, err := ioutil.ReadFile("/proc/self/io")
+	if err != nil {
+		return 0, false
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if strings.HasPrefix(line, "syscw:") {
+			n, err := strconv.ParseUint(strings.TrimSpace(strings.TrimPrefix(line, "syscw:")), 10, 64)
+			return n, err == nil
+		}
+	}
+	return 0, false
+}
+
+func benchmarkFileSink(b *testing.B, batchSize int) {
+	ctx := context.Background()
+	fs := &fileSink{path: b.TempDir(), fileName: "events.log", batchSize: batchSize, batchFlushInterval: time.Hour}
+	e := &eventlogger.Event{Type: eventlogger.EventType(ObservationType)}
+	e.FormattedAs(eventlogger.JSONFormat, []byte(`{"id":"benchmark","type":"observation","data":{"op":"benchmark"}}`+"\n"))
+	before, ok := writeSyscalls()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := fs.Process(ctx, e); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := fs.Close(); err != nil {
+		b.Fatal(err)
+	}
+	b.StopTimer()
+	if after, afterOk := writeSyscalls(); ok && afterOk {
+		b.ReportMetric(float64(after-before)/float64(b.N), "syscw/op")
+	}
+}
+
+// BenchmarkFileSink_Process compares writing each event to the file with
+// writing batches of events.
+func BenchmarkFileSink_Process(b *testing.B) {
+	b.Run("unbatched", func(b *testing.B) { benchmarkFileSink(b, 0) })
+	b.Run("batch-100", func(b *testing.B) { benchmarkFileSink(b, 100) })
+}
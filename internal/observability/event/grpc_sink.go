@@ -0,0 +1,373 @@
+package event
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/boundary/internal/observability/event/store"
+	"github.com/hashicorp/eventlogger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// grpcMaxBatchEvents is the max number of events in a batch sent to a
+	// collector.
+	grpcMaxBatchEvents = 1000
+
+	// grpcMaxBatchBytes is the max size of the formatted events of a batch,
+	// which keeps its request under grpc's default max message size of 4MiB.
+	grpcMaxBatchBytes = 3 * 1024 * 1024
+)
+
+// GRPCSinkConfig defines the configuration for a GRPCSink
+type GRPCSinkConfig struct {
+	Address           string            `hcl:"address"`            // Address defines the collector's address (host:port)
+	DeliveryGuarantee DeliveryGuarantee `hcl:"delivery_guarantee"` // DeliveryGuarantee of Enforced will wait for the collector to ack that it persisted each batch
+	TLSEnabled        bool              `hcl:"tls_enabled"`        // TLSEnabled specifies that TLS should be used to connect to the collector
+	TLSCACert         string            `hcl:"tls_ca_cert"`        // TLSCACert defines an optional PEM encoded CA cert
+	TLSSkipVerify     bool              `hcl:"tls_skip_verify"`    // TLSSkipVerify disables verification of the collector's cert
+}
+
+func (gc *GRPCSinkConfig) validate() error {
+	const op = "event.(GRPCSinkConfig).validate"
+	if gc.Address == "" {
+		return fmt.Errorf("%s: missing address: %w", op, ErrInvalidParameter)
+	}
+	if _, _, err := net.SplitHostPort(gc.Address); err != nil {
+		return fmt.Errorf("%s: address %q isn't a host:port: %w", op, gc.Address, ErrInvalidParameter)
+	}
+	if err := gc.DeliveryGuarantee.validate(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !gc.TLSEnabled && (gc.TLSCACert != "" || gc.TLSSkipVerify) {
+		return fmt.Errorf("%s: tls options provided without tls enabled: %w", op, ErrInvalidParameter)
+	}
+	return nil
+}
+
+// transportCredentials returns the dial option for the config's tls options.
+func (gc *GRPCSinkConfig) transportCredentials() (grpc.DialOption, error) {
+	const op = "event.(GRPCSinkConfig).transportCredentials"
+	if !gc.TLSEnabled {
+		return grpc.WithInsecure(), nil
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: gc.TLSSkipVerify,
+	}
+	if gc.TLSCACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(gc.TLSCACert)) {
+			return nil, fmt.Errorf("%s: unable to parse tls ca cert: %w", op, ErrInvalidParameter)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)), nil
+}
+
+// grpcBatch is a batch of events which are sent together.  Its done channel
+// is closed once it's been sent, and err is its result.
+type grpcBatch struct {
+	events []*store.FormattedEvent
+	bytes  int
+	done   chan struct{}
+	err    error
+}
+
+// fits returns true if the event can be added to the batch without exceeding
+// the batch limits.
+func (b *grpcBatch) fits(fe *store.FormattedEvent) bool {
+	if len(b.events) == 0 {
+		return true
+	}
+	if len(b.events) >= grpcMaxBatchEvents {
+		return false
+	}
+	return b.bytes+len(fe.Data) <= grpcMaxBatchBytes
+}
+
+func (b *grpcBatch) add(fe *store.FormattedEvent) {
+	b.events = append(b.events, fe)
+	b.bytes += len(fe.Data)
+}
+
+// grpcSink is a sink node which streams formatted events to a collector
+// implementing the EventCollectorService.  Events are batched like a
+// cloudWatchSink's: events which are processed while a batch is being sent
+// are added to the next batch, and Process only returns once its event's
+// batch has been acked by the collector.
+type grpcSink struct {
+	config     *GRPCSinkConfig
+	format     string
+	formatName string
+	eventer    *Eventer
+
+	// l serializes sending batches, since each batch's ack is the next
+	// response of the stream, and guards the connection and the stream.
+	l            sync.Mutex
+	conn         *grpc.ClientConn
+	stream       store.EventCollectorService_SendEventsClient
+	cancelStream context.CancelFunc
+	batchId      uint64
+
+	batchLock sync.Mutex
+	pending   *grpcBatch
+}
+
+var _ eventlogger.Node = &grpcSink{}
+
+// newGRPCSink returns a sink for the config which dials its collector.  The
+// sink's format is the format key of its events and formatName is the
+// format sent to the collector with each event.
+func newGRPCSink(e *Eventer, c *GRPCSinkConfig, format, formatName string) (*grpcSink, error) {
+	const op = "event.newGRPCSink"
+	if e == nil {
+		return nil, fmt.Errorf("%s: missing eventer: %w", op, ErrInvalidParameter)
+	}
+	if c == nil {
+		return nil, fmt.Errorf("%s: missing grpc config: %w", op, ErrInvalidParameter)
+	}
+	if format == "" {
+		return nil, fmt.Errorf("%s: missing format: %w", op, ErrInvalidParameter)
+	}
+	s := &grpcSink{
+		config:     c,
+		format:     format,
+		formatName: formatName,
+		eventer:    e,
+	}
+	if err := s.dial(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return s, nil
+}
+
+// dial creates the connection to the collector.  Dialing doesn't block, so
+// the collector needn't be up when the sink is created.  It's called with l
+// held, or before the sink is used.
+func (s *grpcSink) dial() error {
+	const op = "event.(grpcSink).dial"
+	creds, err := s.config.transportCredentials()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	conn, err := grpc.Dial(s.config.Address, creds)
+	if err != nil {
+		return fmt.Errorf("%s: unable to dial collector %q: %w", op, s.config.Address, err)
+	}
+	s.conn = conn
+	return nil
+}
+
+// Process adds the event's formatted data to the pending batch and waits for
+// the batch to be acked.  Failed batches, and batches which weren't acked as
+// persisted when delivery is Enforced, are sent again with a backoff.  The
+// batch is sent with a ctx owned by the sink (see batchSendTimeout), so when
+// ctx is done Process stops waiting, but the batch's other events are still
+// sent.
+func (s *grpcSink) Process(ctx context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	const op = "event.(grpcSink).Process"
+	if e == nil {
+		return nil, fmt.Errorf("%s: missing event: %w", op, ErrInvalidParameter)
+	}
+	value, ok := e.Format(s.format)
+	if !ok {
+		return nil, fmt.Errorf("%s: unable to find %s format: %w", op, s.format, ErrInvalidParameter)
+	}
+	if len(value) > grpcMaxBatchBytes {
+		return nil, fmt.Errorf("%s: event of %d bytes exceeds the grpc limit of %d bytes: %w", op, len(value), grpcMaxBatchBytes, ErrInvalidParameter)
+	}
+	createdAt := e.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	fe := &store.FormattedEvent{
+		CreatedAt: timestamppb.New(createdAt),
+		EventType: string(e.Type),
+		Format:    s.formatName,
+		Data:      value,
+	}
+	b, leader := s.enqueue(fe)
+	if leader {
+		go s.send(b)
+	}
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%s: %w", op, ctx.Err())
+	}
+	if b.err != nil {
+		return nil, fmt.Errorf("%s: %w", op, b.err)
+	}
+	// return a nil event to indicate the pipeline is complete
+	return nil, nil
+}
+
+// enqueue adds the event to the pending batch, starting a new batch when
+// there's none or the event doesn't fit in it.  leader is true when a new
+// batch was started, and its caller is responsible for sending it.
+func (s *grpcSink) enqueue(fe *store.FormattedEvent) (b *grpcBatch, leader bool) {
+	s.batchLock.Lock()
+	defer s.batchLock.Unlock()
+	if s.pending == nil || !s.pending.fits(fe) {
+		s.pending = &grpcBatch{done: make(chan struct{})}
+		leader = true
+	}
+	s.pending.add(fe)
+	return s.pending, leader
+}
+
+// send waits for the previous batch to be sent, stops the batch from
+// accepting more events and then sends it.
+func (s *grpcSink) send(b *grpcBatch) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.batchLock.Lock()
+	if s.pending == b {
+		s.pending = nil
+	}
+	s.batchLock.Unlock()
+	defer close(b.done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), batchSendTimeout)
+	defer cancel()
+
+	enforced := s.config.DeliveryGuarantee == Enforced
+	b.err = s.eventer.sendAcked(ctx, enforced, func() (Ack, error) {
+		return s.sendBatch(ctx, b.events)
+	})
+}
+
+// sendBatch sends the events as the next batch of the stream, opening the
+// stream when there's none, and returns the collector's ack of the batch.
+// When the stream fails it's reset, so the next attempt opens a new stream.
+// It's called with l held.
+func (s *grpcSink) sendBatch(ctx context.Context, events []*store.FormattedEvent) (Ack, error) {
+	const op = "event.(grpcSink).sendBatch"
+	if s.stream == nil {
+		streamCtx, cancel := context.WithCancel(context.Background())
+		stream, err := store.NewEventCollectorServiceClient(s.conn).SendEvents(streamCtx)
+		if err != nil {
+			cancel()
+			return Ack{}, fmt.Errorf("%s: unable to open stream to collector %q: %w", op, s.config.Address, err)
+		}
+		s.stream, s.cancelStream = stream, cancel
+	}
+	s.batchId++
+	req := &store.SendEventsRequest{
+		BatchId: s.batchId,
+		Events:  events,
+	}
+	resp, err := s.exchange(ctx, req)
+	if err != nil {
+		s.resetStream()
+		return Ack{}, fmt.Errorf("%s: %w", op, err)
+	}
+	switch {
+	case resp.GetBatchId() != req.BatchId:
+		// the stream's acks are out of step with its batches, so it can't be
+		// trusted
+		s.resetStream()
+		return Ack{}, fmt.Errorf("%s: collector acked batch %d rather than batch %d", op, resp.GetBatchId(), req.BatchId)
+	case resp.GetError() != "":
+		return Ack{}, fmt.Errorf("%s: collector rejected batch: %s", op, resp.GetError())
+	}
+	return Ack{
+		Persisted: resp.GetPersisted(),
+		Token:     strconv.FormatUint(resp.GetBatchId(), 10),
+	}, nil
+}
+
+// exchange sends the request on the stream and waits for its response.  The
+// stream outlives the ctx of any one batch, so when ctx is done first the
+// stream is reset by the caller, which unblocks the exchange.  It's called
+// with l held.
+func (s *grpcSink) exchange(ctx context.Context, req *store.SendEventsRequest) (*store.SendEventsResponse, error) {
+	const op = "event.(grpcSink).exchange"
+	stream := s.stream
+	type result struct {
+		resp *store.SendEventsResponse
+		err  error
+	}
+	results := make(chan result, 1)
+	go func() {
+		if err := stream.Send(req); err != nil {
+			results <- result{err: fmt.Errorf("%s: unable to send batch: %w", op, err)}
+			return
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			results <- result{err: fmt.Errorf("%s: unable to receive ack: %w", op, err)}
+			return
+		}
+		results <- result{resp: resp}
+	}()
+	select {
+	case r := <-results:
+		return r.resp, r.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%s: %w", op, ctx.Err())
+	}
+}
+
+// resetStream cancels the current stream, if any.  It's called with l held.
+func (s *grpcSink) resetStream() {
+	if s.cancelStream != nil {
+		s.cancelStream()
+	}
+	s.stream, s.cancelStream = nil, nil
+}
+
+// Reopen closes the connection to the collector and dials it again.
+func (s *grpcSink) Reopen() error {
+	const op = "event.(grpcSink).Reopen"
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.resetStream()
+	if err := s.conn.Close(); err != nil {
+		return fmt.Errorf("%s: unable to close collector connection: %w", op, err)
+	}
+	if err := s.dial(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// Close closes the stream and the connection to the collector.
+func (s *grpcSink) Close() error {
+	const op = "event.(grpcSink).Close"
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.resetStream()
+	if err := s.conn.Close(); err != nil {
+		return fmt.Errorf("%s: unable to close collector connection: %w", op, err)
+	}
+	return nil
+}
+
+// checkHealth returns an error when the connection to the collector has
+// failed or was closed.
+func (s *grpcSink) checkHealth(_ context.Context) error {
+	const op = "event.(grpcSink).checkHealth"
+	s.l.Lock()
+	defer s.l.Unlock()
+	switch state := s.conn.GetState(); state {
+	case connectivity.TransientFailure, connectivity.Shutdown:
+		return fmt.Errorf("%s: collector connection is %s", op, state)
+	}
+	return nil
+}
+
+// Type describes the type of the node as a Sink.
+func (s *grpcSink) Type() eventlogger.NodeType {
+	return eventlogger.NodeTypeSink
+}
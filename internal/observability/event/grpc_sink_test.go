@@ -0,0 +1,333 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/boundary/internal/observability/event/store"
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// testCollector is an EventCollectorService which records the events of the
+// batches it persists.  respond returns its response to the stream's batch,
+// which is counted from one across every stream.  A nil response ends the
+// stream with an error.
+type testCollector struct {
+	store.UnimplementedEventCollectorServiceServer
+
+	l       sync.Mutex
+	respond func(batch int, req *store.SendEventsRequest) *store.SendEventsResponse
+	batches int
+	streams int
+	events  []*store.FormattedEvent
+}
+
+func (c *testCollector) SendEvents(stream store.EventCollectorService_SendEventsServer) error {
+	c.l.Lock()
+	c.streams++
+	c.l.Unlock()
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		c.l.Lock()
+		c.batches++
+		resp := &store.SendEventsResponse{BatchId: req.BatchId, Persisted: true}
+		if c.respond != nil {
+			resp = c.respond(c.batches, req)
+		}
+		if resp != nil && resp.Persisted && resp.Error == "" {
+			c.events = append(c.events, req.Events...)
+		}
+		c.l.Unlock()
+		if resp == nil {
+			return errors.New("collector failed")
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *testCollector) state() (batches, streams int, events []*store.FormattedEvent) {
+	c.l.Lock()
+	defer c.l.Unlock()
+	return c.batches, c.streams, append([]*store.FormattedEvent(nil), c.events...)
+}
+
+// testStartCollector serves the collector on a local port and returns its
+// address.
+func testStartCollector(t *testing.T, c *testCollector) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	store.RegisterEventCollectorServiceServer(srv, c)
+	go func() { _ = srv.Serve(l) }()
+	t.Cleanup(srv.Stop)
+	return l.Addr().String()
+}
+
+func TestGRPCSinkConfig_validate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name            string
+		gc              GRPCSinkConfig
+		wantErrIs       error
+		wantErrContains string
+	}{
+		{
+			name:            "missing-address",
+			gc:              GRPCSinkConfig{},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "missing address",
+		},
+		{
+			name:            "address-without-port",
+			gc:              GRPCSinkConfig{Address: "collector"},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "isn't a host:port",
+		},
+		{
+			name:            "invalid-delivery-guarantee",
+			gc:              GRPCSinkConfig{Address: "collector:9400", DeliveryGuarantee: "invalid"},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "not a valid delivery guarantee",
+		},
+		{
+			name:            "tls-options-without-tls",
+			gc:              GRPCSinkConfig{Address: "collector:9400", TLSCACert: "cert"},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "without tls enabled",
+		},
+		{
+			name: "valid",
+			gc: GRPCSinkConfig{
+				Address:           "collector:9400",
+				DeliveryGuarantee: Enforced,
+				TLSEnabled:        true,
+				TLSSkipVerify:     true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			err := tt.gc.validate()
+			if tt.wantErrIs != nil {
+				require.Error(err)
+				assert.ErrorIs(err, tt.wantErrIs)
+				if tt.wantErrContains != "" {
+					assert.Contains(err.Error(), tt.wantErrContains)
+				}
+				return
+			}
+			assert.NoError(err)
+		})
+	}
+}
+
+func TestEventer_grpcSink(t *testing.T) {
+	t.Parallel()
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	newTestEventer := func(t *testing.T, c *testCollector, guarantee DeliveryGuarantee) *Eventer {
+		t.Helper()
+		conf := EventerConfig{
+			Sinks: []SinkConfig{
+				{
+					Name:       "grpc",
+					SinkType:   GRPCSink,
+					EventTypes: []Type{ErrorType},
+					Format:     JSONSinkFormat,
+					GRPCConfig: &GRPCSinkConfig{Address: testStartCollector(t, c), DeliveryGuarantee: guarantee},
+				},
+			},
+		}
+		e, err := NewEventer(testLogger, testLock, conf, WithNoBackoffJitter())
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = e.Close(context.Background()) })
+		return e
+	}
+	writeError := func(t *testing.T, e *Eventer, op Op) error {
+		t.Helper()
+		testErr, err := newError(op, fmt.Errorf("test error"))
+		require.NoError(t, err)
+		return e.writeError(context.Background(), testErr)
+	}
+
+	t.Run("success", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		c := &testCollector{}
+		e := newTestEventer(t, c, BestEffort)
+		require.NoError(writeError(t, e, "TestEventer_grpcSink"))
+
+		_, _, events := c.state()
+		require.Len(events, 1)
+		assert.Equal(string(ErrorType), events[0].EventType)
+		assert.Equal(string(JSONSinkFormat), events[0].Format)
+		assert.NotNil(events[0].CreatedAt)
+		assert.Contains(string(events[0].Data), "TestEventer_grpcSink")
+		assert.True(e.Health(context.Background()).Healthy)
+	})
+	t.Run("enforced-unpersisted-retried", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		c := &testCollector{
+			respond: func(batch int, req *store.SendEventsRequest) *store.SendEventsResponse {
+				return &store.SendEventsResponse{BatchId: req.BatchId, Persisted: batch > 1}
+			},
+		}
+		e := newTestEventer(t, c, Enforced)
+		require.NoError(writeError(t, e, "TestEventer_grpcSink"))
+
+		batches, streams, events := c.state()
+		assert.Equal(2, batches)
+		assert.Equal(1, streams)
+		assert.Len(events, 1)
+	})
+	t.Run("rejected-retried", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		c := &testCollector{
+			respond: func(batch int, req *store.SendEventsRequest) *store.SendEventsResponse {
+				if batch == 1 {
+					return &store.SendEventsResponse{BatchId: req.BatchId, Error: "try again"}
+				}
+				return &store.SendEventsResponse{BatchId: req.BatchId, Persisted: true}
+			},
+		}
+		e := newTestEventer(t, c, BestEffort)
+		require.NoError(writeError(t, e, "TestEventer_grpcSink"))
+
+		batches, _, events := c.state()
+		assert.Equal(2, batches)
+		assert.Len(events, 1)
+	})
+	t.Run("broken-stream-reopened", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		c := &testCollector{
+			respond: func(batch int, req *store.SendEventsRequest) *store.SendEventsResponse {
+				if batch == 1 {
+					return nil
+				}
+				return &store.SendEventsResponse{BatchId: req.BatchId, Persisted: true}
+			},
+		}
+		e := newTestEventer(t, c, Enforced)
+		require.NoError(writeError(t, e, "TestEventer_grpcSink"))
+
+		batches, streams, events := c.state()
+		assert.Equal(2, batches)
+		assert.Equal(2, streams)
+		assert.Len(events, 1)
+	})
+	t.Run("never-persisted", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		c := &testCollector{
+			respond: func(_ int, req *store.SendEventsRequest) *store.SendEventsResponse {
+				return &store.SendEventsResponse{BatchId: req.BatchId}
+			},
+		}
+		e := newTestEventer(t, c, Enforced)
+		err := writeError(t, e, "TestEventer_grpcSink")
+		require.Error(err)
+
+		// every attempt of the eventer's sends is retried by the sink
+		batches, _, events := c.state()
+		assert.GreaterOrEqual(batches, stdRetryCount+1)
+		assert.Empty(events)
+	})
+	t.Run("cancelled-leader", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		started, release := make(chan struct{}), make(chan struct{})
+		c := &testCollector{
+			respond: func(batch int, req *store.SendEventsRequest) *store.SendEventsResponse {
+				if batch == 1 {
+					started <- struct{}{}
+					<-release
+				}
+				return &store.SendEventsResponse{BatchId: req.BatchId, Persisted: true}
+			},
+		}
+		s, err := newGRPCSink(&Eventer{logger: hclog.NewNullLogger()}, &GRPCSinkConfig{Address: testStartCollector(t, c)}, string(JSONSinkFormat), string(JSONSinkFormat))
+		require.NoError(err)
+		t.Cleanup(func() { _ = s.Close() })
+		testEvent := func(data string) *eventlogger.Event {
+			e := &eventlogger.Event{Type: eventlogger.EventType(ErrorType)}
+			e.FormattedAs(string(JSONSinkFormat), []byte(data))
+			return e
+		}
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.Process(context.Background(), testEvent("first"))
+			assert.NoError(err)
+		}()
+		<-started
+
+		// the caller which starts the next batch gives up before it's sent,
+		// which doesn't fail the event added to it by another caller
+		ctx, cancel := context.WithCancel(context.Background())
+		leaderErr := make(chan error, 1)
+		go func() {
+			_, err := s.Process(ctx, testEvent("cancelled"))
+			leaderErr <- err
+		}()
+		pending := func(n int) func() bool {
+			return func() bool {
+				s.batchLock.Lock()
+				defer s.batchLock.Unlock()
+				return s.pending != nil && len(s.pending.events) == n
+			}
+		}
+		assert.Eventually(pending(1), 5*time.Second, 10*time.Millisecond)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.Process(context.Background(), testEvent("batched"))
+			assert.NoError(err)
+		}()
+		assert.Eventually(pending(2), 5*time.Second, 10*time.Millisecond)
+		cancel()
+		assert.ErrorIs(<-leaderErr, context.Canceled)
+
+		close(release)
+		wg.Wait()
+		_, _, events := c.state()
+		require.Len(events, 3)
+		assert.Equal("cancelled", string(events[1].Data))
+		assert.Equal("batched", string(events[2].Data))
+	})
+	t.Run("reopen", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		c := &testCollector{}
+		e := newTestEventer(t, c, BestEffort)
+		require.NoError(writeError(t, e, "first"))
+		_, err := e.ReopenSinks()
+		require.NoError(err)
+		require.NoError(writeError(t, e, "second"))
+
+		_, streams, events := c.state()
+		assert.Equal(2, streams)
+		require.Len(events, 2)
+		assert.Contains(string(events[1].Data), "second")
+	})
+}
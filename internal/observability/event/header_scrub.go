@@ -0,0 +1,85 @@
+package event
+
+import (
+	"net/http"
+	"strings"
+)
+
+// redactedHeaderValue replaces the values of sensitive headers.
+const redactedHeaderValue = "REDACTED"
+
+// defaultSensitiveHeaders are the names of the headers whose values are
+// scrubbed from the header of observations, unless they're replaced using
+// WithSensitiveHeaders.  They're the headers which carry credentials.
+var defaultSensitiveHeaders = []string{
+	"authorization",
+	"proxy-authorization",
+	"cookie",
+	"set-cookie",
+	"x-api-key",
+	"x-auth-token",
+}
+
+// newSensitiveHeaders returns the set of sensitive header names, lower cased
+// so they're matched case-insensitively.
+func newSensitiveHeaders(names []string, replaceDefaults bool) map[string]bool {
+	sensitive := map[string]bool{}
+	if !replaceDefaults {
+		for _, n := range defaultSensitiveHeaders {
+			sensitive[strings.ToLower(n)] = true
+		}
+	}
+	for _, n := range names {
+		sensitive[strings.ToLower(n)] = true
+	}
+	return sensitive
+}
+
+// scrubHeader returns a copy of the header with the value of each sensitive
+// field replaced by redactedHeaderValue.  Fields are scrubbed at any depth of
+// the header, so the headers of a captured request (as a nested map or an
+// http.Header) are scrubbed too.  The header itself isn't modified, since it
+// belongs to the event's writer.
+func scrubHeader(header map[string]interface{}, sensitive map[string]bool) map[string]interface{} {
+	if len(header) == 0 || len(sensitive) == 0 {
+		return header
+	}
+	scrubbed := make(map[string]interface{}, len(header))
+	for k, v := range header {
+		if sensitive[strings.ToLower(k)] {
+			scrubbed[k] = redactedHeaderValue
+			continue
+		}
+		scrubbed[k] = scrubHeaderValue(v, sensitive)
+	}
+	return scrubbed
+}
+
+// scrubHeaderValue scrubs the sensitive fields of a nested header value.
+// Values which can't have fields are returned as is.
+func scrubHeaderValue(v interface{}, sensitive map[string]bool) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return scrubHeader(v, sensitive)
+	case map[string]string:
+		scrubbed := make(map[string]string, len(v))
+		for k, s := range v {
+			if sensitive[strings.ToLower(k)] {
+				s = redactedHeaderValue
+			}
+			scrubbed[k] = s
+		}
+		return scrubbed
+	case http.Header:
+		scrubbed := make(http.Header, len(v))
+		for k, s := range v {
+			if sensitive[strings.ToLower(k)] {
+				s = []string{redactedHeaderValue}
+			}
+			scrubbed[k] = s
+		}
+		return scrubbed
+	default:
+		return v
+	}
+}
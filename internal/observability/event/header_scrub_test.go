@@ -0,0 +1,150 @@
+package event
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_scrubHeader(t *testing.T) {
+	t.Parallel()
+	header := func() map[string]interface{} {
+		return map[string]interface{}{
+			"Authorization": "Bearer secret",
+			"cookie":        "session=secret",
+			"X-Custom":      "custom",
+			"user_agent":    "curl",
+			"request_headers": map[string]interface{}{
+				"AUTHORIZATION": "Bearer secret",
+				"accept":        "*/*",
+			},
+			"http_headers": http.Header{
+				"Set-Cookie": []string{"session=secret"},
+				"Accept":     []string{"*/*"},
+			},
+			"labels": map[string]string{"X-Custom": "custom"},
+		}
+	}
+	tests := []struct {
+		name      string
+		sensitive map[string]bool
+		want      map[string]interface{}
+	}{
+		{
+			name:      "defaults",
+			sensitive: newSensitiveHeaders(nil, false),
+			want: map[string]interface{}{
+				"Authorization": redactedHeaderValue,
+				"cookie":        redactedHeaderValue,
+				"X-Custom":      "custom",
+				"user_agent":    "curl",
+				"request_headers": map[string]interface{}{
+					"AUTHORIZATION": redactedHeaderValue,
+					"accept":        "*/*",
+				},
+				"http_headers": http.Header{
+					"Set-Cookie": []string{redactedHeaderValue},
+					"Accept":     []string{"*/*"},
+				},
+				"labels": map[string]string{"X-Custom": "custom"},
+			},
+		},
+		{
+			name:      "extended",
+			sensitive: newSensitiveHeaders([]string{"x-custom"}, false),
+			want: map[string]interface{}{
+				"Authorization": redactedHeaderValue,
+				"cookie":        redactedHeaderValue,
+				"X-Custom":      redactedHeaderValue,
+				"user_agent":    "curl",
+				"request_headers": map[string]interface{}{
+					"AUTHORIZATION": redactedHeaderValue,
+					"accept":        "*/*",
+				},
+				"http_headers": http.Header{
+					"Set-Cookie": []string{redactedHeaderValue},
+					"Accept":     []string{"*/*"},
+				},
+				"labels": map[string]string{"X-Custom": redactedHeaderValue},
+			},
+		},
+		{
+			name:      "replaced",
+			sensitive: newSensitiveHeaders([]string{"X-CUSTOM"}, true),
+			want: map[string]interface{}{
+				"Authorization": "Bearer secret",
+				"cookie":        "session=secret",
+				"X-Custom":      redactedHeaderValue,
+				"user_agent":    "curl",
+				"request_headers": map[string]interface{}{
+					"AUTHORIZATION": "Bearer secret",
+					"accept":        "*/*",
+				},
+				"http_headers": http.Header{
+					"Set-Cookie": []string{"session=secret"},
+					"Accept":     []string{"*/*"},
+				},
+				"labels": map[string]string{"X-Custom": redactedHeaderValue},
+			},
+		},
+		{
+			name:      "disabled",
+			sensitive: newSensitiveHeaders(nil, true),
+			want:      header(),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			h := header()
+			assert.Equal(tt.want, scrubHeader(h, tt.sensitive))
+			// the writer's header isn't modified
+			assert.Equal(header(), h)
+		})
+	}
+}
+
+func TestEventer_writeObservationScrubsHeaders(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		opts []Option
+		want map[string]interface{}
+	}{
+		{
+			name: "default",
+			want: map[string]interface{}{"Authorization": redactedHeaderValue, "x-tenant": "tenant"},
+		},
+		{
+			name: "custom",
+			opts: []Option{WithSensitiveHeaders([]string{"X-Tenant"}, false)},
+			want: map[string]interface{}{"Authorization": redactedHeaderValue, "x-tenant": redactedHeaderValue},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			e, buf := NewTestEventer(t, tt.opts...)
+			obs, err := newObservation("TestEventer_writeObservationScrubsHeaders", WithHeader(map[string]interface{}{
+				"Authorization": "Bearer secret",
+				"x-tenant":      "tenant",
+			}), WithFlush())
+			require.NoError(err)
+			require.NoError(e.writeObservation(ctx, obs))
+
+			got := buf.Events(t, ObservationType)
+			require.Len(got, 1)
+			header := spanHeader(t, got[0])
+			for k, v := range tt.want {
+				assert.Equal(v, header[k], k)
+			}
+			assert.NotContains(buf.String(), "Bearer secret")
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// HealthStatus reports whether an Eventer is able to deliver events.  It's
+// intended to be serialized as JSON for health endpoints.
+type HealthStatus struct {
+	Healthy    bool                     `json:"healthy"`     // Healthy is true when every enabled event type has a sink accepting writes
+	EventTypes map[Type]EventTypeHealth `json:"event_types"` // EventTypes reports the health of each event type
+}
+
+// EventTypeHealth reports the health of a single event type.
+type EventTypeHealth struct {
+	Enabled         bool         `json:"enabled"`                // Enabled is true when the event type is emitted
+	AcceptingWrites bool         `json:"accepting_writes"`       // AcceptingWrites is true when at least one sink is accepting writes
+	LastSuccess     *time.Time   `json:"last_success,omitempty"` // LastSuccess is the time of the last successful send
+	RecentFailures  int          `json:"recent_failures"`        // RecentFailures is the number of failed sends since the last successful send
+	Sinks           []SinkHealth `json:"sinks,omitempty"`        // Sinks reports the health of the event type's sinks
+	RateLimited     uint64       `json:"rate_limited,omitempty"` // RateLimited is the number of events dropped by the type's rate limit (see WithRateLimit)
+}
+
+// SinkHealth reports the health of a single sink.
+type SinkHealth struct {
+	Name            string       `json:"name"`
+	AcceptingWrites bool         `json:"accepting_writes"`
+	Error           string       `json:"error,omitempty"`
+	CircuitBreaker  CircuitState `json:"circuit_breaker,omitempty"` // CircuitBreaker is the state of a network sink's circuit breaker
+	Spooled         int          `json:"spooled,omitempty"`         // Spooled is the number of events in a network sink's spool waiting to be replayed
+	SpoolDropped    uint64       `json:"spool_dropped,omitempty"`   // SpoolDropped is the number of events dropped because a network sink's spool was full
+}
+
+// healthChecker defines an interface for sink nodes which are able to check
+// if they're accepting writes.
+type healthChecker interface {
+	checkHealth(ctx context.Context) error
+}
+
+// eventerHealth tracks the results of an Eventer's sends.  A nil eventerHealth
+// is valid and doesn't track anything.
+type eventerHealth struct {
+	l              sync.Mutex
+	lastSuccess    map[Type]time.Time
+	recentFailures map[Type]int
+}
+
+func newEventerHealth() *eventerHealth {
+	return &eventerHealth{
+		lastSuccess:    map[Type]time.Time{},
+		recentFailures: map[Type]int{},
+	}
+}
+
+// record the result of sending an event of type t.
+func (h *eventerHealth) record(t Type, sendErr error) {
+	if h == nil {
+		return
+	}
+	h.l.Lock()
+	defer h.l.Unlock()
+	if sendErr != nil {
+		h.recentFailures[t]++
+		return
+	}
+	h.lastSuccess[t] = time.Now()
+	h.recentFailures[t] = 0
+}
+
+func (h *eventerHealth) get(t Type) (*time.Time, int) {
+	if h == nil {
+		return nil, 0
+	}
+	h.l.Lock()
+	defer h.l.Unlock()
+	var last *time.Time
+	if ts, ok := h.lastSuccess[t]; ok {
+		last = &ts
+	}
+	return last, h.recentFailures[t]
+}
+
+// AuditDegraded is true when the eventer's config has an
+// AuditFailClosedThreshold and at least that many consecutive audit events
+// have failed to be delivered.  It's false again once an audit event is
+// delivered.
+func (e *Eventer) AuditDegraded() bool {
+	if e.conf.AuditFailClosedThreshold <= 0 {
+		return false
+	}
+	_, failures := e.health.get(AuditType)
+	return failures >= e.conf.AuditFailClosedThreshold
+}
+
+// Health reports whether each event type has at least one sink currently
+// accepting writes, along with the last successful send and the number of
+// failed sends since then.  File sinks are checked by stat'ing their file
+// and stderr sinks are always considered to be accepting writes.
+func (e *Eventer) Health(ctx context.Context) HealthStatus {
+	status := HealthStatus{
+		Healthy:    true,
+		EventTypes: map[Type]EventTypeHealth{},
+	}
+	type typePipelines struct {
+		t         Type
+		enabled   bool
+		pipelines []pipeline
+	}
+	types := []typePipelines{
+		{t: AuditType, enabled: e.conf.AuditEnabled, pipelines: e.auditPipelines},
+		{t: ObservationType, enabled: e.conf.ObservationsEnabled, pipelines: e.observationPipelines},
+		{t: ErrorType, enabled: true, pipelines: e.errPipelines},
+		{t: SystemType, enabled: e.conf.SysEventsEnabled, pipelines: e.sysPipelines},
+	}
+	// custom types are always enabled, and only reported when a sink
+	// subscribes to them
+	customPipelines := map[Type][]pipeline{}
+	for _, p := range e.customPipelines {
+		customPipelines[p.eventType] = append(customPipelines[p.eventType], p)
+	}
+	for t, pipelines := range customPipelines {
+		types = append(types, typePipelines{t: t, enabled: true, pipelines: pipelines})
+	}
+	for _, et := range types {
+		h := EventTypeHealth{
+			Enabled: et.enabled,
+		}
+		h.LastSuccess, h.RecentFailures = e.health.get(et.t)
+		for _, n := range e.rateLimitNodes[et.t] {
+			h.RateLimited += n.Dropped()
+		}
+		for _, p := range et.pipelines {
+			sh := SinkHealth{
+				Name:            p.sinkConfig.Name,
+				AcceptingWrites: true,
+			}
+			if err := p.checkSink(ctx); err != nil {
+				sh.AcceptingWrites = false
+				sh.Error = err.Error()
+			}
+			if cb := p.circuitBreaker(); cb != nil {
+				sh.CircuitBreaker = cb.State()
+				sh.Spooled = cb.Spooled()
+				sh.SpoolDropped = cb.SpoolDropped()
+			}
+			h.AcceptingWrites = h.AcceptingWrites || sh.AcceptingWrites
+			h.Sinks = append(h.Sinks, sh)
+		}
+		if h.Enabled && (!h.AcceptingWrites || h.RecentFailures > 0) {
+			status.Healthy = false
+		}
+		status.EventTypes[et.t] = h
+	}
+	return status
+}
+
+// checkSink returns an error when the pipeline's sink isn't accepting writes.
+func (p pipeline) checkSink(ctx context.Context) error {
+	node := p.sinkNode
+	if m, ok := node.(*mirrorSink); ok {
+		// only the primary sink is checked, since mirrors don't affect
+		// delivery
+		node = m.primary
+	}
+	if hc, ok := node.(healthChecker); ok {
+		return hc.checkHealth(ctx)
+	}
+	switch p.sinkConfig.SinkType {
+	case FileSink:
+		return checkFileSink(p.sinkConfig)
+	}
+	return nil
+}
+
+// circuitBreaker returns the circuit breaker of the pipeline's sink, or nil
+// when the sink doesn't have one.
+func (p pipeline) circuitBreaker() *circuitBreakerSink {
+	node := p.sinkNode
+	if m, ok := node.(*mirrorSink); ok {
+		node = m.primary
+	}
+	cb, _ := node.(*circuitBreakerSink)
+	return cb
+}
+
+// checkFileSink ensures the sink's directory exists and its file (if it's been
+// created) can be opened for writing, without writing anything to it.
+func checkFileSink(sc SinkConfig) error {
+	const op = "event.checkFileSink"
+	path := filepath.Join(sc.Path, sc.FileName)
+	fi, err := os.Stat(filepath.Dir(path))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("%s: %s is not a directory", op, filepath.Dir(path))
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	switch {
+	case os.IsNotExist(err):
+		// the file will be created by the first write
+		return nil
+	case err != nil:
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return f.Close()
+}
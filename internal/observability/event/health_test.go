@@ -0,0 +1,160 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventer_Health(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+	})
+
+	t.Run("send-failures", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		testSetup := TestEventerConfig(t, "TestEventer_Health")
+		b := &testMockBroker{errorOnSend: fmt.Errorf("%s: no msg: %w", "test", ErrIo)}
+		eventer, err := NewEventer(testLogger, testLock, testSetup.EventerConfig, TestWithBroker(t, b))
+		require.NoError(err)
+
+		got := eventer.Health(ctx)
+		assert.True(got.Healthy)
+		assert.Nil(got.EventTypes[ErrorType].LastSuccess)
+
+		testErr, err := newError("TestEventer_Health", fmt.Errorf("test error"))
+		require.NoError(err)
+		require.Error(eventer.writeError(ctx, testErr))
+		require.Error(eventer.writeError(ctx, testErr))
+
+		got = eventer.Health(ctx)
+		assert.False(got.Healthy)
+		errHealth := got.EventTypes[ErrorType]
+		assert.True(errHealth.Enabled)
+		assert.True(errHealth.AcceptingWrites)
+		assert.Equal(2, errHealth.RecentFailures)
+		assert.Nil(errHealth.LastSuccess)
+
+		// a successful send resets the recent failures
+		b.errorOnSend = nil
+		require.NoError(eventer.writeError(ctx, testErr))
+		got = eventer.Health(ctx)
+		assert.True(got.Healthy)
+		assert.Equal(0, got.EventTypes[ErrorType].RecentFailures)
+		assert.NotNil(got.EventTypes[ErrorType].LastSuccess)
+	})
+	t.Run("file-sink-not-accepting-writes", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		tmpDir, err := ioutil.TempDir("", "TestEventer_Health")
+		require.NoError(err)
+		t.Cleanup(func() { os.RemoveAll(tmpDir) })
+		sinkDir := filepath.Join(tmpDir, "events")
+
+		c := EventerConfig{
+			Sinks: []SinkConfig{
+				{
+					Name:       "err-file-sink",
+					SinkType:   FileSink,
+					EventTypes: []Type{ErrorType},
+					Format:     JSONSinkFormat,
+					Path:       sinkDir,
+					FileName:   "errors.log",
+				},
+			},
+		}
+		eventer, err := NewEventer(testLogger, testLock, c)
+		require.NoError(err)
+		assert.True(eventer.Health(ctx).Healthy)
+
+		require.NoError(os.RemoveAll(sinkDir))
+		got := eventer.Health(ctx)
+		assert.False(got.Healthy)
+		errHealth := got.EventTypes[ErrorType]
+		assert.False(errHealth.AcceptingWrites)
+		require.Len(errHealth.Sinks, 1)
+		assert.Equal("err-file-sink", errHealth.Sinks[0].Name)
+		assert.False(errHealth.Sinks[0].AcceptingWrites)
+		assert.NotEmpty(errHealth.Sinks[0].Error)
+
+		// disabled event types don't affect the eventer's health
+		assert.False(got.EventTypes[AuditType].Enabled)
+	})
+	t.Run("json", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		testSetup := TestEventerConfig(t, "TestEventer_Health")
+		eventer, err := NewEventer(testLogger, testLock, testSetup.EventerConfig, TestWithBroker(t, &testMockBroker{}))
+		require.NoError(err)
+		testErr, err := newError("TestEventer_Health", fmt.Errorf("test error"))
+		require.NoError(err)
+		require.NoError(eventer.writeError(ctx, testErr))
+
+		b, err := json.Marshal(eventer.Health(ctx))
+		require.NoError(err)
+		var got HealthStatus
+		require.NoError(json.Unmarshal(b, &got))
+		assert.True(got.Healthy)
+		assert.NotNil(got.EventTypes[ErrorType].LastSuccess)
+		assert.Len(got.EventTypes[ErrorType].Sinks, 3)
+	})
+}
+
+func TestEventer_AuditDegraded(t *testing.T) {
+	TestEnableEventing(t, true)
+	assert, require := assert.New(t), require.New(t)
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	c := EventerConfig{
+		AuditEnabled:             true,
+		Sinks:                    []SinkConfig{DefaultSink()},
+		AuditFailClosedThreshold: 2,
+	}
+	testBroker := &testMockBroker{errorOnSend: errors.New("not written to enough sinks")}
+	e, err := NewEventer(testLogger, testLock, c, TestWithBroker(t, testBroker), WithNoBackoffJitter())
+	require.NoError(err)
+	ctx, err := NewEventerContext(context.Background(), e)
+	require.NoError(err)
+
+	assert.NoError(CheckAuditDegraded(ctx))
+	assert.Error(WriteAudit(ctx, "TestEventer_AuditDegraded", WithFlush()))
+	assert.False(e.AuditDegraded())
+	assert.NoError(CheckAuditDegraded(ctx))
+
+	// the second consecutive failure reaches the threshold
+	assert.Error(WriteAudit(ctx, "TestEventer_AuditDegraded", WithFlush()))
+	assert.True(e.AuditDegraded())
+	assert.ErrorIs(CheckAuditDegraded(ctx), ErrAuditDegraded)
+
+	// a delivered audit event recovers the eventer
+	testBroker.errorOnSend = nil
+	assert.NoError(WriteAudit(ctx, "TestEventer_AuditDegraded", WithFlush()))
+	assert.False(e.AuditDegraded())
+	assert.NoError(CheckAuditDegraded(ctx))
+
+	// an eventer without a threshold is never degraded
+	c.AuditFailClosedThreshold = 0
+	testBroker.errorOnSend = errors.New("not written to enough sinks")
+	e, err = NewEventer(testLogger, testLock, c, TestWithBroker(t, testBroker), WithNoBackoffJitter())
+	require.NoError(err)
+	for i := 0; i < 3; i++ {
+		a, err := newAudit("TestEventer_AuditDegraded", WithFlush())
+		require.NoError(err)
+		assert.Error(e.writeAudit(ctx, a))
+	}
+	assert.False(e.AuditDegraded())
+}
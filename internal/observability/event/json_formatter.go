@@ -0,0 +1,130 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+)
+
+// jsonPrettyFormat is the format key used for indented JSON.  Formatters which
+// may process the same event must store their output under distinct keys.
+const jsonPrettyFormat = "json-pretty"
+
+// sortedKeysFormatSuffix is appended to the format key of the formatters
+// which sort the keys of the event's payload.
+const sortedKeysFormatSuffix = "-sorted-keys"
+
+// jsonFormatter is a formatter node which formats an event as JSON.  When
+// pretty is true, the JSON is indented which makes it easier for humans to
+// read, but also means each event spans multiple lines.  When epochMillis is
+// true, the created at timestamp is the number of milliseconds since the Unix
+// epoch rather than an RFC3339 string.  When it has fieldNames, the fields of
+// the event's payload are renamed by them (see SinkConfig.FieldNameMap), and
+// the formatted data is stored with the key of fieldNamesKey.  When
+// sortedKeys is true, the keys of every object of the payload are sorted (see
+// SinkConfig.SortedKeys).
+type jsonFormatter struct {
+	pretty      bool
+	epochMillis bool
+	sortedKeys  bool
+
+	fieldNames    map[string]string
+	fieldNamesKey string
+}
+
+var _ eventlogger.Node = &jsonFormatter{}
+
+// Process formats the event as JSON and stores the formatted data in the
+// event's Formatted field with a key of "json" (or "json-pretty" when pretty),
+// suffixed with "-epoch-millis" when epochMillis and then with "-sorted-keys"
+// when sortedKeys, or with a key of fieldNamesKey when it has fieldNames.
+func (f *jsonFormatter) Process(_ context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	const op = "event.(jsonFormatter).Process"
+	if e == nil {
+		return nil, fmt.Errorf("%s: missing event: %w", op, ErrInvalidParameter)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	if f.pretty {
+		enc.SetIndent("", "  ")
+	}
+	var createdAt interface{} = e.CreatedAt
+	if f.epochMillis {
+		createdAt = e.CreatedAt.UnixNano() / int64(time.Millisecond)
+	}
+	payload := e.Payload
+	switch {
+	case len(f.fieldNames) > 0:
+		// the renamed payload is a map, so its keys are already sorted
+		var err error
+		if payload, err = renameFields(e.Payload, f.fieldNames); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	case f.sortedKeys:
+		var err error
+		if payload, err = sortKeys(e.Payload); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	err := enc.Encode(struct {
+		CreatedAt interface{}           `json:"created_at"`
+		EventType eventlogger.EventType `json:"event_type"`
+		Payload   interface{}           `json:"payload"`
+	}{
+		createdAt,
+		e.Type,
+		payload,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(f.fieldNames) > 0 {
+		e.FormattedAs(f.fieldNamesKey, buf.Bytes())
+		return e, nil
+	}
+	format := eventlogger.JSONFormat
+	if f.pretty {
+		format = jsonPrettyFormat
+	}
+	if f.epochMillis {
+		format += epochMillisFormatSuffix
+	}
+	if f.sortedKeys {
+		format += sortedKeysFormatSuffix
+	}
+	e.FormattedAs(format, buf.Bytes())
+	return e, nil
+}
+
+// sortKeys returns the payload as the value decoded from its JSON, whose
+// objects are maps and so are encoded with their keys sorted, rather than in
+// the order of the fields of the payload's structs.  Numbers are decoded as
+// json.Numbers, so they're encoded exactly as they were.
+func sortKeys(payload interface{}) (interface{}, error) {
+	const op = "event.sortKeys"
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var sorted interface{}
+	if err := dec.Decode(&sorted); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return sorted, nil
+}
+
+// Reopen is a no op
+func (f *jsonFormatter) Reopen() error {
+	return nil
+}
+
+// Type describes the type of the node as a Formatter.
+func (f *jsonFormatter) Type() eventlogger.NodeType {
+	return eventlogger.NodeTypeFormatter
+}
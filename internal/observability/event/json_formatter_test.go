@@ -0,0 +1,295 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_jsonFormatter_Process(t *testing.T) {
+	t.Parallel()
+	testEvent := func() *eventlogger.Event {
+		return &eventlogger.Event{
+			Type:      eventlogger.EventType(SystemType),
+			CreatedAt: time.Now(),
+			Payload:   map[string]interface{}{"name": "value"},
+		}
+	}
+	tests := []struct {
+		name            string
+		formatter       *jsonFormatter
+		event           *eventlogger.Event
+		wantFormat      string
+		wantIndent      bool
+		wantEpochMillis bool
+		wantErrIs       error
+	}{
+		{
+			name:      "missing-event",
+			formatter: &jsonFormatter{},
+			wantErrIs: ErrInvalidParameter,
+		},
+		{
+			name:       "compact",
+			formatter:  &jsonFormatter{},
+			event:      testEvent(),
+			wantFormat: eventlogger.JSONFormat,
+		},
+		{
+			name:       "pretty",
+			formatter:  &jsonFormatter{pretty: true},
+			event:      testEvent(),
+			wantFormat: jsonPrettyFormat,
+			wantIndent: true,
+		},
+		{
+			name:            "epoch-millis",
+			formatter:       &jsonFormatter{epochMillis: true},
+			event:           testEvent(),
+			wantFormat:      "json-epoch-millis",
+			wantEpochMillis: true,
+		},
+		{
+			name:            "pretty-epoch-millis",
+			formatter:       &jsonFormatter{pretty: true, epochMillis: true},
+			event:           testEvent(),
+			wantFormat:      "json-pretty-epoch-millis",
+			wantIndent:      true,
+			wantEpochMillis: true,
+		},
+		{
+			name:            "pretty-epoch-millis-sorted-keys",
+			formatter:       &jsonFormatter{pretty: true, epochMillis: true, sortedKeys: true},
+			event:           testEvent(),
+			wantFormat:      "json-pretty-epoch-millis-sorted-keys",
+			wantIndent:      true,
+			wantEpochMillis: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			got, err := tt.formatter.Process(context.Background(), tt.event)
+			if tt.wantErrIs != nil {
+				require.Error(err)
+				assert.ErrorIs(err, tt.wantErrIs)
+				return
+			}
+			require.NoError(err)
+			b, ok := got.Format(tt.wantFormat)
+			require.True(ok)
+			var m map[string]interface{}
+			require.NoError(json.Unmarshal(b, &m))
+			assert.Equal(tt.wantIndent, strings.Contains(string(b), "\n  "))
+			if tt.wantEpochMillis {
+				assert.Equal(float64(tt.event.CreatedAt.UnixNano()/int64(time.Millisecond)), m["created_at"])
+			} else {
+				assert.Equal(tt.event.CreatedAt.Format(time.RFC3339Nano), m["created_at"])
+			}
+		})
+	}
+}
+
+func Test_jsonFormatter_sortedKeys(t *testing.T) {
+	t.Parallel()
+	type nested struct {
+		Y string `json:"y"`
+		B string `json:"b"`
+	}
+	type payload struct {
+		Zeta   string                 `json:"zeta"`
+		Alpha  int64                  `json:"alpha"`
+		Nested nested                 `json:"nested"`
+		Data   map[string]interface{} `json:"data"`
+	}
+	createdAt := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	testEvent := func() *eventlogger.Event {
+		return &eventlogger.Event{
+			Type:      eventlogger.EventType(AuditType),
+			CreatedAt: createdAt,
+			Payload: &payload{
+				Zeta:   "z",
+				Alpha:  1 << 60,
+				Nested: nested{Y: "y", B: "b"},
+				Data:   map[string]interface{}{"k2": 2.5, "k1": []interface{}{"v"}},
+			},
+		}
+	}
+	f := &jsonFormatter{sortedKeys: true}
+	format := eventlogger.JSONFormat + sortedKeysFormatSuffix
+
+	first, err := f.Process(context.Background(), testEvent())
+	require.NoError(t, err)
+	firstBytes, ok := first.Format(format)
+	require.True(t, ok)
+	second, err := f.Process(context.Background(), testEvent())
+	require.NoError(t, err)
+	secondBytes, ok := second.Format(format)
+	require.True(t, ok)
+
+	assert.Equal(t, firstBytes, secondBytes)
+	assert.Equal(t, `{"created_at":"2021-06-01T12:00:00Z","event_type":"audit","payload":{"alpha":1152921504606846976,"data":{"k1":["v"],"k2":2.5},"nested":{"b":"b","y":"y"},"zeta":"z"}}`+"\n", string(firstBytes))
+}
+
+func TestEventer_jsonPretty(t *testing.T) {
+	t.Parallel()
+	compactFile, err := ioutil.TempFile("./", "tmp-compact-TestEventer_jsonPretty")
+	require.NoError(t, err)
+	prettyFile, err := ioutil.TempFile("./", "tmp-pretty-TestEventer_jsonPretty")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		os.Remove(compactFile.Name())
+		os.Remove(prettyFile.Name())
+	})
+	c := EventerConfig{
+		Sinks: []SinkConfig{
+			{
+				Name:       "compact",
+				SinkType:   FileSink,
+				EventTypes: []Type{ErrorType},
+				Format:     JSONSinkFormat,
+				Path:       "./",
+				FileName:   compactFile.Name(),
+			},
+			{
+				Name:       "pretty",
+				SinkType:   FileSink,
+				EventTypes: []Type{ErrorType},
+				Format:     JSONSinkFormat,
+				Path:       "./",
+				FileName:   prettyFile.Name(),
+				JSONPretty: true,
+			},
+		},
+	}
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	e, err := NewEventer(testLogger, testLock, c)
+	require.NoError(t, err)
+
+	testError, err := newError("TestEventer_jsonPretty", fmt.Errorf("%s: no msg: test", ErrIo))
+	require.NoError(t, err)
+	require.NoError(t, e.writeError(context.Background(), testError))
+
+	compact, err := ioutil.ReadFile(compactFile.Name())
+	require.NoError(t, err)
+	pretty, err := ioutil.ReadFile(prettyFile.Name())
+	require.NoError(t, err)
+
+	assert.NotContains(t, string(compact), "\n  ")
+	assert.Contains(t, string(pretty), "\n  ")
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal(pretty, &m))
+}
+
+func TestEventer_FieldNameMap(t *testing.T) {
+	t.Parallel()
+	c := EventerConfig{
+		AuditEnabled: true,
+		Sinks: []SinkConfig{
+			{
+				Name:         "renamed",
+				SinkType:     StderrSink,
+				EventTypes:   []Type{AuditType},
+				Format:       JSONSinkFormat,
+				FieldNameMap: map[string]string{"type": "audit_type", "id": "audit_id"},
+			},
+			{
+				Name:       "unchanged",
+				SinkType:   DiscardSink,
+				EventTypes: []Type{EveryType},
+				Format:     JSONSinkFormat,
+			},
+		},
+	}
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	buf := &SinkBuffer{}
+	e, err := NewEventer(testLogger, testLock, c, WithStderrWriter(buf))
+	require.NoError(t, err)
+
+	a, err := newAudit("TestEventer_FieldNameMap", WithId("audit-id"), WithFlush())
+	require.NoError(t, err)
+	require.NoError(t, e.writeAudit(context.Background(), a))
+
+	got := buf.Events(t, AuditType)
+	require.Len(t, got, 1)
+	assert.Equal(t, AuditType, got[0].EventType)
+	assert.Equal(t, "audit-id", got[0].Payload["audit_id"])
+	assert.Equal(t, string(ApiRequest), got[0].Payload["audit_type"])
+	assert.NotContains(t, got[0].Payload, "id")
+	assert.NotContains(t, got[0].Payload, "type")
+	assert.Contains(t, got[0].Payload, "timestamp")
+}
+
+func Test_renameFields(t *testing.T) {
+	t.Parallel()
+	payload := map[string]interface{}{"id": "1", "type": "t", "version": "v0.1"}
+
+	got, err := renameFields(payload, map[string]string{"id": "type", "type": "id"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"id": "t", "type": "1", "version": "v0.1"}, got)
+
+	_, err = renameFields(payload, map[string]string{"id": "version"})
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+	assert.Contains(t, err.Error(), "collides with a field of the event")
+}
+
+func TestEventer_SortedKeys(t *testing.T) {
+	t.Parallel()
+	c := EventerConfig{
+		AuditEnabled: true,
+		Sinks: []SinkConfig{
+			{
+				Name:       "sorted",
+				SinkType:   StderrSink,
+				EventTypes: []Type{AuditType},
+				Format:     JSONSinkFormat,
+				SortedKeys: true,
+			},
+			{
+				Name:       "unsorted",
+				SinkType:   DiscardSink,
+				EventTypes: []Type{EveryType},
+				Format:     JSONSinkFormat,
+			},
+		},
+	}
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	buf := &SinkBuffer{}
+	e, err := NewEventer(testLogger, testLock, c, WithStderrWriter(buf))
+	require.NoError(t, err)
+
+	a, err := newAudit("TestEventer_SortedKeys", WithId("audit-id"), WithFlush())
+	require.NoError(t, err)
+	require.NoError(t, e.writeAudit(context.Background(), a))
+
+	require.Len(t, buf.Events(t, AuditType), 1)
+	// the audit struct declares id before auth_info, which precedes it once
+	// the keys are sorted
+	out := buf.String()
+	assert.Less(t, strings.Index(out, `"auth_info"`), strings.Index(out, `"id"`))
+	assert.Less(t, strings.Index(out, `"id"`), strings.Index(out, `"type"`))
+	assert.Less(t, strings.Index(out, `"type"`), strings.Index(out, `"version"`))
+}
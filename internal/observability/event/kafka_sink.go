@@ -0,0 +1,386 @@
+package event
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/eventlogger/filters/gated"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+const (
+	DefaultPartitionKey       KafkaPartitionKey = ""               // DefaultPartitionKey will be NoPartitionKey
+	NoPartitionKey            KafkaPartitionKey = "none"           // NoPartitionKey lets the producer pick a partition
+	CorrelationIdPartitionKey KafkaPartitionKey = "correlation_id" // CorrelationIdPartitionKey keys messages by the event's correlation id
+	EventTypePartitionKey     KafkaPartitionKey = "event_type"     // EventTypePartitionKey keys messages by the event's type
+)
+
+// KafkaPartitionKey defines the strategy used to pick the key of each message
+// produced by a KafkaSink.
+type KafkaPartitionKey string
+
+func (k KafkaPartitionKey) validate() error {
+	const op = "event.(KafkaPartitionKey).validate"
+	switch k {
+	case DefaultPartitionKey, NoPartitionKey, CorrelationIdPartitionKey, EventTypePartitionKey:
+		return nil
+	default:
+		return fmt.Errorf("%s: '%s' is not a valid partition key: %w", op, k, ErrInvalidParameter)
+	}
+}
+
+// KafkaSinkConfig defines the configuration for a KafkaSink
+type KafkaSinkConfig struct {
+	Brokers           []string          `hcl:"brokers"`            // Brokers defines the list of broker addresses (host:port)
+	Topic             string            `hcl:"topic"`              // Topic defines the topic events are produced to
+	PartitionKey      KafkaPartitionKey `hcl:"partition_key"`      // PartitionKey defines the strategy used to key messages
+	DeliveryGuarantee DeliveryGuarantee `hcl:"delivery_guarantee"` // DeliveryGuarantee of Enforced will wait for broker acks
+	SASLMechanism     string            `hcl:"sasl_mechanism"`     // SASLMechanism defines an optional SASL mechanism (PLAIN, SCRAM-SHA-256, SCRAM-SHA-512)
+	SASLUsername      string            `hcl:"sasl_username"`      // SASLUsername defines the SASL username
+	SASLPassword      string            `hcl:"sasl_password"`      // SASLPassword defines the SASL password
+	TLSEnabled        bool              `hcl:"tls_enabled"`        // TLSEnabled specifies that TLS should be used to connect to brokers
+	TLSCACert         string            `hcl:"tls_ca_cert"`        // TLSCACert defines an optional PEM encoded CA cert
+	TLSSkipVerify     bool              `hcl:"tls_skip_verify"`    // TLSSkipVerify disables verification of the brokers' certs
+}
+
+func (kc *KafkaSinkConfig) validate() error {
+	const op = "event.(KafkaSinkConfig).validate"
+	if len(kc.Brokers) == 0 {
+		return fmt.Errorf("%s: missing brokers: %w", op, ErrInvalidParameter)
+	}
+	if kc.Topic == "" {
+		return fmt.Errorf("%s: missing topic: %w", op, ErrInvalidParameter)
+	}
+	if err := kc.PartitionKey.validate(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := kc.DeliveryGuarantee.validate(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	switch kc.SASLMechanism {
+	case "":
+		if kc.SASLUsername != "" || kc.SASLPassword != "" {
+			return fmt.Errorf("%s: sasl credentials provided without a sasl mechanism: %w", op, ErrInvalidParameter)
+		}
+	case "PLAIN", "SCRAM-SHA-256", "SCRAM-SHA-512":
+		if kc.SASLUsername == "" {
+			return fmt.Errorf("%s: missing sasl username: %w", op, ErrInvalidParameter)
+		}
+	default:
+		return fmt.Errorf("%s: '%s' is not a valid sasl mechanism: %w", op, kc.SASLMechanism, ErrInvalidParameter)
+	}
+	if !kc.TLSEnabled && (kc.TLSCACert != "" || kc.TLSSkipVerify) {
+		return fmt.Errorf("%s: tls options provided without tls enabled: %w", op, ErrInvalidParameter)
+	}
+	return nil
+}
+
+// KafkaProducer defines the interface a KafkaSink uses to produce messages.
+// Produce must block until the brokers have acknowledged the message when
+// waitForAck is true.  Producers which also implement KafkaPinger will have
+// their connection checked by Eventer.Health.
+type KafkaProducer interface {
+	Produce(ctx context.Context, topic string, key, value []byte, waitForAck bool) error
+	Close() error
+}
+
+// KafkaAckProducer defines an optional interface for KafkaProducers which
+// report the brokers' acknowledgement of each message they produce.  When a
+// KafkaSink's DeliveryGuarantee is Enforced, it produces with ProduceAck
+// rather than Produce, and only considers a message delivered when its Ack
+// is Persisted (the message was acknowledged by all of the topic's in-sync
+// replicas, acks=all).  Messages which weren't persisted are produced again.
+// ProduceAck must block until the brokers have responded.
+type KafkaAckProducer interface {
+	ProduceAck(ctx context.Context, topic string, key, value []byte) (Ack, error)
+}
+
+// KafkaPinger defines an optional interface for KafkaProducers which are able
+// to check their connection to the brokers.
+type KafkaPinger interface {
+	Ping(ctx context.Context) error
+}
+
+// KafkaProducerFactory creates a KafkaProducer for the config.  It's called
+// when the Eventer is created and every time the sink is reopened.
+type KafkaProducerFactory func(*KafkaSinkConfig) (KafkaProducer, error)
+
+// defaultKafkaProducer creates a producer which connects to the config's
+// brokers with its SASL and TLS settings.  When the config's
+// DeliveryGuarantee is Enforced, messages are produced with acks=all, so a
+// produced message was acknowledged by all of the topic's in-sync replicas;
+// otherwise only the partition leader's ack is required.  The producer
+// doesn't connect until it's used, so an unreachable broker doesn't keep the
+// Eventer from being created.
+func defaultKafkaProducer(c *KafkaSinkConfig) (KafkaProducer, error) {
+	const op = "event.defaultKafkaProducer"
+	transport := &kafka.Transport{}
+	if c.TLSEnabled {
+		tlsConfig := &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: c.TLSSkipVerify,
+		}
+		if c.TLSCACert != "" {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM([]byte(c.TLSCACert)) {
+				return nil, fmt.Errorf("%s: unable to parse tls ca cert: %w", op, ErrInvalidParameter)
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport.TLS = tlsConfig
+	}
+	var mechanism sasl.Mechanism
+	switch c.SASLMechanism {
+	case "PLAIN":
+		mechanism = plain.Mechanism{Username: c.SASLUsername, Password: c.SASLPassword}
+	case "SCRAM-SHA-256", "SCRAM-SHA-512":
+		algo := scram.SHA256
+		if c.SASLMechanism == "SCRAM-SHA-512" {
+			algo = scram.SHA512
+		}
+		var err error
+		if mechanism, err = scram.Mechanism(algo, c.SASLUsername, c.SASLPassword); err != nil {
+			return nil, fmt.Errorf("%s: unable to create sasl mechanism: %w", op, err)
+		}
+	}
+	transport.SASL = mechanism
+
+	acks := kafka.RequireOne
+	if c.DeliveryGuarantee == Enforced {
+		acks = kafka.RequireAll
+	}
+	addr := kafka.TCP(c.Brokers...)
+	return &kafkaGoProducer{
+		writer: &kafka.Writer{
+			Addr: addr,
+			// keyed messages are always produced to the same partition,
+			// and messages without a key are spread across them
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: acks,
+			// the sink produces one message at a time and retries failed
+			// sends itself
+			BatchSize:   1,
+			MaxAttempts: 1,
+			Transport:   transport,
+		},
+		client: &kafka.Client{
+			Addr:      addr,
+			Transport: transport,
+		},
+		transport: transport,
+		topic:     c.Topic,
+	}, nil
+}
+
+// kafkaGoProducer is the KafkaProducer created by defaultKafkaProducer.
+type kafkaGoProducer struct {
+	writer    *kafka.Writer
+	client    *kafka.Client
+	transport *kafka.Transport
+	topic     string
+}
+
+var (
+	_ KafkaProducer = &kafkaGoProducer{}
+	_ KafkaPinger   = &kafkaGoProducer{}
+)
+
+// Produce writes the message and waits for the brokers' response, with the
+// acks configured by defaultKafkaProducer, regardless of waitForAck.
+func (p *kafkaGoProducer) Produce(ctx context.Context, topic string, key, value []byte, _ bool) error {
+	const op = "event.(kafkaGoProducer).Produce"
+	if err := p.writer.WriteMessages(ctx, kafka.Message{Topic: topic, Key: key, Value: value}); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// Ping requests the metadata of the sink's topic from the brokers.
+func (p *kafkaGoProducer) Ping(ctx context.Context) error {
+	const op = "event.(kafkaGoProducer).Ping"
+	out, err := p.client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{p.topic}})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	for _, t := range out.Topics {
+		if t.Error != nil {
+			return fmt.Errorf("%s: topic %q: %w", op, t.Name, t.Error)
+		}
+	}
+	return nil
+}
+
+// Close closes the writer and the transport's idle connections.
+func (p *kafkaGoProducer) Close() error {
+	const op = "event.(kafkaGoProducer).Close"
+	defer p.transport.CloseIdleConnections()
+	if err := p.writer.Close(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// kafkaSink is a sink node which produces formatted events to a Kafka topic.
+type kafkaSink struct {
+	config  *KafkaSinkConfig
+	format  string
+	factory KafkaProducerFactory
+	eventer *Eventer
+
+	l        sync.Mutex
+	producer KafkaProducer
+}
+
+var _ eventlogger.Node = &kafkaSink{}
+
+func newKafkaSink(e *Eventer, c *KafkaSinkConfig, format string, factory KafkaProducerFactory) (*kafkaSink, error) {
+	const op = "event.newKafkaSink"
+	if e == nil {
+		return nil, fmt.Errorf("%s: missing eventer: %w", op, ErrInvalidParameter)
+	}
+	if c == nil {
+		return nil, fmt.Errorf("%s: missing kafka config: %w", op, ErrInvalidParameter)
+	}
+	if format == "" {
+		return nil, fmt.Errorf("%s: missing format: %w", op, ErrInvalidParameter)
+	}
+	if factory == nil {
+		factory = defaultKafkaProducer
+	}
+	p, err := factory(c)
+	if err != nil {
+		return nil, fmt.Errorf("%s: unable to create kafka producer: %w", op, err)
+	}
+	return &kafkaSink{
+		config:   c,
+		format:   format,
+		factory:  factory,
+		eventer:  e,
+		producer: p,
+	}, nil
+}
+
+// Process produces the event's formatted data to the configured topic.
+// Transient broker errors, and messages which weren't acknowledged as
+// persisted when delivery is Enforced (see KafkaAckProducer), are retried
+// with a backoff.
+func (s *kafkaSink) Process(ctx context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	const op = "event.(kafkaSink).Process"
+	if e == nil {
+		return nil, fmt.Errorf("%s: missing event: %w", op, ErrInvalidParameter)
+	}
+	value, ok := e.Format(s.format)
+	if !ok {
+		return nil, fmt.Errorf("%s: unable to find %s format: %w", op, s.format, ErrInvalidParameter)
+	}
+	key := s.partitionKey(e)
+	enforced := s.config.DeliveryGuarantee == Enforced
+	err := s.eventer.sendAcked(ctx, enforced, func() (Ack, error) {
+		s.l.Lock()
+		defer s.l.Unlock()
+		if p, ok := s.producer.(KafkaAckProducer); ok && enforced {
+			return p.ProduceAck(ctx, s.config.Topic, key, value)
+		}
+		// a producer without acks must block until the brokers have
+		// acknowledged the message when waiting for it, so its message is
+		// persisted once it's produced
+		if err := s.producer.Produce(ctx, s.config.Topic, key, value, enforced); err != nil {
+			return Ack{}, err
+		}
+		return Ack{Persisted: enforced}, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	// return a nil event to indicate the pipeline is complete
+	return nil, nil
+}
+
+// Reopen closes the current producer and creates a new one.
+func (s *kafkaSink) Reopen() error {
+	const op = "event.(kafkaSink).Reopen"
+	s.l.Lock()
+	defer s.l.Unlock()
+	if err := s.producer.Close(); err != nil {
+		return fmt.Errorf("%s: unable to close kafka producer: %w", op, err)
+	}
+	p, err := s.factory(s.config)
+	if err != nil {
+		return fmt.Errorf("%s: unable to create kafka producer: %w", op, err)
+	}
+	s.producer = p
+	return nil
+}
+
+// Close closes the producer.
+func (s *kafkaSink) Close() error {
+	const op = "event.(kafkaSink).Close"
+	s.l.Lock()
+	defer s.l.Unlock()
+	if err := s.producer.Close(); err != nil {
+		return fmt.Errorf("%s: unable to close kafka producer: %w", op, err)
+	}
+	return nil
+}
+
+// checkHealth pings the brokers when the producer supports it.
+func (s *kafkaSink) checkHealth(ctx context.Context) error {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if p, ok := s.producer.(KafkaPinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// Type describes the type of the node as a Sink.
+func (s *kafkaSink) Type() eventlogger.NodeType {
+	return eventlogger.NodeTypeSink
+}
+
+// partitionKey returns the message key for the event based on the configured
+// KafkaPartitionKey.  A nil key lets the producer pick a partition.
+func (s *kafkaSink) partitionKey(e *eventlogger.Event) []byte {
+	switch s.config.PartitionKey {
+	case EventTypePartitionKey:
+		return []byte(e.Type)
+	case CorrelationIdPartitionKey:
+		if id := correlationIdFromPayload(e.Payload); id != "" {
+			return []byte(id)
+		}
+	}
+	return nil
+}
+
+// correlationIdFromPayload returns the correlation id of an event payload or
+// an empty string if it doesn't have one.
+func correlationIdFromPayload(payload interface{}) string {
+	switch p := payload.(type) {
+	case *audit:
+		return p.CorrelationId
+	case audit:
+		return p.CorrelationId
+	case *err:
+		return p.CorrelationId
+	case *sysEvent:
+		return p.CorrelationId
+	case *customEvent:
+		return p.CorrelationId
+	case *spooledPayload:
+		return p.CorrelationId
+	case *gated.EventPayload:
+		id, _ := p.Header[CorrelationIdField].(string)
+		return id
+	case gated.EventPayload:
+		id, _ := p.Header[CorrelationIdField].(string)
+		return id
+	}
+	return ""
+}
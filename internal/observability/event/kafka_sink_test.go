@@ -0,0 +1,340 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/go-hclog"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testKafkaMessage struct {
+	topic      string
+	key        []byte
+	value      []byte
+	waitForAck bool
+}
+
+type testKafkaProducer struct {
+	l        sync.Mutex
+	failures int
+	closed   bool
+	messages []testKafkaMessage
+}
+
+func (p *testKafkaProducer) Produce(_ context.Context, topic string, key, value []byte, waitForAck bool) error {
+	p.l.Lock()
+	defer p.l.Unlock()
+	if p.failures > 0 {
+		p.failures--
+		return fmt.Errorf("transient broker error")
+	}
+	p.messages = append(p.messages, testKafkaMessage{topic: topic, key: key, value: value, waitForAck: waitForAck})
+	return nil
+}
+
+func (p *testKafkaProducer) Close() error {
+	p.l.Lock()
+	defer p.l.Unlock()
+	p.closed = true
+	return nil
+}
+
+func TestKafkaSinkConfig_validate(t *testing.T) {
+	t.Parallel()
+	brokers := []string{"localhost:9092"}
+	tests := []struct {
+		name            string
+		kc              KafkaSinkConfig
+		wantErrIs       error
+		wantErrContains string
+	}{
+		{
+			name:            "missing-brokers",
+			kc:              KafkaSinkConfig{Topic: "events"},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "missing brokers",
+		},
+		{
+			name:            "missing-topic",
+			kc:              KafkaSinkConfig{Brokers: brokers},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "missing topic",
+		},
+		{
+			name:            "invalid-partition-key",
+			kc:              KafkaSinkConfig{Brokers: brokers, Topic: "events", PartitionKey: "invalid"},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "not a valid partition key",
+		},
+		{
+			name:            "invalid-delivery-guarantee",
+			kc:              KafkaSinkConfig{Brokers: brokers, Topic: "events", DeliveryGuarantee: "invalid"},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "not a valid delivery guarantee",
+		},
+		{
+			name:            "invalid-sasl-mechanism",
+			kc:              KafkaSinkConfig{Brokers: brokers, Topic: "events", SASLMechanism: "invalid"},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "not a valid sasl mechanism",
+		},
+		{
+			name:            "sasl-creds-without-mechanism",
+			kc:              KafkaSinkConfig{Brokers: brokers, Topic: "events", SASLUsername: "alice"},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "without a sasl mechanism",
+		},
+		{
+			name:            "missing-sasl-username",
+			kc:              KafkaSinkConfig{Brokers: brokers, Topic: "events", SASLMechanism: "PLAIN"},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "missing sasl username",
+		},
+		{
+			name:            "tls-options-without-tls",
+			kc:              KafkaSinkConfig{Brokers: brokers, Topic: "events", TLSSkipVerify: true},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "without tls enabled",
+		},
+		{
+			name: "valid",
+			kc: KafkaSinkConfig{
+				Brokers:           brokers,
+				Topic:             "events",
+				PartitionKey:      CorrelationIdPartitionKey,
+				DeliveryGuarantee: Enforced,
+				SASLMechanism:     "SCRAM-SHA-512",
+				SASLUsername:      "alice",
+				SASLPassword:      "secret",
+				TLSEnabled:        true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			err := tt.kc.validate()
+			if tt.wantErrIs != nil {
+				require.Error(err)
+				assert.ErrorIs(err, tt.wantErrIs)
+				if tt.wantErrContains != "" {
+					assert.Contains(err.Error(), tt.wantErrContains)
+				}
+				return
+			}
+			assert.NoError(err)
+		})
+	}
+}
+
+func Test_defaultKafkaProducer(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name            string
+		config          KafkaSinkConfig
+		wantAcks        kafka.RequiredAcks
+		wantSASL        string
+		wantTLS         bool
+		wantErrIs       error
+		wantErrContains string
+	}{
+		{
+			name:     "default",
+			config:   KafkaSinkConfig{Brokers: []string{"localhost:9092"}, Topic: "events"},
+			wantAcks: kafka.RequireOne,
+		},
+		{
+			name:     "enforced",
+			config:   KafkaSinkConfig{Brokers: []string{"localhost:9092"}, Topic: "events", DeliveryGuarantee: Enforced},
+			wantAcks: kafka.RequireAll,
+		},
+		{
+			name:     "sasl-plain",
+			config:   KafkaSinkConfig{Brokers: []string{"localhost:9092"}, Topic: "events", SASLMechanism: "PLAIN", SASLUsername: "user", SASLPassword: "password"},
+			wantAcks: kafka.RequireOne,
+			wantSASL: "PLAIN",
+		},
+		{
+			name:     "sasl-scram",
+			config:   KafkaSinkConfig{Brokers: []string{"localhost:9092"}, Topic: "events", SASLMechanism: "SCRAM-SHA-512", SASLUsername: "user", SASLPassword: "password"},
+			wantAcks: kafka.RequireOne,
+			wantSASL: "SCRAM-SHA-512",
+		},
+		{
+			name:     "tls",
+			config:   KafkaSinkConfig{Brokers: []string{"localhost:9092"}, Topic: "events", TLSEnabled: true, TLSSkipVerify: true},
+			wantAcks: kafka.RequireOne,
+			wantTLS:  true,
+		},
+		{
+			name:            "invalid-ca-cert",
+			config:          KafkaSinkConfig{Brokers: []string{"localhost:9092"}, Topic: "events", TLSEnabled: true, TLSCACert: "not a cert"},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "unable to parse tls ca cert",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			got, err := defaultKafkaProducer(&tt.config)
+			if tt.wantErrIs != nil {
+				require.Error(err)
+				assert.ErrorIs(err, tt.wantErrIs)
+				assert.Contains(err.Error(), tt.wantErrContains)
+				return
+			}
+			require.NoError(err)
+			t.Cleanup(func() { _ = got.Close() })
+			p, ok := got.(*kafkaGoProducer)
+			require.True(ok)
+			assert.Equal(tt.wantAcks, p.writer.RequiredAcks)
+			assert.Equal("events", p.topic)
+			if tt.wantSASL != "" {
+				require.NotNil(p.transport.SASL)
+				assert.Equal(tt.wantSASL, p.transport.SASL.Name())
+			} else {
+				assert.Nil(p.transport.SASL)
+			}
+			if tt.wantTLS {
+				require.NotNil(p.transport.TLS)
+				assert.True(p.transport.TLS.InsecureSkipVerify)
+			} else {
+				assert.Nil(p.transport.TLS)
+			}
+		})
+	}
+}
+
+func Test_kafkaSink(t *testing.T) {
+	t.Parallel()
+	testEventer := &Eventer{logger: hclog.NewNullLogger()}
+	testEvent := func() *eventlogger.Event {
+		e := &eventlogger.Event{
+			Type:    eventlogger.EventType(ErrorType),
+			Payload: &err{CorrelationId: "test-correlation-id"},
+		}
+		e.FormattedAs(string(JSONSinkFormat), []byte(`{"test":"event"}`))
+		return e
+	}
+
+	t.Run("default-factory", func(t *testing.T) {
+		s, err := newKafkaSink(testEventer, &KafkaSinkConfig{Brokers: []string{"localhost:9092"}, Topic: "events"}, string(JSONSinkFormat), nil)
+		require.NoError(t, err)
+		assert.IsType(t, &kafkaGoProducer{}, s.producer)
+		assert.NoError(t, s.Close())
+	})
+	t.Run("partition-keys", func(t *testing.T) {
+		tests := []struct {
+			key     KafkaPartitionKey
+			wantKey []byte
+		}{
+			{key: DefaultPartitionKey},
+			{key: NoPartitionKey},
+			{key: CorrelationIdPartitionKey, wantKey: []byte("test-correlation-id")},
+			{key: EventTypePartitionKey, wantKey: []byte(ErrorType)},
+		}
+		for _, tt := range tests {
+			assert, require := assert.New(t), require.New(t)
+			p := &testKafkaProducer{}
+			s, err := newKafkaSink(testEventer, &KafkaSinkConfig{Topic: "events", PartitionKey: tt.key}, string(JSONSinkFormat), func(*KafkaSinkConfig) (KafkaProducer, error) { return p, nil })
+			require.NoError(err)
+			got, err := s.Process(context.Background(), testEvent())
+			require.NoError(err)
+			assert.Nil(got)
+			require.Len(p.messages, 1)
+			assert.Equal("events", p.messages[0].topic)
+			assert.Equal(tt.wantKey, p.messages[0].key)
+			assert.Equal(`{"test":"event"}`, string(p.messages[0].value))
+		}
+	})
+	t.Run("retry-and-ack", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		p := &testKafkaProducer{failures: 2}
+		s, err := newKafkaSink(testEventer, &KafkaSinkConfig{Topic: "events", DeliveryGuarantee: Enforced}, string(JSONSinkFormat), func(*KafkaSinkConfig) (KafkaProducer, error) { return p, nil })
+		require.NoError(err)
+		_, err = s.Process(context.Background(), testEvent())
+		require.NoError(err)
+		require.Len(p.messages, 1)
+		assert.True(p.messages[0].waitForAck)
+	})
+	t.Run("max-retries", func(t *testing.T) {
+		p := &testKafkaProducer{failures: stdRetryCount + 1}
+		s, err := newKafkaSink(testEventer, &KafkaSinkConfig{Topic: "events"}, string(JSONSinkFormat), func(*KafkaSinkConfig) (KafkaProducer, error) { return p, nil })
+		require.NoError(t, err)
+		_, err = s.Process(context.Background(), testEvent())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrMaxRetries)
+	})
+	t.Run("missing-format", func(t *testing.T) {
+		p := &testKafkaProducer{}
+		s, err := newKafkaSink(testEventer, &KafkaSinkConfig{Topic: "events"}, "cef", func(*KafkaSinkConfig) (KafkaProducer, error) { return p, nil })
+		require.NoError(t, err)
+		_, err = s.Process(context.Background(), testEvent())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	})
+	t.Run("reopen", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		var producers []*testKafkaProducer
+		s, err := newKafkaSink(testEventer, &KafkaSinkConfig{Topic: "events"}, string(JSONSinkFormat), func(*KafkaSinkConfig) (KafkaProducer, error) {
+			p := &testKafkaProducer{}
+			producers = append(producers, p)
+			return p, nil
+		})
+		require.NoError(err)
+		require.NoError(s.Reopen())
+		require.Len(producers, 2)
+		assert.True(producers[0].closed)
+		assert.False(producers[1].closed)
+	})
+}
+
+func TestEventer_kafkaSink(t *testing.T) {
+	t.Parallel()
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	c := EventerConfig{
+		Sinks: []SinkConfig{
+			{
+				Name:        "kafka",
+				SinkType:    KafkaSink,
+				EventTypes:  []Type{ErrorType},
+				Format:      JSONSinkFormat,
+				KafkaConfig: &KafkaSinkConfig{Brokers: []string{"localhost:9092"}, Topic: "events", PartitionKey: CorrelationIdPartitionKey},
+			},
+		},
+	}
+	t.Run("default-producer", func(t *testing.T) {
+		// the default producer doesn't connect until it's used, so the
+		// eventer is created without a broker
+		e, err := NewEventer(testLogger, testLock, c)
+		require.NoError(t, err)
+		assert.NoError(t, e.Close(context.Background()))
+	})
+	t.Run("success", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		p := &testKafkaProducer{}
+		e, err := NewEventer(testLogger, testLock, c, WithKafkaProducer(func(*KafkaSinkConfig) (KafkaProducer, error) { return p, nil }))
+		require.NoError(err)
+
+		ctx, err := WithCorrelationId(context.Background(), "test-correlation-id")
+		require.NoError(err)
+		testErr, err := newError("TestEventer_kafkaSink", fmt.Errorf("test error"))
+		require.NoError(err)
+		require.NoError(e.writeError(ctx, testErr))
+
+		require.Len(p.messages, 1)
+		assert.Equal("test-correlation-id", string(p.messages[0].key))
+		assert.Contains(string(p.messages[0].value), "TestEventer_kafkaSink")
+	})
+}
@@ -0,0 +1,56 @@
+package event
+
+import (
+	"context"
+	"fmt"
+)
+
+// Middleware is called with the payload of each event before it's sent to
+// the eventer's sinks, and returns the payload to send in its place: either
+// the payload it was given, which it may have modified, or a new one.
+// Observations are passed as their composed map of fields, and every other
+// event as a pointer to its struct.
+//
+// When a middleware returns an error the event isn't sent.  For the types
+// with an enforced delivery (audit and error events, and the custom types
+// registered WithEnforcedDelivery) the error is returned by the write, while
+// events of the other types are dropped.
+//
+// A middleware is called for every attempt to send an event, so an event
+// which is retried is passed to it again and it must be idempotent.
+type Middleware func(ctx context.Context, t Type, payload interface{}) (interface{}, error)
+
+// Use adds middleware to the eventer's chain.  Middleware is called in the
+// order it's added, and each is passed the payload returned by the previous
+// one.
+func (e *Eventer) Use(m ...Middleware) {
+	// the chain is only read while events are sent, which hold the
+	// closeLock for reading
+	e.closeLock.Lock()
+	defer e.closeLock.Unlock()
+	for _, mw := range m {
+		if mw != nil {
+			e.middleware = append(e.middleware, mw)
+		}
+	}
+}
+
+// applyMiddleware passes the event's payload through the middleware chain.
+// It returns false when a middleware dropped the event, and an error when a
+// middleware rejected an event with an enforced delivery.  The caller must
+// hold the eventer's closeLock.
+func (e *Eventer) applyMiddleware(ctx context.Context, t Type, payload interface{}) (interface{}, bool, error) {
+	const op = "event.(Eventer).applyMiddleware"
+	for _, mw := range e.middleware {
+		var err error
+		payload, err = mw(ctx, t, payload)
+		if err != nil {
+			if enforcedDelivery(t) {
+				return nil, false, fmt.Errorf("%s: %s event rejected by middleware: %w", op, t, err)
+			}
+			e.logger.Debug("event dropped by middleware", "event_type", t, "error", err.Error())
+			return nil, false, nil
+		}
+	}
+	return payload, true, nil
+}
@@ -0,0 +1,94 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventer_Use(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	// tagTenant adds a tenant tag to system events, and tagTenantSuffix
+	// appends to it, so the order of the chain can be checked.
+	tagTenant := func(_ context.Context, _ Type, payload interface{}) (interface{}, error) {
+		if ev, ok := payload.(*sysEvent); ok {
+			ev.Data["tenant"] = "acme"
+		}
+		return payload, nil
+	}
+	tagTenantSuffix := func(_ context.Context, _ Type, payload interface{}) (interface{}, error) {
+		if ev, ok := payload.(*sysEvent); ok {
+			ev.Data["tenant"] = fmt.Sprintf("%s-east", ev.Data["tenant"])
+		}
+		return payload, nil
+	}
+	errRejected := errors.New("rejected")
+	reject := func(_ context.Context, _ Type, payload interface{}) (interface{}, error) {
+		return nil, errRejected
+	}
+
+	t.Run("mutate", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		e, buf := NewTestEventer(t)
+		e.Use(tagTenant, tagTenantSuffix)
+		require.NoError(e.writeSysEvent(ctx, &sysEvent{Id: "sys-id", Op: "TestEventer_Use", Data: map[string]interface{}{"name": "data"}}))
+
+		got := buf.Events(t, SystemType)
+		require.Len(got, 1)
+		data, ok := got[0].Payload["data"].(map[string]interface{})
+		require.True(ok)
+		assert.Equal("acme-east", data["tenant"])
+		assert.Equal("data", data["name"])
+	})
+	t.Run("replace", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		e, buf := NewTestEventer(t)
+		e.Use(func(_ context.Context, _ Type, payload interface{}) (interface{}, error) {
+			ev := *payload.(*sysEvent)
+			ev.Op = "replaced"
+			return &ev, nil
+		})
+		require.NoError(e.writeSysEvent(ctx, &sysEvent{Id: "sys-id", Op: "TestEventer_Use", Data: map[string]interface{}{"name": "data"}}))
+
+		got := buf.Events(t, SystemType)
+		require.Len(got, 1)
+		assert.Equal("replaced", got[0].Payload["op"])
+	})
+	t.Run("abort-enforced", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		e, buf := NewTestEventer(t)
+		calls := 0
+		e.Use(func(ctx context.Context, t Type, payload interface{}) (interface{}, error) {
+			calls++
+			return reject(ctx, t, payload)
+		})
+		testErr, err := newError("TestEventer_Use", fmt.Errorf("test error"))
+		require.NoError(err)
+		err = e.writeError(ctx, testErr)
+		require.Error(err)
+		assert.ErrorIs(err, errRejected)
+		assert.Contains(err.Error(), "rejected by middleware")
+		// a rejection isn't retried
+		assert.Equal(1, calls)
+		assert.Empty(buf.Events(t, ErrorType))
+	})
+	t.Run("drop-best-effort", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		e, buf := NewTestEventer(t)
+		e.Use(reject, tagTenant)
+		require.NoError(e.writeSysEvent(ctx, &sysEvent{Id: "sys-id", Op: "TestEventer_Use", Data: map[string]interface{}{"name": "data"}}))
+		assert.Empty(buf.Events(t, SystemType))
+	})
+	t.Run("nil-middleware", func(t *testing.T) {
+		require := require.New(t)
+		e, buf := NewTestEventer(t)
+		e.Use(nil)
+		require.NoError(e.writeSysEvent(ctx, &sysEvent{Id: "sys-id", Op: "TestEventer_Use", Data: map[string]interface{}{"name": "data"}}))
+		require.Len(buf.Events(t, SystemType), 1)
+	})
+}
@@ -0,0 +1,173 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/go-multierror"
+)
+
+// mirrorQueueSize is the max number of events queued for a mirror.  When it's
+// exceeded, events are dropped for the mirror.
+const mirrorQueueSize = 1024
+
+// mirrorSink is a sink node which writes events to a primary sink and copies
+// them to mirror sinks (see SinkConfig.Mirrors).  Only the primary's result is
+// returned, so it alone determines whether an event was delivered.  Mirrors
+// are written asynchronously, and their failures are logged rather than
+// returned.
+type mirrorSink struct {
+	primary eventlogger.Node
+	mirrors []*mirror
+}
+
+var (
+	_ eventlogger.Node = &mirrorSink{}
+	_ io.Closer        = &mirrorSink{}
+)
+
+// Process writes the event to the primary and queues it for each mirror.
+func (s *mirrorSink) Process(ctx context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	const op = "event.(mirrorSink).Process"
+	if e == nil {
+		return nil, fmt.Errorf("%s: missing event: %w", op, ErrInvalidParameter)
+	}
+	_, err := s.primary.Process(ctx, e)
+	for _, m := range s.mirrors {
+		m.enqueue(e)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	// return a nil event to indicate the pipeline is complete
+	return nil, nil
+}
+
+// Reopen reopens the primary and each mirror.  Only the primary's error is
+// returned.
+func (s *mirrorSink) Reopen() error {
+	for _, m := range s.mirrors {
+		if err := m.node.Reopen(); err != nil {
+			m.eventer.logger.Error("unable to reopen mirror sink", "sink", m.name, "error", err.Error())
+		}
+	}
+	return s.primary.Reopen()
+}
+
+// Close writes the events queued for each mirror and then closes the mirrors
+// and the primary.
+func (s *mirrorSink) Close() error {
+	const op = "event.(mirrorSink).Close"
+	var closeErrors error
+	for _, m := range s.mirrors {
+		if err := m.close(); err != nil {
+			closeErrors = multierror.Append(closeErrors, fmt.Errorf("%s: unable to close mirror %q: %w", op, m.name, err))
+		}
+	}
+	if c, ok := s.primary.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			closeErrors = multierror.Append(closeErrors, fmt.Errorf("%s: %w", op, err))
+		}
+	}
+	return closeErrors
+}
+
+// Type describes the type of the node as a Sink.
+func (s *mirrorSink) Type() eventlogger.NodeType {
+	return eventlogger.NodeTypeSink
+}
+
+// mirror writes the events queued for it to its sink node, retrying failures.
+// Its goroutine is started when the first event is queued.
+type mirror struct {
+	name    string
+	node    eventlogger.Node
+	eventer *Eventer
+
+	l       sync.Mutex
+	queue   chan *eventlogger.Event
+	running bool
+	closed  bool
+	done    chan struct{}
+
+	dropped uint64
+	failed  uint64
+}
+
+func newMirror(e *Eventer, name string, node eventlogger.Node) (*mirror, error) {
+	const op = "event.newMirror"
+	if e == nil {
+		return nil, fmt.Errorf("%s: missing eventer: %w", op, ErrInvalidParameter)
+	}
+	if node == nil {
+		return nil, fmt.Errorf("%s: missing node: %w", op, ErrInvalidParameter)
+	}
+	return &mirror{
+		name:    name,
+		node:    node,
+		eventer: e,
+		queue:   make(chan *eventlogger.Event, mirrorQueueSize),
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// enqueue queues the event for the mirror without blocking.  The event is
+// dropped when the queue is full or the mirror is closed.
+func (m *mirror) enqueue(e *eventlogger.Event) {
+	m.l.Lock()
+	defer m.l.Unlock()
+	if m.closed {
+		atomic.AddUint64(&m.dropped, 1)
+		return
+	}
+	if !m.running {
+		m.running = true
+		go m.run()
+	}
+	select {
+	case m.queue <- e:
+	default:
+		atomic.AddUint64(&m.dropped, 1)
+		m.eventer.logger.Warn("mirror sink queue is full, dropping event", "sink", m.name)
+	}
+}
+
+func (m *mirror) run() {
+	defer close(m.done)
+	ctx := context.Background()
+	for e := range m.queue {
+		err := m.eventer.retrySend(ctx, stdRetryCount, m.eventer.retryBackoff(), func() (eventlogger.Status, error) {
+			_, err := m.node.Process(ctx, e)
+			return eventlogger.Status{}, err
+		})
+		if err != nil {
+			atomic.AddUint64(&m.failed, 1)
+			m.eventer.logger.Error("unable to write event to mirror sink", "sink", m.name, "error", err.Error())
+		}
+	}
+}
+
+// close waits for the queued events to be written and then closes the
+// mirror's node.
+func (m *mirror) close() error {
+	m.l.Lock()
+	if m.closed {
+		m.l.Unlock()
+		return nil
+	}
+	m.closed = true
+	close(m.queue)
+	running := m.running
+	m.l.Unlock()
+	if running {
+		<-m.done
+	}
+	if c, ok := m.node.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
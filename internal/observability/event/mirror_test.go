@@ -0,0 +1,193 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testMirrorNode is a sink node which records the events it processes, or
+// fails when failWith is set.
+type testMirrorNode struct {
+	l         sync.Mutex
+	processed []*eventlogger.Event
+	failWith  error
+	closed    bool
+}
+
+func (n *testMirrorNode) Process(_ context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	n.l.Lock()
+	defer n.l.Unlock()
+	if n.failWith != nil {
+		return nil, n.failWith
+	}
+	n.processed = append(n.processed, e)
+	return nil, nil
+}
+
+func (n *testMirrorNode) Reopen() error { return nil }
+
+func (n *testMirrorNode) Type() eventlogger.NodeType { return eventlogger.NodeTypeSink }
+
+func (n *testMirrorNode) Close() error {
+	n.l.Lock()
+	defer n.l.Unlock()
+	n.closed = true
+	return nil
+}
+
+func Test_mirrorSink(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testErr := errors.New("test error")
+	e := &Eventer{logger: hclog.NewNullLogger()}
+
+	t.Run("failing-mirror", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		primary := &testMirrorNode{}
+		failing := &testMirrorNode{failWith: testErr}
+		ok := &testMirrorNode{}
+		failingMirror, err := newMirror(e, "failing", failing)
+		require.NoError(err)
+		okMirror, err := newMirror(e, "ok", ok)
+		require.NoError(err)
+		s := &mirrorSink{primary: primary, mirrors: []*mirror{failingMirror, okMirror}}
+
+		testEvent := &eventlogger.Event{Type: "test"}
+		got, err := s.Process(ctx, testEvent)
+		require.NoError(err)
+		assert.Nil(got)
+		assert.Equal([]*eventlogger.Event{testEvent}, primary.processed)
+
+		// closing waits for the queued events to be written
+		require.NoError(s.Close())
+		assert.Equal(uint64(1), failingMirror.failed)
+		assert.Equal([]*eventlogger.Event{testEvent}, ok.processed)
+		assert.True(primary.closed)
+		assert.True(failing.closed)
+		assert.True(ok.closed)
+
+		// events are dropped for closed mirrors
+		_, err = s.Process(ctx, testEvent)
+		require.NoError(err)
+		assert.Equal(uint64(1), okMirror.dropped)
+	})
+	t.Run("failing-primary", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		primary := &testMirrorNode{failWith: testErr}
+		ok := &testMirrorNode{}
+		okMirror, err := newMirror(e, "ok", ok)
+		require.NoError(err)
+		s := &mirrorSink{primary: primary, mirrors: []*mirror{okMirror}}
+
+		testEvent := &eventlogger.Event{Type: "test"}
+		_, err = s.Process(ctx, testEvent)
+		require.Error(err)
+		assert.ErrorIs(err, testErr)
+		require.NoError(s.Close())
+		assert.Equal([]*eventlogger.Event{testEvent}, ok.processed)
+	})
+	t.Run("missing-event", func(t *testing.T) {
+		s := &mirrorSink{primary: &testMirrorNode{}}
+		_, err := s.Process(ctx, nil)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	})
+}
+
+func TestEventer_Mirrors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	tmpFile, err := ioutil.TempFile("./", "tmp-mirror-TestEventer_Mirrors")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.Remove(tmpFile.Name()) })
+
+	primary := SinkConfig{
+		Name:       "primary",
+		SinkType:   FileSink,
+		EventTypes: []Type{AuditType, ErrorType},
+		Format:     JSONSinkFormat,
+		Path:       "./",
+		FileName:   tmpFile.Name(),
+		Mirrors: []SinkConfig{
+			{
+				Name:     "mirror",
+				SinkType: StderrSink,
+			},
+		},
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			name            string
+			mirror          SinkConfig
+			wantErrIs       error
+			wantErrContains string
+		}{
+			{
+				name:            "event-types",
+				mirror:          SinkConfig{Name: "mirror", SinkType: StderrSink, EventTypes: []Type{AuditType}},
+				wantErrIs:       ErrInvalidParameter,
+				wantErrContains: "must not set event types",
+			},
+			{
+				name:            "filters",
+				mirror:          SinkConfig{Name: "mirror", SinkType: StderrSink, DenyFilters: []string{`"/payload/id" == "id"`}},
+				wantErrIs:       ErrInvalidParameter,
+				wantErrContains: "must not set filters",
+			},
+			{
+				name:            "same-target-as-primary",
+				mirror:          SinkConfig{Name: "mirror", SinkType: FileSink, Path: "./", FileName: tmpFile.Name()},
+				wantErrIs:       ErrDuplicateSink,
+				wantErrContains: `sinks "primary" and "mirror" have the same output target`,
+			},
+			{
+				name:            "invalid-mirror",
+				mirror:          SinkConfig{Name: "mirror", SinkType: FileSink},
+				wantErrIs:       ErrInvalidParameter,
+				wantErrContains: "mirror 0 is invalid",
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert, require := assert.New(t), require.New(t)
+				c := primary
+				c.Mirrors = []SinkConfig{tt.mirror}
+				_, err := NewEventer(testLogger, testLock, EventerConfig{AuditEnabled: true, Sinks: []SinkConfig{c}})
+				require.Error(err)
+				assert.ErrorIs(err, tt.wantErrIs)
+				assert.Contains(err.Error(), tt.wantErrContains)
+			})
+		}
+	})
+
+	var mirrored bytes.Buffer
+	e, err := NewEventer(testLogger, testLock, EventerConfig{AuditEnabled: true, Sinks: []SinkConfig{primary}}, WithStderrWriter(&mirrored))
+	require.NoError(t, err)
+	testAudit, err := newAudit("TestEventer_Mirrors", WithId("audit-id"), WithFlush())
+	require.NoError(t, err)
+	require.NoError(t, e.writeAudit(ctx, testAudit))
+
+	b, err := ioutil.ReadFile(tmpFile.Name())
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"id":"audit-id"`)
+
+	// the mirror's queue is written before the eventer is closed
+	require.NoError(t, e.Close(ctx))
+	assert.Equal(t, string(b), mirrored.String())
+}
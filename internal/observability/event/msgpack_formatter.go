@@ -0,0 +1,85 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/go-msgpack/codec"
+)
+
+// msgpackHandle is the codec handle used to encode (and decode) msgpack
+// formatted events.  Maps are decoded with string keys.
+var msgpackHandle = func() *codec.MsgpackHandle {
+	h := &codec.MsgpackHandle{
+		RawToString: true,
+		WriteExt:    true,
+	}
+	h.MapType = reflect.TypeOf(map[string]interface{}(nil))
+	return h
+}()
+
+// msgpackFormatter is a formatter node which formats an event as msgpack,
+// which is more compact than JSON.  Each event is a msgpack map with the same
+// fields as the event's JSON, so a file of msgpack formatted events can be
+// read with a msgpack stream decoder.
+type msgpackFormatter struct{}
+
+var _ eventlogger.Node = &msgpackFormatter{}
+
+// Process formats the event as msgpack and stores the formatted data in the
+// event's Formatted field with a key of "msgpack"
+func (f *msgpackFormatter) Process(_ context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	const op = "event.(msgpackFormatter).Process"
+	if e == nil {
+		return nil, fmt.Errorf("%s: missing event: %w", op, ErrInvalidParameter)
+	}
+	m, err := eventAsMap(e)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	var buf bytes.Buffer
+	if err := codec.NewEncoder(&buf, msgpackHandle).Encode(m); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	e.FormattedAs(string(MsgpackSinkFormat), buf.Bytes())
+	return e, nil
+}
+
+// Reopen is a no op
+func (f *msgpackFormatter) Reopen() error {
+	return nil
+}
+
+// Type describes the type of the node as a Formatter.
+func (f *msgpackFormatter) Type() eventlogger.NodeType {
+	return eventlogger.NodeTypeFormatter
+}
+
+// eventAsMap returns the event as a map with the same fields as its JSON.
+// Payloads only define json struct tags (and may contain proto messages), so
+// the event is round tripped through JSON rather than encoded directly.
+func eventAsMap(e *eventlogger.Event) (map[string]interface{}, error) {
+	const op = "event.eventAsMap"
+	b, err := json.Marshal(struct {
+		CreatedAt time.Time             `json:"created_at"`
+		EventType eventlogger.EventType `json:"event_type"`
+		Payload   interface{}           `json:"payload"`
+	}{
+		e.CreatedAt,
+		e.Type,
+		e.Payload,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return m, nil
+}
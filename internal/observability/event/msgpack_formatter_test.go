@@ -0,0 +1,151 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-msgpack/codec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_msgpackFormatter_Process(t *testing.T) {
+	t.Parallel()
+	now := time.Unix(1625000000, 0).UTC()
+	tests := []struct {
+		name      string
+		event     *eventlogger.Event
+		want      map[string]interface{}
+		wantErrIs error
+	}{
+		{
+			name:      "missing-event",
+			wantErrIs: ErrInvalidParameter,
+		},
+		{
+			name: "audit",
+			event: &eventlogger.Event{
+				Type:      eventlogger.EventType(AuditType),
+				CreatedAt: now,
+				Payload: &audit{
+					Id:       "audit-id",
+					Type:     string(ApiRequest),
+					Request:  &Request{Operation: "POST", Endpoint: "/v1/scopes"},
+					Response: &Response{StatusCode: 200},
+				},
+			},
+			want: map[string]interface{}{
+				"created_at": now.Format(time.RFC3339),
+				"event_type": string(AuditType),
+				"payload": map[string]interface{}{
+					"id":              "audit-id",
+					"version":         "",
+					"type":            string(ApiRequest),
+					"timestamp":       "0001-01-01T00:00:00Z",
+					"serialized_hmac": "",
+					"request":         map[string]interface{}{"operation": "POST", "endpoint": "/v1/scopes", "details": nil},
+					"response":        map[string]interface{}{"status_code": float64(200)},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			got, err := (&msgpackFormatter{}).Process(context.Background(), tt.event)
+			if tt.wantErrIs != nil {
+				require.Error(err)
+				assert.ErrorIs(err, tt.wantErrIs)
+				return
+			}
+			require.NoError(err)
+			b, ok := got.Format(string(MsgpackSinkFormat))
+			require.True(ok)
+			var m map[string]interface{}
+			require.NoError(codec.NewDecoderBytes(b, msgpackHandle).Decode(&m))
+			assert.Equal(tt.want, m)
+		})
+	}
+}
+
+func TestEventer_msgpack(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	msgpackFile, err := ioutil.TempFile("./", "tmp-msgpack-TestEventer_msgpack")
+	require.NoError(t, err)
+	jsonFile, err := ioutil.TempFile("./", "tmp-json-TestEventer_msgpack")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		os.Remove(msgpackFile.Name())
+		os.Remove(jsonFile.Name())
+	})
+	c := EventerConfig{
+		AuditEnabled:        true,
+		ObservationsEnabled: true,
+		Sinks: []SinkConfig{
+			{
+				Name:       "audit-json",
+				SinkType:   FileSink,
+				EventTypes: []Type{AuditType, ErrorType},
+				Format:     JSONSinkFormat,
+				Path:       "./",
+				FileName:   jsonFile.Name(),
+			},
+			{
+				Name:       "observation-msgpack",
+				SinkType:   FileSink,
+				EventTypes: []Type{ObservationType},
+				Format:     MsgpackSinkFormat,
+				Path:       "./",
+				FileName:   msgpackFile.Name(),
+			},
+		},
+	}
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	e, err := NewEventer(testLogger, testLock, c)
+	require.NoError(t, err)
+
+	var wantIds []string
+	for i := 0; i < 2; i++ {
+		testObservation, err := newObservation("TestEventer_msgpack", WithDetails(map[string]interface{}{"count": i}), WithFlush())
+		require.NoError(t, err)
+		require.NoError(t, e.writeObservation(ctx, testObservation))
+		wantIds = append(wantIds, testObservation.ID)
+	}
+	testAudit, err := newAudit("TestEventer_msgpack", WithFlush())
+	require.NoError(t, err)
+	require.NoError(t, e.writeAudit(ctx, testAudit))
+
+	b, err := ioutil.ReadFile(jsonFile.Name())
+	require.NoError(t, err)
+	assert.Contains(t, string(b), fmt.Sprintf(`"id":"%s"`, testAudit.Id))
+
+	b, err = ioutil.ReadFile(msgpackFile.Name())
+	require.NoError(t, err)
+	dec := codec.NewDecoder(bytes.NewReader(b), msgpackHandle)
+	var gotIds []string
+	for {
+		var m map[string]interface{}
+		err := dec.Decode(&m)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		assert.Equal(t, string(ObservationType), m["event_type"])
+		gotIds = append(gotIds, m["payload"].(map[string]interface{})["id"].(string))
+	}
+	assert.Equal(t, wantIds, gotIds)
+}
@@ -0,0 +1,65 @@
+package event
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/eventlogger"
+)
+
+// Ack is a remote's acknowledgement of an event sent to it by a network sink.
+// A network sink's transport returns an Ack once the remote has responded to
+// the event at the application level, rather than when its bytes were
+// written to the connection: for example, when Kafka has acknowledged the
+// message with acks=all, or when an HTTP endpoint has responded with a 2xx
+// and the token of its body.
+type Ack struct {
+	// Persisted is true when the remote has durably stored the event, so it
+	// won't be lost if the remote fails.  An event which was only accepted
+	// by the remote isn't persisted.
+	Persisted bool
+
+	// Token optionally identifies the persisted event at the remote, like a
+	// Kafka offset or the token of an HTTP response's body.
+	Token string
+}
+
+// verify returns ErrNotAcknowledged unless the ack is for a persisted event.
+func (a Ack) verify() error {
+	const op = "event.(Ack).verify"
+	if !a.Persisted {
+		return fmt.Errorf("%s: %w", op, ErrNotAcknowledged)
+	}
+	return nil
+}
+
+// ackSendHandler sends an event to a network sink's remote and returns its
+// ack.
+type ackSendHandler func() (Ack, error)
+
+// sendAcked sends an event to a network sink's remote using handler, retrying
+// failed sends.  When enforced (the sink's DeliveryGuarantee is Enforced) a
+// send only succeeds once the remote acknowledges that it persisted the event,
+// and a send which the remote didn't acknowledge is retried like any other
+// failed send.  Otherwise the ack is ignored, so a best effort send succeeds
+// once the remote has accepted the event.
+func (e *Eventer) sendAcked(ctx context.Context, enforced bool, handler ackSendHandler) error {
+	const op = "event.(Eventer).sendAcked"
+	if handler == nil {
+		return fmt.Errorf("%s: missing handler: %w", op, ErrInvalidParameter)
+	}
+	err := e.retrySend(ctx, stdRetryCount, e.retryBackoff(), func() (eventlogger.Status, error) {
+		ack, err := handler()
+		if err != nil {
+			return eventlogger.Status{}, err
+		}
+		if enforced {
+			return eventlogger.Status{}, ack.verify()
+		}
+		return eventlogger.Status{}, nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
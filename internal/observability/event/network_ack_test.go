@@ -0,0 +1,153 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testAckProducer is a fake network transport which acknowledges the messages
+// it produces as persisted, except for the first unacked of them which it
+// only accepts.
+type testAckProducer struct {
+	testKafkaProducer
+	unacked  int
+	acks     int
+	produced int
+}
+
+var _ KafkaAckProducer = &testAckProducer{}
+
+func (p *testAckProducer) ProduceAck(_ context.Context, topic string, key, value []byte) (Ack, error) {
+	p.l.Lock()
+	defer p.l.Unlock()
+	p.produced++
+	if p.unacked > 0 {
+		p.unacked--
+		return Ack{}, nil
+	}
+	p.acks++
+	p.messages = append(p.messages, testKafkaMessage{topic: topic, key: key, value: value, waitForAck: true})
+	return Ack{Persisted: true, Token: fmt.Sprintf("offset-%d", p.acks)}, nil
+}
+
+func TestEventer_sendAcked(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testEventer := &Eventer{logger: hclog.NewNullLogger(), noBackoffJitter: true}
+
+	t.Run("missing-handler", func(t *testing.T) {
+		err := testEventer.sendAcked(ctx, true, nil)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	})
+	t.Run("enforced-acked", func(t *testing.T) {
+		var calls int
+		err := testEventer.sendAcked(ctx, true, func() (Ack, error) {
+			calls++
+			return Ack{Persisted: true}, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, calls)
+	})
+	t.Run("enforced-retries-until-acked", func(t *testing.T) {
+		var calls int
+		err := testEventer.sendAcked(ctx, true, func() (Ack, error) {
+			calls++
+			return Ack{Persisted: calls == 3}, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, calls)
+	})
+	t.Run("enforced-never-acked", func(t *testing.T) {
+		var calls int
+		err := testEventer.sendAcked(ctx, true, func() (Ack, error) {
+			calls++
+			return Ack{}, nil
+		})
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrNotAcknowledged)
+		assert.ErrorIs(t, err, ErrMaxRetries)
+		assert.Equal(t, stdRetryCount+1, calls)
+	})
+	t.Run("best-effort-ignores-ack", func(t *testing.T) {
+		var calls int
+		err := testEventer.sendAcked(ctx, false, func() (Ack, error) {
+			calls++
+			return Ack{}, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, calls)
+	})
+	t.Run("send-error", func(t *testing.T) {
+		var calls int
+		err := testEventer.sendAcked(ctx, true, func() (Ack, error) {
+			calls++
+			if calls == 1 {
+				return Ack{}, fmt.Errorf("connection reset")
+			}
+			return Ack{Persisted: true}, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+}
+
+func Test_kafkaSinkAcks(t *testing.T) {
+	t.Parallel()
+	testEventer := &Eventer{logger: hclog.NewNullLogger(), noBackoffJitter: true}
+	testEvent := func() *eventlogger.Event {
+		e := &eventlogger.Event{
+			Type:    eventlogger.EventType(AuditType),
+			Payload: &audit{Id: "test-id"},
+		}
+		e.FormattedAs(string(JSONSinkFormat), []byte(`{"test":"event"}`))
+		return e
+	}
+	newSink := func(t *testing.T, p KafkaProducer, guarantee DeliveryGuarantee) *kafkaSink {
+		t.Helper()
+		s, err := newKafkaSink(testEventer, &KafkaSinkConfig{Topic: "events", DeliveryGuarantee: guarantee}, string(JSONSinkFormat), func(*KafkaSinkConfig) (KafkaProducer, error) { return p, nil })
+		require.NoError(t, err)
+		return s
+	}
+
+	t.Run("enforced-acks", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		p := &testAckProducer{}
+		_, err := newSink(t, p, Enforced).Process(context.Background(), testEvent())
+		require.NoError(err)
+		assert.Equal(1, p.produced)
+		require.Len(p.messages, 1)
+		assert.True(p.messages[0].waitForAck)
+	})
+	t.Run("enforced-retries-unacked", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		p := &testAckProducer{unacked: 2}
+		_, err := newSink(t, p, Enforced).Process(context.Background(), testEvent())
+		require.NoError(err)
+		assert.Equal(3, p.produced)
+		assert.Len(p.messages, 1)
+	})
+	t.Run("enforced-does-not-ack", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		p := &testAckProducer{unacked: stdRetryCount + 1}
+		_, err := newSink(t, p, Enforced).Process(context.Background(), testEvent())
+		require.Error(err)
+		assert.ErrorIs(err, ErrNotAcknowledged)
+		assert.Empty(p.messages)
+	})
+	t.Run("best-effort-does-not-wait-for-ack", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		p := &testAckProducer{unacked: stdRetryCount + 1}
+		_, err := newSink(t, p, BestEffort).Process(context.Background(), testEvent())
+		require.NoError(err)
+		assert.Equal(0, p.produced)
+		require.Len(p.messages, 1)
+		assert.False(p.messages[0].waitForAck)
+	})
+}
@@ -0,0 +1,41 @@
+package event
+
+import (
+	"fmt"
+)
+
+const (
+	DefaultObservationLevel ObservationLevel = ""      // DefaultObservationLevel will be InfoLevel
+	DebugLevel              ObservationLevel = "debug" // DebugLevel is for routine observations which are only useful when debugging
+	InfoLevel               ObservationLevel = "info"  // InfoLevel is for observations which are normally emitted
+	WarnLevel               ObservationLevel = "warn"  // WarnLevel is for important observations
+)
+
+type ObservationLevel string // ObservationLevel defines the verbosity level of an observation
+
+func (l ObservationLevel) validate() error {
+	const op = "event.(ObservationLevel).validate"
+	switch l {
+	case DefaultObservationLevel, DebugLevel, InfoLevel, WarnLevel:
+		return nil
+	default:
+		return fmt.Errorf("%s: %s is not a valid observation level: %w", op, l, ErrInvalidParameter)
+	}
+}
+
+// rank orders the levels from the least to the most important.
+func (l ObservationLevel) rank() int {
+	switch l {
+	case DebugLevel:
+		return 0
+	case WarnLevel:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// atLeast returns true when l is at least as important as min.
+func (l ObservationLevel) atLeast(min ObservationLevel) bool {
+	return l.rank() >= min.rank()
+}
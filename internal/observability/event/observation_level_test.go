@@ -0,0 +1,138 @@
+package event
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObservationLevel_validate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name            string
+		l               ObservationLevel
+		wantErrIs       error
+		wantErrContains string
+	}{
+		{
+			name:            "invalid",
+			l:               "invalid",
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "not a valid observation level",
+		},
+		{name: "Default", l: DefaultObservationLevel},
+		{name: "Debug", l: DebugLevel},
+		{name: "Info", l: InfoLevel},
+		{name: "Warn", l: WarnLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			err := tt.l.validate()
+			if tt.wantErrIs != nil {
+				require.Error(err)
+				assert.ErrorIs(err, tt.wantErrIs)
+				assert.Contains(err.Error(), tt.wantErrContains)
+				return
+			}
+			require.NoError(err)
+		})
+	}
+}
+
+func TestEventer_ObservationLevel(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	levels := []ObservationLevel{DebugLevel, DefaultObservationLevel, InfoLevel, WarnLevel}
+
+	tests := []struct {
+		name     string
+		minLevel ObservationLevel
+		want     map[ObservationLevel]bool
+	}{
+		{
+			name:     "default",
+			minLevel: DefaultObservationLevel,
+			want:     map[ObservationLevel]bool{DefaultObservationLevel: true, InfoLevel: true, WarnLevel: true},
+		},
+		{
+			name:     "debug",
+			minLevel: DebugLevel,
+			want:     map[ObservationLevel]bool{DebugLevel: true, DefaultObservationLevel: true, InfoLevel: true, WarnLevel: true},
+		},
+		{
+			name:     "info",
+			minLevel: InfoLevel,
+			want:     map[ObservationLevel]bool{DefaultObservationLevel: true, InfoLevel: true, WarnLevel: true},
+		},
+		{
+			name:     "warn",
+			minLevel: WarnLevel,
+			want:     map[ObservationLevel]bool{WarnLevel: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			tmpFile, err := ioutil.TempFile("./", "tmp-observation-level-TestEventer_ObservationLevel")
+			require.NoError(err)
+			t.Cleanup(func() { os.Remove(tmpFile.Name()) })
+			c := EventerConfig{
+				ObservationsEnabled: true,
+				ObservationLevel:    tt.minLevel,
+				Sinks: []SinkConfig{
+					{
+						Name:       "every-type-file-sink",
+						SinkType:   FileSink,
+						EventTypes: []Type{EveryType},
+						Format:     JSONSinkFormat,
+						Path:       "./",
+						FileName:   tmpFile.Name(),
+					},
+				},
+			}
+			e, err := NewEventer(testLogger, testLock, c)
+			require.NoError(err)
+
+			for _, l := range levels {
+				id := "obs-" + string(l)
+				testObservation, err := newObservation("TestEventer_ObservationLevel", WithId(id), WithObservationLevel(l), WithFlush())
+				require.NoError(err)
+				require.NoError(e.writeObservation(ctx, testObservation))
+			}
+
+			b, err := ioutil.ReadFile(tmpFile.Name())
+			require.NoError(err)
+			for _, l := range levels {
+				id := "obs-" + string(l)
+				assert.Equalf(tt.want[l], strings.Contains(string(b), `"id":"`+id+`"`), "level %q", l)
+			}
+			if tt.want[WarnLevel] {
+				assert.Contains(string(b), `"level":"warn"`)
+			}
+		})
+	}
+	t.Run("invalid-min-level", func(t *testing.T) {
+		c := EventerConfig{ObservationLevel: "invalid"}
+		_, err := NewEventer(testLogger, testLock, c)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	})
+	t.Run("invalid-level", func(t *testing.T) {
+		_, err := newObservation("TestEventer_ObservationLevel", WithObservationLevel("invalid"))
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	})
+}
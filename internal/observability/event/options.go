@@ -1,7 +1,11 @@
 package event
 
 import (
+	"io"
+	"sync"
 	"time"
+
+	wrapping "github.com/hashicorp/go-kms-wrapping"
 )
 
 // getOpts - iterate the inbound Options and return a struct.
@@ -20,17 +24,47 @@ type Option func(*options)
 
 // options = how options are represented
 type options struct {
-	withId            string
-	withDetails       map[string]interface{}
-	withHeader        map[string]interface{}
-	withFlush         bool
-	withRequestInfo   *RequestInfo
-	withNow           time.Time
-	withRequest       *Request
-	withResponse      *Response
-	withAuth          *Auth
-	withEventer       *Eventer
-	withEventerConfig *EventerConfig
+	withId                    string
+	withDetails               map[string]interface{}
+	withHeader                map[string]interface{}
+	withFlush                 bool
+	withRequestInfo           *RequestInfo
+	withNow                   time.Time
+	withRequest               *Request
+	withResponse              *Response
+	withAuth                  *Auth
+	withEventer               *Eventer
+	withEventerConfig         *EventerConfig
+	withSchemaVersion         string
+	withKafkaProducer         KafkaProducerFactory
+	withCloudWatchClient      CloudWatchClientFactory
+	withWrapper               wrapping.Wrapper
+	withEncryptedObservations bool
+	withWarningsHook          WarningsHook
+	withWarningsAsEvents      bool
+	withDefaultFields         map[string]string
+	withMaxEventBytes         int
+	withDedupWindow           time.Duration
+	withStderrWriter          io.Writer
+	withUnserializedStderr    bool
+	withWriterGroups          map[string]serializedWriter
+	withStrictSerialization   bool
+	withRateLimits            map[Type]rateLimit
+	withObservationLevel      ObservationLevel
+	withNoBackoffJitter       bool
+	withErrorStackTraces      bool
+	withErrorStackDepth       int
+	withCircuitBreaker        *circuitBreaker
+	withMaxPausedObservations int
+	withErrorCoalesceInterval time.Duration
+	withSendTimeout           time.Duration
+	withSequenceNumbers       bool
+	withNoDefaultSink         bool
+	withDedupeFileSinks       bool
+	withEnforcedDelivery      bool
+	withPathConfirmation      bool
+	withSensitiveHeaders      []string
+	withReplaceSensitive      bool
 
 	withBroker          broker // test only option
 	withAuditSink       bool   // test only option
@@ -118,3 +152,304 @@ func WithEventerConfig(c *EventerConfig) Option {
 		o.withEventerConfig = c
 	}
 }
+
+// WithSchemaVersion allows an optional schema version which will be stamped
+// onto every event written, rather than the current SchemaVersion.  It's
+// intended to pin the version when testing.
+func WithSchemaVersion(v string) Option {
+	return func(o *options) {
+		o.withSchemaVersion = v
+	}
+}
+
+// WithKafkaProducer allows an optional factory used to create the producers
+// for every KafkaSink.  By default, producers connect to the config's brokers
+// with its SASL and TLS settings.
+func WithKafkaProducer(f KafkaProducerFactory) Option {
+	return func(o *options) {
+		o.withKafkaProducer = f
+	}
+}
+
+// WithCloudWatchClient allows an optional factory used to create the clients
+// for every CloudWatchSink.  By default, clients use the AWS SDK's default
+// credential chain, which uses the instance role when no other credentials are
+// configured in the environment.
+func WithCloudWatchClient(f CloudWatchClientFactory) Option {
+	return func(o *options) {
+		o.withCloudWatchClient = f
+	}
+}
+
+// WithWrapper allows an optional wrapper which is used to encrypt audit events
+// before they're written to file sinks.  Use DecryptAuditFile to read them.
+func WithWrapper(w wrapping.Wrapper) Option {
+	return func(o *options) {
+		o.withWrapper = w
+	}
+}
+
+// WithWarningsHook allows an optional hook which is called with the warnings
+// returned when an event isn't delivered to every sink (for example, to
+// increment a metric).
+func WithWarningsHook(h WarningsHook) Option {
+	return func(o *options) {
+		o.withWarningsHook = h
+	}
+}
+
+// WithWarningsAsEvents allows an optional flag to send the warnings returned
+// when an event wasn't delivered to every sink as a system event, so partial
+// delivery is visible in the event stream.  They're only sent when system
+// events are enabled, and the warnings of system events are only logged.
+func WithWarningsAsEvents(enabled bool) Option {
+	return func(o *options) {
+		o.withWarningsAsEvents = enabled
+	}
+}
+
+// WithDefaultFields allows an optional set of static fields (node id,
+// environment, etc) which are added to the header of every event.  The host's
+// name is added as the HostnameField unless it's provided.  Fields already in
+// an event's header are never overwritten.
+func WithDefaultFields(f map[string]string) Option {
+	return func(o *options) {
+		o.withDefaultFields = f
+	}
+}
+
+// WithMaxEventBytes allows an optional limit on the serialized size of audit
+// and observation events.  Audit events which exceed it are rejected, while
+// observations are truncated: their details are dropped and the
+// TruncatedField and OriginalSizeField are added to their header.
+func WithMaxEventBytes(n int) Option {
+	return func(o *options) {
+		o.withMaxEventBytes = n
+	}
+}
+
+// WithDedupWindow allows an optional window in which observation and system
+// events with the same id (see IdField) are only delivered to each sink once.
+// Audit events are never deduped.
+func WithDedupWindow(d time.Duration) Option {
+	return func(o *options) {
+		o.withDedupWindow = d
+	}
+}
+
+// WithStderrWriter allows an optional writer which StderrSinks write to rather
+// than os.Stderr (for example, to capture events in tests).  Writes are still
+// guarded by the eventer's serialization lock.
+func WithStderrWriter(w io.Writer) Option {
+	return func(o *options) {
+		o.withStderrWriter = w
+	}
+}
+
+// WithUnserializedStderr allows an optional flag for StderrSinks to write to
+// stderr (or the WithStderrWriter writer) without holding the eventer's
+// serialization lock.  It removes the lock's overhead under a heavy load, at
+// the cost of the output of concurrent writes being interleaved, so it's
+// unsafe when anything reads stderr as a stream of events and is only
+// intended for development and tests.  Writer groups are unaffected.
+func WithUnserializedStderr() Option {
+	return func(o *options) {
+		o.withUnserializedStderr = true
+	}
+}
+
+// WithWriterGroup allows an optional writer group, which the StderrSinks
+// with its name as their WriterGroup write to.  Their writes to w are
+// serialized by l rather than the eventer's serialization lock, so they don't
+// contend with the writes of other groups.  Groups which write to the same
+// descriptor must share a lock, otherwise their output may be interleaved.
+// It may be provided once for each group.
+func WithWriterGroup(name string, w io.Writer, l *sync.Mutex) Option {
+	return func(o *options) {
+		if o.withWriterGroups == nil {
+			o.withWriterGroups = map[string]serializedWriter{}
+		}
+		o.withWriterGroups[name] = serializedWriter{w: w, l: l}
+	}
+}
+
+// WithStrictSerialization allows an optional flag to marshal each event
+// before it's sent, so an event which can't be serialized never reaches a
+// sink.  Audit and error events which fail are returned as an error, while
+// observation and system events are logged and dropped.
+func WithStrictSerialization() Option {
+	return func(o *options) {
+		o.withStrictSerialization = true
+	}
+}
+
+// WithEncryptedObservations allows an optional flag to encrypt observation
+// events written to file sinks as well.  It requires WithWrapper.
+func WithEncryptedObservations() Option {
+	return func(o *options) {
+		o.withEncryptedObservations = true
+	}
+}
+
+// WithRateLimit allows an optional rate limit for events of type t, which is
+// applied to each of the type's sinks.  A sink receives at most
+// eventsPerSecond events, with bursts of up to burst events.  When the limit
+// is exceeded, audit and error events wait to be delivered, while observation
+// and system events are dropped.  It may be provided once for each type.
+func WithRateLimit(t Type, eventsPerSecond float64, burst int) Option {
+	return func(o *options) {
+		if o.withRateLimits == nil {
+			o.withRateLimits = map[Type]rateLimit{}
+		}
+		o.withRateLimits[t] = rateLimit{eventsPerSecond: eventsPerSecond, burst: burst}
+	}
+}
+
+// WithObservationLevel allows an optional verbosity level for an observation.
+// Observations default to InfoLevel.
+func WithObservationLevel(l ObservationLevel) Option {
+	return func(o *options) {
+		o.withObservationLevel = l
+	}
+}
+
+// WithNoBackoffJitter allows an optional flag to disable the jitter of the
+// backoff between attempts to send an event, which makes the backoff
+// deterministic.  It's intended for tests.
+func WithNoBackoffJitter() Option {
+	return func(o *options) {
+		o.withNoBackoffJitter = true
+	}
+}
+
+// WithErrorStackTraces allows an optional flag to attach the stack of the
+// goroutine writing an error event to the event (see the error event's stack
+// field).  Runtime frames are stripped and the stack is trimmed to maxDepth
+// frames, which defaults to 32 when it's zero.  It's off by default, since
+// capturing the stack is relatively expensive.
+func WithErrorStackTraces(maxDepth int) Option {
+	return func(o *options) {
+		o.withErrorStackTraces = true
+		o.withErrorStackDepth = maxDepth
+	}
+}
+
+// WithCircuitBreaker allows an optional circuit breaker configuration for
+// network sinks (kafka, cloudwatch and grpc).  A sink's breaker opens after
+// threshold consecutive failures, and events aren't sent to the sink until
+// cooldown has elapsed.  While it's open, audit and error events (and every
+// event of a sink with an enforced delivery guarantee) fail immediately,
+// while observation and system events are dropped.  It defaults to 5
+// failures and a 30 second cooldown.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(o *options) {
+		o.withCircuitBreaker = &circuitBreaker{threshold: threshold, cooldown: cooldown}
+	}
+}
+
+// WithMaxPausedObservations allows an optional limit on the number of
+// observations which are buffered while observations are paused (see
+// PauseObservations).  Observations are dropped once the limit is reached.
+// It defaults to 10,000.
+func WithMaxPausedObservations(n int) Option {
+	return func(o *options) {
+		o.withMaxPausedObservations = n
+	}
+}
+
+// WithErrorCoalescing allows an optional interval in which identical
+// consecutive error events (with the same op and error message) are coalesced.
+// The first of them is sent when it's written, like any error, and its
+// repeats are held and then sent as a single event, with a repeat_count and
+// the times the first and last of them were written.  The repeats are sent
+// when a different error is written, when the interval has elapsed since the
+// first error was written, or when the eventer is closed.  Since their writers
+// have already returned, repeats which can't be sent are only written to the
+// eventer's logger, so they're what can be lost.  Errors aren't coalesced by
+// default.
+func WithErrorCoalescing(interval time.Duration) Option {
+	return func(o *options) {
+		o.withErrorCoalesceInterval = interval
+	}
+}
+
+// WithSendTimeout allows an optional timeout for each attempt to send an event
+// to its sinks, so a blocked sink doesn't hold up the event's writer until the
+// writer's ctx is done.  Observation and system events which time out aren't
+// retried, while audit and error events are retried until the writer's ctx is
+// done.  Sends don't have a timeout by default.
+func WithSendTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.withSendTimeout = d
+	}
+}
+
+// WithSequenceNumbers allows an optional flag to number the events written by
+// the eventer with an increasing seq (see SeqField), so consumers can detect
+// events which were dropped between two events they have.  Audit, error and
+// system events are numbered in their payload, and observations in their
+// header.  Audit events and observations are only numbered when they're
+// flushed, since that's when they're emitted.  Numbers start at 1 for each
+// eventer, so they're reset when the eventer is recreated (and when Boundary
+// is restarted).
+func WithSequenceNumbers() Option {
+	return func(o *options) {
+		o.withSequenceNumbers = true
+	}
+}
+
+// WithNoDefaultSink allows an optional flag to return an error from
+// NewEventer when its config has no sinks, rather than falling back to the
+// DefaultSink.  An empty list of sinks is usually a config mistake, so this
+// ensures it's noticed rather than masked by the fallback.
+func WithNoDefaultSink() Option {
+	return func(o *options) {
+		o.withNoDefaultSink = true
+	}
+}
+
+// WithDedupeFileSinks allows an optional flag to collapse file sinks which
+// write to the same file into a single sink, which receives all of their event
+// types, rather than returning an error from NewEventer.  A warning is logged
+// for each sink which is collapsed.  It's intended for configs which are
+// generated from templates that may expand to overlapping paths.  Only sinks
+// whose other settings are the same are collapsed.
+func WithDedupeFileSinks() Option {
+	return func(o *options) {
+		o.withDedupeFileSinks = true
+	}
+}
+
+// WithEnforcedDelivery allows an optional flag to enforce the delivery of the
+// events of a type registered with RegisterEventType to every sink which
+// subscribes to it, like error events, rather than to at least one of them.
+func WithEnforcedDelivery() Option {
+	return func(o *options) {
+		o.withEnforcedDelivery = true
+	}
+}
+
+// WithPathConfirmation allows an optional flag to send a system event after
+// the first write to each file sink, with the absolute path (with symlinks
+// resolved) and inode of the sink's file.  It lets operators confirm where
+// events are actually written, which may not be where they expect when the
+// sink's Path is relative.  When system events aren't enabled, the path is
+// logged instead.
+func WithPathConfirmation() Option {
+	return func(o *options) {
+		o.withPathConfirmation = true
+	}
+}
+
+// WithSensitiveHeaders allows an optional list of header names whose values
+// are replaced with "REDACTED" in the header of observations, in addition to
+// the default names (Authorization, Proxy-Authorization, Cookie, Set-Cookie,
+// X-Api-Key and X-Auth-Token).  When replaceDefaults is true, the names replace
+// the defaults, so an empty list disables scrubbing.  Names are matched
+// case-insensitively.
+func WithSensitiveHeaders(names []string, replaceDefaults bool) Option {
+	return func(o *options) {
+		o.withSensitiveHeaders = names
+		o.withReplaceSensitive = replaceDefaults
+	}
+}
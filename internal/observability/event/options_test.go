@@ -1,10 +1,15 @@
 package event
 
 import (
+	"bytes"
+	"io"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/hashicorp/go-kms-wrapping/wrappers/aead"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Test_GetOpts provides unit tests for GetOpts and all the options
@@ -100,4 +105,204 @@ func Test_GetOpts(t *testing.T) {
 		testOpts.withEventerConfig = &c
 		assert.Equal(opts, testOpts)
 	})
+	t.Run("WithSchemaVersion", func(t *testing.T) {
+		assert := assert.New(t)
+		opts := getOpts(WithSchemaVersion("v-test"))
+		testOpts := getDefaultOptions()
+		testOpts.withSchemaVersion = "v-test"
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithWrapper", func(t *testing.T) {
+		assert := assert.New(t)
+		w := aead.NewWrapper(nil)
+		opts := getOpts(WithWrapper(w))
+		testOpts := getDefaultOptions()
+		testOpts.withWrapper = w
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithEncryptedObservations", func(t *testing.T) {
+		assert := assert.New(t)
+		opts := getOpts(WithEncryptedObservations())
+		testOpts := getDefaultOptions()
+		testOpts.withEncryptedObservations = true
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithWarningsHook", func(t *testing.T) {
+		assert := assert.New(t)
+		var called bool
+		opts := getOpts(WithWarningsHook(func(Type, []error) { called = true }))
+		assert.NotNil(opts.withWarningsHook)
+		opts.withWarningsHook(ErrorType, nil)
+		assert.True(called)
+	})
+	t.Run("WithDefaultFields", func(t *testing.T) {
+		assert := assert.New(t)
+		opts := getOpts(WithDefaultFields(map[string]string{"environment": "test"}))
+		testOpts := getDefaultOptions()
+		testOpts.withDefaultFields = map[string]string{"environment": "test"}
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithMaxEventBytes", func(t *testing.T) {
+		assert := assert.New(t)
+		opts := getOpts(WithMaxEventBytes(1024))
+		testOpts := getDefaultOptions()
+		testOpts.withMaxEventBytes = 1024
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithDedupWindow", func(t *testing.T) {
+		assert := assert.New(t)
+		opts := getOpts(WithDedupWindow(time.Minute))
+		testOpts := getDefaultOptions()
+		testOpts.withDedupWindow = time.Minute
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithStderrWriter", func(t *testing.T) {
+		assert := assert.New(t)
+		var buf bytes.Buffer
+		opts := getOpts(WithStderrWriter(&buf))
+		testOpts := getDefaultOptions()
+		testOpts.withStderrWriter = &buf
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithUnserializedStderr", func(t *testing.T) {
+		assert := assert.New(t)
+		opts := getOpts(WithUnserializedStderr())
+		testOpts := getDefaultOptions()
+		testOpts.withUnserializedStderr = true
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithStrictSerialization", func(t *testing.T) {
+		assert := assert.New(t)
+		opts := getOpts(WithStrictSerialization())
+		testOpts := getDefaultOptions()
+		testOpts.withStrictSerialization = true
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithRateLimit", func(t *testing.T) {
+		assert := assert.New(t)
+		opts := getOpts(WithRateLimit(AuditType, 10, 5), WithRateLimit(ObservationType, 1, 1))
+		testOpts := getDefaultOptions()
+		testOpts.withRateLimits = map[Type]rateLimit{
+			AuditType:       {eventsPerSecond: 10, burst: 5},
+			ObservationType: {eventsPerSecond: 1, burst: 1},
+		}
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithObservationLevel", func(t *testing.T) {
+		assert := assert.New(t)
+		opts := getOpts(WithObservationLevel(DebugLevel))
+		testOpts := getDefaultOptions()
+		testOpts.withObservationLevel = DebugLevel
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithNoBackoffJitter", func(t *testing.T) {
+		assert := assert.New(t)
+		opts := getOpts(WithNoBackoffJitter())
+		testOpts := getDefaultOptions()
+		testOpts.withNoBackoffJitter = true
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithErrorStackTraces", func(t *testing.T) {
+		assert := assert.New(t)
+		opts := getOpts(WithErrorStackTraces(10))
+		testOpts := getDefaultOptions()
+		testOpts.withErrorStackTraces = true
+		testOpts.withErrorStackDepth = 10
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithCloudWatchClient", func(t *testing.T) {
+		assert := assert.New(t)
+		client := &testCloudWatchClient{}
+		opts := getOpts(WithCloudWatchClient(func(*CloudWatchSinkConfig) (CloudWatchLogsClient, error) { return client, nil }))
+		require.NotNil(t, opts.withCloudWatchClient)
+		got, err := opts.withCloudWatchClient(&CloudWatchSinkConfig{})
+		assert.NoError(err)
+		assert.Equal(client, got)
+	})
+	t.Run("WithCircuitBreaker", func(t *testing.T) {
+		assert := assert.New(t)
+		opts := getOpts(WithCircuitBreaker(3, time.Minute))
+		testOpts := getDefaultOptions()
+		testOpts.withCircuitBreaker = &circuitBreaker{threshold: 3, cooldown: time.Minute}
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithMaxPausedObservations", func(t *testing.T) {
+		assert := assert.New(t)
+		opts := getOpts(WithMaxPausedObservations(10))
+		testOpts := getDefaultOptions()
+		testOpts.withMaxPausedObservations = 10
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithWarningsAsEvents", func(t *testing.T) {
+		assert := assert.New(t)
+		opts := getOpts(WithWarningsAsEvents(true))
+		testOpts := getDefaultOptions()
+		testOpts.withWarningsAsEvents = true
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithWriterGroup", func(t *testing.T) {
+		assert := assert.New(t)
+		l := &sync.Mutex{}
+		opts := getOpts(WithWriterGroup("audit", io.Discard, l))
+		testOpts := getDefaultOptions()
+		testOpts.withWriterGroups = map[string]serializedWriter{"audit": {w: io.Discard, l: l}}
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithErrorCoalescing", func(t *testing.T) {
+		assert := assert.New(t)
+		opts := getOpts(WithErrorCoalescing(time.Second))
+		testOpts := getDefaultOptions()
+		testOpts.withErrorCoalesceInterval = time.Second
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithSendTimeout", func(t *testing.T) {
+		assert := assert.New(t)
+		opts := getOpts(WithSendTimeout(time.Second))
+		testOpts := getDefaultOptions()
+		testOpts.withSendTimeout = time.Second
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithSequenceNumbers", func(t *testing.T) {
+		assert := assert.New(t)
+		opts := getOpts(WithSequenceNumbers())
+		testOpts := getDefaultOptions()
+		testOpts.withSequenceNumbers = true
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithNoDefaultSink", func(t *testing.T) {
+		assert := assert.New(t)
+		opts := getOpts(WithNoDefaultSink())
+		testOpts := getDefaultOptions()
+		testOpts.withNoDefaultSink = true
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithDedupeFileSinks", func(t *testing.T) {
+		assert := assert.New(t)
+		opts := getOpts(WithDedupeFileSinks())
+		testOpts := getDefaultOptions()
+		testOpts.withDedupeFileSinks = true
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithEnforcedDelivery", func(t *testing.T) {
+		assert := assert.New(t)
+		opts := getOpts(WithEnforcedDelivery())
+		testOpts := getDefaultOptions()
+		testOpts.withEnforcedDelivery = true
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithPathConfirmation", func(t *testing.T) {
+		assert := assert.New(t)
+		opts := getOpts(WithPathConfirmation())
+		testOpts := getDefaultOptions()
+		testOpts.withPathConfirmation = true
+		assert.Equal(opts, testOpts)
+	})
+	t.Run("WithSensitiveHeaders", func(t *testing.T) {
+		assert := assert.New(t)
+		opts := getOpts(WithSensitiveHeaders([]string{"X-Secret"}, true))
+		testOpts := getDefaultOptions()
+		testOpts.withSensitiveHeaders = []string{"X-Secret"}
+		testOpts.withReplaceSensitive = true
+		assert.Equal(opts, testOpts)
+	})
 }
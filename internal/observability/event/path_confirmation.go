@@ -0,0 +1,103 @@
+package event
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+)
+
+// pathConfirmation is the path of a file sink's file, which is sent as a
+// system event after the sink's first write (see WithPathConfirmation).
+type pathConfirmation struct {
+	sinkName string
+	path     string
+	inode    uint64
+}
+
+// pathConfirmations queues the paths of the file sinks until they're sent.
+// Paths are queued by the sinks while they write an event, and they're sent
+// once the broker has finished sending that event, since a system event can't
+// be sent from within a sink.
+type pathConfirmations struct {
+	l      sync.Mutex
+	queued []pathConfirmation
+}
+
+// queue the path of a sink's file.
+func (c *pathConfirmations) queue(pc pathConfirmation) {
+	c.l.Lock()
+	defer c.l.Unlock()
+	c.queued = append(c.queued, pc)
+}
+
+// take returns and clears the queued paths.
+func (c *pathConfirmations) take() []pathConfirmation {
+	c.l.Lock()
+	defer c.l.Unlock()
+	queued := c.queued
+	c.queued = nil
+	return queued
+}
+
+// sendPathConfirmations sends a system event for each of the file sink paths
+// queued since they were last sent.  When the eventer doesn't send system
+// events, the paths are logged instead.  Failures are only logged, since the
+// events which were written to the sinks were delivered.  The caller must
+// hold the eventer's closeLock.
+func (e *Eventer) sendPathConfirmations() {
+	const op = "event.(Eventer).sendPathConfirmations"
+	for _, pc := range e.pathConfirmations.take() {
+		if !e.conf.SysEventsEnabled || len(e.sysPipelines) == 0 {
+			e.logger.Info("file sink first written", "sink", pc.sinkName, "path", pc.path, "inode", pc.inode)
+			continue
+		}
+		id, err := newId(string(SystemType))
+		if err != nil {
+			e.logger.Error("unable to generate id for path confirmation system event", "error", err.Error())
+			continue
+		}
+		event := &sysEvent{
+			Id:      Id(id),
+			Version: sysVersion,
+			Op:      op,
+			Data: map[string]interface{}{
+				"msg":   "file sink first written",
+				"sink":  pc.sinkName,
+				"path":  pc.path,
+				"inode": pc.inode,
+			},
+		}
+		// the event isn't sent with the ctx of the event which was written,
+		// so it isn't recorded in that event's SendResult
+		if err := e.sendSysEvent(context.Background(), event); err != nil {
+			e.logger.Error("unable to send path confirmation system event", "sink", pc.sinkName, "path", pc.path, "error", err.Error())
+		}
+	}
+}
+
+// confirmPath queues the path of the sink's file for a path confirmation
+// after the sink's first write.  The path is absolute, with any symlinks
+// resolved, so it's where the events are actually written.  The caller must
+// hold the sink's lock.
+func (fs *fileSink) confirmPath() {
+	if fs.pathConfirmations == nil || fs.pathConfirmed {
+		return
+	}
+	fs.pathConfirmed = true
+	path := fs.f.Name()
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	var inode uint64
+	if fi, err := fs.f.Stat(); err == nil {
+		inode = fileInode(fi)
+	}
+	fs.pathConfirmations.queue(pathConfirmation{
+		sinkName: fs.sinkName,
+		path:     path,
+		inode:    inode,
+	})
+}
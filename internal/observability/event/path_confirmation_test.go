@@ -0,0 +1,96 @@
+package event
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventer_PathConfirmation(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dir := t.TempDir()
+	c := EventerConfig{
+		SysEventsEnabled: true,
+		Sinks: []SinkConfig{
+			{
+				Name:       "first",
+				SinkType:   FileSink,
+				EventTypes: []Type{ErrorType},
+				Format:     JSONSinkFormat,
+				FileName:   "first.log",
+			},
+			{
+				Name:       "second",
+				SinkType:   FileSink,
+				EventTypes: []Type{ErrorType, SystemType},
+				Format:     JSONSinkFormat,
+				FileName:   "second.log",
+			},
+			{
+				Name:       "stderr",
+				SinkType:   StderrSink,
+				EventTypes: []Type{SystemType},
+				Format:     JSONSinkFormat,
+			},
+		},
+	}
+	tests := []struct {
+		name string
+		opts []Option
+		want []string
+	}{
+		{name: "confirmed", opts: []Option{WithPathConfirmation()}, want: []string{"first", "second"}},
+		{name: "not-confirmed"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			c := c
+			c.Sinks = append([]SinkConfig(nil), c.Sinks...)
+			// the file sinks' paths aren't clean, so the confirmed paths
+			// must be resolved
+			for i := range c.Sinks[:2] {
+				c.Sinks[i].Path = filepath.Join(dir, "logs", "..", "logs", tt.name)
+			}
+			buf := &SinkBuffer{}
+			e, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, c, append(tt.opts, WithStderrWriter(buf))...)
+			require.NoError(err)
+			t.Cleanup(func() { e.Close(context.Background()) })
+
+			// several events are written, but each sink's path is only
+			// confirmed once
+			for i := 0; i < 3; i++ {
+				ev, err := newError("TestEventer_PathConfirmation", ErrInvalidParameter)
+				require.NoError(err)
+				require.NoError(e.writeError(ctx, ev))
+			}
+
+			var got []string
+			for _, ev := range buf.Events(t, SystemType) {
+				if ev.Payload[OpField] != "event.(Eventer).sendPathConfirmations" {
+					continue
+				}
+				data := ev.Payload["data"].(map[string]interface{})
+				sink := data["sink"].(string)
+				got = append(got, sink)
+
+				wantPath := filepath.Join(dir, "logs", tt.name, sink+".log")
+				wantPath, err := filepath.EvalSymlinks(wantPath)
+				require.NoError(err)
+				assert.Equal(wantPath, data["path"])
+				fi, err := os.Stat(wantPath)
+				require.NoError(err)
+				assert.Equal(float64(fileInode(fi)), data["inode"])
+			}
+			assert.ElementsMatch(tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package event
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hashicorp/boundary/internal/observability/event/store"
+	"github.com/hashicorp/eventlogger"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxProtoEventBytes is the max size of an event read by a ProtoEventDecoder,
+// which protects it from allocating a huge buffer for a corrupt length.
+const maxProtoEventBytes = 64 << 20
+
+// protoFormatter is a formatter node which formats audit events as a
+// store.Event proto.  Each formatted event is prefixed by its length as an
+// unsigned varint, so a file of proto formatted events can be read with a
+// ProtoEventDecoder.  Only audit events can be formatted as protos.
+type protoFormatter struct{}
+
+var _ eventlogger.Node = &protoFormatter{}
+
+// Process formats the event as a length prefixed proto and stores the
+// formatted data in the event's Formatted field with a key of "proto"
+func (f *protoFormatter) Process(_ context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	const op = "event.(protoFormatter).Process"
+	if e == nil {
+		return nil, fmt.Errorf("%s: missing event: %w", op, ErrInvalidParameter)
+	}
+	pe := &store.Event{
+		CreatedAt: timestamppb.New(e.CreatedAt),
+		EventType: string(e.Type),
+	}
+	var a *audit
+	switch p := e.Payload.(type) {
+	case audit:
+		a = &p
+	case *audit:
+		a = p
+	default:
+		return nil, fmt.Errorf("%s: %s events can't be formatted as %s: %w", op, e.Type, ProtoSinkFormat, ErrInvalidParameter)
+	}
+	pa, err := auditToProto(a)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	pe.Payload = &store.Event_Audit{Audit: pa}
+
+	b, err := proto.Marshal(pe)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	formatted := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(b))
+	n := binary.PutUvarint(formatted, uint64(len(b)))
+	formatted = append(formatted[:n], b...)
+	e.FormattedAs(string(ProtoSinkFormat), formatted)
+	return e, nil
+}
+
+// Reopen is a no op
+func (f *protoFormatter) Reopen() error {
+	return nil
+}
+
+// Type describes the type of the node as a Formatter.
+func (f *protoFormatter) Type() eventlogger.NodeType {
+	return eventlogger.NodeTypeFormatter
+}
+
+// auditToProto converts the audit event into its proto.
+func auditToProto(a *audit) (*store.AuditEvent, error) {
+	const op = "event.auditToProto"
+	pa := &store.AuditEvent{
+		Id:             a.Id,
+		Version:        a.Version,
+		Type:           a.Type,
+		CorrelationId:  a.CorrelationId,
+		SerializedHmac: a.SerializedHMAC,
+	}
+	if !a.Timestamp.IsZero() {
+		pa.Timestamp = timestamppb.New(a.Timestamp)
+	}
+	if ri := a.RequestInfo; ri != nil {
+		pa.RequestInfo = &store.RequestInfo{
+			Id:       ri.Id,
+			Method:   ri.Method,
+			Path:     ri.Path,
+			PublicId: ri.PublicId,
+		}
+	}
+	if auth := a.Auth; auth != nil {
+		pa.Auth = &store.Auth{
+			AccessorId: auth.AccessorId,
+			Email:      auth.UserEmail,
+			Name:       auth.UserName,
+		}
+		if ui := auth.UserInfo; ui != nil {
+			pa.Auth.UserInfo = &store.UserInfo{
+				Id:            ui.UserId,
+				AuthAccountId: ui.AuthAccountId,
+			}
+		}
+		if auth.GrantsInfo != nil {
+			for _, g := range auth.GrantsInfo.Grants {
+				pa.Auth.Grants = append(pa.Auth.Grants, &store.GrantsPair{
+					Grant:   g.Grant,
+					ScopeId: g.ScopeId,
+				})
+			}
+		}
+	}
+	if r := a.Request; r != nil {
+		details, err := detailsToAny(r.Details)
+		if err != nil {
+			return nil, fmt.Errorf("%s: request details: %w", op, err)
+		}
+		pa.Request = &store.Request{
+			Operation: r.Operation,
+			Endpoint:  r.Endpoint,
+			Details:   details,
+		}
+	}
+	if r := a.Response; r != nil {
+		details, err := detailsToAny(r.Details)
+		if err != nil {
+			return nil, fmt.Errorf("%s: response details: %w", op, err)
+		}
+		pa.Response = &store.Response{
+			StatusCode: int32(r.StatusCode),
+			Details:    details,
+		}
+	}
+	if len(a.Header) > 0 {
+		// header values are only defined by their JSON, so the header is
+		// converted through it.
+		b, err := json.Marshal(a.Header)
+		if err != nil {
+			return nil, fmt.Errorf("%s: header: %w", op, err)
+		}
+		pa.Header = &structpb.Struct{}
+		if err := protojson.Unmarshal(b, pa.Header); err != nil {
+			return nil, fmt.Errorf("%s: header: %w", op, err)
+		}
+	}
+	return pa, nil
+}
+
+func detailsToAny(m proto.Message) (*anypb.Any, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return anypb.New(m)
+}
+
+// ProtoEventDecoder reads the events written by a sink with the proto format
+// (ProtoSinkFormat).  Use it like:
+//
+//	d, err := event.NewProtoEventDecoder(f)
+//	...
+//	for {
+//		e, err := d.Decode()
+//		if err == io.EOF {
+//			break
+//		}
+//		...
+//	}
+//
+// The details of an audit event's request and response are Any messages,
+// which can be unmarshaled with their UnmarshalTo or UnmarshalNew.
+type ProtoEventDecoder struct {
+	r *bufio.Reader
+}
+
+// NewProtoEventDecoder creates a new ProtoEventDecoder which reads events
+// from r.
+func NewProtoEventDecoder(r io.Reader) (*ProtoEventDecoder, error) {
+	const op = "event.NewProtoEventDecoder"
+	if r == nil {
+		return nil, fmt.Errorf("%s: missing reader: %w", op, ErrInvalidParameter)
+	}
+	return &ProtoEventDecoder{r: bufio.NewReader(r)}, nil
+}
+
+// Decode returns the next event.  It returns io.EOF when there are no more
+// events, and io.ErrUnexpectedEOF when the last event is truncated.
+func (d *ProtoEventDecoder) Decode() (*store.Event, error) {
+	const op = "event.(ProtoEventDecoder).Decode"
+	size, err := binary.ReadUvarint(d.r)
+	switch {
+	case err == io.EOF:
+		return nil, io.EOF
+	case err != nil:
+		return nil, fmt.Errorf("%s: unable to read event length: %w", op, err)
+	case size > maxProtoEventBytes:
+		return nil, fmt.Errorf("%s: event length of %d bytes exceeds the max of %d bytes: %w", op, size, maxProtoEventBytes, ErrInvalidParameter)
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("%s: unable to read event: %w", op, err)
+	}
+	e := &store.Event{}
+	if err := proto.Unmarshal(b, e); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return e, nil
+}
@@ -0,0 +1,240 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/boundary/internal/observability/event/store"
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func Test_protoFormatter_Process(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	now := time.Now()
+	details, e := structpb.NewStruct(map[string]interface{}{"name": "test"})
+	require.NoError(t, e)
+	wantDetails, e := anypb.New(details)
+	require.NoError(t, e)
+
+	testAudit := &audit{
+		Id:            "test-id",
+		Version:       auditVersion,
+		Type:          string(ApiRequest),
+		Timestamp:     now,
+		RequestInfo:   &RequestInfo{Id: "request-id", Method: "POST", Path: "/v1/users"},
+		CorrelationId: "correlation-id",
+		Auth: &Auth{
+			AccessorId: "at_1234567890",
+			UserInfo:   &UserInfo{UserId: "u_1234567890", AuthAccountId: "acctpw_1234567890"},
+			GrantsInfo: &GrantsInfo{Grants: []GrantsPair{{Grant: "id=*;type=*;actions=*", ScopeId: "global"}}},
+			UserName:   "alice",
+		},
+		Request:  &Request{Operation: "op", Endpoint: "/v1/users", Details: details},
+		Response: &Response{StatusCode: 200},
+		Header:   map[string]interface{}{"deployment": "east"},
+	}
+	wantAudit := &store.AuditEvent{
+		Id:            "test-id",
+		Version:       auditVersion,
+		Type:          string(ApiRequest),
+		Timestamp:     timestamppb.New(now),
+		RequestInfo:   &store.RequestInfo{Id: "request-id", Method: "POST", Path: "/v1/users"},
+		CorrelationId: "correlation-id",
+		Auth: &store.Auth{
+			AccessorId: "at_1234567890",
+			UserInfo:   &store.UserInfo{Id: "u_1234567890", AuthAccountId: "acctpw_1234567890"},
+			Grants:     []*store.GrantsPair{{Grant: "id=*;type=*;actions=*", ScopeId: "global"}},
+			Name:       "alice",
+		},
+		Request:  &store.Request{Operation: "op", Endpoint: "/v1/users", Details: wantDetails},
+		Response: &store.Response{StatusCode: 200},
+		Header:   &structpb.Struct{Fields: map[string]*structpb.Value{"deployment": structpb.NewStringValue("east")}},
+	}
+
+	tests := []struct {
+		name            string
+		e               *eventlogger.Event
+		want            *store.Event
+		wantErrIs       error
+		wantErrContains string
+	}{
+		{
+			name:            "missing-event",
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "missing event",
+		},
+		{
+			name: "not-an-audit",
+			e: &eventlogger.Event{
+				Type:    eventlogger.EventType(ErrorType),
+				Payload: &err{Id: "test-id", Op: "test", Version: errorVersion},
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "error events can't be formatted as proto",
+		},
+		{
+			name: "audit",
+			e: &eventlogger.Event{
+				Type:      eventlogger.EventType(AuditType),
+				CreatedAt: now,
+				Payload:   testAudit,
+			},
+			want: &store.Event{
+				CreatedAt: timestamppb.New(now),
+				EventType: string(AuditType),
+				Payload:   &store.Event_Audit{Audit: wantAudit},
+			},
+		},
+		{
+			name: "composed-audit",
+			e: &eventlogger.Event{
+				Type:      eventlogger.EventType(AuditType),
+				CreatedAt: now,
+				Payload:   *testAudit,
+			},
+			want: &store.Event{
+				CreatedAt: timestamppb.New(now),
+				EventType: string(AuditType),
+				Payload:   &store.Event_Audit{Audit: wantAudit},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			got, err := (&protoFormatter{}).Process(ctx, tt.e)
+			if tt.wantErrIs != nil {
+				require.Error(err)
+				assert.ErrorIs(err, tt.wantErrIs)
+				assert.Contains(err.Error(), tt.wantErrContains)
+				return
+			}
+			require.NoError(err)
+			b, ok := got.Format(string(ProtoSinkFormat))
+			require.True(ok)
+
+			d, err := NewProtoEventDecoder(bytes.NewReader(b))
+			require.NoError(err)
+			decoded, err := d.Decode()
+			require.NoError(err)
+			assert.Empty(cmp.Diff(tt.want, decoded, protocmp.Transform()))
+			_, err = d.Decode()
+			assert.Equal(io.EOF, err)
+		})
+	}
+}
+
+func TestProtoEventDecoder(t *testing.T) {
+	t.Parallel()
+	assert, require := assert.New(t), require.New(t)
+	ctx := context.Background()
+
+	_, err := NewProtoEventDecoder(nil)
+	require.Error(err)
+	assert.ErrorIs(err, ErrInvalidParameter)
+
+	var stream []byte
+	for _, id := range []string{"first", "second"} {
+		e, err := (&protoFormatter{}).Process(ctx, &eventlogger.Event{
+			Type:      eventlogger.EventType(AuditType),
+			CreatedAt: time.Now(),
+			Payload:   &audit{Id: id, Version: auditVersion, Type: string(ApiRequest)},
+		})
+		require.NoError(err)
+		b, _ := e.Format(string(ProtoSinkFormat))
+		stream = append(stream, b...)
+	}
+
+	d, err := NewProtoEventDecoder(bytes.NewReader(stream))
+	require.NoError(err)
+	for _, id := range []string{"first", "second"} {
+		e, err := d.Decode()
+		require.NoError(err)
+		assert.Equal(id, e.GetAudit().GetId())
+	}
+	_, err = d.Decode()
+	assert.Equal(io.EOF, err)
+
+	// a truncated event is an error rather than the end of the stream
+	d, err = NewProtoEventDecoder(bytes.NewReader(stream[:len(stream)-1]))
+	require.NoError(err)
+	_, err = d.Decode()
+	require.NoError(err)
+	_, err = d.Decode()
+	assert.ErrorIs(err, io.ErrUnexpectedEOF)
+}
+
+func TestEventer_proto(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	protoFile, err := ioutil.TempFile("./", "tmp-proto-TestEventer_proto")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		os.Remove(protoFile.Name())
+	})
+	c := EventerConfig{
+		AuditEnabled: true,
+		Sinks: []SinkConfig{
+			{
+				Name:       "audit-proto",
+				SinkType:   FileSink,
+				EventTypes: []Type{AuditType},
+				Format:     ProtoSinkFormat,
+				Path:       "./",
+				FileName:   protoFile.Name(),
+			},
+			{
+				Name:       "errors",
+				SinkType:   StderrSink,
+				EventTypes: []Type{ErrorType},
+				Format:     JSONSinkFormat,
+			},
+		},
+	}
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	e, err := NewEventer(testLogger, testLock, c)
+	require.NoError(t, err)
+
+	// the audit is gated until it's flushed, and the gate composes the
+	// request and response into one event before it's formatted
+	req, err := newAudit("TestEventer_proto", WithId("test-id"), WithRequest(&Request{Operation: "op", Endpoint: "/v1/users"}))
+	require.NoError(t, err)
+	require.NoError(t, e.writeAudit(ctx, req))
+	resp, err := newAudit("TestEventer_proto", WithId("test-id"), WithResponse(&Response{StatusCode: 200}), WithFlush())
+	require.NoError(t, err)
+	require.NoError(t, e.writeAudit(ctx, resp))
+	require.NoError(t, e.Close(ctx))
+
+	f, err := os.Open(protoFile.Name())
+	require.NoError(t, err)
+	defer f.Close()
+	d, err := NewProtoEventDecoder(f)
+	require.NoError(t, err)
+	got, err := d.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, string(AuditType), got.GetEventType())
+	assert.Equal(t, "test-id", got.GetAudit().GetId())
+	assert.Equal(t, "/v1/users", got.GetAudit().GetRequest().GetEndpoint())
+	assert.Equal(t, int32(200), got.GetAudit().GetResponse().GetStatusCode())
+	_, err = d.Decode()
+	assert.Equal(t, io.EOF, err)
+}
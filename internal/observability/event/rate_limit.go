@@ -0,0 +1,103 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/hashicorp/eventlogger"
+	"golang.org/x/time/rate"
+)
+
+// rateLimit is the token bucket configuration for an event type.
+type rateLimit struct {
+	eventsPerSecond float64
+	burst           int
+}
+
+func (r rateLimit) validate() error {
+	const op = "event.(rateLimit).validate"
+	if r.eventsPerSecond <= 0 {
+		return fmt.Errorf("%s: rate must be greater than zero: %w", op, ErrInvalidParameter)
+	}
+	if r.burst <= 0 {
+		return fmt.Errorf("%s: burst must be greater than zero: %w", op, ErrInvalidParameter)
+	}
+	return nil
+}
+
+// enforcedDelivery returns true for the event types whose delivery is
+// enforced (audit and error events, and the custom types registered
+// WithEnforcedDelivery), which are never dropped or buffered to shed load.
+func enforcedDelivery(t Type) bool {
+	switch t {
+	case AuditType, ErrorType:
+		return true
+	case ObservationType, SystemType:
+		return false
+	}
+	ct, ok := registeredType(t)
+	return ok && ct.enforcedDelivery
+}
+
+// rateLimitNode is a filter node which limits the rate at which events are
+// delivered to a sink using a token bucket.  When the limit is exceeded,
+// events with an enforced delivery (audit and error events) wait for a token,
+// while all other events are dropped and counted.
+//
+// A rateLimitNode must not be shared between pipelines, otherwise every sink
+// of an event type would share one sink's limit.
+type rateLimitNode struct {
+	limiter  *rate.Limiter
+	enforced bool
+	dropped  uint64
+}
+
+var _ eventlogger.Node = &rateLimitNode{}
+
+func newRateLimitNode(t Type, r rateLimit) (*rateLimitNode, error) {
+	const op = "event.newRateLimitNode"
+	if err := r.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &rateLimitNode{
+		limiter:  rate.NewLimiter(rate.Limit(r.eventsPerSecond), r.burst),
+		enforced: enforcedDelivery(t),
+	}, nil
+}
+
+// Process returns nil (filtering out the event) when the limit is exceeded
+// and the event's delivery isn't enforced.  When it is enforced, Process
+// waits for the limit to allow the event or for the ctx to be done.
+func (n *rateLimitNode) Process(ctx context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	const op = "event.(rateLimitNode).Process"
+	if e == nil {
+		return nil, fmt.Errorf("%s: missing event: %w", op, ErrInvalidParameter)
+	}
+	if !n.enforced {
+		if !n.limiter.Allow() {
+			atomic.AddUint64(&n.dropped, 1)
+			return nil, nil
+		}
+		return e, nil
+	}
+	if err := n.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return e, nil
+}
+
+// Dropped returns the number of events dropped by the node.
+func (n *rateLimitNode) Dropped() uint64 {
+	return atomic.LoadUint64(&n.dropped)
+}
+
+// Reopen is a no op
+func (n *rateLimitNode) Reopen() error {
+	return nil
+}
+
+// Type describes the type of the node as a Filter.
+func (n *rateLimitNode) Type() eventlogger.NodeType {
+	return eventlogger.NodeTypeFilter
+}
@@ -0,0 +1,162 @@
+package event
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newRateLimitNode(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		t            Type
+		r            rateLimit
+		wantErrIs    error
+		wantEnforced bool
+	}{
+		{name: "zero-rate", t: ObservationType, r: rateLimit{burst: 1}, wantErrIs: ErrInvalidParameter},
+		{name: "zero-burst", t: ObservationType, r: rateLimit{eventsPerSecond: 1}, wantErrIs: ErrInvalidParameter},
+		{name: "audit", t: AuditType, r: rateLimit{eventsPerSecond: 1, burst: 1}, wantEnforced: true},
+		{name: "error", t: ErrorType, r: rateLimit{eventsPerSecond: 1, burst: 1}, wantEnforced: true},
+		{name: "observation", t: ObservationType, r: rateLimit{eventsPerSecond: 1, burst: 1}},
+		{name: "system", t: SystemType, r: rateLimit{eventsPerSecond: 1, burst: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			n, err := newRateLimitNode(tt.t, tt.r)
+			if tt.wantErrIs != nil {
+				require.Error(err)
+				assert.ErrorIs(err, tt.wantErrIs)
+				return
+			}
+			require.NoError(err)
+			assert.Equal(tt.wantEnforced, n.enforced)
+		})
+	}
+}
+
+func Test_rateLimitNode_Process(t *testing.T) {
+	t.Parallel()
+	t.Run("dropped", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		n, err := newRateLimitNode(ObservationType, rateLimit{eventsPerSecond: 0.001, burst: 2})
+		require.NoError(err)
+		var delivered int
+		for i := 0; i < 5; i++ {
+			got, err := n.Process(context.Background(), &eventlogger.Event{Type: "test"})
+			require.NoError(err)
+			if got != nil {
+				delivered++
+			}
+		}
+		assert.Equal(2, delivered)
+		assert.Equal(uint64(3), n.Dropped())
+	})
+	t.Run("enforced-canceled", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		n, err := newRateLimitNode(AuditType, rateLimit{eventsPerSecond: 0.001, burst: 1})
+		require.NoError(err)
+		ctx, cancel := context.WithCancel(context.Background())
+		got, err := n.Process(ctx, &eventlogger.Event{Type: "test"})
+		require.NoError(err)
+		assert.NotNil(got)
+
+		cancel()
+		_, err = n.Process(ctx, &eventlogger.Event{Type: "test"})
+		require.Error(err)
+		assert.Equal(uint64(0), n.Dropped())
+	})
+	t.Run("missing-event", func(t *testing.T) {
+		n, err := newRateLimitNode(ObservationType, rateLimit{eventsPerSecond: 1, burst: 1})
+		require.NoError(t, err)
+		_, err = n.Process(context.Background(), nil)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	})
+}
+
+func TestEventer_WithRateLimit(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	tmpFile, err := ioutil.TempFile("./", "tmp-rate-limit-TestEventer_WithRateLimit")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.Remove(tmpFile.Name()) })
+
+	c := EventerConfig{
+		AuditEnabled:        true,
+		ObservationsEnabled: true,
+		Sinks: []SinkConfig{
+			{
+				Name:       "every-type-file-sink",
+				SinkType:   FileSink,
+				EventTypes: []Type{EveryType},
+				Format:     JSONSinkFormat,
+				Path:       "./",
+				FileName:   tmpFile.Name(),
+			},
+		},
+	}
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewEventer(testLogger, testLock, c, WithRateLimit(ObservationType, 0, 1))
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+		_, err = NewEventer(testLogger, testLock, c, WithRateLimit(EveryType, 1, 1))
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	})
+
+	const (
+		burst  = 2
+		events = 6
+	)
+	e, err := NewEventer(testLogger, testLock, c,
+		WithRateLimit(ObservationType, 0.001, burst),
+		WithRateLimit(AuditType, 50, burst),
+	)
+	require.NoError(t, err)
+
+	start := time.Now()
+	for i := 0; i < events; i++ {
+		testObservation, err := newObservation("TestEventer_WithRateLimit", WithId("obs-id"), WithFlush())
+		require.NoError(t, err)
+		require.NoError(t, e.writeObservation(ctx, testObservation))
+
+		testAudit, err := newAudit("TestEventer_WithRateLimit", WithId("audit-id"), WithFlush())
+		require.NoError(t, err)
+		require.NoError(t, e.writeAudit(ctx, testAudit))
+	}
+	// the audit events exceeding the burst waited for the limit
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64((events-burst-1)*time.Second/50))
+
+	b, err := ioutil.ReadFile(tmpFile.Name())
+	require.NoError(t, err)
+	got := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		for _, id := range []string{"obs-id", "audit-id"} {
+			if strings.Contains(line, `"id":"`+id+`"`) {
+				got[id]++
+			}
+		}
+	}
+	assert.Equal(t, map[string]int{"obs-id": burst, "audit-id": events}, got)
+
+	status := e.Health(ctx)
+	assert.Equal(t, uint64(events-burst), status.EventTypes[ObservationType].RateLimited)
+	assert.Equal(t, uint64(0), status.EventTypes[AuditType].RateLimited)
+}
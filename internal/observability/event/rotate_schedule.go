@@ -0,0 +1,150 @@
+package event
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// dailyRotateSchedulePrefix is the prefix of a "daily@HH:MM" rotate schedule.
+const dailyRotateSchedulePrefix = "daily@"
+
+// rotateSchedule is a parsed SinkConfig.RotateSchedule: a daily rotation at a
+// wall clock time in the local time zone.
+type rotateSchedule struct {
+	hour   int
+	minute int
+}
+
+// parseRotateSchedule parses a "daily@HH:MM" spec, using a 24 hour clock.
+// "@daily" is the same as "daily@00:00".
+func parseRotateSchedule(spec string) (*rotateSchedule, error) {
+	const op = "event.parseRotateSchedule"
+	if spec == "@daily" {
+		return &rotateSchedule{}, nil
+	}
+	if !strings.HasPrefix(spec, dailyRotateSchedulePrefix) {
+		return nil, fmt.Errorf("%s: rotate schedule %q must be in the form daily@HH:MM: %w", op, spec, ErrInvalidParameter)
+	}
+	parts := strings.Split(strings.TrimPrefix(spec, dailyRotateSchedulePrefix), ":")
+	if len(parts) != 2 || len(parts[0]) != 2 || len(parts[1]) != 2 {
+		return nil, fmt.Errorf("%s: rotate schedule %q must be in the form daily@HH:MM: %w", op, spec, ErrInvalidParameter)
+	}
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil || hour < 0 || hour > 23 {
+		return nil, fmt.Errorf("%s: rotate schedule %q has an invalid hour: %w", op, spec, ErrInvalidParameter)
+	}
+	minute, err := strconv.Atoi(parts[1])
+	if err != nil || minute < 0 || minute > 59 {
+		return nil, fmt.Errorf("%s: rotate schedule %q has an invalid minute: %w", op, spec, ErrInvalidParameter)
+	}
+	return &rotateSchedule{hour: hour, minute: minute}, nil
+}
+
+// on returns the scheduled time of the day, in the location.  When the wall
+// clock time doesn't exist that day, because a DST transition skips it, the
+// equivalent time after the transition is returned: 02:30 is 03:30 when
+// clocks go forward an hour at 02:00.
+func (s *rotateSchedule) on(year int, month time.Month, day int, loc *time.Location) time.Time {
+	at := time.Date(year, month, day, s.hour, s.minute, 0, 0, loc)
+	if at.Hour() == s.hour && at.Minute() == s.minute {
+		return at
+	}
+	// time.Date doesn't guarantee how a skipped time is resolved, so it's
+	// resolved using the offset in effect before the transition
+	_, offset := time.Date(year, month, day-1, 12, 0, 0, 0, loc).Zone()
+	return time.Date(year, month, day, s.hour, s.minute, 0, 0, time.FixedZone("", offset)).In(loc)
+}
+
+// next returns the first scheduled time after t, in t's location.
+func (s *rotateSchedule) next(t time.Time) time.Time {
+	y, m, d := t.Date()
+	for i := 0; ; i++ {
+		if at := s.on(y, m, d+i, t.Location()); at.After(t) {
+			return at
+		}
+	}
+}
+
+// following returns the scheduled time after the one which fired.  It's
+// always on a later day than fired, so the schedule fires once a day even
+// when a DST transition repeats its wall clock time.  If that time has
+// already passed, because the process was suspended, the first scheduled time
+// after now is returned instead.
+func (s *rotateSchedule) following(fired, now time.Time) time.Time {
+	y, m, d := fired.Date()
+	if at := s.on(y, m, d+1, fired.Location()); at.After(now) {
+		return at
+	}
+	return s.next(now)
+}
+
+// rotationScheduler rotates a file sink's file at the times of its schedule,
+// from a goroutine which runs until the scheduler is stopped.
+type rotationScheduler struct {
+	name     string
+	schedule *rotateSchedule
+	sink     *fileSink
+	logger   hclog.Logger
+	now      func() time.Time
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func newRotationScheduler(name string, schedule *rotateSchedule, sink *fileSink, logger hclog.Logger) (*rotationScheduler, error) {
+	const op = "event.newRotationScheduler"
+	switch {
+	case schedule == nil:
+		return nil, fmt.Errorf("%s: missing schedule: %w", op, ErrInvalidParameter)
+	case sink == nil:
+		return nil, fmt.Errorf("%s: missing sink: %w", op, ErrInvalidParameter)
+	case logger == nil:
+		return nil, fmt.Errorf("%s: missing logger: %w", op, ErrMissingLogger)
+	}
+	return &rotationScheduler{
+		name:     name,
+		schedule: schedule,
+		sink:     sink,
+		logger:   logger,
+		now:      time.Now,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// start starts the scheduler's goroutine.
+func (r *rotationScheduler) start() {
+	go r.run()
+}
+
+func (r *rotationScheduler) run() {
+	defer close(r.done)
+	at := r.schedule.next(r.now())
+	for {
+		t := time.NewTimer(at.Sub(r.now()))
+		select {
+		case <-r.stop:
+			t.Stop()
+			return
+		case <-t.C:
+		}
+		if err := r.sink.roll(); err != nil {
+			r.logger.Error("unable to rotate file sink on schedule", "sink", r.name, "error", err.Error())
+		}
+		at = r.schedule.following(at, r.now())
+	}
+}
+
+// close stops the scheduler and waits for its goroutine to return, so the
+// sink isn't rotated after close returns.  It must only be called after
+// start.
+func (r *rotationScheduler) close() {
+	r.stopOnce.Do(func() { close(r.stop) })
+	<-r.done
+}
@@ -0,0 +1,213 @@
+package event
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseRotateSchedule(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name            string
+		spec            string
+		want            *rotateSchedule
+		wantErrContains string
+	}{
+		{name: "daily", spec: "daily@02:30", want: &rotateSchedule{hour: 2, minute: 30}},
+		{name: "midnight", spec: "daily@00:00", want: &rotateSchedule{}},
+		{name: "at-daily", spec: "@daily", want: &rotateSchedule{}},
+		{name: "last-minute", spec: "daily@23:59", want: &rotateSchedule{hour: 23, minute: 59}},
+		{name: "cron", spec: "0 0 * * *", wantErrContains: "must be in the form daily@HH:MM"},
+		{name: "missing-minute", spec: "daily@02", wantErrContains: "must be in the form daily@HH:MM"},
+		{name: "single-digit-hour", spec: "daily@2:30", wantErrContains: "must be in the form daily@HH:MM"},
+		{name: "invalid-hour", spec: "daily@24:00", wantErrContains: "invalid hour"},
+		{name: "invalid-minute", spec: "daily@12:60", wantErrContains: "invalid minute"},
+		{name: "not-a-number", spec: "daily@ab:00", wantErrContains: "invalid hour"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			got, err := parseRotateSchedule(tt.spec)
+			if tt.wantErrContains != "" {
+				require.Error(err)
+				assert.ErrorIs(err, ErrInvalidParameter)
+				assert.Contains(err.Error(), tt.wantErrContains)
+				return
+			}
+			require.NoError(err)
+			assert.Equal(tt.want, got)
+		})
+	}
+}
+
+func Test_rotateSchedule_next(t *testing.T) {
+	t.Parallel()
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database is unavailable: %s", err)
+	}
+	tests := []struct {
+		name     string
+		schedule rotateSchedule
+		now      time.Time
+		want     time.Time
+	}{
+		{
+			name:     "same-day",
+			schedule: rotateSchedule{hour: 12},
+			now:      time.Date(2021, 6, 1, 9, 15, 0, 0, ny),
+			want:     time.Date(2021, 6, 1, 12, 0, 0, 0, ny),
+		},
+		{
+			name:     "across-midnight",
+			schedule: rotateSchedule{},
+			now:      time.Date(2021, 6, 1, 23, 59, 0, 0, ny),
+			want:     time.Date(2021, 6, 2, 0, 0, 0, 0, ny),
+		},
+		{
+			name:     "at-the-scheduled-time",
+			schedule: rotateSchedule{hour: 12},
+			now:      time.Date(2021, 6, 1, 12, 0, 0, 0, ny),
+			want:     time.Date(2021, 6, 2, 12, 0, 0, 0, ny),
+		},
+		{
+			name:     "across-month",
+			schedule: rotateSchedule{hour: 1},
+			now:      time.Date(2021, 6, 30, 2, 0, 0, 0, ny),
+			want:     time.Date(2021, 7, 1, 1, 0, 0, 0, ny),
+		},
+		{
+			// 2:30 doesn't exist on 2021-03-14, when clocks go from 2:00 EST
+			// to 3:00 EDT
+			name:     "dst-skipped-time",
+			schedule: rotateSchedule{hour: 2, minute: 30},
+			now:      time.Date(2021, 3, 14, 0, 0, 0, 0, ny),
+			want:     time.Date(2021, 3, 14, 3, 30, 0, 0, ny),
+		},
+		{
+			// the day is 23 hours long, and midnight is still midnight
+			name:     "dst-start-midnight",
+			schedule: rotateSchedule{},
+			now:      time.Date(2021, 3, 14, 0, 0, 0, 0, ny),
+			want:     time.Date(2021, 3, 15, 0, 0, 0, 0, ny),
+		},
+		{
+			// the day is 25 hours long, and midnight is still midnight
+			name:     "dst-end-midnight",
+			schedule: rotateSchedule{},
+			now:      time.Date(2021, 11, 7, 0, 0, 0, 0, ny),
+			want:     time.Date(2021, 11, 8, 0, 0, 0, 0, ny),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.schedule.next(tt.now)
+			assert.True(t, tt.want.Equal(got), "want %s, got %s", tt.want, got)
+		})
+	}
+	t.Run("dst-end-midnight-is-25-hours", func(t *testing.T) {
+		s := rotateSchedule{}
+		from := time.Date(2021, 11, 7, 0, 0, 0, 0, ny)
+		assert.Equal(t, 25*time.Hour, s.next(from).Sub(from))
+	})
+}
+
+func Test_rotateSchedule_following(t *testing.T) {
+	t.Parallel()
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database is unavailable: %s", err)
+	}
+	// 1:30 happens twice on 2021-11-07, when clocks go from 2:00 EDT back to
+	// 1:00 EST, but the schedule only fires once that day
+	s := rotateSchedule{hour: 1, minute: 30}
+	firstOccurrence := time.Date(2021, 11, 7, 5, 30, 0, 0, time.UTC).In(ny)
+	got := s.following(firstOccurrence, firstOccurrence)
+	want := time.Date(2021, 11, 8, 1, 30, 0, 0, ny)
+	assert.True(t, want.Equal(got), "want %s, got %s", want, got)
+
+	// when the next day's time has already passed, the first scheduled time
+	// after now is used
+	now := time.Date(2021, 11, 10, 9, 0, 0, 0, ny)
+	got = s.following(firstOccurrence, now)
+	want = time.Date(2021, 11, 11, 1, 30, 0, 0, ny)
+	assert.True(t, want.Equal(got), "want %s, got %s", want, got)
+}
+
+func Test_rotationScheduler(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	newEvent := func(data string) *eventlogger.Event {
+		e := &eventlogger.Event{Type: "test"}
+		e.FormattedAs(eventlogger.JSONFormat, []byte(data))
+		return e
+	}
+	tests := []struct {
+		name     string
+		schedule rotateSchedule
+		now      time.Time
+	}{
+		{
+			name:     "same-day",
+			schedule: rotateSchedule{hour: 12},
+			now:      time.Date(2021, 6, 1, 11, 59, 59, int(900*time.Millisecond), time.Local),
+		},
+		{
+			name:     "across-midnight",
+			schedule: rotateSchedule{},
+			now:      time.Date(2021, 6, 1, 23, 59, 59, int(900*time.Millisecond), time.Local),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			dir := t.TempDir()
+			fs := &fileSink{path: dir, fileName: "events.log", scheduled: true}
+			t.Cleanup(func() { fs.Close() })
+			_, err := fs.Process(ctx, newEvent("before\n"))
+			require.NoError(err)
+
+			rs, err := newRotationScheduler("test", &tt.schedule, fs, hclog.NewNullLogger())
+			require.NoError(err)
+			// the fake clock starts 100ms before the scheduled time, so the
+			// scheduler rotates once and then waits for the next day
+			start := time.Now()
+			rs.now = func() time.Time { return tt.now.Add(time.Since(start)) }
+			rs.start()
+
+			files := func() []string {
+				files, err := filepath.Glob(filepath.Join(dir, "events-*.log"))
+				require.NoError(err)
+				return files
+			}
+			assert.Eventually(func() bool { return len(files()) == 2 }, 5*time.Second, 10*time.Millisecond)
+			rs.close()
+			// stopping twice is a no op
+			rs.close()
+
+			_, err = fs.Process(ctx, newEvent("after\n"))
+			require.NoError(err)
+			assert.Len(files(), 2)
+		})
+	}
+	t.Run("missing-schedule", func(t *testing.T) {
+		_, err := newRotationScheduler("test", nil, &fileSink{}, hclog.NewNullLogger())
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	})
+	t.Run("missing-sink", func(t *testing.T) {
+		_, err := newRotationScheduler("test", &rotateSchedule{}, nil, hclog.NewNullLogger())
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	})
+	t.Run("missing-logger", func(t *testing.T) {
+		_, err := newRotationScheduler("test", &rotateSchedule{}, &fileSink{}, nil)
+		assert.ErrorIs(t, err, ErrMissingLogger)
+	})
+}
@@ -0,0 +1,106 @@
+package event
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hashicorp/eventlogger"
+)
+
+// SendResult reports which sinks an event was written to.  Sinks are
+// identified by their SinkConfig.Name.  A sink which didn't receive the event,
+// because it doesn't receive the event's type or its filters dropped the
+// event, is in neither list.
+type SendResult struct {
+	// Delivered are the sinks which wrote the event.
+	Delivered []string
+
+	// Failed are the sinks which returned an error writing the event.
+	Failed []string
+}
+
+// sendResultKey is the ctx key of the sendRecorder of a write whose result is
+// returned to its caller.
+type sendResultKey struct{}
+
+// sendRecorder records the outcome of each sink a write sends its event to.
+// The eventlogger.Status returned by the broker only includes the ids of the
+// sinks which wrote the event in unexported fields, so each sink node records
+// its own outcome in the recorder found in the ctx it processes the event
+// with.
+type sendRecorder struct {
+	l         sync.Mutex
+	delivered []string
+	failed    []string
+}
+
+func newSendResultContext(ctx context.Context) (context.Context, *sendRecorder) {
+	r := &sendRecorder{}
+	return context.WithValue(ctx, sendResultKey{}, r), r
+}
+
+// sendRecorderFromContext returns the ctx's recorder, if it has one.
+func sendRecorderFromContext(ctx context.Context) (*sendRecorder, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	r, ok := ctx.Value(sendResultKey{}).(*sendRecorder)
+	return r, ok
+}
+
+func (r *sendRecorder) record(sink string, err error) {
+	r.l.Lock()
+	defer r.l.Unlock()
+	if err != nil {
+		r.failed = append(r.failed, sink)
+		return
+	}
+	r.delivered = append(r.delivered, sink)
+}
+
+// reset forgets the recorded outcomes, so a retried send only reports the
+// outcomes of its last attempt.
+func (r *sendRecorder) reset() {
+	r.l.Lock()
+	defer r.l.Unlock()
+	r.delivered, r.failed = nil, nil
+}
+
+func (r *sendRecorder) result() SendResult {
+	r.l.Lock()
+	defer r.l.Unlock()
+	return SendResult{
+		Delivered: append([]string(nil), r.delivered...),
+		Failed:    append([]string(nil), r.failed...),
+	}
+}
+
+// resultSink is a sink node which records the outcome of its sink for the
+// write which sent the event (see sendRecorder).  It's only registered with
+// the broker: the eventer's pipelines keep the sink node it wraps.
+type resultSink struct {
+	name string
+	node eventlogger.Node
+}
+
+var _ eventlogger.Node = &resultSink{}
+
+// Process writes the event to the sink and records the outcome.  The sink's
+// result is returned as is.
+func (s *resultSink) Process(ctx context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	got, err := s.node.Process(ctx, e)
+	if r, ok := sendRecorderFromContext(ctx); ok {
+		r.record(s.name, err)
+	}
+	return got, err
+}
+
+// Reopen reopens the sink.
+func (s *resultSink) Reopen() error {
+	return s.node.Reopen()
+}
+
+// Type describes the type of the node as a Sink.
+func (s *resultSink) Type() eventlogger.NodeType {
+	return eventlogger.NodeTypeSink
+}
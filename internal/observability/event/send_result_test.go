@@ -0,0 +1,215 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteObservationWithResult(t *testing.T) {
+	TestEnableEventing(t, true)
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	c := EventerConfig{
+		ObservationsEnabled: true,
+		Sinks:               []SinkConfig{DefaultSink()},
+	}
+
+	t.Run("partial-status", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		testBroker := &testMockBroker{
+			deliveredOnSend: []string{"file", "kafka"},
+			failedOnSend:    []string{"stderr"},
+		}
+		e, err := NewEventer(testLogger, testLock, c, TestWithBroker(t, testBroker))
+		require.NoError(err)
+		ctx, err := NewEventerContext(context.Background(), e)
+		require.NoError(err)
+
+		got, err := WriteObservationWithResult(ctx, "TestWriteObservationWithResult", WithHeader(map[string]interface{}{"test": "header"}), WithFlush())
+		require.NoError(err)
+		assert.ElementsMatch([]string{"file", "kafka"}, got.Delivered)
+		assert.Equal([]string{"stderr"}, got.Failed)
+	})
+	t.Run("send-error", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		testBroker := &testMockBroker{
+			failedOnSend: []string{"stderr"},
+			errorOnSend:  errors.New("not written to enough sinks"),
+		}
+		e, err := NewEventer(testLogger, testLock, c, TestWithBroker(t, testBroker), WithNoBackoffJitter())
+		require.NoError(err)
+		ctx, err := NewEventerContext(context.Background(), e)
+		require.NoError(err)
+
+		got, err := WriteObservationWithResult(ctx, "TestWriteObservationWithResult", WithHeader(map[string]interface{}{"test": "header"}), WithFlush())
+		require.Error(err)
+		// only the last attempt's outcomes are returned
+		assert.Empty(got.Delivered)
+		assert.Equal([]string{"stderr"}, got.Failed)
+	})
+	t.Run("not-flushed", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		testBroker := &testMockBroker{}
+		e, err := NewEventer(testLogger, testLock, c, TestWithBroker(t, testBroker))
+		require.NoError(err)
+		ctx, err := NewEventerContext(context.Background(), e)
+		require.NoError(err)
+
+		got, err := WriteObservationWithResult(ctx, "TestWriteObservationWithResult", WithHeader(map[string]interface{}{"test": "header"}))
+		require.NoError(err)
+		assert.Empty(got.Delivered)
+		assert.Empty(got.Failed)
+	})
+	t.Run("sinks", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		c := EventerConfig{
+			ObservationsEnabled: true,
+			Sinks: []SinkConfig{
+				{
+					Name:       "stderr",
+					SinkType:   StderrSink,
+					EventTypes: []Type{ObservationType, ErrorType},
+					Format:     JSONSinkFormat,
+				},
+				{
+					Name:       "discard",
+					SinkType:   DiscardSink,
+					EventTypes: []Type{ObservationType},
+					Format:     JSONSinkFormat,
+				},
+				{
+					Name:       "filtered",
+					SinkType:   DiscardSink,
+					EventTypes: []Type{ObservationType},
+					Format:     JSONSinkFormat,
+					OpPrefixes: []string{"other"},
+				},
+			},
+		}
+		e, err := NewEventer(testLogger, testLock, c, WithStderrWriter(failingWriter{}))
+		require.NoError(err)
+		t.Cleanup(func() { e.Close(context.Background()) })
+		ctx, err := NewEventerContext(context.Background(), e)
+		require.NoError(err)
+
+		got, err := WriteObservationWithResult(ctx, "TestWriteObservationWithResult", WithHeader(map[string]interface{}{"test": "header"}), WithFlush())
+		require.NoError(err)
+		assert.Equal([]string{"discard"}, got.Delivered)
+		assert.Equal([]string{"stderr"}, got.Failed)
+	})
+}
+
+func TestWriteAuditWithResult(t *testing.T) {
+	TestEnableEventing(t, true)
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	c := EventerConfig{
+		AuditEnabled: true,
+		Sinks:        []SinkConfig{DefaultSink()},
+	}
+
+	t.Run("delivered", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		testBroker := &testMockBroker{deliveredOnSend: []string{"file"}}
+		e, err := NewEventer(testLogger, testLock, c, TestWithBroker(t, testBroker))
+		require.NoError(err)
+		ctx, err := NewEventerContext(context.Background(), e)
+		require.NoError(err)
+
+		got, err := WriteAuditWithResult(ctx, "TestWriteAuditWithResult", WithFlush())
+		require.NoError(err)
+		assert.Equal([]string{"file"}, got.Delivered)
+		assert.Empty(got.Failed)
+	})
+	t.Run("send-error", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		testBroker := &testMockBroker{
+			failedOnSend: []string{"kafka"},
+			errorOnSend:  errors.New("not written to enough sinks"),
+		}
+		e, err := NewEventer(testLogger, testLock, c, TestWithBroker(t, testBroker), WithNoBackoffJitter())
+		require.NoError(err)
+		ctx, err := NewEventerContext(context.Background(), e)
+		require.NoError(err)
+
+		got, err := WriteAuditWithResult(ctx, "TestWriteAuditWithResult", WithFlush())
+		require.Error(err)
+		assert.Equal([]string{"kafka"}, got.Failed)
+	})
+}
+
+func TestWriteAuditFailClosed(t *testing.T) {
+	TestEnableEventing(t, true)
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	c := EventerConfig{
+		AuditEnabled: true,
+		Sinks:        []SinkConfig{DefaultSink()},
+	}
+
+	t.Run("delivered", func(t *testing.T) {
+		e, err := NewEventer(testLogger, testLock, c, TestWithBroker(t, &testMockBroker{}))
+		require.NoError(t, err)
+		ctx, err := NewEventerContext(context.Background(), e)
+		require.NoError(t, err)
+		assert.NoError(t, WriteAuditFailClosed(ctx, "TestWriteAuditFailClosed", WithFlush()))
+	})
+	t.Run("not-delivered", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		sendErr := errors.New("not written to enough sinks")
+		testBroker := &testMockBroker{errorOnSend: sendErr}
+		e, err := NewEventer(testLogger, testLock, c, TestWithBroker(t, testBroker), WithNoBackoffJitter())
+		require.NoError(err)
+		ctx, err := NewEventerContext(context.Background(), e)
+		require.NoError(err)
+
+		err = WriteAuditFailClosed(ctx, "TestWriteAuditFailClosed", WithFlush())
+		require.Error(err)
+		assert.ErrorIs(err, ErrAuditNotDelivered)
+		// the cause of the failure is wrapped too
+		assert.ErrorIs(err, ErrMaxRetries)
+	})
+	t.Run("closed", func(t *testing.T) {
+		e, err := NewEventer(testLogger, testLock, c, TestWithBroker(t, &testMockBroker{}))
+		require.NoError(t, err)
+		ctx, err := NewEventerContext(context.Background(), e)
+		require.NoError(t, err)
+		require.NoError(t, e.Close(ctx))
+
+		err = WriteAuditFailClosed(ctx, "TestWriteAuditFailClosed", WithFlush())
+		assert.ErrorIs(t, err, ErrAuditNotDelivered)
+		assert.ErrorIs(t, err, ErrEventerClosed)
+	})
+	t.Run("invalid-parameter", func(t *testing.T) {
+		e, err := NewEventer(testLogger, testLock, c, TestWithBroker(t, &testMockBroker{}))
+		require.NoError(t, err)
+		ctx, err := NewEventerContext(context.Background(), e)
+		require.NoError(t, err)
+
+		err = WriteAuditFailClosed(ctx, "")
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+		assert.False(t, errors.Is(err, ErrAuditNotDelivered))
+	})
+}
+
+// failingWriter is an io.Writer which always fails.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("failing writer")
+}
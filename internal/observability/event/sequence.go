@@ -0,0 +1,19 @@
+package event
+
+import "sync/atomic"
+
+// nextSeq returns the sequence number of the next event sent, or zero when the
+// eventer wasn't created WithSequenceNumbers.  Sequence numbers are assigned
+// as events are sent, starting at 1 for each eventer.  They're only held in
+// memory, so a consumer which sees the sequence start over at 1 is seeing a
+// new eventer (most likely a restart), not a gap.  Events written concurrently
+// may reach a sink slightly out of order, so gaps should be detected over a
+// window of events rather than between adjacent ones.  Events which aren't
+// sent at all, like coalesced errors and observations below the eventer's
+// level, aren't numbered.
+func (e *Eventer) nextSeq() uint64 {
+	if !e.sequenceNumbers {
+		return 0
+	}
+	return atomic.AddUint64(&e.seq, 1)
+}
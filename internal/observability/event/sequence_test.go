@@ -0,0 +1,93 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventer_WithSequenceNumbers(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	const (
+		writers          = 8
+		writesPerWriter  = 25
+		typesPerWrite    = 4
+		wantEventsWriter = writesPerWriter * typesPerWrite
+	)
+
+	e, buf := NewTestEventer(t, WithSequenceNumbers())
+
+	// each writer records the seq of the events it wrote, in the order it
+	// wrote them
+	gotSeqs := make([][]uint64, writers)
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			op := Op(fmt.Sprintf("TestEventer_WithSequenceNumbers-%d", w))
+			for i := 0; i < writesPerWriter; i++ {
+				a, err := newAudit(op, WithFlush())
+				require.NoError(t, err)
+				require.NoError(t, e.writeAudit(ctx, a))
+				gotSeqs[w] = append(gotSeqs[w], a.Seq)
+
+				o, err := newObservation(op, WithFlush(), WithHeader(map[string]interface{}{"name": "value"}))
+				require.NoError(t, err)
+				require.NoError(t, e.writeObservation(ctx, o))
+				gotSeqs[w] = append(gotSeqs[w], o.Header[SeqField].(uint64))
+
+				er, err := newError(op, fmt.Errorf("%d: %w", i, ErrIo))
+				require.NoError(t, err)
+				require.NoError(t, e.writeError(ctx, er))
+				gotSeqs[w] = append(gotSeqs[w], er.Seq)
+
+				id, err := newId(string(SystemType))
+				require.NoError(t, err)
+				s := &sysEvent{Id: Id(id), Op: op, Data: map[string]interface{}{"i": i}}
+				require.NoError(t, e.writeSysEvent(ctx, s))
+				gotSeqs[w] = append(gotSeqs[w], s.Seq)
+			}
+		}(w)
+	}
+	wg.Wait()
+	require.NoError(t, e.Close(ctx))
+
+	for w, seqs := range gotSeqs {
+		require.Len(t, seqs, wantEventsWriter)
+		for i := 1; i < len(seqs); i++ {
+			assert.Greaterf(t, seqs[i], seqs[i-1], "writer %d event %d", w, i)
+		}
+	}
+
+	// every event written has a seq, and the sequence has no gaps
+	var written []uint64
+	for _, got := range buf.Events(t) {
+		seq, ok := got.Payload[SeqField].(float64)
+		if got.EventType == ObservationType {
+			seq, ok = got.Payload[HeaderField].(map[string]interface{})[SeqField].(float64)
+		}
+		require.Truef(t, ok, "%s event is missing its seq", got.EventType)
+		written = append(written, uint64(seq))
+	}
+	require.Len(t, written, writers*wantEventsWriter)
+	sort.Slice(written, func(i, j int) bool { return written[i] < written[j] })
+	for i, seq := range written {
+		assert.Equal(t, uint64(i+1), seq)
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		e, buf := NewTestEventer(t)
+		er, err := newError("TestEventer_WithSequenceNumbers", ErrIo)
+		require.NoError(t, err)
+		require.NoError(t, e.writeError(ctx, er))
+		assert.Zero(t, er.Seq)
+		assert.NotContains(t, buf.String(), `"seq"`)
+	})
+}
@@ -1,11 +1,18 @@
 package event
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
 	"os"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/boundary/internal/errors"
+	"github.com/hashicorp/go-hclog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -74,3 +81,161 @@ func TestSerializedWriter_Write(t *testing.T) {
 		})
 	}
 }
+
+func TestEventer_WithWriterGroup(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	c := EventerConfig{
+		AuditEnabled: true,
+		Sinks: []SinkConfig{
+			{
+				Name:        "audit",
+				SinkType:    StderrSink,
+				EventTypes:  []Type{AuditType},
+				Format:      JSONSinkFormat,
+				WriterGroup: "audit",
+			},
+			{
+				Name:       "errors",
+				SinkType:   StderrSink,
+				EventTypes: []Type{ErrorType},
+				Format:     JSONSinkFormat,
+			},
+		},
+	}
+	t.Run("separate-writers", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		var stderrBuf, auditBuf bytes.Buffer
+		e, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, c, WithStderrWriter(&stderrBuf), WithWriterGroup("audit", &auditBuf, &sync.Mutex{}))
+		require.NoError(err)
+
+		a, err := newAudit("TestEventer_WithWriterGroup", WithId("audit-id"), WithFlush())
+		require.NoError(err)
+		require.NoError(e.writeAudit(ctx, a))
+		ev, err := newError("TestEventer_WithWriterGroup", fmt.Errorf("test error"), WithId("error-id"))
+		require.NoError(err)
+		require.NoError(e.writeError(ctx, ev))
+
+		assert.Contains(auditBuf.String(), "audit-id")
+		assert.NotContains(auditBuf.String(), "error-id")
+		assert.Contains(stderrBuf.String(), "error-id")
+		assert.NotContains(stderrBuf.String(), "audit-id")
+	})
+	t.Run("missing-group", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		_, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, c)
+		require.Error(err)
+		assert.ErrorIs(err, ErrInvalidParameter)
+		assert.Contains(err.Error(), `requires writer group "audit"`)
+	})
+	t.Run("missing-lock", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		_, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, c, WithWriterGroup("audit", io.Discard, nil))
+		require.Error(err)
+		assert.ErrorIs(err, ErrInvalidParameter)
+	})
+	t.Run("missing-writer", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		_, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, c, WithWriterGroup("audit", nil, &sync.Mutex{}))
+		require.Error(err)
+		assert.ErrorIs(err, ErrInvalidParameter)
+	})
+}
+
+func TestEventer_WithUnserializedStderr(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	c := EventerConfig{
+		Sinks: []SinkConfig{
+			{
+				Name:       "errors",
+				SinkType:   StderrSink,
+				EventTypes: []Type{ErrorType},
+				Format:     JSONSinkFormat,
+			},
+		},
+	}
+	assert, require := assert.New(t), require.New(t)
+	var buf bytes.Buffer
+	l := &sync.Mutex{}
+	e, err := NewEventer(hclog.NewNullLogger(), l, c, WithStderrWriter(&buf), WithUnserializedStderr())
+	require.NoError(err)
+
+	// the write doesn't wait for the serialization lock
+	l.Lock()
+	defer l.Unlock()
+	ev, err := newError("TestEventer_WithUnserializedStderr", fmt.Errorf("test error"), WithId("error-id"))
+	require.NoError(err)
+	require.NoError(e.writeError(ctx, ev))
+	assert.Contains(buf.String(), "error-id")
+}
+
+// testSlowWriter simulates the latency of writing to a descriptor.
+type testSlowWriter struct{}
+
+func (w testSlowWriter) Write(p []byte) (int, error) {
+	time.Sleep(50 * time.Microsecond)
+	return len(p), nil
+}
+
+// BenchmarkSerializedWriter_contention compares two groups of writers which
+// share a lock (like stderr sinks sharing the serialization lock) with groups
+// which each have their own lock (see WithWriterGroup).
+func BenchmarkSerializedWriter_contention(b *testing.B) {
+	p := []byte(`{"id":"benchmark","type":"observation","data":{"op":"benchmark"}}` + "\n")
+	bench := func(b *testing.B, writers [2]*serializedWriter) {
+		var next uint32
+		b.SetParallelism(4)
+		b.RunParallel(func(pb *testing.PB) {
+			// each goroutine writes to one of the groups
+			w := writers[atomic.AddUint32(&next, 1)%2]
+			for pb.Next() {
+				if _, err := w.Write(p); err != nil {
+					b.Error(err)
+				}
+			}
+		})
+	}
+	b.Run("shared-lock", func(b *testing.B) {
+		l := &sync.Mutex{}
+		bench(b, [2]*serializedWriter{{l: l, w: testSlowWriter{}}, {l: l, w: testSlowWriter{}}})
+	})
+	b.Run("lock-per-group", func(b *testing.B) {
+		bench(b, [2]*serializedWriter{{l: &sync.Mutex{}, w: testSlowWriter{}}, {l: &sync.Mutex{}, w: testSlowWriter{}}})
+	})
+}
+
+// BenchmarkEventer_unserializedStderr compares concurrent writers of a stderr
+// sink which share the serialization lock with writers which bypass it (see
+// WithUnserializedStderr).
+func BenchmarkEventer_unserializedStderr(b *testing.B) {
+	ctx := context.Background()
+	c := EventerConfig{
+		SysEventsEnabled: true,
+		Sinks: []SinkConfig{
+			{
+				Name:       "stderr",
+				SinkType:   StderrSink,
+				EventTypes: []Type{EveryType},
+				Format:     JSONSinkFormat,
+			},
+		},
+	}
+	bench := func(b *testing.B, opt ...Option) {
+		opt = append(opt, WithStderrWriter(testSlowWriter{}))
+		e, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, c, opt...)
+		require.NoError(b, err)
+		b.Cleanup(func() { _ = e.Close(ctx) })
+		b.SetParallelism(4)
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if err := e.writeSysEvent(ctx, &sysEvent{Id: "benchmark", Op: "benchmark", Data: map[string]interface{}{"msg": "benchmark"}}); err != nil {
+					b.Error(err)
+				}
+			}
+		})
+	}
+	b.Run("serialized", func(b *testing.B) { bench(b) })
+	b.Run("unserialized", func(b *testing.B) { bench(b, WithUnserializedStderr()) })
+}
@@ -2,6 +2,12 @@ package event
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
 	"time"
 )
 
@@ -10,13 +16,164 @@ type SinkConfig struct {
 	Name           string        `hcl:"name"`             // Name defines a name for the sink.
 	Description    string        `hcl:"description"`      // Description defines a description for the sink.
 	EventTypes     []Type        `hcl:"event_types"`      // EventTypes defines a list of event types that will be sent to the sink. See the docs for EventTypes for a list of accepted values.
-	SinkType       SinkType      `hcl:"sink_type"`        // SinkType defines the type of sink (StderrSink or FileSink)
-	Format         SinkFormat    `hcl:"format"`           // Format defines the format for the sink (JSONSinkFormat)
+	SinkType       SinkType      `hcl:"sink_type"`        // SinkType defines the type of sink (StderrSink, FileSink, KafkaSink, CloudWatchSink, GRPCSink or DiscardSink)
+	Format         SinkFormat    `hcl:"format"`           // Format defines the format for the sink (JSONSinkFormat, CEFSinkFormat, MsgpackSinkFormat, TextSinkFormat or ProtoSinkFormat)
 	Path           string        `hcl:"path"`             // Path defines the file path for the sink
 	FileName       string        `hcl:"file_name"`        // FileName defines the file name for the sink
 	RotateBytes    int           `hcl:"rotate_bytes"`     // RotateByes defines the number of bytes that should trigger rotation of a FileSink
 	RotateDuration time.Duration `hcl:"rotate_duration"`  // RotateDuration defines how often a FileSink should be rotated
 	RotateMaxFiles int           `hcl:"rotate_max_files"` // RotateMaxFiles defines how may historical rotated files should be kept for a FileSink
+
+	// WriterGroup is the writer group of a StderrSink (see WithWriterGroup).
+	// A sink in a group writes to the group's writer, serialized by the
+	// group's lock, rather than to stderr serialized by the eventer's
+	// serialization lock, so sinks in different groups don't contend with
+	// each other.  Sinks which write to the same descriptor must share a
+	// lock, otherwise their output may be interleaved, so they should be in
+	// the same group.  It defaults to stderr's group.
+	WriterGroup string `hcl:"writer_group"`
+
+	// FileNameTemplate is a text/template for the file names of a FileSink
+	// which writes each of its event types to a separate file, in place of
+	// FileName.  It's executed with an EventType field for each of the
+	// sink's event types (every type, for EveryType), so "{{.EventType}}.log"
+	// writes audit events to audit.log and observations to observation.log.
+	// The files share the sink's rotation policy, and each must have a
+	// distinct name, so the template must use {{.EventType}}.  A sink with a
+	// template can't have mirrors.
+	FileNameTemplate string `hcl:"file_name_template"`
+
+	// RotateSchedule rotates a FileSink at a wall clock time each day, in
+	// the local time zone, rather than after an elapsed duration.  It's in
+	// the form "daily@HH:MM", using a 24 hour clock, and "@daily" is the
+	// same as "daily@00:00".  The sink rotates once a day across DST
+	// transitions: a time skipped by the transition is rotated at the
+	// equivalent time after it, and a time repeated by it is only rotated
+	// once.  It may be combined with RotateBytes, RotateDuration and
+	// RotateMaxFiles.
+	RotateSchedule string `hcl:"rotate_schedule"`
+
+	// JSONPretty specifies that JSON formatted events should be indented for
+	// human readability.  Pretty output spans multiple lines per event, which
+	// makes it unfriendly for line oriented log shippers.
+	JSONPretty bool `hcl:"json_pretty"`
+
+	// SortedKeys specifies that JSON formatted events are written with the
+	// keys of every object of their payload sorted, following the event's
+	// created_at, event_type and payload fields, so identical events are
+	// written as identical bytes.  This makes the sink's output reproducible
+	// for golden tests and easier to diff.  It's only supported by the json
+	// format.
+	SortedKeys bool `hcl:"sorted_keys"`
+
+	// FieldNameMap renames the fields of the sink's events, for downstream
+	// systems which reserve field names like "type" or "id": a field named
+	// by a key of the map is written with the key's value as its name, so
+	// {"type": "audit_type"} writes an audit event's type as "audit_type".
+	// The fields of the event's payload are renamed, not the fields nested
+	// within them.  Fields must be renamed to distinct names, and an event
+	// which has a field of the same name as a renamed field isn't written to
+	// the sink.  It's only supported by the json format.
+	FieldNameMap map[string]string `hcl:"field_name_map"`
+
+	// TimestampFormat defines how the created at timestamp of the sink's
+	// events is serialized, for the json and text formats.  It defaults to
+	// the format's own representation, an RFC3339 string with nanoseconds.
+	// The cef, msgpack and proto formats define their own timestamps, so it
+	// can't be set for them.
+	TimestampFormat TimestampFormat `hcl:"timestamp_format"`
+
+	// AllowFilters are filter expressions which an event must match to be
+	// sent to the sink, and DenyFilters are expressions which will drop an
+	// event for the sink when matched.  Deny filters take precedence.
+	// Filters are evaluated against the event as it's written, for example:
+	// "/payload/op" matches "^session\."
+	AllowFilters []string `hcl:"allow_filters"`
+	DenyFilters  []string `hcl:"deny_filters"`
+
+	// OpPrefixes limits the sink to the events whose op is within the
+	// namespace of one of the prefixes.  Ops are dotted namespaces (see
+	// Op), and a prefix matches an op when it equals the op or is
+	// followed by a "." in it, so "auth" matches "auth.oidc.Callback".  A
+	// trailing ".*" is allowed and ignored: "auth.*" is the same as "auth".
+	// An empty prefix, or no prefixes, matches every event.  Audit events
+	// don't have an op, so they never match a non-empty prefix.
+	OpPrefixes []string `hcl:"op_prefixes"`
+
+	// ScopeIds limits the sink to the events of requests whose resource is
+	// in one of the scopes, so the events of an org can be routed to its
+	// own sink.  The scope is the request's scope id (see RequestInfo),
+	// which audit, observation and error events carry; a scope only matches
+	// its own id, so the ids of an org's projects must be listed to receive
+	// their events too.  Events without a scope (system events, and the
+	// events of requests which weren't authorized against a scope) bypass
+	// the filter and are sent to the sink, unless DropMissingScope is set,
+	// in which case they're dropped.  No scope ids matches every event.
+	ScopeIds         []string `hcl:"scope_ids"`
+	DropMissingScope bool     `hcl:"drop_missing_scope"`
+
+	// HeaderLine is written as the first line of each new file of a
+	// FileSink, for parsers which require a schema header or a BOM.  It's
+	// written when a file is created, including when the sink rotates or is
+	// reopened after its file was moved, but never to a file which already
+	// has contents.  It must be a single line, and its newline is added.
+	HeaderLine string `hcl:"header_line"`
+
+	// FileMode is the permission bits of a FileSink's files, which are
+	// applied whenever a file is created or reopened, regardless of the
+	// umask.  It defaults to 0600, so audit files aren't readable by other
+	// users.  DirMode is the permission bits of the sink's directory, and
+	// any missing parents, when NewEventer creates them (an existing
+	// directory's mode isn't changed).  It defaults to 0700.  The owner
+	// must be able to write the files and to create them in the directory.
+	FileMode os.FileMode `hcl:"file_mode"`
+	DirMode  os.FileMode `hcl:"dir_mode"`
+
+	// BatchSize is the number of events a FileSink buffers before writing
+	// them to its file together, which reduces the number of writes under a
+	// heavy load.  Buffered events are written when BatchFlushInterval has
+	// passed since the first of them was buffered (which defaults to a
+	// second), when the eventer's nodes are flushed and when it's closed, so
+	// they can be lost if the process exits abruptly.  Audit and error
+	// events are never buffered.  Batching is disabled when BatchSize is
+	// less than two.
+	BatchSize          int           `hcl:"batch_size"`
+	BatchFlushInterval time.Duration `hcl:"batch_flush_interval"`
+
+	// KafkaConfig defines the configuration for a KafkaSink and is required
+	// for that sink type.
+	KafkaConfig *KafkaSinkConfig `hcl:"kafka"`
+
+	// CloudWatchConfig defines the configuration for a CloudWatchSink and is
+	// required for that sink type.
+	CloudWatchConfig *CloudWatchSinkConfig `hcl:"cloudwatch"`
+
+	// GRPCConfig defines the configuration for a GRPCSink and is required for
+	// that sink type.
+	GRPCConfig *GRPCSinkConfig `hcl:"grpc"`
+
+	// SpoolPath is the file of a network sink's spool (kafka, cloudwatch and
+	// grpc sinks).  Best effort events which the sink fails to send, or
+	// which arrive while its circuit breaker is open, are written to the
+	// spool and replayed in order once the sink recovers, instead of being
+	// dropped.  Events with an enforced delivery are never spooled, since
+	// their failures are returned.  The spool is kept across restarts, and
+	// SpoolMaxBytes is its max size (which defaults to 64 MiB), beyond which
+	// the oldest events are dropped.
+	SpoolPath     string `hcl:"spool_path"`
+	SpoolMaxBytes int64  `hcl:"spool_max_bytes"`
+
+	// Mirrors are sinks which receive a best effort copy of every event
+	// written to this sink.  This sink remains authoritative: whether an
+	// event was delivered only depends on this sink.  Mirrors are written
+	// asynchronously from a queue, with retries, and their failures are only
+	// logged, so a failing mirror never fails or delays this sink.  An event
+	// is lost for a mirror when its queue is full or its retries are
+	// exhausted; events still queued when the eventer is closed are written
+	// before the mirror is closed.  Mirrors use this sink's event types,
+	// format and filters, so those can't be set for a mirror, and a mirror
+	// can't have mirrors of its own.
+	Mirrors []SinkConfig `hcl:"mirrors"`
 }
 
 func (sc *SinkConfig) validate() error {
@@ -25,11 +182,117 @@ func (sc *SinkConfig) validate() error {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 	if err := sc.Format.Validate(); err != nil {
+		return fmt.Errorf("%s: sink %q: %w", op, sc.Name, err)
+	}
+	if sc.JSONPretty && sc.Format != JSONSinkFormat {
+		return fmt.Errorf("%s: json pretty requires the %s format: %w", op, JSONSinkFormat, ErrInvalidParameter)
+	}
+	if sc.SortedKeys && sc.Format != JSONSinkFormat {
+		return fmt.Errorf("%s: sorted keys requires the %s format: %w", op, JSONSinkFormat, ErrInvalidParameter)
+	}
+	if err := sc.TimestampFormat.validate(); err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
-	if sc.SinkType == FileSink && sc.FileName == "" {
+	if len(sc.FieldNameMap) > 0 {
+		if sc.Format != JSONSinkFormat {
+			return fmt.Errorf("%s: field name maps are only supported by the %s format: %w", op, JSONSinkFormat, ErrInvalidParameter)
+		}
+		if err := validateFieldNameMap(sc.FieldNameMap); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if sc.TimestampFormat != DefaultTimestampFormat && sc.Format != JSONSinkFormat && sc.Format != TextSinkFormat {
+		return fmt.Errorf("%s: timestamp formats are only supported by the %s and %s formats: %w", op, JSONSinkFormat, TextSinkFormat, ErrInvalidParameter)
+	}
+	if sc.SinkType == FileSink && sc.FileName == "" && sc.FileNameTemplate == "" {
 		return fmt.Errorf("%s: missing sink file name: %w", op, ErrInvalidParameter)
 	}
+	if sc.WriterGroup != "" && sc.SinkType != StderrSink {
+		return fmt.Errorf("%s: writer groups are only supported by %s sinks: %w", op, StderrSink, ErrInvalidParameter)
+	}
+	if sc.FileNameTemplate != "" {
+		if err := sc.validateFileNameTemplate(); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if sc.HeaderLine != "" {
+		if sc.SinkType != FileSink {
+			return fmt.Errorf("%s: header lines are only supported by %s sinks: %w", op, FileSink, ErrInvalidParameter)
+		}
+		if strings.ContainsAny(sc.HeaderLine, "\r\n") {
+			return fmt.Errorf("%s: header line must be a single line: %w", op, ErrInvalidParameter)
+		}
+		if sc.Format == ProtoSinkFormat || sc.Format == MsgpackSinkFormat {
+			return fmt.Errorf("%s: header lines aren't supported by the binary %s format: %w", op, sc.Format, ErrInvalidParameter)
+		}
+	}
+	if sc.RotateSchedule != "" {
+		if sc.SinkType != FileSink {
+			return fmt.Errorf("%s: rotate schedules are only supported by %s sinks: %w", op, FileSink, ErrInvalidParameter)
+		}
+		if _, err := parseRotateSchedule(sc.RotateSchedule); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if err := sc.validateRotation(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if sc.FileMode != 0 || sc.DirMode != 0 {
+		if sc.SinkType != FileSink {
+			return fmt.Errorf("%s: file and directory modes are only supported by %s sinks: %w", op, FileSink, ErrInvalidParameter)
+		}
+		if sc.FileMode&^os.ModePerm != 0 || (sc.FileMode != 0 && sc.FileMode&0o200 == 0) {
+			return fmt.Errorf("%s: file mode %#o must only have permission bits, including the owner's write permission: %w", op, uint32(sc.FileMode), ErrInvalidParameter)
+		}
+		if sc.DirMode&^os.ModePerm != 0 || (sc.DirMode != 0 && sc.DirMode&0o300 != 0o300) {
+			return fmt.Errorf("%s: directory mode %#o must only have permission bits, including the owner's write and execute permissions: %w", op, uint32(sc.DirMode), ErrInvalidParameter)
+		}
+	}
+	if sc.BatchSize < 0 {
+		return fmt.Errorf("%s: batch size must not be negative: %w", op, ErrInvalidParameter)
+	}
+	if sc.BatchFlushInterval < 0 {
+		return fmt.Errorf("%s: batch flush interval must not be negative: %w", op, ErrInvalidParameter)
+	}
+	if sc.BatchSize > 0 && sc.SinkType != FileSink {
+		return fmt.Errorf("%s: batching is only supported by %s sinks: %w", op, FileSink, ErrInvalidParameter)
+	}
+	if sc.BatchFlushInterval > 0 && sc.BatchSize <= 1 {
+		return fmt.Errorf("%s: batch flush interval requires a batch size greater than one: %w", op, ErrInvalidParameter)
+	}
+	if sc.SinkType == KafkaSink {
+		if sc.KafkaConfig == nil {
+			return fmt.Errorf("%s: missing kafka config: %w", op, ErrInvalidParameter)
+		}
+		if err := sc.KafkaConfig.validate(); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if sc.SinkType == CloudWatchSink {
+		if sc.CloudWatchConfig == nil {
+			return fmt.Errorf("%s: missing cloudwatch config: %w", op, ErrInvalidParameter)
+		}
+		if err := sc.CloudWatchConfig.validate(); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if sc.SinkType == GRPCSink {
+		if sc.GRPCConfig == nil {
+			return fmt.Errorf("%s: missing grpc config: %w", op, ErrInvalidParameter)
+		}
+		if err := sc.GRPCConfig.validate(); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if sc.SpoolMaxBytes < 0 {
+		return fmt.Errorf("%s: spool max bytes must not be negative: %w", op, ErrInvalidParameter)
+	}
+	if sc.SpoolMaxBytes > 0 && sc.SpoolPath == "" {
+		return fmt.Errorf("%s: spool max bytes requires a spool path: %w", op, ErrInvalidParameter)
+	}
+	if sc.SpoolPath != "" && sc.SinkType != KafkaSink && sc.SinkType != CloudWatchSink && sc.SinkType != GRPCSink {
+		return fmt.Errorf("%s: spools are only supported by %s, %s and %s sinks: %w", op, KafkaSink, CloudWatchSink, GRPCSink, ErrInvalidParameter)
+	}
 	if sc.Name == "" {
 		return fmt.Errorf("%s: missing sink name: %w", op, ErrInvalidParameter)
 	}
@@ -40,6 +303,346 @@ func (sc *SinkConfig) validate() error {
 		if err := et.validate(); err != nil {
 			return fmt.Errorf("%s: %w", op, err)
 		}
+		if sc.Format == ProtoSinkFormat && et != AuditType {
+			return fmt.Errorf("%s: the %s format only supports %s events: %w", op, ProtoSinkFormat, AuditType, ErrInvalidParameter)
+		}
+	}
+	if sc.DropMissingScope && len(sc.ScopeIds) == 0 {
+		return fmt.Errorf("%s: drop missing scope requires scope ids: %w", op, ErrInvalidParameter)
+	}
+	if _, err := newSinkFilter(sc.AllowFilters, sc.DenyFilters, sc.OpPrefixes, sc.ScopeIds, sc.DropMissingScope); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	for i, m := range sc.Mirrors {
+		switch {
+		case len(m.EventTypes) > 0:
+			return fmt.Errorf("%s: mirror %d must not set event types: %w", op, i, ErrInvalidParameter)
+		case m.Format != "" || m.JSONPretty || m.SortedKeys || m.TimestampFormat != DefaultTimestampFormat || len(m.FieldNameMap) > 0:
+			return fmt.Errorf("%s: mirror %d must not set a format: %w", op, i, ErrInvalidParameter)
+		case len(m.AllowFilters) > 0 || len(m.DenyFilters) > 0 || len(m.OpPrefixes) > 0 || len(m.ScopeIds) > 0 || m.DropMissingScope:
+			return fmt.Errorf("%s: mirror %d must not set filters: %w", op, i, ErrInvalidParameter)
+		case len(m.Mirrors) > 0:
+			return fmt.Errorf("%s: mirror %d must not have mirrors: %w", op, i, ErrInvalidParameter)
+		}
+	}
+	for i, m := range sc.mirrorConfigs() {
+		if err := m.validate(); err != nil {
+			return fmt.Errorf("%s: mirror %d is invalid: %w", op, i, err)
+		}
+	}
+	return nil
+}
+
+// validateRotation returns an error when the sink's rotation settings can't
+// have the effect they're configured for: rotation is only supported by file
+// sinks, sizes, durations and counts can't be negative, and a max number of
+// files is only enforced when files are rotated, so it requires one of
+// RotateBytes, RotateDuration or RotateSchedule.
+func (sc *SinkConfig) validateRotation() error {
+	const op = "event.(SinkConfig).validateRotation"
+	switch {
+	case sc.RotateBytes < 0:
+		return fmt.Errorf("%s: sink %q: rotate bytes must not be negative: %w", op, sc.Name, ErrInvalidParameter)
+	case sc.RotateDuration < 0:
+		return fmt.Errorf("%s: sink %q: rotate duration must not be negative: %w", op, sc.Name, ErrInvalidParameter)
+	case sc.RotateMaxFiles < 0:
+		return fmt.Errorf("%s: sink %q: rotate max files must not be negative: %w", op, sc.Name, ErrInvalidParameter)
+	}
+	rotates := sc.RotateBytes > 0 || sc.RotateDuration > 0 || sc.RotateSchedule != ""
+	if (rotates || sc.RotateMaxFiles > 0) && sc.SinkType != FileSink {
+		return fmt.Errorf("%s: sink %q: rotation is only supported by %s sinks: %w", op, sc.Name, FileSink, ErrInvalidParameter)
+	}
+	if sc.RotateMaxFiles > 0 && !rotates {
+		return fmt.Errorf("%s: sink %q: rotate max files requires rotate bytes, a rotate duration or a rotate schedule, otherwise the file is never rotated: %w", op, sc.Name, ErrInvalidParameter)
+	}
+	return nil
+}
+
+// mirrorConfigs returns the configs of the sink's mirrors, with the event
+// types and format of the sink.
+func (sc *SinkConfig) mirrorConfigs() []SinkConfig {
+	if len(sc.Mirrors) == 0 {
+		return nil
+	}
+	mirrors := make([]SinkConfig, 0, len(sc.Mirrors))
+	for _, m := range sc.Mirrors {
+		m.EventTypes = sc.EventTypes
+		m.Format = sc.Format
+		m.JSONPretty = sc.JSONPretty
+		m.SortedKeys = sc.SortedKeys
+		m.TimestampFormat = sc.TimestampFormat
+		m.FieldNameMap = sc.FieldNameMap
+		mirrors = append(mirrors, m)
+	}
+	return mirrors
+}
+
+// fileNameTemplateData is the data a sink's FileNameTemplate is executed with.
+type fileNameTemplateData struct {
+	EventType Type
+}
+
+// validateFileNameTemplate ensures the sink's FileNameTemplate can be used in
+// place of its FileName, and expands to a distinct file name for each type.
+func (sc *SinkConfig) validateFileNameTemplate() error {
+	const op = "event.(SinkConfig).validateFileNameTemplate"
+	switch {
+	case sc.SinkType != FileSink:
+		return fmt.Errorf("%s: file name templates are only supported by %s sinks: %w", op, FileSink, ErrInvalidParameter)
+	case sc.FileName != "":
+		return fmt.Errorf("%s: file name and file name template are mutually exclusive: %w", op, ErrInvalidParameter)
+	case len(sc.Mirrors) > 0:
+		return fmt.Errorf("%s: a sink with a file name template must not have mirrors: %w", op, ErrInvalidParameter)
+	}
+	seen := map[string]Type{}
+	for _, t := range []Type{AuditType, ObservationType, ErrorType, SystemType} {
+		name, err := sc.templateFileName(t)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if name == "" {
+			return fmt.Errorf("%s: file name template expands to an empty file name for %s events: %w", op, t, ErrInvalidParameter)
+		}
+		if other, found := seen[name]; found {
+			return fmt.Errorf("%s: file name template expands to %q for both %s and %s events (it must use {{.EventType}}): %w", op, name, other, t, ErrInvalidParameter)
+		}
+		seen[name] = t
+	}
+	return nil
+}
+
+// templateFileName returns the file name of the sink's FileNameTemplate for
+// events of type t.
+func (sc *SinkConfig) templateFileName(t Type) (string, error) {
+	const op = "event.(SinkConfig).templateFileName"
+	tmpl, err := template.New("file_name_template").Option("missingkey=error").Parse(sc.FileNameTemplate)
+	if err != nil {
+		return "", fmt.Errorf("%s: invalid file name template: %s: %w", op, err, ErrInvalidParameter)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, fileNameTemplateData{EventType: t}); err != nil {
+		return "", fmt.Errorf("%s: invalid file name template: %s: %w", op, err, ErrInvalidParameter)
+	}
+	return b.String(), nil
+}
+
+// expandFileNameTemplate returns the sink's configs once its FileNameTemplate
+// is expanded: a FileSink config for each of its event types, with the
+// type's file name.  A sink without a template is returned as is.
+func (sc *SinkConfig) expandFileNameTemplate() ([]SinkConfig, error) {
+	const op = "event.(SinkConfig).expandFileNameTemplate"
+	if sc.FileNameTemplate == "" {
+		return []SinkConfig{*sc}, nil
+	}
+	types := sc.EventTypes
+	for _, t := range sc.EventTypes {
+		if t == EveryType {
+			types = []Type{AuditType, ObservationType, ErrorType, SystemType}
+			break
+		}
+	}
+	expanded := make([]SinkConfig, 0, len(types))
+	for _, t := range types {
+		fileName, err := sc.templateFileName(t)
+		if err != nil {
+			return nil, fmt.Errorf("%s: sink %q: %w", op, sc.Name, err)
+		}
+		c := *sc
+		c.EventTypes = []Type{t}
+		c.FileName = fileName
+		c.FileNameTemplate = ""
+		expanded = append(expanded, c)
+	}
+	return expanded, nil
+}
+
+// expandSinks returns the sinks with each sink's FileNameTemplate expanded
+// (see expandFileNameTemplate).
+func expandSinks(sinks []SinkConfig) ([]SinkConfig, error) {
+	const op = "event.expandSinks"
+	expanded := make([]SinkConfig, 0, len(sinks))
+	for _, s := range sinks {
+		configs, err := s.expandFileNameTemplate()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		expanded = append(expanded, configs...)
+	}
+	return expanded, nil
+}
+
+// validateEventTypes ensures the sink won't receive the same event type more
+// than once, which would happen if a type is listed twice or is listed along
+// with EveryType.
+func (sc *SinkConfig) validateEventTypes() error {
+	const op = "event.(SinkConfig).validateEventTypes"
+	seen := make(map[Type]bool, len(sc.EventTypes))
+	for _, et := range sc.EventTypes {
+		if seen[et] {
+			return fmt.Errorf("%s: sink %q lists event type %s more than once: %w", op, sc.Name, et, ErrInvalidParameter)
+		}
+		seen[et] = true
+	}
+	if seen[EveryType] && len(seen) > 1 {
+		return fmt.Errorf("%s: sink %q receives event types through both %s and an explicit type: %w", op, sc.Name, EveryType, ErrInvalidParameter)
+	}
+	return nil
+}
+
+// isLocal returns true when the sink writes to the local host rather than
+// over the network.
+func (sc *SinkConfig) isLocal() bool {
+	switch sc.SinkType {
+	case FileSink, StderrSink:
+		return true
+	default:
+		return false
+	}
+}
+
+// outputTarget returns an identifier for where the sink writes its events.
+// File sinks which resolve to stderr share stderr's target.
+func (sc *SinkConfig) outputTarget() string {
+	switch sc.SinkType {
+	case StderrSink:
+		if sc.WriterGroup != "" {
+			return fmt.Sprintf("%s:%s", StderrSink, sc.WriterGroup)
+		}
+		return string(StderrSink)
+	case DiscardSink:
+		return string(DiscardSink)
+	case KafkaSink:
+		if sc.KafkaConfig == nil {
+			return string(KafkaSink)
+		}
+		brokers := make([]string, len(sc.KafkaConfig.Brokers))
+		copy(brokers, sc.KafkaConfig.Brokers)
+		sort.Strings(brokers)
+		return fmt.Sprintf("%s:%s/%s", KafkaSink, strings.Join(brokers, ","), sc.KafkaConfig.Topic)
+	case CloudWatchSink:
+		if sc.CloudWatchConfig == nil {
+			return string(CloudWatchSink)
+		}
+		return fmt.Sprintf("%s:%s/%s/%s", CloudWatchSink, sc.CloudWatchConfig.Region, sc.CloudWatchConfig.LogGroup, sc.CloudWatchConfig.LogStream)
+	case GRPCSink:
+		if sc.GRPCConfig == nil {
+			return string(GRPCSink)
+		}
+		return fmt.Sprintf("%s:%s", GRPCSink, sc.GRPCConfig.Address)
+	default:
+		p := filepath.Join(sc.Path, sc.FileName)
+		if abs, err := filepath.Abs(p); err == nil {
+			p = abs
+		}
+		switch p {
+		case "/dev/stderr", "/dev/fd/2", "/proc/self/fd/2":
+			return string(StderrSink)
+		}
+		return fmt.Sprintf("%s:%s", FileSink, p)
+	}
+}
+
+// formatKey returns the key of the sink's formatted events, which identifies
+// the formatter node shared by the sinks with the same format settings.
+func (sc *SinkConfig) formatKey() string {
+	key := string(sc.Format)
+	if sc.JSONPretty {
+		key = jsonPrettyFormat
+	}
+	if sc.TimestampFormat == EpochMillisTimestampFormat {
+		key += epochMillisFormatSuffix
+	}
+	if sc.SortedKeys {
+		key += sortedKeysFormatSuffix
+	}
+	return key
+}
+
+// spoolTarget returns the output target of the sink's spool, which is a file
+// like a file sink's, or an empty string when it doesn't have one.
+func (sc *SinkConfig) spoolTarget() string {
+	if sc.SpoolPath == "" {
+		return ""
+	}
+	p := sc.SpoolPath
+	if abs, err := filepath.Abs(p); err == nil {
+		p = abs
+	}
+	return fmt.Sprintf("%s:%s", FileSink, p)
+}
+
+// sortedSinks returns a copy of the sinks sorted by name and then by output
+// target, so sinks are registered and compared in a stable order regardless
+// of the order they're configured in.
+func sortedSinks(sinks []SinkConfig) []SinkConfig {
+	sorted := make([]SinkConfig, len(sinks))
+	copy(sorted, sinks)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Name != sorted[j].Name {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].outputTarget() < sorted[j].outputTarget()
+	})
+	return sorted
+}
+
+// probe is a read-only version of preflight: it ensures a FileSink's directory,
+// or the nearest existing directory it would be created in, is writable without
+// creating anything.
+func (sc *SinkConfig) probe() error {
+	const op = "event.(SinkConfig).probe"
+	if sc.SinkType != FileSink {
+		return nil
+	}
+	dir := filepath.Dir(filepath.Join(sc.Path, sc.FileName))
+	for {
+		fi, err := os.Stat(dir)
+		switch {
+		case os.IsNotExist(err):
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				return fmt.Errorf("%s: sink %q: no existing parent directory for %s: %w", op, sc.Name, dir, ErrUnwritablePath)
+			}
+			dir = parent
+			continue
+		case err != nil:
+			return fmt.Errorf("%s: sink %q: unable to stat directory %s: %s: %w", op, sc.Name, dir, err, ErrUnwritablePath)
+		case !fi.IsDir():
+			return fmt.Errorf("%s: sink %q: %s is not a directory: %w", op, sc.Name, dir, ErrUnwritablePath)
+		}
+		if err := dirWritable(dir); err != nil {
+			return fmt.Errorf("%s: sink %q: directory %s is not writable: %s: %w", op, sc.Name, dir, err, ErrUnwritablePath)
+		}
+		return nil
+	}
+}
+
+// preflight ensures a FileSink's directory exists and is writable, so an
+// unwritable path is reported when the Eventer is created rather than when the
+// first event is written.  Like the FileSink, it will create a missing
+// directory.
+func (sc *SinkConfig) preflight() error {
+	const op = "event.(SinkConfig).preflight"
+	if sc.SinkType != FileSink {
+		return nil
+	}
+	dir := filepath.Dir(filepath.Join(sc.Path, sc.FileName))
+	fi, err := os.Stat(dir)
+	switch {
+	case os.IsNotExist(err):
+		if err := mkdirAll(dir, sc.DirMode); err != nil {
+			return fmt.Errorf("%s: sink %q: unable to create directory %s: %s: %w", op, sc.Name, dir, err, ErrUnwritablePath)
+		}
+	case err != nil:
+		return fmt.Errorf("%s: sink %q: unable to stat directory %s: %s: %w", op, sc.Name, dir, err, ErrUnwritablePath)
+	case !fi.IsDir():
+		return fmt.Errorf("%s: sink %q: %s is not a directory: %w", op, sc.Name, dir, ErrUnwritablePath)
+	}
+	f, err := ioutil.TempFile(dir, ".preflight-")
+	if err != nil {
+		return fmt.Errorf("%s: sink %q: directory %s is not writable: %s: %w", op, sc.Name, dir, err, ErrUnwritablePath)
 	}
+	f.Close()
+	os.Remove(f.Name())
 	return nil
 }
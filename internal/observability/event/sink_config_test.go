@@ -1,8 +1,15 @@
 package event
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/go-hclog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -105,6 +112,648 @@ func TestSinkConfig_validate(t *testing.T) {
 			wantErrIs:       ErrInvalidParameter,
 			wantErrContains: "missing sink file name",
 		},
+		{
+			name: "json-pretty-with-cef-format",
+			sc: SinkConfig{
+				Name:       "cef",
+				EventTypes: []Type{EveryType},
+				SinkType:   StderrSink,
+				Format:     CEFSinkFormat,
+				JSONPretty: true,
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "json pretty requires",
+		},
+		{
+			name: "sorted-keys-with-text-format",
+			sc: SinkConfig{
+				Name:       "text",
+				EventTypes: []Type{EveryType},
+				SinkType:   StderrSink,
+				Format:     TextSinkFormat,
+				SortedKeys: true,
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "sorted keys requires",
+		},
+		{
+			name: "spool-on-file-sink",
+			sc: SinkConfig{
+				Name:       "file",
+				EventTypes: []Type{EveryType},
+				SinkType:   FileSink,
+				Format:     JSONSinkFormat,
+				FileName:   "events.log",
+				SpoolPath:  "/var/spool/boundary/events",
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "spools are only supported by",
+		},
+		{
+			name: "spool-max-bytes-without-path",
+			sc: SinkConfig{
+				Name:          "grpc",
+				EventTypes:    []Type{EveryType},
+				SinkType:      GRPCSink,
+				Format:        JSONSinkFormat,
+				GRPCConfig:    &GRPCSinkConfig{Address: "collector:9400"},
+				SpoolMaxBytes: 1024,
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "spool max bytes requires a spool path",
+		},
+		{
+			name: "negative-spool-max-bytes",
+			sc: SinkConfig{
+				Name:          "grpc",
+				EventTypes:    []Type{EveryType},
+				SinkType:      GRPCSink,
+				Format:        JSONSinkFormat,
+				GRPCConfig:    &GRPCSinkConfig{Address: "collector:9400"},
+				SpoolPath:     "/var/spool/boundary/events",
+				SpoolMaxBytes: -1,
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "spool max bytes must not be negative",
+		},
+		{
+			name: "invalid-timestamp-format",
+			sc: SinkConfig{
+				Name:            "stderr",
+				EventTypes:      []Type{EveryType},
+				SinkType:        StderrSink,
+				Format:          JSONSinkFormat,
+				TimestampFormat: "epoch_seconds",
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "not a valid timestamp format",
+		},
+		{
+			name: "timestamp-format-with-cef-format",
+			sc: SinkConfig{
+				Name:            "cef",
+				EventTypes:      []Type{EveryType},
+				SinkType:        StderrSink,
+				Format:          CEFSinkFormat,
+				TimestampFormat: EpochMillisTimestampFormat,
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "timestamp formats are only supported",
+		},
+		{
+			name: "file-name-template-with-file-name",
+			sc: SinkConfig{
+				Name:             "file",
+				EventTypes:       []Type{EveryType},
+				SinkType:         FileSink,
+				Format:           JSONSinkFormat,
+				FileName:         "tmp.file",
+				FileNameTemplate: "{{.EventType}}.log",
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "mutually exclusive",
+		},
+		{
+			name: "file-name-template-with-stderr-sink",
+			sc: SinkConfig{
+				Name:             "stderr",
+				EventTypes:       []Type{EveryType},
+				SinkType:         StderrSink,
+				Format:           JSONSinkFormat,
+				FileNameTemplate: "{{.EventType}}.log",
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "file name templates are only supported",
+		},
+		{
+			name: "file-name-template-without-event-type",
+			sc: SinkConfig{
+				Name:             "file",
+				EventTypes:       []Type{AuditType},
+				SinkType:         FileSink,
+				Format:           JSONSinkFormat,
+				FileNameTemplate: "events.log",
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "it must use {{.EventType}}",
+		},
+		{
+			name: "file-name-template-unknown-field",
+			sc: SinkConfig{
+				Name:             "file",
+				EventTypes:       []Type{AuditType},
+				SinkType:         FileSink,
+				Format:           JSONSinkFormat,
+				FileNameTemplate: "{{.EventType}}-{{.Host}}.log",
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "invalid file name template",
+		},
+		{
+			name: "file-name-template-unparsable",
+			sc: SinkConfig{
+				Name:             "file",
+				EventTypes:       []Type{AuditType},
+				SinkType:         FileSink,
+				Format:           JSONSinkFormat,
+				FileNameTemplate: "{{.EventType.log",
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "invalid file name template",
+		},
+		{
+			name: "file-name-template-with-mirrors",
+			sc: SinkConfig{
+				Name:             "file",
+				EventTypes:       []Type{AuditType},
+				SinkType:         FileSink,
+				Format:           JSONSinkFormat,
+				FileNameTemplate: "{{.EventType}}.log",
+				Mirrors:          []SinkConfig{{Name: "mirror", SinkType: StderrSink}},
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "must not have mirrors",
+		},
+		{
+			name: "writer-group-with-file-sink",
+			sc: SinkConfig{
+				Name:        "file",
+				EventTypes:  []Type{EveryType},
+				SinkType:    FileSink,
+				Format:      JSONSinkFormat,
+				FileName:    "tmp.file",
+				WriterGroup: "group",
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "writer groups are only supported",
+		},
+		{
+			name: "missing-kafka-config",
+			sc: SinkConfig{
+				Name:       "kafka",
+				EventTypes: []Type{EveryType},
+				SinkType:   KafkaSink,
+				Format:     JSONSinkFormat,
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "missing kafka config",
+		},
+		{
+			name: "invalid-kafka-config",
+			sc: SinkConfig{
+				Name:        "kafka",
+				EventTypes:  []Type{EveryType},
+				SinkType:    KafkaSink,
+				Format:      JSONSinkFormat,
+				KafkaConfig: &KafkaSinkConfig{Topic: "events"},
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "missing brokers",
+		},
+		{
+			name: "missing-cloudwatch-config",
+			sc: SinkConfig{
+				Name:       "cloudwatch",
+				EventTypes: []Type{EveryType},
+				SinkType:   CloudWatchSink,
+				Format:     JSONSinkFormat,
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "missing cloudwatch config",
+		},
+		{
+			name: "invalid-cloudwatch-config",
+			sc: SinkConfig{
+				Name:             "cloudwatch",
+				EventTypes:       []Type{EveryType},
+				SinkType:         CloudWatchSink,
+				Format:           JSONSinkFormat,
+				CloudWatchConfig: &CloudWatchSinkConfig{LogGroup: "boundary", Region: "us-east-1"},
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "missing log stream",
+		},
+		{
+			name: "negative-batch-size",
+			sc: SinkConfig{
+				Name:       "file",
+				EventTypes: []Type{EveryType},
+				SinkType:   FileSink,
+				FileName:   "tmp.file",
+				Format:     JSONSinkFormat,
+				BatchSize:  -1,
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "batch size must not be negative",
+		},
+		{
+			name: "batching-stderr",
+			sc: SinkConfig{
+				Name:       "stderr",
+				EventTypes: []Type{EveryType},
+				SinkType:   StderrSink,
+				Format:     JSONSinkFormat,
+				BatchSize:  10,
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "batching is only supported by file sinks",
+		},
+		{
+			name: "batch-interval-without-size",
+			sc: SinkConfig{
+				Name:               "file",
+				EventTypes:         []Type{EveryType},
+				SinkType:           FileSink,
+				FileName:           "tmp.file",
+				Format:             JSONSinkFormat,
+				BatchFlushInterval: time.Second,
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "batch flush interval requires a batch size",
+		},
+		{
+			name: "valid-batching",
+			sc: SinkConfig{
+				Name:               "file",
+				EventTypes:         []Type{EveryType},
+				SinkType:           FileSink,
+				FileName:           "tmp.file",
+				Format:             JSONSinkFormat,
+				BatchSize:          10,
+				BatchFlushInterval: time.Second,
+			},
+		},
+		{
+			name: "field-name-map-not-json",
+			sc: SinkConfig{
+				Name:         "stderr",
+				EventTypes:   []Type{EveryType},
+				SinkType:     StderrSink,
+				Format:       TextSinkFormat,
+				FieldNameMap: map[string]string{"type": "event_type"},
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "field name maps are only supported by the json format",
+		},
+		{
+			name: "field-name-map-collision",
+			sc: SinkConfig{
+				Name:         "stderr",
+				EventTypes:   []Type{EveryType},
+				SinkType:     StderrSink,
+				Format:       JSONSinkFormat,
+				FieldNameMap: map[string]string{"type": "kind", "id": "kind"},
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: `fields "id" and "type" are both renamed to "kind"`,
+		},
+		{
+			name: "field-name-map-empty-name",
+			sc: SinkConfig{
+				Name:         "stderr",
+				EventTypes:   []Type{EveryType},
+				SinkType:     StderrSink,
+				Format:       JSONSinkFormat,
+				FieldNameMap: map[string]string{"type": ""},
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: `missing new name of field "type"`,
+		},
+		{
+			name: "valid-field-name-map",
+			sc: SinkConfig{
+				Name:         "stderr",
+				EventTypes:   []Type{EveryType},
+				SinkType:     StderrSink,
+				Format:       JSONSinkFormat,
+				FieldNameMap: map[string]string{"type": "id", "id": "type"},
+			},
+		},
+		{
+			name: "drop-missing-scope-without-scope-ids",
+			sc: SinkConfig{
+				Name:             "stderr",
+				EventTypes:       []Type{EveryType},
+				SinkType:         StderrSink,
+				Format:           JSONSinkFormat,
+				DropMissingScope: true,
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "drop missing scope requires scope ids",
+		},
+		{
+			name: "empty-scope-id",
+			sc: SinkConfig{
+				Name:       "stderr",
+				EventTypes: []Type{EveryType},
+				SinkType:   StderrSink,
+				Format:     JSONSinkFormat,
+				ScopeIds:   []string{""},
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "empty scope id",
+		},
+		{
+			name: "valid-scope-ids",
+			sc: SinkConfig{
+				Name:             "stderr",
+				EventTypes:       []Type{EveryType},
+				SinkType:         StderrSink,
+				Format:           JSONSinkFormat,
+				ScopeIds:         []string{"o_1234567890"},
+				DropMissingScope: true,
+			},
+		},
+		{
+			name: "proto-format-with-non-audit-type",
+			sc: SinkConfig{
+				Name:       "proto",
+				EventTypes: []Type{AuditType, ObservationType},
+				SinkType:   FileSink,
+				FileName:   "tmp.file",
+				Format:     ProtoSinkFormat,
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "the proto format only supports audit events",
+		},
+		{
+			name: "file-mode-for-stderr",
+			sc: SinkConfig{
+				Name:       "stderr",
+				EventTypes: []Type{EveryType},
+				SinkType:   StderrSink,
+				Format:     JSONSinkFormat,
+				FileMode:   0o600,
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "only supported by file sinks",
+		},
+		{
+			name: "file-mode-not-permission-bits",
+			sc: SinkConfig{
+				Name:       "file",
+				EventTypes: []Type{EveryType},
+				SinkType:   FileSink,
+				FileName:   "tmp.file",
+				Format:     JSONSinkFormat,
+				FileMode:   os.ModeSetuid | 0o600,
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "file mode",
+		},
+		{
+			name: "file-mode-not-owner-writable",
+			sc: SinkConfig{
+				Name:       "file",
+				EventTypes: []Type{EveryType},
+				SinkType:   FileSink,
+				FileName:   "tmp.file",
+				Format:     JSONSinkFormat,
+				FileMode:   0o444,
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "owner's write permission",
+		},
+		{
+			name: "dir-mode-not-owner-searchable",
+			sc: SinkConfig{
+				Name:       "file",
+				EventTypes: []Type{EveryType},
+				SinkType:   FileSink,
+				FileName:   "tmp.file",
+				Format:     JSONSinkFormat,
+				DirMode:    0o600,
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "directory mode",
+		},
+		{
+			name: "valid-modes",
+			sc: SinkConfig{
+				Name:       "file",
+				EventTypes: []Type{EveryType},
+				SinkType:   FileSink,
+				FileName:   "tmp.file",
+				Format:     JSONSinkFormat,
+				FileMode:   0o640,
+				DirMode:    0o750,
+			},
+		},
+		{
+			name: "header-line-for-stderr",
+			sc: SinkConfig{
+				Name:       "stderr",
+				EventTypes: []Type{EveryType},
+				SinkType:   StderrSink,
+				Format:     JSONSinkFormat,
+				HeaderLine: "#schema: v1",
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "header lines are only supported by file sinks",
+		},
+		{
+			name: "multi-line-header-line",
+			sc: SinkConfig{
+				Name:       "file",
+				EventTypes: []Type{EveryType},
+				SinkType:   FileSink,
+				FileName:   "tmp.file",
+				Format:     JSONSinkFormat,
+				HeaderLine: "#schema: v1\n#other",
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "single line",
+		},
+		{
+			name: "header-line-for-binary-format",
+			sc: SinkConfig{
+				Name:       "file",
+				EventTypes: []Type{EveryType},
+				SinkType:   FileSink,
+				FileName:   "tmp.file",
+				Format:     MsgpackSinkFormat,
+				HeaderLine: "#schema: v1",
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "binary msgpack format",
+		},
+		{
+			name: "rotate-schedule-for-stderr",
+			sc: SinkConfig{
+				Name:           "stderr",
+				EventTypes:     []Type{EveryType},
+				SinkType:       StderrSink,
+				Format:         JSONSinkFormat,
+				RotateSchedule: "daily@00:00",
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "rotate schedules are only supported by file sinks",
+		},
+		{
+			name: "invalid-rotate-schedule",
+			sc: SinkConfig{
+				Name:           "file",
+				EventTypes:     []Type{EveryType},
+				SinkType:       FileSink,
+				FileName:       "tmp.file",
+				Format:         JSONSinkFormat,
+				RotateSchedule: "daily@24:00",
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "invalid hour",
+		},
+		{
+			name: "valid-rotate-schedule",
+			sc: SinkConfig{
+				Name:           "file",
+				EventTypes:     []Type{EveryType},
+				SinkType:       FileSink,
+				FileName:       "tmp.file",
+				Format:         JSONSinkFormat,
+				RotateSchedule: "daily@02:30",
+			},
+		},
+		{
+			name: "negative-rotate-bytes",
+			sc: SinkConfig{
+				Name:        "file",
+				EventTypes:  []Type{EveryType},
+				SinkType:    FileSink,
+				FileName:    "tmp.file",
+				Format:      JSONSinkFormat,
+				RotateBytes: -1,
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: `sink "file": rotate bytes must not be negative`,
+		},
+		{
+			name: "negative-rotate-duration",
+			sc: SinkConfig{
+				Name:           "file",
+				EventTypes:     []Type{EveryType},
+				SinkType:       FileSink,
+				FileName:       "tmp.file",
+				Format:         JSONSinkFormat,
+				RotateDuration: -time.Hour,
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: `sink "file": rotate duration must not be negative`,
+		},
+		{
+			name: "negative-rotate-max-files",
+			sc: SinkConfig{
+				Name:           "file",
+				EventTypes:     []Type{EveryType},
+				SinkType:       FileSink,
+				FileName:       "tmp.file",
+				Format:         JSONSinkFormat,
+				RotateBytes:    1024,
+				RotateMaxFiles: -1,
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: `sink "file": rotate max files must not be negative`,
+		},
+		{
+			name: "rotate-max-files-without-trigger",
+			sc: SinkConfig{
+				Name:           "file",
+				EventTypes:     []Type{EveryType},
+				SinkType:       FileSink,
+				FileName:       "tmp.file",
+				Format:         JSONSinkFormat,
+				RotateMaxFiles: 3,
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: `sink "file": rotate max files requires rotate bytes, a rotate duration or a rotate schedule`,
+		},
+		{
+			name: "rotate-bytes-for-stderr",
+			sc: SinkConfig{
+				Name:        "stderr",
+				EventTypes:  []Type{EveryType},
+				SinkType:    StderrSink,
+				Format:      JSONSinkFormat,
+				RotateBytes: 1024,
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: `sink "stderr": rotation is only supported by file sinks`,
+		},
+		{
+			name: "rotate-max-files-for-stderr",
+			sc: SinkConfig{
+				Name:           "stderr",
+				EventTypes:     []Type{EveryType},
+				SinkType:       StderrSink,
+				Format:         JSONSinkFormat,
+				RotateMaxFiles: 3,
+			},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: `sink "stderr": rotation is only supported by file sinks`,
+		},
+		{
+			name: "valid-rotate-max-files",
+			sc: SinkConfig{
+				Name:           "file",
+				EventTypes:     []Type{EveryType},
+				SinkType:       FileSink,
+				FileName:       "tmp.file",
+				Format:         JSONSinkFormat,
+				RotateDuration: time.Hour,
+				RotateMaxFiles: 3,
+			},
+		},
+		{
+			name: "valid-rotate-max-files-with-schedule",
+			sc: SinkConfig{
+				Name:           "file",
+				EventTypes:     []Type{EveryType},
+				SinkType:       FileSink,
+				FileName:       "tmp.file",
+				Format:         JSONSinkFormat,
+				RotateSchedule: "daily@02:30",
+				RotateMaxFiles: 3,
+			},
+		},
+		{
+			name: "valid-timestamp-format",
+			sc: SinkConfig{
+				Name:            "text",
+				EventTypes:      []Type{EveryType},
+				SinkType:        StderrSink,
+				Format:          TextSinkFormat,
+				TimestampFormat: EpochMillisTimestampFormat,
+			},
+		},
+		{
+			name: "valid-file-name-template",
+			sc: SinkConfig{
+				Name:             "file",
+				EventTypes:       []Type{EveryType},
+				SinkType:         FileSink,
+				Format:           JSONSinkFormat,
+				FileNameTemplate: "boundary-{{.EventType}}.log",
+			},
+		},
+		{
+			name: "valid-kafka",
+			sc: SinkConfig{
+				Name:        "kafka",
+				EventTypes:  []Type{EveryType},
+				SinkType:    KafkaSink,
+				Format:      JSONSinkFormat,
+				KafkaConfig: &KafkaSinkConfig{Brokers: []string{"localhost:9092"}, Topic: "events"},
+			},
+		},
+		{
+			name: "valid-cloudwatch",
+			sc: SinkConfig{
+				Name:             "cloudwatch",
+				EventTypes:       []Type{AuditType},
+				SinkType:         CloudWatchSink,
+				Format:           JSONSinkFormat,
+				CloudWatchConfig: &CloudWatchSinkConfig{LogGroup: "boundary", LogStream: "controller", Region: "us-east-1"},
+			},
+		},
 		{
 			name: "valid",
 			sc: SinkConfig{
@@ -132,3 +781,191 @@ func TestSinkConfig_validate(t *testing.T) {
 		})
 	}
 }
+
+func TestSinkConfig_expandFileNameTemplate(t *testing.T) {
+	t.Parallel()
+	t.Run("without-template", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		sc := SinkConfig{
+			Name:       "file",
+			EventTypes: []Type{EveryType},
+			SinkType:   FileSink,
+			Format:     JSONSinkFormat,
+			FileName:   "events.log",
+		}
+		got, err := sc.expandFileNameTemplate()
+		require.NoError(err)
+		assert.Equal([]SinkConfig{sc}, got)
+	})
+	t.Run("explicit-types", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		sc := SinkConfig{
+			Name:             "file",
+			EventTypes:       []Type{AuditType, ObservationType},
+			SinkType:         FileSink,
+			Format:           JSONSinkFormat,
+			Path:             "/var/log/boundary",
+			FileNameTemplate: "{{.EventType}}.log",
+			RotateBytes:      1024,
+			RotateMaxFiles:   3,
+		}
+		got, err := sc.expandFileNameTemplate()
+		require.NoError(err)
+		require.Len(got, 2)
+		for i, want := range []struct {
+			t        Type
+			fileName string
+		}{
+			{t: AuditType, fileName: "audit.log"},
+			{t: ObservationType, fileName: "observation.log"},
+		} {
+			assert.Equal("file", got[i].Name)
+			assert.Equal([]Type{want.t}, got[i].EventTypes)
+			assert.Equal(want.fileName, got[i].FileName)
+			assert.Empty(got[i].FileNameTemplate)
+			assert.Equal("/var/log/boundary", got[i].Path)
+			assert.Equal(1024, got[i].RotateBytes)
+			assert.Equal(3, got[i].RotateMaxFiles)
+			assert.NoError(got[i].validate())
+		}
+		// the sink's own event types aren't changed
+		assert.Equal([]Type{AuditType, ObservationType}, sc.EventTypes)
+	})
+	t.Run("every-type", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		sc := SinkConfig{
+			Name:             "file",
+			EventTypes:       []Type{EveryType},
+			SinkType:         FileSink,
+			Format:           JSONSinkFormat,
+			FileNameTemplate: "{{.EventType}}.log",
+		}
+		got, err := sc.expandFileNameTemplate()
+		require.NoError(err)
+		var fileNames []string
+		for _, c := range got {
+			fileNames = append(fileNames, c.FileName)
+		}
+		assert.Equal([]string{"audit.log", "observation.log", "error.log", "system.log"}, fileNames)
+	})
+}
+
+func TestEventer_FileNameTemplate(t *testing.T) {
+	t.Parallel()
+	assert, require := assert.New(t), require.New(t)
+	dir := t.TempDir()
+	c := EventerConfig{
+		AuditEnabled: true,
+		Sinks: []SinkConfig{
+			{
+				Name:             "per-type",
+				EventTypes:       []Type{AuditType, ErrorType},
+				SinkType:         FileSink,
+				Format:           JSONSinkFormat,
+				Path:             dir,
+				FileNameTemplate: "{{.EventType}}.log",
+			},
+		},
+	}
+	e, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, c)
+	require.NoError(err)
+	t.Cleanup(func() { _ = e.Close(context.Background()) })
+
+	ctx := context.Background()
+	a, err := newAudit("TestEventer_FileNameTemplate", WithId("audit-id"), WithFlush())
+	require.NoError(err)
+	require.NoError(e.writeAudit(ctx, a))
+	ev, err := newError("TestEventer_FileNameTemplate", fmt.Errorf("test error"), WithId("error-id"))
+	require.NoError(err)
+	require.NoError(e.writeError(ctx, ev))
+
+	auditFile, err := os.ReadFile(filepath.Join(dir, "audit.log"))
+	require.NoError(err)
+	assert.Contains(string(auditFile), "audit-id")
+	assert.NotContains(string(auditFile), "error-id")
+	errFile, err := os.ReadFile(filepath.Join(dir, "error.log"))
+	require.NoError(err)
+	assert.Contains(string(errFile), "error-id")
+	assert.NotContains(string(errFile), "audit-id")
+}
+
+func TestSinkConfig_preflight(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	notADir := filepath.Join(tmpDir, "not-a-dir")
+	require.NoError(t, os.WriteFile(notADir, nil, 0o600))
+
+	tests := []struct {
+		name            string
+		sc              SinkConfig
+		wantErrIs       error
+		wantErrContains string
+	}{
+		{
+			name: "stderr",
+			sc:   SinkConfig{Name: "stderr", SinkType: StderrSink},
+		},
+		{
+			name: "existing-dir",
+			sc:   SinkConfig{Name: "existing", SinkType: FileSink, Path: tmpDir, FileName: "events.log"},
+		},
+		{
+			name: "missing-dir-is-created",
+			sc:   SinkConfig{Name: "missing", SinkType: FileSink, Path: filepath.Join(tmpDir, "missing"), FileName: "events.log"},
+		},
+		{
+			name:            "not-a-dir",
+			sc:              SinkConfig{Name: "not-a-dir", SinkType: FileSink, Path: notADir, FileName: "events.log"},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: `sink "not-a-dir": ` + notADir + " is not a directory",
+		},
+		{
+			name:            "parent-not-a-dir",
+			sc:              SinkConfig{Name: "parent", SinkType: FileSink, Path: filepath.Join(notADir, "sub"), FileName: "events.log"},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: `sink "parent"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			err := tt.sc.preflight()
+			if tt.wantErrIs != nil {
+				require.Error(err)
+				assert.ErrorIs(err, tt.wantErrIs)
+				assert.Contains(err.Error(), tt.wantErrContains)
+				return
+			}
+			require.NoError(err)
+		})
+	}
+}
+
+func TestEventer_unwritableFileSink(t *testing.T) {
+	t.Parallel()
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to read-only directories")
+	}
+	assert, require := assert.New(t), require.New(t)
+	readOnly := t.TempDir()
+	require.NoError(os.Chmod(readOnly, 0o500))
+	t.Cleanup(func() { os.Chmod(readOnly, 0o700) })
+
+	testLock := &sync.Mutex{}
+	c := EventerConfig{
+		Sinks: []SinkConfig{
+			{
+				Name:       "read-only",
+				SinkType:   FileSink,
+				EventTypes: []Type{EveryType},
+				Format:     JSONSinkFormat,
+				Path:       readOnly,
+				FileName:   "events.log",
+			},
+		},
+	}
+	_, err := NewEventer(hclog.NewNullLogger(), testLock, c)
+	require.Error(err)
+	assert.ErrorIs(err, ErrInvalidParameter)
+	assert.Contains(err.Error(), `sink "read-only": directory `+readOnly+" is not writable")
+}
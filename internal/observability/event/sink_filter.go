@@ -0,0 +1,255 @@
+package event
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/eventlogger/filters/gated"
+	"github.com/hashicorp/go-bexpr"
+	"github.com/mitchellh/pointerstructure"
+)
+
+// sinkFilter evaluates a sink's op prefixes, scope ids and its allow and deny
+// filter expressions against events.  An event is kept when its op matches
+// one of the op prefixes, its scope is one of the scope ids, it matches every
+// allow filter and it doesn't match any deny filter, so deny filters take
+// precedence.
+type sinkFilter struct {
+	opPrefixes       []string
+	scopeIds         map[string]struct{}
+	dropMissingScope bool
+	allow            []*bexpr.Evaluator
+	deny             []*bexpr.Evaluator
+}
+
+// newSinkFilter returns a filter node for the op prefixes, the scope ids and
+// the allow and deny filter expressions.  Events without a scope are dropped
+// when dropMissingScope is set, otherwise they bypass the scope ids.  A nil
+// node is returned when there are no filters.
+func newSinkFilter(allow, deny, opPrefixes, scopeIds []string, dropMissingScope bool) (*eventlogger.Filter, error) {
+	const op = "event.newSinkFilter"
+	prefixes, err := normalizeOpPrefixes(opPrefixes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(allow) == 0 && len(deny) == 0 && len(prefixes) == 0 && len(scopeIds) == 0 {
+		return nil, nil
+	}
+	f := &sinkFilter{
+		opPrefixes:       prefixes,
+		dropMissingScope: dropMissingScope,
+		allow:            make([]*bexpr.Evaluator, 0, len(allow)),
+		deny:             make([]*bexpr.Evaluator, 0, len(deny)),
+	}
+	if len(scopeIds) > 0 {
+		f.scopeIds = make(map[string]struct{}, len(scopeIds))
+		for _, id := range scopeIds {
+			if id == "" {
+				return nil, fmt.Errorf("%s: empty scope id: %w", op, ErrInvalidParameter)
+			}
+			f.scopeIds[id] = struct{}{}
+		}
+	}
+	for _, a := range allow {
+		eval, err := bexpr.CreateEvaluator(a)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid allow filter %q: %s: %w", op, a, err, ErrInvalidParameter)
+		}
+		f.allow = append(f.allow, eval)
+	}
+	for _, d := range deny {
+		eval, err := bexpr.CreateEvaluator(d)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid deny filter %q: %s: %w", op, d, err, ErrInvalidParameter)
+		}
+		f.deny = append(f.deny, eval)
+	}
+	return &eventlogger.Filter{Predicate: f.keep}, nil
+}
+
+// keep is the filter node's predicate.  Filters are evaluated against the
+// event as it will be written, which is:
+//
+//	{"created_at": ..., "event_type": ..., "payload": {...}}
+func (f *sinkFilter) keep(e *eventlogger.Event) (bool, error) {
+	const op = "event.(sinkFilter).keep"
+	if e == nil {
+		return false, fmt.Errorf("%s: missing event: %w", op, ErrInvalidParameter)
+	}
+	if len(f.opPrefixes) > 0 && !matchesOpPrefix(f.opPrefixes, eventOps(e)) {
+		return false, nil
+	}
+	if len(f.scopeIds) > 0 {
+		// events without a scope bypass the scope ids, unless they're dropped
+		if scopeId := eventScopeId(e); scopeId == "" {
+			if f.dropMissingScope {
+				return false, nil
+			}
+		} else if _, ok := f.scopeIds[scopeId]; !ok {
+			return false, nil
+		}
+	}
+	if len(f.allow) == 0 && len(f.deny) == 0 {
+		return true, nil
+	}
+	data, err := filterData(e)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	for _, eval := range f.deny {
+		match, err := evaluate(eval, data)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", op, err)
+		}
+		if match {
+			return false, nil
+		}
+	}
+	for _, eval := range f.allow {
+		match, err := evaluate(eval, data)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", op, err)
+		}
+		if !match {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// normalizeOpPrefixes validates the op prefixes and removes a trailing ".*"
+// from them, so "auth.*" and "auth" are the same prefix.  Nil is returned
+// when any prefix is empty, since it matches every op.
+func normalizeOpPrefixes(opPrefixes []string) ([]string, error) {
+	const op = "event.normalizeOpPrefixes"
+	prefixes := make([]string, 0, len(opPrefixes))
+	matchAll := false
+	for _, p := range opPrefixes {
+		n := strings.TrimSuffix(strings.TrimSuffix(p, "*"), ".")
+		if strings.ContainsAny(n, "* \t\n") {
+			return nil, fmt.Errorf("%s: invalid op prefix %q: only a trailing \".*\" wildcard is supported: %w", op, p, ErrInvalidParameter)
+		}
+		if n == "" {
+			matchAll = true
+		}
+		prefixes = append(prefixes, n)
+	}
+	if matchAll || len(prefixes) == 0 {
+		return nil, nil
+	}
+	return prefixes, nil
+}
+
+// matchesOpPrefix returns true when any of the ops is within the namespace of
+// any of the prefixes.  Ops are dotted namespaces, so an op matches a prefix
+// when it equals the prefix or starts with the prefix followed by a ".":
+// "auth.oidc" matches "auth.oidc.Callback" but not "auth.oidcx".
+func matchesOpPrefix(prefixes []string, ops []string) bool {
+	for _, o := range ops {
+		for _, p := range prefixes {
+			if o == p || strings.HasPrefix(o, p+".") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// eventOps returns the ops of the event.  An observation has the ops of each
+// of its parts, and audit events don't have an op.
+func eventOps(e *eventlogger.Event) []string {
+	switch p := e.Payload.(type) {
+	case *err:
+		return []string{string(p.Op)}
+	case *sysEvent:
+		return []string{string(p.Op)}
+	case *customEvent:
+		return []string{string(p.Op)}
+	case *gated.EventPayload:
+		return gatedOps(p)
+	case gated.EventPayload:
+		return gatedOps(&p)
+	case map[string]interface{}:
+		if o, ok := p[OpField].(string); ok {
+			return []string{o}
+		}
+	}
+	return nil
+}
+
+func gatedOps(p *gated.EventPayload) []string {
+	ops := make([]string, 0, len(p.Details))
+	for _, d := range p.Details {
+		if o, ok := d.Payload[OpField].(string); ok {
+			ops = append(ops, o)
+		}
+	}
+	return ops
+}
+
+// eventScopeId returns the scope id of the event's request info, or an empty
+// string when the event doesn't have one.  An observation's request info is
+// in its header.
+func eventScopeId(e *eventlogger.Event) string {
+	var ri interface{}
+	switch p := e.Payload.(type) {
+	case *audit:
+		ri = p.RequestInfo
+	case *err:
+		ri = p.RequestInfo
+	case *gated.EventPayload:
+		ri = p.Header[RequestInfoField]
+	case gated.EventPayload:
+		ri = p.Header[RequestInfoField]
+	case map[string]interface{}:
+		ri = p[RequestInfoField]
+	}
+	switch ri := ri.(type) {
+	case *RequestInfo:
+		if ri != nil {
+			return ri.ScopeId
+		}
+	case map[string]interface{}:
+		if id, ok := ri["scope_id"].(string); ok {
+			return id
+		}
+	}
+	return ""
+}
+
+// evaluate returns whether the data matches the filter.  Selectors which
+// aren't found in the data are a mismatch rather than an error, since
+// payloads differ between event types.
+func evaluate(eval *bexpr.Evaluator, data map[string]interface{}) (bool, error) {
+	match, err := eval.Evaluate(data)
+	if err != nil && !errors.Is(err, pointerstructure.ErrNotFound) {
+		return false, err
+	}
+	return match, nil
+}
+
+// filterData converts the event into a generic map which filters can be
+// evaluated against.
+func filterData(e *eventlogger.Event) (map[string]interface{}, error) {
+	const op = "event.filterData"
+	b, err := json.Marshal(struct {
+		CreatedAt string      `json:"created_at"`
+		EventType string      `json:"event_type"`
+		Payload   interface{} `json:"payload"`
+	}{
+		CreatedAt: e.CreatedAt.String(),
+		EventType: string(e.Type),
+		Payload:   e.Payload,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	data := map[string]interface{}{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,441 @@
+package event
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/eventlogger/filters/gated"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newSinkFilter(t *testing.T) {
+	t.Parallel()
+	testEvent := &eventlogger.Event{
+		Type:      eventlogger.EventType(ErrorType),
+		CreatedAt: time.Now(),
+		Payload: &err{
+			Id:      "test-id",
+			Op:      "session.(Repository).CreateSession",
+			Version: errorVersion,
+		},
+	}
+	tests := []struct {
+		name            string
+		allow           []string
+		deny            []string
+		wantNilNode     bool
+		wantKeep        bool
+		wantErrIs       error
+		wantErrContains string
+	}{
+		{
+			name:        "no-filters",
+			wantNilNode: true,
+		},
+		{
+			name:            "invalid-allow",
+			allow:           []string{`"/payload/op" ==`},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "invalid allow filter",
+		},
+		{
+			name:            "invalid-deny",
+			deny:            []string{`"/payload/op" ==`},
+			wantErrIs:       ErrInvalidParameter,
+			wantErrContains: "invalid deny filter",
+		},
+		{
+			name:     "allow-match",
+			allow:    []string{`"/payload/op" matches "^session\\."`},
+			wantKeep: true,
+		},
+		{
+			name:  "allow-no-match",
+			allow: []string{`"/payload/op" matches "^auth\\."`},
+		},
+		{
+			name:  "allow-must-match-all",
+			allow: []string{`"/payload/op" matches "^session\\."`, `"/event_type" == "audit"`},
+		},
+		{
+			name:  "allow-missing-selector",
+			allow: []string{`"/payload/request_info/id" == "test-id"`},
+		},
+		{
+			name: "deny-match",
+			deny: []string{`"/event_type" == "error"`},
+		},
+		{
+			name:     "deny-no-match",
+			deny:     []string{`"/event_type" == "audit"`},
+			wantKeep: true,
+		},
+		{
+			name:  "deny-wins",
+			allow: []string{`"/payload/op" matches "^session\\."`},
+			deny:  []string{`"/payload/id" == "test-id"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			n, err := newSinkFilter(tt.allow, tt.deny, nil, nil, false)
+			if tt.wantErrIs != nil {
+				require.Error(err)
+				assert.ErrorIs(err, tt.wantErrIs)
+				assert.Contains(err.Error(), tt.wantErrContains)
+				return
+			}
+			require.NoError(err)
+			if tt.wantNilNode {
+				assert.Nil(n)
+				return
+			}
+			got, err := n.Process(context.Background(), testEvent)
+			require.NoError(err)
+			if tt.wantKeep {
+				assert.NotNil(got)
+				return
+			}
+			assert.Nil(got)
+		})
+	}
+}
+
+func Test_sinkFilter_opPrefixes(t *testing.T) {
+	t.Parallel()
+	errEvent := func(op Op) *eventlogger.Event {
+		return &eventlogger.Event{
+			Type:      eventlogger.EventType(ErrorType),
+			CreatedAt: time.Now(),
+			Payload:   &err{Id: "test-id", Op: op, Version: errorVersion},
+		}
+	}
+	observationEvent := &eventlogger.Event{
+		Type:      eventlogger.EventType(ObservationType),
+		CreatedAt: time.Now(),
+		Payload: &gated.EventPayload{
+			ID: "test-id",
+			Details: []gated.EventPayloadDetails{
+				{Payload: map[string]interface{}{OpField: "session.(Repository).CreateSession"}},
+				{Payload: map[string]interface{}{OpField: "auth.oidc.Callback"}},
+			},
+		},
+	}
+	auditEvent := &eventlogger.Event{
+		Type:      eventlogger.EventType(AuditType),
+		CreatedAt: time.Now(),
+		Payload:   &audit{Id: "test-id"},
+	}
+	tests := []struct {
+		name            string
+		opPrefixes      []string
+		event           *eventlogger.Event
+		wantNilNode     bool
+		wantKeep        bool
+		wantErrContains string
+	}{
+		{
+			name:        "no-prefixes",
+			wantNilNode: true,
+		},
+		{
+			name:        "empty-prefix-matches-all",
+			opPrefixes:  []string{"auth", ""},
+			wantNilNode: true,
+		},
+		{
+			name:        "wildcard-prefix-matches-all",
+			opPrefixes:  []string{"*"},
+			wantNilNode: true,
+		},
+		{
+			name:            "invalid-wildcard",
+			opPrefixes:      []string{"auth.*.Callback"},
+			wantErrContains: "invalid op prefix",
+		},
+		{
+			name:       "exact",
+			opPrefixes: []string{"auth.oidc.Callback"},
+			event:      errEvent("auth.oidc.Callback"),
+			wantKeep:   true,
+		},
+		{
+			name:       "namespace",
+			opPrefixes: []string{"auth"},
+			event:      errEvent("auth.oidc.Callback"),
+			wantKeep:   true,
+		},
+		{
+			name:       "namespace-wildcard",
+			opPrefixes: []string{"auth.oidc.*"},
+			event:      errEvent("auth.oidc.Callback"),
+			wantKeep:   true,
+		},
+		{
+			name:       "partial-segment",
+			opPrefixes: []string{"auth.oid"},
+			event:      errEvent("auth.oidc.Callback"),
+		},
+		{
+			name:       "no-match",
+			opPrefixes: []string{"auth", "session"},
+			event:      errEvent("event.(Eventer).writeError"),
+		},
+		{
+			name:       "sys-event",
+			opPrefixes: []string{"event"},
+			event: &eventlogger.Event{
+				Type:    eventlogger.EventType(SystemType),
+				Payload: &sysEvent{Id: "test-id", Op: "event.(Eventer).Reopen"},
+			},
+			wantKeep: true,
+		},
+		{
+			name:       "observation-any-part",
+			opPrefixes: []string{"auth.oidc"},
+			event:      observationEvent,
+			wantKeep:   true,
+		},
+		{
+			name:       "observation-no-match",
+			opPrefixes: []string{"auth.password"},
+			event:      observationEvent,
+		},
+		{
+			name:       "audit-has-no-op",
+			opPrefixes: []string{"auth"},
+			event:      auditEvent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			n, err := newSinkFilter(nil, nil, tt.opPrefixes, nil, false)
+			if tt.wantErrContains != "" {
+				require.Error(err)
+				assert.ErrorIs(err, ErrInvalidParameter)
+				assert.Contains(err.Error(), tt.wantErrContains)
+				return
+			}
+			require.NoError(err)
+			if tt.wantNilNode {
+				assert.Nil(n)
+				return
+			}
+			got, err := n.Process(context.Background(), tt.event)
+			require.NoError(err)
+			if tt.wantKeep {
+				assert.NotNil(got)
+				return
+			}
+			assert.Nil(got)
+		})
+	}
+}
+
+func Test_sinkFilterScopeIds(t *testing.T) {
+	t.Parallel()
+	auditEvent := func(scopeId string) *eventlogger.Event {
+		return &eventlogger.Event{
+			Type:      eventlogger.EventType(AuditType),
+			CreatedAt: time.Now(),
+			Payload:   &audit{Id: "test-id", RequestInfo: &RequestInfo{Id: "test-request", ScopeId: scopeId}},
+		}
+	}
+	observationEvent := func(scopeId string) *eventlogger.Event {
+		return &eventlogger.Event{
+			Type:      eventlogger.EventType(ObservationType),
+			CreatedAt: time.Now(),
+			Payload: &gated.EventPayload{
+				ID:     "test-id",
+				Header: map[string]interface{}{RequestInfoField: &RequestInfo{Id: "test-request", ScopeId: scopeId}},
+			},
+		}
+	}
+	errEvent := &eventlogger.Event{
+		Type:      eventlogger.EventType(ErrorType),
+		CreatedAt: time.Now(),
+		Payload:   &err{Id: "test-id", Op: "test", RequestInfo: &RequestInfo{Id: "test-request", ScopeId: "o_1234567890"}},
+	}
+	sysEv := &eventlogger.Event{
+		Type:      eventlogger.EventType(SystemType),
+		CreatedAt: time.Now(),
+		Payload:   &sysEvent{Id: "test-id", Op: "test"},
+	}
+	tests := []struct {
+		name             string
+		scopeIds         []string
+		dropMissingScope bool
+		event            *eventlogger.Event
+		wantNilNode      bool
+		wantKeep         bool
+		wantErrContains  string
+	}{
+		{
+			name:        "no-scope-ids",
+			wantNilNode: true,
+		},
+		{
+			name:            "empty-scope-id",
+			scopeIds:        []string{"o_1234567890", ""},
+			wantErrContains: "empty scope id",
+		},
+		{
+			name:     "audit-match",
+			scopeIds: []string{"global", "o_1234567890"},
+			event:    auditEvent("o_1234567890"),
+			wantKeep: true,
+		},
+		{
+			name:     "audit-no-match",
+			scopeIds: []string{"o_1234567890"},
+			event:    auditEvent("o_0987654321"),
+		},
+		{
+			name:     "project-doesnt-match-its-org",
+			scopeIds: []string{"o_1234567890"},
+			event:    auditEvent("p_1234567890"),
+		},
+		{
+			name:     "observation-match",
+			scopeIds: []string{"o_1234567890"},
+			event:    observationEvent("o_1234567890"),
+			wantKeep: true,
+		},
+		{
+			name:     "observation-no-match",
+			scopeIds: []string{"o_1234567890"},
+			event:    observationEvent("o_0987654321"),
+		},
+		{
+			name:     "error-match",
+			scopeIds: []string{"o_1234567890"},
+			event:    errEvent,
+			wantKeep: true,
+		},
+		{
+			name:     "missing-scope-bypasses",
+			scopeIds: []string{"o_1234567890"},
+			event:    auditEvent(""),
+			wantKeep: true,
+		},
+		{
+			name:     "sys-event-bypasses",
+			scopeIds: []string{"o_1234567890"},
+			event:    sysEv,
+			wantKeep: true,
+		},
+		{
+			name:             "missing-scope-dropped",
+			scopeIds:         []string{"o_1234567890"},
+			dropMissingScope: true,
+			event:            auditEvent(""),
+		},
+		{
+			name:             "sys-event-dropped",
+			scopeIds:         []string{"o_1234567890"},
+			dropMissingScope: true,
+			event:            sysEv,
+		},
+		{
+			name:             "drop-missing-scope-match",
+			scopeIds:         []string{"o_1234567890"},
+			dropMissingScope: true,
+			event:            observationEvent("o_1234567890"),
+			wantKeep:         true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			n, err := newSinkFilter(nil, nil, nil, tt.scopeIds, tt.dropMissingScope)
+			if tt.wantErrContains != "" {
+				require.Error(err)
+				assert.ErrorIs(err, ErrInvalidParameter)
+				assert.Contains(err.Error(), tt.wantErrContains)
+				return
+			}
+			require.NoError(err)
+			if tt.wantNilNode {
+				assert.Nil(n)
+				return
+			}
+			got, err := n.Process(context.Background(), tt.event)
+			require.NoError(err)
+			if tt.wantKeep {
+				assert.NotNil(got)
+				return
+			}
+			assert.Nil(got)
+		})
+	}
+}
+
+func TestEventer_sinkFilters(t *testing.T) {
+	t.Parallel()
+	require, assert := require.New(t), assert.New(t)
+	ctx := context.Background()
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+
+	filtered, err := ioutil.TempFile("./", "tmp-filtered")
+	require.NoError(err)
+	unfiltered, err := ioutil.TempFile("./", "tmp-unfiltered")
+	require.NoError(err)
+	t.Cleanup(func() {
+		os.Remove(filtered.Name())
+		os.Remove(unfiltered.Name())
+	})
+
+	c := EventerConfig{
+		Sinks: []SinkConfig{
+			{
+				Name:         "filtered",
+				SinkType:     FileSink,
+				EventTypes:   []Type{ErrorType},
+				Format:       JSONSinkFormat,
+				Path:         "./",
+				FileName:     filtered.Name(),
+				AllowFilters: []string{`"/payload/op" matches "^session\\."`},
+				DenyFilters:  []string{`"/payload/op" == "session.denied"`},
+			},
+			{
+				Name:       "unfiltered",
+				SinkType:   FileSink,
+				EventTypes: []Type{ErrorType},
+				Format:     JSONSinkFormat,
+				Path:       "./",
+				FileName:   unfiltered.Name(),
+			},
+		},
+	}
+	e, err := NewEventer(testLogger, testLock, c)
+	require.NoError(err)
+
+	for _, op := range []Op{"session.allowed", "session.denied", "auth.dropped"} {
+		ev, err := newError(op, ErrInvalidParameter)
+		require.NoError(err)
+		require.NoError(e.writeError(ctx, ev))
+	}
+
+	b, err := ioutil.ReadFile(filtered.Name())
+	require.NoError(err)
+	assert.Equal(1, strings.Count(string(b), "\n"))
+	assert.Contains(string(b), "session.allowed")
+
+	b, err = ioutil.ReadFile(unfiltered.Name())
+	require.NoError(err)
+	assert.Equal(3, strings.Count(string(b), "\n"))
+}
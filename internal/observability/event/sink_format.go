@@ -2,20 +2,57 @@ package event
 
 import (
 	"fmt"
+	"strings"
 )
 
 const (
-	JSONSinkFormat SinkFormat = "json" // JSONSinkFormat means the event is formatted as JSON
+	JSONSinkFormat    SinkFormat = "json"    // JSONSinkFormat means the event is formatted as JSON
+	CEFSinkFormat     SinkFormat = "cef"     // CEFSinkFormat means the event is formatted as ArcSight Common Event Format
+	MsgpackSinkFormat SinkFormat = "msgpack" // MsgpackSinkFormat means the event is formatted as msgpack
+	TextSinkFormat    SinkFormat = "text"    // TextSinkFormat means the event is formatted as a single line of text
+	ProtoSinkFormat   SinkFormat = "proto"   // ProtoSinkFormat means the event is formatted as a length prefixed proto, which is only supported for audit events
 )
 
-type SinkFormat string // SinkFormat defines the formatting for a sink in a config file stanza (json)
+type SinkFormat string // SinkFormat defines the formatting for a sink in a config file stanza (json, cef, msgpack, text, proto)
 
+// supportedSinkFormats are the formats a sink may use.  A new format must be
+// added here, so sinks are able to use it.
+var supportedSinkFormats = []SinkFormat{JSONSinkFormat, CEFSinkFormat, MsgpackSinkFormat, TextSinkFormat, ProtoSinkFormat}
+
+// Validate returns an error naming the supported formats when the format
+// isn't one of them.
 func (f SinkFormat) Validate() error {
 	const op = "event.(SinkFormat).Validate"
+	names := make([]string, 0, len(supportedSinkFormats))
+	for _, supported := range supportedSinkFormats {
+		if f == supported {
+			return nil
+		}
+		names = append(names, string(supported))
+	}
+	return fmt.Errorf("%s: '%s' is not a valid sink format (supported formats: %s): %w", op, f, strings.Join(names, ", "), ErrInvalidParameter)
+}
+
+const (
+	DefaultTimestampFormat     TimestampFormat = ""             // DefaultTimestampFormat is the sink format's own representation, which is RFC3339 with nanoseconds for json and text
+	RFC3339NanoTimestampFormat TimestampFormat = "rfc3339nano"  // RFC3339NanoTimestampFormat is an RFC3339 string with nanoseconds
+	EpochMillisTimestampFormat TimestampFormat = "epoch_millis" // EpochMillisTimestampFormat is the number of milliseconds since the Unix epoch
+)
+
+// epochMillisFormatSuffix is appended to the format key of the formatters
+// which serialize the created at timestamp as epoch millis, since formatters
+// which may process the same event must store their output under distinct
+// keys.
+const epochMillisFormatSuffix = "-epoch-millis"
+
+type TimestampFormat string // TimestampFormat defines how a sink serializes the created at timestamp of its events (rfc3339nano, epoch_millis)
+
+func (f TimestampFormat) validate() error {
+	const op = "event.(TimestampFormat).validate"
 	switch f {
-	case JSONSinkFormat:
+	case DefaultTimestampFormat, RFC3339NanoTimestampFormat, EpochMillisTimestampFormat:
 		return nil
 	default:
-		return fmt.Errorf("%s: '%s' is not a valid sink format: %w", op, f, ErrInvalidParameter)
+		return fmt.Errorf("%s: '%s' is not a valid timestamp format: %w", op, f, ErrInvalidParameter)
 	}
 }
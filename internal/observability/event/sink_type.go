@@ -5,16 +5,20 @@ import (
 )
 
 const (
-	StderrSink SinkType = "stderr" // StderrSink is written to stderr
-	FileSink   SinkType = "file"   // FileSink is written to a file
+	StderrSink     SinkType = "stderr"     // StderrSink is written to stderr
+	FileSink       SinkType = "file"       // FileSink is written to a file
+	KafkaSink      SinkType = "kafka"      // KafkaSink is produced to a Kafka topic
+	CloudWatchSink SinkType = "cloudwatch" // CloudWatchSink is put to an AWS CloudWatch Logs log stream
+	GRPCSink       SinkType = "grpc"       // GRPCSink is streamed to a collector implementing the EventCollectorService
+	DiscardSink    SinkType = "discard"    // DiscardSink discards its events, which always succeeds
 )
 
-type SinkType string // SinkType defines the type of sink in a config stanza (file, stderr)
+type SinkType string // SinkType defines the type of sink in a config stanza (file, stderr, kafka, cloudwatch, grpc, discard)
 
 func (t SinkType) validate() error {
 	const op = "event.(SinkType).validate"
 	switch t {
-	case StderrSink, FileSink:
+	case StderrSink, FileSink, KafkaSink, CloudWatchSink, GRPCSink, DiscardSink:
 		return nil
 	default:
 		return fmt.Errorf("%s: '%s' is not a valid sink type: %w", op, t, ErrInvalidParameter)
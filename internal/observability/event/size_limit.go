@@ -0,0 +1,121 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/eventlogger/filters/gated"
+)
+
+const (
+	// TruncatedField is the header field set to true when an observation's
+	// details are dropped because it exceeded the eventer's max event bytes.
+	TruncatedField = "truncated"
+
+	// OriginalSizeField is the header field set to the serialized size (in
+	// bytes) of an observation before it was truncated.
+	OriginalSizeField = "original_size"
+)
+
+// sizeLimitNode is a filter node which limits the serialized size of audit
+// and observation events.  Audit events which exceed the limit are rejected,
+// to preserve their integrity, while observations are truncated by dropping
+// their details.
+type sizeLimitNode struct {
+	maxBytes int
+}
+
+var _ eventlogger.Node = &sizeLimitNode{}
+
+func newSizeLimitNode(maxBytes int) (*sizeLimitNode, error) {
+	const op = "event.newSizeLimitNode"
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("%s: max event bytes must be greater than zero: %w", op, ErrInvalidParameter)
+	}
+	return &sizeLimitNode{
+		maxBytes: maxBytes,
+	}, nil
+}
+
+// Process checks the serialized size of the event's payload.  Since events
+// are shared between pipelines, a new event is returned when an observation
+// is truncated rather than modifying the event's payload.
+func (n *sizeLimitNode) Process(_ context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	const op = "event.(sizeLimitNode).Process"
+	if e == nil {
+		return nil, fmt.Errorf("%s: missing event: %w", op, ErrInvalidParameter)
+	}
+	size, err := payloadSize(e.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if size <= n.maxBytes {
+		return e, nil
+	}
+	var payload interface{}
+	switch p := e.Payload.(type) {
+	case *audit, audit:
+		return nil, fmt.Errorf("%s: audit event of %d bytes exceeds the max of %d bytes: %w", op, size, n.maxBytes, ErrEventTooLarge)
+	case *gated.EventPayload:
+		payload = truncateGatedPayload(p, size)
+	case gated.EventPayload:
+		payload = *truncateGatedPayload(&p, size)
+	default:
+		return nil, fmt.Errorf("%s: unable to truncate %T payload of %d bytes: %w", op, e.Payload, size, ErrEventTooLarge)
+	}
+	return &eventlogger.Event{
+		Type:      e.Type,
+		CreatedAt: e.CreatedAt,
+		Payload:   payload,
+	}, nil
+}
+
+// Reopen is a no op
+func (n *sizeLimitNode) Reopen() error {
+	return nil
+}
+
+// Type describes the type of the node as a Filter.
+func (n *sizeLimitNode) Type() eventlogger.NodeType {
+	return eventlogger.NodeTypeFilter
+}
+
+// payloadSize returns the size of the payload serialized as JSON.
+func payloadSize(payload interface{}) (int, error) {
+	const op = "event.payloadSize"
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("%s: unable to marshal payload: %w", op, err)
+	}
+	return len(b), nil
+}
+
+// truncateGatedPayload returns a copy of the composed observation without the
+// payloads of its details, and with the TruncatedField and OriginalSizeField
+// added to its header.
+func truncateGatedPayload(p *gated.EventPayload, originalSize int) *gated.EventPayload {
+	header := make(map[string]interface{}, len(p.Header)+2)
+	for k, v := range p.Header {
+		header[k] = v
+	}
+	header[TruncatedField] = true
+	header[OriginalSizeField] = originalSize
+
+	var details []gated.EventPayloadDetails
+	if p.Details != nil {
+		details = make([]gated.EventPayloadDetails, 0, len(p.Details))
+		for _, d := range p.Details {
+			details = append(details, gated.EventPayloadDetails{
+				Type:      d.Type,
+				CreatedAt: d.CreatedAt,
+			})
+		}
+	}
+	return &gated.EventPayload{
+		ID:      p.ID,
+		Header:  header,
+		Details: details,
+	}
+}
@@ -0,0 +1,188 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/eventlogger/filters/gated"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newSizeLimitNode(t *testing.T) {
+	t.Parallel()
+	_, err := newSizeLimitNode(0)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+
+	n, err := newSizeLimitNode(10)
+	require.NoError(t, err)
+	assert.Equal(t, 10, n.maxBytes)
+}
+
+func Test_sizeLimitNode_Process(t *testing.T) {
+	t.Parallel()
+	const maxBytes = 256
+	n, nodeErr := newSizeLimitNode(maxBytes)
+	require.NoError(t, nodeErr)
+	bigDetails := map[string]interface{}{"big": strings.Repeat("a", maxBytes)}
+	bigObservation := gated.EventPayload{
+		ID:     "obs-id",
+		Header: map[string]interface{}{CorrelationIdField: "correlation-id"},
+		Details: []gated.EventPayloadDetails{
+			{Type: string(ObservationType), CreatedAt: "now", Payload: bigDetails},
+		},
+	}
+	bigObservationSize, nodeErr := payloadSize(bigObservation)
+	require.NoError(t, nodeErr)
+
+	tests := []struct {
+		name        string
+		payload     interface{}
+		wantPayload interface{}
+		wantErrIs   error
+	}{
+		{
+			name:        "small-observation",
+			payload:     gated.EventPayload{ID: "obs-id", Details: []gated.EventPayloadDetails{{Payload: map[string]interface{}{"small": "a"}}}},
+			wantPayload: gated.EventPayload{ID: "obs-id", Details: []gated.EventPayloadDetails{{Payload: map[string]interface{}{"small": "a"}}}},
+		},
+		{
+			name:    "truncated-observation",
+			payload: bigObservation,
+			wantPayload: gated.EventPayload{
+				ID: "obs-id",
+				Header: map[string]interface{}{
+					CorrelationIdField: "correlation-id",
+					TruncatedField:     true,
+					OriginalSizeField:  bigObservationSize,
+				},
+				Details: []gated.EventPayloadDetails{{Type: string(ObservationType), CreatedAt: "now"}},
+			},
+		},
+		{
+			name:        "small-audit",
+			payload:     audit{Id: "audit-id"},
+			wantPayload: audit{Id: "audit-id"},
+		},
+		{
+			name:      "rejected-audit",
+			payload:   audit{Id: "audit-id", Request: &Request{Endpoint: bigDetails["big"].(string)}},
+			wantErrIs: ErrEventTooLarge,
+		},
+		{
+			name:      "rejected-audit-ptr",
+			payload:   &audit{Id: "audit-id", Request: &Request{Endpoint: bigDetails["big"].(string)}},
+			wantErrIs: ErrEventTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			e := &eventlogger.Event{Type: "test", Payload: tt.payload}
+			got, gotErr := n.Process(context.Background(), e)
+			if tt.wantErrIs != nil {
+				require.Error(gotErr)
+				assert.ErrorIs(gotErr, tt.wantErrIs)
+				return
+			}
+			require.NoError(gotErr)
+			assert.Equal(tt.wantPayload, got.Payload)
+		})
+	}
+	t.Run("shared-event-not-modified", func(t *testing.T) {
+		e := &eventlogger.Event{Type: "test", Payload: bigObservation}
+		got, err := n.Process(context.Background(), e)
+		require.NoError(t, err)
+		assert.NotSame(t, e, got)
+		assert.Equal(t, bigDetails, e.Payload.(gated.EventPayload).Details[0].Payload)
+		assert.NotContains(t, e.Payload.(gated.EventPayload).Header, TruncatedField)
+	})
+}
+
+func TestEventer_WithMaxEventBytes(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	newTestEventer := func(t *testing.T) (*Eventer, string) {
+		t.Helper()
+		tmpFile, err := ioutil.TempFile("./", "tmp-size-limit-TestEventer_WithMaxEventBytes")
+		require.NoError(t, err)
+		t.Cleanup(func() { os.Remove(tmpFile.Name()) })
+		c := EventerConfig{
+			AuditEnabled:        true,
+			ObservationsEnabled: true,
+			Sinks: []SinkConfig{
+				{
+					Name:       "every-type-file-sink",
+					SinkType:   FileSink,
+					EventTypes: []Type{EveryType},
+					Format:     JSONSinkFormat,
+					Path:       "./",
+					FileName:   tmpFile.Name(),
+				},
+			},
+		}
+		e, err := NewEventer(testLogger, testLock, c, WithMaxEventBytes(1024))
+		require.NoError(t, err)
+		return e, tmpFile.Name()
+	}
+	bigDetails := map[string]interface{}{"big": strings.Repeat("a", 2048)}
+
+	t.Run("negative", func(t *testing.T) {
+		_, err := NewEventer(testLogger, testLock, EventerConfig{}, WithMaxEventBytes(-1))
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	})
+	t.Run("observation-truncated", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		e, fileName := newTestEventer(t)
+		testObservation, err := newObservation("TestEventer_WithMaxEventBytes", WithDetails(bigDetails), WithFlush())
+		require.NoError(err)
+		require.NoError(e.writeObservation(ctx, testObservation))
+
+		b, err := ioutil.ReadFile(fileName)
+		require.NoError(err)
+		var got map[string]interface{}
+		require.NoError(json.Unmarshal(b, &got))
+		header := got["payload"].(map[string]interface{})[HeaderField].(map[string]interface{})
+		assert.Equal(true, header[TruncatedField])
+		assert.Greater(header[OriginalSizeField].(float64), float64(2048))
+		assert.NotContains(string(b), bigDetails["big"])
+	})
+	t.Run("audit-rejected", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		e, fileName := newTestEventer(t)
+		testAudit, err := newAudit("TestEventer_WithMaxEventBytes", WithRequest(&Request{Endpoint: bigDetails["big"].(string)}), WithFlush())
+		require.NoError(err)
+		err = e.writeAudit(ctx, testAudit)
+		require.Error(err)
+		assert.ErrorIs(err, ErrEventTooLarge)
+
+		b, err := ioutil.ReadFile(fileName)
+		require.NoError(err)
+		assert.Empty(b)
+	})
+	t.Run("small-audit", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		e, fileName := newTestEventer(t)
+		testAudit, err := newAudit("TestEventer_WithMaxEventBytes", WithFlush())
+		require.NoError(err)
+		require.NoError(e.writeAudit(ctx, testAudit))
+
+		b, err := ioutil.ReadFile(fileName)
+		require.NoError(err)
+		assert.Contains(string(b), testAudit.Id)
+	})
+}
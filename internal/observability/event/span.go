@@ -0,0 +1,99 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// spanIdPrefix is the prefix of the ids of spans.
+const spanIdPrefix = "span"
+
+// Span times an operation.  It's started with (Eventer).StartSpan and its End
+// writes an observation with the operation's duration.  The zero Span is the
+// span started when observations aren't enabled, and its End is a no op.
+type Span struct {
+	eventer  *Eventer
+	ctx      context.Context
+	op       Op
+	id       string
+	parentId string
+	start    time.Time
+}
+
+// StartSpan starts timing the operation op and returns its Span, along with
+// a ctx for the operation.  Spans started with the returned ctx are children
+// of the span.  The returned ctx carries a correlation id (see
+// WithCorrelationId): the correlation id of ctx when it has one, otherwise
+// the span's id.  So a span and its children, and every other event written
+// with their ctx, share a correlation id.
+//
+// When observations aren't enabled, the zero Span and ctx are returned without
+// allocating, so spans may be started in hot paths.
+func (e *Eventer) StartSpan(ctx context.Context, op Op) (Span, context.Context) {
+	if e == nil || ctx == nil || op == "" || !e.conf.ObservationsEnabled {
+		return Span{}, ctx
+	}
+	id, err := newId(spanIdPrefix)
+	if err != nil {
+		e.logger.Error("unable to start span", "op", op, "error", err.Error())
+		return Span{}, ctx
+	}
+	s := Span{
+		eventer: e,
+		op:      op,
+		id:      id,
+		start:   e.timeNow(),
+	}
+	if parent, ok := ctx.Value(spanKey).(string); ok {
+		s.parentId = parent
+	}
+	if _, ok := CorrelationIdFromContext(ctx); !ok {
+		ctx = context.WithValue(ctx, correlationIdKey, id)
+	}
+	ctx = context.WithValue(ctx, spanKey, id)
+	s.ctx = ctx
+	return s, ctx
+}
+
+// End stops timing the span's operation and writes an observation with the
+// op, the span's start, stop and duration in milliseconds, its id and (for a
+// child span) its parent's id.  The observation is flushed, so it isn't
+// composed with the other observations of the span's request.  End must only
+// be called once.
+func (s Span) End() error {
+	const op = "event.(Span).End"
+	if s.eventer == nil {
+		return nil
+	}
+	stop := s.eventer.timeNow()
+	details := map[string]interface{}{
+		"span_id":     s.id,
+		"start":       s.start,
+		"stop":        stop,
+		"duration-ms": float64(stop.Sub(s.start)) / float64(time.Millisecond),
+	}
+	if s.parentId != "" {
+		details["parent_span_id"] = s.parentId
+	}
+	opts := []Option{WithId(s.id), WithDetails(details), WithFlush()}
+	if info, ok := RequestInfoFromContext(s.ctx); ok {
+		opts = append(opts, WithRequestInfo(info))
+	}
+	obs, err := newObservation(s.op, opts...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.eventer.writeObservation(s.ctx, obs); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// timeNow returns the current time of the eventer's clock.
+func (e *Eventer) timeNow() time.Time {
+	if e.now != nil {
+		return e.now()
+	}
+	return time.Now()
+}
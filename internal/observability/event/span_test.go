@@ -0,0 +1,105 @@
+package event
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventer_StartSpan(t *testing.T) {
+	// not parallel, since testing.AllocsPerRun can't be called by parallel
+	// tests
+	t.Run("duration", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		e, buf := NewTestEventer(t)
+		now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+		e.now = func() time.Time { return now }
+
+		s, _ := e.StartSpan(context.Background(), "TestEventer_StartSpan")
+		now = now.Add(1500 * time.Microsecond)
+		require.NoError(s.End())
+
+		got := buf.Events(t, ObservationType)
+		require.Len(got, 1)
+		details := spanDetails(t, got[0])
+		assert.Equal("TestEventer_StartSpan", details[OpField])
+		assert.Equal(1.5, details["duration-ms"])
+		assert.Equal(s.id, details["span_id"])
+		assert.NotContains(details, "parent_span_id")
+	})
+	t.Run("children", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		e, buf := NewTestEventer(t)
+
+		parent, parentCtx := e.StartSpan(context.Background(), "parent")
+		parentCorrelationId, ok := CorrelationIdFromContext(parentCtx)
+		require.True(ok)
+		assert.Equal(parent.id, parentCorrelationId)
+
+		child, childCtx := e.StartSpan(parentCtx, "child")
+		childCorrelationId, ok := CorrelationIdFromContext(childCtx)
+		require.True(ok)
+		assert.Equal(parentCorrelationId, childCorrelationId)
+		require.NoError(child.End())
+		require.NoError(parent.End())
+
+		got := buf.Events(t, ObservationType)
+		require.Len(got, 2)
+		childDetails, parentDetails := spanDetails(t, got[0]), spanDetails(t, got[1])
+		assert.Equal("child", childDetails[OpField])
+		assert.Equal(parent.id, childDetails["parent_span_id"])
+		assert.Equal("parent", parentDetails[OpField])
+		for _, ev := range got {
+			assert.Equal(parentCorrelationId, spanHeader(t, ev)[CorrelationIdField])
+		}
+	})
+	t.Run("ctx-correlation-id", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		e, _ := NewTestEventer(t)
+		ctx, err := WithCorrelationId(context.Background(), "correlation-id")
+		require.NoError(err)
+
+		_, spanCtx := e.StartSpan(ctx, "TestEventer_StartSpan")
+		got, ok := CorrelationIdFromContext(spanCtx)
+		require.True(ok)
+		assert.Equal("correlation-id", got)
+	})
+	t.Run("observations-disabled", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		e, buf := NewTestEventer(t)
+		e.conf.ObservationsEnabled = false
+		ctx := context.Background()
+
+		allocs := testing.AllocsPerRun(100, func() {
+			s, _ := e.StartSpan(ctx, "TestEventer_StartSpan")
+			_ = s.End()
+		})
+		assert.Zero(allocs)
+
+		s, spanCtx := e.StartSpan(ctx, "TestEventer_StartSpan")
+		assert.Equal(Span{}, s)
+		assert.Equal(ctx, spanCtx)
+		require.NoError(s.End())
+		assert.Empty(buf.Events(t, ObservationType))
+	})
+}
+
+func spanDetails(t *testing.T, ev TestEvent) map[string]interface{} {
+	t.Helper()
+	details, ok := ev.Payload["details"].([]interface{})
+	require.True(t, ok, "missing details: %v", ev.Payload)
+	require.Len(t, details, 1)
+	d, ok := details[0].(map[string]interface{})["payload"].(map[string]interface{})
+	require.True(t, ok, "missing details payload: %v", details[0])
+	return d
+}
+
+func spanHeader(t *testing.T, ev TestEvent) map[string]interface{} {
+	t.Helper()
+	h, ok := ev.Payload["header"].(map[string]interface{})
+	require.True(t, ok, "missing header: %v", ev.Payload)
+	return h
+}
@@ -0,0 +1,294 @@
+package event
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// defaultSpoolMaxBytes is the default max size of a network sink's spool.
+const defaultSpoolMaxBytes = 64 << 20
+
+// spoolRecord is an event written to a spool: its type, creation time,
+// correlation id and the sink's formatted bytes.  It's written as a single
+// line of json.
+type spoolRecord struct {
+	CreatedAt     time.Time `json:"created_at"`
+	EventType     string    `json:"event_type"`
+	CorrelationId string    `json:"correlation_id,omitempty"`
+	Data          []byte    `json:"data"`
+}
+
+// spooledPayload is the payload of a replayed event.  Only the record's
+// correlation id is kept, so a sink which keys its messages by correlation id
+// (see CorrelationIdPartitionKey) keys a replayed event like the original.
+type spooledPayload struct {
+	CorrelationId string
+}
+
+// spool is a network sink's on-disk buffer of the events it failed to send,
+// which are replayed in order once it recovers.  Records are appended to the
+// file and removed from its front, and the file is compacted when more than
+// half of it has been replayed.  When a record doesn't fit within the max
+// size, the oldest records are dropped to make room for it.
+type spool struct {
+	path     string
+	maxBytes int64
+
+	l       sync.Mutex
+	f       *os.File
+	sizes   []int64 // sizes is the size of each record, oldest first
+	offset  int64   // offset is the offset of the oldest record in the file
+	size    int64   // size is the total size of the records
+	dropped uint64
+}
+
+// newSinkSpool opens the sink's spool, or returns nil when it doesn't have
+// one.
+func newSinkSpool(sc SinkConfig) (*spool, error) {
+	if sc.SpoolPath == "" {
+		return nil, nil
+	}
+	maxBytes := sc.SpoolMaxBytes
+	if maxBytes == 0 {
+		maxBytes = defaultSpoolMaxBytes
+	}
+	return openSpool(sc.SpoolPath, maxBytes)
+}
+
+// openSpool opens the spool at path, creating it and its directory when they
+// don't exist.  The records of an existing spool are kept, except for a
+// partially written last record and the oldest records which don't fit within
+// maxBytes.
+func openSpool(path string, maxBytes int64) (*spool, error) {
+	const op = "event.openSpool"
+	if path == "" {
+		return nil, fmt.Errorf("%s: missing path: %w", op, ErrInvalidParameter)
+	}
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("%s: max bytes must be greater than zero: %w", op, ErrInvalidParameter)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	records, err := readSpoolRecords(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	s := &spool{
+		path:     path,
+		maxBytes: maxBytes,
+	}
+	var size int64
+	first := len(records)
+	for first > 0 && size+int64(len(records[first-1])) <= maxBytes {
+		first--
+		size += int64(len(records[first]))
+	}
+	s.dropped = uint64(first)
+	records = records[first:]
+	for _, r := range records {
+		s.sizes = append(s.sizes, int64(len(r)))
+	}
+	s.size = size
+	if err := s.rewrite(bytes.Join(records, nil)); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return s, nil
+}
+
+// readSpoolRecords returns the complete records of the spool's file, each
+// with its newline.
+func readSpoolRecords(path string) ([][]byte, error) {
+	const op = "event.readSpoolRecords"
+	f, err := os.Open(path)
+	switch {
+	case os.IsNotExist(err):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer f.Close()
+	var records [][]byte
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// a last line without a newline was only partially written
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		var rec spoolRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return nil, fmt.Errorf("%s: %s has an invalid record: %w", op, path, err)
+		}
+		records = append(records, line)
+	}
+}
+
+// rewrite replaces the spool's file with data, which must be its records.
+func (s *spool) rewrite(data []byte) error {
+	const op = "event.(spool).rewrite"
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	f, err := os.OpenFile(s.path, os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if s.f != nil {
+		_ = s.f.Close()
+	}
+	s.f = f
+	s.offset = 0
+	return nil
+}
+
+// append writes the record to the end of the spool, dropping the oldest
+// records when it doesn't fit.  A record larger than the spool is dropped.
+func (s *spool) append(rec spoolRecord) error {
+	const op = "event.(spool).append"
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	data = append(data, '\n')
+	n := int64(len(data))
+	s.l.Lock()
+	defer s.l.Unlock()
+	if s.f == nil {
+		return fmt.Errorf("%s: spool is closed: %w", op, ErrIo)
+	}
+	if n > s.maxBytes {
+		s.dropped++
+		return nil
+	}
+	for s.size+n > s.maxBytes {
+		s.drop()
+	}
+	if _, err := s.f.Write(data); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	s.sizes = append(s.sizes, n)
+	s.size += n
+	return s.maybeCompact()
+}
+
+// peek returns the oldest record, and false when the spool is empty.
+func (s *spool) peek() (spoolRecord, bool, error) {
+	const op = "event.(spool).peek"
+	s.l.Lock()
+	defer s.l.Unlock()
+	if len(s.sizes) == 0 || s.f == nil {
+		return spoolRecord{}, false, nil
+	}
+	data := make([]byte, s.sizes[0])
+	if _, err := s.f.ReadAt(data, s.offset); err != nil {
+		return spoolRecord{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+	var rec spoolRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return spoolRecord{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+	return rec, true, nil
+}
+
+// pop removes the oldest record.
+func (s *spool) pop() error {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if len(s.sizes) == 0 || s.f == nil {
+		return nil
+	}
+	s.offset += s.sizes[0]
+	s.size -= s.sizes[0]
+	s.sizes = s.sizes[1:]
+	return s.maybeCompact()
+}
+
+// drop removes the oldest record to make room for a new one.  The caller must
+// hold the spool's lock.
+func (s *spool) drop() {
+	s.offset += s.sizes[0]
+	s.size -= s.sizes[0]
+	s.sizes = s.sizes[1:]
+	s.dropped++
+}
+
+// maybeCompact truncates the spool's file when it's empty, and removes the
+// replayed and dropped records from its front once they're more than half of
+// it.  The caller must hold the spool's lock.
+func (s *spool) maybeCompact() error {
+	const op = "event.(spool).maybeCompact"
+	switch {
+	case len(s.sizes) == 0:
+		if err := s.f.Truncate(0); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		s.offset = 0
+		return nil
+	case s.offset <= s.size:
+		return nil
+	}
+	data := make([]byte, s.size)
+	if _, err := s.f.ReadAt(data, s.offset); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.rewrite(data); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// len returns the number of records in the spool.
+func (s *spool) len() int {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return len(s.sizes)
+}
+
+// Dropped returns the number of records dropped because the spool was full.
+func (s *spool) Dropped() uint64 {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return s.dropped
+}
+
+// close compacts the spool, so only its records remain in the file to be
+// replayed by the next spool opened at its path, and closes its file.
+func (s *spool) close() error {
+	const op = "event.(spool).close"
+	s.l.Lock()
+	defer s.l.Unlock()
+	if s.f == nil {
+		return nil
+	}
+	if s.offset > 0 {
+		data := make([]byte, s.size)
+		if _, err := s.f.ReadAt(data, s.offset); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if err := s.rewrite(data); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	err := s.f.Close()
+	s.f = nil
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
@@ -0,0 +1,378 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSpoolSink is a sink node which records the formatted data of the events
+// it sends, and fails while its err is set.
+type testSpoolSink struct {
+	testFlakySink
+
+	sent []string
+}
+
+func (s *testSpoolSink) Process(ctx context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	if _, err := s.testFlakySink.Process(ctx, e); err != nil {
+		return nil, err
+	}
+	s.l.Lock()
+	defer s.l.Unlock()
+	data, _ := e.Format(string(JSONSinkFormat))
+	s.sent = append(s.sent, string(data))
+	return nil, nil
+}
+
+func (s *testSpoolSink) Sent() []string {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return append([]string(nil), s.sent...)
+}
+
+func testSpoolEvent(t Type, data string) *eventlogger.Event {
+	return &eventlogger.Event{
+		Type:      eventlogger.EventType(t),
+		CreatedAt: time.Now(),
+		Formatted: map[string][]byte{string(JSONSinkFormat): []byte(data)},
+	}
+}
+
+func Test_openSpool(t *testing.T) {
+	t.Parallel()
+	appendRecords := func(t *testing.T, s *spool, data ...string) {
+		t.Helper()
+		for _, d := range data {
+			require.NoError(t, s.append(spoolRecord{EventType: string(ObservationType), Data: []byte(d)}))
+		}
+	}
+	popRecords := func(t *testing.T, s *spool) []string {
+		t.Helper()
+		var got []string
+		for {
+			rec, ok, err := s.peek()
+			require.NoError(t, err)
+			if !ok {
+				return got
+			}
+			got = append(got, string(rec.Data))
+			require.NoError(t, s.pop())
+		}
+	}
+
+	t.Run("reopened", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		path := filepath.Join(t.TempDir(), "spool", "events")
+		s, err := openSpool(path, 1024)
+		require.NoError(err)
+		appendRecords(t, s, "first", "second", "third")
+		rec, ok, err := s.peek()
+		require.NoError(err)
+		require.True(ok)
+		assert.Equal("first", string(rec.Data))
+		require.NoError(s.pop())
+		require.NoError(s.close())
+
+		s, err = openSpool(path, 1024)
+		require.NoError(err)
+		t.Cleanup(func() { _ = s.close() })
+		assert.Equal(2, s.len())
+		assert.Equal([]string{"second", "third"}, popRecords(t, s))
+
+		// an empty spool's file is truncated
+		fi, err := os.Stat(path)
+		require.NoError(err)
+		assert.Zero(fi.Size())
+	})
+	t.Run("partial-last-record", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		path := filepath.Join(t.TempDir(), "events")
+		s, err := openSpool(path, 1024)
+		require.NoError(err)
+		appendRecords(t, s, "first")
+		require.NoError(s.close())
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+		require.NoError(err)
+		_, err = f.WriteString(`{"event_type":"obser`)
+		require.NoError(err)
+		require.NoError(f.Close())
+
+		s, err = openSpool(path, 1024)
+		require.NoError(err)
+		t.Cleanup(func() { _ = s.close() })
+		appendRecords(t, s, "second")
+		assert.Equal([]string{"first", "second"}, popRecords(t, s))
+	})
+	t.Run("invalid-record", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		path := filepath.Join(t.TempDir(), "events")
+		require.NoError(os.WriteFile(path, []byte("not json\n"), 0o600))
+		_, err := openSpool(path, 1024)
+		require.Error(err)
+		assert.Contains(err.Error(), "has an invalid record")
+	})
+	t.Run("overflow", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		path := filepath.Join(t.TempDir(), "events")
+		// each record is the same size, so the spool holds three of them
+		size := int64(len(`{"created_at":"0001-01-01T00:00:00Z","event_type":"observation","data":"ZXZlbnQtMQ=="}`) + 1)
+		s, err := openSpool(path, 3*size)
+		require.NoError(err)
+		appendRecords(t, s, "event-1", "event-2", "event-3", "event-4", "event-5")
+		assert.Equal(3, s.len())
+		assert.Equal(uint64(2), s.Dropped())
+
+		// a record larger than the spool is dropped
+		appendRecords(t, s, string(make([]byte, 3*size)))
+		assert.Equal(3, s.len())
+		assert.Equal(uint64(3), s.Dropped())
+		require.NoError(s.close())
+
+		// the oldest records which don't fit in a smaller spool are dropped
+		s, err = openSpool(path, 2*size)
+		require.NoError(err)
+		t.Cleanup(func() { _ = s.close() })
+		assert.Equal(uint64(1), s.Dropped())
+		assert.Equal([]string{"event-4", "event-5"}, popRecords(t, s))
+	})
+	t.Run("invalid-parameters", func(t *testing.T) {
+		_, err := openSpool("", 1024)
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+		_, err = openSpool(filepath.Join(t.TempDir(), "events"), 0)
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	})
+}
+
+func Test_circuitBreakerSink_spool(t *testing.T) {
+	t.Parallel()
+	testEventer := &Eventer{logger: hclog.NewNullLogger()}
+	testErr := errors.New("sink is down")
+	ctx := context.Background()
+	newTestSink := func(t *testing.T) (*circuitBreakerSink, *testSpoolSink, *time.Time) {
+		t.Helper()
+		node := &testSpoolSink{}
+		s, err := newCircuitBreakerSink(testEventer, "test", node, circuitBreaker{threshold: 1, cooldown: time.Minute}, false)
+		require.NoError(t, err)
+		sp, err := openSpool(filepath.Join(t.TempDir(), "events"), defaultSpoolMaxBytes)
+		require.NoError(t, err)
+		s.withSpool(sp, string(JSONSinkFormat))
+		t.Cleanup(func() { _ = s.Close() })
+		now := time.Now()
+		s.now = func() time.Time { return now }
+		return s, node, &now
+	}
+	replayed := func(s *circuitBreakerSink) func() bool {
+		return func() bool { return s.Spooled() == 0 && !s.isReplaying() }
+	}
+
+	t.Run("spill-and-replay-on-health-check", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		s, node, now := newTestSink(t)
+		node.setErr(testErr)
+
+		// the failed event and the events sent while the breaker is open
+		// are spooled rather than dropped
+		for i := 1; i <= 3; i++ {
+			_, err := s.Process(ctx, testSpoolEvent(ObservationType, fmt.Sprintf("event-%d", i)))
+			require.NoError(err)
+		}
+		assert.Equal(CircuitOpen, s.State())
+		assert.Equal(3, s.Spooled())
+		assert.Equal(uint64(0), s.Dropped())
+		assert.Equal(1, node.Calls())
+
+		// events with an enforced delivery aren't spooled
+		_, err := s.Process(ctx, testSpoolEvent(AuditType, "audit"))
+		require.Error(err)
+		assert.ErrorIs(err, ErrCircuitOpen)
+		assert.Equal(3, s.Spooled())
+
+		// the health check doesn't replay the spool during the cooldown
+		assert.ErrorIs(s.checkHealth(ctx), ErrCircuitOpen)
+		assert.Equal(3, s.Spooled())
+
+		node.setErr(nil)
+		*now = now.Add(time.Minute)
+		_ = s.checkHealth(ctx)
+		require.Eventually(replayed(s), time.Second, time.Millisecond)
+		assert.Equal(CircuitClosed, s.State())
+		assert.Equal([]string{"event-1", "event-2", "event-3"}, node.Sent())
+
+		// once the spool is empty, events are sent directly
+		_, err = s.Process(ctx, testSpoolEvent(ObservationType, "event-4"))
+		require.NoError(err)
+		assert.Equal([]string{"event-1", "event-2", "event-3", "event-4"}, node.Sent())
+	})
+	t.Run("replay-on-next-event", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		s, node, now := newTestSink(t)
+		node.setErr(testErr)
+		for i := 1; i <= 2; i++ {
+			_, err := s.Process(ctx, testSpoolEvent(ObservationType, fmt.Sprintf("event-%d", i)))
+			require.NoError(err)
+		}
+
+		// the next event after the cooldown starts the replay, and is
+		// replayed after the events spooled before it
+		node.setErr(nil)
+		*now = now.Add(time.Minute)
+		_, err := s.Process(ctx, testSpoolEvent(ObservationType, "event-3"))
+		require.NoError(err)
+		require.Eventually(replayed(s), time.Second, time.Millisecond)
+		assert.Equal(CircuitClosed, s.State())
+		assert.Equal([]string{"event-1", "event-2", "event-3"}, node.Sent())
+	})
+	t.Run("failed-replay", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		s, node, now := newTestSink(t)
+		node.setErr(testErr)
+		_, err := s.Process(ctx, testSpoolEvent(ObservationType, "event-1"))
+		require.NoError(err)
+
+		// the failed probe leaves the event spooled and opens the breaker
+		// for another cooldown
+		*now = now.Add(time.Minute)
+		_ = s.checkHealth(ctx)
+		require.Eventually(func() bool { return !s.isReplaying() }, time.Second, time.Millisecond)
+		assert.Equal(CircuitOpen, s.State())
+		assert.Equal(1, s.Spooled())
+		assert.Equal(2, node.Calls())
+		assert.Empty(node.Sent())
+	})
+	t.Run("kept-across-close", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		node := &testSpoolSink{}
+		node.setErr(testErr)
+		path := filepath.Join(t.TempDir(), "events")
+		newSink := func() *circuitBreakerSink {
+			s, err := newCircuitBreakerSink(testEventer, "test", node, circuitBreaker{threshold: 1, cooldown: time.Minute}, false)
+			require.NoError(err)
+			sp, err := openSpool(path, defaultSpoolMaxBytes)
+			require.NoError(err)
+			s.withSpool(sp, string(JSONSinkFormat))
+			return s
+		}
+		s := newSink()
+		_, err := s.Process(ctx, testSpoolEvent(ObservationType, "event-1"))
+		require.NoError(err)
+		require.NoError(s.Close())
+
+		node.setErr(nil)
+		s = newSink()
+		t.Cleanup(func() { _ = s.Close() })
+		assert.Equal(1, s.Spooled())
+		assert.NoError(s.checkHealth(ctx))
+		require.Eventually(replayed(s), time.Second, time.Millisecond)
+		assert.Equal([]string{"event-1"}, node.Sent())
+	})
+	t.Run("replayed-kafka-partition-key", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		p := &testKafkaProducer{failures: 1}
+		node, err := newKafkaSink(testEventer, &KafkaSinkConfig{Topic: "events", PartitionKey: CorrelationIdPartitionKey}, string(JSONSinkFormat), func(*KafkaSinkConfig) (KafkaProducer, error) { return p, nil })
+		require.NoError(err)
+		s, err := newCircuitBreakerSink(testEventer, "test", node, circuitBreaker{threshold: 1, cooldown: time.Minute}, false)
+		require.NoError(err)
+		path := filepath.Join(t.TempDir(), "events")
+		sp, err := openSpool(path, defaultSpoolMaxBytes)
+		require.NoError(err)
+		s.withSpool(sp, string(JSONSinkFormat))
+		t.Cleanup(func() { _ = s.Close() })
+		now := time.Now()
+		s.now = func() time.Time { return now }
+
+		// the event is spooled while kafka is down, and keyed by its
+		// correlation id when it's replayed, even after the spool is
+		// reopened
+		p.failures = stdRetryCount + 1
+		ev := testSpoolEvent(SystemType, "event-1")
+		ev.Payload = &sysEvent{CorrelationId: "test-correlation-id"}
+		_, err = s.Process(ctx, ev)
+		require.NoError(err)
+		require.Equal(1, s.Spooled())
+		require.NoError(s.spool.close())
+		s.spool, err = openSpool(path, defaultSpoolMaxBytes)
+		require.NoError(err)
+
+		now = now.Add(time.Minute)
+		_ = s.checkHealth(ctx)
+		require.Eventually(replayed(s), time.Second, time.Millisecond)
+		require.Len(p.messages, 1)
+		assert.Equal("test-correlation-id", string(p.messages[0].key))
+		assert.Equal("event-1", string(p.messages[0].value))
+	})
+	t.Run("enforced-sink", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		node := &testSpoolSink{}
+		node.setErr(testErr)
+		s, err := newCircuitBreakerSink(testEventer, "test", node, circuitBreaker{threshold: 1, cooldown: time.Minute}, true)
+		require.NoError(err)
+		sp, err := openSpool(filepath.Join(t.TempDir(), "events"), defaultSpoolMaxBytes)
+		require.NoError(err)
+		s.withSpool(sp, string(JSONSinkFormat))
+		t.Cleanup(func() { _ = s.Close() })
+
+		_, err = s.Process(ctx, testSpoolEvent(ObservationType, "event-1"))
+		require.Error(err)
+		assert.ErrorIs(err, testErr)
+		assert.Zero(s.Spooled())
+	})
+}
+
+func TestEventer_spool(t *testing.T) {
+	t.Parallel()
+	testLock := &sync.Mutex{}
+	testLogger := hclog.New(&hclog.LoggerOptions{
+		Mutex: testLock,
+		Name:  "test",
+	})
+	c := &testCollector{}
+	addr := testStartCollector(t, c)
+	spoolPath := filepath.Join(t.TempDir(), "grpc.spool")
+	conf := EventerConfig{
+		SysEventsEnabled: true,
+		Sinks: []SinkConfig{
+			{
+				Name:       "grpc",
+				SinkType:   GRPCSink,
+				EventTypes: []Type{SystemType},
+				Format:     JSONSinkFormat,
+				GRPCConfig: &GRPCSinkConfig{Address: addr},
+				SpoolPath:  spoolPath,
+			},
+			{
+				Name:       "errors",
+				SinkType:   DiscardSink,
+				EventTypes: []Type{ErrorType},
+				Format:     JSONSinkFormat,
+			},
+		},
+	}
+	e, err := NewEventer(testLogger, testLock, conf, WithNoBackoffJitter())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = e.Close(context.Background()) })
+
+	// events spooled by an earlier eventer are replayed by the health check
+	cb := e.sysPipelines[0].circuitBreaker()
+	require.NotNil(t, cb)
+	require.NoError(t, cb.spool.append(spoolRecord{CreatedAt: time.Now(), EventType: string(SystemType), Data: []byte(`{"id":"spooled"}`)}))
+	h := e.Health(context.Background())
+	require.Eventually(t, func() bool { return cb.Spooled() == 0 }, time.Second, time.Millisecond)
+	assert.Equal(t, "grpc", h.EventTypes[SystemType].Sinks[0].Name)
+
+	require.NoError(t, e.writeSysEvent(context.Background(), &sysEvent{Id: "sys-id", Op: "TestEventer_spool"}))
+	_, _, events := c.state()
+	require.Len(t, events, 2)
+	assert.Equal(t, `{"id":"spooled"}`, string(events[0].Data))
+	assert.Contains(t, string(events[1].Data), "TestEventer_spool")
+}
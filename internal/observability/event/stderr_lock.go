@@ -0,0 +1,68 @@
+package event
+
+import "sync"
+
+// stderrLock is the process's serialization lock for os.Stderr (see
+// StderrLock).
+var stderrLock sync.Mutex
+
+// StderrLock returns the process's serialization lock for os.Stderr.
+//
+// An eventer serializes the writes of its StderrSinks with the lock it's
+// created with, so the output of eventers which write to os.Stderr is only
+// kept from being interleaved when they share a lock.  Every eventer in a
+// process which writes to os.Stderr, and anything else which writes to it like
+// the process's loggers, should be given this lock.  NewEventer logs a warning
+// when an eventer which writes to os.Stderr has a different lock than another
+// open eventer which does.
+func StderrLock() *sync.Mutex {
+	return &stderrLock
+}
+
+// stderrLocks tracks the serialization locks of the open eventers which write
+// to os.Stderr, so an eventer with a different lock than the others can be
+// reported.
+type stderrLocks struct {
+	l     sync.Mutex
+	locks map[*sync.Mutex]int
+}
+
+// openStderrLocks are the locks of the process's open eventers which write to
+// os.Stderr.
+var openStderrLocks stderrLocks
+
+// add records the lock of an eventer which writes to os.Stderr, and returns
+// false when another open eventer has a different lock.
+func (s *stderrLocks) add(l *sync.Mutex) bool {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if s.locks == nil {
+		s.locks = map[*sync.Mutex]int{}
+	}
+	s.locks[l]++
+	return len(s.locks) == 1
+}
+
+// remove the lock of an eventer once it's closed.
+func (s *stderrLocks) remove(l *sync.Mutex) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if s.locks[l] <= 1 {
+		delete(s.locks, l)
+		return
+	}
+	s.locks[l]--
+}
+
+// writesStderr returns true when one of the sinks, or their mirrors, writes to
+// the eventer's stderr rather than a writer group.
+func writesStderr(sinks []SinkConfig) bool {
+	for _, s := range sinks {
+		for _, s := range append([]SinkConfig{s}, s.mirrorConfigs()...) {
+			if s.SinkType == StderrSink && s.WriterGroup == "" {
+				return true
+			}
+		}
+	}
+	return false
+}
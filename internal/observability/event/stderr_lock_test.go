@@ -0,0 +1,118 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testByteWriter writes one byte at a time, yielding between them, so
+// concurrent writes which aren't serialized are interleaved.
+type testByteWriter struct {
+	l   sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *testByteWriter) Write(p []byte) (int, error) {
+	for _, b := range p {
+		w.l.Lock()
+		w.buf.WriteByte(b)
+		w.l.Unlock()
+		runtime.Gosched()
+	}
+	return len(p), nil
+}
+
+func (w *testByteWriter) String() string {
+	w.l.Lock()
+	defer w.l.Unlock()
+	return w.buf.String()
+}
+
+func Test_stderrLocks(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+	var locks stderrLocks
+	shared, other := &sync.Mutex{}, &sync.Mutex{}
+	assert.True(locks.add(shared))
+	assert.True(locks.add(shared))
+	assert.False(locks.add(other))
+
+	// once the eventers with the other lock are closed, the shared lock is
+	// consistent again
+	locks.remove(other)
+	assert.True(locks.add(shared))
+	locks.remove(shared)
+	locks.remove(shared)
+	locks.remove(shared)
+	assert.True(locks.add(other))
+}
+
+func TestEventer_sharedStderrLock(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	c := EventerConfig{
+		SysEventsEnabled: true,
+		Sinks: []SinkConfig{
+			{
+				Name:       "stderr",
+				SinkType:   StderrSink,
+				EventTypes: []Type{EveryType},
+				Format:     JSONSinkFormat,
+			},
+		},
+	}
+
+	t.Run("not-interleaved", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		w := &testByteWriter{}
+		lock := &sync.Mutex{}
+		eventers := make([]*Eventer, 2)
+		for i := range eventers {
+			e, err := NewEventer(hclog.NewNullLogger(), lock, c, WithStderrWriter(w))
+			require.NoError(err)
+			t.Cleanup(func() { _ = e.Close(ctx) })
+			eventers[i] = e
+		}
+
+		const eventsPerEventer = 20
+		var wg sync.WaitGroup
+		for i, e := range eventers {
+			for j := 0; j < eventsPerEventer; j++ {
+				wg.Add(1)
+				go func(e *Eventer, id string) {
+					defer wg.Done()
+					assert.NoError(e.writeSysEvent(ctx, &sysEvent{Id: Id(id), Op: "TestEventer_sharedStderrLock", Data: map[string]interface{}{"msg": id}}))
+				}(e, fmt.Sprintf("eventer-%d-event-%d", i, j))
+			}
+		}
+		wg.Wait()
+
+		lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+		require.Len(lines, len(eventers)*eventsPerEventer)
+		for _, line := range lines {
+			var got map[string]interface{}
+			assert.NoErrorf(json.Unmarshal([]byte(line), &got), "interleaved line: %s", line)
+		}
+	})
+	t.Run("different-locks-warning", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		var logs bytes.Buffer
+		logger := hclog.New(&hclog.LoggerOptions{Output: &logs})
+		for i := 0; i < 2; i++ {
+			e, err := NewEventer(logger, &sync.Mutex{}, c)
+			require.NoError(err)
+			t.Cleanup(func() { _ = e.Close(ctx) })
+		}
+		assert.Contains(logs.String(), "eventers which write to stderr have different serialization locks")
+	})
+}
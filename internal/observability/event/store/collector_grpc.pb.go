@@ -0,0 +1,141 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package store
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// EventCollectorServiceClient is the client API for EventCollectorService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type EventCollectorServiceClient interface {
+	// SendEvents streams batches of events to the collector.  The collector
+	// responds to each batch with an ack, once it has received (and, when it
+	// persists events, persisted) the batch's events.  Batches are acked in
+	// the order they're sent.
+	SendEvents(ctx context.Context, opts ...grpc.CallOption) (EventCollectorService_SendEventsClient, error)
+}
+
+type eventCollectorServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEventCollectorServiceClient(cc grpc.ClientConnInterface) EventCollectorServiceClient {
+	return &eventCollectorServiceClient{cc}
+}
+
+func (c *eventCollectorServiceClient) SendEvents(ctx context.Context, opts ...grpc.CallOption) (EventCollectorService_SendEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &EventCollectorService_ServiceDesc.Streams[0], "/observability.event.store.v1.EventCollectorService/SendEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &eventCollectorServiceSendEventsClient{stream}
+	return x, nil
+}
+
+type EventCollectorService_SendEventsClient interface {
+	Send(*SendEventsRequest) error
+	Recv() (*SendEventsResponse, error)
+	grpc.ClientStream
+}
+
+type eventCollectorServiceSendEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *eventCollectorServiceSendEventsClient) Send(m *SendEventsRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *eventCollectorServiceSendEventsClient) Recv() (*SendEventsResponse, error) {
+	m := new(SendEventsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// EventCollectorServiceServer is the server API for EventCollectorService service.
+// All implementations must embed UnimplementedEventCollectorServiceServer
+// for forward compatibility
+type EventCollectorServiceServer interface {
+	// SendEvents streams batches of events to the collector.  The collector
+	// responds to each batch with an ack, once it has received (and, when it
+	// persists events, persisted) the batch's events.  Batches are acked in
+	// the order they're sent.
+	SendEvents(EventCollectorService_SendEventsServer) error
+	mustEmbedUnimplementedEventCollectorServiceServer()
+}
+
+// UnimplementedEventCollectorServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedEventCollectorServiceServer struct {
+}
+
+func (UnimplementedEventCollectorServiceServer) SendEvents(EventCollectorService_SendEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SendEvents not implemented")
+}
+func (UnimplementedEventCollectorServiceServer) mustEmbedUnimplementedEventCollectorServiceServer() {}
+
+// UnsafeEventCollectorServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EventCollectorServiceServer will
+// result in compilation errors.
+type UnsafeEventCollectorServiceServer interface {
+	mustEmbedUnimplementedEventCollectorServiceServer()
+}
+
+func RegisterEventCollectorServiceServer(s grpc.ServiceRegistrar, srv EventCollectorServiceServer) {
+	s.RegisterService(&EventCollectorService_ServiceDesc, srv)
+}
+
+func _EventCollectorService_SendEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(EventCollectorServiceServer).SendEvents(&eventCollectorServiceSendEventsServer{stream})
+}
+
+type EventCollectorService_SendEventsServer interface {
+	Send(*SendEventsResponse) error
+	Recv() (*SendEventsRequest, error)
+	grpc.ServerStream
+}
+
+type eventCollectorServiceSendEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *eventCollectorServiceSendEventsServer) Send(m *SendEventsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *eventCollectorServiceSendEventsServer) Recv() (*SendEventsRequest, error) {
+	m := new(SendEventsRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// EventCollectorService_ServiceDesc is the grpc.ServiceDesc for EventCollectorService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var EventCollectorService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "observability.event.store.v1.EventCollectorService",
+	HandlerType: (*EventCollectorServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SendEvents",
+			Handler:       _EventCollectorService_SendEvents_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "observability/event/store/v1/collector.proto",
+}
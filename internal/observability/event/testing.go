@@ -1,9 +1,14 @@
 package event
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -11,6 +16,8 @@ import (
 	"github.com/hashicorp/boundary/internal/gen/controller/api/resources/groups"
 	pbs "github.com/hashicorp/boundary/internal/gen/controller/api/services"
 	"github.com/hashicorp/eventlogger"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 )
@@ -150,6 +157,112 @@ func TestEventerConfig(t *testing.T, testName string, opt ...Option) TestConfig
 	return c
 }
 
+// TestEvent is an event captured by a SinkBuffer, decoded from its json.
+type TestEvent struct {
+	CreatedAt time.Time              `json:"created_at"`
+	EventType Type                   `json:"event_type"`
+	Payload   map[string]interface{} `json:"payload"`
+}
+
+// SinkBuffer captures the events written by an eventer created with
+// NewTestEventer.
+type SinkBuffer struct {
+	l   sync.Mutex
+	buf bytes.Buffer
+}
+
+// Write is the io.Writer of the test eventer's sink.
+func (b *SinkBuffer) Write(p []byte) (int, error) {
+	b.l.Lock()
+	defer b.l.Unlock()
+	return b.buf.Write(p)
+}
+
+// String returns the json of the captured events, one event per line.
+func (b *SinkBuffer) String() string {
+	b.l.Lock()
+	defer b.l.Unlock()
+	return b.buf.String()
+}
+
+// Reset discards the captured events.
+func (b *SinkBuffer) Reset() {
+	b.l.Lock()
+	defer b.l.Unlock()
+	b.buf.Reset()
+}
+
+// Events returns the captured events, in the order they were written.  When
+// types are provided, only events of those types are returned.
+func (b *SinkBuffer) Events(t *testing.T, types ...Type) []TestEvent {
+	t.Helper()
+	var events []TestEvent
+	scanner := bufio.NewScanner(bytes.NewBufferString(b.String()))
+	scanner.Buffer(nil, 1<<24)
+	for scanner.Scan() {
+		var e TestEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e), "unable to decode captured event: %s", scanner.Text())
+		if len(types) > 0 && !containsType(types, e.EventType) {
+			continue
+		}
+		events = append(events, e)
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+// AssertCount asserts the number of captured events of type typ (or of every
+// type, when it's EveryType).
+func (b *SinkBuffer) AssertCount(t *testing.T, typ Type, want int) bool {
+	t.Helper()
+	var events []TestEvent
+	switch typ {
+	case EveryType:
+		events = b.Events(t)
+	default:
+		events = b.Events(t, typ)
+	}
+	return assert.Lenf(t, events, want, "number of %s events", typ)
+}
+
+func containsType(types []Type, typ Type) bool {
+	for _, t := range types {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// NewTestEventer creates an eventer which writes every type of event as json
+// to the returned SinkBuffer, and registers a cleanup func which closes it.
+// Audit events, observations and system events are all enabled.  The options
+// are passed to NewEventer.
+func NewTestEventer(t *testing.T, opt ...Option) (*Eventer, *SinkBuffer) {
+	t.Helper()
+	c := EventerConfig{
+		AuditEnabled:        true,
+		ObservationsEnabled: true,
+		SysEventsEnabled:    true,
+		Sinks: []SinkConfig{
+			{
+				Name:       "test-sink",
+				SinkType:   StderrSink,
+				EventTypes: []Type{EveryType},
+				Format:     JSONSinkFormat,
+			},
+		},
+	}
+	buf := &SinkBuffer{}
+	// the buffer's writer is last, so it's used in place of any other stderr
+	// writer
+	opt = append(opt, WithStderrWriter(buf))
+	e, err := NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, c, opt...)
+	require.NoError(t, err)
+	t.Cleanup(func() { e.Close(context.Background()) })
+	return e, buf
+}
+
 // TestRequestInfo provides a test RequestInfo
 func TestRequestInfo(t *testing.T) *RequestInfo {
 	t.Helper()
@@ -235,7 +348,22 @@ type testMockBroker struct {
 	successThresholds map[eventlogger.EventType]int
 	pipelines         []eventlogger.Pipeline
 
-	errorOnSend error
+	errorOnSend    error
+	warningsOnSend []error
+
+	// deliveredOnSend and failedOnSend are the sinks recorded for a send's
+	// SendResult, as if the broker's sink nodes had written the event.
+	deliveredOnSend []string
+	failedOnSend    []string
+
+	// errorOnRegisterPipeline fails the registration of the pipeline with
+	// this number, counting from one.
+	errorOnRegisterPipeline int
+	registerPipelineCalls   int
+	removedPipelines        []eventlogger.PipelineID
+
+	// sent are the payloads sent, by event type.
+	sent map[eventlogger.EventType][]interface{}
 }
 
 func (b *testMockBroker) Reopen(ctx context.Context) error {
@@ -244,15 +372,42 @@ func (b *testMockBroker) Reopen(ctx context.Context) error {
 }
 
 func (b *testMockBroker) RegisterPipeline(def eventlogger.Pipeline) error {
+	b.registerPipelineCalls++
+	if b.registerPipelineCalls == b.errorOnRegisterPipeline {
+		return fmt.Errorf("unable to register pipeline %s", def.PipelineID)
+	}
 	b.pipelines = append(b.pipelines, def)
 	return nil
 }
 
+func (b *testMockBroker) RemovePipeline(t eventlogger.EventType, id eventlogger.PipelineID) error {
+	for i, p := range b.pipelines {
+		if p.EventType == t && p.PipelineID == id {
+			b.pipelines = append(b.pipelines[:i], b.pipelines[i+1:]...)
+			b.removedPipelines = append(b.removedPipelines, id)
+			return nil
+		}
+	}
+	return fmt.Errorf("pipeline %s isn't registered", id)
+}
+
 func (b *testMockBroker) Send(ctx context.Context, t eventlogger.EventType, payload interface{}) (eventlogger.Status, error) {
+	if b.sent == nil {
+		b.sent = map[eventlogger.EventType][]interface{}{}
+	}
+	b.sent[t] = append(b.sent[t], payload)
+	if r, ok := sendRecorderFromContext(ctx); ok {
+		for _, name := range b.deliveredOnSend {
+			r.record(name, nil)
+		}
+		for _, name := range b.failedOnSend {
+			r.record(name, fmt.Errorf("%s: failed", name))
+		}
+	}
 	if b.errorOnSend != nil {
-		return eventlogger.Status{}, b.errorOnSend
+		return eventlogger.Status{Warnings: b.warningsOnSend}, b.errorOnSend
 	}
-	return eventlogger.Status{}, nil
+	return eventlogger.Status{Warnings: b.warningsOnSend}, nil
 }
 
 func (b *testMockBroker) StopTimeAt(t time.Time) {
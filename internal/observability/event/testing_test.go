@@ -0,0 +1,43 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTestEventer(t *testing.T) {
+	t.Parallel()
+	assert, require := assert.New(t), require.New(t)
+	ctx := context.Background()
+	e, buf := NewTestEventer(t, WithSchemaVersion("v0.2"))
+
+	testAudit, err := newAudit("TestNewTestEventer", WithFlush())
+	require.NoError(err)
+	require.NoError(e.writeAudit(ctx, testAudit))
+	for i := 0; i < 2; i++ {
+		testError, err := newError("TestNewTestEventer", fmt.Errorf("%d: %w", i, ErrIo))
+		require.NoError(err)
+		require.NoError(e.writeError(ctx, testError))
+	}
+
+	buf.AssertCount(t, EveryType, 3)
+	buf.AssertCount(t, AuditType, 1)
+	buf.AssertCount(t, ErrorType, 2)
+	buf.AssertCount(t, ObservationType, 0)
+
+	errs := buf.Events(t, ErrorType)
+	require.Len(errs, 2)
+	assert.Equal(ErrorType, errs[0].EventType)
+	assert.Equal("TestNewTestEventer", errs[0].Payload[OpField])
+	assert.Equal("v0.2", errs[0].Payload[VersionField])
+	assert.False(errs[0].CreatedAt.IsZero())
+	assert.Len(buf.Events(t, AuditType, ErrorType), 3)
+
+	buf.Reset()
+	assert.Empty(buf.Events(t))
+	assert.Empty(buf.String())
+}
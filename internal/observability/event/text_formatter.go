@@ -0,0 +1,128 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+)
+
+// textFormatter is a formatter node which formats an event as a single line
+// of text, which is easier for humans to read than JSON:
+//
+//	<created at> [<event type>] <key>=<value> <key>=<value> ...
+//
+// The keys are the event payload's JSON fields, with nested fields joined by
+// dots (e.g. request.operation), sorted so the output is deterministic.  When
+// epochMillis is true, the created at timestamp is the number of milliseconds
+// since the Unix epoch rather than an RFC3339 string.
+type textFormatter struct {
+	epochMillis bool
+}
+
+var _ eventlogger.Node = &textFormatter{}
+
+// Process formats the event as text and stores the formatted data in the
+// event's Formatted field with a key of "text" (or "text-epoch-millis" when
+// epochMillis)
+func (f *textFormatter) Process(_ context.Context, e *eventlogger.Event) (*eventlogger.Event, error) {
+	const op = "event.(textFormatter).Process"
+	if e == nil {
+		return nil, fmt.Errorf("%s: missing event: %w", op, ErrInvalidParameter)
+	}
+	m, err := eventAsMap(e)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	fields := map[string]string{}
+	prefix := "payload"
+	if _, ok := m[prefix].(map[string]interface{}); ok {
+		prefix = ""
+	}
+	if err := flattenTextFields(prefix, m["payload"], fields); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	if f.epochMillis {
+		sb.WriteString(strconv.FormatInt(e.CreatedAt.UnixNano()/int64(time.Millisecond), 10))
+	} else {
+		sb.WriteString(e.CreatedAt.Format(time.RFC3339Nano))
+	}
+	sb.WriteString(" [")
+	sb.WriteString(string(e.Type))
+	sb.WriteString("]")
+	for _, k := range keys {
+		sb.WriteString(" ")
+		sb.WriteString(k)
+		sb.WriteString("=")
+		sb.WriteString(fields[k])
+	}
+	sb.WriteString("\n")
+	format := string(TextSinkFormat)
+	if f.epochMillis {
+		format += epochMillisFormatSuffix
+	}
+	e.FormattedAs(format, []byte(sb.String()))
+	return e, nil
+}
+
+// Reopen is a no op
+func (f *textFormatter) Reopen() error {
+	return nil
+}
+
+// Type describes the type of the node as a Formatter.
+func (f *textFormatter) Type() eventlogger.NodeType {
+	return eventlogger.NodeTypeFormatter
+}
+
+// flattenTextFields adds the fields of v to fields, with the keys of nested
+// maps joined by dots.  Arrays are added as JSON.
+func flattenTextFields(prefix string, v interface{}, fields map[string]string) error {
+	const op = "event.flattenTextFields"
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, nested := range val {
+			if prefix != "" {
+				k = prefix + "." + k
+			}
+			if err := flattenTextFields(k, nested, fields); err != nil {
+				return err
+			}
+		}
+		return nil
+	case string:
+		fields[prefix] = textValue(val)
+	case []interface{}:
+		b, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Errorf("%s: unable to marshal %s: %w", op, prefix, err)
+		}
+		fields[prefix] = textValue(string(b))
+	case nil:
+		fields[prefix] = "null"
+	default:
+		fields[prefix] = fmt.Sprint(val)
+	}
+	return nil
+}
+
+// textValue quotes the value if it's empty or contains characters which
+// would make the line ambiguous.
+func textValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\r\n=\"") {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,77 @@
+package event
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/eventlogger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_textFormatter_Process(t *testing.T) {
+	t.Parallel()
+	now := time.Unix(1625000000, 0).UTC()
+	tests := []struct {
+		name      string
+		event     *eventlogger.Event
+		want      string
+		wantErrIs error
+	}{
+		{
+			name:      "missing-event",
+			wantErrIs: ErrInvalidParameter,
+		},
+		{
+			name: "system",
+			event: &eventlogger.Event{
+				Type:      eventlogger.EventType(SystemType),
+				CreatedAt: now,
+				Payload: &sysEvent{
+					Id:   "sys-id",
+					Op:   "test.op",
+					Data: map[string]interface{}{"msg": "hello world", "count": 2, "list": []string{"a", "b"}, "empty": ""},
+				},
+			},
+			want: `2021-06-29T20:53:20Z [system] data.count=2 data.empty="" data.list="[\"a\",\"b\"]" data.msg="hello world" id=sys-id op=test.op version=` + "\"\"\n",
+		},
+		{
+			name: "non-map-payload",
+			event: &eventlogger.Event{
+				Type:      eventlogger.EventType(SystemType),
+				CreatedAt: now,
+				Payload:   "a=b",
+			},
+			want: `2021-06-29T20:53:20Z [system] payload="a=b"` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+			got, err := (&textFormatter{}).Process(context.Background(), tt.event)
+			if tt.wantErrIs != nil {
+				require.Error(err)
+				assert.ErrorIs(err, tt.wantErrIs)
+				return
+			}
+			require.NoError(err)
+			b, ok := got.Format(string(TextSinkFormat))
+			require.True(ok)
+			assert.Equal(tt.want, string(b))
+		})
+	}
+	t.Run("epoch-millis", func(t *testing.T) {
+		assert, require := assert.New(t), require.New(t)
+		e := &eventlogger.Event{
+			Type:      eventlogger.EventType(SystemType),
+			CreatedAt: now.Add(123 * time.Millisecond),
+			Payload:   "a=b",
+		}
+		got, err := (&textFormatter{epochMillis: true}).Process(context.Background(), e)
+		require.NoError(err)
+		b, ok := got.Format("text-epoch-millis")
+		require.True(ok)
+		assert.Equal(`1625000000123 [system] payload="a=b"`+"\n", string(b))
+	})
+}
@@ -140,6 +140,15 @@ func WrapWithEventsHandler(h http.Handler, e *event.Eventer, logger hclog.Logger
 			logger.Trace("unable to create context with eventer", "method", r.Method, "url", r.URL.RequestURI(), "error", err)
 			return
 		}
+		// the request's identity is filled in once it has been
+		// authenticated, so it's recorded by the audit events written after
+		// that
+		ctx, err = event.WithAuthInfo(ctx, &event.AuthInfo{})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			logger.Trace("unable to create context with auth info", "method", r.Method, "url", r.URL.RequestURI(), "error", err)
+			return
+		}
 
 		// Set the context back on the request
 		r = r.WithContext(ctx)
@@ -347,6 +347,7 @@ func Test_WrapWithEventsHandler(t *testing.T) {
 					"id":              got.Payload["id"].(string),
 					"timestamp":       got.Payload["timestamp"].(string),
 					"serialized_hmac": got.Payload["serialized_hmac"].(string),
+					"auth_info":       map[string]interface{}{"authenticated": false},
 				}
 				wantJson := testJson(t, event.AuditType, &info, event.Op(tt.name), got, hdr, nil)
 				assert.JSONEq(string(wantJson), string(actualJson))
@@ -499,6 +500,9 @@ func (b *testMockBroker) RegisterNode(id eventlogger.NodeID, node eventlogger.No
 func (b *testMockBroker) SetSuccessThreshold(t eventlogger.EventType, successThreshold int) error {
 	return nil
 }
+func (b *testMockBroker) RemovePipeline(t eventlogger.EventType, id eventlogger.PipelineID) error {
+	return nil
+}
 
 type eventJson struct {
 	CreatedAt string                 `json:"created_at"`
@@ -147,6 +147,7 @@ func TestHandleImplementedPaths(t *testing.T) {
 			"v1/accounts/someid:change-password",
 			"v1/auth-methods/someid:authenticate",
 			"v1/auth-methods/someid:authenticate:login",
+			"v1/credential-stores:test-connection",
 			"v1/groups/someid:add-members",
 			"v1/groups/someid:set-members",
 			"v1/groups/someid:remove-members",
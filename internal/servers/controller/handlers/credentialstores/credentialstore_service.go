@@ -325,6 +325,23 @@ func (s Service) DeleteCredentialStore(ctx context.Context, req *pbs.DeleteCrede
 	return nil, nil
 }
 
+// TestCredentialStoreConnection implements the interface pbs.CredentialStoreServiceServer.
+// It's authorized as creating the credential store, since the connection is
+// checked with the configuration the store would be created with.
+func (s Service) TestCredentialStoreConnection(ctx context.Context, req *pbs.TestCredentialStoreConnectionRequest) (*pbs.TestCredentialStoreConnectionResponse, error) {
+	if err := validateTestConnectionRequest(req); err != nil {
+		return nil, err
+	}
+	authResults := s.authResult(ctx, req.GetItem().GetScopeId(), action.Create)
+	if authResults.Error != nil {
+		return nil, authResults.Error
+	}
+	if err := s.checkConnectionInRepo(ctx, authResults.Scope.GetId(), req.GetItem()); err != nil {
+		return nil, err
+	}
+	return &pbs.TestCredentialStoreConnectionResponse{}, nil
+}
+
 func (s Service) listFromRepo(ctx context.Context, scopeIds []string) ([]*vault.CredentialStore, error) {
 	const op = "credentialstores.(Service).listFromRepo"
 	repo, err := s.repoFn()
@@ -374,6 +391,22 @@ func (s Service) createInRepo(ctx context.Context, projId string, item *pb.Crede
 	return out, nil
 }
 
+func (s Service) checkConnectionInRepo(ctx context.Context, projId string, item *pb.CredentialStore) error {
+	const op = "credentialstores.(Service).checkConnectionInRepo"
+	cs, err := toStorageVaultStore(projId, item)
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	repo, err := s.repoFn()
+	if err != nil {
+		return errors.Wrap(err, op)
+	}
+	if err := repo.CheckCredentialStoreConnection(ctx, cs); err != nil {
+		return errors.Wrap(err, op, errors.WithMsg("unable to connect to the credential store"))
+	}
+	return nil
+}
+
 func (s Service) updateInRepo(ctx context.Context, projId, id string, mask []string, item *pb.CredentialStore) (credential.Store, error) {
 	const op = "credentialstores.(Service).updateInRepo"
 	cs, err := toStorageVaultStore(projId, item)
@@ -652,6 +685,10 @@ func validateCreateRequest(req *pbs.CreateCredentialStoreRequest) error {
 	})
 }
 
+func validateTestConnectionRequest(req *pbs.TestCredentialStoreConnectionRequest) error {
+	return validateCreateRequest(&pbs.CreateCredentialStoreRequest{Item: req.GetItem()})
+}
+
 func validateUpdateRequest(req *pbs.UpdateCredentialStoreRequest) error {
 	return handlers.ValidateUpdateRequest(req, req.GetItem(), func() map[string]string {
 		badFields := map[string]string{}
@@ -549,6 +549,102 @@ func TestCreate(t *testing.T) {
 	}
 }
 
+func TestTestConnection(t *testing.T) {
+	conn, _ := db.TestSetup(t, "postgres")
+	wrapper := db.TestWrapper(t)
+	kms := kms.TestKms(t, conn, wrapper)
+	sche := scheduler.TestScheduler(t, conn, wrapper)
+	rw := db.New(conn)
+
+	iamRepo := iam.TestRepo(t, conn, wrapper)
+	iamRepoFn := func() (*iam.Repository, error) {
+		return iamRepo, nil
+	}
+	repoFn := func() (*vault.Repository, error) {
+		return vault.NewRepository(rw, rw, kms, sche)
+	}
+
+	_, prj := iam.TestScopes(t, iamRepo)
+
+	v := vault.NewTestVaultServer(t, vault.WithTestVaultTLS(vault.TestClientTLS))
+	_, token := v.CreateToken(t)
+	item := func(token string) *pb.CredentialStore {
+		attrs, err := handlers.ProtoToStruct(&pb.VaultCredentialStoreAttributes{
+			Address:              wrapperspb.String(v.Addr),
+			Token:                wrapperspb.String(token),
+			CaCert:               wrapperspb.String(string(v.CaCert)),
+			ClientCertificate:    wrapperspb.String(string(v.ClientCert)),
+			ClientCertificateKey: wrapperspb.String(string(v.ClientKey)),
+		})
+		require.NoError(t, err)
+		return &pb.CredentialStore{
+			ScopeId:    prj.GetPublicId(),
+			Type:       credential.VaultSubtype.String(),
+			Attributes: attrs,
+		}
+	}
+
+	cases := []struct {
+		name    string
+		req     *pbs.TestCredentialStoreConnectionRequest
+		err     error
+		wantErr bool
+	}{
+		{
+			name: "Valid token",
+			req:  &pbs.TestCredentialStoreConnectionRequest{Item: item(token)},
+		},
+		{
+			name:    "Bad token",
+			req:     &pbs.TestCredentialStoreConnectionRequest{Item: item("madeup")},
+			wantErr: true,
+		},
+		{
+			name: "Can't specify Id",
+			req: &pbs.TestCredentialStoreConnectionRequest{Item: func() *pb.CredentialStore {
+				i := item(token)
+				i.Id = vault.CredentialStorePrefix + "_notallowed"
+				return i
+			}()},
+			err: handlers.ApiErrorWithCode(codes.InvalidArgument),
+		},
+		{
+			name: "Invalid scope",
+			req: &pbs.TestCredentialStoreConnectionRequest{Item: func() *pb.CredentialStore {
+				i := item(token)
+				i.ScopeId = "o_invalid"
+				return i
+			}()},
+			err: handlers.ApiErrorWithCode(codes.InvalidArgument),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert, require := assert.New(t), require.New(t)
+
+			s, err := NewService(repoFn, iamRepoFn)
+			require.NoError(err, "Error when getting new credential store service.")
+
+			ctx := auth.DisabledAuthTestContext(iamRepoFn, prj.GetPublicId())
+			got, gErr := s.TestCredentialStoreConnection(ctx, tc.req)
+			if tc.wantErr || tc.err != nil {
+				require.Error(gErr)
+				if tc.err != nil {
+					assert.True(errors.Is(gErr, tc.err), "TestCredentialStoreConnection(...) got error %v, wanted %v", gErr, tc.err)
+				}
+			} else {
+				require.NoError(gErr)
+				assert.NotNil(got)
+			}
+
+			// the connection is tested without creating the store
+			r, err := s.ListCredentialStores(ctx, &pbs.ListCredentialStoresRequest{ScopeId: prj.GetPublicId()})
+			require.NoError(err)
+			assert.Empty(r.GetItems())
+		})
+	}
+}
+
 func TestGet(t *testing.T) {
 	conn, _ := db.TestSetup(t, "postgres")
 	wrapper := db.TestWrapper(t)
@@ -22,6 +22,7 @@ import (
 	"github.com/hashicorp/boundary/internal/host"
 	"github.com/hashicorp/boundary/internal/host/static"
 	"github.com/hashicorp/boundary/internal/kms"
+	"github.com/hashicorp/boundary/internal/observability/event"
 	"github.com/hashicorp/boundary/internal/perms"
 	"github.com/hashicorp/boundary/internal/requests"
 	"github.com/hashicorp/boundary/internal/servers"
@@ -570,6 +571,11 @@ func (s Service) AuthorizeSession(ctx context.Context, req *pbs.AuthorizeSession
 	if err := validateAuthorizeSessionRequest(req); err != nil {
 		return nil, err
 	}
+	// sessions aren't authorized while they can't be audited (see
+	// EventerConfig.AuditFailClosedThreshold)
+	if err := event.CheckAuditDegraded(ctx); err != nil {
+		return nil, handlers.ApiErrorWithCodeAndMessage(codes.Unavailable, "Sessions can't be authorized while audit events can't be delivered.")
+	}
 	authResults := s.authResult(ctx, req.GetId(), action.AuthorizeSession,
 		target.WithName(req.GetName()),
 		target.WithScopeId(req.GetScopeId()),
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/boundary/internal/auth"
@@ -26,6 +27,7 @@ import (
 	"github.com/hashicorp/boundary/internal/host/static"
 	"github.com/hashicorp/boundary/internal/iam"
 	"github.com/hashicorp/boundary/internal/kms"
+	"github.com/hashicorp/boundary/internal/observability/event"
 	"github.com/hashicorp/boundary/internal/requests"
 	"github.com/hashicorp/boundary/internal/scheduler"
 	"github.com/hashicorp/boundary/internal/servers"
@@ -37,6 +39,7 @@ import (
 	"github.com/hashicorp/boundary/internal/session"
 	"github.com/hashicorp/boundary/internal/target"
 	"github.com/hashicorp/boundary/internal/types/scope"
+	"github.com/hashicorp/eventlogger"
 	"github.com/hashicorp/go-hclog"
 	wrapping "github.com/hashicorp/go-kms-wrapping"
 	"github.com/jinzhu/gorm"
@@ -2046,6 +2049,142 @@ func TestAuthorizeSession_Errors(t *testing.T) {
 	}
 }
 
+// testAuditFailingBroker is an eventer broker which fails to send every audit
+// event, so an eventer which uses it is degraded once its audit failures reach
+// the eventer's AuditFailClosedThreshold.
+type testAuditFailingBroker struct{}
+
+func (testAuditFailingBroker) Send(_ context.Context, t eventlogger.EventType, _ interface{}) (eventlogger.Status, error) {
+	if t == eventlogger.EventType(event.AuditType) {
+		return eventlogger.Status{}, errors.New("audit sink unavailable")
+	}
+	return eventlogger.Status{}, nil
+}
+
+func (testAuditFailingBroker) Reopen(context.Context) error { return nil }
+
+func (testAuditFailingBroker) StopTimeAt(time.Time) {}
+
+func (testAuditFailingBroker) RegisterNode(eventlogger.NodeID, eventlogger.Node) error { return nil }
+
+func (testAuditFailingBroker) SetSuccessThreshold(eventlogger.EventType, int) error { return nil }
+
+func (testAuditFailingBroker) RegisterPipeline(eventlogger.Pipeline) error { return nil }
+
+func (testAuditFailingBroker) RemovePipeline(eventlogger.EventType, eventlogger.PipelineID) error {
+	return nil
+}
+
+func TestAuthorizeSession_AuditDegraded(t *testing.T) {
+	conn, _ := db.TestSetup(t, "postgres")
+	rw := db.New(conn)
+	wrapper := db.TestWrapper(t)
+	kms := kms.TestKms(t, conn, wrapper)
+
+	sche := scheduler.TestScheduler(t, conn, wrapper)
+	repoFn := func() (*target.Repository, error) {
+		return target.NewRepository(rw, rw, kms)
+	}
+	iamRepo := iam.TestRepo(t, conn, wrapper)
+	iamRepoFn := func() (*iam.Repository, error) {
+		return iamRepo, nil
+	}
+	serversRepoFn := func() (*servers.Repository, error) {
+		return servers.NewRepository(rw, rw, kms)
+	}
+	sessionRepoFn := func() (*session.Repository, error) {
+		return session.NewRepository(rw, rw, kms)
+	}
+	staticHostRepoFn := func() (*static.Repository, error) {
+		return static.NewRepository(rw, rw, kms)
+	}
+	credentialRepoFn := func() (*vault.Repository, error) {
+		return vault.NewRepository(rw, rw, kms, sche)
+	}
+	atRepoFn := func() (*authtoken.Repository, error) {
+		return authtoken.NewRepository(rw, rw, kms)
+	}
+
+	org, proj := iam.TestScopes(t, iamRepo)
+	at := authtoken.TestAuthToken(t, conn, kms, org.GetPublicId())
+	ctx := auth.NewVerifierContext(requests.NewRequestContext(context.Background()),
+		nil,
+		iamRepoFn,
+		atRepoFn,
+		serversRepoFn,
+		kms,
+		auth.RequestInfo{
+			Token:       at.GetToken(),
+			TokenFormat: auth.AuthTokenTypeBearer,
+			PublicId:    at.GetPublicId(),
+		})
+
+	r := iam.TestRole(t, conn, proj.GetPublicId())
+	_ = iam.TestUserRole(t, conn, r.GetPublicId(), at.GetIamUserId())
+	_ = iam.TestRoleGrant(t, conn, r.GetPublicId(), "id=*;type=*;actions=*")
+
+	s, err := targets.NewService(kms, repoFn, iamRepoFn, serversRepoFn, sessionRepoFn, staticHostRepoFn, credentialRepoFn)
+	require.NoError(t, err)
+
+	tar := target.TestTcpTarget(t, conn, proj.GetPublicId(), "test")
+	hc := static.TestCatalogs(t, conn, proj.GetPublicId(), 1)[0]
+	h := static.TestHosts(t, conn, hc.GetPublicId(), 1)[0]
+	hs := static.TestSets(t, conn, hc.GetPublicId(), 1)[0]
+	_ = static.TestSetMembers(t, conn, hs.GetPublicId(), []*static.Host{h})
+	_, err = s.AddTargetHostSets(ctx, &pbs.AddTargetHostSetsRequest{
+		Id:         tar.GetPublicId(),
+		Version:    tar.GetVersion(),
+		HostSetIds: []string{hs.GetPublicId()},
+	})
+	require.NoError(t, err)
+
+	workerService := workers.NewWorkerServiceServer(hclog.Default(), serversRepoFn, sessionRepoFn, &sync.Map{}, kms)
+	_, err = workerService.Status(ctx, &spbs.StatusRequest{
+		Worker: &spb.Server{
+			PrivateId: "testworker",
+			Address:   "localhost:8457",
+		},
+	})
+	require.NoError(t, err)
+
+	// an eventer whose audit events can't be delivered is degraded after
+	// the first failure
+	event.TestEnableEventing(t, true)
+	eventer, err := event.NewEventer(hclog.NewNullLogger(), &sync.Mutex{}, event.EventerConfig{
+		AuditEnabled:             true,
+		AuditFailClosedThreshold: 1,
+	}, event.TestWithBroker(t, testAuditFailingBroker{}), event.WithNoBackoffJitter())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = eventer.Close(context.Background()) })
+	degradedCtx, err := event.NewEventerContext(ctx, eventer)
+	require.NoError(t, err)
+	require.Error(t, event.WriteAudit(degradedCtx, "TestAuthorizeSession_AuditDegraded", event.WithFlush()))
+	require.True(t, eventer.AuditDegraded())
+
+	res, err := s.AuthorizeSession(degradedCtx, &pbs.AuthorizeSessionRequest{
+		Id: tar.GetPublicId(),
+	})
+	require.Error(t, err)
+	assert.Nil(t, res)
+	assert.True(t, errors.Is(err, handlers.ApiErrorWithCode(codes.Unavailable)), "Got %v, wanted unavailable error.", err)
+
+	sessionRepo, err := sessionRepoFn()
+	require.NoError(t, err)
+	sessions, err := sessionRepo.ListSessions(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, sessions, "a session was created while audit was degraded")
+
+	// the same request is authorized when audit isn't degraded
+	res, err = s.AuthorizeSession(ctx, &pbs.AuthorizeSessionRequest{
+		Id: tar.GetPublicId(),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	sessions, err = sessionRepo.ListSessions(ctx)
+	require.NoError(t, err)
+	assert.Len(t, sessions, 1)
+}
+
 func decodeJsonSecret(t *testing.T, in string) map[string]interface{} {
 	t.Helper()
 	ret := make(map[string]interface{})